@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ListActions returns a list of all actions of rs triggered by opening the document
+// or by document, page, annotation and form field events.
+func ListActions(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTACTIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.ListActions(ctx)
+}
+
+// ListActionsFile returns a list of all actions of inFile triggered by opening the document
+// or by document, page, annotation and form field events.
+func ListActionsFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListActions(f, conf)
+}
+
+// StripActions removes all Launch, ImportData, SubmitForm, Sound and Movie actions of rs
+// keeping any other actions and writes the result to w.
+func StripActions(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.STRIPACTIONS
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	removed, err := ctx.StripActions()
+	if err != nil {
+		return err
+	}
+	log.CLI.Printf("removed %d actions\n", removed)
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durStrip := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durStrip + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "strip actions, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// StripActionsFile removes all Launch, ImportData, SubmitForm, Sound and Movie actions of inFile
+// keeping any other actions and writes the result to outFile.
+func StripActionsFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return StripActions(f1, f2, conf)
+}
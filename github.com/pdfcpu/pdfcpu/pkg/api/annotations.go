@@ -0,0 +1,241 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ListAnnotations returns the annotations of the selected pages of rs along with their type, location and link targets.
+func ListAnnotations(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.AnnotationInfo, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTANNOTATIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ListAnnotations(pages)
+}
+
+// ListAnnotationsFile returns the annotations of the selected pages of inFile along with their type, location and link targets.
+func ListAnnotationsFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.AnnotationInfo, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListAnnotations(f, selectedPages, conf)
+}
+
+// RemoveAnnotations removes the annotations of the selected pages of rs whose subtype is one of types,
+// writes the result to w and returns the number of annotations removed.
+// All annotations get removed if types is empty.
+func RemoveAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages []string, types []string, conf *pdfcpu.Configuration) (int, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.REMOVEANNOTATIONS
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return 0, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return 0, err
+	}
+
+	from := time.Now()
+
+	count, err := ctx.RemoveAnnotations(pages, types)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durRemove := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return 0, err
+	}
+
+	durWrite := durRemove + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "remove annotations, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return count, nil
+}
+
+// RemoveAnnotationsFile removes the annotations of the selected pages of inFile whose subtype is one of types,
+// writes the result to outFile and returns the number of annotations removed.
+// All annotations get removed if types is empty.
+func RemoveAnnotationsFile(inFile, outFile string, selectedPages []string, types []string, conf *pdfcpu.Configuration) (count int, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return 0, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			count = 0
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return RemoveAnnotations(f1, f2, selectedPages, types, conf)
+}
+
+// AddLinkAnnotations adds the link annotations ll to rs and writes the result to w.
+func AddLinkAnnotations(rs io.ReadSeeker, w io.Writer, ll []pdfcpu.LinkAnnotation, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ADDLINKANNOTATIONS
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := ctx.AddLinkAnnotations(ll); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durAdd := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durAdd + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "add link annotations, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// AddLinkAnnotationsFile adds the link annotations ll to inFile and writes the result to outFile.
+func AddLinkAnnotationsFile(inFile, outFile string, ll []pdfcpu.LinkAnnotation, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return AddLinkAnnotations(f1, f2, ll, conf)
+}
@@ -44,6 +44,8 @@ import (
 )
 
 // ReadContext uses an io.ReadSeeker to build an internal structure holding its cross reference table aka the Context.
+// For encrypted files lacking a preset password conf.PasswordFunc gets asked for one.
+// pdfcpu.ErrWrongPassword is returned if no correct password is available.
 func ReadContext(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.Context, error) {
 	return pdfcpu.Read(rs, conf)
 }
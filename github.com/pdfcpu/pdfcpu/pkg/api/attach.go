@@ -31,6 +31,18 @@ import (
 
 // ListAttachments returns a list of embedded file attachments of rs.
 func ListAttachments(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, error) {
+	return ListAttachmentsByType(rs, nil, conf)
+}
+
+// ListAttachmentsFile returns a list of embedded file attachments of inFile.
+func ListAttachmentsFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+	return ListAttachmentsByTypeFile(inFile, nil, conf)
+}
+
+// ListAttachmentsByType returns a list of embedded file attachments of rs matching any of mimeTypes.
+// Matching is case insensitive and supports wildcards like "audio/*".
+// An empty mimeTypes lists all attachments.
+func ListAttachmentsByType(rs io.ReadSeeker, mimeTypes []string, conf *pdfcpu.Configuration) ([]string, error) {
 	if rs == nil {
 		return nil, errors.New("pdfcpu: ListAttachments: Please provide rs")
 	}
@@ -46,7 +58,7 @@ func ListAttachments(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, er
 
 	fromWrite := time.Now()
 
-	aa, err := ctx.ListAttachments()
+	aa, err := ctx.ListAttachmentsByType(mimeTypes)
 	if err != nil {
 		return nil, err
 	}
@@ -65,14 +77,14 @@ func ListAttachments(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, er
 	return ss, nil
 }
 
-// ListAttachmentsFile returns a list of embedded file attachments of inFile.
-func ListAttachmentsFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+// ListAttachmentsByTypeFile returns a list of embedded file attachments of inFile matching any of mimeTypes.
+func ListAttachmentsByTypeFile(inFile string, mimeTypes []string, conf *pdfcpu.Configuration) ([]string, error) {
 	f, err := os.Open(inFile)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	return ListAttachments(f, conf)
+	return ListAttachmentsByType(f, mimeTypes, conf)
 }
 
 // AddAttachments embeds files into a PDF context read from rs and writes the result to w.
@@ -121,7 +133,7 @@ func AddAttachments(rs io.ReadSeeker, w io.Writer, files []string, coll bool, co
 		}
 		mt := fi.ModTime()
 
-		a := pdfcpu.Attachment{Reader: f, ID: filepath.Base(fileName), Desc: desc, ModTime: &mt}
+		a := pdfcpu.Attachment{Reader: f, ID: fileName, Desc: desc, ModTime: &mt}
 		if err = ctx.AddAttachment(a, coll); err != nil {
 			return err
 		}
@@ -269,11 +281,108 @@ func RemoveAttachmentsFile(inFile, outFile string, files []string, conf *pdfcpu.
 	return RemoveAttachments(f1, f2, files, conf)
 }
 
+// RenameAttachments renames embedded files of a PDF context read from rs and writes the result to w.
+// m maps attachment ids to their new ids.
+func RenameAttachments(rs io.ReadSeeker, w io.Writer, m map[string]string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: RenameAttachments: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: RenameAttachments: Please provide w")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	var ok bool
+	if ok, err = ctx.RenameAttachments(m); err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("no attachment renamed")
+	}
+
+	durRename := time.Since(from).Seconds()
+	fromWrite := time.Now()
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durRename + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "rename att, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// RenameAttachmentsFile renames embedded files of a PDF context read from inFile and writes the result to outFile.
+func RenameAttachmentsFile(inFile, outFile string, m map[string]string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return RenameAttachments(f1, f2, m, conf)
+}
+
 // ExtractAttachments extracts embedded files from a PDF context read from rs into outDir.
 func ExtractAttachments(rs io.ReadSeeker, outDir string, fileNames []string, conf *pdfcpu.Configuration) error {
 	if rs == nil {
 		return errors.New("pdfcpu: ExtractAttachments: Please provide rs")
 	}
+	return extractAttachments(rs, outDir, fileNames, false, conf)
+}
+
+// ExtractAttachmentsVerified extracts embedded files from a PDF context read from rs into outDir
+// after verifying their data against the checksums stored along with them.
+// Nothing gets written if the cause of the returned error is pdfcpu.ErrAttachmentChecksumMismatch.
+func ExtractAttachmentsVerified(rs io.ReadSeeker, outDir string, fileNames []string, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractAttachmentsVerified: Please provide rs")
+	}
+	return extractAttachments(rs, outDir, fileNames, true, conf)
+}
+
+func extractAttachments(rs io.ReadSeeker, outDir string, fileNames []string, verify bool, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
@@ -286,13 +395,21 @@ func ExtractAttachments(rs io.ReadSeeker, outDir string, fileNames []string, con
 
 	fromWrite := time.Now()
 
-	aa, err := ctx.ExtractAttachments(fileNames)
+	var aa []pdfcpu.Attachment
+	if verify {
+		aa, err = ctx.ExtractAttachmentsVerified(fileNames)
+	} else {
+		aa, err = ctx.ExtractAttachments(fileNames)
+	}
 	if err != nil {
 		return err
 	}
 
 	for _, a := range aa {
-		filename := filepath.Join(outDir, a.ID)
+		filename := filepath.Join(outDir, filepath.FromSlash(a.ID))
+		if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+			return err
+		}
 		f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
 		if err != nil {
 			return err
@@ -313,6 +430,37 @@ func ExtractAttachments(rs io.ReadSeeker, outDir string, fileNames []string, con
 	return nil
 }
 
+// ExtractAttachment returns the attachment id of rs including a reader for its data without touching the file system.
+// pdfcpu.ErrAttachmentNotFound is returned if there is no such attachment.
+func ExtractAttachment(rs io.ReadSeeker, id string, conf *pdfcpu.Configuration) (*pdfcpu.Attachment, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ExtractAttachment: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return nil, err
+	}
+
+	fromWrite := time.Now()
+
+	a, err := ctx.ExtractAttachment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.TimingStats("extract file", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return a, nil
+}
+
 // ExtractAttachmentsFile extracts embedded files from a PDF context read from inFile into outDir.
 func ExtractAttachmentsFile(inFile, outDir string, fileNames []string, conf *pdfcpu.Configuration) error {
 	f, err := os.Open(inFile)
@@ -322,3 +470,14 @@ func ExtractAttachmentsFile(inFile, outDir string, fileNames []string, conf *pdf
 	defer f.Close()
 	return ExtractAttachments(f, outDir, fileNames, conf)
 }
+
+// ExtractAttachmentsVerifiedFile extracts embedded files from a PDF context read from inFile into outDir
+// after verifying their data against the checksums stored along with them.
+func ExtractAttachmentsVerifiedFile(inFile, outDir string, fileNames []string, conf *pdfcpu.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return ExtractAttachmentsVerified(f, outDir, fileNames, conf)
+}
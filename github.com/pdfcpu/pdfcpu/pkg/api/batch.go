@@ -0,0 +1,169 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// Job describes the processing of a single file by BatchProcess.
+type Job struct {
+	InFile  string                      // the file to be processed.
+	OutFile string                      // the file to be written, if empty InFile gets overwritten.
+	Op      func(*pdfcpu.Context) error // the operation applied to the context of InFile.
+	Conf    *pdfcpu.Configuration       // optional, each job works on its own copy.
+}
+
+// Result represents the outcome of a Job.
+type Result struct {
+	Job Job
+	Err error
+}
+
+// Process reads a PDF stream from rs, applies op and writes the result to w.
+func Process(rs io.ReadSeeker, w io.Writer, op func(*pdfcpu.Context) error, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.BATCH
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if op != nil {
+		if err := op(ctx); err != nil {
+			return err
+		}
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	fromWrite := time.Now()
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "process, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// processFile runs job converting any panic into an error.
+func processFile(job Job) (err error) {
+	var f1, f2 *os.File
+
+	inFile, outFile := job.InFile, job.OutFile
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("pdfcpu: processing %s panicked: %v", inFile, r)
+		}
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	var conf *pdfcpu.Configuration
+	if job.Conf != nil {
+		c := *job.Conf
+		conf = &c
+	}
+
+	return Process(f1, f2, job.Op, conf)
+}
+
+// BatchProcess runs jobs using a pool of parallelism workers and returns their results in the order of jobs.
+// Each job reads its input file, applies its operation and writes its output file independently,
+// so at most parallelism contexts are held in memory at any time.
+// A panicking operation fails its job only.
+func BatchProcess(jobs []Job, parallelism int) []Result {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	rr := make([]Result, len(jobs))
+	ch := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				rr[j] = Result{Job: jobs[j], Err: processFile(jobs[j])}
+			}
+		}()
+	}
+
+	for j := range jobs {
+		ch <- j
+	}
+	close(ch)
+
+	wg.Wait()
+
+	return rr
+}
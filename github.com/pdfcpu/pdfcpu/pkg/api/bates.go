@@ -0,0 +1,80 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// BatesStampFile stamps all pages of inFiles with sequential Bates numbers made of prefix
+// and a number zero padded to digits, starting with start and continuing across inFiles in the order given.
+// pos is either a position anchor like "br" or a stamp description like "pos:br, points:10, fillcolor:#FF0000"
+// controlling font, size and color.
+// The stamped copies are written to outDir using the file names of inFiles.
+func BatesStampFile(inFiles []string, outDir string, prefix string, start int, digits int, pos string, conf *pdfcpu.Configuration) error {
+	if start < 0 {
+		return errors.Errorf("pdfcpu: Bates numbering: invalid start number: %d", start)
+	}
+
+	wm, err := pdfcpu.ParseBatesStampDetails(prefix, digits, pos)
+	if err != nil {
+		return err
+	}
+
+	outFiles := map[string]bool{}
+	for _, inFile := range inFiles {
+		outFile := filepath.Join(outDir, filepath.Base(inFile))
+		if outFiles[outFile] {
+			return errors.Errorf("pdfcpu: Bates numbering: duplicate file name: %s", filepath.Base(inFile))
+		}
+		outFiles[outFile] = true
+	}
+
+	nr := start
+	for _, inFile := range inFiles {
+		pageCount, err := batesPageCount(inFile, conf)
+		if err != nil {
+			return err
+		}
+		wm.PageNrOffset = nr - 1
+		if err := AddWatermarksFile(inFile, filepath.Join(outDir, filepath.Base(inFile)), nil, wm, conf); err != nil {
+			return err
+		}
+		nr += pageCount
+	}
+
+	return nil
+}
+
+// batesPageCount returns the page count of inFile using the passwords of conf.
+func batesPageCount(inFile string, conf *pdfcpu.Configuration) (int, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return PageCount(f, conf)
+}
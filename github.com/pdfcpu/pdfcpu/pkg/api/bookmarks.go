@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// AddBookmarks adds an outline made of bms to rs and writes the result to w.
+// Each bookmark points to the top of its page. Existing outline items are kept unless replace is true.
+func AddBookmarks(rs io.ReadSeeker, w io.Writer, bms []pdfcpu.Bookmark, replace bool, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.ADDBOOKMARKS
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := pdfcpu.AddBookmarks(ctx, bms, replace); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durAdd := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durAdd + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "add bookmarks, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// AddBookmarksFile adds an outline made of bms to inFile and writes the result to outFile.
+// Each bookmark points to the top of its page. Existing outline items are kept unless replace is true.
+func AddBookmarksFile(inFile, outFile string, bms []pdfcpu.Bookmark, replace bool, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return AddBookmarks(f1, f2, bms, replace, conf)
+}
+
+// ExportBookmarks writes the outline of rs to w as JSON made of nested bookmarks.
+// Named destinations get resolved to page numbers.
+func ExportBookmarks(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXPORTBOOKMARKS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	bms, err := ctx.Bookmarks()
+	if err != nil {
+		return err
+	}
+
+	bb, err := json.MarshalIndent(bms, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bb)
+	return err
+}
+
+// ExportBookmarksFile writes the outline of inFile to jsonFile as JSON made of nested bookmarks.
+// Named destinations get resolved to page numbers.
+func ExportBookmarksFile(inFile, jsonFile string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+	defer f1.Close()
+
+	log.CLI.Printf("writing %s...\n", jsonFile)
+	if f2, err = os.Create(jsonFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			os.Remove(jsonFile)
+			return
+		}
+		err = f2.Close()
+	}()
+
+	return ExportBookmarks(f1, f2, conf)
+}
+
+func decodeBookmarks(rd io.Reader) ([]pdfcpu.Bookmark, error) {
+	var bms []pdfcpu.Bookmark
+	if err := json.NewDecoder(rd).Decode(&bms); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: ImportBookmarks: corrupt bookmarks")
+	}
+	return bms, nil
+}
+
+// ImportBookmarks replaces the outline of rs by the JSON bookmarks read from rd and writes the result to w.
+func ImportBookmarks(rs io.ReadSeeker, rd io.Reader, w io.Writer, conf *pdfcpu.Configuration) error {
+	bms, err := decodeBookmarks(rd)
+	if err != nil {
+		return err
+	}
+
+	return AddBookmarks(rs, w, bms, true, conf)
+}
+
+// ImportBookmarksFile replaces the outline of inFile by the JSON bookmarks read from jsonFile and writes the result to outFile.
+func ImportBookmarksFile(inFile, jsonFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	f, err := os.Open(jsonFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bms, err := decodeBookmarks(f)
+	if err != nil {
+		return err
+	}
+
+	return AddBookmarksFile(inFile, outFile, bms, true, conf)
+}
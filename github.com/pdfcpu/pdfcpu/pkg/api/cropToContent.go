@@ -0,0 +1,123 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// CropToContent sets the crop box of selected pages of rs to the bounding box of their visible content
+// enlarged by padding points on each side and writes the result to w.
+// Pages without detectable content remain unchanged.
+func CropToContent(rs io.ReadSeeker, w io.Writer, selectedPages []string, padding float64, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.CROPTOCONTENT
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	from := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	skipped, err := pdfcpu.CropToContent(ctx, pages, padding)
+	if err != nil {
+		return err
+	}
+	for _, i := range skipped {
+		log.CLI.Printf("warning: page %d: no content detected, crop box unchanged\n", i)
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durCrop := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durCrop + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "crop to content, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// CropToContentFile sets the crop box of selected pages of inFile to the bounding box of their visible content
+// enlarged by padding points on each side and writes the result to outFile.
+func CropToContentFile(inFile, outFile string, selectedPages []string, padding float64, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return CropToContent(f1, f2, selectedPages, padding, conf)
+}
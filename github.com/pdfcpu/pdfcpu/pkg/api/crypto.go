@@ -17,6 +17,9 @@
 package api
 
 import (
+	"io"
+	"os"
+
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -64,3 +67,38 @@ func ChangeOwnerPasswordFile(inFile, outFile string, pwOld, pwNew string, conf *
 	conf.OwnerPWNew = &pwNew
 	return OptimizeFile(inFile, outFile, conf)
 }
+
+// ChangePasswordFile reads inFile, replaces both passwords and writes the result to outFile.
+// Only the current owner password is required.
+// AES-256 encrypted files keep their file encryption key, all others get re-encrypted using the new passwords.
+// Permissions are preserved unless conf.PermissionFlags is set.
+func ChangePasswordFile(inFile, outFile string, oldOwnerPW, newUserPW, newOwnerPW string, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.CHANGEPW
+	conf.UserPW = ""
+	conf.OwnerPW = oldOwnerPW
+	conf.UserPWNew = &newUserPW
+	conf.OwnerPWNew = &newOwnerPW
+	return OptimizeFile(inFile, outFile, conf)
+}
+
+// IsEncrypted reports whether rs is encrypted and if so whether opening it requires a user password
+// and whether its permissions are protected by an owner password.
+// Only the cross reference table and the encrypt dictionary get read, no objects get decrypted.
+func IsEncrypted(rs io.ReadSeeker) (encrypted, hasUserPW, hasOwnerPW bool, err error) {
+	return pdfcpu.EncryptionStatus(rs, pdfcpu.NewDefaultConfiguration())
+}
+
+// IsEncryptedFile reports whether inFile is encrypted and if so whether opening it requires a user password
+// and whether its permissions are protected by an owner password.
+func IsEncryptedFile(inFile string) (encrypted, hasUserPW, hasOwnerPW bool, err error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return false, false, false, err
+	}
+	defer f.Close()
+
+	return IsEncrypted(f)
+}
@@ -0,0 +1,64 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// Diff returns the differences between rsA and rsB regarding page count,
+// page text normalized for whitespace, annotations and document info.
+func Diff(rsA, rsB io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.Diff, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.DIFF
+
+	ctxA, _, _, err := readAndValidate(rsA, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ctxB, _, _, err := readAndValidate(rsB, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.Compare(ctxA, ctxB)
+}
+
+// DiffFile returns the differences between fileA and fileB regarding page count,
+// page text normalized for whitespace, annotations and document info.
+func DiffFile(fileA, fileB string, conf *pdfcpu.Configuration) (*pdfcpu.Diff, error) {
+	f1, err := os.Open(fileA)
+	if err != nil {
+		return nil, err
+	}
+	defer f1.Close()
+
+	f2, err := os.Open(fileB)
+	if err != nil {
+		return nil, err
+	}
+	defer f2.Close()
+
+	return Diff(f1, f2, conf)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ReadingDirection returns the predominant reading order of rs, either pdfcpu.L2R or pdfcpu.R2L.
+func ReadingDirection(rs io.ReadSeeker, conf *pdfcpu.Configuration) (string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTDIRECTION
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	return pdfcpu.ReadingDirection(ctx)
+}
+
+// ReadingDirectionFile returns the predominant reading order of inFile, either pdfcpu.L2R or pdfcpu.R2L.
+func ReadingDirectionFile(inFile string, conf *pdfcpu.Configuration) (string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return ReadingDirection(f, conf)
+}
+
+// SetReadingDirection sets the predominant reading order of rs to pdfcpu.L2R or pdfcpu.R2L and writes the result to w.
+func SetReadingDirection(rs io.ReadSeeker, w io.Writer, dir string, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETDIRECTION
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := pdfcpu.SetReadingDirection(ctx, dir); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durDir := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durDir + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "reading direction, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetReadingDirectionFile sets the predominant reading order of inFile to pdfcpu.L2R or pdfcpu.R2L and writes the result to outFile.
+func SetReadingDirectionFile(inFile, outFile string, dir string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetReadingDirection(f1, f2, dir, conf)
+}
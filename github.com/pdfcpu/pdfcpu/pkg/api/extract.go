@@ -100,6 +100,8 @@ func ExtractImagesFile(inFile, outDir string, selectedPages []string, conf *pdfc
 }
 
 // ExtractFonts dumps embedded fontfiles from rs into outDir for selected pages.
+// Each font program is written once as pfb, ttf, otf or cff file named after its PostScript font name.
+// Non embedded fonts are skipped.
 func ExtractFonts(rs io.ReadSeeker, outDir, fileName string, selectedPages []string, conf *pdfcpu.Configuration) error {
 	if rs == nil {
 		return errors.New("pdfcpu: ExtractFonts: Please provide rs")
@@ -126,27 +128,23 @@ func ExtractFonts(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 
 	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
 
-	for i, v := range pages {
-		if !v {
-			continue
-		}
-		ff, err := ctx.ExtractPageFonts(i)
+	ff, err := ctx.ExtractFonts(pages)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range ff {
+		outFile := filepath.Join(outDir, fmt.Sprintf("%s_%s.%s", fileName, f.Name, f.Type))
+		log.CLI.Printf("writing %s\n", outFile)
+		w, err := os.Create(outFile)
 		if err != nil {
 			return err
 		}
-		for _, f := range ff {
-			outFile := filepath.Join(outDir, fmt.Sprintf("%s_%s.%s", fileName, f.Name, f.Type))
-			log.CLI.Printf("writing %s\n", outFile)
-			w, err := os.Create(outFile)
-			if err != nil {
-				return err
-			}
-			if _, err = io.Copy(w, f); err != nil {
-				return err
-			}
-			if err := w.Close(); err != nil {
-				return err
-			}
+		if _, err = io.Copy(w, f); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,50 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ListPDFFonts returns the fonts used by rs along with their type, embedding and subset status, encoding and pages.
+func ListPDFFonts(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.FontInfo, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTPDFFONTS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ListFonts()
+}
+
+// ListFontsFile returns the fonts used by inFile along with their type, embedding and subset status, encoding and pages.
+func ListFontsFile(inFile string, conf *pdfcpu.Configuration) ([]pdfcpu.FontInfo, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListPDFFonts(f, conf)
+}
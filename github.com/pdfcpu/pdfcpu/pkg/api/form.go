@@ -0,0 +1,529 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// RegenerateAppearances regenerates the appearance streams of form fields and markup annotations of rs and writes the result to w.
+func RegenerateAppearances(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.REGENERATEAPPEARANCES
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = ctx.RegenerateAppearances(); err != nil {
+		return err
+	}
+
+	durRegen := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durRegen + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "regenerate appearances, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// RegenerateAppearancesFile regenerates the appearance streams of form fields and markup annotations of inFile and writes the result to outFile.
+func RegenerateAppearancesFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return RegenerateAppearances(f1, f2, conf)
+}
+
+// FormValues returns the values of all form fields of rs keyed by their fully qualified field names.
+func FormValues(rs io.ReadSeeker, conf *pdfcpu.Configuration) (map[string]string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return nil, err
+	}
+
+	fromWrite := time.Now()
+	m, err := ctx.FormValues()
+	if err != nil {
+		return nil, err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.TimingStats("list form values", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return m, nil
+}
+
+// FormValuesFile returns the values of all form fields of inFile keyed by their fully qualified field names.
+func FormValuesFile(inFile string, conf *pdfcpu.Configuration) (map[string]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return FormValues(f, conf)
+}
+
+// ListFormFields returns all form fields of rs along with their type, value, options, flags and page number.
+func ListFormFields(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.FormField, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTFORMFIELDS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ListFormFields()
+}
+
+// ListFormFieldsFile returns all form fields of inFile along with their type, value, options, flags and page number.
+func ListFormFieldsFile(inFile string, conf *pdfcpu.Configuration) ([]pdfcpu.FormField, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListFormFields(f, conf)
+}
+
+// SetFieldFlags sets the common field flags of the form fields of rs named by the keys of flags and writes the result to w.
+func SetFieldFlags(rs io.ReadSeeker, w io.Writer, flags map[string]pdfcpu.FieldFlags, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETFIELDFLAGS
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = ctx.SetFieldFlags(flags); err != nil {
+		return err
+	}
+
+	durSet := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durSet + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "set field flags, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetFieldFlagsFile sets the common field flags of the form fields of inFile named by the keys of flags and writes the result to outFile.
+func SetFieldFlagsFile(inFile, outFile string, flags map[string]pdfcpu.FieldFlags, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetFieldFlags(f1, f2, flags, conf)
+}
+
+// FillForm sets the values of the form fields of rs named by the keys of values, regenerates their appearances
+// and writes the result to w. If flatten is true the filled fields get converted to static page content.
+func FillForm(rs io.ReadSeeker, w io.Writer, values map[string]string, flatten bool, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.FILLFORM
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = ctx.FillForm(values, flatten); err != nil {
+		return err
+	}
+
+	durFill := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durFill + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "fill form, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// FillFormFile sets the values of the form fields of inFile named by the keys of values, regenerates their appearances
+// and writes the result to outFile. If flatten is true the filled fields get converted to static page content.
+func FillFormFile(inFile, outFile string, values map[string]string, flatten bool, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return FillForm(f1, f2, values, flatten, conf)
+}
+
+// isXFDF returns true for XFDF and false for FDF data files based on the file extension.
+func isXFDF(dataFile string) (bool, error) {
+	switch strings.ToLower(filepath.Ext(dataFile)) {
+	case ".fdf":
+		return false, nil
+	case ".xfdf":
+		return true, nil
+	}
+	return false, errors.Errorf("pdfcpu: unsupported form data file: %s, use .fdf or .xfdf", dataFile)
+}
+
+// ExportForm writes the names and values of all form fields of rs to w as XFDF if xfdf is true or as FDF.
+// fileName is recorded as source document if not empty.
+func ExportForm(rs io.ReadSeeker, w io.Writer, fileName string, xfdf bool, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXPORTFORM
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+
+	fd, err := ctx.FormData()
+	if err != nil {
+		return err
+	}
+	fd.File = fileName
+
+	if xfdf {
+		err = fd.WriteXFDF(w)
+	} else {
+		err = fd.WriteFDF(w)
+	}
+	if err != nil {
+		return err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	pdfcpu.TimingStats("export form", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// ExportFormFile writes the names and values of all form fields of inFile to dataFile.
+// The format is chosen by the extension of dataFile which may be .fdf or .xfdf.
+func ExportFormFile(inFile, dataFile string, conf *pdfcpu.Configuration) (err error) {
+	xfdf, err := isXFDF(dataFile)
+	if err != nil {
+		return err
+	}
+
+	f1, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f1.Close()
+
+	f2, err := os.Create(dataFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f2.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dataFile)
+		}
+	}()
+
+	log.CLI.Printf("writing %s...\n", dataFile)
+
+	return ExportForm(f1, f2, filepath.Base(inFile), xfdf, conf)
+}
+
+// ImportForm sets the values of the form fields of rs to the values of the XFDF data rd if xfdf is true or FDF data,
+// regenerates their appearances and writes the result to w.
+func ImportForm(rs io.ReadSeeker, rd io.Reader, w io.Writer, xfdf bool, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.IMPORTFORM
+
+	var (
+		fd  *pdfcpu.FormData
+		err error
+	)
+	if xfdf {
+		fd, err = pdfcpu.ReadXFDF(rd)
+	} else {
+		fd, err = pdfcpu.ReadFDF(rd)
+	}
+	if err != nil {
+		return err
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = ctx.ImportFormData(fd); err != nil {
+		return err
+	}
+
+	durImport := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durImport + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "import form, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// ImportFormFile sets the values of the form fields of inFile to the values of dataFile,
+// regenerates their appearances and writes the result to outFile.
+// The format is chosen by the extension of dataFile which may be .fdf or .xfdf.
+func ImportFormFile(inFile, dataFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	xfdf, err := isXFDF(dataFile)
+	if err != nil {
+		return err
+	}
+
+	fd, err := os.Open(dataFile)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return ImportForm(f1, fd, f2, xfdf, conf)
+}
@@ -19,16 +19,33 @@ package api
 import (
 	"bufio"
 	"io"
+	"io/ioutil"
 	"os"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
 )
 
 // ImportImages appends PDF pages containing images to rs and writes the result to w.
 // If rs == nil a new PDF file will be written to w.
 func ImportImages(rs io.ReadSeeker, w io.Writer, imgs []io.Reader, imp *pdfcpu.Import, conf *pdfcpu.Configuration) error {
+	return importImages(rs, w, imgs, nil, imp, conf)
+}
+
+// ImportImagesWithText appends PDF pages containing images overlaid by the invisible OCR text of ocr to rs
+// and writes the result to w. ocr holds the recognized lines of each image.
+// Each line gets tagged as a paragraph of the document's structure tree.
+// If rs == nil a new PDF file will be written to w.
+func ImportImagesWithText(rs io.ReadSeeker, w io.Writer, imgs []io.Reader, ocr [][]pdfcpu.OCRLine, imp *pdfcpu.Import, conf *pdfcpu.Configuration) error {
+	if len(ocr) != len(imgs) {
+		return errors.Errorf("pdfcpu: need OCR text for %d images, got %d", len(imgs), len(ocr))
+	}
+	return importImages(rs, w, imgs, ocr, imp, conf)
+}
+
+func importImages(rs io.ReadSeeker, w io.Writer, imgs []io.Reader, ocr [][]pdfcpu.OCRLine, imp *pdfcpu.Import, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
@@ -63,9 +80,14 @@ func ImportImages(rs io.ReadSeeker, w io.Writer, imgs []io.Reader, imp *pdfcpu.I
 		return err
 	}
 
-	for _, r := range imgs {
+	for i, r := range imgs {
 
-		indRef, err := pdfcpu.NewPageForImage(ctx.XRefTable, r, pagesIndRef, imp)
+		var indRef *pdfcpu.IndirectRef
+		if ocr != nil {
+			indRef, err = pdfcpu.NewPageForImageWithText(ctx.XRefTable, r, pagesIndRef, imp, ocr[i])
+		} else {
+			indRef, err = pdfcpu.NewPageForImage(ctx.XRefTable, r, pagesIndRef, imp)
+		}
 		if err != nil {
 			return err
 		}
@@ -100,6 +122,19 @@ func fileExists(filename string) bool {
 
 // ImportImagesFile appends PDF pages containing images to outFile which will be created if necessary.
 func ImportImagesFile(imgFiles []string, outFile string, imp *pdfcpu.Import, conf *pdfcpu.Configuration) (err error) {
+	return importImagesFile(imgFiles, nil, outFile, imp, conf)
+}
+
+// ImportImagesWithTextFile appends PDF pages containing images overlaid by the invisible OCR text of ocr
+// to outFile which will be created if necessary. ocr holds the recognized lines of each image.
+func ImportImagesWithTextFile(imgFiles []string, ocr [][]pdfcpu.OCRLine, outFile string, imp *pdfcpu.Import, conf *pdfcpu.Configuration) (err error) {
+	if len(ocr) != len(imgFiles) {
+		return errors.Errorf("pdfcpu: need OCR text for %d images, got %d", len(imgFiles), len(ocr))
+	}
+	return importImagesFile(imgFiles, ocr, outFile, imp, conf)
+}
+
+func importImagesFile(imgFiles []string, ocr [][]pdfcpu.OCRLine, outFile string, imp *pdfcpu.Import, conf *pdfcpu.Configuration) (err error) {
 	var f1, f2 *os.File
 
 	rs := io.ReadSeeker(nil)
@@ -161,5 +196,116 @@ func ImportImagesFile(imgFiles []string, outFile string, imp *pdfcpu.Import, con
 		}
 	}()
 
-	return ImportImages(rs, f2, rr, imp, conf)
+	return importImages(rs, f2, rr, ocr, imp, conf)
+}
+
+// ImagesToPDF writes a new PDF file to w containing one page for each image of imgs
+// fitted into pages of dimensions pageSize according to fit, one of "contain", "cover" or "actual".
+// If pageSize is nil each page takes on the dimensions of its image at 72 DPI.
+// JPEG images are embedded without reencoding.
+func ImagesToPDF(imgs []io.Reader, w io.Writer, pageSize *pdfcpu.Dim, fit string, conf *pdfcpu.Configuration) error {
+	open := func(i int) (io.ReadCloser, error) {
+		return ioutil.NopCloser(imgs[i]), nil
+	}
+	return imagesToPDF(len(imgs), open, w, pageSize, fit, conf)
+}
+
+// imagesToPDF writes a PDF file to w with one page for each of n images.
+// Each image gets opened, embedded and closed before the next one is opened.
+func imagesToPDF(n int, open func(i int) (io.ReadCloser, error), w io.Writer, pageSize *pdfcpu.Dim, fit string, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.IMAGESTOPDF
+
+	dim := pageSize
+	if dim == nil {
+		dim = pdfcpu.PaperSize["A4"]
+	}
+
+	ctx, err := pdfcpu.CreateContextWithXRefTable(conf, dim)
+	if err != nil {
+		return err
+	}
+
+	pagesIndRef, err := ctx.Pages()
+	if err != nil {
+		return err
+	}
+
+	pagesDict, err := ctx.DereferenceDict(*pagesIndRef)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+
+		r, err := open(i)
+		if err != nil {
+			return err
+		}
+
+		indRef, err := pdfcpu.NewPageForImageFit(ctx.XRefTable, r, pagesIndRef, pageSize, fit)
+		if err != nil {
+			r.Close()
+			return err
+		}
+
+		if err = r.Close(); err != nil {
+			return err
+		}
+
+		if err = pdfcpu.AppendPageTree(indRef, 1, pagesDict); err != nil {
+			return err
+		}
+
+		ctx.PageCount++
+	}
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return nil
+}
+
+// ImagesToPDFFile writes a new PDF file outFile containing one page for each of imgFiles.
+// Supported image formats are JPEG, PNG and TIFF.
+func ImagesToPDFFile(imgFiles []string, outFile string, pageSize *pdfcpu.Dim, fit string, conf *pdfcpu.Configuration) (err error) {
+	open := func(i int) (io.ReadCloser, error) {
+		f, err := os.Open(imgFiles[i])
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{bufio.NewReader(f), f}, nil
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(outFile)
+			return
+		}
+		err = f.Close()
+	}()
+
+	log.CLI.Printf("writing %s...\n", outFile)
+
+	return imagesToPDF(len(imgFiles), open, f, pageSize, fit, conf)
 }
@@ -21,6 +21,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
@@ -51,3 +52,109 @@ func InfoFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
 	defer f.Close()
 	return Info(f, conf)
 }
+
+// GetInfo returns the title, author, subject, keywords, creator and producer of rs as recorded in the document info dict.
+func GetInfo(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.DocumentInfo, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return ctx.DocumentInfo()
+}
+
+// GetInfoFile returns the title, author, subject, keywords, creator and producer of inFile as recorded in the document info dict.
+func GetInfoFile(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.DocumentInfo, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return GetInfo(f, conf)
+}
+
+// SetInfo updates the document info dict of rs with the non nil fields of info and writes the result to w.
+// Empty fields get removed. Any XMP metadata of the document gets updated accordingly.
+func SetInfo(rs io.ReadSeeker, w io.Writer, info pdfcpu.DocumentInfo, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETINFO
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := ctx.SetDocumentInfo(info); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durSet := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durSet + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "set info, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetInfoFile updates the document info dict of inFile with the non nil fields of info and writes the result to outFile.
+// Empty fields get removed. Any XMP metadata of the document gets updated accordingly.
+func SetInfoFile(inFile, outFile string, info pdfcpu.DocumentInfo, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetInfo(f1, f2, info, conf)
+}
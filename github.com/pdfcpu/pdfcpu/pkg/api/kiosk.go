@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// KioskMode returns the page duration in seconds if rs opens in full screen mode
+// and all pages advance automatically after the same duration, or 0 otherwise.
+func KioskMode(rs io.ReadSeeker, conf *pdfcpu.Configuration) (float64, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTKIOSKMODE
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return pdfcpu.KioskMode(ctx)
+}
+
+// KioskModeFile returns the page duration in seconds if inFile opens in full screen mode
+// and all pages advance automatically after the same duration, or 0 otherwise.
+func KioskModeFile(inFile string, conf *pdfcpu.Configuration) (float64, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return KioskMode(f, conf)
+}
+
+// SetKioskMode makes all pages of rs advance automatically after seconds, opens the document in full screen mode
+// and writes the result to w. A duration of 0 clears the page durations and the full screen mode.
+func SetKioskMode(rs io.ReadSeeker, w io.Writer, seconds float64, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETKIOSKMODE
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := pdfcpu.SetKioskMode(ctx, seconds); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durKiosk := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durKiosk + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "kiosk mode, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetKioskModeFile makes all pages of inFile advance automatically after seconds, opens the document in full screen mode
+// and writes the result to outFile. A duration of 0 clears the page durations and the full screen mode.
+func SetKioskModeFile(inFile, outFile string, seconds float64, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetKioskMode(f1, f2, seconds, conf)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// LinkMap returns the clickable areas of the link annotations of page pageNr of rs
+// in the pixel space of this page rendered at dpi along with their targets.
+func LinkMap(rs io.ReadSeeker, pageNr, dpi int, conf *pdfcpu.Configuration) ([]pdfcpu.LinkArea, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.LinkMap(ctx, pageNr, dpi)
+}
+
+// LinkMapFile returns the clickable areas of the link annotations of page pageNr of inFile
+// in the pixel space of this page rendered at dpi along with their targets.
+func LinkMapFile(inFile string, pageNr, dpi int, conf *pdfcpu.Configuration) ([]pdfcpu.LinkArea, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LinkMap(f, pageNr, dpi, conf)
+}
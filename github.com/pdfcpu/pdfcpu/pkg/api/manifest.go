@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// SetManifest embeds the assembly manifest m into rs and writes the result to w.
+// Any existing manifest gets replaced.
+func SetManifest(rs io.ReadSeeker, w io.Writer, m pdfcpu.Manifest, conf *pdfcpu.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: SetManifest: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: SetManifest: Please provide w")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = ctx.SetManifest(m); err != nil {
+		return err
+	}
+
+	durAdd := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durAdd + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "set manifest, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetManifestFile embeds the assembly manifest m into inFile and writes the result to outFile.
+// Any existing manifest gets replaced.
+func SetManifestFile(inFile, outFile string, m pdfcpu.Manifest, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetManifest(f1, f2, m, conf)
+}
+
+// GetManifest returns the assembly manifest embedded in rs.
+func GetManifest(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.Manifest, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: GetManifest: Please provide rs")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.Manifest()
+}
+
+// GetManifestFile returns the assembly manifest embedded in inFile.
+func GetManifestFile(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.Manifest, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return GetManifest(f, conf)
+}
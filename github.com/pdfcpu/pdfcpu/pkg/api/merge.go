@@ -19,6 +19,8 @@ package api
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
@@ -37,6 +39,42 @@ func appendTo(rs io.ReadSeeker, ctxDest *pdfcpu.Context) error {
 	return pdfcpu.MergeXRefTables(ctxSource, ctxDest)
 }
 
+// appendWithBookmarks appends rsc to ctxDest's page tree replacing the outline of ctxDest
+// by a top level bookmark for each stream holding its outline.
+func appendWithBookmarks(rsc []io.ReadSeeker, titles []string, ctxDest *pdfcpu.Context) error {
+	if err := ctxDest.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	bm, err := ctxDest.MergedBookmark(titles[0], 0)
+	if err != nil {
+		return err
+	}
+	bms := []pdfcpu.Bookmark{bm}
+
+	for i, rs := range rsc {
+		ctxSource, _, _, err := readAndValidate(rs, ctxDest.Configuration, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := ctxSource.EnsurePageCount(); err != nil {
+			return err
+		}
+
+		bm, err := ctxSource.MergedBookmark(titles[i+1], ctxDest.PageCount)
+		if err != nil {
+			return err
+		}
+		bms = append(bms, bm)
+
+		if err := pdfcpu.MergeXRefTablesWithNamedDests(ctxSource, ctxDest); err != nil {
+			return err
+		}
+	}
+
+	return pdfcpu.AddBookmarks(ctxDest, bms, true)
+}
+
 // ReadSeekerCloser combines io.ReadSeeker and io.Closer
 type ReadSeekerCloser interface {
 	io.ReadSeeker
@@ -45,6 +83,22 @@ type ReadSeekerCloser interface {
 
 // Merge merges a sequence of PDF streams and writes the result to w.
 func Merge(rsc []io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	return merge(rsc, nil, w, conf)
+}
+
+// MergeWithBookmarks merges a sequence of PDF streams and writes the result to w.
+// The outline of each stream ends up below a new top level bookmark using the corresponding entry of titles,
+// named destinations defined by more than one stream get renamed.
+func MergeWithBookmarks(rsc []io.ReadSeeker, titles []string, w io.Writer, conf *pdfcpu.Configuration) error {
+	if len(titles) != len(rsc) {
+		return errors.New("pdfcpu: MergeWithBookmarks: Please provide a title for each stream")
+	}
+	return merge(rsc, titles, w, conf)
+}
+
+// merge merges rsc into the context of the first stream.
+// Any titles given lead to an outline made of the outlines of all streams.
+func merge(rsc []io.ReadSeeker, titles []string, w io.Writer, conf *pdfcpu.Configuration) error {
 	if rsc == nil {
 		return errors.New("pdfcpu: Merge: Please provide rsc")
 	}
@@ -63,11 +117,15 @@ func Merge(rsc []io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
 
 	ctxDest.EnsureVersionForWriting()
 
-	// Repeatedly merge files into fileDest's xref table.
-	for _, f := range rsc[1:] {
-		if err = appendTo(f, ctxDest); err != nil {
-			return err
+	if titles == nil {
+		// Repeatedly merge files into fileDest's xref table.
+		for _, f := range rsc[1:] {
+			if err = appendTo(f, ctxDest); err != nil {
+				return err
+			}
 		}
+	} else if err = appendWithBookmarks(rsc[1:], titles, ctxDest); err != nil {
+		return err
 	}
 
 	if err = OptimizeContext(ctxDest); err != nil {
@@ -87,6 +145,16 @@ func Merge(rsc []io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
 // This operation corresponds to file concatenation in the order specified by inFiles.
 // The first entry of inFiles serves as the destination context where all remaining files get merged into.
 func MergeCreateFile(inFiles []string, outFile string, conf *pdfcpu.Configuration) error {
+	return mergeCreateFile(inFiles, outFile, false, conf)
+}
+
+// MergeCreateWithBookmarksFile merges a sequence of inFiles and writes the result to outFile.
+// The outline of each file ends up below a new top level bookmark named after the file.
+func MergeCreateWithBookmarksFile(inFiles []string, outFile string, conf *pdfcpu.Configuration) error {
+	return mergeCreateFile(inFiles, outFile, true, conf)
+}
+
+func mergeCreateFile(inFiles []string, outFile string, withBookmarks bool, conf *pdfcpu.Configuration) error {
 	ff := []*os.File(nil)
 	for _, f := range inFiles {
 		log.CLI.Println(f)
@@ -124,6 +192,15 @@ func MergeCreateFile(inFiles []string, outFile string, conf *pdfcpu.Configuratio
 	}
 
 	log.CLI.Printf("writing %s...\n", outFile)
+
+	if withBookmarks {
+		titles := make([]string, len(inFiles))
+		for i, fn := range inFiles {
+			titles[i] = strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+		}
+		return MergeWithBookmarks(rs, titles, f, conf)
+	}
+
 	return Merge(rs, f, conf)
 }
 
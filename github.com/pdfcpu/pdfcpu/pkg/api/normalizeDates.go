@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// NormalizeDates rewrites malformed date strings of rs in canonical form and writes the result to w.
+// Dates get normalized before validation so that otherwise valid files pass.
+// It returns a description of each date that could not be parsed.
+func NormalizeDates(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) ([]string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.NORMALIZEDATES
+
+	fromStart := time.Now()
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	durRead := time.Since(fromStart).Seconds()
+	from := time.Now()
+
+	c, ss := ctx.NormalizeDates()
+	log.CLI.Printf("normalized %d dates\n", c)
+	for _, s := range ss {
+		log.CLI.Println(s)
+	}
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return ss, err
+		}
+	}
+
+	if err = OptimizeContext(ctx); err != nil {
+		return ss, err
+	}
+
+	durNormalize := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return ss, err
+	}
+
+	durWrite := durNormalize + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "normalize dates, write", durRead, 0, 0, durWrite, durTotal)
+
+	return ss, nil
+}
+
+// NormalizeDatesFile rewrites malformed date strings of inFile in canonical form and writes the result to outFile.
+// It returns a description of each date that could not be parsed.
+func NormalizeDatesFile(inFile, outFile string, conf *pdfcpu.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return NormalizeDates(f1, f2, conf)
+}
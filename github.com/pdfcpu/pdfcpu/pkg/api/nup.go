@@ -23,6 +23,7 @@ import (
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
 )
 
 // NUp rearranges PDF pages or images into page grids and writes the result to w.
@@ -120,3 +121,82 @@ func NUpFile(inFiles []string, outFile string, selectedPages []string, nup *pdfc
 
 	return NUp(f1, f2, inFiles, selectedPages, nup, conf)
 }
+
+// Booklet imposes selected pages of rs as a saddle stitch booklet and writes the result to w.
+// Pages of inner sheets get shifted towards the spine to compensate the creep configured by nup.
+func Booklet(rs io.ReadSeeker, w io.Writer, selectedPages []string, nup *pdfcpu.NUp, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.BOOKLET
+
+	if nup == nil {
+		return errors.New("pdfcpu: Booklet: Please provide nup")
+	}
+	if !nup.Booklet || nup.ImgInputFile {
+		return errors.New("pdfcpu: Booklet: Please provide a booklet configuration, see PDFBookletConfig")
+	}
+
+	log.Info.Printf("%s", nup)
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	if err = pdfcpu.NUpFromPDF(ctx, pages, nup); err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return nil
+}
+
+// BookletFile imposes selected pages of inFile as a saddle stitch booklet and writes the result to outFile.
+func BookletFile(inFile, outFile string, selectedPages []string, nup *pdfcpu.NUp, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	if f2, err = os.Create(outFile); err != nil {
+		f1.Close()
+		return err
+	}
+	log.CLI.Printf("writing %s...\n", outFile)
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		err = f1.Close()
+	}()
+
+	return Booklet(f1, f2, selectedPages, nup, conf)
+}
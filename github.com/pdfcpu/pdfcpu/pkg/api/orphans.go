@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ListOrphanObjects returns a list of all objects of rs not reachable from its trailer
+// along with their types and approximate sizes.
+func ListOrphanObjects(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	// Optimization would get rid of some orphans.
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{}
+	for _, o := range ctx.OrphanObjects() {
+		ss = append(ss, o.String())
+	}
+
+	return ss, nil
+}
+
+// ListOrphanObjectsFile returns a list of all objects of inFile not reachable from its trailer
+// along with their types and approximate sizes.
+func ListOrphanObjectsFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListOrphanObjects(f, conf)
+}
+
+// GarbageCollect removes all objects of rs not reachable from its trailer and writes the result to w.
+// Unlike Optimize this neither removes duplicate resources nor touches any stream content.
+func GarbageCollect(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.GARBAGECOLLECT
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, err := readAndValidate(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	oo, err := ctx.GarbageCollect()
+	if err != nil {
+		return err
+	}
+	log.CLI.Printf("removed %d orphans\n", len(oo))
+
+	durGC := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durGC + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "garbage collect, write", durRead, durVal, 0, durWrite, durTotal)
+
+	return nil
+}
+
+// GarbageCollectFile removes all objects of inFile not reachable from its trailer and writes the result to outFile.
+func GarbageCollectFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return GarbageCollect(f1, f2, conf)
+}
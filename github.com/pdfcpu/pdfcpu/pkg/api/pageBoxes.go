@@ -0,0 +1,163 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// SetPageBoxes sets the page boundaries specified by boxes for selected pages of rs and writes the result to w.
+// Nothing gets written if any page would violate the nesting constraints of its boxes.
+func SetPageBoxes(rs io.ReadSeeker, w io.Writer, boxes pdfcpu.PageBoxes, selectedPages []string, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SETPAGEBOXES
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	from := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	if err = pdfcpu.SetPageBoxes(ctx, pages, boxes); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durBoxes := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durBoxes + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "set page boxes, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SetPageBoxesFile sets the page boundaries specified by boxes for selected pages of inFile and writes the result to outFile.
+func SetPageBoxesFile(inFile, outFile string, boxes pdfcpu.PageBoxes, selectedPages []string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return SetPageBoxes(f1, f2, boxes, selectedPages, conf)
+}
+
+// PageBoxes returns the media, crop, bleed, trim and art box of selected pages of rs keyed by page number.
+// Missing boxes are reported using their default values.
+func PageBoxes(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) (map[int]*pdfcpu.PageBoxes, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTPAGEBOXES
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[int]*pdfcpu.PageBoxes{}
+	for i, v := range pages {
+		if !v {
+			continue
+		}
+		if m[i], err = ctx.PageBoundaries(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// PageBoxesFile returns the media, crop, bleed, trim and art box of selected pages of inFile keyed by page number.
+func PageBoxesFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) (map[int]*pdfcpu.PageBoxes, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return PageBoxes(f, selectedPages, conf)
+}
@@ -114,6 +114,92 @@ func InsertPagesFile(inFile, outFile string, selectedPages []string, before bool
 	return InsertPages(f1, f2, selectedPages, before, conf)
 }
 
+// InsertBlankPages inserts a blank page before each page of rs at positions and writes the result to w.
+// Positions refer to the original page numbering, PageCount+1 appends a blank page.
+// If dim is nil each blank page gets the size of the page following it.
+func InsertBlankPages(rs io.ReadSeeker, w io.Writer, positions []int, dim *pdfcpu.Dim, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.INSERTBLANKPAGES
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := ctx.InsertBlankPagesAt(positions, dim); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durInsert := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durInsert + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "insert blank pages, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// InsertBlankPagesFile inserts a blank page before each page of inFile at positions and writes the result to outFile.
+// Positions refer to the original page numbering, PageCount+1 appends a blank page.
+// If dim is nil each blank page gets the size of the page following it.
+func InsertBlankPagesFile(inFile, outFile string, positions []int, dim *pdfcpu.Dim, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return InsertBlankPages(f1, f2, positions, dim, conf)
+}
+
 // RemovePages removes selected pages from rs and writes the result to w.
 func RemovePages(rs io.ReadSeeker, w io.Writer, selectedPages []string, conf *pdfcpu.Configuration) error {
 	if conf == nil {
@@ -200,6 +286,35 @@ func RemovePagesFile(inFile, outFile string, selectedPages []string, conf *pdfcp
 	return RemovePages(f1, f2, selectedPages, conf)
 }
 
+// AppendPage appends p as new last page to rws using an incremental update.
+// Only the file portions needed for locating the page tree are read
+// and existing file content remains untouched.
+func AppendPage(rws io.ReadWriteSeeker, p pdfcpu.Page, conf *pdfcpu.Configuration) error {
+	if rws == nil {
+		return errors.New("pdfcpu: AppendPage: Please provide rws")
+	}
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	return pdfcpu.AppendPage(rws, p, conf)
+}
+
+// AppendPageFile appends p as new last page to inFile using an incremental update.
+func AppendPageFile(inFile string, p pdfcpu.Page, conf *pdfcpu.Configuration) error {
+	f, err := os.OpenFile(inFile, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	if err = AppendPage(f, p, conf); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
 // PageCount returns rs's page count.
 func PageCount(rs io.ReadSeeker, conf *pdfcpu.Configuration) (int, error) {
 	ctx, err := ReadContext(rs, conf)
@@ -212,18 +327,44 @@ func PageCount(rs io.ReadSeeker, conf *pdfcpu.Configuration) (int, error) {
 	return ctx.PageCount, nil
 }
 
-// PageCountFile returns inFile's page count.
-func PageCountFile(inFile string) (int, error) {
-	f, err := os.Open(inFile)
+// PageCountFile returns inFile's page count as recorded in the root of its page tree.
+// Only the cross reference table, the catalog and the page tree root get read.
+func PageCountFile(inFile string, conf *pdfcpu.Configuration) (int, error) {
+	qi, err := QuickInfoFile(inFile, conf)
 	if err != nil {
 		return 0, err
 	}
+	if qi.PageCount < 0 {
+		return 0, errors.New("pdfcpu: page count unavailable, please provide the correct password")
+	}
+	return qi.PageCount, nil
+}
+
+// QuickInfo returns the page count, PDF version, encryption status and AcroForm presence of rs
+// without reading all objects. For encrypted files the page count is reported as -1 if unavailable.
+func QuickInfo(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.QuickInfo, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.QUICKINFO
+
+	return pdfcpu.ReadQuickInfo(rs, conf)
+}
+
+// QuickInfoFile returns the page count, PDF version, encryption status and AcroForm presence of inFile
+// without reading all objects.
+func QuickInfoFile(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.QuickInfo, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
-	return PageCount(f, pdfcpu.NewDefaultConfiguration())
+	return QuickInfo(f, conf)
 }
 
-// PageDims returns a sorted slice of mediaBox dimensions for rs.
+// PageDims returns a sorted slice of the dimensions of the visible page regions for rs
+// taking into account crop box and page rotation.
 func PageDims(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.Dim, error) {
 	ctx, err := ReadContext(rs, conf)
 	if err != nil {
@@ -241,7 +382,8 @@ func PageDims(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]pdfcpu.Dim, error
 	return pd, nil
 }
 
-// PageDimsFile returns a sorted slice of mediaBox dimensions for inFile.
+// PageDimsFile returns a sorted slice of the dimensions of the visible page regions for inFile
+// taking into account crop box and page rotation.
 func PageDimsFile(inFile string) ([]pdfcpu.Dim, error) {
 	f, err := os.Open(inFile)
 	if err != nil {
@@ -251,3 +393,115 @@ func PageDimsFile(inFile string) ([]pdfcpu.Dim, error) {
 
 	return PageDims(f, pdfcpu.NewDefaultConfiguration())
 }
+
+// PageSizeHistogram groups the pages of rs by their visible page size and orientation
+// taking into account crop box and page rotation.
+// Pages deviating by no more than conf.PageSizeTolerance points get grouped together.
+func PageSizeHistogram(rs io.ReadSeeker, conf *pdfcpu.Configuration) (map[string]pdfcpu.PageSizeGroup, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.PageSizeHistogram(conf.PageSizeTolerance)
+}
+
+// PageSizeHistogramFile groups the pages of inFile by their visible page size and orientation
+// taking into account crop box and page rotation.
+func PageSizeHistogramFile(inFile string, conf *pdfcpu.Configuration) (map[string]pdfcpu.PageSizeGroup, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return PageSizeHistogram(f, conf)
+}
+
+// ReorderPages rearranges the pages of rs according to the permutation order and writes the result to w.
+// Page order[i-1] becomes page i. Outline destinations and internal links follow their pages.
+func ReorderPages(rs io.ReadSeeker, w io.Writer, order []int, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.REORDERPAGES
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err := ctx.ReorderPages(order); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durReorder := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durReorder + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "reorder pages, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// ReorderPagesFile rearranges the pages of inFile according to the permutation order and writes the result to outFile.
+// Page order[i-1] becomes page i. Outline destinations and internal links follow their pages.
+func ReorderPagesFile(inFile, outFile string, order []int, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return ReorderPages(f1, f2, order, conf)
+}
@@ -0,0 +1,52 @@
+/*
+	Copyright 2020 The pdfcpu Authors.
+
+	Licensed under the Apache License, Version 2.0 (the "License");
+	you may not use this file except in compliance with the License.
+	You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+	distributed under the License is distributed on an "AS IS" BASIS,
+	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+	See the License for the specific language governing permissions and
+	limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// CheckPDFA returns the violations of the PDF/A conformance level (1b or 2b) by rs.
+// A conforming document yields an empty slice.
+func CheckPDFA(rs io.ReadSeeker, level string, conf *pdfcpu.Configuration) ([]pdfcpu.ConformanceIssue, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.CHECKPDFA
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.CheckPDFA(level)
+}
+
+// CheckPDFAFile returns the violations of the PDF/A conformance level (1b or 2b) by inFile.
+// A conforming document yields an empty slice.
+func CheckPDFAFile(inFile string, level string, conf *pdfcpu.Configuration) ([]pdfcpu.ConformanceIssue, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return CheckPDFA(f, level, conf)
+}
@@ -166,3 +166,33 @@ func GetPermissionsFile(inFile string, conf *pdfcpu.Configuration) (*int16, erro
 
 	return GetPermissions(f, conf)
 }
+
+// GetPermissionFlags decodes the user access permissions of rs.
+// The result is nil for unencrypted files.
+func GetPermissionFlags(rs io.ReadSeeker, conf *pdfcpu.Configuration) (*pdfcpu.PermissionFlags, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if ctx.E == nil {
+		// Full access - permissions don't apply.
+		return nil, nil
+	}
+	pf := pdfcpu.NewPermissionFlags(ctx.E.P, ctx.E.R)
+	return &pf, nil
+}
+
+// GetPermissionFlagsFile decodes the user access permissions of inFile.
+// The result is nil for unencrypted files.
+func GetPermissionFlagsFile(inFile string, conf *pdfcpu.Configuration) (*pdfcpu.PermissionFlags, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return GetPermissionFlags(f, conf)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// Redline highlights the text of rsNew that changed relative to rsOld and writes the result to w.
+// Both documents are expected to share their page layouts.
+func Redline(rsOld, rsNew io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.REDLINE
+
+	ctxOld, _, _, err := readAndValidate(rsOld, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rsNew, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	n, err := ctx.Redline(ctxOld)
+	if err != nil {
+		return err
+	}
+	log.CLI.Printf("%d changed lines\n", n)
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durRedline := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durRedline + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "redline, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// RedlineFile highlights the text of newFile that changed relative to oldFile and writes the result to outFile.
+// Both documents are expected to share their page layouts.
+func RedlineFile(oldFile, newFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	var f0, f1, f2 *os.File
+
+	if f0, err = os.Open(oldFile); err != nil {
+		return err
+	}
+	defer f0.Close()
+
+	if f1, err = os.Open(newFile); err != nil {
+		return err
+	}
+
+	tmpFile := newFile + ".tmp"
+	if outFile != "" && newFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", newFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || newFile == outFile {
+			if err = os.Rename(tmpFile, newFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return Redline(f0, f1, f2, conf)
+}
@@ -114,3 +114,112 @@ func RotateFile(inFile, outFile string, rotation int, selectedPages []string, co
 
 	return Rotate(f1, f2, rotation, selectedPages, conf)
 }
+
+// ListRotatedPages returns a list of all pages of rs with a non zero rotation along with their rotation.
+func ListRotatedPages(rs io.ReadSeeker, conf *pdfcpu.Configuration) ([]string, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.LISTROTATEDPAGES
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.RotatedPagesList(ctx)
+}
+
+// ListRotatedPagesFile returns a list of all pages of inFile with a non zero rotation along with their rotation.
+func ListRotatedPagesFile(inFile string, conf *pdfcpu.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListRotatedPages(f, conf)
+}
+
+// NormalizeRotation bakes the rotation of all pages of rs into their content, sets their rotation to 0 and writes the result to w.
+// Each page including its annotations displays as before.
+func NormalizeRotation(rs io.ReadSeeker, w io.Writer, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.NORMALIZEROTATION
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	if err = pdfcpu.NormalizeRotation(ctx); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durNormalize := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != pdfcpu.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durNormalize + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "normalize rotation, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// NormalizeRotationFile bakes the rotation of all pages of inFile into their content, sets their rotation to 0 and writes the result to outFile.
+// Each page including its annotations displays as before.
+func NormalizeRotationFile(inFile, outFile string, conf *pdfcpu.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			os.Remove(tmpFile)
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			if err = os.Rename(tmpFile, inFile); err != nil {
+				return
+			}
+		}
+	}()
+
+	return NormalizeRotation(f1, f2, conf)
+}
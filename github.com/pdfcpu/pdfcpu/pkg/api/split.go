@@ -26,6 +26,7 @@ import (
 
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
 )
 
 func spanFileName(fileName string, from, thru int) string {
@@ -80,13 +81,46 @@ func writePageSpan(ctx *pdfcpu.Context, from, thru int, outDir, fileName string,
 	return WriteContextFile(ctxNew, outFile)
 }
 
-func writePageSpansSplitAlongBookmarks(ctx *pdfcpu.Context, outDir string) error {
-	bms, err := ctx.BookmarksForOutlineLevel1()
+// bookmarkFileName returns a file name for a bookmark title safe to use on common file systems.
+func bookmarkFileName(title string) string {
+	fn := strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, title)
+	fn = strings.Trim(fn, " .")
+	if fn == "" {
+		fn = "bookmark"
+	}
+	return fn
+}
+
+// bookmarkFileNames returns unique file names for the titles of bms.
+// Colliding names get a numeric suffix, ignoring case for the sake of case insensitive file systems.
+func bookmarkFileNames(bms []pdfcpu.Bookmark) []string {
+	used := map[string]bool{}
+	ss := make([]string, len(bms))
+	for i, bm := range bms {
+		fn := bookmarkFileName(bm.Title)
+		s := fn
+		for j := 2; used[strings.ToLower(s)]; j++ {
+			s = fn + "_" + strconv.Itoa(j)
+		}
+		used[strings.ToLower(s)] = true
+		ss[i] = s
+	}
+	return ss
+}
+
+func writePageSpansSplitAlongBookmarks(ctx *pdfcpu.Context, outDir string, depth int) error {
+	bms, err := ctx.BookmarksForOutlineLevel(depth)
 	if err != nil {
 		return err
 	}
-	for _, bm := range bms {
-		fileName := bm.Title
+	fileNames := bookmarkFileNames(bms)
+	for i, bm := range bms {
+		fileName := fileNames[i]
 		from := bm.PageFrom
 		thru := bm.PageThru
 		if thru == 0 {
@@ -102,7 +136,7 @@ func writePageSpansSplitAlongBookmarks(ctx *pdfcpu.Context, outDir string) error
 
 func writePageSpans(ctx *pdfcpu.Context, span int, outDir, fileName string) error {
 	if span == 0 {
-		return writePageSpansSplitAlongBookmarks(ctx, outDir)
+		return writePageSpansSplitAlongBookmarks(ctx, outDir, 1)
 	}
 
 	forBookmark := false
@@ -129,6 +163,80 @@ func writePageSpans(ctx *pdfcpu.Context, span int, outDir, fileName string) erro
 	return nil
 }
 
+// pageSpanSize returns the size of a PDF file made of the pages from thru of ctx.
+// This includes all resources shared by these pages.
+func pageSpanSize(ctx *pdfcpu.Context, from, thru int) (int64, error) {
+	ctxNew, err := ctx.ExtractPages(PagesForPageRange(from, thru), false)
+	if err != nil {
+		return 0, err
+	}
+
+	var c pdfcpu.ByteCounter
+	if err := WriteContext(ctxNew, &c); err != nil {
+		return 0, err
+	}
+
+	return int64(c), nil
+}
+
+// maxPageSpan returns the last page of the longest page span starting at from not exceeding maxBytes.
+// Spans are measured by writing them, growing exponentially followed by a binary search.
+func maxPageSpan(ctx *pdfcpu.Context, from int, maxBytes int64) (int, error) {
+	size, err := pageSpanSize(ctx, from, from)
+	if err != nil {
+		return 0, err
+	}
+	if size > maxBytes {
+		log.CLI.Printf("warning: page %d exceeds %d bytes (%d bytes)\n", from, maxBytes, size)
+		return from, nil
+	}
+
+	// Page span from-fits does not exceed maxBytes, from-tooBig does or is beyond the last page.
+	fits, tooBig := from, from+1
+	for step := 1; tooBig <= ctx.PageCount; step *= 2 {
+		if size, err = pageSpanSize(ctx, from, tooBig); err != nil {
+			return 0, err
+		}
+		if size > maxBytes {
+			break
+		}
+		fits = tooBig
+		tooBig = fits + step
+	}
+	if tooBig > ctx.PageCount+1 {
+		tooBig = ctx.PageCount + 1
+	}
+
+	for tooBig-fits > 1 {
+		mid := (fits + tooBig) / 2
+		if size, err = pageSpanSize(ctx, from, mid); err != nil {
+			return 0, err
+		}
+		if size > maxBytes {
+			tooBig = mid
+		} else {
+			fits = mid
+		}
+	}
+
+	return fits, nil
+}
+
+func writePageSpansBySize(ctx *pdfcpu.Context, maxBytes int64, outDir, fileName string) error {
+	forBookmark := false
+	for from := 1; from <= ctx.PageCount; {
+		thru, err := maxPageSpan(ctx, from, maxBytes)
+		if err != nil {
+			return err
+		}
+		if err := writePageSpan(ctx, from, thru, outDir, fileName, forBookmark); err != nil {
+			return err
+		}
+		from = thru + 1
+	}
+	return nil
+}
+
 // Split generates a sequence of PDF files in outDir for the PDF stream read from rs obeying given split span.
 // If span == 1 splitting results in single page PDFs.
 // If span == 0 we split along given bookmarks (level 1 only).
@@ -184,3 +292,113 @@ func SplitFile(inFile, outDir string, span int, conf *pdfcpu.Configuration) erro
 
 	return Split(f, outDir, filepath.Base(inFile), span, conf)
 }
+
+// SplitBySize generates a sequence of PDF files in outDir for the PDF stream read from rs
+// each made of as many consecutive pages as fit into maxBytes.
+// A page exceeding maxBytes on its own ends up in a separate file.
+func SplitBySize(rs io.ReadSeeker, outDir, fileName string, maxBytes int64, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SPLIT
+
+	if maxBytes <= 0 {
+		return errors.Errorf("pdfcpu: invalid maximum file size: %d", maxBytes)
+	}
+
+	fromStart := time.Now()
+
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+
+	if err = writePageSpansBySize(ctx, maxBytes, outDir, fileName); err != nil {
+		return err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "split", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SplitBySizeFile generates a sequence of PDF files in outDir for inFile
+// each made of as many consecutive pages as fit into maxBytes.
+// A page exceeding maxBytes on its own ends up in a separate file.
+func SplitBySizeFile(inFile, outDir string, maxBytes int64, conf *pdfcpu.Configuration) (err error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	log.CLI.Printf("splitting %s to %s/...\n", inFile, outDir)
+
+	defer func() {
+		if err != nil {
+			f.Close()
+			return
+		}
+		err = f.Close()
+	}()
+
+	return SplitBySize(f, outDir, filepath.Base(inFile), maxBytes, conf)
+}
+
+// SplitByBookmark generates a PDF file in outDir for each bookmark at outline level depth of the PDF stream read from rs.
+// Level 1 splits along the top level outline items, each file is named after the bookmark title.
+func SplitByBookmark(rs io.ReadSeeker, outDir string, depth int, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.SPLIT
+
+	fromStart := time.Now()
+
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+
+	if err = writePageSpansSplitAlongBookmarks(ctx, outDir, depth); err != nil {
+		return err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "split", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// SplitByBookmarkFile generates a PDF file in outDir for each bookmark at outline level depth of inFile.
+// Level 1 splits along the top level outline items, each file is named after the bookmark title.
+func SplitByBookmarkFile(inFile, outDir string, depth int, conf *pdfcpu.Configuration) (err error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	log.CLI.Printf("splitting %s to %s/...\n", inFile, outDir)
+
+	defer func() {
+		if err != nil {
+			f.Close()
+			return
+		}
+		err = f.Close()
+	}()
+
+	return SplitByBookmark(f, outDir, depth, conf)
+}
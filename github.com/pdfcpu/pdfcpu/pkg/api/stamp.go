@@ -281,6 +281,19 @@ func PDFWatermark(fileName, desc string, onTop, update bool) (*pdfcpu.Watermark,
 	return wm, nil
 }
 
+// PDFWatermarkTemplate returns a PDF watermark configuration along with the parsed PDF source
+// ready to be applied to many documents by calling AddWatermarks or AddWatermarksFile repeatedly.
+func PDFWatermarkTemplate(fileName, desc string, onTop, update bool) (*pdfcpu.Watermark, error) {
+	wm, err := PDFWatermark(fileName, desc, onTop, update)
+	if err != nil {
+		return nil, err
+	}
+	if err := wm.LoadPDF(); err != nil {
+		return nil, err
+	}
+	return wm, nil
+}
+
 // AddTextWatermarksFile adds text stamps/watermarks to all selected pages of inFile and writes the result to outFile.
 func AddTextWatermarksFile(inFile, outFile string, selectedPages []string, onTop bool, text, desc string, conf *pdfcpu.Configuration) error {
 	wm, err := TextWatermark(text, desc, onTop, false)
@@ -308,6 +321,18 @@ func AddPDFWatermarksFile(inFile, outFile string, selectedPages []string, onTop
 	return AddWatermarksFile(inFile, outFile, selectedPages, wm, conf)
 }
 
+// OverlayFile draws pages of overlayFile scaled to the page size over or under the selected pages of baseFile
+// and writes the result to outFile.
+// Base pages are paired with overlay pages by page number and any base pages beyond the overlay page count get the last overlay page.
+// Use overlayFile:n to repeat overlay page n on all selected pages instead.
+func OverlayFile(baseFile, overlayFile, outFile string, overlayOnTop bool, selectedPages []string, conf *pdfcpu.Configuration) error {
+	wm, err := PDFWatermark(overlayFile, "scalefactor:1 rel, rotation:0", overlayOnTop, false)
+	if err != nil {
+		return err
+	}
+	return AddWatermarksFile(baseFile, outFile, selectedPages, wm, conf)
+}
+
 // UpdateTextWatermarksFile adds text stamps/watermarks to all selected pages of inFile and writes the result to outFile.
 func UpdateTextWatermarksFile(inFile, outFile string, selectedPages []string, onTop bool, text, desc string, conf *pdfcpu.Configuration) error {
 	wm, err := TextWatermark(text, desc, onTop, true)
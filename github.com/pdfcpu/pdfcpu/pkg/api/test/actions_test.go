@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// writeActionsTestFile adds a mix of dangerous and navigation actions to inFile,
+// writes the result to outFile and returns the expected action list.
+func writeActionsTestFile(t *testing.T, msg, inFile, outFile string) []string {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	launch := pdf.Dict{
+		"S":    pdf.Name("Launch"),
+		"F":    pdf.StringLiteral("calc.exe"),
+		"Next": pdf.Dict{"S": pdf.Name("URI"), "URI": pdf.StringLiteral("https://pdfcpu.io")},
+	}
+	launchIndRef, err := ctx.IndRefForNewObject(launch)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict.Update("OpenAction", *launchIndRef)
+	rootDict.Update("AA", pdf.Dict{"WC": pdf.Dict{"S": pdf.Name("Named"), "N": pdf.Name("FirstPage")}})
+
+	pageDict, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _ := ctx.NewStreamDictForBuf([]byte{0x00, 0x00})
+	sd.InsertName("Type", "Sound")
+	sd.Insert("R", pdf.Integer(8000))
+	soundIndRef, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pageDict.Update("AA", pdf.Dict{"O": pdf.Dict{"S": pdf.Name("Sound"), "Sound": *soundIndRef}})
+
+	annotIndRef, err := ctx.IndRefForNewObject(pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    pdf.NewIntegerArray(0, 0, 100, 100),
+		"A":       pdf.Dict{"S": pdf.Name("ImportData"), "F": pdf.StringLiteral("data.fdf")},
+	})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pageDict.Update("Annots", append(pageDict.ArrayEntry("Annots"), *annotIndRef))
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	return []string{
+		fmt.Sprintf("OpenAction: Launch (obj#%d)", launchIndRef.ObjectNumber),
+		"OpenAction Next: URI",
+		"AA/WC: Named",
+		"page 1 AA/O: Sound",
+		fmt.Sprintf("page 1 annot obj#%d A: ImportData", annotIndRef.ObjectNumber),
+	}
+}
+
+func TestListAndStripActions(t *testing.T) {
+	msg := "TestListAndStripActions"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "actions.pdf")
+
+	want := writeActionsTestFile(t, msg, inFile, outFile)
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	got, err := pdf.ListActions(ctx)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s:\nwant: %v\ngot:  %v\n", msg, want, got)
+	}
+
+	n, err := ctx.StripActions()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != 3 {
+		t.Fatalf("%s: want 3 actions removed, got %d\n", msg, n)
+	}
+
+	if err := api.StripActionsFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Navigation actions survive, the URI action takes over OpenAction.
+	if got, err = api.ListActionsFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want = []string{"OpenAction: URI", "AA/WC: Named"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s:\nwant: %v\ngot:  %v\n", msg, want, got)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// firstTwoPages returns the indirect references of the first two pages of a flat page tree.
+func firstTwoPages(t *testing.T, msg string, ctx *pdf.Context) (pdf.IndirectRef, pdf.IndirectRef) {
+	t.Helper()
+
+	root, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*root)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	kids := d.ArrayEntry("Kids")
+	if len(kids) < 2 {
+		t.Fatalf("%s: want flat page tree\n", msg)
+	}
+
+	return kids[0].(pdf.IndirectRef), kids[1].(pdf.IndirectRef)
+}
+
+func TestFixAnnotationPageRefs(t *testing.T) {
+	msg := "TestFixAnnotationPageRefs"
+	inFile := filepath.Join(outDir, "annotWrongP.pdf")
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Put an annotation on page 2 referring to page 1.
+	page1, page2 := firstTwoPages(t, msg, ctx)
+
+	annotIndRef, err := ctx.IndRefForNewObject(pdf.Dict{
+		"Type":     pdf.Name("Annot"),
+		"Subtype":  pdf.Name("Text"),
+		"Rect":     pdf.Array{pdf.Integer(100), pdf.Integer(100), pdf.Integer(120), pdf.Integer(120)},
+		"Contents": pdf.StringLiteral("Note"),
+		"P":        page1,
+	})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, err := ctx.DereferenceDict(page2)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Annots", append(annots, *annotIndRef))
+
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.FixAnnotationPageRefsFile(inFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// ReadContextFile validates.
+	if ctx, err = api.ReadContextFile(inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	_, page2 = firstTwoPages(t, msg, ctx)
+	d, _ = ctx.DereferenceDict(page2)
+	annots, _ = ctx.DereferenceArray(d["Annots"])
+
+	for _, o := range annots {
+		ad, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if p := ad.IndirectRefEntry("P"); p != nil && p.ObjectNumber != page2.ObjectNumber {
+			t.Fatalf("%s: want P obj#%d, got: obj#%d\n", msg, page2.ObjectNumber, p.ObjectNumber)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestListAnnotations(t *testing.T) {
+	msg := "TestListAnnotations"
+	outFile := filepath.Join(outDir, "listAnnotations.pdf")
+
+	writeLinkMapTestFile(t, msg, outFile, 0)
+
+	aa, err := api.ListAnnotationsFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(aa) != 2 {
+		t.Fatalf("%s: want 2 annotations, got %d: %v\n", msg, len(aa), aa)
+	}
+	uri, goTo := aa[0], aa[1]
+	if uri.Subtype != "Link" || uri.Page != 1 || uri.URI != "https://pdfcpu.io" || uri.Rect.String() != pdf.Rect(10, 20, 110, 70).String() {
+		t.Fatalf("%s: unexpected URI link: %s\n", msg, uri)
+	}
+	if goTo.Subtype != "Link" || goTo.Page != 1 || goTo.DestPage != 2 || goTo.URI != "" {
+		t.Fatalf("%s: unexpected go-to link: %s\n", msg, goTo)
+	}
+
+	// Page 2 has no annotations.
+	if aa, err = api.ListAnnotationsFile(outFile, []string{"2"}, nil); err != nil || len(aa) != 0 {
+		t.Fatalf("%s: want no annotations for page 2, got %v %v\n", msg, aa, err)
+	}
+
+	// All kinds of annotations.
+	xRefTable, err := pdf.CreateAnnotationDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	outFile = filepath.Join(outDir, "listAnnotationsDemo.pdf")
+	if err := api.CreatePDFFile(xRefTable, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if aa, err = api.ListAnnotationsFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	subtypes := map[string]int{}
+	for _, a := range aa {
+		subtypes[a.Subtype]++
+		if a.IndRef == nil {
+			t.Fatalf("%s: missing indirect ref: %s\n", msg, a)
+		}
+		if a.Subtype == "Text" && a.Contents != "Text Annotation" {
+			t.Fatalf("%s: unexpected text annotation: %s\n", msg, a)
+		}
+	}
+	for _, st := range []string{"Text", "Link", "Highlight", "Widget", "FileAttachment"} {
+		if subtypes[st] == 0 {
+			t.Fatalf("%s: missing %s annotation in %v\n", msg, st, subtypes)
+		}
+	}
+}
+
+func TestRemoveAnnotations(t *testing.T) {
+	msg := "TestRemoveAnnotations"
+
+	xRefTable, err := pdf.CreateAnnotationDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFile := filepath.Join(outDir, "removeAnnotationsIn.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	aa, err := api.ListAnnotationsFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := 0
+	for _, a := range aa {
+		if a.Subtype == "Text" || a.Subtype == "Highlight" {
+			want++
+		}
+	}
+
+	// Remove selected types only.
+	outFile := filepath.Join(outDir, "removeAnnotationsByType.pdf")
+	n, err := api.RemoveAnnotationsFile(inFile, outFile, nil, []string{"Text", "Highlight"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != want {
+		t.Fatalf("%s: want %d removed annotations, got %d\n", msg, want, n)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := api.ListAnnotationsFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(bb) != len(aa)-n {
+		t.Fatalf("%s: want %d annotations left, got %d\n", msg, len(aa)-n, len(bb))
+	}
+	for _, b := range bb {
+		if b.Subtype == "Text" || b.Subtype == "Highlight" {
+			t.Fatalf("%s: annotation not removed: %s\n", msg, b)
+		}
+	}
+
+	// Remove all.
+	outFile = filepath.Join(outDir, "removeAnnotationsAll.pdf")
+	if n, err = api.RemoveAnnotationsFile(inFile, outFile, nil, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != len(aa) {
+		t.Fatalf("%s: want %d removed annotations, got %d\n", msg, len(aa), n)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if bb, err = api.ListAnnotationsFile(outFile, nil, nil); err != nil || len(bb) != 0 {
+		t.Fatalf("%s: want no annotations, got %v %v\n", msg, bb, err)
+	}
+
+	// Removing widgets cleans up the form.
+	if xRefTable, err = pdf.CreateAcroFormDemoXRef(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFile = filepath.Join(outDir, "removeWidgetsIn.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	outFile = filepath.Join(outDir, "removeWidgets.pdf")
+	if n, err = api.RemoveAnnotationsFile(inFile, outFile, nil, []string{"Widget"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n == 0 {
+		t.Fatalf("%s: no widgets removed\n", msg)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := rootDict.Find("AcroForm"); found {
+		t.Fatalf("%s: AcroForm not removed\n", msg)
+	}
+
+	// Only the removed widgets and their appearance streams get freed.
+	if ctx, err = api.ReadContextFile(inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var objNrs []int
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		d, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		annots, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, o := range annots {
+			ad, err := ctx.DereferenceDict(o)
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			ap, err := ctx.DereferenceDict(ad["AP"])
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			if ir, ok := ap["N"].(pdf.IndirectRef); ok && *ad.Subtype() == "Widget" {
+				objNrs = append(objNrs, o.(pdf.IndirectRef).ObjectNumber.Value(), ir.ObjectNumber.Value())
+			}
+		}
+	}
+	if len(objNrs) == 0 {
+		t.Fatalf("%s: no widget appearance streams\n", msg)
+	}
+	ir, err := ctx.IndRefForNewObject(pdf.Dict{})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, err := ctx.RemoveAnnotations(nil, []string{"Widget"}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, objNr := range objNrs {
+		if entry, found := ctx.FindTableEntryLight(objNr); !found || !entry.Free {
+			t.Fatalf("%s: obj#%d not freed\n", msg, objNr)
+		}
+	}
+	if entry, found := ctx.FindTableEntryForIndRef(ir); !found || entry.Free {
+		t.Fatalf("%s: unlinked obj#%d freed\n", msg, ir.ObjectNumber.Value())
+	}
+}
+
+func TestAddLinkAnnotations(t *testing.T) {
+	msg := "TestAddLinkAnnotations"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "addLinkAnnotations.pdf")
+
+	ll := []pdf.LinkAnnotation{
+		{Page: 1, Rect: pdf.Rect(10, 20, 110, 70), URI: "https://pdfcpu.io"},
+		{Page: 1, Rect: pdf.Rect(50, 50, 200, 100), DestPage: 2},
+		{Page: 2, Rect: pdf.Rect(0, 0, 100, 100), DestPage: 1, Border: true},
+	}
+
+	if err := api.AddLinkAnnotationsFile(inFile, outFile, ll, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	aa, err := api.ListAnnotationsFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	links := []pdf.AnnotationInfo{}
+	for _, a := range aa {
+		if a.Subtype == "Link" {
+			links = append(links, a)
+		}
+	}
+	if len(links) < len(ll) {
+		t.Fatalf("%s: want at least %d links, got %d\n", msg, len(ll), len(links))
+	}
+	links = links[len(links)-len(ll):]
+	for i, la := range ll {
+		got := links[i]
+		if got.Page != la.Page || got.URI != la.URI || got.DestPage != la.DestPage || got.Rect.String() != la.Rect.String() {
+			t.Fatalf("%s: link %d: want %v, got %s\n", msg, i, la, got)
+		}
+	}
+
+	// Links need exactly one target.
+	for _, la := range []pdf.LinkAnnotation{
+		{Page: 1, Rect: pdf.Rect(0, 0, 10, 10)},
+		{Page: 1, Rect: pdf.Rect(0, 0, 10, 10), DestPage: 2, URI: "https://pdfcpu.io"},
+		{Page: 1, Rect: pdf.Rect(0, 0, 10, 10), Dest: "missing"},
+		{Page: 1, Rect: pdf.Rect(0, 0, 10, 10), DestPage: 1000},
+		{Page: 0, Rect: pdf.Rect(0, 0, 10, 10), DestPage: 1},
+	} {
+		if err := api.AddLinkAnnotationsFile(inFile, outFile, []pdf.LinkAnnotation{la}, nil); err == nil {
+			t.Fatalf("%s: want error for %v\n", msg, la)
+		}
+	}
+}
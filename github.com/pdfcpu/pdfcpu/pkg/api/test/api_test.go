@@ -17,11 +17,14 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -112,7 +115,7 @@ func TestPageCount(t *testing.T) {
 	inFile := filepath.Join(inDir, fn)
 
 	// Retrieve page count for inFile.
-	gotPageCount, err := api.PageCountFile(inFile)
+	gotPageCount, err := api.PageCountFile(inFile, nil)
 	if err != nil {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
@@ -134,6 +137,102 @@ func TestPageDimensions(t *testing.T) {
 	}
 }
 
+func TestPageDimensionsRotated(t *testing.T) {
+	msg := "TestPageDimensionsRotated"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "PageDimsRotated.pdf")
+
+	pd, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	mb := pd[0]
+
+	if err := api.RotateFile(inFile, outFile, 270, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Shrink the visible region of page 2 by a crop box.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("CropBox", pdfcpu.NewNumberArray(10, 20, 110, 70))
+	d.Update("Rotate", pdfcpu.Integer(-90))
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if pd, err = api.PageDimsFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The visible width of the rotated page equals the media box height.
+	if pd[0].Width != mb.Height || pd[0].Height != mb.Width {
+		t.Fatalf("%s: page 1: want %v, got %v\n", msg, pdfcpu.Dim{Width: mb.Height, Height: mb.Width}, pd[0])
+	}
+	if pd[1].Width != 50 || pd[1].Height != 100 {
+		t.Fatalf("%s: page 2: want 50x100, got %v\n", msg, pd[1])
+	}
+}
+
+func TestPageSizeHistogram(t *testing.T) {
+	msg := "TestPageSizeHistogram"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "PageSizeHistogram.pdf")
+
+	// Walden.pdf consists of 2 A4 pages.
+	if err := api.InsertPagesFile(inFile, outFile, []string{"2"}, false, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i, mb := range []pdfcpu.Array{pdfcpu.NewNumberArray(0, 0, 500, 300), pdfcpu.NewNumberArray(0, 0, 501, 299)} {
+		d, _, err := ctx.PageDict(i+2, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d.Update("MediaBox", mb)
+	}
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.RotateFile(outFile, "", 90, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		tol  float64
+		want map[string][]int
+	}{
+		{2, map[string][]int{"A4 landscape": {1}, "500x300 landscape": {2, 3}}},
+		{.5, map[string][]int{"A4 landscape": {1}, "500x300 landscape": {2}, "501x299 landscape": {3}}},
+	} {
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.PageSizeTolerance = tt.tol
+		m, err := api.PageSizeHistogramFile(outFile, conf)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(m) != len(tt.want) {
+			t.Fatalf("%s: tolerance %.1f: want %d sizes, got: %v\n", msg, tt.tol, len(tt.want), m)
+		}
+		for k, pp := range tt.want {
+			g, ok := m[k]
+			if !ok || g.Count != len(pp) || !reflect.DeepEqual(g.PageNrs, pp) {
+				t.Fatalf("%s: tolerance %.1f: want %s for pages %v, got: %v\n", msg, tt.tol, k, pp, m)
+			}
+		}
+	}
+}
+
 func TestValidate(t *testing.T) {
 	msg := "TestValidate"
 	inFile := filepath.Join(inDir, "Acroforms2.pdf")
@@ -144,6 +243,322 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+type finding struct {
+	severity pdfcpu.ValidationSeverity
+	objNr    int
+}
+
+// readContextWithEmptyField returns the unvalidated context of inFile with a form made of an empty field dict.
+func readContextWithEmptyField(t *testing.T, inFile string, conf *pdfcpu.Configuration) (*pdfcpu.Context, int) {
+	t.Helper()
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer f.Close()
+
+	ctx, err := api.ReadContext(f, conf)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	ir, err := ctx.IndRefForNewObject(pdfcpu.Dict{})
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	rootDict.Update("AcroForm", pdfcpu.Dict{"Fields": pdfcpu.Array{*ir}})
+
+	return ctx, ir.ObjectNumber.Value()
+}
+
+func TestValidateReportFunc(t *testing.T) {
+	msg := "TestValidateReportFunc"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	var ff []finding
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ReportFunc = func(severity pdfcpu.ValidationSeverity, objNr int, s string) error {
+		ff = append(ff, finding{severity, objNr})
+		return nil
+	}
+
+	// Relaxed mode tolerates the empty field dict and reports a warning.
+	ctx, objNr := readContextWithEmptyField(t, inFile, conf)
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ff) != 1 || ff[0] != (finding{pdfcpu.SeverityWarning, objNr}) {
+		t.Fatalf("%s: want warning for obj#%d, got: %v\n", msg, objNr, ff)
+	}
+
+	// Strict mode fails and reports an error.
+	ff = nil
+	conf.ValidationMode = pdfcpu.ValidationStrict
+	ctx, _ = readContextWithEmptyField(t, inFile, conf)
+	if err := api.ValidateContext(ctx); err == nil {
+		t.Fatalf("%s: missing validation error\n", msg)
+	}
+	if rootObjNr := ctx.Root.ObjectNumber.Value(); len(ff) != 1 || ff[0] != (finding{pdfcpu.SeverityError, rootObjNr}) {
+		t.Fatalf("%s: want single error for obj#%d, got: %v\n", msg, rootObjNr, ff)
+	}
+
+	// Relaxed mode reports an entry admitted for an older PDF version only.
+	var warnings []string
+	conf.ValidationMode = pdfcpu.ValidationRelaxed
+	conf.ReportFunc = func(severity pdfcpu.ValidationSeverity, objNr int, s string) error {
+		if severity == pdfcpu.SeverityWarning {
+			warnings = append(warnings, s)
+		}
+		return nil
+	}
+	ctx, _ = readContextWithEmptyField(t, inFile, conf)
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s pageDict: %v\n", msg, err)
+	}
+	d.Update("Tabs", pdfcpu.Name("R"))
+	v := pdfcpu.V14
+	ctx.HeaderVersion, ctx.RootVersion = &v, nil
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var found bool
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "entry=Tabs")
+	}
+	if !found {
+		t.Fatalf("%s: missing warning for Tabs, got: %v\n", msg, warnings)
+	}
+
+	// Returning an error from the callback aborts validation.
+	errAbort := errors.New("abort")
+	conf.ValidationMode = pdfcpu.ValidationRelaxed
+	conf.ReportFunc = func(severity pdfcpu.ValidationSeverity, objNr int, s string) error {
+		return errAbort
+	}
+	ctx, _ = readContextWithEmptyField(t, inFile, conf)
+	if err := api.ValidateContext(ctx); err != errAbort {
+		t.Fatalf("%s: want %v, got: %v\n", msg, errAbort, err)
+	}
+}
+
+func TestValidateCollect(t *testing.T) {
+	msg := "TestValidateCollect"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationCollect
+
+	// Warnings only do not fail validation.
+	ctx, _ := readContextWithEmptyField(t, inFile, conf)
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Corrupt the content of pages 2 and 4.
+	ctx, objNr := readContextWithEmptyField(t, inFile, conf)
+	for _, i := range []int{2, 4} {
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s pageDict: %v\n", msg, err)
+		}
+		d.Update("Contents", pdfcpu.Integer(7))
+	}
+
+	err := api.ValidateContext(ctx)
+	ve, ok := err.(pdfcpu.ValidationErrors)
+	if !ok {
+		t.Fatalf("%s: want ValidationErrors, got: %v\n", msg, err)
+	}
+	if len(ve) != 3 {
+		t.Fatalf("%s: want 3 findings, got: %v\n", msg, ve)
+	}
+	for i, pageNr := range []int{2, 4} {
+		if ve[i].Severity != pdfcpu.SeverityError || ve[i].ObjNr == 0 || !strings.Contains(ve[i].Msg, fmt.Sprintf("page %d", pageNr)) {
+			t.Fatalf("%s: want error for page %d, got: %v\n", msg, pageNr, ve[i])
+		}
+	}
+	if ve[2].Severity != pdfcpu.SeverityWarning || ve[2].ObjNr != objNr {
+		t.Fatalf("%s: want warning for obj#%d, got: %v\n", msg, objNr, ve[2])
+	}
+	if ctx.Valid {
+		t.Fatalf("%s: context marked valid\n", msg)
+	}
+}
+
+// readContextWithCycles returns the unvalidated context of inFile with a page tree
+// and an outline item referencing themselves.
+func readContextWithCycles(t *testing.T, inFile string, conf *pdfcpu.Configuration) *pdfcpu.Context {
+	t.Helper()
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer f.Close()
+
+	ctx, err := api.ReadContext(f, conf)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	ir, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	d.Update("Kids", append(d.ArrayEntry("Kids"), *ir))
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	item := pdfcpu.Dict{"Title": pdfcpu.StringLiteral("Loop")}
+	itemIndRef, err := ctx.IndRefForNewObject(item)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	outlines := pdfcpu.Dict{"First": *itemIndRef, "Last": *itemIndRef}
+	outlinesIndRef, err := ctx.IndRefForNewObject(outlines)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	item["Parent"] = *outlinesIndRef
+	item["Next"] = *itemIndRef
+	rootDict.Update("Outlines", *outlinesIndRef)
+
+	return ctx
+}
+
+func TestValidateReferenceCycle(t *testing.T) {
+	msg := "TestValidateReferenceCycle"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "cycle.pdf")
+
+	// By default recursive references fail validation.
+	conf := pdfcpu.NewDefaultConfiguration()
+	ctx := readContextWithCycles(t, inFile, conf)
+	if err := api.ValidateContext(ctx); err == nil {
+		t.Fatalf("%s: missing validation error\n", msg)
+	}
+
+	// Break both cycles and continue with a warning for each.
+	var ff []finding
+	conf.CycleMode = pdfcpu.CycleBreak
+	conf.ReportFunc = func(severity pdfcpu.ValidationSeverity, objNr int, s string) error {
+		ff = append(ff, finding{severity, objNr})
+		return nil
+	}
+	ctx = readContextWithCycles(t, inFile, conf)
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ff) != 2 || ff[0].severity != pdfcpu.SeverityWarning || ff[1].severity != pdfcpu.SeverityWarning {
+		t.Fatalf("%s: want 2 warnings, got: %v\n", msg, ff)
+	}
+
+	// The cycles are gone for good.
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+// pdfWithStreamLength returns a single page PDF whose content stream declares length l.
+func pdfWithStreamLength(content string, l int) []byte {
+	objs := []string{
+		"<</Type/Catalog/Pages 2 0 R>>",
+		"<</Type/Pages/Kids[3 0 R]/Count 1>>",
+		"<</Type/Page/Parent 2 0 R/MediaBox[0 0 200 200]/Contents 4 0 R>>",
+		fmt.Sprintf("<</Length %d>>\nstream\n%s\nendstream", l, content),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	// Pad the file beyond the 512 byte chunk used when scanning for startxref.
+	buf.WriteString("%" + strings.Repeat("-", 512) + "\n")
+
+	offsets := []int{}
+	for i, o := range objs {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<</Size %d/Root 1 0 R>>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestReadWrongStreamLength(t *testing.T) {
+	msg := "TestReadWrongStreamLength"
+	content := "BT /F1 12 Tf 10 100 Td (Hello) Tj ET"
+
+	for _, tt := range []struct {
+		name string
+		l    int
+	}{
+		{"correct", len(content)},
+		{"short", len(content) - 3},
+		{"long", len(content) + 4},
+	} {
+		bb := pdfWithStreamLength(content, tt.l)
+
+		// Strict mode fails for any length not followed by endstream.
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.StreamLengthMode = pdfcpu.StreamLengthError
+		_, err := api.ReadContext(bytes.NewReader(bb), conf)
+		if tt.l == len(content) && err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.name, err)
+		}
+		if tt.l != len(content) && err == nil {
+			t.Fatalf("%s %s: missing error in strict mode\n", msg, tt.name)
+		}
+
+		// Lenient mode recovers the stream data reporting a warning.
+		var warnings int
+		conf = pdfcpu.NewDefaultConfiguration()
+		conf.ReportFunc = func(severity pdfcpu.ValidationSeverity, objNr int, s string) error {
+			if severity == pdfcpu.SeverityWarning && objNr == 4 {
+				warnings++
+			}
+			return nil
+		}
+		ctx, err := api.ReadContext(bytes.NewReader(bb), conf)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.name, err)
+		}
+		if want := map[bool]int{true: 0, false: 1}[tt.l == len(content)]; warnings != want {
+			t.Fatalf("%s %s: want %d warnings, got %d\n", msg, tt.name, want, warnings)
+		}
+
+		d, _, err := ctx.PageDict(1, false)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.name, err)
+		}
+		bb, err = ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.name, err)
+		}
+		if string(bb) != content {
+			t.Fatalf("%s %s: want %q, got %q\n", msg, tt.name, content, bb)
+		}
+	}
+}
+
 func TestManipulateContext(t *testing.T) {
 	msg := "TestManipulateContext"
 	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
@@ -17,14 +17,21 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"io"
 	"io/ioutil"
+	"math/rand"
+	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
 )
 
 func prepareForAttachmentTest(t *testing.T) error {
@@ -109,6 +116,732 @@ func TestAttachments(t *testing.T) {
 	}
 }
 
+func TestRenameAttachments(t *testing.T) {
+	msg := "TestRenameAttachments"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+
+	files := []string{
+		outDir + "/golang.pdf,Go",
+		outDir + "/T4.pdf",
+		outDir + "/go-lecture.pdf",
+		outDir + "/test.wav"}
+
+	if err := api.AddAttachmentsFile(fileName, "", files, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	// Renaming to an existing id must fail.
+	if err := api.RenameAttachmentsFile(fileName, "", map[string]string{"T4.pdf": "test.wav"}, nil); err == nil {
+		t.Fatalf("%s: rename to existing id should fail\n", msg)
+	}
+
+	m := map[string]string{"golang.pdf": "zzz.pdf", "test.wav": "a.wav", "missing.pdf": "x.pdf"}
+	if err := api.RenameAttachmentsFile(fileName, "", m, nil); err != nil {
+		t.Fatalf("%s rename attachments: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	aa, err := ctx.ListAttachments()
+	if err != nil {
+		t.Fatalf("%s listAttachments: %v\n", msg, err)
+	}
+
+	// The name tree stays sorted.
+	want := []string{"T4.pdf", "a.wav", "go-lecture.pdf", "zzz.pdf"}
+	if len(aa) != len(want) {
+		t.Fatalf("%s: want %d attachments got %d\n", msg, len(want), len(aa))
+	}
+	for i, a := range aa {
+		if a.ID != want[i] {
+			t.Fatalf("%s: attachment %d: want %s got %s\n", msg, i, want[i], a.ID)
+		}
+		if a.ID == "zzz.pdf" && a.Desc != "Go" {
+			t.Fatalf("%s: lost description of %s\n", msg, a.ID)
+		}
+	}
+
+	o, _ := ctx.Names["EmbeddedFiles"].Value("a.wav")
+	d, err := ctx.DereferenceDict(o)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, k := range []string{"F", "UF"} {
+		if s := d.StringEntry(k); s == nil || *s != "a.wav" {
+			t.Fatalf("%s: %s not renamed\n", msg, k)
+		}
+	}
+
+	// Renaming two attachments to the same id must fail.
+	if err := api.RenameAttachmentsFile(fileName, "", map[string]string{"T4.pdf": "x.pdf", "a.wav": "x.pdf"}, nil); err == nil {
+		t.Fatalf("%s: rename to same id should fail\n", msg)
+	}
+
+	// Swapping ids is fine.
+	if err := api.RenameAttachmentsFile(fileName, "", map[string]string{"T4.pdf": "zzz.pdf", "zzz.pdf": "T4.pdf"}, nil); err != nil {
+		t.Fatalf("%s swap attachments: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(fileName); err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	if aa, err = ctx.ListAttachments(); err != nil {
+		t.Fatalf("%s listAttachments: %v\n", msg, err)
+	}
+	for i, a := range aa {
+		if a.ID != want[i] {
+			t.Fatalf("%s: attachment %d: want %s got %s\n", msg, i, want[i], a.ID)
+		}
+		if (a.ID == "T4.pdf") != (a.Desc == "Go") {
+			t.Fatalf("%s: %s not swapped\n", msg, a.ID)
+		}
+	}
+
+	if err := api.ValidateFile(fileName, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+}
+
+func TestListAttachmentsByType(t *testing.T) {
+	msg := "TestListAttachmentsByType"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+
+	files := []string{
+		outDir + "/golang.pdf",
+		outDir + "/T4.pdf",
+		outDir + "/test.wav"}
+
+	if err := api.AddAttachmentsFile(fileName, "", files, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	// Record an explicit Subtype for T4.pdf taking precedence over its file extension.
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	o, _ := ctx.Names["EmbeddedFiles"].Value("T4.pdf")
+	d, err := ctx.DereferenceDict(o)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, err := ctx.DereferenceStreamDict(d.DictEntry("EF")["F"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing embedded file stream: %v\n", msg, err)
+	}
+	sd.InsertName("Subtype", "application#2Fx-custom")
+	if err := api.WriteContextFile(ctx, fileName); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		mimeTypes []string
+		want      []string
+	}{
+		{nil, []string{"T4.pdf", "golang.pdf", "test.wav"}},
+		{[]string{"AUDIO/*"}, []string{"test.wav"}},
+		{[]string{"application/pdf"}, []string{"golang.pdf"}},
+		{[]string{"application/X-Custom", "audio/wav"}, []string{"T4.pdf", "test.wav"}},
+		{[]string{"*/*"}, []string{"T4.pdf", "golang.pdf", "test.wav"}},
+		{[]string{"image/*"}, nil},
+	} {
+		got, err := api.ListAttachmentsByTypeFile(fileName, tt.mimeTypes, nil)
+		if err != nil {
+			t.Fatalf("%s list attachments %v: %v\n", msg, tt.mimeTypes, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s %v: want %v got %v\n", msg, tt.mimeTypes, tt.want, got)
+		}
+	}
+}
+
+func TestExtractAttachment(t *testing.T) {
+	msg := "TestExtractAttachment"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+
+	// No attachments at all.
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, err := api.ExtractAttachment(f, "test.wav", nil); err != pdfcpu.ErrAttachmentNotFound {
+		t.Fatalf("%s: want ErrAttachmentNotFound, got %v\n", msg, err)
+	}
+	f.Close()
+
+	wavFile := filepath.Join(outDir, "test.wav")
+	if err := api.AddAttachmentsFile(fileName, "", []string{outDir + "/golang.pdf", wavFile}, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	bb, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	a, err := api.ExtractAttachment(bytes.NewReader(bb), "test.wav", nil)
+	if err != nil {
+		t.Fatalf("%s extract attachment: %v\n", msg, err)
+	}
+	if a.ID != "test.wav" || a.MimeType != "audio/wav" {
+		t.Fatalf("%s: unexpected attachment %s mimeType:%s\n", msg, a, a.MimeType)
+	}
+
+	got, err := ioutil.ReadAll(a)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want, err := ioutil.ReadFile(wavFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s: attachment data differs\n", msg)
+	}
+
+	if _, err := api.ExtractAttachment(bytes.NewReader(bb), "missing.wav", nil); err != pdfcpu.ErrAttachmentNotFound {
+		t.Fatalf("%s: want ErrAttachmentNotFound, got %v\n", msg, err)
+	}
+}
+
+func TestAttachmentCreationTime(t *testing.T) {
+	msg := "TestAttachmentCreationTime"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	// Archival timestamps west of UTC.
+	loc := time.FixedZone("", -(3*3600 + 30*60))
+	creationTime := time.Date(1999, 12, 31, 23, 59, 58, 0, loc)
+	modTime := time.Date(2005, 6, 1, 8, 0, 0, 0, loc)
+
+	for _, a := range []pdfcpu.Attachment{
+		{Reader: strings.NewReader("created"), ID: "a1", ModTime: &modTime, CreationTime: &creationTime},
+		{Reader: strings.NewReader("unknown"), ID: "a2", ModTime: &modTime},
+	} {
+		if err := ctx.AddAttachment(a, false); err != nil {
+			t.Fatalf("%s addAttachment: %v\n", msg, err)
+		}
+	}
+
+	if err := api.WriteContextFile(ctx, fileName); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	ctx, err = api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	aa, err := ctx.ListAttachments()
+	if err != nil {
+		t.Fatalf("%s listAttachments: %v\n", msg, err)
+	}
+	if len(aa) != 2 {
+		t.Fatalf("%s listAttachments: want 2 got %d\n", msg, len(aa))
+	}
+
+	for _, a := range aa {
+		if a.ModTime == nil || !a.ModTime.Equal(modTime) {
+			t.Fatalf("%s %s: want modTime %s, got %v\n", msg, a.ID, modTime, a.ModTime)
+		}
+		switch a.ID {
+		case "a1":
+			if a.CreationTime == nil || !a.CreationTime.Equal(creationTime) {
+				t.Fatalf("%s %s: want creationTime %s, got %v\n", msg, a.ID, creationTime, a.CreationTime)
+			}
+			if _, offset := a.CreationTime.Zone(); offset != -(3*3600 + 30*60) {
+				t.Fatalf("%s %s: lost timezone offset: %s\n", msg, a.ID, a.CreationTime)
+			}
+		case "a2":
+			if a.CreationTime != nil {
+				t.Fatalf("%s %s: want no creationTime, got %s\n", msg, a.ID, a.CreationTime)
+			}
+		}
+	}
+}
+
+// embeddedFileStream returns the embedded file stream of attachment id along with its object number.
+func embeddedFileStream(t *testing.T, msg string, ctx *pdfcpu.Context, id string) (*pdfcpu.StreamDict, int) {
+	t.Helper()
+	o, ok := ctx.Names["EmbeddedFiles"].Value(id)
+	if !ok {
+		t.Fatalf("%s: missing attachment %s\n", msg, id)
+	}
+	d, err := ctx.DereferenceDict(o)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ef, err := ctx.DereferenceDict(d["EF"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, ok := ef["F"].(pdfcpu.IndirectRef)
+	if !ok {
+		t.Fatalf("%s: missing embedded file stream %s\n", msg, id)
+	}
+	sd, err := ctx.DereferenceStreamDict(ir)
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing embedded file stream %s: %v\n", msg, id, err)
+	}
+	return sd, ir.ObjectNumber.Value()
+}
+
+func TestExtractAttachmentsVerified(t *testing.T) {
+	msg := "TestExtractAttachmentsVerified"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+	dir := filepath.Join(outDir, "verified")
+
+	if err := api.AddAttachmentsFile(fileName, "", []string{filepath.Join(outDir, "test.wav")}, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	// A freshly embedded file carries a matching checksum.
+	if err := api.ExtractAttachmentsVerifiedFile(fileName, dir, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Tamper with the embedded file keeping its checksum.
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	sd, objNr := embeddedFileStream(t, msg, ctx, "test.wav")
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd.Content = append(sd.Content, 0)
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx.Table[objNr].Object = *sd
+	tamperedFile := filepath.Join(outDir, "goTampered.pdf")
+	if err := api.WriteContextFile(ctx, tamperedFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	err = api.ExtractAttachmentsVerifiedFile(tamperedFile, filepath.Join(outDir, "tampered"), nil, nil)
+	if errors.Cause(err) != pdfcpu.ErrAttachmentChecksumMismatch {
+		t.Fatalf("%s: want ErrAttachmentChecksumMismatch, got %v\n", msg, err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "tampered", "test.wav")); !os.IsNotExist(err) {
+		t.Fatalf("%s: corrupted attachment got extracted\n", msg)
+	}
+
+	// Without verification extraction succeeds.
+	if err := api.ExtractAttachmentsFile(tamperedFile, filepath.Join(outDir, "tampered"), nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Embedded files without checksum pass verification.
+	if ctx, err = api.ReadContextFile(tamperedFile); err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	sd, _ = embeddedFileStream(t, msg, ctx, "test.wav")
+	sd.DictEntry("Params").Delete("CheckSum")
+	if err := api.WriteContextFile(ctx, tamperedFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+	if err := api.ExtractAttachmentsVerifiedFile(tamperedFile, filepath.Join(outDir, "tampered"), nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestAddLargeAttachment(t *testing.T) {
+	msg := "TestAddLargeAttachment"
+
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goLargeAttachment.pdf")
+
+	// A sparse file of 200MB.
+	const size = 200 << 20
+	fileName := filepath.Join(outDir, "large.bin")
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer os.Remove(fileName)
+	if err := f.Truncate(size); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	var m1, m2 runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m1)
+
+	if err := ctx.AddAttachment(pdfcpu.Attachment{Reader: f, ID: "large.bin"}, false); err != nil {
+		t.Fatalf("%s addAttachment: %v\n", msg, err)
+	}
+
+	runtime.ReadMemStats(&m2)
+	if alloc := m2.TotalAlloc - m1.TotalAlloc; alloc > 50<<20 {
+		t.Fatalf("%s: embedding %d bytes allocated %d bytes\n", msg, size, alloc)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	sd, _ := embeddedFileStream(t, msg, ctx, "large.bin")
+	if i := sd.DictEntry("Params").IntEntry("Size"); i == nil || *i != size {
+		t.Fatalf("%s: want size %d, got %v\n", msg, size, i)
+	}
+}
+
+func TestAddIncompressibleAttachment(t *testing.T) {
+	msg := "TestAddIncompressibleAttachment"
+
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goIncompressibleAttachment.pdf")
+
+	// A file of 64MB random data.
+	const size = 64 << 20
+	fileName := filepath.Join(outDir, "random.bin")
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer os.Remove(fileName)
+	defer f.Close()
+	if _, err := io.CopyN(f, rand.New(rand.NewSource(1)), size); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	var m1, m2 runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m1)
+
+	// Embedding and writing copy the data in chunks.
+	if err := ctx.AddAttachment(pdfcpu.Attachment{Reader: f, ID: "random.bin"}, false); err != nil {
+		t.Fatalf("%s addAttachment: %v\n", msg, err)
+	}
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	runtime.ReadMemStats(&m2)
+	if alloc := m2.TotalAlloc - m1.TotalAlloc; alloc > 16<<20 {
+		t.Fatalf("%s: embedding and writing %d bytes allocated %d bytes\n", msg, size, alloc)
+	}
+
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	aa, err := ctx.ExtractAttachmentsVerified([]string{"random.bin"})
+	if err != nil {
+		t.Fatalf("%s extractAttachments: %v\n", msg, err)
+	}
+	n, err := io.Copy(ioutil.Discard, aa[0])
+	if err != nil || n != size {
+		t.Fatalf("%s: want %d bytes, got %d: %v\n", msg, size, n, err)
+	}
+}
+
+func collectionInitial(t *testing.T, msg, fileName string) string {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(rootDict["Collection"])
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing collection: %v\n", msg, err)
+	}
+	if s := d.StringEntry("D"); s != nil {
+		return *s
+	}
+	return ""
+}
+
+func TestCollectionInitial(t *testing.T) {
+	msg := "TestCollectionInitial"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	add := func(id string, initial, coll bool) error {
+		bb, err := ioutil.ReadFile(filepath.Join(outDir, id))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a := pdfcpu.Attachment{Reader: bytes.NewReader(bb), ID: id, CollectionInitial: initial}
+		return ctx.AddAttachment(a, coll)
+	}
+
+	if err := add("golang.pdf", true, false); err == nil {
+		t.Fatalf("%s: initial document without collection should fail\n", msg)
+	}
+	if err := add("golang.pdf", false, true); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := add("T4.pdf", true, true); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := add("test.wav", true, true); err == nil {
+		t.Fatalf("%s: second initial document should fail\n", msg)
+	}
+
+	if err := api.WriteContextFile(ctx, fileName); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(fileName, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+	if s := collectionInitial(t, msg, fileName); s != "T4.pdf" {
+		t.Fatalf("%s: initial document: want T4.pdf, got %q\n", msg, s)
+	}
+
+	// Renaming and removing the initial document keep the collection consistent.
+	if err := api.RenameAttachmentsFile(fileName, "", map[string]string{"T4.pdf": "cover.pdf"}, nil); err != nil {
+		t.Fatalf("%s rename attachments: %v\n", msg, err)
+	}
+	if s := collectionInitial(t, msg, fileName); s != "cover.pdf" {
+		t.Fatalf("%s: initial document: want cover.pdf, got %q\n", msg, s)
+	}
+
+	if err := api.RemoveAttachmentsFile(fileName, "", []string{"cover.pdf"}, nil); err != nil {
+		t.Fatalf("%s remove attachment: %v\n", msg, err)
+	}
+	if s := collectionInitial(t, msg, fileName); s != "" {
+		t.Fatalf("%s: initial document: want none, got %q\n", msg, s)
+	}
+}
+
+func TestDedupAttachments(t *testing.T) {
+	msg := "TestDedupAttachments"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+	copyName := filepath.Join(outDir, "T4copy.pdf")
+	if err := copyFile(t, filepath.Join(outDir, "T4.pdf"), copyName); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	files := []string{outDir + "/T4.pdf", copyName, outDir + "/test.wav"}
+
+	fileSize := func(fileName string) int64 {
+		fi, err := os.Stat(fileName)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return fi.Size()
+	}
+
+	plainFile := filepath.Join(outDir, "attachPlain.pdf")
+	if err := api.AddAttachmentsFile(filepath.Join(inDir, "go.pdf"), plainFile, files, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.DedupAttachments = true
+	fileName := filepath.Join(outDir, "attachDedup.pdf")
+	if err := api.AddAttachmentsFile(filepath.Join(inDir, "go.pdf"), fileName, files, false, conf); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	// Both logical attachments remain listed.
+	listAttachments(t, msg, fileName, 3)
+
+	if fileSize(fileName) >= fileSize(plainFile) {
+		t.Fatalf("%s: deduplicated file not smaller: %d >= %d\n", msg, fileSize(fileName), fileSize(plainFile))
+	}
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	streamRef := func(id string) pdfcpu.Object {
+		o, _ := ctx.Names["EmbeddedFiles"].Value(id)
+		d, err := ctx.DereferenceDict(o)
+		if err != nil || d == nil {
+			t.Fatalf("%s: missing %s: %v\n", msg, id, err)
+		}
+		return d.DictEntry("EF")["F"]
+	}
+	if streamRef("T4.pdf") != streamRef("T4copy.pdf") {
+		t.Fatalf("%s: identical attachments do not share their stream\n", msg)
+	}
+	if streamRef("T4.pdf") == streamRef("test.wav") {
+		t.Fatalf("%s: different attachments share their stream\n", msg)
+	}
+
+	// Removing one attachment keeps the shared stream for the other.
+	if err := api.RemoveAttachmentsFile(fileName, "", []string{"T4.pdf"}, nil); err != nil {
+		t.Fatalf("%s remove attachment: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(fileName, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	a, err := api.ExtractAttachment(f, "T4copy.pdf", nil)
+	if err != nil {
+		t.Fatalf("%s extract attachment: %v\n", msg, err)
+	}
+	got, err := ioutil.ReadAll(a)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want, err := ioutil.ReadFile(copyName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s: shared attachment data differs\n", msg)
+	}
+}
+
+func TestAttachmentFileNames(t *testing.T) {
+	msg := "TestAttachmentFileNames"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	dir := filepath.Join(outDir, "sub", "dir")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := copyFile(t, filepath.Join(inDir, "go.pdf"), filepath.Join(dir, "report.pdf")); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := os.Chdir(outDir); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range []struct {
+		preserve bool
+		want     string
+	}{
+		{false, "report.pdf"},
+		{true, "sub/dir/report.pdf"},
+	} {
+		fileName := "golang.pdf"
+		if err := copyFile(t, filepath.Join(wd, inDir, fileName), fileName); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		conf := pdfcpu.NewDefaultConfiguration()
+		conf.PreserveAttachmentPaths = tt.preserve
+		if err := api.AddAttachmentsFile(fileName, "", []string{filepath.Join("sub", "dir", "report.pdf")}, false, conf); err != nil {
+			t.Fatalf("%s add attachment: %v\n", msg, err)
+		}
+
+		ctx, err := api.ReadContextFile(fileName)
+		if err != nil {
+			t.Fatalf("%s readContext: %v\n", msg, err)
+		}
+		if err := ctx.LocateNameTree("EmbeddedFiles", false); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		o, ok := ctx.Names["EmbeddedFiles"].Value(tt.want)
+		if !ok {
+			t.Fatalf("%s: missing attachment %s\n", msg, tt.want)
+		}
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, k := range []string{"F", "UF"} {
+			if s := d.StringEntry(k); s == nil || *s != tt.want {
+				t.Fatalf("%s: %s: want %s, got %v\n", msg, k, tt.want, s)
+			}
+		}
+
+		// Extraction restores the relative path.
+		extractDir := filepath.Join(outDir, "extract")
+		if err := api.ExtractAttachmentsFile(fileName, extractDir, nil, nil); err != nil {
+			t.Fatalf("%s extract attachments: %v\n", msg, err)
+		}
+		if _, err := os.Stat(filepath.Join(extractDir, filepath.FromSlash(tt.want))); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
+
 // timeEqualsTimeFromDateTime returns true if t1 equals t2
 // working on the assumption that t2 is restored from a PDF
 // date string that does not have a way to include nanoseconds.
@@ -149,7 +882,8 @@ func TestAttachmentsLowLevel(t *testing.T) {
 	desc := "description"
 	want := "12345"
 	modTime := time.Now()
-	a := pdfcpu.Attachment{Reader: strings.NewReader(want), ID: id, Desc: desc, ModTime: &modTime}
+	creationTime := modTime.Add(-24 * time.Hour)
+	a := pdfcpu.Attachment{Reader: strings.NewReader(want), ID: id, Desc: desc, ModTime: &modTime, CreationTime: &creationTime}
 
 	useCollection := false
 	if err = ctx.AddAttachment(a, useCollection); err != nil {
@@ -177,7 +911,8 @@ func TestAttachmentsLowLevel(t *testing.T) {
 	}
 	if aa[0].ID != id ||
 		aa[0].Desc != desc ||
-		!timeEqualsTimeFromDateTime(&modTime, aa[0].ModTime) {
+		!timeEqualsTimeFromDateTime(&modTime, aa[0].ModTime) ||
+		!timeEqualsTimeFromDateTime(&creationTime, aa[0].CreationTime) {
 		t.Fatalf("%s listAttachments: unexpected attachment: %s\n", msg, aa[0])
 	}
 
@@ -191,7 +926,8 @@ func TestAttachmentsLowLevel(t *testing.T) {
 	}
 	if aa[0].ID != id ||
 		aa[0].Desc != desc ||
-		!timeEqualsTimeFromDateTime(&modTime, aa[0].ModTime) {
+		!timeEqualsTimeFromDateTime(&modTime, aa[0].ModTime) ||
+		!timeEqualsTimeFromDateTime(&creationTime, aa[0].CreationTime) {
 		t.Fatalf("%s extractAttachment: unexpected attachment: %s\n", msg, aa[0])
 	}
 
@@ -236,3 +972,104 @@ func TestAttachmentsLowLevel(t *testing.T) {
 		t.Fatalf("%s extractAttachment: want 0 got %d\n", msg, len(aa))
 	}
 }
+
+func TestAttachmentCompression(t *testing.T) {
+	msg := "TestAttachmentCompression"
+
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "attCompression.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	data := strings.Repeat("0123456789", 100)
+
+	for _, a := range []pdfcpu.Attachment{
+		{Reader: strings.NewReader(data), ID: "compressed"},
+		{Reader: strings.NewReader(data), ID: "raw", Uncompressed: true},
+	} {
+		if err = ctx.AddAttachment(a, false); err != nil {
+			t.Fatalf("%s addAttachment: %v\n", msg, err)
+		}
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	for id, wantFilter := range map[string]bool{"compressed": true, "raw": false} {
+		o, _ := ctx.Names["EmbeddedFiles"].Value(id)
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		sd, err := ctx.DereferenceStreamDict(d.DictEntry("EF")["F"])
+		if err != nil || sd == nil {
+			t.Fatalf("%s: %s: missing embedded file stream: %v\n", msg, id, err)
+		}
+		if _, found := sd.Find("Filter"); found != wantFilter {
+			t.Fatalf("%s: %s: filter found: %t\n", msg, id, found)
+		}
+		// Size reflects the uncompressed data.
+		if size := sd.DictEntry("Params").IntEntry("Size"); size == nil || *size != len(data) {
+			t.Fatalf("%s: %s: want size %d got %v\n", msg, id, len(data), size)
+		}
+
+		aa, err := ctx.ExtractAttachments([]string{id})
+		if err != nil || len(aa) != 1 {
+			t.Fatalf("%s: %s: extract: %v\n", msg, id, err)
+		}
+		bb, err := ioutil.ReadAll(aa[0])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if string(bb) != data {
+			t.Fatalf("%s: %s: extracted data mismatch\n", msg, id)
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	msg := "TestManifest"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "manifest.pdf")
+
+	if _, err := api.GetManifestFile(inFile, nil); err == nil {
+		t.Fatalf("%s: unexpected manifest in %s\n", msg, inFile)
+	}
+
+	m := pdfcpu.Manifest{
+		Sources: []pdfcpu.ManifestSource{
+			{FileName: "a.pdf", Pages: "1-3", Checksum: "sha256:01ab"},
+			{FileName: "b.pdf", Pages: "2,5"},
+		},
+		Checksum: "sha256:ff00",
+	}
+
+	if err := api.SetManifestFile(inFile, outFile, m, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Replace the manifest.
+	m.Sources = m.Sources[:1]
+	if err := api.SetManifestFile(outFile, "", m, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	got, err := api.GetManifestFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !reflect.DeepEqual(*got, m) {
+		t.Fatalf("%s: want %v, got: %v\n", msg, m, *got)
+	}
+
+	// The manifest is a regular attachment.
+	listAttachments(t, msg, outFile, 1)
+}
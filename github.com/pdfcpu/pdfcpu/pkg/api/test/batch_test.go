@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestBatchProcess(t *testing.T) {
+	msg := "TestBatchProcess"
+
+	title := "Batch"
+	setTitle := func(ctx *pdfcpu.Context) error {
+		return ctx.SetDocumentInfo(pdfcpu.DocumentInfo{Title: &title})
+	}
+
+	errOp := errors.New("op failed")
+
+	var jobs []api.Job
+	for _, fileName := range []string{"Acroforms2.pdf", "CenterOfWhy.pdf", "Walden.pdf", "5116.DCT_Filter.pdf", "networkProgr.pdf"} {
+		jobs = append(jobs, api.Job{
+			InFile:  filepath.Join(inDir, fileName),
+			OutFile: filepath.Join(outDir, "batch_"+fileName),
+			Op:      setTitle,
+		})
+	}
+	jobs = append(jobs,
+		api.Job{InFile: filepath.Join(inDir, "Walden.pdf"), OutFile: filepath.Join(outDir, "batch_error.pdf"), Op: func(ctx *pdfcpu.Context) error { return errOp }},
+		api.Job{InFile: filepath.Join(inDir, "Walden.pdf"), OutFile: filepath.Join(outDir, "batch_panic.pdf"), Op: func(ctx *pdfcpu.Context) error { panic("boom") }},
+		api.Job{InFile: filepath.Join(inDir, "missing.pdf"), OutFile: filepath.Join(outDir, "batch_missing.pdf"), Op: setTitle},
+	)
+
+	rr := api.BatchProcess(jobs, 3)
+	if len(rr) != len(jobs) {
+		t.Fatalf("%s: want %d results, got: %d\n", msg, len(jobs), len(rr))
+	}
+
+	for i, r := range rr[:5] {
+		if r.Job.InFile != jobs[i].InFile || r.Err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, r.Job.InFile, r.Err)
+		}
+		ctx, err := api.ReadContextFile(r.Job.OutFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		di, err := ctx.DocumentInfo()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if di.Title == nil || *di.Title != title {
+			t.Fatalf("%s: %s: want title %q, got: %v\n", msg, r.Job.OutFile, title, di.Title)
+		}
+	}
+
+	if rr[5].Err != errOp {
+		t.Fatalf("%s: want %v, got: %v\n", msg, errOp, rr[5].Err)
+	}
+	if rr[6].Err == nil || rr[7].Err == nil {
+		t.Fatalf("%s: want errors for panicking op and missing input, got: %v, %v\n", msg, rr[6].Err, rr[7].Err)
+	}
+
+	// Failed jobs leave no output behind.
+	for _, r := range rr[5:] {
+		if _, err := os.Stat(r.Job.OutFile); err == nil {
+			t.Fatalf("%s: unexpected output %s\n", msg, r.Job.OutFile)
+		}
+	}
+}
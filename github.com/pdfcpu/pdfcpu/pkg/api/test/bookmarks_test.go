@@ -0,0 +1,284 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// firstOutlineItems returns the outline items of the first level holding more than one item.
+func firstOutlineItems(t *testing.T, msg string, ctx *pdf.Context) []pdf.Dict {
+	t.Helper()
+
+	ir, err := ctx.Outlines()
+	if err != nil || ir == nil {
+		t.Fatalf("%s: missing outlines: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for *d.IndirectRefEntry("First") == *d.IndirectRefEntry("Last") {
+		if d, err = ctx.DereferenceDict(*d.IndirectRefEntry("First")); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	dd := []pdf.Dict{}
+	for ir := d.IndirectRefEntry("First"); ir != nil; ir = d.IndirectRefEntry("Next") {
+		if d, err = ctx.DereferenceDict(*ir); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		dd = append(dd, d)
+	}
+	return dd
+}
+
+func TestBookmarkStyle(t *testing.T) {
+	msg := "TestBookmarkStyle"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "bookmarkStyle.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	dd := firstOutlineItems(t, msg, ctx)
+	if len(dd) < 3 {
+		t.Fatalf("%s: want at least 3 outline items, got %d\n", msg, len(dd))
+	}
+	dd[0].Update("C", pdf.NewNumberArray(1, 0, 0))
+	dd[0].Update("F", pdf.Integer(3))
+	dd[1].Update("C", pdf.NewNumberArray(0, 0, .5))
+	dd[1].Update("F", pdf.Integer(1))
+	dd[2].Delete("C")
+	dd[2].Delete("F")
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	bms, err := ctx.BookmarksForOutlineLevel1()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for i, want := range []pdf.Bookmark{
+		{Color: &pdf.SimpleColor{R: 1}, Bold: true, Italic: true},
+		{Color: &pdf.SimpleColor{B: .5}, Italic: true},
+		{},
+	} {
+		got := bms[i]
+		if got.Bold != want.Bold || got.Italic != want.Italic {
+			t.Fatalf("%s %s: want bold=%t italic=%t, got bold=%t italic=%t\n", msg, got.Title, want.Bold, want.Italic, got.Bold, got.Italic)
+		}
+		if (got.Color == nil) != (want.Color == nil) || got.Color != nil && *got.Color != *want.Color {
+			t.Fatalf("%s %s: want color %v, got %v\n", msg, got.Title, want.Color, got.Color)
+		}
+	}
+}
+
+// topLevelOutlineItems returns the titles of all outline items at the top level of the outline of fileName.
+func topLevelOutlineItems(t *testing.T, msg, fileName string) []string {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	ir, err := ctx.Outlines()
+	if err != nil || ir == nil {
+		t.Fatalf("%s: missing outlines: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss := []string{}
+	var prev *pdf.IndirectRef
+	for ir := d.IndirectRefEntry("First"); ir != nil; ir = d.IndirectRefEntry("Next") {
+		if d, err = ctx.DereferenceDict(*ir); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if p := d.IndirectRefEntry("Prev"); prev != nil && (p == nil || *p != *prev) {
+			t.Fatalf("%s: corrupt Prev of outline item %s\n", msg, ir)
+		}
+		title, _ := pdf.Text(d["Title"])
+		ss = append(ss, title)
+		prev = ir
+	}
+
+	return ss
+}
+
+func TestAddBookmarks(t *testing.T) {
+	msg := "TestAddBookmarks"
+	outFile := filepath.Join(outDir, "addBookmarks.pdf")
+
+	bms := []pdf.Bookmark{
+		{Title: "Part 1", PageFrom: 1, Bold: true, Color: &pdf.SimpleColor{R: 1}, Children: []pdf.Bookmark{
+			{Title: "Section 1.1", PageFrom: 2},
+			{Title: "Section 1.2", PageFrom: 3, Italic: true},
+		}},
+		{Title: "Teil 2: Übersicht", PageFrom: 4},
+	}
+
+	if err := api.AddBookmarksFile(filepath.Join(inDir, "CenterOfWhy.pdf"), outFile, bms, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	ir, _ := ctx.Outlines()
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if c := d.IntEntry("Count"); c == nil || *c != 4 {
+		t.Fatalf("%s: want outline count 4, got %v\n", msg, c)
+	}
+
+	for _, tt := range []struct {
+		depth int
+		want  []pdf.Bookmark
+	}{
+		{1, []pdf.Bookmark{bms[0], bms[1]}},
+		{2, []pdf.Bookmark{bms[0], bms[0].Children[0], bms[0].Children[1], bms[1]}},
+	} {
+		depth, want := tt.depth, tt.want
+		got, err := ctx.BookmarksForOutlineLevel(depth)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s depth %d: want %d bookmarks, got %d\n", msg, depth, len(want), len(got))
+		}
+		for i, bm := range got {
+			w := want[i]
+			if bm.Title != w.Title || bm.PageFrom != w.PageFrom || bm.Bold != w.Bold || bm.Italic != w.Italic {
+				t.Fatalf("%s depth %d: want %v, got %v\n", msg, depth, w, bm)
+			}
+			if (bm.Color == nil) != (w.Color == nil) || bm.Color != nil && *bm.Color != *w.Color {
+				t.Fatalf("%s %s: want color %v, got %v\n", msg, bm.Title, w.Color, bm.Color)
+			}
+		}
+	}
+
+	// Merge with and replace an existing outline.
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	n := len(topLevelOutlineItems(t, msg, inFile))
+
+	if err := api.AddBookmarksFile(inFile, outFile, bms, false, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ss := topLevelOutlineItems(t, msg, outFile)
+	if len(ss) != n+2 || ss[n] != bms[0].Title || ss[n+1] != bms[1].Title {
+		t.Fatalf("%s: want %d items ending with the added bookmarks, got %v\n", msg, n+2, ss)
+	}
+
+	if err := api.AddBookmarksFile(inFile, outFile, bms, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss := topLevelOutlineItems(t, msg, outFile); len(ss) != 2 {
+		t.Fatalf("%s: want 2 items, got %v\n", msg, ss)
+	}
+
+	bms[1].PageFrom = 1000
+	if err := api.AddBookmarksFile(inFile, outFile, bms, true, nil); err == nil {
+		t.Fatalf("%s: missing error for invalid page number\n", msg)
+	}
+}
+
+func TestExportImportBookmarks(t *testing.T) {
+	msg := "TestExportImportBookmarks"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	namedDestsFile := filepath.Join(outDir, "namedDests.pdf")
+	outFile := filepath.Join(outDir, "importedBookmarks.pdf")
+
+	export := func(fileName string) []byte {
+		t.Helper()
+		jsonFile := filepath.Join(outDir, "bookmarks.json")
+		if err := api.ExportBookmarksFile(fileName, jsonFile, nil); err != nil {
+			t.Fatalf("%s export %s: %v\n", msg, fileName, err)
+		}
+		bb, err := ioutil.ReadFile(jsonFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return bb
+	}
+
+	want := export(inFile)
+	if !bytes.Contains(want, []byte(`"kids"`)) {
+		t.Fatalf("%s: missing nested bookmarks: %s\n", msg, want)
+	}
+
+	// The outline items of inFile point to named destinations of the Dests name tree.
+	// Let the first one point to a named destination of the Dests dict of the catalog instead.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	bms, err := ctx.BookmarksForOutlineLevel1()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.PageDictIndRef(bms[0].PageFrom)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict.Update("Dests", pdf.Dict{"chapter1": pdf.Dict{"D": pdf.Array{*ir, pdf.Name("Fit")}}})
+	firstOutlineItems(t, msg, ctx)[0].Update("Dest", pdf.Name("chapter1"))
+	if err := api.WriteContextFile(ctx, namedDestsFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+	if got := export(namedDestsFile); !bytes.Equal(got, want) {
+		t.Fatalf("%s: named destination not resolved:\n%s\n", msg, got)
+	}
+
+	jsonFile := filepath.Join(outDir, "bookmarksForImport.json")
+	if err := ioutil.WriteFile(jsonFile, want, 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ImportBookmarksFile(namedDestsFile, jsonFile, outFile, nil); err != nil {
+		t.Fatalf("%s import: %v\n", msg, err)
+	}
+	if got := export(outFile); !bytes.Equal(got, want) {
+		t.Fatalf("%s: round trip mismatch:\n%s\n", msg, got)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// writeScanTestFile writes a white 200x100 png with an optional black rectangle r.
+func writeScanTestFile(t *testing.T, msg, fileName string, r *image.Rectangle) {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, 200, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	if r != nil {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestCropToContent(t *testing.T) {
+	msg := "TestCropToContent"
+	inkFile := filepath.Join(outDir, "cropToContentInk.png")
+	whiteFile := filepath.Join(outDir, "cropToContentWhite.png")
+	inFile := filepath.Join(outDir, "cropToContentIn.pdf")
+	outFile := filepath.Join(outDir, "cropToContent.pdf")
+	os.Remove(inFile)
+
+	writeScanTestFile(t, msg, inkFile, &image.Rectangle{Min: image.Point{X: 50, Y: 20}, Max: image.Point{X: 100, Y: 40}})
+	writeScanTestFile(t, msg, whiteFile, nil)
+
+	// Centered on A4 at 1 pixel per point.
+	imp, err := pdf.ParseImportDetails("pos:c, sc:1 abs")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ImportImagesFile([]string{inkFile, whiteFile, whiteFile}, inFile, imp, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Replace the content of page 3 by a line on a white background.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _ := ctx.NewStreamDictForBuf([]byte("1 1 1 rg 0 0 595 842 re f 0 0 0 RG 100 100 m 200 150 l S"))
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(3, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Contents", *ir)
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.CropToContentFile(inFile, outFile, nil, 5, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for i, want := range []string{
+		"[242.50 426.00 302.50 456.00]", // image at 197.5,371
+		"",                              // no content
+		"[95.00 95.00 205.00 155.00]",
+	} {
+		d, _, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		got := ""
+		if a := d.ArrayEntry("CropBox"); a != nil {
+			got = a.String()
+		}
+		if got != want {
+			t.Fatalf("%s: page %d: want crop box %q, got %q\n", msg, i+1, want, got)
+		}
+	}
+}
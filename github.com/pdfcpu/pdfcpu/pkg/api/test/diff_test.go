@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestDiff(t *testing.T) {
+	msg := "TestDiff"
+	fileA := filepath.Join(inDir, "go.pdf")
+	fileB := filepath.Join(outDir, "diff.pdf")
+
+	d, err := api.DiffFile(fileA, fileA, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !d.Equal() {
+		t.Fatalf("%s: unexpected diff:\n%s\n", msg, d)
+	}
+
+	// Change the title, stamp page 3, add a link to page 5 and append a blank page.
+	title := "Diff"
+	if err := api.SetInfoFile(fileA, fileB, pdf.DocumentInfo{Title: &title}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.AddTextWatermarksFile(fileB, "", []string{"3"}, true, "Draft", "sc:1 abs, rot:0", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	link := pdf.LinkAnnotation{Page: 5, Rect: pdf.Rect(10, 10, 100, 30), URI: "https://pdfcpu.io"}
+	if err := api.AddLinkAnnotationsFile(fileB, "", []pdf.LinkAnnotation{link}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.InsertBlankPagesFile(fileB, "", []int{24}, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if d, err = api.DiffFile(fileA, fileB, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d.Equal() || d.PageCountA != 23 || d.PageCountB != 24 {
+		t.Fatalf("%s: unexpected page counts:\n%s\n", msg, d)
+	}
+	// Writing with pdfcpu also updates the producer.
+	if len(d.Info) != 2 || d.Info[0].Key != "Title" || d.Info[0].B != title || d.Info[1].Key != "Producer" {
+		t.Fatalf("%s: unexpected info diff: %v\n", msg, d.Info)
+	}
+	if len(d.Pages) != 3 {
+		t.Fatalf("%s: unexpected page diffs:\n%s\n", msg, d)
+	}
+
+	pd := d.Pages[0]
+	if pd.Page != 3 || !pd.TextChanged() || len(pd.AddedLines) != 1 || pd.AddedLines[0] != "Draft" || len(pd.RemovedLines) != 0 {
+		t.Fatalf("%s: unexpected diff for page 3: %+v\n", msg, pd)
+	}
+
+	pd = d.Pages[1]
+	if pd.Page != 5 || pd.TextChanged() || len(pd.AddedAnnotations) != 1 || pd.AddedAnnotations[0].URI != link.URI {
+		t.Fatalf("%s: unexpected diff for page 5: %+v\n", msg, pd)
+	}
+
+	if pd = d.Pages[2]; pd.Page != 24 || !pd.Added {
+		t.Fatalf("%s: unexpected diff for page 24: %+v\n", msg, pd)
+	}
+}
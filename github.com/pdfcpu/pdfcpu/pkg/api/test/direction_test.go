@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestReadingDirection(t *testing.T) {
+	msg := "TestReadingDirection"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "ReadingDirectionR2L.pdf")
+
+	dir, err := api.ReadingDirectionFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if dir != pdf.L2R {
+		t.Fatalf("%s: want L2R got %s\n", msg, dir)
+	}
+
+	// Lay out pages side by side from right to left.
+	nup, err := pdf.PDFNUpConfig(2, "formsize:A4L, orientation:ld")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// Derive the grid from the landscape paper size.
+	if err := pdf.ParseNUpValue(2, nup); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.NUpFile([]string{inFile}, outFile, []string{"1-4"}, nup, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !pdf.PaperSize["A4"].Portrait() {
+		t.Fatalf("%s: A4L modified paper size A4\n", msg)
+	}
+
+	if err := api.SetReadingDirectionFile(outFile, "", pdf.R2L, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if dir, err = api.ReadingDirectionFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if dir != pdf.R2L {
+		t.Fatalf("%s: want R2L got %s\n", msg, dir)
+	}
+
+	// The first page of each sheet is placed on the right matching the reading direction.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	mm := regexp.MustCompile(`q \S+ \S+ \S+ \S+ (\S+) \S+ cm /\S+ Do Q`).FindAllSubmatch(bb, -1)
+	if len(mm) != 2 {
+		t.Fatalf("%s: want 2 tiles, got %d\n", msg, len(mm))
+	}
+	x1, _ := strconv.ParseFloat(string(mm[0][1]), 64)
+	x2, _ := strconv.ParseFloat(string(mm[1][1]), 64)
+	if x1 <= x2 {
+		t.Fatalf("%s: first page at %.2f not right of second page at %.2f\n", msg, x1, x2)
+	}
+
+	// L2R is the default and removes the viewer preference.
+	if err := api.SetReadingDirectionFile(outFile, "", pdf.L2R, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := rootDict.Find("ViewerPreferences"); found {
+		t.Fatalf("%s: unexpected ViewerPreferences\n", msg)
+	}
+
+	if err := api.SetReadingDirectionFile(inFile, outFile, "RTL", nil); err == nil {
+		t.Fatalf("%s: missing error for invalid direction\n", msg)
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"errors"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -178,3 +181,411 @@ func TestEncryption(t *testing.T) {
 		testEncryption(t, fileName, "aes", 256)
 	}
 }
+
+func readEncryptedContext(t *testing.T, fileName string, conf *pdf.Configuration) *pdf.Context {
+	t.Helper()
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatalf("open %s: %v\n", fileName, err)
+	}
+	defer f.Close()
+	ctx, err := api.ReadContext(f, conf)
+	if err != nil {
+		t.Fatalf("read %s: %v\n", fileName, err)
+	}
+	if err = api.ValidateContext(ctx); err != nil {
+		t.Fatalf("validate %s: %v\n", fileName, err)
+	}
+	return ctx
+}
+
+func TestDecryptIdentityStmF(t *testing.T) {
+	msg := "TestDecryptIdentityStmF"
+	inFile := filepath.Join(inDir, "networkProgr.pdf")
+	encFile := filepath.Join(outDir, "enc.pdf")
+	outFile := filepath.Join(outDir, "identityStmF.pdf")
+
+	conf := confForAlgorithm(true, 128, "upw", "opw")
+	if err := api.EncryptFile(inFile, encFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, encFile, err)
+	}
+
+	// Rewrite the encrypted file leaving streams unencrypted.
+	conf = confForAlgorithm(true, 128, "upw", "")
+	ctx := readEncryptedContext(t, encFile, conf)
+	d, err := ctx.EncryptDict()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("StmF", pdf.Name("Identity"))
+	ctx.IdentityStmF = true
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: write %s: %v\n", msg, outFile, err)
+	}
+
+	want, err := ctx.PageContent(firstPageDict(t, ctx))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx = readEncryptedContext(t, outFile, conf)
+	if !ctx.IdentityStmF || ctx.IdentityStrF {
+		t.Fatalf("%s: want Identity StmF and encrypted StrF\n", msg)
+	}
+
+	// Strings are readable.
+	d, err = ctx.DereferenceDict(*ctx.Info)
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing info dict: %v\n", msg, err)
+	}
+	s, err := ctx.DereferenceText(d["Producer"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.HasPrefix(s, "pdfcpu") {
+		t.Fatalf("%s: unreadable producer: %q\n", msg, s)
+	}
+
+	// Streams are untouched.
+	got, err := ctx.PageContent(firstPageDict(t, ctx))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s: page content mismatch\n", msg)
+	}
+}
+
+func firstPageDict(t *testing.T, ctx *pdf.Context) pdf.Dict {
+	t.Helper()
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil || d == nil {
+		t.Fatalf("missing page 1: %v\n", err)
+	}
+	return d
+}
+
+func TestIsEncrypted(t *testing.T) {
+	msg := "TestIsEncrypted"
+	inFile := filepath.Join(inDir, "networkProgr.pdf")
+	outFile := filepath.Join(outDir, "isEncrypted.pdf")
+
+	check := func(fileName string, wantEnc, wantUPW, wantOPW bool) {
+		t.Helper()
+		enc, upw, opw, err := api.IsEncryptedFile(fileName)
+		if err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, fileName, err)
+		}
+		if enc != wantEnc || upw != wantUPW || opw != wantOPW {
+			t.Fatalf("%s: %s: got (%t %t %t), want (%t %t %t)\n", msg, fileName, enc, upw, opw, wantEnc, wantUPW, wantOPW)
+		}
+	}
+
+	check(inFile, false, false, false)
+
+	for _, alg := range []struct {
+		aes       bool
+		keyLength int
+	}{
+		{false, 40},
+		{false, 128},
+		{true, 128},
+		{true, 256},
+	} {
+		// Owner restricted only.
+		conf := confForAlgorithm(alg.aes, alg.keyLength, "", "opw")
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+		check(outFile, true, false, true)
+
+		// Document open password required.
+		conf = confForAlgorithm(alg.aes, alg.keyLength, "upw", "opw")
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+		check(outFile, true, true, true)
+	}
+}
+
+func TestEncryptionAES256R6(t *testing.T) {
+	msg := "TestEncryptionAES256R6"
+	inFile := filepath.Join(inDir, "networkProgr.pdf")
+	outFile := filepath.Join(outDir, "aes256r6.pdf")
+
+	conf := pdf.NewDefaultConfiguration()
+	conf.UserPW, conf.OwnerPW = "upw", "opw"
+	conf.EncryptUsingAES256 = true
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+
+	// Ensure the PDF 2.0 security handler got written.
+	ctx := readEncryptedContext(t, outFile, pdf.NewAESConfiguration("upw", "", 256))
+	d, err := ctx.EncryptDict()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if v, r := d.IntEntry("V"), d.IntEntry("R"); v == nil || *v != 5 || r == nil || *r != 6 {
+		t.Fatalf("%s: want V 5 R 6, got %s\n", msg, d)
+	}
+
+	// Opening w/o passwords or with a wrong password should fail.
+	for _, pw := range []string{"", "opwx"} {
+		conf = pdf.NewAESConfiguration(pw, pw, 256)
+		if _, err := api.ListPermissionsFile(outFile, conf); err == nil {
+			t.Fatalf("%s: list permissions using %q succeeded\n", msg, pw)
+		}
+	}
+
+	// Either password grants access.
+	for _, pws := range [][2]string{{"upw", ""}, {"", "opw"}} {
+		conf = pdf.NewAESConfiguration(pws[0], pws[1], 256)
+		p, err := api.GetPermissionsFile(outFile, conf)
+		if err != nil {
+			t.Fatalf("%s: get permissions %s: %v\n", msg, outFile, err)
+		}
+		if p == nil || *p != pdf.PermissionsNone {
+			t.Fatalf("%s: want permissions none\n", msg)
+		}
+	}
+
+	// Decrypt file using both passwords.
+	conf = pdf.NewAESConfiguration("upw", "opw", 256)
+	if err = api.DecryptFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: decrypt %s: %v\n", msg, outFile, err)
+	}
+
+	// Validate decrypted file.
+	if err = api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate %s: %v\n", msg, outFile, err)
+	}
+}
+
+func TestPermissionFlags(t *testing.T) {
+	msg := "TestPermissionFlags"
+	inFile := filepath.Join(inDir, "networkProgr.pdf")
+	outFile := filepath.Join(outDir, "permissionFlags.pdf")
+
+	if pf, err := api.GetPermissionFlagsFile(inFile, nil); err != nil || pf != nil {
+		t.Fatalf("%s: want full access for %s: %v %v\n", msg, inFile, pf, err)
+	}
+
+	pf := pdf.PermissionFlags{Print: true, Copy: true, FillForms: true, Assemble: true}
+	all := pdf.PermissionFlags{
+		Print: true, HighResPrint: true, Modify: true, Copy: true,
+		Annotate: true, FillForms: true, Extract: true, Assemble: true,
+	}
+
+	for _, tt := range []struct {
+		aes       bool
+		keyLength int
+		want      pdf.PermissionFlags
+		p         int16
+	}{
+		// Revision 2 folds fill forms into annotate and assemble into modify.
+		{false, 40, all, -3841},
+		{false, 128, pf, -2601},
+		{true, 256, pf, -2601},
+	} {
+		conf := confForAlgorithm(tt.aes, tt.keyLength, "upw", "opw")
+		conf.PermissionFlags = &pf
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+
+		conf = confForAlgorithm(tt.aes, tt.keyLength, "upw", "")
+		p, err := api.GetPermissionsFile(outFile, conf)
+		if err != nil {
+			t.Fatalf("%s: get permissions %s: %v\n", msg, outFile, err)
+		}
+		if p == nil || *p != tt.p {
+			t.Fatalf("%s: %s-%d: want /P %d, got %v\n", msg, map[bool]string{false: "rc4", true: "aes"}[tt.aes], tt.keyLength, tt.p, p)
+		}
+
+		got, err := api.GetPermissionFlagsFile(outFile, conf)
+		if err != nil {
+			t.Fatalf("%s: get permission flags %s: %v\n", msg, outFile, err)
+		}
+		if got == nil || *got != tt.want {
+			t.Fatalf("%s: want %+v, got %+v\n", msg, tt.want, got)
+		}
+	}
+
+	// Update permissions of the AES-256 encrypted file.
+	conf := confForAlgorithm(true, 256, "upw", "opw")
+	conf.PermissionFlags = &pdf.PermissionFlags{HighResPrint: true, Print: true}
+	if err := api.SetPermissionsFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: set permissions %s: %v\n", msg, outFile, err)
+	}
+	got, err := api.GetPermissionFlagsFile(outFile, confForAlgorithm(true, 256, "", "opw"))
+	if err != nil {
+		t.Fatalf("%s: get permission flags %s: %v\n", msg, outFile, err)
+	}
+	if got == nil || *got != *conf.PermissionFlags {
+		t.Fatalf("%s: want %+v, got %+v\n", msg, *conf.PermissionFlags, got)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	msg := "TestChangePassword"
+	inFile := filepath.Join(inDir, "networkProgr.pdf")
+	outFile := filepath.Join(outDir, "changePassword.pdf")
+
+	pf := pdf.PermissionFlags{Print: true, Annotate: true}
+
+	for _, alg := range []struct {
+		aes       bool
+		keyLength int
+	}{
+		{false, 128},
+		{true, 128},
+		{true, 256},
+	} {
+		conf := confForAlgorithm(alg.aes, alg.keyLength, "upw", "opw")
+		conf.PermissionFlags = &pf
+		if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+		}
+		encKey := readEncryptedContext(t, outFile, confForAlgorithm(alg.aes, alg.keyLength, "upw", "")).EncKey
+
+		// A wrong owner password fails.
+		if err := api.ChangePasswordFile(outFile, "", "upw", "upwNew", "opwNew", nil); err == nil {
+			t.Fatalf("%s: change password using the user password succeeded\n", msg)
+		}
+
+		if err := api.ChangePasswordFile(outFile, "", "opw", "upwNew", "opwNew", nil); err != nil {
+			t.Fatalf("%s: change password %s: %v\n", msg, outFile, err)
+		}
+
+		// The old passwords are gone.
+		for _, pws := range [][2]string{{"upw", ""}, {"", "opw"}} {
+			if _, err := api.GetPermissionsFile(outFile, confForAlgorithm(alg.aes, alg.keyLength, pws[0], pws[1])); err == nil {
+				t.Fatalf("%s: opened %s using old passwords %v\n", msg, outFile, pws)
+			}
+		}
+
+		// The new passwords work and permissions are preserved.
+		for _, pws := range [][2]string{{"upwNew", ""}, {"", "opwNew"}} {
+			got, err := api.GetPermissionFlagsFile(outFile, confForAlgorithm(alg.aes, alg.keyLength, pws[0], pws[1]))
+			if err != nil {
+				t.Fatalf("%s: get permission flags %s: %v\n", msg, outFile, err)
+			}
+			if got == nil || *got != pf {
+				t.Fatalf("%s: want %+v, got %+v\n", msg, pf, got)
+			}
+		}
+
+		// AES-256 keeps the file encryption key.
+		ctx := readEncryptedContext(t, outFile, confForAlgorithm(alg.aes, alg.keyLength, "upwNew", ""))
+		if sameKey := bytes.Equal(ctx.EncKey, encKey); sameKey != (alg.keyLength == 256) {
+			t.Fatalf("%s: %d: same file encryption key: %t\n", msg, alg.keyLength, sameKey)
+		}
+	}
+
+	// Override permissions.
+	conf := pdf.NewDefaultConfiguration()
+	conf.PermissionFlags = &pdf.PermissionFlags{}
+	if err := api.ChangePasswordFile(outFile, "", "opwNew", "upw", "opw", conf); err != nil {
+		t.Fatalf("%s: change password %s: %v\n", msg, outFile, err)
+	}
+	p, err := api.GetPermissionsFile(outFile, confForAlgorithm(true, 256, "upw", ""))
+	if err != nil {
+		t.Fatalf("%s: get permissions %s: %v\n", msg, outFile, err)
+	}
+	if p == nil || *p != pdf.PermissionsNone {
+		t.Fatalf("%s: want permissions none, got %v\n", msg, p)
+	}
+}
+
+func TestReadContextPasswordFunc(t *testing.T) {
+	msg := "TestReadContextPasswordFunc"
+	inFile := filepath.Join(inDir, "networkProgr.pdf")
+	outFile := filepath.Join(outDir, "passwordFunc.pdf")
+
+	read := func(fileName, upw, pw string, pwErr error) (int, error) {
+		t.Helper()
+		bb, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		var calls int
+		conf := pdf.NewDefaultConfiguration()
+		conf.UserPW = upw
+		conf.PasswordFunc = func() (string, error) {
+			calls++
+			return pw, pwErr
+		}
+		_, err = api.ReadContext(bytes.NewReader(bb), conf)
+		return calls, err
+	}
+
+	check := func(fileName, upw, pw string, wantCalls int, wantErr error) {
+		t.Helper()
+		calls, err := read(fileName, upw, pw, nil)
+		if calls != wantCalls || err != wantErr {
+			t.Fatalf("%s: %s upw=%q pw=%q: want %d calls, err %v, got: %d, %v\n", msg, fileName, upw, pw, wantCalls, wantErr, calls, err)
+		}
+	}
+
+	// Unencrypted files do not need a password.
+	check(inFile, "", "upw", 0, nil)
+
+	// Owner restricted files open with the empty user password.
+	if err := api.EncryptFile(inFile, outFile, confForAlgorithm(true, 256, "", "opw")); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	check(outFile, "", "opw", 0, nil)
+
+	for _, alg := range []struct {
+		aes       bool
+		keyLength int
+	}{
+		{false, 128},
+		{true, 256},
+	} {
+		if err := api.EncryptFile(inFile, outFile, confForAlgorithm(alg.aes, alg.keyLength, "upw", "opw")); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		check(outFile, "", "upw", 1, nil)
+		check(outFile, "", "opw", 1, nil)
+		check(outFile, "", "wrong", 1, pdf.ErrWrongPassword)
+
+		// A preset password bypasses the callback.
+		check(outFile, "upw", "", 0, nil)
+		check(outFile, "wrong", "upw", 0, pdf.ErrWrongPassword)
+	}
+
+	// Errors of the callback get passed on.
+	pwErr := errors.New("no password available")
+	if _, err := read(outFile, "", "", pwErr); err != pwErr {
+		t.Fatalf("%s: want %v, got: %v\n", msg, pwErr, err)
+	}
+
+	// A shared configuration does not retain the password fetched for a previous file.
+	outFile2 := filepath.Join(outDir, "passwordFunc2.pdf")
+	if err := api.EncryptFile(inFile, outFile2, confForAlgorithm(true, 256, "upw2", "opw2")); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pws := []string{"upw", "upw2"}
+	conf := pdf.NewDefaultConfiguration()
+	conf.PasswordFunc = func() (string, error) {
+		pw := pws[0]
+		pws = pws[1:]
+		return pw, nil
+	}
+	for _, fileName := range []string{outFile, outFile2} {
+		f, err := os.Open(fileName)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		_, err = api.ReadContext(f, conf)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, fileName, err)
+		}
+	}
+	if len(pws) != 0 || conf.UserPW != "" || conf.OwnerPW != "" {
+		t.Fatalf("%s: want both passwords fetched and none retained, got: %v %q %q\n", msg, pws, conf.UserPW, conf.OwnerPW)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestExtractImages(t *testing.T) {
@@ -101,6 +102,76 @@ func TestExtractFonts(t *testing.T) {
 	}
 }
 
+func TestExtractFontFiles(t *testing.T) {
+	msg := "TestExtractFontFiles"
+
+	for _, tt := range []struct {
+		fileName string
+		want     map[string]string // font file contents prefix by extension
+	}{
+		// Type1 font programs become printer font binaries.
+		{"golang.pdf", map[string]string{"ttf": "\x74\x72\x75\x65", "pfb": "\x80\x01"}},
+		{"TheGoProgrammingLanguageCh1.pdf", map[string]string{"ttf": "\x00\x01\x00\x00", "cff": "\x01\x00"}},
+	} {
+		dir, err := ioutil.TempDir(outDir, "extractFonts")
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ExtractFontsFile(filepath.Join(inDir, tt.fileName), dir, nil, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.fileName, err)
+		}
+
+		ff, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		found := map[string]bool{}
+		for _, fi := range ff {
+			ext := strings.TrimPrefix(filepath.Ext(fi.Name()), ".")
+			prefix, ok := tt.want[ext]
+			if !ok {
+				t.Fatalf("%s %s: unexpected font file: %s\n", msg, tt.fileName, fi.Name())
+			}
+			b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			if !strings.HasPrefix(string(b), prefix) {
+				t.Fatalf("%s %s: corrupt font file: %s\n", msg, tt.fileName, fi.Name())
+			}
+			found[ext] = true
+		}
+		for ext := range tt.want {
+			if !found[ext] {
+				t.Fatalf("%s %s: missing %s font file\n", msg, tt.fileName, ext)
+			}
+		}
+	}
+
+	// Shared fonts are extracted once and subsetted fonts keep their subset tag.
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "golang.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.OptimizeContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ff, err := ctx.ExtractFonts(nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	names := map[string]bool{}
+	for _, f := range ff {
+		if names[f.Name] {
+			t.Fatalf("%s: duplicate font: %s\n", msg, f.Name)
+		}
+		names[f.Name] = true
+	}
+	if !names["CAAAAA+TimesNewRomanPSMT"] {
+		t.Fatalf("%s: missing subsetted font in %v\n", msg, names)
+	}
+}
+
 func TestExtractFontsLowLevel(t *testing.T) {
 	msg := "TestExtractFontsLowLevel"
 	inFile := filepath.Join(inDir, "go.pdf")
@@ -243,3 +314,93 @@ func TestExtractMetadataLowLevel(t *testing.T) {
 			md.ObjNr, md.ParentObjNr, md.ParentType, string(bb))
 	}
 }
+
+func TestExtractPageResources(t *testing.T) {
+	msg := "TestExtractPageResources"
+	outFile := filepath.Join(outDir, "pageResources.pdf")
+
+	ctxSrc, err := api.ReadContextFile(filepath.Join(inDir, "Walden.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	pr, err := ctxSrc.ExtractPageResources(1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if pr.Dict.DictEntry("Font") == nil || len(pr.Objects) == 0 {
+		t.Fatalf("%s: incomplete resources bundle: %s\n", msg, pr.Dict)
+	}
+
+	r, err := ctxSrc.ExtractPageContent(1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Reuse the look of the source page for page 1 of another document.
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	size := *ctx.Size
+
+	res, err := ctx.AddPageResources(pr)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Any resource gets copied into new objects.
+	for id, o := range res.DictEntry("Font") {
+		ir, ok := o.(pdf.IndirectRef)
+		if !ok || ir.ObjectNumber.Value() < size {
+			t.Fatalf("%s: font %s: want new object, got: %v\n", msg, id, o)
+		}
+	}
+
+	sd, err := ctx.NewStreamDictForBuf(bb)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Resources", res)
+	d.Update("Contents", *ir)
+	d.Delete("Annots")
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	text := func(fileName string) string {
+		rr, err := api.ExtractTextWithLinksFile(fileName, []string{"1"}, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		var sb strings.Builder
+		for _, r := range rr {
+			sb.WriteString(r.Text)
+		}
+		return sb.String()
+	}
+
+	if want, got := text(filepath.Join(inDir, "Walden.pdf")), text(outFile); want == "" || got != want {
+		t.Fatalf("%s: want text:\n%s\ngot:\n%s\n", msg, want, got)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestListFontsFile(t *testing.T) {
+	msg := "TestListFontsFile"
+	inFile := filepath.Join(inDir, "go.pdf")
+
+	ff, err := api.ListFontsFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	seen := map[int]bool{}
+	var arial, wingdings bool
+
+	for _, f := range ff {
+		if seen[f.ObjNr] {
+			t.Fatalf("%s: font reported twice: %s\n", msg, f)
+		}
+		seen[f.ObjNr] = true
+		for i := 1; i < len(f.Pages); i++ {
+			if f.Pages[i] <= f.Pages[i-1] {
+				t.Fatalf("%s: unsorted pages: %s\n", msg, f)
+			}
+		}
+		switch {
+		case f.Name == "Arial" && f.Type == "TrueType":
+			// Referenced font used throughout the document.
+			arial = !f.Embedded && !f.Subset && f.Encoding == "WinAnsiEncoding" && len(f.Pages) == 21 && f.Pages[0] == 1
+		case f.Name == "ABCDEE+Wingdings":
+			wingdings = f.Embedded && f.Subset && f.Type == "Type0" && f.Encoding == "Identity-H"
+		}
+	}
+
+	if !arial || !wingdings {
+		t.Fatalf("%s: unexpected fonts: %v\n", msg, ff)
+	}
+}
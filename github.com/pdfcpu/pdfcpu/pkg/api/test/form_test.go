@@ -0,0 +1,562 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// formFieldDict returns the i-th top level field of the interactive form of xRefTable.
+func formFieldDict(t *testing.T, xRefTable *pdf.XRefTable, i int, msg string) pdf.Dict {
+	t.Helper()
+
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	acroForm, err := xRefTable.DereferenceDict(rootDict["AcroForm"])
+	if err != nil || acroForm == nil {
+		t.Fatalf("%s: missing AcroForm: %v\n", msg, err)
+	}
+
+	fields, err := xRefTable.DereferenceArray(acroForm["Fields"])
+	if err != nil || len(fields) <= i {
+		t.Fatalf("%s: missing field %d: %v\n", msg, i, err)
+	}
+
+	d, err := xRefTable.DereferenceDict(fields[i])
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing field %d: %v\n", msg, i, err)
+	}
+
+	return d
+}
+
+func TestRegenerateAppearances(t *testing.T) {
+	msg := "TestRegenerateAppearances"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Strip the appearances of the text field and the check box.
+	formFieldDict(t, xRefTable, 0, msg).Delete("AP")
+	formFieldDict(t, xRefTable, 1, msg).Delete("AP")
+
+	inFile := filepath.Join(outDir, "AcroFormNoAP.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	outFile := filepath.Join(outDir, "AcroFormRegenAP.pdf")
+	if err := api.RegenerateAppearancesFile(inFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The text field appearance shows the field value.
+	d := formFieldDict(t, ctx.XRefTable, 0, msg)
+	ap, err := ctx.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		t.Fatalf("%s: missing text field appearance: %v\n", msg, err)
+	}
+	sd, err := ctx.DereferenceStreamDict(ap["N"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing normal text field appearance: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Contains(sd.Content, []byte("(Default value) Tj")) {
+		t.Fatalf("%s: text field appearance does not render field value: %s\n", msg, sd.Content)
+	}
+
+	// The check box appearance state matches the field value.
+	d = formFieldDict(t, ctx.XRefTable, 1, msg)
+	if as := d.NameEntry("AS"); as == nil || *as != "Yes" {
+		t.Fatalf("%s: check box appearance state: got %v want Yes\n", msg, as)
+	}
+	ap, err = ctx.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		t.Fatalf("%s: missing check box appearance: %v\n", msg, err)
+	}
+	n, err := ctx.DereferenceDict(ap["N"])
+	if err != nil || n == nil {
+		t.Fatalf("%s: missing normal check box appearances: %v\n", msg, err)
+	}
+	for _, state := range []string{"Yes", "Off"} {
+		if _, found := n.Find(state); !found {
+			t.Fatalf("%s: missing check box appearance state %s\n", msg, state)
+		}
+	}
+}
+
+func TestFormValues(t *testing.T) {
+	msg := "TestFormValues"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormValues.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m, err := api.FormValuesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := map[string]string{
+		"inputField":         "Default value",
+		"CheckBox":           "Yes",
+		"Credit card.Radio1": "card1",
+		"Credit card.Radio2": "",
+	}
+
+	if len(m) != len(want) {
+		t.Fatalf("%s: got %v want %v\n", msg, m, want)
+	}
+
+	for k, v := range want {
+		if got, ok := m[k]; !ok || got != v {
+			t.Fatalf("%s: %s: got %q want %q\n", msg, k, got, v)
+		}
+	}
+}
+
+func TestFormValuesFieldCycle(t *testing.T) {
+	msg := "TestFormValuesFieldCycle"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Make the radio button group a kid of itself.
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	acroForm, err := xRefTable.DereferenceDict(rootDict["AcroForm"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, ok := acroForm.ArrayEntry("Fields")[2].(pdf.IndirectRef)
+	if !ok {
+		t.Fatalf("%s: missing indirect field reference\n", msg)
+	}
+	d := formFieldDict(t, xRefTable, 2, msg)
+	d.Update("Kids", append(d.ArrayEntry("Kids"), ir))
+
+	inFile := filepath.Join(outDir, "AcroFormFieldCycle.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// By default the cycle is an error.
+	conf := pdf.NewDefaultConfiguration()
+	conf.ValidationMode = pdf.ValidationNone
+	if _, err := api.FormValuesFile(inFile, conf); err == nil || !strings.Contains(err.Error(), "recursive reference") {
+		t.Fatalf("%s: want cycle error, got: %v\n", msg, err)
+	}
+
+	// Break the cycle and collect all fields once.
+	conf.CycleMode = pdf.CycleBreak
+	m, err := api.FormValuesFile(inFile, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(m) != 4 || m["Credit card.Radio1"] != "card1" {
+		t.Fatalf("%s: got %v\n", msg, m)
+	}
+}
+
+func TestSetFieldFlags(t *testing.T) {
+	msg := "TestSetFieldFlags"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormFlags.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	flags := map[string]pdf.FieldFlags{
+		"inputField":         {Required: true},
+		"Credit card.Radio1": {ReadOnly: true, NoExport: true},
+	}
+
+	outFile := filepath.Join(outDir, "AcroFormFlagsSet.pdf")
+	if err := api.SetFieldFlagsFile(inFile, outFile, flags, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d := formFieldDict(t, ctx.XRefTable, 0, msg)
+	if ff := d.IntEntry("Ff"); ff == nil || *ff != pdf.FieldRequired {
+		t.Fatalf("%s: inputField: got Ff=%v want %d\n", msg, ff, pdf.FieldRequired)
+	}
+
+	d = formFieldDict(t, ctx.XRefTable, 2, msg)
+	kid, err := ctx.DereferenceDict(d.ArrayEntry("Kids")[0])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// The inherited radio flag has to be preserved.
+	want := pdf.FieldRadio | pdf.FieldReadOnly | pdf.FieldNoExport
+	if ff := kid.IntEntry("Ff"); ff == nil || *ff != want {
+		t.Fatalf("%s: Radio1: got Ff=%v want %d\n", msg, ff, want)
+	}
+
+	if err := api.SetFieldFlagsFile(inFile, outFile, map[string]pdf.FieldFlags{"missing": {}}, nil); err == nil {
+		t.Fatalf("%s: unknown field should fail\n", msg)
+	}
+}
+
+func TestFillForm(t *testing.T) {
+	msg := "TestFillForm"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormFill.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	values := map[string]string{
+		"inputField":         "Hello (world)",
+		"CheckBox":           "off",
+		"Credit card.Radio2": "card2",
+	}
+
+	outFile := filepath.Join(outDir, "AcroFormFilled.pdf")
+	if err := api.FillFormFile(inFile, outFile, values, false, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m, err := api.FormValuesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for k, v := range map[string]string{
+		"inputField":         "Hello (world)",
+		"CheckBox":           "",
+		"Credit card.Radio1": "card1",
+		"Credit card.Radio2": "card2",
+	} {
+		if got := m[k]; got != v {
+			t.Fatalf("%s: %s: got %q want %q\n", msg, k, got, v)
+		}
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The text field appearance shows the new value.
+	d := formFieldDict(t, ctx.XRefTable, 0, msg)
+	ap, err := ctx.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		t.Fatalf("%s: missing text field appearance: %v\n", msg, err)
+	}
+	sd, err := ctx.DereferenceStreamDict(ap["N"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing normal text field appearance: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Contains(sd.Content, []byte("(Hello \\(world\\)) Tj")) {
+		t.Fatalf("%s: text field appearance does not render field value: %s\n", msg, sd.Content)
+	}
+
+	d = formFieldDict(t, ctx.XRefTable, 1, msg)
+	if as := d.NameEntry("AS"); as == nil || *as != "Off" {
+		t.Fatalf("%s: check box appearance state: got %v want Off\n", msg, as)
+	}
+
+	// Unknown fields are listed.
+	err = api.FillFormFile(inFile, outFile, map[string]string{"b": "", "a": "", "CheckBox": "on"}, false, nil)
+	if err == nil || !strings.Contains(err.Error(), "a, b") {
+		t.Fatalf("%s: want error listing unknown fields, got: %v\n", msg, err)
+	}
+
+	for name, v := range map[string]string{"CheckBox": "maybe", "Credit card.Radio2": "card3"} {
+		if err := api.FillFormFile(inFile, outFile, map[string]string{name: v}, false, nil); err == nil {
+			t.Fatalf("%s: %s: want error for invalid button value %q\n", msg, name, v)
+		}
+	}
+}
+
+func TestFillFormFlatten(t *testing.T) {
+	msg := "TestFillFormFlatten"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormFlattenIn.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	values := map[string]string{"inputField": "Flattened", "CheckBox": "true"}
+
+	outFile := filepath.Join(outDir, "AcroFormFlatten.pdf")
+	if err := api.FillFormFile(inFile, outFile, values, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	// Flattened fields are gone.
+	m, err := api.FormValuesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for k := range values {
+		if _, ok := m[k]; ok {
+			t.Fatalf("%s: %s: field not removed\n", msg, k)
+		}
+	}
+	if _, ok := m["Credit card.Radio1"]; !ok {
+		t.Fatalf("%s: unfilled field removed\n", msg)
+	}
+
+	// The value is part of the page content now.
+	pp, err := api.ExtractTextFile(outFile, []string{"1"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	found := false
+	for _, ts := range pp[0].Spans {
+		if ts.Text == "Flattened" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("%s: missing flattened text field value: %v\n", msg, pp[0].Spans)
+	}
+}
+
+func TestExportImportForm(t *testing.T) {
+	msg := "TestExportImportForm"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormExport.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	filledFile := filepath.Join(outDir, "AcroFormExportFilled.pdf")
+	values := map[string]string{"inputField": "Grüße (1)", "CheckBox": "off", "Credit card.Radio2": "card2"}
+	if err := api.FillFormFile(inFile, filledFile, values, false, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want, err := api.FormValuesFile(filledFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		dataFile string
+		contains []string
+	}{
+		{"AcroFormExport.fdf", []string{"%FDF-1.2", "/T(Credit card)", "/T(Radio2)", "/V/card2", "/V/Off"}},
+		{"AcroFormExport.xfdf", []string{`<field name="Credit card">`, `<field name="Radio2">`, "<value>card2</value>", "<value>Grüße (1)</value>"}},
+	} {
+		dataFile := filepath.Join(outDir, tt.dataFile)
+		if err := api.ExportFormFile(filledFile, dataFile, nil); err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, tt.dataFile, err)
+		}
+
+		bb, err := ioutil.ReadFile(dataFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, s := range tt.contains {
+			if !strings.Contains(string(bb), s) {
+				t.Fatalf("%s: %s: missing %q:\n%s\n", msg, tt.dataFile, s, bb)
+			}
+		}
+
+		// Importing into the unfilled form reproduces the filled values.
+		outFile := filepath.Join(outDir, "AcroFormImport.pdf")
+		if err := api.ImportFormFile(inFile, dataFile, outFile, nil); err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, tt.dataFile, err)
+		}
+		got, err := api.FormValuesFile(outFile, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: %s: got %v want %v\n", msg, tt.dataFile, got, want)
+		}
+	}
+
+	if err := api.ExportFormFile(inFile, filepath.Join(outDir, "AcroFormExport.txt"), nil); err == nil {
+		t.Fatalf("%s: want error for unsupported data file\n", msg)
+	}
+}
+
+func TestExportImportFormMultiSelect(t *testing.T) {
+	msg := "TestExportImportFormMultiSelect"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Turn the text field into a multiple selection list box with items containing commas.
+	d := formFieldDict(t, xRefTable, 0, msg)
+	d.Update("FT", pdf.Name("Ch"))
+	d.Update("Ff", pdf.Integer(pdf.FieldMultiSelect))
+	d.Update("Opt", pdf.Array{pdf.StringLiteral("Smith, John"), pdf.StringLiteral("Doe, Jane"), pdf.StringLiteral("Roe")})
+	d.Delete("V")
+	d.Delete("DV")
+
+	inFile := filepath.Join(outDir, "AcroFormMultiSelect.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := []string{"Smith, John", "Roe"}
+	if err := ctx.ImportFormData(&pdf.FormData{Values: map[string][]string{"inputField": want}}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	filledFile := filepath.Join(outDir, "AcroFormMultiSelectFilled.pdf")
+	if err := api.WriteContextFile(ctx, filledFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, dataFile := range []string{"AcroFormMultiSelect.fdf", "AcroFormMultiSelect.xfdf"} {
+		dataFile = filepath.Join(outDir, dataFile)
+		if err := api.ExportFormFile(filledFile, dataFile, nil); err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, dataFile, err)
+		}
+
+		outFile := filepath.Join(outDir, "AcroFormMultiSelectImport.pdf")
+		if err := api.ImportFormFile(inFile, dataFile, outFile, nil); err != nil {
+			t.Fatalf("%s: %s: %v\n", msg, dataFile, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		fd, err := ctx.FormData()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if got := fd.Values["inputField"]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: %s: got %q want %q\n", msg, dataFile, got, want)
+		}
+	}
+}
+
+func TestListFormFields(t *testing.T) {
+	msg := "TestListFormFields"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	formFieldDict(t, xRefTable, 0, msg).Update("Ff", pdf.Integer(pdf.FieldRequired|pdf.FieldReadOnly))
+
+	// Turn the radio buttons into widgets of a single radio button field.
+	for _, o := range formFieldDict(t, xRefTable, 2, msg).ArrayEntry("Kids") {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d.Delete("T")
+		d.Delete("TU")
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormList.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	got, err := api.ListFormFieldsFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := []pdf.FormField{
+		{Name: "inputField", Type: "text", Value: "Default value", Required: true, ReadOnly: true, Page: 1},
+		{Name: "CheckBox", Type: "checkbox", Value: "Yes", Page: 1},
+		{Name: "Credit card", Type: "radio", Value: "card1", Options: []string{"card1", "card2"}, Page: 1},
+		{Name: "Reset", Type: "button", Page: 1},
+		{Name: "Submit", Type: "button", Page: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s:\ngot  %v\nwant %v\n", msg, got, want)
+	}
+
+	bb1, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Equal(bb, bb1) {
+		t.Fatalf("%s: input file modified\n", msg)
+	}
+}
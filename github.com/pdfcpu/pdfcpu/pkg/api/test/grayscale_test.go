@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestToGrayscale(t *testing.T) {
+	msg := "TestToGrayscale"
+	inFile := filepath.Join(outDir, "grayscaleIn.pdf")
+	outFile := filepath.Join(outDir, "grayscale.pdf")
+	os.Remove(inFile)
+
+	// A flate encoded and a DCT encoded RGB image.
+	imgFiles := []string{filepath.Join(resDir, "logoSmall.png"), filepath.Join(resDir, "snow.jpg")}
+	if err := api.ImportImagesFile(imgFiles, inFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Paint colored rectangles on page 1.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _ := ctx.NewStreamDictForBuf([]byte("q 1 0 0 rg 0 0 1 RG 0 0 10 10 re B 0 1 1 0 k /DeviceRGB CS 0 1 0 SC 10 10 10 10 re B Q"))
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Contents", pdf.Array{d["Contents"], *ir})
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ToGrayscaleFile(inFile, outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if d, _, err = ctx.PageDict(1, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	s := string(bb)
+	for _, op := range []string{" rg ", " RG ", " k ", "DeviceRGB"} {
+		if strings.Contains(s, op) {
+			t.Fatalf("%s: color operator %q left in content: %s\n", msg, op, s)
+		}
+	}
+	for _, op := range []string{"Q\nq", "0.300 g 0.110 G", "0.300 g /DeviceGray CS 0.590 SC"} {
+		if !strings.Contains(s, op) {
+			t.Fatalf("%s: missing %q in content: %s\n", msg, op, s)
+		}
+	}
+
+	filters := []string{}
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		resDict, err := ctx.DereferenceDict(d["Resources"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		xObjects, err := ctx.DereferenceDict(resDict["XObject"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, o := range xObjects {
+			sd, err := ctx.DereferenceStreamDict(o)
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			if cs := sd.NameEntry("ColorSpace"); cs == nil || *cs != pdf.DeviceGrayCS {
+				t.Fatalf("%s: page %d: image not converted: %s\n", msg, i, sd.Dict)
+			}
+			filters = append(filters, sd.FilterPipeline[0].Name)
+		}
+	}
+	if len(filters) != 2 || filters[0] == filters[1] || filters[0] != filter.DCT && filters[1] != filter.DCT {
+		t.Fatalf("%s: want one DCT and one flate encoded gray image, got %v\n", msg, filters)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestImageResolutionReport(t *testing.T) {
+	msg := "TestImageResolutionReport"
+	imgFile := filepath.Join(resDir, "logoSmall.png")
+	outFile := filepath.Join(outDir, "imageResolution.pdf")
+	os.Remove(outFile)
+
+	// The same image rendered at its pixel size and at a quarter of it.
+	for _, s := range []string{"pos:c, sc:1 abs", "pos:c, sc:.25 abs"} {
+		imp, err := pdf.ParseImportDetails(s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ImportImagesFile([]string{imgFile}, outFile, imp, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	ii, err := api.ImageResolutionReportFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ii) != 2 {
+		t.Fatalf("%s: want 2 placements, got %d\n", msg, len(ii))
+	}
+
+	for i, tt := range []struct {
+		dpi    float64
+		lowRes bool
+	}{
+		{72, true},
+		{288, false},
+	} {
+		ip := ii[i]
+		t.Log(ip)
+		if ip.Page != i+1 {
+			t.Fatalf("%s: placement %d: want page %d got %d\n", msg, i, i+1, ip.Page)
+		}
+		if math.Abs(ip.DPI()-tt.dpi) > 1 {
+			t.Fatalf("%s: page %d: want %.0f dpi got %.2f\n", msg, ip.Page, tt.dpi, ip.DPI())
+		}
+		if ip.LowRes != tt.lowRes {
+			t.Fatalf("%s: page %d: want lowRes %t\n", msg, ip.Page, tt.lowRes)
+		}
+	}
+
+	// A lower threshold accepts the larger rendition.
+	conf := pdf.NewDefaultConfiguration()
+	conf.MinImageDPI = 72
+	if ii, err = api.ImageResolutionReportFile(outFile, []string{"1"}, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ii) != 1 || ii[0].LowRes {
+		t.Fatalf("%s: unexpected report for page 1: %v\n", msg, ii)
+	}
+}
@@ -19,11 +19,15 @@ package test
 import (
 	"bufio"
 	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/hhrutter/tiff"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
@@ -110,3 +114,156 @@ func TestMemBasedWriterPanic(t *testing.T) {
 	}
 
 }
+
+func TestImportImagesWithText(t *testing.T) {
+	msg := "TestImportImagesWithText"
+	outFile := filepath.Join(outDir, "importImagesWithText.pdf")
+	os.Remove(outFile)
+
+	// logoSmall.png is 500 x 550 pixels, word boxes use the upper left image corner as origin.
+	ocr := [][]pdf.OCRLine{
+		{
+			{{Text: "Hello", Box: pdf.Rect(50, 100, 180, 140)}, {Text: "world", Box: pdf.Rect(200, 100, 300, 140)}},
+			{{Text: "second", Box: pdf.Rect(50, 200, 150, 230)}, {Text: "line", Box: pdf.Rect(160, 200, 220, 230)}},
+		},
+		{
+			{{Text: "QR", Box: pdf.Rect(10, 10, 40, 30)}},
+		},
+	}
+	imgFiles := []string{filepath.Join(resDir, "logoSmall.png"), filepath.Join(resDir, "qr.png")}
+
+	if err := api.ImportImagesWithTextFile(imgFiles, ocr, outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Append another page to the tagged document.
+	if err := api.ImportImagesWithTextFile(imgFiles[:1], ocr[:1], outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// One paragraph per line.
+	ee, err := api.ExportStructureTreeFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ee) != 1 || ee[0].Type != "Document" {
+		t.Fatalf("%s: want single Document element, got: %v\n", msg, ee)
+	}
+	want := []struct{ page, mcid int }{{1, 0}, {1, 1}, {2, 0}, {3, 0}, {3, 1}}
+	if len(ee[0].Kids) != len(want) {
+		t.Fatalf("%s: want %d paragraphs, got: %v\n", msg, len(want), ee[0].Kids)
+	}
+	for i, e := range ee[0].Kids {
+		if e.Type != "P" || e.Page != want[i].page || len(e.MCIDs) != 1 || e.MCIDs[0] != want[i].mcid {
+			t.Fatalf("%s: want P page:%d mcid:%d, got: %s\n", msg, want[i].page, want[i].mcid, e)
+		}
+	}
+
+	// The text of the first line is selectable at the position of its word boxes.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Annots", pdf.Array{pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    pdf.NewNumberArray(200, 410, 300, 450),
+		"A":       pdf.Dict{"S": pdf.Name("URI"), "URI": pdf.StringLiteral("https://pdfcpu.io")},
+	}})
+
+	rr, err := ctx.ExtractTextWithLinks(pdf.IntSet{1: true})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(rr) != 3 || rr[0].Text != "Hello " || rr[1].Text != "world" || !rr[1].Linked() || rr[2].Text != "\nsecond line" {
+		t.Fatalf("%s: unexpected text runs: %v\n", msg, rr)
+	}
+}
+
+func TestImagesToPDF(t *testing.T) {
+	msg := "TestImagesToPDF"
+
+	// Create a TIFF image of 60 x 30 pixels.
+	tifFile := filepath.Join(outDir, "imagesToPDF.tif")
+	f, err := os.Create(tifFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 60, 30))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.NRGBA{R: 200, A: 255}}, image.Point{}, draw.Src)
+	if err := tiff.Encode(f, img, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	f.Close()
+
+	jpgFile := filepath.Join(resDir, "snow.jpg")
+	imgFiles := []string{jpgFile, filepath.Join(resDir, "logoSmall.png"), tifFile}
+
+	outFile := filepath.Join(outDir, "imagesToPDF.pdf")
+	if err := api.ImagesToPDFFile(imgFiles, outFile, nil, pdf.FitContain, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	dims, err := ctx.PageDims()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(dims) != 3 || dims[2] != (pdf.Dim{Width: 60, Height: 30}) {
+		t.Fatalf("%s: want 3 pages sized to their images, got: %v\n", msg, dims)
+	}
+
+	// The JPEG gets embedded as is.
+	bb, err := os.ReadFile(jpgFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var found bool
+	for _, entry := range ctx.Table {
+		if sd, ok := entry.Object.(pdf.StreamDict); ok && len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == "DCTDecode" {
+			found = bytes.Equal(sd.Raw, bb)
+		}
+	}
+	if !found {
+		t.Fatalf("%s: want JPEG embedded unchanged\n", msg)
+	}
+
+	for _, fit := range []string{pdf.FitContain, pdf.FitCover, pdf.FitActual} {
+		outFile := filepath.Join(outDir, "imagesToPDF_"+fit+".pdf")
+		if err := api.ImagesToPDFFile(imgFiles, outFile, pdf.PaperSize["A4"], fit, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fit, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fit, err)
+		}
+		if n, err := api.PageCountFile(outFile, nil); err != nil || n != 3 {
+			t.Fatalf("%s %s: want 3 pages, got: %d %v\n", msg, fit, n, err)
+		}
+	}
+
+	if err := api.ImagesToPDFFile(imgFiles, outFile, nil, "stretch", nil); err == nil {
+		t.Fatalf("%s: want error for unknown fit mode\n", msg)
+	}
+
+	// A missing image leaves no output behind.
+	if err := api.ImagesToPDFFile(append(imgFiles, filepath.Join(resDir, "missing.png")), outFile, nil, pdf.FitContain, nil); err == nil {
+		t.Fatalf("%s: want error for missing image\n", msg)
+	}
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		t.Fatalf("%s: want %s removed, got: %v\n", msg, outFile, err)
+	}
+}
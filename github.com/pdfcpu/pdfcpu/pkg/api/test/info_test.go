@@ -0,0 +1,196 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+const testXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" pdf:Producer="Old Producer"/>
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <dc:format>application/pdf</dc:format>
+   <dc:title><rdf:Alt><rdf:li xml:lang="x-default">Old Title</rdf:li></rdf:Alt></dc:title>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+// catalogXMP returns the XMP metadata of the catalog of inFile.
+func catalogXMP(t *testing.T, msg, inFile string) string {
+	t.Helper()
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, err := ctx.DereferenceStreamDict(rootDict["Metadata"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing metadata stream: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	return string(sd.Content)
+}
+
+func TestSetInfo(t *testing.T) {
+	msg := "TestSetInfo"
+	inFile := filepath.Join(outDir, "setInfoIn.pdf")
+	outFile := filepath.Join(outDir, "setInfo.pdf")
+
+	// Attach XMP metadata to the catalog.
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _ := ctx.NewStreamDictForBuf([]byte(testXMP))
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict.Update("Metadata", *ir)
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	before, err := api.GetInfoFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	title, author, producer, empty := "Grüße aus Go", "Jane <Doe>", "acme", ""
+	info := pdf.DocumentInfo{Title: &title, Author: &author, Producer: &producer, Keywords: &empty}
+	if err := api.SetInfoFile(inFile, outFile, info, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	got, err := api.GetInfoFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got.Title == nil || *got.Title != title || got.Author == nil || *got.Author != author {
+		t.Fatalf("%s: unexpected title or author: %v %v\n", msg, got.Title, got.Author)
+	}
+	if got.Producer == nil || *got.Producer != producer {
+		t.Fatalf("%s: unexpected producer: %v\n", msg, got.Producer)
+	}
+	if got.Keywords != nil {
+		t.Fatalf("%s: keywords not removed: %s\n", msg, *got.Keywords)
+	}
+	// Unset fields are preserved.
+	if (before.Subject == nil) != (got.Subject == nil) || (got.Subject != nil && *got.Subject != *before.Subject) {
+		t.Fatalf("%s: subject not preserved\n", msg)
+	}
+
+	xmp := catalogXMP(t, msg, outFile)
+	for _, s := range []string{"Grüße aus Go", "Jane &lt;Doe&gt;", "<pdf:Producer>acme</pdf:Producer>", "<dc:format>application/pdf</dc:format>"} {
+		if !strings.Contains(xmp, s) {
+			t.Fatalf("%s: XMP metadata missing %s:\n%s\n", msg, s, xmp)
+		}
+	}
+	for _, s := range []string{"Old Title", "Old Producer"} {
+		if strings.Contains(xmp, s) {
+			t.Fatalf("%s: XMP metadata still contains %s:\n%s\n", msg, s, xmp)
+		}
+	}
+
+	// Documents without XMP metadata just get their info dict updated.
+	if err := api.SetInfoFile(filepath.Join(inDir, "go.pdf"), outFile, info, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got, err = api.GetInfoFile(outFile, nil); err != nil || got.Title == nil || *got.Title != title {
+		t.Fatalf("%s: unexpected title: %v %v\n", msg, got, err)
+	}
+	bb, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if strings.Contains(string(bb), "xmpmeta") {
+		t.Fatalf("%s: unexpected XMP metadata\n", msg)
+	}
+}
+
+func TestSetXMP(t *testing.T) {
+	msg := "TestSetXMP"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "setXMP.pdf")
+
+	xmp, err := api.GetXMPFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if xmp != nil {
+		t.Fatalf("%s: unexpected XMP metadata:\n%s\n", msg, xmp)
+	}
+
+	// Create a metadata stream.
+	if err := api.SetXMPFile(inFile, outFile, []byte(testXMP), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if xmp, err = api.GetXMPFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if string(xmp) != testXMP {
+		t.Fatalf("%s: unexpected XMP metadata:\n%s\n", msg, xmp)
+	}
+
+	// Replace the metadata stream in place.
+	custom := strings.Replace(testXMP, "<dc:format>", `<prism:issn xmlns:prism="http://prismstandard.org/namespaces/basic/2.0/">1234-5678</prism:issn><dc:format>`, 1)
+	if err := api.SetXMPFile(outFile, "", []byte(custom), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if xmp = []byte(catalogXMP(t, msg, outFile)); string(xmp) != custom {
+		t.Fatalf("%s: unexpected XMP metadata:\n%s\n", msg, xmp)
+	}
+
+	for _, s := range []string{
+		"",
+		"<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"><rdf:RDF>",
+		"<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\"/>",
+	} {
+		if err := api.SetXMPFile(inFile, outFile, []byte(s), nil); err == nil {
+			t.Fatalf("%s: invalid XMP metadata accepted: %s\n", msg, s)
+		}
+	}
+}
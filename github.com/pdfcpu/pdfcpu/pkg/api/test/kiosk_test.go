@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestKioskMode(t *testing.T) {
+	msg := "TestKioskMode"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "KioskMode.pdf")
+
+	if err := api.SetKioskModeFile(inFile, outFile, 7.5, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	dur, err := api.KioskModeFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if dur != 7.5 {
+		t.Fatalf("%s: duration want:7.5 got:%f\n", msg, dur)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if pm := rootDict.NameEntry("PageMode"); pm == nil || *pm != "FullScreen" {
+		t.Fatalf("%s: missing full screen page mode\n", msg)
+	}
+
+	// Clear kiosk mode.
+	if err := api.SetKioskModeFile(outFile, outFile, 0, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if dur, err = api.KioskModeFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if dur != 0 {
+		t.Fatalf("%s: duration want:0 got:%f\n", msg, dur)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if rootDict, err = ctx.Catalog(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, k := range []string{"PageMode", "ViewerPreferences"} {
+		if _, found := rootDict.Find(k); found {
+			t.Fatalf("%s: unexpected %s\n", msg, k)
+		}
+	}
+
+	if err := api.SetKioskModeFile(inFile, outFile, -1, nil); err == nil {
+		t.Fatalf("%s: missing error for negative duration\n", msg)
+	}
+}
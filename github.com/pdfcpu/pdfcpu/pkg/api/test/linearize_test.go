@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func linearizationParms(t *testing.T, msg string, bb []byte) map[string]int {
+	t.Helper()
+	m := regexp.MustCompile(`^%PDF-1\.\d\n[^\n]*\n\d+ 0 obj\n<<([^>]*)>>`).FindSubmatch(bb)
+	if m == nil {
+		t.Fatalf("%s: missing linearization parameter dict\n", msg)
+	}
+	parms := map[string]int{}
+	for _, kv := range regexp.MustCompile(`/(\w+)\s*\[?(\d+)`).FindAllSubmatch(m[1], -1) {
+		i, _ := strconv.Atoi(string(kv[2]))
+		parms[string(kv[1])] = i
+	}
+	// The length of the primary hint stream.
+	if h := regexp.MustCompile(`/H\[\d+ (\d+)`).FindSubmatch(m[1]); h != nil {
+		parms["HLen"], _ = strconv.Atoi(string(h[1]))
+	}
+	return parms
+}
+
+func TestLinearize(t *testing.T) {
+	msg := "TestLinearize"
+	for _, fn := range []string{"go.pdf", "Acroforms2.pdf", "empty.pdf"} {
+		inFile := filepath.Join(inDir, fn)
+		outFile := filepath.Join(outDir, "linearized"+fn)
+
+		conf := pdf.NewDefaultConfiguration()
+		conf.Linearize = true
+		if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		bb, err := ioutil.ReadFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		parms := linearizationParms(t, msg, bb)
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if !ctx.Read.Linearized {
+			t.Fatalf("%s %s: not linearized\n", msg, fn)
+		}
+		if parms["L"] != len(bb) {
+			t.Fatalf("%s %s: L=%d, file size=%d\n", msg, fn, parms["L"], len(bb))
+		}
+		if parms["N"] != ctx.PageCount {
+			t.Fatalf("%s %s: N=%d, page count=%d\n", msg, fn, parms["N"], ctx.PageCount)
+		}
+		ir, err := ctx.PageDictIndRef(1)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if parms["O"] != ir.ObjectNumber.Value() {
+			t.Fatalf("%s %s: O=%d, first page obj#%d\n", msg, fn, parms["O"], ir.ObjectNumber.Value())
+		}
+
+		// The primary hint stream is followed by the first page.
+		h := parms["H"]
+		if !regexp.MustCompile(`^\d+ 0 obj\n<<[^>]*/S \d+`).Match(bb[h:]) {
+			t.Fatalf("%s %s: no hint stream at offset %d\n", msg, fn, h)
+		}
+		if !regexp.MustCompile(`^` + strconv.Itoa(parms["O"]) + ` 0 obj\n`).Match(bb[h+parms["HLen"]:]) {
+			t.Fatalf("%s %s: first page does not follow hint stream\n", msg, fn)
+		}
+		if !regexp.MustCompile(`^\s*0000000000 65535 f`).Match(bb[parms["T"]:]) {
+			t.Fatalf("%s %s: T=%d does not point to the main xref table\n", msg, fn, parms["T"])
+		}
+		if parms["E"] <= h || parms["E"] > parms["T"] {
+			t.Fatalf("%s %s: E=%d out of range\n", msg, fn, parms["E"])
+		}
+	}
+
+	// Linearized files may be encrypted.
+	conf := pdf.NewDefaultConfiguration()
+	conf.UserPW, conf.OwnerPW = "upw", "opw"
+	conf.Linearize = true
+	inFile := filepath.Join(outDir, "linearizedgo.pdf")
+	outFile := filepath.Join(outDir, "linearizedgoEnc.pdf")
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	conf = pdf.NewDefaultConfiguration()
+	conf.UserPW, conf.OwnerPW = "upw", "opw"
+	if err := api.ValidateFile(outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// writeLinkMapTestFile writes go.pdf to outFile with two links on the rotated first page.
+func writeLinkMapTestFile(t *testing.T, msg, outFile string, rot int) {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	page1, page2 := firstTwoPages(t, msg, ctx)
+
+	uriLink := pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    pdf.NewIntegerArray(10, 20, 110, 70),
+		"A": pdf.Dict{
+			"S":   pdf.Name("URI"),
+			"URI": pdf.StringLiteral("https://pdfcpu.io"),
+		},
+	}
+	goToLink := pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    pdf.NewIntegerArray(0, 0, 400, 300),
+		"Dest":    pdf.Array{page2, pdf.Name("Fit")},
+	}
+
+	d, err := ctx.DereferenceDict(page1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Annots", pdf.Array{uriLink, goToLink})
+	d.Update("CropBox", pdf.NewIntegerArray(0, 0, 400, 300))
+	d.Update("Rotate", pdf.Integer(rot))
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestLinkMap(t *testing.T) {
+	msg := "TestLinkMap"
+	outFile := filepath.Join(outDir, "linkMap.pdf")
+
+	for _, tt := range []struct {
+		rot  int
+		want []pdf.LinkArea
+	}{
+		{0, []pdf.LinkArea{
+			{X0: 20, Y0: 460, X1: 220, Y1: 560, URI: "https://pdfcpu.io"},
+			{X0: 0, Y0: 0, X1: 800, Y1: 600, Page: 2},
+		}},
+		{90, []pdf.LinkArea{
+			{X0: 40, Y0: 20, X1: 140, Y1: 220, URI: "https://pdfcpu.io"},
+			{X0: 0, Y0: 0, X1: 600, Y1: 800, Page: 2},
+		}},
+	} {
+		writeLinkMapTestFile(t, msg, outFile, tt.rot)
+
+		got, err := api.LinkMapFile(outFile, 1, 144, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: rot %d: want %v, got: %v\n", msg, tt.rot, tt.want, got)
+		}
+	}
+
+	if _, err := api.LinkMapFile(outFile, 1, 0, nil); err == nil {
+		t.Fatalf("%s: missing error for invalid dpi\n", msg)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestMergeCreate(t *testing.T) {
@@ -88,3 +89,158 @@ func TestMergeToBuf(t *testing.T) {
 		t.Fatalf("%s: write: %v\n", msg, err)
 	}
 }
+
+func TestMergeAcroForms(t *testing.T) {
+	msg := "TestMergeAcroForms"
+
+	// The first form calculates its check box and does not need appearances.
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, _ := xRefTable.Catalog()
+	acroForm := rootDict.DictEntry("AcroForm")
+	acroForm.Delete("NeedAppearances")
+	acroForm.Update("CO", pdf.Array{acroForm.ArrayEntry("Fields")[1]})
+	inFile1 := filepath.Join(outDir, "AcroForm1.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The second form calculates its text field and needs appearances.
+	xRefTable, err = pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFile2 := filepath.Join(outDir, "AcroForm2.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile2, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	outFile := filepath.Join(outDir, "AcroFormMerged.pdf")
+	if err := api.MergeCreateFile([]string{inFile1, inFile2}, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rootDict, _ = ctx.Catalog()
+	acroForm, err = ctx.DereferenceDict(rootDict["AcroForm"])
+	if err != nil || acroForm == nil {
+		t.Fatalf("%s: missing AcroForm: %v\n", msg, err)
+	}
+
+	fields, err := ctx.DereferenceArray(acroForm["Fields"])
+	if err != nil || len(fields) != 10 {
+		t.Fatalf("%s: want 10 fields, got %d: %v\n", msg, len(fields), err)
+	}
+
+	co, err := ctx.DereferenceArray(acroForm["CO"])
+	if err != nil || len(co) != 2 {
+		t.Fatalf("%s: want calculation order of 2 fields, got %v: %v\n", msg, co, err)
+	}
+	for i, j := range []int{1, 5} {
+		if co[i] != fields[j] {
+			t.Fatalf("%s: calculation order %d: want %v got %v\n", msg, i, fields[j], co[i])
+		}
+	}
+
+	if b := acroForm.BooleanEntry("NeedAppearances"); b == nil || !*b {
+		t.Fatalf("%s: NeedAppearances not preserved\n", msg)
+	}
+}
+
+func TestMergeCreateWithBookmarks(t *testing.T) {
+	msg := "TestMergeCreateWithBookmarks"
+
+	fileName := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	inFiles := []string{
+		fileName,
+		filepath.Join(inDir, "Acroforms2.pdf"),
+		fileName,
+	}
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	bms, err := ctx.Bookmarks()
+	if err != nil {
+		t.Fatalf("%s bookmarks: %v\n", msg, err)
+	}
+	ctx1, err := api.ReadContextFile(inFiles[1])
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	offset := ctx.PageCount + ctx1.PageCount
+
+	if err := api.MergeCreateWithBookmarksFile(inFiles, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	ctx2, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	bms2, err := ctx2.Bookmarks()
+	if err != nil {
+		t.Fatalf("%s bookmarks: %v\n", msg, err)
+	}
+
+	want := []struct {
+		title    string
+		pageFrom int
+		children int
+	}{
+		{"5116.DCT_Filter", 1, len(bms)},
+		{"Acroforms2", ctx.PageCount + 1, 0},
+		{"5116.DCT_Filter", offset + 1, len(bms)},
+	}
+	if len(bms2) != len(want) {
+		t.Fatalf("%s: got %d top level bookmarks, want %d\n", msg, len(bms2), len(want))
+	}
+	for i, w := range want {
+		bm := bms2[i]
+		if bm.Title != w.title || bm.PageFrom != w.pageFrom || len(bm.Children) != w.children {
+			t.Fatalf("%s: bookmark %d: got %s page %d with %d children, want %s page %d with %d children\n",
+				msg, i, bm.Title, bm.PageFrom, len(bm.Children), w.title, w.pageFrom, w.children)
+		}
+	}
+	for i, bm := range bms {
+		if got := bms2[2].Children[i]; got.Title != bm.Title || got.PageFrom != bm.PageFrom+offset {
+			t.Fatalf("%s: got %s page %d, want %s page %d\n", msg, got.Title, got.PageFrom, bm.Title, bm.PageFrom+offset)
+		}
+	}
+
+	// Named destinations defined by both copies of fileName get renamed.
+	if err := ctx.LocateNameTree("Dests", false); err != nil || ctx.Names["Dests"] == nil {
+		t.Fatalf("%s: missing named destinations: %v\n", msg, err)
+	}
+	kk, err := ctx.Names["Dests"].KeyList()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx2.LocateNameTree("Dests", false); err != nil || ctx2.Names["Dests"] == nil {
+		t.Fatalf("%s: missing named destinations: %v\n", msg, err)
+	}
+	kk2, err := ctx2.Names["Dests"].KeyList()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(kk2) != 2*len(kk) {
+		t.Fatalf("%s: got %d named destinations, want %d\n", msg, len(kk2), 2*len(kk))
+	}
+	if _, found := ctx2.Names["Dests"].Value(kk[0] + "_2"); !found {
+		t.Fatalf("%s: missing renamed destination %s_2\n", msg, kk[0])
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestNormalizeDates(t *testing.T) {
+	msg := "TestNormalizeDates"
+
+	if err := prepareForAttachmentTest(t); err != nil {
+		t.Fatalf("%s prepare for attachments: %v\n", msg, err)
+	}
+
+	fileName := filepath.Join(outDir, "go.pdf")
+	if err := api.AddAttachmentsFile(fileName, "", []string{filepath.Join(outDir, "test.wav")}, false, nil); err != nil {
+		t.Fatalf("%s add attachments: %v\n", msg, err)
+	}
+
+	// Corrupt the dates of the embedded file.
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	sd, _ := embeddedFileStream(t, msg, ctx, "test.wav")
+	d := sd.DictEntry("Params")
+	d.Update("ModDate", pdfcpu.StringLiteral("20200102030405+01'00"))
+	d.Update("CreationDate", pdfcpu.StringLiteral("D:20190102030405-0530"))
+	d.Update("M", pdfcpu.StringLiteral("yesterday"))
+
+	inFile := filepath.Join(outDir, "malformedDates.pdf")
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(inFile, nil); err == nil {
+		t.Fatalf("%s: malformed dates pass validation\n", msg)
+	}
+
+	outFile := filepath.Join(outDir, "normalizedDates.pdf")
+	ss, err := api.NormalizeDatesFile(inFile, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 || !strings.Contains(ss[0], "M: invalid date <yesterday>") {
+		t.Fatalf("%s: unexpected report: %v\n", msg, ss)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	aa, err := ctx.ListAttachments()
+	if err != nil || len(aa) != 1 {
+		t.Fatalf("%s listAttachments: %v\n", msg, err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+	creationTime := time.Date(2019, 1, 2, 3, 4, 5, 0, time.FixedZone("", -(5*3600+30*60)))
+	if aa[0].ModTime == nil || !aa[0].ModTime.Equal(modTime) {
+		t.Fatalf("%s: want modTime %s, got %v\n", msg, modTime, aa[0].ModTime)
+	}
+	if aa[0].CreationTime == nil || !aa[0].CreationTime.Equal(creationTime) {
+		t.Fatalf("%s: want creationTime %s, got %v\n", msg, creationTime, aa[0].CreationTime)
+	}
+}
@@ -18,6 +18,7 @@ package test
 
 import (
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -88,6 +89,15 @@ func TestNUp(t *testing.T) {
 			9,
 			false},
 
+		// 9-Up a PDF copying page content inline.
+		{"TestNUpFromPDFInline",
+			[]string{filepath.Join(inDir, "WaldenFull.pdf")},
+			filepath.Join(outDir, "TestNUpFromPDFInline.pdf"),
+			nil,
+			"inline:on",
+			9,
+			false},
+
 		// 9-Up an image
 		{"TestNUpFromSingleImage",
 			[]string{filepath.Join(resDir, "logoSmall.png")},
@@ -109,3 +119,280 @@ func TestNUp(t *testing.T) {
 		testNUp(t, tt.msg, tt.inFiles, tt.outFile, tt.selectedPages, tt.desc, tt.n, tt.isImg)
 	}
 }
+
+func BenchmarkNUp(b *testing.B) {
+	inFile := filepath.Join(inDir, "WaldenFull.pdf")
+	for _, tt := range []struct {
+		name string
+		desc string
+	}{
+		{"form", ""},
+		{"inline", "inline:on"},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			msg := "BenchmarkNUp " + tt.name
+			outFile := filepath.Join(outDir, "nupBenchmark_"+tt.name+".pdf")
+			nup, err := pdf.PDFNUpConfig(9, tt.desc)
+			if err != nil {
+				b.Fatalf("%s: %v\n", msg, err)
+			}
+			for n := 0; n < b.N; n++ {
+				if err := api.NUpFile([]string{inFile}, outFile, nil, nup, nil); err != nil {
+					b.Fatalf("%s: %v\n", msg, err)
+				}
+			}
+			fi, err := os.Stat(outFile)
+			if err != nil {
+				b.Fatalf("%s: %v\n", msg, err)
+			}
+			b.ReportMetric(float64(fi.Size()), "bytes")
+		})
+	}
+}
+
+func TestNUpAnnotations(t *testing.T) {
+	msg := "TestNUpAnnotations"
+	oldFile := filepath.Join(outDir, "nupAnnotsOld.pdf")
+	inFile := filepath.Join(outDir, "nupAnnotsIn.pdf")
+	redlineFile := filepath.Join(outDir, "nupAnnotsRedline.pdf")
+	outFile := filepath.Join(outDir, "nupAnnots.pdf")
+
+	// Highlight "brown" on page 1.
+	writeRedlineTestFile(t, msg, oldFile, "The quick fox")
+	writeRedlineTestFile(t, msg, inFile, "The quick brown fox")
+	if err := api.RedlineFile(oldFile, inFile, redlineFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Cover the highlight by a link in order to locate the highlighted text after n-up.
+	ctx, err := api.ReadContextFile(redlineFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var hl pdf.Dict
+	for _, d := range pageAnnotations(t, msg, ctx, 1) {
+		if *d.Subtype() == "Highlight" {
+			hl = d
+		}
+	}
+	if hl == nil {
+		t.Fatalf("%s: missing highlight\n", msg)
+	}
+	ir, err := ctx.IndRefForNewObject(pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    hl.ArrayEntry("Rect"),
+		"Border":  pdf.NewIntegerArray(0, 0, 0),
+		"A":       pdf.Dict{"S": pdf.Name("URI"), "URI": pdf.StringLiteral("https://pdfcpu.io")},
+	})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Annots", append(d.ArrayEntry("Annots"), *ir))
+	if err := api.WriteContextFile(ctx, redlineFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, desc := range []string{"", "inline:on"} {
+
+		testNUp(t, msg, []string{redlineFile}, outFile, []string{"1-2"}, desc, 2, false)
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		var hl, link pdf.Dict
+		for _, d := range pageAnnotations(t, msg, ctx, 1) {
+			switch *d.Subtype() {
+			case "Highlight":
+				hl = d
+			case "Link":
+				link = d
+			}
+		}
+		if hl == nil || link == nil {
+			t.Fatalf("%s %s: missing annotations\n", msg, desc)
+		}
+		if hl.ArrayEntry("Rect").String() != link.ArrayEntry("Rect").String() {
+			t.Fatalf("%s %s: highlight %v and link %v diverged\n", msg, desc, hl.ArrayEntry("Rect"), link.ArrayEntry("Rect"))
+		}
+
+		rr, err := api.ExtractTextWithLinksFile(outFile, []string{"1"}, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		var linked []string
+		for _, r := range rr {
+			if r.Linked() {
+				linked = append(linked, strings.TrimSpace(r.Text))
+			}
+		}
+		if len(linked) != 1 || linked[0] != "brown" {
+			t.Fatalf("%s %s: want highlight covering \"brown\", got: %v\n", msg, desc, rr)
+		}
+	}
+}
+
+func TestNUpGutter(t *testing.T) {
+	msg := "TestNUpGutter"
+	inFile := filepath.Join(inDir, "WaldenFull.pdf")
+	outFile := filepath.Join(outDir, "nupGutter.pdf")
+
+	testNUp(t, msg, []string{inFile}, outFile, []string{"1-4"}, "f:A4, m:20, g:10, b:on", 4, false)
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// 2x2 cells of 272.5x396 points separated by the gutter within the margin.
+	for _, s := range []string{
+		"20.00 426.00 m 292.50 426.00 l 292.50 822.00 l 20.00 822.00 l s",
+		"302.50 426.00 m 575.00 426.00 l 575.00 822.00 l 302.50 822.00 l s",
+		"20.00 20.00 m 292.50 20.00 l 292.50 416.00 l 20.00 416.00 l s",
+		"302.50 20.00 m 575.00 20.00 l 575.00 416.00 l 302.50 416.00 l s",
+	} {
+		if !strings.Contains(string(bb), s) {
+			t.Fatalf("%s: missing cell border: %s\n", msg, s)
+		}
+	}
+
+	// Without a gutter adjacent cells are spaced by twice the margin.
+	testNUp(t, msg, []string{inFile}, outFile, []string{"1-4"}, "f:A4, m:20, b:on", 4, false)
+
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err = ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if bb, err = ctx.PageContent(d); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := "317.50 441.00 m 575.00 441.00 l 575.00 822.00 l 317.50 822.00 l s"; !strings.Contains(string(bb), s) {
+		t.Fatalf("%s: missing cell border: %s\n", msg, s)
+	}
+
+	// A zero gutter leaves adjacent cells touching.
+	testNUp(t, msg, []string{inFile}, outFile, []string{"1-4"}, "f:A4, m:20, g:0, b:on", 4, false)
+
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err = ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if bb, err = ctx.PageContent(d); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := "297.50 421.00 m 575.00 421.00 l 575.00 822.00 l 297.50 822.00 l s"; !strings.Contains(string(bb), s) {
+		t.Fatalf("%s: missing cell border: %s\n", msg, s)
+	}
+
+	// A gutter set in code applies as well.
+	nup, err := pdf.PDFNUpConfig(4, "f:A4, m:20, b:on")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	nup.Gutter = 10
+	if err := api.NUpFile([]string{inFile}, outFile, []string{"1-4"}, nup, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, _, err = ctx.PageDict(1, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if bb, err = ctx.PageContent(d); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := "302.50 426.00 m 575.00 426.00 l 575.00 822.00 l 302.50 822.00 l s"; !strings.Contains(string(bb), s) {
+		t.Fatalf("%s: missing cell border: %s\n", msg, s)
+	}
+
+	// Margin and gutter leaving no room for the cells.
+	nup, err = pdf.PDFNUpConfig(4, "f:A4, m:300")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.NUpFile([]string{inFile}, outFile, nil, nup, nil); err == nil {
+		t.Fatalf("%s: want error for margin exceeding page dimensions\n", msg)
+	}
+}
+
+func TestBooklet(t *testing.T) {
+	msg := "TestBooklet"
+	inFile := filepath.Join(inDir, "WaldenFull.pdf")
+
+	pageContent := func(fileName string, pageNr int) string {
+		t.Helper()
+		ctx, err := api.ReadContextFile(fileName)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		// 6 pages padded to 2 sheets printed on both sides.
+		if ctx.PageCount != 4 {
+			t.Fatalf("%s: got %d pages, want 4\n", msg, ctx.PageCount)
+		}
+		d, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return string(bb)
+	}
+
+	cc := map[string][]string{}
+
+	for _, desc := range []string{"", "creep:10"} {
+		outFile := filepath.Join(outDir, "booklet.pdf")
+		nup, err := pdf.PDFBookletConfig(desc)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, desc, err)
+		}
+		if err := api.BookletFile(inFile, outFile, []string{"1-6"}, nup, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, desc, err)
+		}
+		for i := 1; i <= 4; i++ {
+			cc[desc] = append(cc[desc], pageContent(outFile, i))
+		}
+	}
+
+	// The first sheet holds blank pages 8 and 7 next to pages 1 and 2.
+	for i, want := range []int{1, 1, 2, 2} {
+		if got := strings.Count(cc[""][i], " Do "); got != want {
+			t.Fatalf("%s: side %d: got %d pages, want %d\n", msg, i+1, got, want)
+		}
+	}
+
+	// Creep shifts pages of the inner sheet only.
+	for i, c := range cc["creep:10"] {
+		if shifted := c != cc[""][i]; shifted != (i >= 2) {
+			t.Fatalf("%s: side %d: shifted=%t\n", msg, i+1, shifted)
+		}
+	}
+	if !strings.Contains(cc["creep:10"][2], " re W n ") {
+		t.Fatalf("%s: missing clip of shifted pages\n", msg)
+	}
+}
@@ -17,10 +17,15 @@ limitations under the License.
 package test
 
 import (
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestOptimize(t *testing.T) {
@@ -41,3 +46,365 @@ func TestOptimize(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestOptimizeUsingLZW(t *testing.T) {
+	msg := "TestOptimizeUsingLZW"
+	fileName := "Acroforms2.pdf"
+	inFile := filepath.Join(inDir, fileName)
+	outFile := filepath.Join(outDir, "lzw"+fileName)
+
+	// Encode object streams and the xref stream using LZWDecode.
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.StreamFilter = filter.LZW
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The writer only encodes using Flate or LZW.
+	conf.StreamFilter = filter.ASCIIHex
+	if err := api.OptimizeFile(inFile, outFile, conf); err == nil {
+		t.Fatalf("%s: want error for unsupported stream filter\n", msg)
+	}
+}
+
+func TestOptimizeExcludingPagesFromObjectStreams(t *testing.T) {
+	msg := "TestOptimizeExcludingPagesFromObjectStreams"
+	fileName := "Acroforms2.pdf"
+	inFile := filepath.Join(inDir, fileName)
+	outFile := filepath.Join(outDir, "pagesUncompressed"+fileName)
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ExcludePagesFromObjectStreams = true
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rootObjNr := ctx.Root.ObjectNumber.Value()
+	compressed := 0
+
+	for objNr, entry := range ctx.Table {
+		if entry.Free {
+			continue
+		}
+		// Objects read from object streams remember their object stream.
+		inObjStream := entry.ObjectStream != nil
+		if inObjStream {
+			compressed++
+		}
+		d, ok := entry.Object.(pdfcpu.Dict)
+		if !ok {
+			continue
+		}
+		isPageTreeObj := d.Type() != nil && (*d.Type() == "Page" || *d.Type() == "Pages")
+		if (objNr == rootObjNr || isPageTreeObj) && inObjStream {
+			t.Fatalf("%s: obj#%d is part of an object stream\n", msg, objNr)
+		}
+	}
+
+	if compressed == 0 {
+		t.Fatalf("%s: missing object streams\n", msg)
+	}
+}
+
+// embeddedSubsetFont returns the font dict of an embedded simple subset font of the font resources fonts
+// along with its font descriptor and font file entry.
+func embeddedSubsetFont(ctx *pdfcpu.Context, fonts pdfcpu.Dict) (pdfcpu.Dict, pdfcpu.Dict, string) {
+	ids := []string{}
+	for id := range fonts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		d, err := ctx.DereferenceDict(fonts[id])
+		if err != nil || d == nil || d.ArrayEntry("Widths") == nil {
+			continue
+		}
+		if bf := d.NameEntry("BaseFont"); bf == nil || !strings.Contains(*bf, "+") {
+			continue
+		}
+		fd, err := ctx.DereferenceDict(d["FontDescriptor"])
+		if err != nil || fd == nil {
+			continue
+		}
+		for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+			if fd.IndirectRefEntry(k) != nil {
+				return d, fd, k
+			}
+		}
+	}
+	return nil, nil, ""
+}
+
+func pageFonts(t *testing.T, ctx *pdfcpu.Context, msg string) pdfcpu.Dict {
+	t.Helper()
+	pd, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	res, err := ctx.DereferenceDict(pd["Resources"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fonts, err := ctx.DereferenceDict(res["Font"])
+	if err != nil || fonts == nil {
+		t.Fatalf("%s: missing font resources for page 1: %v\n", msg, err)
+	}
+	return fonts
+}
+
+func TestOptimizeTrimFonts(t *testing.T) {
+	msg := "TestOptimizeTrimFonts"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "trimFontsIn.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	fonts := pageFonts(t, ctx, msg)
+
+	d, fd, k := embeddedSubsetFont(ctx, fonts)
+	if d == nil {
+		t.Fatalf("%s: missing embedded subset font\n", msg)
+	}
+
+	// Embed a copy of the font file for a renamed copy of the font padding its widths with zeros.
+	sd, err := ctx.DereferenceStreamDict(*fd.IndirectRefEntry(k))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ffIndRef, err := ctx.IndRefForNewObject(sd.Clone())
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fdCopy := fd.Clone().(pdfcpu.Dict)
+	fdCopy.Update(k, *ffIndRef)
+	fdIndRef, err := ctx.IndRefForNewObject(fdCopy)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The copy gets a slightly different first width in order to survive the removal of duplicate fonts.
+	fc, lc, w := *d.IntEntry("FirstChar"), *d.IntEntry("LastChar"), d.ArrayEntry("Widths")
+	w = append(pdfcpu.Array{w[0].(pdfcpu.Integer) + 1}, w[1:]...)
+	dCopy := d.Clone().(pdfcpu.Dict)
+	dCopy.Update("BaseFont", pdfcpu.Name("ZZZZZZ+"+strings.SplitN(*d.NameEntry("BaseFont"), "+", 2)[1]))
+	dCopy.Update("FontDescriptor", *fdIndRef)
+	dCopy.Update("FirstChar", pdfcpu.Integer(fc-2))
+	dCopy.Update("LastChar", pdfcpu.Integer(lc+3))
+	dCopy.Update("Widths", append(append(pdfcpu.NewIntegerArray(0, 0), w...), pdfcpu.NewIntegerArray(0, 0, 0)...))
+	fontIndRef, err := ctx.IndRefForNewObject(dCopy)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	fonts.Update("FZZ", *fontIndRef)
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	size := func(fileName string) int64 {
+		fi, err := os.Stat(fileName)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return fi.Size()
+	}
+
+	outFile1 := filepath.Join(outDir, "trimFontsOff.pdf")
+	if err := api.OptimizeFile(outFile, outFile1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	outFile2 := filepath.Join(outDir, "trimFontsOn.pdf")
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.TrimFonts = true
+	if err := api.OptimizeFile(outFile, outFile2, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if size(outFile1)-size(outFile2) < int64(len(sd.Raw)) {
+		t.Fatalf("%s: want at least %d bytes saved, got: %d\n", msg, len(sd.Raw), size(outFile1)-size(outFile2))
+	}
+
+	if ctx, err = api.ReadContextFile(outFile2); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, err = ctx.DereferenceDict(pageFonts(t, ctx, msg)["FZZ"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if *d.IntEntry("FirstChar") != fc || *d.IntEntry("LastChar") != lc || len(d.ArrayEntry("Widths")) != len(w) {
+		t.Fatalf("%s: want widths for %d..%d, got: %s\n", msg, fc, lc, d)
+	}
+}
+
+func TestOptimizeDeduplicateObjects(t *testing.T) {
+	msg := "TestOptimizeDeduplicateObjects"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "dedupIn.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	pd, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	res, err := ctx.DereferenceDict(pd["Resources"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	subDict := func(k string) pdfcpu.Dict {
+		d, err := ctx.DereferenceDict(res[k])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if d == nil {
+			d = pdfcpu.Dict{}
+			res[k] = d
+		}
+		return d
+	}
+
+	add := func(o pdfcpu.Object) pdfcpu.IndirectRef {
+		indRef, err := ctx.IndRefForNewObject(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return *indRef
+	}
+
+	// Add the same form twice, once compressed and once uncompressed.
+	form := func(compress bool) pdfcpu.IndirectRef {
+		d := pdfcpu.Dict(map[string]pdfcpu.Object{
+			"Type":    pdfcpu.Name("XObject"),
+			"Subtype": pdfcpu.Name("Form"),
+			"BBox":    pdfcpu.NewIntegerArray(0, 0, 100, 100),
+		})
+		var pl []pdfcpu.PDFFilter
+		if compress {
+			d.Insert("Filter", pdfcpu.Name(filter.Flate))
+			pl = []pdfcpu.PDFFilter{{Name: filter.Flate}}
+		}
+		sd := pdfcpu.NewStreamDict(d, 0, nil, nil, pl)
+		sd.Content = []byte(strings.Repeat("0 0 m 100 100 l S\n", 50))
+		if err := sd.Encode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return add(sd)
+	}
+	xObjs := subDict("XObject")
+	xObjs["FmDD0"] = form(true)
+	xObjs["FmDD1"] = form(false)
+
+	// Add identical graphics states each referring to its own copy of a function.
+	gs := func() pdfcpu.IndirectRef {
+		f := add(pdfcpu.Dict(map[string]pdfcpu.Object{
+			"FunctionType": pdfcpu.Integer(2),
+			"Domain":       pdfcpu.NewIntegerArray(0, 1),
+			"N":            pdfcpu.Integer(1),
+		}))
+		return add(pdfcpu.Dict(map[string]pdfcpu.Object{
+			"Type": pdfcpu.Name("ExtGState"),
+			"TR":   f,
+		}))
+	}
+	extGStates := subDict("ExtGState")
+	extGStates["GSDD0"] = gs()
+	extGStates["GSDD1"] = gs()
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	outFile1 := filepath.Join(outDir, "dedupOut.pdf")
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.DeduplicateObjects = true
+	if err := api.OptimizeFile(outFile, outFile1, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile1); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if pd, _, err = ctx.PageDict(1, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if res, err = ctx.DereferenceDict(pd["Resources"]); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for k, ids := range map[string][2]string{"XObject": {"FmDD0", "FmDD1"}, "ExtGState": {"GSDD0", "GSDD1"}} {
+		d := subDict(k)
+		ir0, ir1 := d.IndirectRefEntry(ids[0]), d.IndirectRefEntry(ids[1])
+		if ir0 == nil || ir1 == nil || ir0.ObjectNumber != ir1.ObjectNumber {
+			t.Fatalf("%s: want %s and %s merged, got: %v %v\n", msg, ids[0], ids[1], ir0, ir1)
+		}
+	}
+}
+
+func TestOptimizeDeduplicateObjectsGeneration(t *testing.T) {
+	msg := "TestOptimizeDeduplicateObjectsGeneration"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	fn := func() int {
+		indRef, err := ctx.IndRefForNewObject(pdfcpu.Dict(map[string]pdfcpu.Object{
+			"FunctionType": pdfcpu.Integer(2),
+			"Domain":       pdfcpu.NewIntegerArray(0, 1),
+			"N":            pdfcpu.Integer(1),
+		}))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return indRef.ObjectNumber.Value()
+	}
+
+	// The surviving copy lives at a non zero generation.
+	objNr0, objNr1 := fn(), fn()
+	*ctx.Table[objNr0].Generation = 1
+
+	gs := pdfcpu.Dict(map[string]pdfcpu.Object{
+		"Type": pdfcpu.Name("ExtGState"),
+		"TR":   *pdfcpu.NewIndirectRef(objNr1, 0),
+	})
+	if _, err := ctx.IndRefForNewObject(gs); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx.DeduplicateObjects = true
+	if err := api.OptimizeContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ir := gs.IndirectRefEntry("TR")
+	if ir == nil || ir.ObjectNumber.Value() != objNr0 || ir.GenerationNumber.Value() != 1 {
+		t.Fatalf("%s: want TR %d 1 R, got: %v\n", msg, objNr0, ir)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// appendOrphans simulates an incremental update of inFile
+// adding n objects not referenced by anything and writes the result to outFile.
+// Returns the object numbers of the orphans.
+func appendOrphans(t *testing.T, inFile, outFile string, n int) []int {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("appendOrphans: %v\n", err)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("appendOrphans: %v\n", err)
+	}
+
+	i := bytes.LastIndex(b, []byte("startxref"))
+	if i < 0 {
+		t.Fatalf("appendOrphans: missing startxref\n")
+	}
+	var prev int64
+	if _, err := fmt.Sscan(string(b[i+len("startxref"):]), &prev); err != nil {
+		t.Fatalf("appendOrphans: %v\n", err)
+	}
+
+	buf := bytes.NewBuffer(b)
+	buf.WriteString("\n")
+
+	size := *ctx.Size
+	objNrs := []int{}
+	offsets := []int{}
+
+	for j := 0; j < n; j++ {
+		objNr := size + j
+		objNrs = append(objNrs, objNr)
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n<</Orphan %d /Filler (%s)>>\nendobj\n", objNr, j, strings.Repeat("x", 100))
+	}
+
+	xRefOffset := buf.Len()
+	fmt.Fprintf(buf, "xref\n%d %d\n", size, n)
+	for _, off := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n\r\n", off)
+	}
+
+	fmt.Fprintf(buf, "trailer\n<</Size %d /Root %s /Prev %d", size+n, ctx.Root.PDFString(), prev)
+	if ctx.Info != nil {
+		fmt.Fprintf(buf, " /Info %s", ctx.Info.PDFString())
+	}
+	fmt.Fprintf(buf, ">>\nstartxref\n%d\n%%%%EOF\n", xRefOffset)
+
+	if err := ioutil.WriteFile(outFile, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("appendOrphans: %v\n", err)
+	}
+
+	return objNrs
+}
+
+func TestListOrphanObjects(t *testing.T) {
+	msg := "TestListOrphanObjects"
+
+	for _, fn := range []string{"Acroforms2.pdf", "go.pdf"} {
+
+		inFile := filepath.Join(inDir, fn)
+		orphansFile := filepath.Join(outDir, "orphans"+fn)
+
+		list, err := api.ListOrphanObjectsFile(inFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		before := len(list)
+
+		objNrs := appendOrphans(t, inFile, orphansFile, 3)
+
+		if list, err = api.ListOrphanObjectsFile(orphansFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		if len(list) != before+len(objNrs) {
+			t.Fatalf("%s %s: want %d orphans, got %d: %v\n", msg, fn, before+len(objNrs), len(list), list)
+		}
+
+		for _, objNr := range objNrs {
+			prefix := fmt.Sprintf("obj#%d gen#0 Dict", objNr)
+			found := false
+			for _, s := range list {
+				if strings.HasPrefix(s, prefix) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Fatalf("%s %s: missing orphan obj#%d in %v\n", msg, fn, objNr, list)
+			}
+		}
+	}
+}
+
+func TestGarbageCollect(t *testing.T) {
+	msg := "TestGarbageCollect"
+
+	for _, fn := range []string{"Acroforms2.pdf", "go.pdf", "WaldenFull.pdf"} {
+
+		inFile := filepath.Join(inDir, fn)
+		orphansFile := filepath.Join(outDir, "gcOrphans"+fn)
+		outFile := filepath.Join(outDir, "gc"+fn)
+
+		appendOrphans(t, inFile, orphansFile, 3)
+
+		if err := api.GarbageCollectFile(orphansFile, outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		list, err := api.ListOrphanObjectsFile(outFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if len(list) > 0 {
+			t.Fatalf("%s %s: orphans left: %v\n", msg, fn, list)
+		}
+
+		// Live objects remain.
+		n1, err := api.PageCountFile(inFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		n2, err := api.PageCountFile(outFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if n1 != n2 {
+			t.Fatalf("%s %s: page count: want %d got %d\n", msg, fn, n1, n2)
+		}
+	}
+}
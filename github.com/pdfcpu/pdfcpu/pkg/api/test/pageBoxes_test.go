@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The pdf Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func checkPageBox(t *testing.T, msg string, pageNr int, name string, pb *pdfcpu.PageBox, want *pdfcpu.Rectangle) {
+	t.Helper()
+	if pb == nil || pb.Rect == nil {
+		t.Fatalf("%s: page %d: missing %s\n", msg, pageNr, name)
+	}
+	if pb.Rect.LL != want.LL || pb.Rect.UR != want.UR {
+		t.Fatalf("%s: page %d: %s: want %v got %v\n", msg, pageNr, name, want, pb.Rect)
+	}
+}
+
+func TestSetPageBoxes(t *testing.T) {
+	msg := "TestSetPageBoxes"
+	inFile := filepath.Join(outDir, "mixedPageSizes.pdf")
+	outFile := filepath.Join(outDir, "pageBoxes.pdf")
+
+	rr := createMixedPageSizes(t, msg, inFile)
+
+	boxes, err := pdfcpu.ParsePageBoxes("bleed:9, trim:18, art:[100 100 300 300]")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.SetPageBoxesFile(inFile, outFile, *boxes, []string{"1-2"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m, err := api.PageBoxesFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(m) != 3 {
+		t.Fatalf("%s: want 3 pages, got %d\n", msg, len(m))
+	}
+
+	for i := 1; i <= 2; i++ {
+		mb := rr[i-1]
+		pbs := m[i]
+		checkPageBox(t, msg, i, "MediaBox", pbs.MediaBox, mb)
+		checkPageBox(t, msg, i, "CropBox", pbs.CropBox, mb)
+		checkPageBox(t, msg, i, "BleedBox", pbs.BleedBox, mb.CroppedCopy(9))
+		checkPageBox(t, msg, i, "TrimBox", pbs.TrimBox, mb.CroppedCopy(18))
+		checkPageBox(t, msg, i, "ArtBox", pbs.ArtBox, pdfcpu.Rect(100, 100, 300, 300))
+	}
+
+	// Unselected pages report the default boxes.
+	pbs := m[3]
+	for _, pb := range []*pdfcpu.PageBox{pbs.MediaBox, pbs.CropBox, pbs.BleedBox, pbs.TrimBox, pbs.ArtBox} {
+		checkPageBox(t, msg, 3, "box", pb, rr[2])
+	}
+
+	// Insets are relative to the new media box.
+	boxes, err = pdfcpu.ParsePageBoxes("media:[0 0 600 800], crop:10 20")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.SetPageBoxesFile(inFile, outFile, *boxes, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if m, err = api.PageBoxesFile(outFile, []string{"1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	checkPageBox(t, msg, 1, "CropBox", m[1].CropBox, pdfcpu.Rect(20, 10, 580, 790))
+	checkPageBox(t, msg, 1, "TrimBox", m[1].TrimBox, pdfcpu.Rect(20, 10, 580, 790))
+
+	// A trim box exceeding the bleed box violates the nesting constraints.
+	boxes, err = pdfcpu.ParsePageBoxes("bleed:20, trim:10")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.SetPageBoxesFile(inFile, outFile, *boxes, nil, nil); err == nil {
+		t.Fatalf("%s: want error for trim box exceeding bleed box\n", msg)
+	}
+
+	// Boxes taking their default values are not subject to the nesting constraints.
+	boxes, err = pdfcpu.ParsePageBoxes("bleed:9")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.SetPageBoxesFile(inFile, outFile, *boxes, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, s := range []string{"trim", "trim:1 2 3", "trim:[0 0 10]", "trim:[10 10 0 0]", "cut:10"} {
+		if _, err := pdfcpu.ParsePageBoxes(s); err == nil {
+			t.Fatalf("%s: want error for %q\n", msg, s)
+		}
+	}
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestInsertRemovePages(t *testing.T) {
@@ -28,7 +32,7 @@ func TestInsertRemovePages(t *testing.T) {
 	inFile := filepath.Join(inDir, "Acroforms2.pdf")
 	outFile := filepath.Join(outDir, "test.pdf")
 
-	n1, err := api.PageCountFile(inFile)
+	n1, err := api.PageCountFile(inFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, inFile, err)
 	}
@@ -40,7 +44,7 @@ func TestInsertRemovePages(t *testing.T) {
 	if err := api.ValidateFile(outFile, nil); err != nil {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
-	n2, err := api.PageCountFile(outFile)
+	n2, err := api.PageCountFile(outFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, outFile, err)
 	}
@@ -55,7 +59,7 @@ func TestInsertRemovePages(t *testing.T) {
 	if err := api.ValidateFile(outFile, nil); err != nil {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
-	n2, err = api.PageCountFile(outFile)
+	n2, err = api.PageCountFile(outFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, inFile, err)
 	}
@@ -63,3 +67,564 @@ func TestInsertRemovePages(t *testing.T) {
 		t.Fatalf("%s %s: pageCount want:%d got:%d\n", msg, inFile, n1, n2)
 	}
 }
+
+func appendTestPage(fileName string, i int) error {
+	p := pdfcpu.NewPage(pdfcpu.RectForFormat("A4"))
+	fontID := p.Fm.EnsureKey("Helvetica")
+	fmt.Fprintf(p.Buf, "BT /%s 24 Tf 100 700 Td (Log page %d) Tj ET", fontID, i)
+	return api.AppendPageFile(fileName, p, nil)
+}
+
+func TestAppendPage(t *testing.T) {
+	msg := "TestAppendPage"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "appendPage.pdf")
+	if err := copyFile(t, inFile, outFile); err != nil {
+		t.Fatalf("%s copyFile: %v\n", msg, err)
+	}
+
+	orig, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n1, err := api.PageCountFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if err := appendTestPage(outFile, i); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n2, err := api.PageCountFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+	if n2 != n1+3 {
+		t.Fatalf("%s %s: pageCount want:%d got:%d\n", msg, outFile, n1+3, n2)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(n2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	content, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.Contains(content, []byte("(Log page 3)")) {
+		t.Fatalf("%s: missing content of appended page: %s\n", msg, content)
+	}
+
+	// Existing file content must remain untouched.
+	bb, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.HasPrefix(bb, orig) {
+		t.Fatalf("%s: original file content modified\n", msg)
+	}
+
+	// The updates continue the chain of xref streams of the original file.
+	if bytes.Contains(bb[len(orig):], []byte("trailer")) || !bytes.Contains(bb[len(orig):], []byte("/Type/XRef")) {
+		t.Fatalf("%s: want xref streams for the updates\n", msg)
+	}
+}
+
+func TestPreserveHeader(t *testing.T) {
+	msg := "TestPreserveHeader"
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.PreserveHeader = true
+
+	// Full rewrite of a PDF 1.5 file using CR as header eol.
+	inFile := filepath.Join(inDir, "WaldenFull.pdf")
+	outFile := filepath.Join(outDir, "preserveHeader.pdf")
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	header := []byte("%PDF-1.5\r%\xe2\xe3\xcf\xd3\r\n")
+	bb, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.HasPrefix(bb, header) {
+		t.Fatalf("%s: header not preserved: %q\n", msg, bb[:len(header)])
+	}
+	if bytes.Contains(bb, []byte("obj\n")) {
+		t.Fatalf("%s: eol not preserved\n", msg)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.RootVersion == nil || *ctx.RootVersion != pdfcpu.V17 {
+		t.Fatalf("%s: missing root version 1.7\n", msg)
+	}
+
+	// Incremental update of a file using CRLF.
+	inFile = filepath.Join(inDir, "Acroforms2.pdf")
+	outFile = filepath.Join(outDir, "preserveHeaderCRLF.pdf")
+	conf1 := pdfcpu.NewDefaultConfiguration()
+	conf1.Eol = pdfcpu.EolCRLF
+	if err := api.OptimizeFile(inFile, outFile, conf1); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	orig, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	p := pdfcpu.NewPage(pdfcpu.RectForFormat("A4"))
+	fontID := p.Fm.EnsureKey("Helvetica")
+	fmt.Fprintf(p.Buf, "BT /%s 24 Tf 100 700 Td (Appended page) Tj ET", fontID)
+	if err := api.AppendPageFile(outFile, p, conf); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err = ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.HasPrefix(bb, orig) {
+		t.Fatalf("%s: original file content modified\n", msg)
+	}
+	if !bytes.Contains(bb[len(orig):], []byte("xref\r\n")) {
+		t.Fatalf("%s: appended update does not use CRLF\n", msg)
+	}
+}
+
+func TestAddPageWithoutLength(t *testing.T) {
+	msg := "TestAddPageWithoutLength"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "addPageWithoutLength.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	n := ctx.PageCount
+
+	pagesIndRef, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pagesDict, err := ctx.DereferenceDict(*pagesIndRef)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Neither stream specifies its Length.
+	sd1, _ := ctx.NewStreamDictForBuf([]byte("0 0 1 rg 100 100 200 200 re f"))
+	sd2 := pdfcpu.StreamDict{Dict: pdfcpu.NewDict(), Raw: []byte("1 0 0 rg 350 350 100 100 re f")}
+
+	contents := pdfcpu.Array{}
+	for _, sd := range []pdfcpu.StreamDict{*sd1, sd2} {
+		ir, err := ctx.IndRefForNewObject(sd)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		contents = append(contents, *ir)
+	}
+
+	pageIndRef, err := ctx.IndRefForNewObject(pdfcpu.Dict{
+		"Type":     pdfcpu.Name("Page"),
+		"Parent":   *pagesIndRef,
+		"MediaBox": pdfcpu.RectForFormat("A4").Array(),
+		"Contents": contents,
+	})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pagesDict.Update("Kids", append(pagesDict.ArrayEntry("Kids"), *pageIndRef))
+	pagesDict.Update("Count", pdfcpu.Integer(n+1))
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	got, err := api.PageCountFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got != n+1 {
+		t.Fatalf("%s: pageCount want:%d got:%d\n", msg, n+1, got)
+	}
+}
+
+func BenchmarkAppendPage(b *testing.B) {
+	msg := "BenchmarkAppendPage"
+	outFile := filepath.Join(outDir, "appendPageBenchmark.pdf")
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		bb, err := ioutil.ReadFile(filepath.Join(inDir, "empty.pdf"))
+		if err != nil {
+			b.Fatalf("%s: %v\n", msg, err)
+		}
+		if err = ioutil.WriteFile(outFile, bb, 0644); err != nil {
+			b.Fatalf("%s: %v\n", msg, err)
+		}
+		b.StartTimer()
+		// Total time for 1000 appends is expected to grow near-linear.
+		for i := 1; i <= 1000; i++ {
+			if err := appendTestPage(outFile, i); err != nil {
+				b.Fatalf("%s: %v\n", msg, err)
+			}
+		}
+	}
+}
+
+func TestReorderPages(t *testing.T) {
+	msg := "TestReorderPages"
+	inFile := filepath.Join(outDir, "reorderPagesIn.pdf")
+	outFile := filepath.Join(outDir, "reorderPages.pdf")
+
+	bms := []pdfcpu.Bookmark{
+		{PageFrom: 1, Title: "Page 1"},
+		{PageFrom: 2, Title: "Page 2"},
+		{PageFrom: 3, Title: "Page 3"},
+	}
+	if err := api.AddBookmarksFile(filepath.Join(inDir, "go.pdf"), inFile, bms, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	links := []pdfcpu.LinkAnnotation{{Page: 1, Rect: pdfcpu.Rect(10, 10, 100, 100), DestPage: 3}}
+	if err := api.AddLinkAnnotationsFile(inFile, "", links, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	n := ctx.PageCount
+	texts := make([]string, n)
+	for i := range texts {
+		if texts[i], err = ctx.ExtractPageText(i + 1); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	// Reverse the page order.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = n - i
+	}
+	if err := api.ReorderPagesFile(inFile, outFile, order, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i, p := range order {
+		s, err := ctx.ExtractPageText(i + 1)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if s != texts[p-1] {
+			t.Fatalf("%s: page %d does not show former page %d\n", msg, i+1, p)
+		}
+	}
+
+	got, err := ctx.Bookmarks()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(got) != len(bms) {
+		t.Fatalf("%s: want %d bookmarks, got %d\n", msg, len(bms), len(got))
+	}
+	for i, bm := range got {
+		if bm.Title != bms[i].Title || bm.PageFrom != n+1-bms[i].PageFrom {
+			t.Fatalf("%s: bookmark %s points to page %d\n", msg, bm.Title, bm.PageFrom)
+		}
+	}
+
+	aa, err := api.ListAnnotationsFile(outFile, []string{fmt.Sprintf("%d", n)}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(aa) != 1 || aa[0].DestPage != n-2 {
+		t.Fatalf("%s: want link to page %d on page %d, got %v\n", msg, n-2, n, aa)
+	}
+
+	// order needs to be a permutation of all pages.
+	for _, order := range [][]int{{1, 2}, append([]int{1}, order[1:]...), append(order[:n-1:n-1], n+1)} {
+		if err := api.ReorderPagesFile(inFile, outFile, order, nil); err == nil {
+			t.Fatalf("%s: want error for order %v\n", msg, order)
+		}
+	}
+}
+
+func TestInsertBlankPagesForDuplex(t *testing.T) {
+	msg := "TestInsertBlankPagesForDuplex"
+	inFile := filepath.Join(outDir, "insertBlankPagesIn.pdf")
+	outFile := filepath.Join(outDir, "insertBlankPages.pdf")
+
+	bms := []pdfcpu.Bookmark{{PageFrom: 1, Title: "Page 1"}, {PageFrom: 3, Title: "Page 3"}}
+	if err := api.AddBookmarksFile(filepath.Join(inDir, "go.pdf"), inFile, bms, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	links := []pdfcpu.LinkAnnotation{{Page: 2, Rect: pdfcpu.Rect(10, 10, 100, 100), DestPage: 3}}
+	if err := api.AddLinkAnnotationsFile(inFile, "", links, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n, err := api.PageCountFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Insert a blank page after every page so that each page starts a new sheet.
+	positions := []int{}
+	for i := 2; i <= n+1; i++ {
+		positions = append(positions, i)
+	}
+	if err := api.InsertBlankPagesFile(inFile, outFile, positions, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.PageCount != 2*n {
+		t.Fatalf("%s: want %d pages, got %d\n", msg, 2*n, ctx.PageCount)
+	}
+	dims, err := ctx.PageDims()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i := 1; i < len(dims); i += 2 {
+		// Blank pages take the size of the following page.
+		if dims[i] != dims[i-1] {
+			t.Fatalf("%s: page %d: want %v, got %v\n", msg, i+1, dims[i-1], dims[i])
+		}
+		s, err := ctx.ExtractPageText(i + 1)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if s != "" {
+			t.Fatalf("%s: page %d is not blank\n", msg, i+1)
+		}
+	}
+
+	// Destinations follow their pages.
+	got, err := ctx.Bookmarks()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(got) != 2 || got[0].PageFrom != 1 || got[1].PageFrom != 5 {
+		t.Fatalf("%s: unexpected bookmarks: %v\n", msg, got)
+	}
+	aa, err := api.ListAnnotationsFile(outFile, []string{"3"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(aa) != 1 || aa[0].DestPage != 5 {
+		t.Fatalf("%s: want link to page 5 on page 3, got %v\n", msg, aa)
+	}
+
+	// Blank pages of given size before page 1.
+	dim := &pdfcpu.Dim{Width: 200, Height: 300}
+	if err := api.InsertBlankPagesFile(inFile, outFile, []int{1, 1}, dim, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if dims, err = ctx.PageDims(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(dims) != n+2 || dims[0] != *dim || dims[1] != *dim {
+		t.Fatalf("%s: unexpected page dims: %v\n", msg, dims[:3])
+	}
+
+	if err := api.InsertBlankPagesFile(inFile, outFile, []int{n + 2}, nil, nil); err == nil {
+		t.Fatalf("%s: want error for invalid position\n", msg)
+	}
+}
+
+func TestQuickInfo(t *testing.T) {
+	msg := "TestQuickInfo"
+
+	for _, fileName := range []string{"Acroforms2.pdf", "CenterOfWhy.pdf", "5116.DCT_Filter.pdf"} {
+		inFile := filepath.Join(inDir, fileName)
+		qi, err := api.QuickInfoFile(inFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		ctx, err := api.ReadContextFile(inFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fileName, err)
+		}
+		_, acroForm := ctx.RootDict.Find("AcroForm")
+		if qi.PageCount != ctx.PageCount || qi.Version != ctx.VersionString() || qi.Encrypted || qi.AcroForm != acroForm {
+			t.Fatalf("%s %s: want %d pages, version %s, acroForm %t, got: %s\n", msg, fileName, ctx.PageCount, ctx.VersionString(), acroForm, qi)
+		}
+	}
+
+	// Encrypted files still report their page count given the password.
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "quickInfoEnc.pdf")
+	want, err := api.PageCountFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.EncryptFile(inFile, outFile, pdfcpu.NewAESConfiguration("upw", "opw", 256)); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	qi, err := api.QuickInfoFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !qi.Encrypted {
+		t.Fatalf("%s: want encrypted, got: %s\n", msg, qi)
+	}
+
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.UserPW = "upw"
+	got, err := api.PageCountFile(outFile, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got != want {
+		t.Fatalf("%s: want %d pages, got: %d\n", msg, want, got)
+	}
+
+	// Files with an owner password only open with the empty user password.
+	if err := api.EncryptFile(inFile, outFile, pdfcpu.NewRC4Configuration("", "opw", 128)); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got, err = api.PageCountFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if got != want {
+		t.Fatalf("%s: want %d pages, got: %d\n", msg, want, got)
+	}
+}
+
+func TestReplacePage(t *testing.T) {
+	msg := "TestReplacePage"
+
+	src, err := api.ReadContextFile(filepath.Join(inDir, "CenterOfWhy.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want, err := src.PageDims()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	srcDict, _, err := src.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	wantContent, err := src.PageContent(srcDict)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, preserveAnnots := range []bool{false, true} {
+		ctx, err := api.ReadContextFile(filepath.Join(inDir, "Walden.pdf"))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		pageCount := ctx.PageCount
+
+		ll := []pdfcpu.LinkAnnotation{{Page: 2, Rect: pdfcpu.Rect(100, 100, 200, 120), URI: "https://pdfcpu.io"}}
+		if err := ctx.AddLinkAnnotations(ll); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		// An object not linked yet must survive the replacement.
+		ir, err := ctx.IndRefForNewObject(pdfcpu.Dict{})
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ctx.PreserveAnnotations = preserveAnnots
+		if err := ctx.ReplacePage(2, src, 1); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if d, err := ctx.DereferenceDict(*ir); err != nil || d == nil {
+			t.Fatalf("%s: unlinked object: %v\n", msg, err)
+		}
+
+		outFile := filepath.Join(outDir, fmt.Sprintf("replacePage%t.pdf", preserveAnnots))
+		if err := api.WriteContextFile(ctx, outFile); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		if ctx, err = api.ReadContextFile(outFile); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if ctx.PageCount != pageCount {
+			t.Fatalf("%s: want %d pages, got: %d\n", msg, pageCount, ctx.PageCount)
+		}
+
+		dims, err := ctx.PageDims()
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if dims[1] != want[0] {
+			t.Fatalf("%s: want page dimensions %v, got: %v\n", msg, want[0], dims[1])
+		}
+
+		d, _, err := ctx.PageDict(2, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		content, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !bytes.Equal(content, wantContent) {
+			t.Fatalf("%s: page 2 does not render the source page\n", msg)
+		}
+
+		aa, err := ctx.ListAnnotations(pdfcpu.IntSet{2: true})
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if preserveAnnots != (len(aa) == 1) {
+			t.Fatalf("%s: preserveAnnotations=%t, got %d annotations\n", msg, preserveAnnots, len(aa))
+		}
+	}
+
+	// The source document stays intact.
+	if err := api.ValidateContext(src); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
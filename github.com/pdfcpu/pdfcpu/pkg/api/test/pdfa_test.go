@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+const testPDFAXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/" pdfaid:part="%s">
+   <pdfaid:conformance>B</pdfaid:conformance>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func checkPDFA(t *testing.T, msg, inFile, level string, want ...string) {
+	t.Helper()
+	issues, err := api.CheckPDFAFile(inFile, level, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("%s %s: want %d issues, got %v\n", msg, level, len(want), issues)
+	}
+	for i, s := range want {
+		if !strings.Contains(issues[i].Message, s) {
+			t.Fatalf("%s %s: want issue %q, got %q\n", msg, level, s, issues[i].Message)
+		}
+	}
+}
+
+func TestCheckPDFA(t *testing.T) {
+	msg := "TestCheckPDFA"
+	inFile := filepath.Join(inDir, "annotTest.pdf")
+	outFile := filepath.Join(outDir, "pdfa.pdf")
+
+	if _, err := api.CheckPDFAFile(inFile, "3x", nil); err == nil {
+		t.Fatalf("%s: unsupported level accepted\n", msg)
+	}
+
+	checkPDFA(t, msg, inFile, "1b", "missing PDF/A output intent", "missing XMP metadata")
+	checkPDFA(t, msg, filepath.Join(inDir, "go.pdf"), "2b", "missing PDF/A output intent", "missing XMP metadata",
+		"font Arial is not embedded", "font Arial,Bold is not embedded", "font Times New Roman,Italic is not embedded")
+
+	// Identify as PDF/A-2b and add an output intent.
+	if err := api.SetXMPFile(inFile, outFile, []byte(strings.Replace(testPDFAXMP, "%s", "2", 1)), nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _ := ctx.NewStreamDictForBuf([]byte("ICC profile"))
+	sd.InsertInt("N", 3)
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	oi := pdf.NewDict()
+	oi.InsertName("Type", "OutputIntent")
+	oi.InsertName("S", "GTS_PDFA1")
+	oi.InsertString("OutputConditionIdentifier", "sRGB")
+	oi.Insert("DestOutputProfile", *ir)
+	rootDict.Insert("OutputIntents", pdf.Array{oi})
+
+	// Transparency is fine for PDF/A-2 but not for PDF/A-1.
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	gs := pdf.NewDict()
+	gs.Insert("ca", pdf.Float(0.5))
+	resDict, err := ctx.DereferenceDict(d["Resources"])
+	if err != nil || resDict == nil {
+		t.Fatalf("%s: missing resources: %v\n", msg, err)
+	}
+	resDict.Insert("ExtGState", pdf.Dict(map[string]pdf.Object{"GS0": gs}))
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	checkPDFA(t, msg, outFile, "2b")
+	checkPDFA(t, msg, outFile, "PDF/A-1b", "XMP metadata claims PDF/A-2", "ca 0.50 is not permitted")
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestDocMDP(t *testing.T) {
+	msg := "TestDocMDP"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "DocMDP.pdf")
+
+	p, err := api.GetPermsFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if p.DocMDP || p.UR3 {
+		t.Fatalf("%s: want no permissions, got: %s\n", msg, p)
+	}
+
+	// Prepare a certification signature allowing form fill.
+	if err := api.SetDocMDPFile(inFile, outFile, pdfcpu.DocMDPFormFill, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if p, err = api.GetPermsFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !p.DocMDP || p.DocMDPLevel != pdfcpu.DocMDPFormFill || p.DocMDPObjNr == 0 {
+		t.Fatalf("%s: want DocMDP form fill, got: %s\n", msg, p)
+	}
+	objNr := p.DocMDPObjNr
+
+	// Tighten the existing access permissions.
+	if err := api.SetDocMDPFile(outFile, outFile, pdfcpu.DocMDPNoChanges, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if p, err = api.GetPermsFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !p.DocMDP || p.DocMDPLevel != pdfcpu.DocMDPNoChanges || p.DocMDPObjNr != objNr {
+		t.Fatalf("%s: want DocMDP no changes for obj#%d, got: %s\n", msg, objNr, p)
+	}
+
+	if err := api.SetDocMDPFile(outFile, outFile, 4, nil); err == nil {
+		t.Fatalf("%s: missing error for invalid access permissions\n", msg)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The pdf Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func within(r1, r2 *pdfcpu.Rectangle) bool {
+	return r1.LL.X >= r2.LL.X && r1.LL.Y >= r2.LL.Y && r1.UR.X <= r2.UR.X && r1.UR.Y <= r2.UR.Y
+}
+
+func TestAddPrintMarks(t *testing.T) {
+	msg := "TestAddPrintMarks"
+	inFile := filepath.Join(outDir, "mixedPageSizes.pdf")
+	boxFile := filepath.Join(outDir, "printMarksBoxes.pdf")
+	outFile := filepath.Join(outDir, "printMarks.pdf")
+
+	rr := createMixedPageSizes(t, msg, inFile)
+
+	// Page 1 has room for the marks, page 2 and 3 need a larger media box.
+	for _, c := range []struct {
+		pages []string
+		desc  string
+	}{
+		{[]string{"1"}, "bleed:47, trim:50"},
+		{[]string{"2-3"}, "bleed:9, trim:18"},
+	} {
+		boxes, err := pdfcpu.ParsePageBoxes(c.desc)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		from := inFile
+		if c.pages[0] != "1" {
+			from = boxFile
+		}
+		if err := api.SetPageBoxesFile(from, boxFile, *boxes, c.pages, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	pm := pdfcpu.DefaultPrintMarks()
+	pm.ColorBars = true
+	if err := api.AddPrintMarksFile(boxFile, outFile, nil, pm, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m, err := api.PageBoxesFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		pbs := m[i]
+
+		trim, bleed := 18., 9.
+		if i == 1 {
+			trim, bleed = 50, 47
+		}
+		checkPageBox(t, msg, i, "TrimBox", pbs.TrimBox, rr[i-1].CroppedCopy(trim))
+		checkPageBox(t, msg, i, "BleedBox", pbs.BleedBox, rr[i-1].CroppedCopy(bleed))
+
+		// Marks start outside the bleed box and extend by the mark length.
+		marks := pbs.BleedBox.Rect.CroppedCopy(-pm.Length)
+		if i == 1 {
+			// The bleed box is closer to the trim box than the mark offset.
+			marks = pbs.TrimBox.Rect.CroppedCopy(-pm.Offset - pm.Length)
+			checkPageBox(t, msg, i, "MediaBox", pbs.MediaBox, rr[0])
+		}
+		if !within(marks, pbs.MediaBox.Rect) || !within(marks, pbs.CropBox.Rect) {
+			t.Fatalf("%s: page %d: marks %v exceed media box %v\n", msg, i, marks, pbs.MediaBox.Rect)
+		}
+
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, s := range []string{"/Artifact", "1 1 1 1 K", "0.00 0.00 0.00 1.00 k"} {
+			if !bytes.Contains(bb, []byte(s)) {
+				t.Fatalf("%s: page %d: missing %q\n", msg, i, s)
+			}
+		}
+	}
+
+	// Disabled marks.
+	pm = &pdfcpu.PrintMarks{CropMarks: true, Length: 10}
+	if err := api.AddPrintMarksFile(boxFile, outFile, []string{"1"}, pm, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// The marks get appended as last content stream.
+	a, err := ctx.DereferenceArray(firstPageDict(t, ctx)["Contents"])
+	if err != nil || len(a) == 0 {
+		t.Fatalf("%s: missing content: %v\n", msg, err)
+	}
+	sd, err := ctx.DereferenceStreamDict(a[len(a)-1])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb := sd.Content
+	if bytes.Contains(bb, []byte(" k ")) || bytes.Contains(bb, []byte(" c ")) {
+		t.Fatalf("%s: want crop marks only\n", msg)
+	}
+
+	if err := api.AddPrintMarksFile(boxFile, outFile, nil, &pdfcpu.PrintMarks{}, nil); err == nil {
+		t.Fatalf("%s: want error for zero mark length\n", msg)
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestRedactText(t *testing.T) {
+	msg := "TestRedactText"
+	inFile := filepath.Join(outDir, "redactTextIn.pdf")
+	outFile := filepath.Join(outDir, "redactText.pdf")
+
+	writeTextWithLinksTestFile(t, msg, inFile)
+
+	// Make the font an indirect object as expected by the optimizer.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fonts := d.DictEntry("Resources").DictEntry("Font")
+	ir, err := ctx.IndRefForNewObject(fonts["F1"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fonts["F1"] = *ir
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Cover "pdfcpu.io" of the first line and "chap" of the second line.
+	areas := map[int][]pdf.Rectangle{
+		1: {*pdf.Rect(45, 495, 95, 512), *pdf.Rect(47, 475, 74, 490)},
+	}
+	if err := api.RedactFile(inFile, outFile, areas, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	pp, err := api.ExtractTextFile(outFile, []string{"1"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	got := []string{}
+	for _, ts := range pp[0].Spans {
+		got = append(got, ts.Text, ts.Rect.String())
+	}
+
+	// Remaining glyphs keep their position.
+	want := []string{
+		"Visit ", "(20.00, 497.00, 46.00, 509.00) w=26.00 h=12.00 ar=2.17",
+		" for more.", "(94.70, 497.00, 146.05, 509.00) w=51.35 h=12.00 ar=4.28",
+		"See ter", "(20.00, 477.00, 87.70, 489.00) w=67.70 h=12.00 ar=5.64",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: want %q, got: %q\n", msg, want, got)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, _, err = ctx.PageDict(1, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.HasSuffix(string(bb), "q 0 g\n45.00 495.00 50.00 17.00 re f\n47.00 475.00 27.00 15.00 re f\nQ\n") {
+		t.Fatalf("%s: missing redaction boxes: %s\n", msg, bb)
+	}
+
+	// Pages out of range are rejected.
+	if err := api.RedactFile(inFile, outFile, map[int][]pdf.Rectangle{5000: {*pdf.Rect(0, 0, 10, 10)}}, nil); err == nil {
+		t.Fatalf("%s: want error for invalid page\n", msg)
+	}
+}
+
+func TestRedactImage(t *testing.T) {
+	msg := "TestRedactImage"
+	imgFile := filepath.Join(outDir, "redactImage.png")
+	inFile := filepath.Join(outDir, "redactImageIn.pdf")
+	outFile := filepath.Join(outDir, "redactImage.pdf")
+	os.Remove(inFile)
+
+	writeScanTestFile(t, msg, imgFile, &image.Rectangle{Min: image.Point{X: 50, Y: 20}, Max: image.Point{X: 100, Y: 40}})
+
+	// Centered on A4 at 1 pixel per point starting at 197.5,371.
+	imp, err := pdf.ParseImportDetails("pos:c, sc:1 abs")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ImportImagesFile([]string{imgFile}, inFile, imp, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Cover the pixel columns 3 to 22 of the rows 71 to 90.
+	areas := map[int][]pdf.Rectangle{1: {*pdf.Rect(200, 380, 220, 400)}}
+	if err := api.RedactFile(inFile, outFile, areas, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// No image of the output file holds the covered pixels.
+	images := xObjects(t, msg, ctx, "Image")
+	if len(images) == 0 {
+		t.Fatalf("%s: missing masked image\n", msg)
+	}
+	for _, sd := range images {
+		for _, tt := range []struct {
+			x, y int
+			want byte
+		}{
+			{10, 80, 0},    // masked
+			{150, 80, 255}, // white
+			{60, 30, 0},    // black
+		} {
+			if got := sd.Content[tt.y*200+tt.x]; got != tt.want {
+				t.Fatalf("%s: pixel %d,%d: want %d, got %d\n", msg, tt.x, tt.y, tt.want, got)
+			}
+		}
+	}
+}
+
+// xObjects returns the decoded XObjects of subtype st of ctx.
+func xObjects(t *testing.T, msg string, ctx *pdf.Context, st string) []*pdf.StreamDict {
+	t.Helper()
+
+	var sds []*pdf.StreamDict
+	for objNr := range ctx.Table {
+		o, err := ctx.Dereference(*pdf.NewIndirectRef(objNr, *ctx.Table[objNr].Generation))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		sd, ok := o.(pdf.StreamDict)
+		if !ok || sd.Subtype() == nil || *sd.Subtype() != st {
+			continue
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		sds = append(sds, &sd)
+	}
+
+	return sds
+}
+
+func TestRedactForm(t *testing.T) {
+	msg := "TestRedactForm"
+	inFile := filepath.Join(outDir, "redactFormIn.pdf")
+	outFile := filepath.Join(outDir, "redactForm.pdf")
+
+	writeTextWithLinksTestFile(t, msg, inFile)
+
+	// Move the page content into a form.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, err := ctx.NewStreamDictForBuf(bb)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd.Insert("Type", pdf.Name("XObject"))
+	sd.Insert("Subtype", pdf.Name("Form"))
+	sd.Insert("BBox", pdf.NewIntegerArray(0, 0, 600, 800))
+	sd.Insert("Resources", d.DictEntry("Resources").Clone())
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if sd, err = ctx.NewStreamDictForBuf([]byte("/Fm0 Do")); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	contents, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Contents", *contents)
+	d.Update("Resources", pdf.Dict{"XObject": pdf.Dict{"Fm0": *ir}})
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Cover "pdfcpu.io" of the first line and "chap" of the second line.
+	areas := map[int][]pdf.Rectangle{
+		1: {*pdf.Rect(45, 495, 95, 512), *pdf.Rect(47, 475, 74, 490)},
+	}
+	if err := api.RedactFile(inFile, outFile, areas, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	// No form of the output file holds the covered text.
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	forms := xObjects(t, msg, ctx, "Form")
+	if len(forms) == 0 {
+		t.Fatalf("%s: missing redacted form\n", msg)
+	}
+	for _, sd := range forms {
+		for _, s := range []string{"pdfcpu.io", "chap", "63686170"} {
+			if strings.Contains(string(sd.Content), s) {
+				t.Fatalf("%s: form still holds %q: %s\n", msg, s, sd.Content)
+			}
+		}
+		if !strings.Contains(string(sd.Content), "( for more.)") {
+			t.Fatalf("%s: form lost uncovered text: %s\n", msg, sd.Content)
+		}
+	}
+}
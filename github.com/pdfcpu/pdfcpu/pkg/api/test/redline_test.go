@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// writeRedlineTestFile writes go.pdf to outFile replacing the content of the first page by lines of Helvetica text.
+func writeRedlineTestFile(t *testing.T, msg, outFile string, lines ...string) {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	page1, _ := firstTwoPages(t, msg, ctx)
+
+	s := "BT /F1 12 Tf 72 700 Td"
+	for _, l := range lines {
+		s += " (" + l + ") Tj 0 -20 Td"
+	}
+	s += " ET"
+
+	sd, err := ctx.NewStreamDictForBuf([]byte(s))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	fontIndRef, err := ctx.IndRefForNewObject(pdf.Dict{
+		"Type":     pdf.Name("Font"),
+		"Subtype":  pdf.Name("Type1"),
+		"BaseFont": pdf.Name("Helvetica"),
+	})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, err := ctx.DereferenceDict(page1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Contents", *ir)
+	d.Update("Resources", pdf.Dict{"Font": pdf.Dict{"F1": *fontIndRef}})
+	d.Delete("Annots")
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func pageAnnotations(t *testing.T, msg string, ctx *pdf.Context, pageNr int) []pdf.Dict {
+	t.Helper()
+
+	d, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	a, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	dd := []pdf.Dict{}
+	for _, o := range a {
+		ad, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		dd = append(dd, ad)
+	}
+
+	return dd
+}
+
+func TestRedline(t *testing.T) {
+	msg := "TestRedline"
+	oldFile := filepath.Join(outDir, "redlineOld.pdf")
+	newFile := filepath.Join(outDir, "redlineNew.pdf")
+	outFile := filepath.Join(outDir, "redline.pdf")
+
+	writeRedlineTestFile(t, msg, oldFile, "The quick brown fox", "jumps over the dog.", "Unchanged line", "Removed line")
+	writeRedlineTestFile(t, msg, newFile, "The quick red fox", "jumps over the lazy dog.", "Unchanged line")
+
+	if err := api.RedlineFile(oldFile, newFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// "red" and "lazy" get highlighted, both changed lines and the removed line get marked.
+	var highlights, markers []pdf.Dict
+	for _, d := range pageAnnotations(t, msg, ctx, 1) {
+		switch *d.Subtype() {
+		case "Highlight":
+			highlights = append(highlights, d)
+		case "Square":
+			markers = append(markers, d)
+		}
+		if _, found := d.Find("AP"); !found {
+			t.Fatalf("%s: missing appearance: %s\n", msg, d)
+		}
+	}
+	if len(highlights) != 2 || len(markers) != 3 {
+		t.Fatalf("%s: want 2 highlights and 3 markers, got %d and %d\n", msg, len(highlights), len(markers))
+	}
+
+	// "red" follows "The quick " on the first line.
+	r := highlights[0].ArrayEntry("Rect")
+	x0, _ := ctx.DereferenceNumber(r[0])
+	y0, _ := ctx.DereferenceNumber(r[1])
+	if x0 < 120 || x0 > 140 || y0 > 700 || y0 < 690 {
+		t.Fatalf("%s: unexpected highlight rect: %v\n", msg, r)
+	}
+
+	// Identical pages remain untouched.
+	ctx0, err := api.ReadContextFile(newFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i := 2; i <= ctx.PageCount; i++ {
+		if got, want := len(pageAnnotations(t, msg, ctx, i)), len(pageAnnotations(t, msg, ctx0, i)); got != want {
+			t.Fatalf("%s: page %d: want %d annotations, got %d\n", msg, i, want, got)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// pageImage returns the single image rendered on page pageNr of fileName.
+func pageImage(t *testing.T, msg, fileName string, pageNr int) *pdf.StreamDict {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	resDict, err := ctx.DereferenceDict(d["Resources"])
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	xObjects, err := ctx.DereferenceDict(resDict["XObject"])
+	if err != nil || len(xObjects) != 1 {
+		t.Fatalf("%s: page %d: want 1 image: %v\n", msg, pageNr, err)
+	}
+	for _, o := range xObjects {
+		sd, err := ctx.DereferenceStreamDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return sd
+	}
+	return nil
+}
+
+func TestResampleImages(t *testing.T) {
+	msg := "TestResampleImages"
+	inFile := filepath.Join(outDir, "resampleImagesIn.pdf")
+	outFile := filepath.Join(outDir, "resampleImages.pdf")
+	os.Remove(inFile)
+
+	// A flate encoded and a DCT encoded image at 288 dpi followed by an image at 72 dpi.
+	for _, tt := range []struct {
+		fileName, desc string
+	}{
+		{"logoSmall.png", "pos:c, sc:.25 abs"},
+		{"snow.jpg", "pos:c, sc:.25 abs"},
+		{"pdfchip3.png", "pos:c, sc:1 abs"},
+	} {
+		imp, err := pdf.ParseImportDetails(tt.desc)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ImportImagesFile([]string{filepath.Join(resDir, tt.fileName)}, inFile, imp, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+
+	if err := api.ResampleImagesFile(inFile, outFile, 144, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: validate: %v\n", msg, err)
+	}
+
+	ii, err := api.ImageResolutionReportFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ii) != 3 {
+		t.Fatalf("%s: want 3 placements, got %d\n", msg, len(ii))
+	}
+	for i, dpi := range []float64{144, 144, 72} {
+		if math.Abs(ii[i].DPI()-dpi) > 2 {
+			t.Fatalf("%s: page %d: want %.0f dpi got %.2f\n", msg, i+1, dpi, ii[i].DPI())
+		}
+	}
+
+	for i := 1; i <= 2; i++ {
+		sd, sd1 := pageImage(t, msg, inFile, i), pageImage(t, msg, outFile, i)
+		if len(sd1.Raw) >= len(sd.Raw) {
+			t.Fatalf("%s: page %d: image grew from %d to %d bytes\n", msg, i, len(sd.Raw), len(sd1.Raw))
+		}
+		if sd.FilterPipeline[0].Name != sd1.FilterPipeline[0].Name || sd.Dict["ColorSpace"].String() != sd1.Dict["ColorSpace"].String() {
+			t.Fatalf("%s: page %d: want filter and color space preserved\n", msg, i)
+		}
+	}
+
+	// Images at or below the target resolution remain untouched.
+	if sd, sd1 := pageImage(t, msg, inFile, 3), pageImage(t, msg, outFile, 3); !bytes.Equal(sd.Raw, sd1.Raw) {
+		t.Fatalf("%s: page 3: image modified\n", msg)
+	}
+}
@@ -18,9 +18,11 @@ package test
 
 import (
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestRotate(t *testing.T) {
@@ -41,3 +43,128 @@ func TestRotate(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestListRotatedPages(t *testing.T) {
+	msg := "TestListRotatedPages"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "rotatedPages.pdf")
+
+	ss, err := api.ListRotatedPagesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 0 {
+		t.Fatalf("%s: want no rotated pages, got: %v\n", msg, ss)
+	}
+
+	// Rotate all pages via the page tree root, then override pages 1 and 2.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	root, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*root)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Rotate", pdfcpu.Integer(-90))
+	for i, r := range []int{0, 180} {
+		pageDict, _, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		pageDict.Update("Rotate", pdfcpu.Integer(r))
+	}
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ss, err = api.ListRotatedPagesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != ctx.PageCount-1 {
+		t.Fatalf("%s: want %d rotated pages, got: %v\n", msg, ctx.PageCount-1, ss)
+	}
+	if want := []string{"page 2: 180", "page 3: 270"}; !reflect.DeepEqual(ss[:2], want) {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, ss[:2])
+	}
+}
+
+func TestNormalizeRotation(t *testing.T) {
+	msg := "TestNormalizeRotation"
+	inFile := filepath.Join(outDir, "normalizeRotationIn.pdf")
+	outFile := filepath.Join(outDir, "normalizeRotation.pdf")
+
+	for _, rot := range []int{90, 180, 270, -90} {
+		// Links cover the page content and map to display space.
+		writeLinkMapTestFile(t, msg, inFile, rot)
+
+		want, err := api.LinkMapFile(inFile, 1, 144, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		if err := api.NormalizeRotationFile(inFile, outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ss, err := api.ListRotatedPagesFile(outFile, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(ss) != 0 {
+			t.Fatalf("%s rot=%d: want no rotated pages, got: %v\n", msg, rot, ss)
+		}
+
+		got, err := api.LinkMapFile(outFile, 1, 144, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s rot=%d: want %v, got %v\n", msg, rot, want, got)
+		}
+	}
+
+	// Form widgets get rotated along with the content.
+	xRefTable, err := pdfcpu.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFile = filepath.Join(outDir, "normalizeRotationForm.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.RotateFile(inFile, "", 90, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.NormalizeRotationFile(inFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	before, err := api.ListAnnotationsFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	after, err := api.ListAnnotationsFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(after) != len(before) || len(after) == 0 {
+		t.Fatalf("%s: want %d annotations, got %d\n", msg, len(before), len(after))
+	}
+	for i, a := range after {
+		// Rotated by 90 degrees widths and heights swap.
+		b := before[i]
+		if int(a.Rect.Width()+.5) != int(b.Rect.Height()+.5) || int(a.Rect.Height()+.5) != int(b.Rect.Width()+.5) {
+			t.Fatalf("%s: annotation not rotated: %s -> %s\n", msg, b, a)
+		}
+	}
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package test
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 )
 
 func TestSplitSpan1(t *testing.T) {
@@ -59,6 +62,123 @@ func TestSplit0ByBookmark(t *testing.T) {
 	}
 }
 
+func TestSplitBySize(t *testing.T) {
+	msg := "TestSplitBySize"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	want, err := api.PageCountFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		maxBytes int64
+		files    int
+	}{
+		{100000, 2},
+		// Page 1 exceeds maxBytes.
+		{60000, 3},
+	} {
+		dir, err := ioutil.TempDir(outDir, "splitBySize")
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer os.RemoveAll(dir)
+
+		if err := api.SplitBySizeFile(inFile, dir, tt.maxBytes, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(files) != tt.files {
+			t.Fatalf("%s %d: want %d files, got %d\n", msg, tt.maxBytes, tt.files, len(files))
+		}
+
+		got := 0
+		for _, fi := range files {
+			n, err := api.PageCountFile(filepath.Join(dir, fi.Name()), nil)
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			// Only single pages may exceed maxBytes.
+			if fi.Size() > tt.maxBytes && n > 1 {
+				t.Fatalf("%s: %s exceeds %d bytes: %d\n", msg, fi.Name(), tt.maxBytes, fi.Size())
+			}
+			got += n
+		}
+		if got != want {
+			t.Fatalf("%s %d: pageCount want:%d got:%d\n", msg, tt.maxBytes, want, got)
+		}
+	}
+
+	if err := api.SplitBySizeFile(inFile, outDir, 0, nil); err == nil {
+		t.Fatalf("%s: missing error for invalid size\n", msg)
+	}
+}
+
+func TestSplitByBookmark(t *testing.T) {
+	msg := "TestSplitByBookmark"
+	inFile := filepath.Join(outDir, "bookmarksForSplit.pdf")
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "5116.DCT_Filter.pdf"))
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	// Colliding titles not suitable for file names.
+	dd := firstOutlineItems(t, msg, ctx)
+	dd[0].Update("Title", pdfcpu.StringLiteral("Part/1"))
+	dd[1].Update("Title", pdfcpu.StringLiteral("part:1"))
+
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(inFile); err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+
+	for depth := 1; depth <= 2; depth++ {
+		bms, err := ctx.BookmarksForOutlineLevel(depth)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		dir, err := ioutil.TempDir(outDir, "splitByBookmark")
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer os.RemoveAll(dir)
+
+		if err := api.SplitByBookmarkFile(inFile, dir, depth, nil); err != nil {
+			t.Fatalf("%s depth %d: %v\n", msg, depth, err)
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(files) != len(bms) {
+			t.Fatalf("%s depth %d: want %d files, got %d\n", msg, depth, len(bms), len(files))
+		}
+
+		if depth == 1 {
+			for _, fn := range []string{"Part_1.pdf", "part_1_2.pdf"} {
+				if _, err := os.Stat(filepath.Join(dir, fn)); err != nil {
+					t.Fatalf("%s: %v\n", msg, err)
+				}
+			}
+		}
+	}
+
+	if err := api.SplitByBookmarkFile(filepath.Join(inDir, "Acroforms2.pdf"), outDir, 1, nil); err == nil {
+		t.Fatalf("%s: missing error for missing outlines\n", msg)
+	}
+}
+
 func TestSplitLowLevel(t *testing.T) {
 	msg := "TestSplitLowLevel"
 	inFile := filepath.Join(inDir, "TheGoProgrammingLanguageCh1.pdf")
@@ -17,8 +17,14 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
@@ -371,6 +377,24 @@ func TestAddWatermarks(t *testing.T) {
 			filepath.Join(resDir, "logoSmall.png"),
 			"scale:.33 abs, rot:180"},
 
+		// Add image watermark clipped to a circle.
+		{"TestWatermarkImageClipCircle",
+			"Walden.pdf",
+			"ImageClipCircle.pdf",
+			[]string{"1-"},
+			"image",
+			filepath.Join(resDir, "snow.jpg"),
+			"sc:.5, rot:30, shape:circle"},
+
+		// Add image watermark clipped to a rounded rectangle.
+		{"TestWatermarkImageClipRound",
+			"Walden.pdf",
+			"ImageClipRound.pdf",
+			[]string{"1-"},
+			"image",
+			filepath.Join(resDir, "snow.jpg"),
+			"scale:.4 abs, pos:tr, rot:0, shape:round, radius:20"},
+
 		// Add a PDF stamp to all pages of inFile using the 3rd page of pdfFile
 		// and rotate along the 2nd diagonal running from upper left to lower right corner.
 		{"TestWatermarkPDF",
@@ -390,12 +414,71 @@ func TestAddWatermarks(t *testing.T) {
 			"pdf",
 			filepath.Join(inDir, "Walden.pdf"),
 			"sc:.2, pos:tr, off:-10 -10, rot:0"},
+
+		// Repeat a text watermark in a grid covering all pages.
+		{"TestWatermarkTextTiled",
+			"Walden.pdf",
+			"TextTiled.pdf",
+			nil,
+			"text",
+			"CONFIDENTIAL",
+			"sc:.3 rel, rot:30, op:.4, tiled:true, tilespacing:20"},
+
+		// Repeat an image watermark in a grid covering all pages.
+		{"TestWatermarkImageTiled",
+			"Walden.pdf",
+			"ImageTiled.pdf",
+			nil,
+			"image",
+			filepath.Join(resDir, "logoSmall.png"),
+			"sc:.2 rel, rot:0, op:.5, tiled:on"},
+
+		// Repeat a PDF watermark in a grid covering all pages.
+		{"TestWatermarkPDFTiled",
+			"Walden.pdf",
+			"PdfTiled.pdf",
+			nil,
+			"pdf",
+			filepath.Join(inDir, "Walden.pdf:1"),
+			"sc:.25 rel, rot:45, tiled:true, tilespacing:10"},
 	} {
 		testAddWatermarks(t, tt.msg, tt.inFile, tt.outFile, tt.selectedPages, tt.mode, tt.modeParm, tt.wmConf, false)
 		testAddWatermarks(t, tt.msg, tt.inFile, tt.outFile, tt.selectedPages, tt.mode, tt.modeParm, tt.wmConf, true)
 	}
 }
 
+func TestOverlay(t *testing.T) {
+	msg := "TestOverlay"
+	baseFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	for _, tt := range []struct {
+		overlayFile string
+		outFile     string
+		onTop       bool
+	}{
+		// Repeat the first page of the overlay on top of every base page.
+		{filepath.Join(inDir, "Walden.pdf:1"), "OverlayRepeat.pdf", true},
+
+		// Pair base and overlay pages by page number in the background.
+		{filepath.Join(inDir, "Walden.pdf"), "OverlayPaired.pdf", false},
+	} {
+		outFile := filepath.Join(outDir, tt.outFile)
+		if err := api.OverlayFile(baseFile, tt.overlayFile, outFile, tt.onTop, nil, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+		if ok := hasWatermarks(outFile, t); !ok {
+			t.Fatalf("%s: missing overlay: %s\n", msg, outFile)
+		}
+	}
+
+	if err := api.OverlayFile(baseFile, filepath.Join(resDir, "snow.jpg"), filepath.Join(outDir, "x.pdf"), true, nil, nil); err == nil {
+		t.Fatalf("%s: missing error for non PDF overlay\n", msg)
+	}
+}
+
 func hasWatermarks(inFile string, t *testing.T) bool {
 	t.Helper()
 	ok, err := api.HasWatermarksFile(inFile, nil)
@@ -475,3 +558,315 @@ func TestStampingLifecyle(t *testing.T) {
 		t.Fatalf("Watermarks found: %s\n", outFile)
 	}
 }
+
+func TestParseImageWatermarkClip(t *testing.T) {
+	msg := "TestParseImageWatermarkClip"
+	fileName := filepath.Join(resDir, "logoSmall.png")
+
+	for _, tt := range []struct {
+		desc   string
+		clip   pdf.ClipShape
+		radius float64
+	}{
+		{"shape:circle", pdf.ClipCircle, 0},
+		{"shape:round", pdf.ClipRound, 0},
+		{"shape:round 5", pdf.ClipRound, 5},
+		{"shape:round, radius:10", pdf.ClipRound, 10},
+		{"radius:10", pdf.ClipRound, 10},
+	} {
+		wm, err := pdf.ParseImageWatermarkDetails(fileName, tt.desc, true)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.desc, err)
+		}
+		if wm.Clip != tt.clip || wm.ClipRadius != tt.radius {
+			t.Fatalf("%s %s: got clip %d radius %.1f\n", msg, tt.desc, wm.Clip, wm.ClipRadius)
+		}
+	}
+
+	for _, desc := range []string{"shape:square", "shape:circle 5", "radius:-1"} {
+		if _, err := pdf.ParseImageWatermarkDetails(fileName, desc, true); err == nil {
+			t.Fatalf("%s %s: want error\n", msg, desc)
+		}
+	}
+
+	// Only images get clipped.
+	if _, err := pdf.ParseTextWatermarkDetails("Draft", "shape:circle", true); err == nil {
+		t.Fatalf("%s: want error for text watermark\n", msg)
+	}
+}
+
+func TestPDFWatermarkTemplate(t *testing.T) {
+	msg := "TestPDFWatermarkTemplate"
+
+	wm, err := api.PDFWatermarkTemplate(filepath.Join(inDir, "Walden.pdf:1"), "sc:.2, pos:tr, off:-10 -10, rot:0", true, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Apply the same template to several documents.
+	for _, fileName := range []string{"Acroforms2.pdf", "Walden.pdf", "go.pdf"} {
+		outFile := filepath.Join(outDir, "Template"+fileName)
+		if err := api.AddWatermarksFile(filepath.Join(inDir, fileName), outFile, nil, wm, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+		if ok := hasWatermarks(outFile, t); !ok {
+			t.Fatalf("%s: missing stamp: %s\n", msg, outFile)
+		}
+	}
+}
+
+func BenchmarkPDFWatermarkTemplate(b *testing.B) {
+	stampFile := filepath.Join(inDir, "WaldenFull.pdf:1")
+	desc := "sc:.2, pos:tr, off:-10 -10, rot:0"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "stampBenchmark.pdf")
+
+	for _, tt := range []struct {
+		name   string
+		cached bool
+	}{
+		{"reparse", false},
+		{"template", true},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			msg := "BenchmarkPDFWatermarkTemplate " + tt.name
+			var (
+				wm  *pdf.Watermark
+				err error
+			)
+			if tt.cached {
+				if wm, err = api.PDFWatermarkTemplate(stampFile, desc, true, false); err != nil {
+					b.Fatalf("%s: %v\n", msg, err)
+				}
+			}
+			for n := 0; n < b.N; n++ {
+				if !tt.cached {
+					if wm, err = api.PDFWatermark(stampFile, desc, true, false); err != nil {
+						b.Fatalf("%s: %v\n", msg, err)
+					}
+				}
+				if err := api.AddWatermarksFile(inFile, outFile, nil, wm, nil); err != nil {
+					b.Fatalf("%s: %v\n", msg, err)
+				}
+			}
+		})
+	}
+}
+
+// embeddedFontPrograms returns true for each font named baseFont if it embeds a font program.
+func embeddedFontPrograms(t *testing.T, fileName, baseFont string) []bool {
+	t.Helper()
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", fileName, err)
+	}
+	bb := []bool{}
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free {
+			continue
+		}
+		d, ok := entry.Object.(pdf.Dict)
+		if !ok || d.Type() == nil || *d.Type() != "Font" {
+			continue
+		}
+		if n := d.NameEntry("BaseFont"); n == nil || *n != baseFont {
+			continue
+		}
+		fd, err := ctx.DereferenceDict(d["FontDescriptor"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", fileName, err)
+		}
+		embedded := false
+		for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+			if _, found := fd.Find(k); found {
+				embedded = true
+			}
+		}
+		bb = append(bb, embedded)
+	}
+	return bb
+}
+
+func TestEmbedStandardFonts(t *testing.T) {
+	msg := "TestEmbedStandardFonts"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	if err := api.InstallFonts([]string{filepath.Join(inDir, "fonts", "Roboto-Regular.ttf")}); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		embed bool
+	}{
+		{"referenced", false},
+		{"embedded", true},
+	} {
+		outFile := filepath.Join(outDir, "stampStandardFont"+tt.name+".pdf")
+		conf := pdf.NewDefaultConfiguration()
+		conf.EmbedStandardFonts = tt.embed
+		conf.StandardFontPrograms = map[string]string{"Helvetica": "Roboto-Regular"}
+		if err := api.AddTextWatermarksFile(inFile, outFile, nil, true, "Demo", "font:Helvetica", conf); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.name, err)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, tt.name, err)
+		}
+		bb := embeddedFontPrograms(t, outFile, "Helvetica")
+		if len(bb) != 1 || bb[0] != tt.embed {
+			t.Fatalf("%s %s: want one Helvetica font embedded=%t, got %v\n", msg, tt.name, tt.embed, bb)
+		}
+	}
+
+	// Embedding requires a font program.
+	conf := pdf.NewDefaultConfiguration()
+	conf.EmbedStandardFonts = true
+	outFile := filepath.Join(outDir, "stampStandardFontMissing.pdf")
+	if err := api.AddTextWatermarksFile(inFile, outFile, nil, true, "Demo", "font:Times-Roman", conf); err == nil {
+		t.Fatalf("%s: expected error for missing font program\n", msg)
+	}
+}
+
+func TestTiledWatermark(t *testing.T) {
+	msg := "TestTiledWatermark"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "tiled.pdf")
+
+	for _, desc := range []string{"tiled:maybe", "tilespacing:-1", "tile:true"} {
+		if _, err := pdf.ParseTextWatermarkDetails("Draft", desc, true); err == nil {
+			t.Fatalf("%s %s: want error\n", msg, desc)
+		}
+	}
+
+	wm, err := pdf.ParseTextWatermarkDetails("CONFIDENTIAL", "sc:.3 rel, rot:30, tiled:true, tilespacing:15", true)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !wm.Tiled || wm.TileSpacing != 15 {
+		t.Fatalf("%s: got tiled %t spacing %.1f\n", msg, wm.Tiled, wm.TileSpacing)
+	}
+
+	if err := api.AddWatermarksFile(inFile, outFile, []string{"1"}, wm, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Tiles get clipped to the crop box.
+	if !regexp.MustCompile(`BDC q [\d.]+ [\d.]+ [\d.]+ [\d.]+ re W n`).Match(bb) {
+		t.Fatalf("%s: missing clip\n", msg)
+	}
+	if n := bytes.Count(bb, []byte(" Do Q")); n < 4 {
+		t.Fatalf("%s: want tiles, got %d\n", msg, n)
+	}
+
+	// Tiled watermarks are removed like any other watermark.
+	if err := api.RemoveWatermarksFile(outFile, "", nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if hasWatermarks(outFile, t) {
+		t.Fatalf("%s: watermarks not removed\n", msg)
+	}
+
+	// Tiles without extent or too many of them.
+	for _, sc := range []float64{0, .001} {
+		wm, err := pdf.ParseImageWatermarkDetails(filepath.Join(resDir, "logoSmall.png"), "sc:1 abs, tiled:on", true)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		wm.Scale = sc
+		if err := api.AddWatermarksFile(inFile, outFile, []string{"1"}, wm, nil); err == nil {
+			t.Fatalf("%s scale %.3f: want error\n", msg, sc)
+		}
+	}
+}
+
+func TestWatermarkPlaceholders(t *testing.T) {
+	msg := "TestWatermarkPlaceholders"
+	inFile := filepath.Join(inDir, "Walden.pdf")
+	outFile := filepath.Join(outDir, "placeholders.pdf")
+
+	wm, err := pdf.ParseTextWatermarkDetails("Page %p of %P, %f, %d, 100%% %x", "sc:1 abs, pos:bc, rot:0", true)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.AddWatermarksFile(inFile, outFile, nil, wm, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	pageCount, err := api.PageCountFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	pp, err := api.ExtractTextFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	date := time.Now().Format("2006-01-02")
+	for _, p := range []int{1, 2, pageCount} {
+		var sb strings.Builder
+		for _, span := range pp[p-1].Spans {
+			sb.WriteString(span.Text)
+		}
+		want := fmt.Sprintf("Page %d of %d, Walden.pdf, %s, 100%% %%x", p, pageCount, date)
+		if !strings.Contains(sb.String(), want) {
+			t.Fatalf("%s: page %d: missing %q in %q\n", msg, p, want, sb.String())
+		}
+	}
+}
+
+func TestBatesStamp(t *testing.T) {
+	msg := "TestBatesStamp"
+	dir := filepath.Join(outDir, "bates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	inFiles := []string{filepath.Join(inDir, "Walden.pdf"), filepath.Join(inDir, "networkProgr.pdf")}
+
+	if err := api.BatesStampFile(inFiles, dir, "ABC", 123, 6, "pos:br, points:9, fillcolor:#FF0000", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	nr := 123
+	for _, inFile := range inFiles {
+		outFile := filepath.Join(dir, filepath.Base(inFile))
+		pp, err := api.ExtractTextFile(outFile, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, p := range pp {
+			want := fmt.Sprintf("ABC%06d", nr)
+			found := false
+			for _, span := range p.Spans {
+				if span.Text == want && span.Size == 9 {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("%s: %s page %d: missing %s\n", msg, outFile, p.Page, want)
+			}
+			nr++
+		}
+	}
+
+	// Duplicate file names would overwrite stamped copies.
+	if err := api.BatesStampFile([]string{inFiles[0], inFiles[0]}, dir, "ABC", 1, 6, "br", nil); err == nil {
+		t.Fatalf("%s: want error for duplicate file names\n", msg)
+	}
+}
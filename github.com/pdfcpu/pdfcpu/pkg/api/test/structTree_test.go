@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// structElements returns all structure elements of ee in depth first order.
+func structElements(ee []*pdfcpu.StructElement) []*pdfcpu.StructElement {
+	all := []*pdfcpu.StructElement{}
+	for _, e := range ee {
+		all = append(all, e)
+		all = append(all, structElements(e.Kids)...)
+	}
+	return all
+}
+
+func TestExportStructureTree(t *testing.T) {
+	msg := "TestExportStructureTree"
+	inFile := filepath.Join(inDir, "Hybrid-PDF.pdf")
+
+	ee, err := api.ExportStructureTreeFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if len(ee) != 1 || ee[0].Type != "Document" {
+		t.Fatalf("%s: want single Document element, got: %v\n", msg, ee)
+	}
+
+	figures := 0
+	for i, e := range structElements(ee) {
+		if e.Index != i+1 {
+			t.Fatalf("%s: want index %d, got: %d\n", msg, i+1, e.Index)
+		}
+		if e.Type == "Figure" {
+			figures++
+			if e.Page == 0 || len(e.MCIDs) == 0 {
+				t.Fatalf("%s: figure without content: %s\n", msg, e)
+			}
+		}
+	}
+
+	if figures != 2 {
+		t.Fatalf("%s: want 2 figures, got: %d\n", msg, figures)
+	}
+
+	// Untagged documents have no structure tree.
+	inFile = filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	if _, err = api.ExportStructureTreeFile(inFile, nil); err == nil {
+		t.Fatalf("%s: missing error for untagged document\n", msg)
+	}
+}
+
+func TestSetFigureAltText(t *testing.T) {
+	msg := "TestSetFigureAltText"
+	inFile := filepath.Join(inDir, "Hybrid-PDF.pdf")
+	outFile := filepath.Join(outDir, "HybridAltText.pdf")
+
+	ee, err := api.ExportStructureTreeFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	altTexts := map[int]string{}
+	for _, e := range structElements(ee) {
+		if e.Type == "Figure" {
+			altTexts[e.Index] = fmt.Sprintf("Figure (%d) - Übersicht", e.Index)
+		}
+	}
+
+	if err := api.SetFigureAltTextFile(inFile, outFile, altTexts, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ee, err = api.ExportStructureTreeFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, e := range structElements(ee) {
+		if want, ok := altTexts[e.Index]; ok && e.Alt != want {
+			t.Fatalf("%s: #%d: want alt %q, got: %q\n", msg, e.Index, want, e.Alt)
+		}
+	}
+
+	// Untagged documents need to be tagged first.
+	inFile = filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile = filepath.Join(outDir, "DCTAltText.pdf")
+	if err := api.SetFigureAltTextFile(inFile, outFile, map[int]string{1: "x"}, nil); err == nil {
+		t.Fatalf("%s: missing error for untagged document\n", msg)
+	}
+}
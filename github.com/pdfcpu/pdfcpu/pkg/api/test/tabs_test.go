@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The pdf Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func listTabOrder(t *testing.T, msg, fileName, want string) {
+	t.Helper()
+
+	list, err := api.ListTabOrderFile(fileName, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(list) != 1 || list[0] != want {
+		t.Fatalf("%s: want %q got %v\n", msg, want, list)
+	}
+}
+
+// annotObjNrs returns the object numbers of the annotations on page 1 of fileName in Annots order.
+func annotObjNrs(t *testing.T, msg, fileName string) []int {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		t.Fatalf("%s: missing annotations: %v\n", msg, err)
+	}
+
+	objNrs := []int{}
+	for _, o := range annots {
+		ir, ok := o.(pdf.IndirectRef)
+		if !ok {
+			t.Fatalf("%s: direct annotation\n", msg)
+		}
+		objNrs = append(objNrs, ir.ObjectNumber.Value())
+	}
+
+	return objNrs
+}
+
+func TestTabOrder(t *testing.T) {
+	msg := "TestTabOrder"
+
+	xRefTable, err := pdf.CreateAcroFormDemoXRef()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	inFile := filepath.Join(outDir, "AcroFormTabs.pdf")
+	if err := api.CreatePDFFile(xRefTable, inFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	listTabOrder(t, msg, inFile, "page 1: none")
+
+	if err := api.SetTabOrderFile(inFile, "", "X", nil, nil, nil); err == nil {
+		t.Fatalf("%s: want error for unknown tab order\n", msg)
+	}
+
+	orig := annotObjNrs(t, msg, inFile)
+
+	// Row, column and structure order leave the annotations untouched.
+	for _, order := range []string{"R", "C", "S"} {
+		if err := api.SetTabOrderFile(inFile, "", order, nil, []string{"1"}, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if got := annotObjNrs(t, msg, inFile); !reflect.DeepEqual(got, orig) {
+			t.Fatalf("%s %s: annotations reordered: %v\n", msg, order, got)
+		}
+	}
+	listTabOrder(t, msg, inFile, "page 1: S (structure)")
+
+	ff, err := api.ListFormFieldsFile(inFile, nil)
+	if err != nil || len(ff) < 2 {
+		t.Fatalf("%s: missing form fields: %v\n", msg, err)
+	}
+	last, first := ff[len(ff)-1].Name, ff[0].Name
+
+	if err := api.SetTabOrderFile(inFile, "", "R", []string{last}, nil, nil); err == nil {
+		t.Fatalf("%s: want error for field order with row order\n", msg)
+	}
+	if err := api.SetTabOrderFile(inFile, "", "A", []string{"unknown"}, nil, nil); err == nil {
+		t.Fatalf("%s: want error for unknown field\n", msg)
+	}
+
+	// Annotation order puts the widgets of the given fields first.
+	if err := api.SetTabOrderFile(inFile, "", "A", []string{last, first}, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	listTabOrder(t, msg, inFile, "page 1: A (annotations)")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) != len(orig) {
+		t.Fatalf("%s: want %d annotations, got: %v %v\n", msg, len(orig), annots, err)
+	}
+	for i, want := range []string{last, first} {
+		ad, err := ctx.DereferenceDict(annots[i])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if o, ok := ad.Find("Parent"); ok {
+			if ad, err = ctx.DereferenceDict(o); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+		}
+		if got, _ := ctx.DereferenceText(ad["T"]); got != want {
+			t.Fatalf("%s: annotation %d: want field %s, got %s\n", msg, i, want, got)
+		}
+	}
+}
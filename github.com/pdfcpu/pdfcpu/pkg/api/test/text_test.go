@@ -0,0 +1,214 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// writeTextWithLinksTestFile writes go.pdf to outFile with two lines of text partially covered by links on the first page.
+func writeTextWithLinksTestFile(t *testing.T, msg, outFile string) {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "go.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	page1, page2 := firstTwoPages(t, msg, ctx)
+
+	sd, err := ctx.NewStreamDictForBuf([]byte(
+		"BT /F1 12 Tf 20 500 Td (Visit ) Tj (pdfcpu.io) Tj ( for more.) Tj 0 -20 Td [(See ) -250 (chapter)] TJ ET"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	uriLink := pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    pdf.NewIntegerArray(45, 495, 95, 515),
+		"A": pdf.Dict{
+			"S":   pdf.Name("URI"),
+			"URI": pdf.StringLiteral("https://pdfcpu.io"),
+		},
+	}
+	goToLink := pdf.Dict{
+		"Type":    pdf.Name("Annot"),
+		"Subtype": pdf.Name("Link"),
+		"Rect":    pdf.NewIntegerArray(90, 475, 45, 495),
+		"Dest":    pdf.Array{page2, pdf.Name("Fit")},
+	}
+
+	d, err := ctx.DereferenceDict(page1)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("Contents", *ir)
+	d.Update("Resources", pdf.Dict{
+		"Font": pdf.Dict{
+			"F1": pdf.Dict{
+				"Type":     pdf.Name("Font"),
+				"Subtype":  pdf.Name("Type1"),
+				"BaseFont": pdf.Name("Helvetica"),
+			},
+		},
+	})
+	d.Update("Annots", pdf.Array{uriLink, goToLink})
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestExtractTextWithLinks(t *testing.T) {
+	msg := "TestExtractTextWithLinks"
+	outFile := filepath.Join(outDir, "textWithLinks.pdf")
+
+	writeTextWithLinksTestFile(t, msg, outFile)
+
+	got, err := api.ExtractTextWithLinksFile(outFile, []string{"1"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := []pdf.TextRun{
+		{Page: 1, Text: "Visit "},
+		{Page: 1, Text: "pdfcpu.io", URI: "https://pdfcpu.io"},
+		{Page: 1, Text: " for more.\nSee "},
+		{Page: 1, Text: "chapter", DestPage: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, got)
+	}
+
+	// Extract the text of all pages of a real world file.
+	rr, err := api.ExtractTextWithLinksFile(filepath.Join(inDir, "go.pdf"), nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(rr) == 0 {
+		t.Fatalf("%s: missing text\n", msg)
+	}
+}
+
+func TestExtractTextPerPage(t *testing.T) {
+	msg := "TestExtractTextPerPage"
+	inFile := filepath.Join(outDir, "textPerPage.pdf")
+
+	writeTextWithLinksTestFile(t, msg, inFile)
+
+	// Clear page 2.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	_, page2 := firstTwoPages(t, msg, ctx)
+	d, err := ctx.DereferenceDict(page2)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Delete("Contents")
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	dir, err := ioutil.TempDir(outDir, "textPerPage")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := api.ExtractTextPerPageFile(inFile, dir, []string{"1-3"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ff, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	got := []string{}
+	for _, f := range ff {
+		got = append(got, f.Name())
+	}
+	want := []string{"page_0001.txt", "page_0002.txt", "page_0003.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, got)
+	}
+
+	for i, s := range []string{"Visit pdfcpu.io for more.\nSee chapter\n", ""} {
+		bb, err := ioutil.ReadFile(filepath.Join(dir, want[i]))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if string(bb) != s {
+			t.Fatalf("%s: page %d: want %q, got: %q\n", msg, i+1, s, bb)
+		}
+	}
+
+	bb, err := ioutil.ReadFile(filepath.Join(dir, want[2]))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(bb) == 0 {
+		t.Fatalf("%s: page 3: missing text\n", msg)
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	msg := "TestExtractText"
+	outFile := filepath.Join(outDir, "textPositions.pdf")
+
+	writeTextWithLinksTestFile(t, msg, outFile)
+
+	pp, err := api.ExtractTextFile(outFile, []string{"1"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(pp) != 1 || pp[0].Page != 1 {
+		t.Fatalf("%s: want page 1, got: %v\n", msg, pp)
+	}
+
+	got := []string{}
+	for _, ts := range pp[0].Spans {
+		if ts.Font != "Helvetica" || ts.Size != 12 {
+			t.Fatalf("%s: unexpected font: %s\n", msg, ts)
+		}
+		got = append(got, ts.Text, ts.Rect.String())
+	}
+
+	// Glyph boxes extend from a quarter below to three quarters above the baseline.
+	want := []string{
+		"Visit ", "(20.00, 497.00, 46.00, 509.00) w=26.00 h=12.00 ar=2.17",
+		"pdfcpu.io", "(46.00, 497.00, 94.70, 509.00) w=48.70 h=12.00 ar=4.06",
+		" for more.", "(94.70, 497.00, 146.05, 509.00) w=51.35 h=12.00 ar=4.28",
+		"See chapter", "(20.00, 477.00, 87.70, 489.00) w=67.70 h=12.00 ar=5.64",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s: want %q, got: %q\n", msg, want, got)
+	}
+}
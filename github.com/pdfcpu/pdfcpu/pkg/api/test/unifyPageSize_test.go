@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The pdf Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// createMixedPageSizes writes a file with an A4 page, a Letter page and a rotated Letter page.
+func createMixedPageSizes(t *testing.T, msg, outFile string) []*pdfcpu.Rectangle {
+	t.Helper()
+
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	if err := api.TrimFile(inFile, outFile, []string{"1-3"}, nil); err != nil {
+		t.Fatalf("%s: trim: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rr := []*pdfcpu.Rectangle{
+		pdfcpu.Rect(0, 0, 595, 842),
+		pdfcpu.Rect(10, 20, 622, 812),
+		pdfcpu.Rect(0, 0, 612, 792),
+	}
+
+	for i, r := range rr {
+		d, _, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d.Update("MediaBox", r.Array())
+		d.Delete("CropBox")
+		if i == 2 {
+			d.Update("Rotate", pdfcpu.Integer(90))
+		}
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	return rr
+}
+
+func mediaBoxes(t *testing.T, msg, fileName string) []*pdfcpu.Rectangle {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rr := []*pdfcpu.Rectangle{}
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a, err := ctx.DereferenceArray(d["MediaBox"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		f := make([]float64, 4)
+		for j := range f {
+			if f[j], err = ctx.DereferenceNumber(a[j]); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+		}
+		rr = append(rr, pdfcpu.Rect(f[0], f[1], f[2], f[3]))
+	}
+
+	return rr
+}
+
+func TestUnifyPageSize(t *testing.T) {
+	msg := "TestUnifyPageSize"
+	inFile := filepath.Join(outDir, "mixedPageSizes.pdf")
+	outFile := filepath.Join(outDir, "uniformPageSize.pdf")
+
+	rr := createMixedPageSizes(t, msg, inFile)
+
+	// Center each page within a media box of the largest page dimensions as displayed.
+	if err := api.UnifyPageSizeFile(inFile, outFile, "max", "c", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for i, mb := range mediaBoxes(t, msg, outFile) {
+		w, h := mb.Width(), mb.Height()
+		if i == 2 {
+			// Rotated page.
+			w, h = h, w
+		}
+		if w != 792 || h != 842 {
+			t.Fatalf("%s: page %d: want 792x842 got %.2fx%.2f\n", msg, i+1, w, h)
+		}
+		if mb.Center() != rr[i].Center() {
+			t.Fatalf("%s: page %d: not centered: %s\n", msg, i+1, mb)
+		}
+	}
+
+	// Align the pages to the bottom left of an A3 media box.
+	if err := api.UnifyPageSizeFile(inFile, outFile, "A3", "bl", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for i, mb := range mediaBoxes(t, msg, outFile) {
+		// The rotated page displays its lower right corner at the bottom left.
+		want := pdfcpu.Point{X: rr[i].LL.X, Y: rr[i].LL.Y}
+		got := pdfcpu.Point{X: mb.LL.X, Y: mb.LL.Y}
+		if i == 2 {
+			want.X, got.X = rr[i].UR.X, mb.UR.X
+		}
+		if got != want {
+			t.Fatalf("%s: page %d: want %v got %v\n", msg, i+1, want, got)
+		}
+	}
+
+	// A target size smaller than a page is an error.
+	if err := api.UnifyPageSizeFile(inFile, outFile, "A5", "c", nil); err == nil {
+		t.Fatalf("%s: want error for A5\n", msg)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestValidatePages(t *testing.T) {
+	msg := "TestValidatePages"
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "CenterOfWhy.pdf"))
+	if err != nil {
+		t.Fatalf("%s readContext: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil || ctx.PageCount < 4 {
+		t.Fatalf("%s: need at least 4 pages: %v\n", msg, err)
+	}
+
+	// Corrupt the content of page 3.
+	d, _, err := ctx.PageDict(3, false)
+	if err != nil {
+		t.Fatalf("%s pageDict: %v\n", msg, err)
+	}
+	d.Update("Contents", pdfcpu.Integer(7))
+
+	inFile := filepath.Join(outDir, "corruptPage3.pdf")
+	if err := api.WriteContextFile(ctx, inFile); err != nil {
+		t.Fatalf("%s writeContext: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(inFile, nil); err == nil {
+		t.Fatalf("%s: corrupt page passes validation\n", msg)
+	}
+
+	for _, tt := range []struct {
+		selectedPages []string
+		ok            bool
+	}{
+		{[]string{"1-2", "4-"}, true},
+		{[]string{"even"}, true},
+		{[]string{"odd"}, false},
+		{[]string{"3"}, false},
+	} {
+		err := api.ValidatePagesFile(inFile, tt.selectedPages, nil)
+		if tt.ok && err != nil {
+			t.Fatalf("%s %v: %v\n", msg, tt.selectedPages, err)
+		}
+		if !tt.ok && (err == nil || !strings.Contains(err.Error(), "page 3")) {
+			t.Fatalf("%s %v: want error for page 3, got: %v\n", msg, tt.selectedPages, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// ExtractTextWithLinks returns the text of the selected pages of rs as runs of consecutive text
+// along with the target of any link annotation covering them.
+func ExtractTextWithLinks(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.TextRun, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTTEXTWITHLINKS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ExtractTextWithLinks(pages)
+}
+
+// ExtractTextWithLinksFile returns the text of the selected pages of inFile as runs of consecutive text
+// along with the target of any link annotation covering them.
+func ExtractTextWithLinksFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.TextRun, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ExtractTextWithLinks(f, selectedPages, conf)
+}
+
+// ExtractText returns the text of the selected pages of rs as spans in content stream order
+// along with font, font size and bounding box in user space.
+func ExtractText(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.PageText, error) {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTTEXT
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.ExtractPositionedText(pages)
+}
+
+// ExtractTextFile returns the text of the selected pages of inFile as spans in content stream order
+// along with font, font size and bounding box in user space.
+func ExtractTextFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) ([]pdfcpu.PageText, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ExtractText(f, selectedPages, conf)
+}
+
+// ExtractTextPerPage writes the text of each selected page of rs to a separate file page_0001.txt, page_0002.txt.. in outDir.
+// Pages without text result in empty files in order to keep the page numbering aligned.
+func ExtractTextPerPage(rs io.ReadSeeker, outDir string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	if conf == nil {
+		conf = pdfcpu.NewDefaultConfiguration()
+	}
+	conf.Cmd = pdfcpu.EXTRACTTEXT
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, err := readAndValidate(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if !pages[i] {
+			continue
+		}
+		s, err := ctx.ExtractPageText(i)
+		if err != nil {
+			return err
+		}
+		outFile := filepath.Join(outDir, fmt.Sprintf("page_%04d.txt", i))
+		log.CLI.Printf("writing %s\n", outFile)
+		if err := ioutil.WriteFile(outFile, []byte(s), 0644); err != nil {
+			return err
+		}
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	pdfcpu.TimingStats("write text", durRead, durVal, 0, durWrite, durTotal)
+
+	return nil
+}
+
+// ExtractTextPerPageFile writes the text of each selected page of inFile to a separate file page_0001.txt, page_0002.txt.. in outDir.
+func ExtractTextPerPageFile(inFile, outDir string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("extracting text from %s into %s/ ...\n", inFile, outDir)
+	return ExtractTextPerPage(f, outDir, selectedPages, conf)
+}
@@ -27,7 +27,19 @@ import (
 )
 
 // Validate validates a PDF stream read from rs.
+// In ValidationCollect mode any failing violations are returned as pdfcpu.ValidationErrors along with all warnings.
 func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
+	return validateSelectedPages(rs, nil, conf)
+}
+
+// ValidatePages validates a PDF stream read from rs.
+// The document catalog and the cross reference table get validated in full,
+// content streams and resources only for selected pages.
+func ValidatePages(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) error {
+	return validateSelectedPages(rs, selectedPages, conf)
+}
+
+func validateSelectedPages(rs io.ReadSeeker, selectedPages []string, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
@@ -44,10 +56,20 @@ func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
 		return err
 	}
 
+	if selectedPages != nil {
+		if err := ctx.EnsurePageCount(); err != nil {
+			return err
+		}
+		if ctx.ValidatePages, err = PagesForPageSelection(ctx.PageCount, selectedPages, true); err != nil {
+			return err
+		}
+	}
+
 	dur1 := time.Since(from1).Seconds()
 	from2 := time.Now()
 
-	if err = ValidateContext(ctx); err != nil {
+	err = ValidateContext(ctx)
+	if _, ok := err.(pdfcpu.ValidationErrors); err != nil && !ok {
 		s := ""
 		if conf.ValidationMode == pdfcpu.ValidationStrict {
 			s = " (try -mode=relaxed)"
@@ -71,6 +93,15 @@ func Validate(rs io.ReadSeeker, conf *pdfcpu.Configuration) error {
 
 // ValidateFile validates inFile.
 func ValidateFile(inFile string, conf *pdfcpu.Configuration) error {
+	return validateSelectedPagesFile(inFile, nil, conf)
+}
+
+// ValidatePagesFile validates inFile checking content streams and resources of selected pages only.
+func ValidatePagesFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) error {
+	return validateSelectedPagesFile(inFile, selectedPages, conf)
+}
+
+func validateSelectedPagesFile(inFile string, selectedPages []string, conf *pdfcpu.Configuration) error {
 	if conf == nil {
 		conf = pdfcpu.NewDefaultConfiguration()
 	}
@@ -88,7 +119,7 @@ func ValidateFile(inFile string, conf *pdfcpu.Configuration) error {
 
 	defer f.Close()
 
-	if err = Validate(f, conf); err != nil {
+	if err = validateSelectedPages(f, selectedPages, conf); err != nil {
 		return err
 	}
 
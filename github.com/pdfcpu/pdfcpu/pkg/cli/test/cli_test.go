@@ -109,7 +109,7 @@ func TestGetPageCount(t *testing.T) {
 	msg := "TestGetPageCount"
 	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
 
-	n, err := api.PageCountFile(inFile)
+	n, err := api.PageCountFile(inFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, inFile, err)
 	}
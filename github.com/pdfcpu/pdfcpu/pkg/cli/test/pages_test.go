@@ -29,7 +29,7 @@ func TestPagesCommand(t *testing.T) {
 	inFile := filepath.Join(inDir, "Acroforms2.pdf")
 	outFile := filepath.Join(outDir, "test.pdf")
 
-	n1, err := api.PageCountFile(inFile)
+	n1, err := api.PageCountFile(inFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, inFile, err)
 	}
@@ -43,7 +43,7 @@ func TestPagesCommand(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 
-	n2, err := api.PageCountFile(outFile)
+	n2, err := api.PageCountFile(outFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, inFile, err)
 	}
@@ -60,7 +60,7 @@ func TestPagesCommand(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 
-	n2, err = api.PageCountFile(outFile)
+	n2, err = api.PageCountFile(outFile, nil)
 	if err != nil {
 		t.Fatalf("%s %s: %v\n", msg, inFile, err)
 	}
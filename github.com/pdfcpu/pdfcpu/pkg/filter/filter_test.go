@@ -17,6 +17,7 @@ limitations under the License.
 package filter_test
 
 import (
+	"bytes"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -179,3 +180,36 @@ func TestWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestLZWRoundTrip(t *testing.T) {
+	want, err := ioutil.ReadFile("testdata/Mark.Twain-Tom.Sawyer.txt")
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	for _, ec := range []int{0, 1} {
+		f, err := filter.NewFilter(filter.LZW, map[string]int{"EarlyChange": ec})
+		if err != nil {
+			t.Fatalf("Problem: %v\n", err)
+		}
+
+		enc, err := f.Encode(bytes.NewReader(want))
+		if err != nil {
+			t.Fatalf("Problem encoding (EarlyChange=%d): %v\n", ec, err)
+		}
+
+		dec, err := f.Decode(enc)
+		if err != nil {
+			t.Fatalf("Problem decoding (EarlyChange=%d): %v\n", ec, err)
+		}
+
+		got, err := ioutil.ReadAll(dec)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Fatalf("EarlyChange=%d: round trip mismatch: len %d != %d\n", ec, len(got), len(want))
+		}
+	}
+}
@@ -42,10 +42,15 @@ func (f lzwDecode) Encode(r io.Reader) (io.Reader, error) {
 	}
 
 	wc := lzw.NewWriter(&b, ec == 1)
-	defer wc.Close()
 
 	written, err := io.Copy(wc, r)
 	if err != nil {
+		wc.Close()
+		return nil, err
+	}
+
+	// Close flushes any pending code and writes the EOD marker.
+	if err = wc.Close(); err != nil {
 		return nil, err
 	}
 	log.Trace.Printf("EncodeLZW end: %d bytes written\n", written)
@@ -788,5 +788,12 @@ func InstallTrueTypeFont(fontDir, fontName string) error {
 		return errors.Errorf("%s can't be installed", fontName)
 	}
 
+	// Make the font available right away.
+	fdl := TTFLight{}
+	if err := load(gobName, &fdl); err != nil {
+		return err
+	}
+	UserFontMetrics[fn] = fdl
+
 	return nil
 }
@@ -0,0 +1,438 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DangerousActionTypes are the action types removed by StripActions, see 12.6.4
+var DangerousActionTypes = []string{"Launch", "ImportData", "SubmitForm", "Sound", "Movie"}
+
+// Action represents an action triggered by opening the document or by a document, page, annotation or field event.
+type Action struct {
+	Location string // Where the action is attached eg. OpenAction, AA/WC, page 1 AA/O, page 2 annot obj#12 A, field name AA/K
+	Type     string // Action type eg. GoTo, URI, Launch
+	ObjNr    int    // Object number of the action dict or 0 for direct action dicts.
+}
+
+func (a Action) String() string {
+	s := a.Location + ": " + a.Type
+	if a.ObjNr > 0 {
+		s += fmt.Sprintf(" (obj#%d)", a.ObjNr)
+	}
+	return s
+}
+
+type actionWalker struct {
+	xRefTable *XRefTable
+	strip     bool
+	actions   []Action
+	cuts      int    // references to dangerous actions removed
+	removed   int    // dangerous actions removed
+	stripped  IntSet // dangerous action dicts removed
+	walking   IntSet // action dicts being processed, guards against Next cycles
+	annots    IntSet // annotation dicts processed
+}
+
+// nextActions returns the actions to be performed after the action d, see 12.6.2
+func (w *actionWalker) nextActions(d Dict) ([]Object, error) {
+
+	o, found := d.Find("Next")
+	if !found {
+		return nil, nil
+	}
+
+	o1, err := w.xRefTable.Dereference(o)
+	if err != nil || o1 == nil {
+		return nil, err
+	}
+
+	if a, ok := o1.(Array); ok {
+		return a, nil
+	}
+
+	return []Object{o}, nil
+}
+
+func setNextActions(d Dict, next []Object) {
+	switch len(next) {
+	case 0:
+		d.Delete("Next")
+	case 1:
+		d.Update("Next", next[0])
+	default:
+		d.Update("Next", Array(next))
+	}
+}
+
+// processAction records the action o found at loc along with its successors
+// and returns the actions replacing o.
+func (w *actionWalker) processAction(loc string, o Object) ([]Object, error) {
+
+	var objNr int
+	if ir, ok := o.(IndirectRef); ok {
+		objNr = ir.ObjectNumber.Value()
+		if w.walking[objNr] {
+			return []Object{o}, nil
+		}
+		w.walking[objNr] = true
+		defer delete(w.walking, objNr)
+	}
+
+	d, err := w.xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		return []Object{o}, err
+	}
+
+	var t string
+	if s := d.NameEntry("S"); s != nil {
+		t = *s
+	}
+
+	w.actions = append(w.actions, Action{Location: loc, Type: t, ObjNr: objNr})
+
+	nn, err := w.nextActions(d)
+	if err != nil {
+		return nil, err
+	}
+
+	cuts := w.cuts
+
+	var next []Object
+	for _, o := range nn {
+		kept, err := w.processAction(loc+" Next", o)
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, kept...)
+	}
+
+	if !w.strip {
+		return []Object{o}, nil
+	}
+
+	if MemberOf(t, DangerousActionTypes) {
+		w.cuts++
+		if objNr == 0 || !w.stripped[objNr] {
+			w.removed++
+		}
+		if objNr > 0 {
+			w.stripped[objNr] = true
+		}
+		return next, nil
+	}
+
+	if w.cuts > cuts {
+		setNextActions(d, next)
+	}
+
+	return []Object{o}, nil
+}
+
+// processActionEntry processes the action of d[key].
+func (w *actionWalker) processActionEntry(loc string, d Dict, key string) error {
+
+	o, found := d.Find(key)
+	if !found {
+		return nil
+	}
+
+	cuts := w.cuts
+
+	kept, err := w.processAction(loc, o)
+	if err != nil || w.cuts == cuts {
+		return err
+	}
+
+	if len(kept) == 0 {
+		d.Delete(key)
+		return nil
+	}
+
+	d.Update(key, kept[0])
+
+	if len(kept) == 1 {
+		return nil
+	}
+
+	// Chain the remaining actions to the new first one.
+	head, err := w.xRefTable.DereferenceDict(kept[0])
+	if err != nil || head == nil {
+		return err
+	}
+
+	next, err := w.nextActions(head)
+	if err != nil {
+		return err
+	}
+
+	setNextActions(head, append(next, kept[1:]...))
+
+	return nil
+}
+
+// processAdditionalActions processes the additional-actions dict of d, see 12.6.3
+func (w *actionWalker) processAdditionalActions(loc string, d Dict) error {
+
+	o, found := d.Find("AA")
+	if !found {
+		return nil
+	}
+
+	aa, err := w.xRefTable.DereferenceDict(o)
+	if err != nil || aa == nil {
+		return err
+	}
+
+	keys := []string{}
+	for k := range aa {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := w.processActionEntry(loc+"AA/"+k, aa, k); err != nil {
+			return err
+		}
+	}
+
+	if w.strip && len(aa) == 0 {
+		d.Delete("AA")
+	}
+
+	return nil
+}
+
+func (w *actionWalker) processCatalog() error {
+
+	rootDict, err := w.xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+
+	if o, found := rootDict.Find("OpenAction"); found {
+		o, err := w.xRefTable.Dereference(o)
+		if err != nil {
+			return err
+		}
+		// OpenAction may also be a destination.
+		if _, ok := o.(Dict); ok {
+			if err := w.processActionEntry("OpenAction", rootDict, "OpenAction"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return w.processAdditionalActions("", rootDict)
+}
+
+func (w *actionWalker) processPage(pageNr int) error {
+
+	d, _, err := w.xRefTable.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return err
+	}
+
+	loc := fmt.Sprintf("page %d ", pageNr)
+
+	if err := w.processAdditionalActions(loc, d); err != nil {
+		return err
+	}
+
+	o, found := d.Find("Annots")
+	if !found {
+		return nil
+	}
+
+	annots, err := w.xRefTable.DereferenceArray(o)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range annots {
+
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		objNr := ir.ObjectNumber.Value()
+		if w.annots[objNr] {
+			continue
+		}
+		w.annots[objNr] = true
+
+		ad, err := w.xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+		if ad == nil {
+			continue
+		}
+
+		annotLoc := fmt.Sprintf("%sannot obj#%d ", loc, objNr)
+
+		if err := w.processActionEntry(annotLoc+"A", ad, "A"); err != nil {
+			return err
+		}
+
+		if err := w.processAdditionalActions(annotLoc, ad); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processField processes the additional actions of the field o and its descendants.
+// Fields merged with their widget annotation have been taken care of as annotations.
+func (w *actionWalker) processField(o Object, parentName string, visited IntSet) error {
+
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		return nil
+	}
+	objNr := ir.ObjectNumber.Value()
+	if visited[objNr] {
+		return nil
+	}
+	visited[objNr] = true
+
+	d, err := w.xRefTable.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return err
+	}
+
+	name := parentName
+	if o, found := d.Find("T"); found {
+		s, err := w.xRefTable.DereferenceText(o)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			name += "."
+		}
+		name += s
+	}
+
+	if !w.annots[objNr] {
+		if err := w.processAdditionalActions("field "+name+" ", d); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+		if err := w.processField(o, name, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *actionWalker) processFields() error {
+
+	d, err := w.xRefTable.acroForm()
+	if err != nil || d == nil {
+		return err
+	}
+
+	o, found := d.Find("Fields")
+	if !found {
+		return nil
+	}
+
+	a, err := w.xRefTable.DereferenceArray(o)
+	if err != nil {
+		return err
+	}
+
+	visited := IntSet{}
+
+	for _, o := range a {
+		if err := w.processField(o, "", visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ctx *Context) walkActions(strip bool) (*actionWalker, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	w := &actionWalker{xRefTable: ctx.XRefTable, strip: strip, stripped: IntSet{}, walking: IntSet{}, annots: IntSet{}}
+
+	if err := w.processCatalog(); err != nil {
+		return nil, err
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if err := w.processPage(i); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.processFields(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Actions returns all actions attached to the catalog, pages, annotations and form fields including any successor actions.
+func (ctx *Context) Actions() ([]Action, error) {
+
+	w, err := ctx.walkActions(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.actions, nil
+}
+
+// ListActions returns a list of all actions attached to the catalog, pages, annotations and form fields.
+func ListActions(ctx *Context) ([]string, error) {
+
+	aa, err := ctx.Actions()
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{}
+	for _, a := range aa {
+		ss = append(ss, a.String())
+	}
+
+	return ss, nil
+}
+
+// StripActions removes all actions of DangerousActionTypes keeping any successor actions
+// and returns the number of actions removed.
+func (ctx *Context) StripActions() (int, error) {
+
+	w, err := ctx.walkActions(true)
+	if err != nil {
+		return 0, err
+	}
+
+	return w.removed, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+func (xRefTable *XRefTable) collectPageIndRefs(ir IndirectRef, irs *[]IndirectRef, visited IntSet) error {
+
+	objNr := ir.ObjectNumber.Value()
+	if visited[objNr] {
+		return errors.Errorf("pdfcpu: collectPageIndRefs: page tree cycle at obj#%d", objNr)
+	}
+	visited[objNr] = true
+
+	d, err := xRefTable.DereferenceDict(ir)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return nil
+	}
+
+	kids := d.ArrayEntry("Kids")
+	if kids == nil {
+		*irs = append(*irs, ir)
+		return nil
+	}
+
+	for _, o := range kids {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return errors.New("pdfcpu: collectPageIndRefs: corrupt page node dict")
+		}
+		if err := xRefTable.collectPageIndRefs(ir, irs, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pageIndRefs returns the indirect references of all page dicts in page order.
+func (xRefTable *XRefTable) pageIndRefs() ([]IndirectRef, error) {
+
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, errors.New("pdfcpu: pageIndRefs: missing page tree")
+	}
+
+	irs := []IndirectRef{}
+
+	if err := xRefTable.collectPageIndRefs(*root, &irs, IntSet{}); err != nil {
+		return nil, err
+	}
+
+	return irs, nil
+}
+
+// FixAnnotationPageRefs sets the page reference P of any annotation pointing to a page
+// other than the one holding this annotation in its Annots array, see 12.5.2 Table 164
+// An annotation listed by more than one page stays with the first of these pages.
+// Returns the number of annotations fixed.
+func FixAnnotationPageRefs(ctx *Context) (int, error) {
+
+	irs, err := ctx.pageIndRefs()
+	if err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	done := IntSet{}
+
+	for i, pageIndRef := range irs {
+
+		d, err := ctx.DereferenceDict(pageIndRef)
+		if err != nil {
+			return 0, err
+		}
+
+		o, found := d.Find("Annots")
+		if !found {
+			continue
+		}
+
+		a, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, o := range a {
+
+			if ir, ok := o.(IndirectRef); ok {
+				objNr := ir.ObjectNumber.Value()
+				if done[objNr] {
+					continue
+				}
+				done[objNr] = true
+			}
+
+			annotDict, err := ctx.DereferenceDict(o)
+			if err != nil {
+				return 0, err
+			}
+			if annotDict == nil {
+				continue
+			}
+
+			p := annotDict.IndirectRefEntry("P")
+			if p == nil || p.ObjectNumber == pageIndRef.ObjectNumber {
+				continue
+			}
+
+			log.Debug.Printf("FixAnnotationPageRefs: page %d: obj#%d => obj#%d\n", i+1, p.ObjectNumber, pageIndRef.ObjectNumber)
+			annotDict.Update("P", pageIndRef)
+			fixed++
+		}
+	}
+
+	return fixed, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+// transformedRect returns the bounding box of r mapped by m.
+func transformedRect(r *Rectangle, m matrix) *Rectangle {
+	var tr *Rectangle
+	for _, p := range [][2]float64{{r.LL.X, r.LL.Y}, {r.UR.X, r.LL.Y}, {r.LL.X, r.UR.Y}, {r.UR.X, r.UR.Y}} {
+		q := m.transform(p[0], p[1])
+		tr = unionRect(tr, Rect(q.X, q.Y, q.X, q.Y))
+	}
+	return tr
+}
+
+// transformedCoordinates returns the coordinate pairs of the array o mapped by m.
+func (xRefTable *XRefTable) transformedCoordinates(o Object, m matrix) (Array, error) {
+	a, err := xRefTable.DereferenceArray(o)
+	if err != nil || a == nil {
+		return nil, err
+	}
+
+	ff := make([]float64, len(a)-len(a)%2)
+	for i := range ff {
+		if ff[i], err = xRefTable.DereferenceNumber(a[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 0; i < len(ff); i += 2 {
+		p := m.transform(ff[i], ff[i+1])
+		ff[i], ff[i+1] = p.X, p.Y
+	}
+
+	return NewNumberArray(ff...), nil
+}
+
+// transformAppearanceStream applies the linear part of m to the form matrix of the appearance stream o.
+// Translation is taken care of by the annotation rectangle, see 12.5.5
+func (xRefTable *XRefTable) transformAppearanceStream(o Object, m matrix, visited IntSet) error {
+	if ir, ok := o.(IndirectRef); ok {
+		// Appearance streams may be shared.
+		if visited[ir.ObjectNumber.Value()] {
+			return nil
+		}
+		visited[ir.ObjectNumber.Value()] = true
+	}
+
+	sd, err := xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return err
+	}
+
+	fm := identMatrix
+	if a, err := xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		ff := make([]float64, 6)
+		for i, o := range a {
+			if ff[i], err = xRefTable.DereferenceNumber(o); err != nil {
+				return err
+			}
+		}
+		fm = matrixForNumbers(ff)
+	}
+
+	lm := m
+	lm[2][0], lm[2][1] = 0, 0
+	fm = fm.multiply(lm)
+
+	sd.Update("Matrix", NewNumberArray(fm[0][0], fm[0][1], fm[1][0], fm[1][1], fm[2][0], fm[2][1]))
+
+	return nil
+}
+
+// transformAppearances applies m to the appearance streams of the annotation dict d.
+func (xRefTable *XRefTable) transformAppearances(d Dict, m matrix, visited IntSet) error {
+	ap, err := xRefTable.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		return err
+	}
+
+	for _, k := range []string{"N", "R", "D"} {
+		o, found := ap.Find(k)
+		if !found {
+			continue
+		}
+		o1, err := xRefTable.Dereference(o)
+		if err != nil {
+			return err
+		}
+		// An appearance is either a stream or a dict of streams by appearance state.
+		if states, ok := o1.(Dict); ok {
+			for _, o := range states {
+				if err := xRefTable.transformAppearanceStream(o, m, visited); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := xRefTable.transformAppearanceStream(o, m, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformAnnotation applies the coordinate transform m to the annotation dict d
+// so that the annotation stays aligned with page content transformed by m.
+// The annotation rectangle, any coordinates of markup annotations and the appearance streams get transformed.
+func (xRefTable *XRefTable) transformAnnotation(d Dict, m matrix, visited IntSet) error {
+	a, err := xRefTable.DereferenceArray(d["Rect"])
+	if err != nil {
+		return err
+	}
+	if len(a) == 4 {
+		r, err := rect(xRefTable, a)
+		if err != nil {
+			return err
+		}
+		d.Update("Rect", transformedRect(r, m).Array())
+	}
+
+	// Coordinate pairs of text markup, line, polygon, polyline and free text callout annotations.
+	for _, k := range []string{"QuadPoints", "L", "Vertices", "CL"} {
+		o, found := d.Find(k)
+		if !found {
+			continue
+		}
+		a, err := xRefTable.transformedCoordinates(o, m)
+		if err != nil {
+			return err
+		}
+		if a != nil {
+			d.Update(k, a)
+		}
+	}
+
+	if o, found := d.Find("InkList"); found {
+		paths, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		inkList := Array{}
+		for _, o := range paths {
+			a, err := xRefTable.transformedCoordinates(o, m)
+			if err != nil {
+				return err
+			}
+			if a != nil {
+				inkList = append(inkList, a)
+			}
+		}
+		d.Update("InkList", inkList)
+	}
+
+	return xRefTable.transformAppearances(d, m, visited)
+}
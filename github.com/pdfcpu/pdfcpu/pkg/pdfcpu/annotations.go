@@ -0,0 +1,548 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// AnnotationInfo describes an annotation of a page.
+type AnnotationInfo struct {
+	Subtype  string       // eg. Text, Link, Highlight, Widget, FileAttachment
+	Page     int          // page number
+	Rect     *Rectangle   // normalized annotation rectangle in user space
+	Contents string       // text to be displayed or an alternate description
+	Title    string       // author of a markup annotation or the partial field name of a widget
+	IndRef   *IndirectRef // annotation dict or nil for direct annotations
+	URI      string       // target of a link's URI action
+	Dest     string       // named destination of a link
+	DestPage int          // target page of a link's destination or 0
+}
+
+func (ai AnnotationInfo) String() string {
+	s := fmt.Sprintf("page %d %s (%.2f, %.2f, %.2f, %.2f)", ai.Page, ai.Subtype, ai.Rect.LL.X, ai.Rect.LL.Y, ai.Rect.UR.X, ai.Rect.UR.Y)
+	if ai.IndRef != nil {
+		s += " obj#" + ai.IndRef.ObjectNumber.String()
+	}
+	if ai.Title != "" {
+		s += fmt.Sprintf(" title:%q", ai.Title)
+	}
+	if ai.Contents != "" {
+		s += fmt.Sprintf(" contents:%q", ai.Contents)
+	}
+	if ai.URI != "" {
+		s += " uri:" + ai.URI
+	}
+	if ai.Dest != "" {
+		s += " dest:" + ai.Dest
+	}
+	if ai.DestPage > 0 {
+		s += fmt.Sprintf(" destPage:%d", ai.DestPage)
+	}
+	return s
+}
+
+func (xRefTable *XRefTable) annotationInfo(o Object, pageNr int) (*AnnotationInfo, error) {
+
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	ai := &AnnotationInfo{Page: pageNr}
+
+	if ir, ok := o.(IndirectRef); ok {
+		ai.IndRef = &ir
+	}
+
+	if st := d.Subtype(); st != nil {
+		ai.Subtype = *st
+	}
+
+	if ai.Rect, err = xRefTable.annotRect(d); err != nil {
+		return nil, err
+	}
+
+	for k, s := range map[string]*string{"Contents": &ai.Contents, "T": &ai.Title} {
+		if o, found := d.Find(k); found {
+			if *s, err = xRefTable.DereferenceText(o); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if ai.Subtype == "Link" {
+		var la LinkArea
+		if err := xRefTable.resolveLinkTarget(&la, d); err != nil {
+			return nil, err
+		}
+		ai.URI, ai.Dest, ai.DestPage = la.URI, la.Dest, la.Page
+	}
+
+	return ai, nil
+}
+
+// ListAnnotations returns the annotations of the selected pages in page order.
+// All pages are inspected if selectedPages is empty.
+func (ctx *Context) ListAnnotations(selectedPages IntSet) ([]AnnotationInfo, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	var pageNrs []int
+	for i := 1; i <= ctx.PageCount; i++ {
+		if len(selectedPages) == 0 || selectedPages[i] {
+			pageNrs = append(pageNrs, i)
+		}
+	}
+
+	aa := []AnnotationInfo{}
+
+	for _, pageNr := range pageNrs {
+
+		d, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			return nil, err
+		}
+
+		o, found := d.Find("Annots")
+		if !found {
+			continue
+		}
+
+		annots, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range annots {
+			ai, err := ctx.annotationInfo(o, pageNr)
+			if err != nil {
+				return nil, err
+			}
+			if ai != nil {
+				aa = append(aa, *ai)
+			}
+		}
+	}
+
+	return aa, nil
+}
+
+// removedAnnotations returns the indices of the annotations of annots to be removed.
+// Popups get removed along with their parent annotation.
+func (xRefTable *XRefTable) removedAnnotations(annots Array, types []string) (map[int]bool, error) {
+
+	removed := map[int]bool{}
+	popups := IntSet{}
+
+	for i, o := range annots {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		st := d.Subtype()
+		if len(types) > 0 && (st == nil || !MemberOf(*st, types)) {
+			continue
+		}
+		removed[i] = true
+		if ir, ok := d["Popup"].(IndirectRef); ok {
+			popups[ir.ObjectNumber.Value()] = true
+		}
+	}
+
+	for i, o := range annots {
+		if ir, ok := o.(IndirectRef); ok && popups[ir.ObjectNumber.Value()] {
+			removed[i] = true
+		}
+	}
+
+	return removed, nil
+}
+
+// removeWidget removes the widget d referenced by ir from the field tree of acroForm.
+func (xRefTable *XRefTable) removeWidget(acroForm Dict, d Dict, ir IndirectRef) error {
+
+	if err := xRefTable.removeField(acroForm, d, ir); err != nil {
+		return err
+	}
+
+	if o, found := acroForm.Find("CO"); found {
+		a, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		if a = removeArrayRef(a, ir.ObjectNumber.Value()); len(a) > 0 {
+			acroForm.Update("CO", a)
+		} else {
+			acroForm.Delete("CO")
+		}
+	}
+
+	return nil
+}
+
+// appearanceObjNrs adds the object numbers of the appearance dict of annotation d and its streams to objNrs.
+func (xRefTable *XRefTable) appearanceObjNrs(d Dict, objNrs IntSet) error {
+
+	if ir, ok := d["AP"].(IndirectRef); ok {
+		objNrs[ir.ObjectNumber.Value()] = true
+	}
+
+	ap, err := xRefTable.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		return err
+	}
+
+	for _, k := range []string{"N", "R", "D"} {
+		o, found := ap.Find(k)
+		if !found {
+			continue
+		}
+		if ir, ok := o.(IndirectRef); ok {
+			objNrs[ir.ObjectNumber.Value()] = true
+		}
+		o, err := xRefTable.Dereference(o)
+		if err != nil {
+			return err
+		}
+		// A subdictionary maps appearance states to streams.
+		if states, ok := o.(Dict); ok {
+			for _, o := range states {
+				if ir, ok := o.(IndirectRef); ok {
+					objNrs[ir.ObjectNumber.Value()] = true
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// removePageAnnotations removes the selected annotations of page pageNr
+// and adds the object numbers of the removed annotations and their appearances to removedObjNrs.
+func (ctx *Context) removePageAnnotations(pageNr int, types []string, acroForm Dict, removedObjNrs IntSet) (int, error) {
+
+	d, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return 0, err
+	}
+
+	o, found := d.Find("Annots")
+	if !found {
+		return 0, nil
+	}
+
+	annots, err := ctx.DereferenceArray(o)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := ctx.removedAnnotations(annots, types)
+	if err != nil || len(removed) == 0 {
+		return 0, err
+	}
+
+	a := Array{}
+
+	for i, o := range annots {
+
+		if !removed[i] {
+			a = append(a, o)
+			continue
+		}
+
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		removedObjNrs[ir.ObjectNumber.Value()] = true
+
+		ad, err := ctx.DereferenceDict(ir)
+		if err != nil {
+			return 0, err
+		}
+		if ad == nil {
+			continue
+		}
+		if err := ctx.appearanceObjNrs(ad, removedObjNrs); err != nil {
+			return 0, err
+		}
+		if acroForm == nil || ad.Subtype() == nil || *ad.Subtype() != "Widget" {
+			continue
+		}
+		if err := ctx.removeWidget(acroForm, ad, ir); err != nil {
+			return 0, err
+		}
+	}
+
+	// Remaining annotations lose their removed popups.
+	for _, o := range a {
+		ad, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return 0, err
+		}
+		if ir, ok := ad["Popup"].(IndirectRef); ok && removedObjNrs[ir.ObjectNumber.Value()] {
+			ad.Delete("Popup")
+		}
+	}
+
+	if len(a) == 0 {
+		d.Delete("Annots")
+	} else {
+		d.Update("Annots", a)
+	}
+
+	return len(annots) - len(a), nil
+}
+
+// RemoveAnnotations removes the annotations of the selected pages whose subtype is one of types
+// and returns the number of annotations removed.
+// All annotations get removed if types is empty and all pages are processed if selectedPages is empty.
+// Popups get removed along with their parent annotation and widgets along with their form fields.
+// The removed annotation dicts and their appearance streams get freed unless still referenced elsewhere.
+func (ctx *Context) RemoveAnnotations(selectedPages IntSet, types []string) (int, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return 0, err
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return 0, err
+	}
+
+	acroForm, err := ctx.DereferenceDict(rootDict["AcroForm"])
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	removedObjNrs := IntSet{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if len(selectedPages) > 0 && !selectedPages[i] {
+			continue
+		}
+		n, err := ctx.removePageAnnotations(i, types, acroForm, removedObjNrs)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	if acroForm != nil {
+		a, err := ctx.DereferenceArray(acroForm["Fields"])
+		if err != nil {
+			return 0, err
+		}
+		if len(a) == 0 {
+			rootDict.Delete("AcroForm")
+		}
+	}
+
+	// Appearance streams may be shared with annotations left in place.
+	reachable := ctx.reachableObjects()
+
+	objNrs := []int{}
+	for objNr := range removedObjNrs {
+		if !reachable[objNr] {
+			objNrs = append(objNrs, objNr)
+		}
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		if err := ctx.DeleteObject(objNr); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// LinkAnnotation describes a link annotation to be added to a page.
+// A link either targets a page of the document, a named destination or a URI.
+type LinkAnnotation struct {
+	Page     int        // page number of the link
+	Rect     *Rectangle // clickable area in user space
+	DestPage int        // target page of an internal link
+	Dest     string     // named destination of an internal link
+	URI      string     // target of an external link
+	Border   bool       // draw a 1 point black border, the border is invisible by default
+}
+
+func (la LinkAnnotation) validate(pageCount int) error {
+
+	if la.Page < 1 || la.Page > pageCount {
+		return errors.Errorf("pdfcpu: link: invalid page number: %d", la.Page)
+	}
+
+	if la.Rect == nil || la.Rect.Width() <= 0 || la.Rect.Height() <= 0 {
+		return errors.Errorf("pdfcpu: link on page %d: missing or empty rect", la.Page)
+	}
+
+	var targets int
+	for _, ok := range []bool{la.DestPage != 0, la.Dest != "", la.URI != ""} {
+		if ok {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return errors.Errorf("pdfcpu: link on page %d: please provide exactly one of target page, destination or URI", la.Page)
+	}
+
+	if la.DestPage < 0 || la.DestPage > pageCount {
+		return errors.Errorf("pdfcpu: link on page %d: invalid target page: %d", la.Page, la.DestPage)
+	}
+
+	return nil
+}
+
+// linkAction returns the action dict for la, see 12.6.4.2 and 12.6.4.7
+func (ctx *Context) linkAction(la LinkAnnotation) (Dict, error) {
+
+	if la.URI != "" {
+		s, err := Escape(la.URI)
+		if err != nil {
+			return nil, err
+		}
+		return Dict(
+			map[string]Object{
+				"S":   Name("URI"),
+				"URI": StringLiteral(*s),
+			},
+		), nil
+	}
+
+	var dest Object
+
+	if la.Dest != "" {
+		if err := ctx.LocateNameTree("Dests", false); err != nil {
+			return nil, err
+		}
+		if ctx.Names["Dests"] == nil {
+			return nil, errors.Errorf("pdfcpu: link on page %d: unknown destination: %s", la.Page, la.Dest)
+		}
+		if _, ok := ctx.Names["Dests"].Value(la.Dest); !ok {
+			return nil, errors.Errorf("pdfcpu: link on page %d: unknown destination: %s", la.Page, la.Dest)
+		}
+		s, err := Escape(la.Dest)
+		if err != nil {
+			return nil, err
+		}
+		dest = StringLiteral(*s)
+	} else {
+		ir, err := ctx.PageDictIndRef(la.DestPage)
+		if err != nil {
+			return nil, err
+		}
+		// Go to the top left corner of the target page keeping the current zoom.
+		dest = Array{*ir, Name("XYZ"), nil, nil, nil}
+	}
+
+	return Dict(
+		map[string]Object{
+			"S": Name("GoTo"),
+			"D": dest,
+		},
+	), nil
+}
+
+func (ctx *Context) linkAnnotation(la LinkAnnotation, pageIndRef IndirectRef) (*IndirectRef, error) {
+
+	action, err := ctx.linkAction(la)
+	if err != nil {
+		return nil, err
+	}
+
+	border := NewIntegerArray(0, 0, 0)
+	if la.Border {
+		border = NewIntegerArray(0, 0, 1)
+	}
+
+	d := Dict(
+		map[string]Object{
+			"Type":    Name("Annot"),
+			"Subtype": Name("Link"),
+			"Rect":    la.Rect.Array(),
+			"P":       pageIndRef,
+			"F":       Integer(4), // Print
+			"Border":  border,
+			"H":       Name("I"),
+			"A":       action,
+		},
+	)
+
+	return ctx.IndRefForNewObject(d)
+}
+
+// AddLinkAnnotations adds the link annotations ll to their pages.
+// Internal links go to a target page or a named destination, external links open a URI.
+// Links may overlap each other.
+func (ctx *Context) AddLinkAnnotations(ll []LinkAnnotation) error {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	for _, la := range ll {
+		if err := la.validate(ctx.PageCount); err != nil {
+			return err
+		}
+	}
+
+	for _, la := range ll {
+
+		pageIndRef, err := ctx.PageDictIndRef(la.Page)
+		if err != nil {
+			return err
+		}
+
+		d, err := ctx.DereferenceDict(*pageIndRef)
+		if err != nil {
+			return err
+		}
+
+		ir, err := ctx.linkAnnotation(la, *pageIndRef)
+		if err != nil {
+			return err
+		}
+
+		annots := Array{}
+		if o, found := d.Find("Annots"); found {
+			if annots, err = ctx.DereferenceArray(o); err != nil {
+				return err
+			}
+		}
+
+		d.Update("Annots", append(annots, *ir))
+	}
+
+	return nil
+}
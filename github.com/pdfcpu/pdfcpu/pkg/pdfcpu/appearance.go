@@ -0,0 +1,950 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// defaultAppearance represents a parsed default appearance string (DA), see 12.7.3.3
+type defaultAppearance struct {
+	fontResName string
+	fontSize    float64
+	ops         string // any graphics state operators except Tf, eg. the text color.
+}
+
+func parseDefaultAppearance(da string) defaultAppearance {
+
+	var d defaultAppearance
+
+	tt := strings.Fields(da)
+	ops := []string{}
+
+	for i := 0; i < len(tt); i++ {
+		if i+2 < len(tt) && tt[i+2] == "Tf" && strings.HasPrefix(tt[i], "/") {
+			d.fontResName = tt[i][1:]
+			d.fontSize, _ = strconv.ParseFloat(tt[i+1], 64)
+			i += 2
+			continue
+		}
+		ops = append(ops, tt[i])
+	}
+
+	d.ops = strings.Join(ops, " ")
+
+	return d
+}
+
+// colorOperator returns the content stream operator setting the color represented by a.
+func colorOperator(xRefTable *XRefTable, a Array, stroke bool) string {
+
+	ff := []string{}
+	for _, o := range a {
+		f, err := xRefTable.DereferenceNumber(o)
+		if err != nil {
+			return ""
+		}
+		ff = append(ff, fmt.Sprintf("%.3f", f))
+	}
+
+	var op string
+	switch len(ff) {
+	case 1:
+		op = "g"
+	case 3:
+		op = "rg"
+	case 4:
+		op = "k"
+	default:
+		return ""
+	}
+
+	if stroke {
+		op = strings.ToUpper(op)
+	}
+
+	return strings.Join(ff, " ") + " " + op
+}
+
+// winAnsiString maps s to single bytes as needed for simple fonts using WinAnsiEncoding.
+func winAnsiString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFF {
+			r = '?'
+		}
+		b.WriteByte(byte(r))
+	}
+	return b.String()
+}
+
+func escapedText(s string) string {
+	s1, err := Escape(s)
+	if err != nil {
+		return ""
+	}
+	return *s1
+}
+
+// appearanceGenerator regenerates appearance streams for fields and annotations.
+type appearanceGenerator struct {
+	xRefTable *XRefTable
+	dr        Dict                    // the interactive form's default resources.
+	fonts     map[string]*IndirectRef // font resources by resource name.
+}
+
+func (ag *appearanceGenerator) fontResources() (Dict, error) {
+
+	o, found := ag.dr.Find("Font")
+	if !found {
+		d := NewDict()
+		ag.dr.Insert("Font", d)
+		return d, nil
+	}
+
+	return ag.xRefTable.DereferenceDict(o)
+}
+
+// font returns the font for resName and the name of the core font used for measuring text.
+// Fonts missing in the default resources get created as Helvetica.
+func (ag *appearanceGenerator) font(resName string) (*IndirectRef, string, error) {
+
+	if resName == "" {
+		resName = "Helv"
+	}
+
+	fd, err := ag.fontResources()
+	if err != nil {
+		return nil, "", err
+	}
+
+	ir, ok := ag.fonts[resName]
+	if !ok {
+		o, found := fd.Find(resName)
+		if found {
+			if ir1, ok := o.(IndirectRef); ok {
+				ir = &ir1
+			}
+		}
+		if ir == nil {
+			if ir, err = createFontDict(ag.xRefTable, "Helvetica"); err != nil {
+				return nil, "", err
+			}
+			fd.Update(resName, *ir)
+		}
+		ag.fonts[resName] = ir
+	}
+
+	fontName := "Helvetica"
+
+	d, err := ag.xRefTable.DereferenceDict(*ir)
+	if err != nil {
+		return nil, "", err
+	}
+	if bf := d.NameEntry("BaseFont"); bf != nil && font.IsCoreFont(*bf) {
+		fontName = *bf
+	}
+
+	return ir, fontName, nil
+}
+
+// zapfDingbats returns a ZapfDingbats font resource as needed for check boxes and radio buttons.
+func (ag *appearanceGenerator) zapfDingbats() (string, *IndirectRef, error) {
+
+	fd, err := ag.fontResources()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for k, o := range fd {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+		d, err := ag.xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return "", nil, err
+		}
+		if bf := d.NameEntry("BaseFont"); bf != nil && *bf == "ZapfDingbats" {
+			return k, &ir, nil
+		}
+	}
+
+	ir, err := createZapfDingbatsFontDict(ag.xRefTable)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fd.Update("ZaDb", *ir)
+
+	return "ZaDb", ir, nil
+}
+
+func (ag *appearanceGenerator) rect(d Dict) (*Rectangle, error) {
+
+	o, found := d.Find("Rect")
+	if !found {
+		return nil, errors.New("pdfcpu: regenerate appearance: missing rect")
+	}
+
+	a, err := ag.xRefTable.DereferenceArray(o)
+	if err != nil || len(a) != 4 {
+		return nil, errors.New("pdfcpu: regenerate appearance: corrupt rect")
+	}
+
+	r, err := rect(ag.xRefTable, a)
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize.
+	return Rect(
+		math.Min(r.LL.X, r.UR.X), math.Min(r.LL.Y, r.UR.Y),
+		math.Max(r.LL.X, r.UR.X), math.Max(r.LL.Y, r.UR.Y)), nil
+}
+
+// borderWidth returns the border width of an annotation, see 12.5.4
+func (ag *appearanceGenerator) borderWidth(d Dict, def float64) float64 {
+
+	if o, found := d.Find("BS"); found {
+		bs, err := ag.xRefTable.DereferenceDict(o)
+		if err == nil && bs != nil {
+			if o, found := bs.Find("W"); found {
+				if w, err := ag.xRefTable.DereferenceNumber(o); err == nil {
+					return w
+				}
+			}
+		}
+	}
+
+	if o, found := d.Find("Border"); found {
+		a, err := ag.xRefTable.DereferenceArray(o)
+		if err == nil && len(a) >= 3 {
+			if w, err := ag.xRefTable.DereferenceNumber(a[2]); err == nil {
+				return w
+			}
+		}
+	}
+
+	return def
+}
+
+func (ag *appearanceGenerator) colorEntry(d Dict, key string, stroke bool) string {
+	o, found := d.Find(key)
+	if !found {
+		return ""
+	}
+	a, err := ag.xRefTable.DereferenceArray(o)
+	if err != nil {
+		return ""
+	}
+	return colorOperator(ag.xRefTable, a, stroke)
+}
+
+// widgetBackground renders background and border of a widget as specified by its appearance characteristics dict.
+func (ag *appearanceGenerator) widgetBackground(buf *bytes.Buffer, wd Dict, w, h float64) (float64, error) {
+
+	var mk Dict
+	if o, found := wd.Find("MK"); found {
+		d, err := ag.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return 0, err
+		}
+		mk = d
+	}
+
+	if mk == nil {
+		return 0, nil
+	}
+
+	if bg := ag.colorEntry(mk, "BG", false); bg != "" {
+		fmt.Fprintf(buf, "%s 0 0 %.2f %.2f re f\n", bg, w, h)
+	}
+
+	bc := ag.colorEntry(mk, "BC", true)
+	if bc == "" {
+		return 0, nil
+	}
+
+	bw := ag.borderWidth(wd, 1)
+	if bw > 0 {
+		fmt.Fprintf(buf, "%s %.2f w %.2f %.2f %.2f %.2f re S\n", bc, bw, bw/2, bw/2, w-bw, h-bw)
+	}
+
+	return bw, nil
+}
+
+func (ag *appearanceGenerator) formXObject(buf []byte, bBox *Rectangle, resDict Dict) (*IndirectRef, error) {
+
+	sd, _ := ag.xRefTable.NewStreamDictForBuf(buf)
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.InsertInt("FormType", 1)
+	sd.Insert("BBox", bBox.Array())
+	sd.Insert("Matrix", NewIntegerArray(1, 0, 0, 1, 0, 0))
+	if resDict != nil {
+		sd.Insert("Resources", resDict)
+	}
+
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	return ag.xRefTable.IndRefForNewObject(*sd)
+}
+
+func fontResourceDict(resName string, ir IndirectRef) Dict {
+	return Dict(
+		map[string]Object{
+			"Font": Dict(
+				map[string]Object{
+					resName: ir,
+				},
+			),
+		},
+	)
+}
+
+// fieldText returns the text to be displayed for a text or choice field.
+func (ag *appearanceGenerator) fieldText(f *formField) ([]string, error) {
+
+	if f.V == nil {
+		return nil, nil
+	}
+
+	switch v := f.V.(type) {
+
+	case StringLiteral, HexLiteral:
+		s, err := Text(v)
+		if err != nil {
+			return nil, err
+		}
+		if f.Ff&FieldPassword > 0 {
+			s = strings.Repeat("*", len([]rune(s)))
+		}
+		if f.FT == "Tx" && f.Ff&FieldMultiline > 0 {
+			s = strings.Replace(s, "\r\n", "\n", -1)
+			return strings.FieldsFunc(s, func(c rune) bool { return c == '\n' || c == '\r' }), nil
+		}
+		return []string{s}, nil
+
+	case Array:
+		// Multiple selection list box.
+		ss := []string{}
+		for _, o := range v {
+			s, err := ag.xRefTable.DereferenceText(o)
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, s)
+		}
+		return ss, nil
+
+	case Name:
+		return []string{v.Value()}, nil
+	}
+
+	return nil, nil
+}
+
+func (ag *appearanceGenerator) textFieldAppearance(f *formField, wd Dict, lines []string) error {
+
+	r, err := ag.rect(wd)
+	if err != nil {
+		return err
+	}
+	w, h := r.Width(), r.Height()
+
+	da := f.DA
+	if o, found := wd.Find("DA"); found {
+		if da, err = ag.xRefTable.DereferenceText(o); err != nil {
+			return err
+		}
+	}
+	dap := parseDefaultAppearance(da)
+
+	fontIndRef, fontName, err := ag.font(dap.fontResName)
+	if err != nil {
+		return err
+	}
+	resName := dap.fontResName
+	if resName == "" {
+		resName = "Helv"
+	}
+
+	q := f.Q
+	if i := wd.IntEntry("Q"); i != nil {
+		q = *i
+	}
+
+	buf := new(bytes.Buffer)
+
+	bw, err := ag.widgetBackground(buf, wd, w, h)
+	if err != nil {
+		return err
+	}
+
+	pad := bw + 2
+	availW := w - 2*pad
+
+	for i, s := range lines {
+		lines[i] = winAnsiString(s)
+	}
+
+	fontSize := dap.fontSize
+	if fontSize <= 0 {
+		// Auto size.
+		fontSize = math.Min(12, (h-2*pad)/(font.LineHeight(fontName, 1000)/1000))
+		if len(lines) == 1 && availW > 0 {
+			if s := float64(font.Size(lines[0], fontName, availW)); s > 0 && s < fontSize {
+				fontSize = s
+			}
+		}
+		if fontSize < 1 {
+			fontSize = 1
+		}
+	}
+	fs := int(math.Round(fontSize))
+	if fs < 1 {
+		fs = 1
+	}
+
+	fmt.Fprintf(buf, "/Tx BMC\nq\n%.2f %.2f %.2f %.2f re W n\nBT\n", bw, bw, w-2*bw, h-2*bw)
+	if dap.ops != "" {
+		fmt.Fprintf(buf, "%s\n", dap.ops)
+	}
+	fmt.Fprintf(buf, "/%s %d Tf\n", resName, fs)
+
+	maxLen := 0
+	if i := f.Dict.IntEntry("MaxLen"); i != nil {
+		maxLen = *i
+	}
+
+	lineHeight := font.LineHeight(fontName, fs)
+
+	for i, s := range lines {
+
+		var y float64
+		if len(lines) == 1 && f.Ff&FieldMultiline == 0 {
+			// Vertically centered.
+			y = (h-(font.Ascent(fontName, fs)+font.Descent(fontName, fs)))/2 + font.Descent(fontName, fs)
+		} else {
+			y = h - pad - font.Ascent(fontName, fs) - float64(i)*lineHeight
+		}
+
+		if f.Ff&FieldComb > 0 && maxLen > 0 {
+			cellW := w / float64(maxLen)
+			for j := 0; j < len(s) && j < maxLen; j++ {
+				c := s[j : j+1]
+				x := float64(j)*cellW + (cellW-font.TextWidth(c, fontName, fs))/2
+				fmt.Fprintf(buf, "1 0 0 1 %.2f %.2f Tm (%s) Tj\n", x, y, escapedText(c))
+			}
+			continue
+		}
+
+		tw := font.TextWidth(s, fontName, fs)
+		x := pad
+		switch q {
+		case 1:
+			x = (w - tw) / 2
+		case 2:
+			x = w - pad - tw
+		}
+
+		fmt.Fprintf(buf, "1 0 0 1 %.2f %.2f Tm (%s) Tj\n", x, y, escapedText(s))
+	}
+
+	buf.WriteString("ET\nQ\nEMC\n")
+
+	ir, err := ag.formXObject(buf.Bytes(), RectForDim(w, h), fontResourceDict(resName, *fontIndRef))
+	if err != nil {
+		return err
+	}
+
+	wd.Update("AP", Dict(map[string]Object{"N": *ir}))
+
+	return nil
+}
+
+func (ag *appearanceGenerator) buttonFieldAppearance(f *formField, wd Dict) error {
+
+	r, err := ag.rect(wd)
+	if err != nil {
+		return err
+	}
+	w, h := r.Width(), r.Height()
+
+	on, err := ag.xRefTable.widgetOnState(wd)
+	if err != nil {
+		return err
+	}
+
+	var v string
+	if n, ok := f.V.(Name); ok {
+		v = n.Value()
+	}
+
+	da := f.DA
+	if o, found := wd.Find("DA"); found {
+		if da, err = ag.xRefTable.DereferenceText(o); err != nil {
+			return err
+		}
+	}
+	dap := parseDefaultAppearance(da)
+
+	resName, fontIndRef, err := ag.zapfDingbats()
+	if err != nil {
+		return err
+	}
+
+	// Default check mark and bullet, see 12.7.3.3 Table 189 entry CA.
+	c := "4"
+	if f.Ff&FieldRadio > 0 {
+		c = "l"
+	}
+
+	if o, found := wd.Find("MK"); found {
+		mk, err := ag.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if mk != nil {
+			if o, found := mk.Find("CA"); found {
+				if s, err := ag.xRefTable.DereferenceText(o); err == nil && s != "" {
+					c = s
+				}
+			}
+		}
+	}
+
+	fontSize := dap.fontSize
+	if fontSize <= 0 {
+		fontSize = math.Min(w, h) * 0.8
+	}
+	fs := int(math.Round(fontSize))
+	if fs < 1 {
+		fs = 1
+	}
+
+	offBuf := new(bytes.Buffer)
+	if _, err := ag.widgetBackground(offBuf, wd, w, h); err != nil {
+		return err
+	}
+
+	onBuf := new(bytes.Buffer)
+	onBuf.Write(offBuf.Bytes())
+
+	x := (w - font.TextWidth(c, "ZapfDingbats", fs)) / 2
+	y := (h - 0.7*float64(fs)) / 2
+	onBuf.WriteString("q\nBT\n")
+	if dap.ops != "" {
+		fmt.Fprintf(onBuf, "%s\n", dap.ops)
+	}
+	fmt.Fprintf(onBuf, "/%s %d Tf\n%.2f %.2f Td\n(%s) Tj\nET\nQ\n", resName, fs, x, y, escapedText(c))
+
+	resDict := fontResourceDict(resName, *fontIndRef)
+
+	irOn, err := ag.formXObject(onBuf.Bytes(), RectForDim(w, h), resDict)
+	if err != nil {
+		return err
+	}
+
+	irOff, err := ag.formXObject(offBuf.Bytes(), RectForDim(w, h), nil)
+	if err != nil {
+		return err
+	}
+
+	wd.Update("AP", Dict(
+		map[string]Object{
+			"N": Dict(
+				map[string]Object{
+					on:    *irOn,
+					"Off": *irOff,
+				},
+			),
+		},
+	))
+
+	as := "Off"
+	if v == on {
+		as = on
+	}
+	wd.Update("AS", Name(as))
+
+	return nil
+}
+
+func (ag *appearanceGenerator) fieldAppearances(f *formField) error {
+
+	switch f.FT {
+
+	case "Tx", "Ch":
+		lines, err := ag.fieldText(f)
+		if err != nil {
+			return err
+		}
+		for _, wd := range f.Widgets {
+			if err := ag.textFieldAppearance(f, wd, append([]string(nil), lines...)); err != nil {
+				return err
+			}
+		}
+
+	case "Btn":
+		if f.Ff&FieldPushbutton > 0 {
+			// Push button appearances can't be derived from a value.
+			return nil
+		}
+		for _, wd := range f.Widgets {
+			if err := ag.buttonFieldAppearance(f, wd); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// quadPoints returns the quadrilaterals of a text markup annotation as [x1 y1 x2 y2 x3 y3 x4 y4] tuples, see 12.5.6.10
+func (ag *appearanceGenerator) quadPoints(d Dict) ([][8]float64, error) {
+
+	o, found := d.Find("QuadPoints")
+	if !found {
+		return nil, nil
+	}
+
+	a, err := ag.xRefTable.DereferenceArray(o)
+	if err != nil {
+		return nil, err
+	}
+
+	qq := [][8]float64{}
+
+	for i := 0; i+8 <= len(a); i += 8 {
+		var q [8]float64
+		for j := 0; j < 8; j++ {
+			if q[j], err = ag.xRefTable.DereferenceNumber(a[i+j]); err != nil {
+				return nil, err
+			}
+		}
+		qq = append(qq, q)
+	}
+
+	return qq, nil
+}
+
+func (ag *appearanceGenerator) opacity(d Dict) float64 {
+	if o, found := d.Find("CA"); found {
+		if f, err := ag.xRefTable.DereferenceNumber(o); err == nil {
+			return f
+		}
+	}
+	return 1
+}
+
+func (ag *appearanceGenerator) textMarkupAppearance(d Dict, subtype string) error {
+
+	r, err := ag.rect(d)
+	if err != nil {
+		return err
+	}
+
+	qq, err := ag.quadPoints(d)
+	if err != nil {
+		return err
+	}
+
+	col := ag.colorEntry(d, "C", subtype != "Highlight")
+	if col == "" {
+		col = "1 1 0 rg"
+		if subtype != "Highlight" {
+			col = "0 0 0 RG"
+		}
+	}
+
+	ca := ag.opacity(d)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "/GS0 gs\n%s\n", col)
+
+	for _, q := range qq {
+
+		// Acrobat orders the points: upper left, upper right, lower left, lower right.
+		ulx, uly, urx, ury, llx, lly, lrx, lry := q[0], q[1], q[2], q[3], q[4], q[5], q[6], q[7]
+		qh := math.Hypot(ulx-llx, uly-lly)
+
+		switch subtype {
+
+		case "Highlight":
+			fmt.Fprintf(buf, "%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l h f\n", ulx, uly, urx, ury, lrx, lry, llx, lly)
+
+		case "Underline":
+			lw := math.Max(qh/14, 0.5)
+			dx, dy := (ulx-llx)/qh*lw, (uly-lly)/qh*lw
+			fmt.Fprintf(buf, "%.2f w %.2f %.2f m %.2f %.2f l S\n", lw, llx+dx, lly+dy, lrx+dx, lry+dy)
+
+		case "StrikeOut":
+			lw := math.Max(qh/14, 0.5)
+			fmt.Fprintf(buf, "%.2f w %.2f %.2f m %.2f %.2f l S\n", lw, (ulx+llx)/2, (uly+lly)/2, (urx+lrx)/2, (ury+lry)/2)
+
+		case "Squiggly":
+			lw := math.Max(qh/20, 0.5)
+			amp := qh / 12
+			step := qh / 6
+			l := math.Hypot(lrx-llx, lry-lly)
+			if l == 0 {
+				continue
+			}
+			ux, uy := (lrx-llx)/l, (lry-lly)/l
+			nx, ny := -uy, ux
+			fmt.Fprintf(buf, "%.2f w %.2f %.2f m ", lw, llx+nx*amp, lly+ny*amp)
+			for i, s := 1, step; s <= l; i, s = i+1, s+step {
+				a := amp
+				if i%2 == 1 {
+					a = -amp
+				}
+				fmt.Fprintf(buf, "%.2f %.2f l ", llx+ux*s+nx*(amp+a), lly+uy*s+ny*(amp+a))
+			}
+			buf.WriteString("S\n")
+		}
+	}
+
+	resDict := Dict(
+		map[string]Object{
+			"ExtGState": Dict(
+				map[string]Object{
+					"GS0": ag.extGState(ca, subtype == "Highlight"),
+				},
+			),
+		},
+	)
+
+	ir, err := ag.formXObject(buf.Bytes(), r, resDict)
+	if err != nil {
+		return err
+	}
+
+	d.Update("AP", Dict(map[string]Object{"N": *ir}))
+
+	return nil
+}
+
+func (ag *appearanceGenerator) extGState(ca float64, multiply bool) Dict {
+	d := Dict(
+		map[string]Object{
+			"Type": Name("ExtGState"),
+			"CA":   Float(ca),
+			"ca":   Float(ca),
+		},
+	)
+	if multiply {
+		d.Insert("BM", Name("Multiply"))
+	}
+	return d
+}
+
+func (ag *appearanceGenerator) shapeAppearance(d Dict, subtype string) error {
+
+	r, err := ag.rect(d)
+	if err != nil {
+		return err
+	}
+
+	bw := ag.borderWidth(d, 1)
+	stroke := ag.colorEntry(d, "C", true)
+	fill := ag.colorEntry(d, "IC", false)
+
+	op := "n"
+	switch {
+	case stroke != "" && bw > 0 && fill != "":
+		op = "B"
+	case stroke != "" && bw > 0:
+		op = "S"
+	case fill != "":
+		op = "f"
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "/GS0 gs\n%.2f w\n", bw)
+	if stroke != "" {
+		fmt.Fprintf(buf, "%s\n", stroke)
+	}
+	if fill != "" {
+		fmt.Fprintf(buf, "%s\n", fill)
+	}
+
+	x, y := r.LL.X+bw/2, r.LL.Y+bw/2
+	w, h := r.Width()-bw, r.Height()-bw
+
+	if subtype == "Square" {
+		fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f re %s\n", x, y, w, h, op)
+	} else {
+		ellipsePath(buf, RectForWidthAndHeight(x, y, w, h))
+		fmt.Fprintf(buf, "%s\n", op)
+	}
+
+	resDict := Dict(
+		map[string]Object{
+			"ExtGState": Dict(
+				map[string]Object{
+					"GS0": ag.extGState(ag.opacity(d), false),
+				},
+			),
+		},
+	)
+
+	ir, err := ag.formXObject(buf.Bytes(), r, resDict)
+	if err != nil {
+		return err
+	}
+
+	d.Update("AP", Dict(map[string]Object{"N": *ir}))
+
+	return nil
+}
+
+func (ag *appearanceGenerator) annotationAppearances() error {
+
+	for i := 1; i <= ag.xRefTable.PageCount; i++ {
+
+		pageDict, _, err := ag.xRefTable.PageDict(i, false)
+		if err != nil {
+			return err
+		}
+
+		o, found := pageDict.Find("Annots")
+		if !found {
+			continue
+		}
+
+		a, err := ag.xRefTable.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range a {
+
+			d, err := ag.xRefTable.DereferenceDict(o)
+			if err != nil {
+				return err
+			}
+			if d == nil {
+				continue
+			}
+
+			st := d.Subtype()
+			if st == nil {
+				continue
+			}
+
+			switch *st {
+
+			case "Highlight", "Underline", "StrikeOut", "Squiggly":
+				err = ag.textMarkupAppearance(d, *st)
+
+			case "Square", "Circle":
+				err = ag.shapeAppearance(d, *st)
+
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// newAppearanceGenerator returns an appearance generator using the default resources of acroForm
+// which get created if missing.
+func (xRefTable *XRefTable) newAppearanceGenerator(acroForm Dict) (*appearanceGenerator, error) {
+
+	ag := &appearanceGenerator{xRefTable: xRefTable, fonts: map[string]*IndirectRef{}}
+
+	if acroForm == nil {
+		return ag, nil
+	}
+
+	o, found := acroForm.Find("DR")
+	if found {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		ag.dr = d
+	}
+	if ag.dr == nil {
+		ag.dr = NewDict()
+		acroForm.Update("DR", ag.dr)
+	}
+
+	return ag, nil
+}
+
+// RegenerateAppearances regenerates the normal appearance streams for form fields
+// based on their values and default appearance strings
+// and for text markup, square and circle annotations based on their properties.
+func (ctx *Context) RegenerateAppearances() error {
+
+	log.Info.Println("RegenerateAppearances begin")
+
+	xRefTable := ctx.XRefTable
+
+	acroForm, err := xRefTable.acroForm()
+	if err != nil {
+		return err
+	}
+
+	ag, err := xRefTable.newAppearanceGenerator(acroForm)
+	if err != nil {
+		return err
+	}
+
+	if acroForm != nil {
+
+		fields, err := xRefTable.formFields()
+		if err != nil {
+			return err
+		}
+
+		for _, f := range fields {
+			if err := ag.fieldAppearances(f); err != nil {
+				return err
+			}
+		}
+
+		// All widgets carry appearances now.
+		acroForm.Delete("NeedAppearances")
+	}
+
+	if err := ag.annotationAppearances(); err != nil {
+		return err
+	}
+
+	log.Info.Println("RegenerateAppearances end")
+
+	return nil
+}
@@ -18,8 +18,15 @@ package pdfcpu
 
 import (
 	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"mime"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/filter"
@@ -27,10 +34,60 @@ import (
 	"github.com/pkg/errors"
 )
 
-func fileSpecStreamDictInfo(xRefTable *XRefTable, id string, o Object, decode bool) (*StreamDict, string, *time.Time, error) {
+// ErrAttachmentNotFound is returned when an attachment id is not present.
+var ErrAttachmentNotFound = errors.New("pdfcpu: attachment not found")
+
+// ErrAttachmentChecksumMismatch is the cause of errors returned when the data of an attachment does not match its stored checksum.
+var ErrAttachmentChecksumMismatch = errors.New("pdfcpu: attachment checksum mismatch")
+
+// embeddedFileChecksum returns the MD5 checksum of the embedded file sd if available, see 7.11.4 Table 46
+func embeddedFileChecksum(sd *StreamDict) ([]byte, error) {
+	d := sd.DictEntry("Params")
+	if d == nil {
+		return nil, nil
+	}
+	switch o := d["CheckSum"].(type) {
+	case StringLiteral:
+		return Unescape(o.Value())
+	case HexLiteral:
+		return o.Bytes()
+	}
+	return nil, nil
+}
+
+// verifyEmbeddedFile checks the decoded data of the embedded file sd against its stored checksum.
+// Embedded files without checksum pass.
+func verifyEmbeddedFile(id string, sd *StreamDict) error {
+	sum, err := embeddedFileChecksum(sd)
+	if err != nil {
+		return errors.Wrapf(err, "pdfcpu: %s: invalid checksum", id)
+	}
+	if sum == nil {
+		return nil
+	}
+	if got := md5.Sum(sd.Content); !bytes.Equal(got[:], sum) {
+		return errors.Wrapf(ErrAttachmentChecksumMismatch, "%s", id)
+	}
+	return nil
+}
+
+// embeddedFileDate returns the date entry key of the embedded file parameter dict d, see 7.11.4 Table 46
+func embeddedFileDate(d Dict, key string) (*time.Time, error) {
+	s := d.StringEntry(key)
+	if s == nil {
+		return nil, nil
+	}
+	dt, ok := DateTime(*s)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: invalid date %s", key)
+	}
+	return &dt, nil
+}
+
+func fileSpecStreamDictInfo(xRefTable *XRefTable, id string, o Object, decode bool) (*StreamDict, string, *time.Time, *time.Time, error) {
 	d, err := xRefTable.DereferenceDict(o)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, "", nil, nil, err
 	}
 
 	var desc string
@@ -41,75 +98,83 @@ func fileSpecStreamDictInfo(xRefTable *XRefTable, id string, o Object, decode bo
 	// Entry EF is a dict holding a stream dict in entry F.
 	o, found := d.Find("EF")
 	if !found || o == nil {
-		return nil, "", nil, nil
+		return nil, "", nil, nil, nil
 	}
 
 	d, err = xRefTable.DereferenceDict(o)
 	if err != nil || o == nil {
-		return nil, "", nil, err
+		return nil, "", nil, nil, err
 	}
 
 	// Entry F holds the embedded file's data.
 	o, found = d.Find("F")
 	if !found || o == nil {
-		return nil, desc, nil, nil
+		return nil, desc, nil, nil, nil
 	}
 
 	sd, err := xRefTable.DereferenceStreamDict(o)
 	if err != nil || sd == nil {
-		return nil, desc, nil, err
+		return nil, desc, nil, nil, err
 	}
 
 	if d = sd.DictEntry("Params"); d == nil {
-		return sd, desc, nil, nil
+		return sd, desc, nil, nil, nil
 	}
 
-	var modDate *time.Time
-	if s := d.StringEntry("ModDate"); s != nil {
-		dt, ok := DateTime(*s)
-		if !ok {
-			return nil, desc, nil, errors.New("pdfcpu: invalid date ModDate")
-		}
-		modDate = &dt
+	modDate, err := embeddedFileDate(d, "ModDate")
+	if err != nil {
+		return nil, desc, nil, nil, err
+	}
+
+	creationDate, err := embeddedFileDate(d, "CreationDate")
+	if err != nil {
+		return nil, desc, nil, nil, err
 	}
 
 	fpl := sd.FilterPipeline
 
 	if fpl == nil {
-		sd.Content = sd.Raw
-		return sd, desc, modDate, nil
+		if err := sd.Decode(); err != nil {
+			return nil, desc, modDate, creationDate, err
+		}
+		return sd, desc, modDate, creationDate, nil
 	}
 
 	// Ignore filter chains with length > 1
 	if len(fpl) > 1 {
 		log.Debug.Printf("decodedFileSpecStreamDict: ignore %s, more than 1 filter.\n", id)
-		return nil, desc, modDate, nil
+		return nil, desc, modDate, creationDate, nil
 	}
 
 	// Only FlateDecode supported.
 	if fpl[0].Name != filter.Flate {
 		log.Debug.Printf("decodedFileSpecStreamDict: ignore %s, %s filter unsupported.\n", id, fpl[0].Name)
-		return nil, desc, modDate, nil
+		return nil, desc, modDate, creationDate, nil
 	}
 
 	// Decode streamDict for supported filters only.
 	if err := sd.Decode(); err != nil {
-		return nil, desc, modDate, err
+		return nil, desc, modDate, creationDate, err
 	}
 
-	return sd, desc, modDate, nil
+	return sd, desc, modDate, creationDate, nil
 }
 
 // Attachment is a Reader representing a PDF attachment.
 type Attachment struct {
-	io.Reader            // attachment data
-	ID        string     // id
-	Desc      string     // description
-	ModTime   *time.Time // time of last modification (optional)
+	io.Reader               // attachment data
+	ID           string     // id
+	Desc         string     // description
+	ModTime      *time.Time // time of last modification (optional)
+	CreationTime *time.Time // time of creation (optional)
+	Uncompressed bool       // store data as is, eg. for already compressed data like zip or jpeg files (optional)
+	MimeType     string     // MIME type of the embedded file taken from its Subtype or file extension (optional)
+	// Initial document shown in the collection view of a portfolio (optional).
+	CollectionInitial bool
 }
 
 func (a Attachment) String() string {
-	return fmt.Sprintf("Attachment: id:%s desc:%s modTime:%s", a.ID, a.Desc, a.ModTime)
+	return fmt.Sprintf("Attachment: id:%s desc:%s modTime:%s creationTime:%s", a.ID, a.Desc, a.ModTime, a.CreationTime)
 }
 
 // ListAttachments returns a slice of attachment stubs (attachment w/o data).
@@ -128,11 +193,11 @@ func (ctx *Context) ListAttachments() ([]Attachment, error) {
 
 	createAttachmentStub := func(xRefTable *XRefTable, id string, o Object) error {
 		decode := false
-		_, desc, modTime, err := fileSpecStreamDictInfo(xRefTable, id, o, decode)
+		sd, desc, modTime, creationTime, err := fileSpecStreamDictInfo(xRefTable, id, o, decode)
 		if err != nil {
 			return err
 		}
-		aa = append(aa, Attachment{nil, id, desc, modTime})
+		aa = append(aa, Attachment{ID: id, Desc: desc, ModTime: modTime, CreationTime: creationTime, MimeType: attachmentMimeType(sd, id)})
 		return nil
 	}
 
@@ -144,9 +209,121 @@ func (ctx *Context) ListAttachments() ([]Attachment, error) {
 	return aa, nil
 }
 
+// ListAttachmentsByType returns a slice of attachment stubs matching any of mimeTypes.
+// Matching is case insensitive and supports wildcards like "audio/*".
+// An empty mimeTypes returns all attachments.
+func (ctx *Context) ListAttachmentsByType(mimeTypes []string) ([]Attachment, error) {
+	aa, err := ctx.ListAttachments()
+	if err != nil || len(mimeTypes) == 0 {
+		return aa, err
+	}
+
+	aa1 := []Attachment{}
+	for _, a := range aa {
+		if matchMimeType(a.MimeType, mimeTypes) {
+			aa1 = append(aa1, a)
+		}
+	}
+
+	return aa1, nil
+}
+
+// extMimeTypes covers common attachment types missing from the builtin table of package mime.
+var extMimeTypes = map[string]string{
+	".aif":  "audio/aiff",
+	".aiff": "audio/aiff",
+	".csv":  "text/csv",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".mp3":  "audio/mpeg",
+	".mp4":  "video/mp4",
+	".pdf":  "application/pdf",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".txt":  "text/plain",
+	".wav":  "audio/wav",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".zip":  "application/zip",
+}
+
+// attachmentMimeType returns the MIME type of the embedded file stream dict sd recorded in its Subtype, see 7.11.4 Table 44.
+// If missing the MIME type gets derived from the file extension of id.
+func attachmentMimeType(sd *StreamDict, id string) string {
+	if sd != nil {
+		if n := sd.NameEntry("Subtype"); n != nil && *n != "" {
+			return Name(*n).Value()
+		}
+	}
+
+	ext := strings.ToLower(path.Ext(id))
+	if mt, ok := extMimeTypes[ext]; ok {
+		return mt
+	}
+
+	return mime.TypeByExtension(ext)
+}
+
+// matchMimeType returns true if the MIME type mt matches any of patterns.
+func matchMimeType(mt string, patterns []string) bool {
+	if i := strings.Index(mt, ";"); i >= 0 {
+		// Drop parameters like charset.
+		mt = mt[:i]
+	}
+	mt = strings.ToLower(strings.TrimSpace(mt))
+
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "*" {
+			return true
+		}
+		if mt == "" {
+			continue
+		}
+		if ok, err := path.Match(p, mt); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attachmentFileName returns the file name to be recorded for an attachment with id,
+// which by default is the base name of id.
+// If preservePath is set a relative path gets preserved using forward slashes as separators, see 7.11.2
+func attachmentFileName(id string, preservePath bool) string {
+	s := strings.ReplaceAll(filepath.ToSlash(id), `\`, "/")
+
+	if !preservePath || path.IsAbs(s) || filepath.VolumeName(id) != "" {
+		return path.Base(s)
+	}
+
+	// Relative paths must not escape from the attachment's root.
+	s = path.Clean(s)
+	for strings.HasPrefix(s, "../") {
+		s = s[3:]
+	}
+
+	return s
+}
+
 // AddAttachment adds a.
+// The attachment is recorded under the base name of a.ID unless ctx.PreserveAttachmentPaths is set.
+// An a.Reader implementing io.Seeker gets read in chunks again when writing ctx and needs to stay open until then.
 func (ctx *Context) AddAttachment(a Attachment, useCollection bool) error {
+	a.ID = attachmentFileName(a.ID, ctx.PreserveAttachmentPaths)
+
 	xRefTable := ctx.XRefTable
+
+	if a.CollectionInitial {
+		if !useCollection {
+			return errors.Errorf("pdfcpu: AddAttachment: %s: initial document needs a collection", a.ID)
+		}
+		if err := ctx.checkCollectionInitial(a.ID); err != nil {
+			return err
+		}
+	}
+
 	if err := xRefTable.LocateNameTree("EmbeddedFiles", true); err != nil {
 		return err
 	}
@@ -158,12 +335,121 @@ func (ctx *Context) AddAttachment(a Attachment, useCollection bool) error {
 		}
 	}
 
-	ir, err := xRefTable.NewFileSpectDictForAttachment(a)
+	sd, sum, err := attachmentStreamDict(a)
+	if err != nil {
+		return err
+	}
+
+	var ir *IndirectRef
+
+	if ctx.DedupAttachments {
+		if ir, err = ctx.identicalEmbeddedFileSpecDict(a, sum); err != nil {
+			return err
+		}
+	}
+
+	if ir == nil {
+		if ir, err = xRefTable.fileSpecDictForEmbeddedStream(a, sd); err != nil {
+			return err
+		}
+	}
+
+	if err := xRefTable.Names["EmbeddedFiles"].Add(xRefTable, a.ID, *ir); err != nil {
+		return err
+	}
+
+	if !a.CollectionInitial {
+		return nil
+	}
+
+	d, err := xRefTable.collectionDict()
+	if err != nil {
+		return err
+	}
+	d.Update("D", StringLiteral(a.ID))
+
+	return nil
+}
+
+// embeddedFileStreamRef returns the indirect reference of the embedded file stream of the file specification o.
+func embeddedFileStreamRef(xRefTable *XRefTable, o Object) (*IndirectRef, error) {
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		return nil, err
+	}
+	ef, err := xRefTable.DereferenceDict(d["EF"])
+	if err != nil || ef == nil {
+		return nil, err
+	}
+	ir, ok := ef["F"].(IndirectRef)
+	if !ok {
+		return nil, nil
+	}
+	return &ir, nil
+}
+
+// identicalEmbeddedFileSpecDict returns a file specification for a sharing the stream of
+// an already embedded file whose content matches the SHA-256 checksum sum, or nil if there is none.
+func (ctx *Context) identicalEmbeddedFileSpecDict(a Attachment, sum [sha256.Size]byte) (*IndirectRef, error) {
+	xRefTable := ctx.XRefTable
+
+	var sd *IndirectRef
+
+	match := func(xRefTable *XRefTable, id string, o Object) error {
+		if sd != nil {
+			return nil
+		}
+		ir, err := embeddedFileStreamRef(xRefTable, o)
+		if err != nil || ir == nil {
+			return err
+		}
+		sd1, _, _, _, err := fileSpecStreamDictInfo(xRefTable, id, o, true)
+		if err != nil || sd1 == nil {
+			return err
+		}
+		if sha256.Sum256(sd1.Content) == sum {
+			log.Debug.Printf("AddAttachment: %s shares the embedded file stream of %s\n", a.ID, id)
+			sd = ir
+		}
+		return nil
+	}
+
+	if err := xRefTable.Names["EmbeddedFiles"].Process(xRefTable, match); err != nil {
+		return nil, err
+	}
+
+	if sd == nil {
+		return nil, nil
+	}
+
+	d, err := xRefTable.NewFileSpecDict(a.ID, a.Desc, *sd)
 	if err != nil {
+		return nil, err
+	}
+
+	return xRefTable.IndRefForNewObject(d)
+}
+
+// checkCollectionInitial returns an error if an attachment other than id is the initial document of the collection.
+func (ctx *Context) checkCollectionInitial(id string) error {
+	xRefTable := ctx.XRefTable
+
+	_, s, err := xRefTable.collectionInitial()
+	if err != nil || s == "" || s == id {
 		return err
 	}
 
-	return xRefTable.Names["EmbeddedFiles"].Add(xRefTable, a.ID, *ir)
+	if err := xRefTable.LocateNameTree("EmbeddedFiles", false); err != nil {
+		return err
+	}
+	if xRefTable.Names["EmbeddedFiles"] == nil {
+		return nil
+	}
+	if _, found := xRefTable.Names["EmbeddedFiles"].Value(s); found {
+		return errors.Errorf("pdfcpu: AddAttachment: %s: %s already is the initial document", id, s)
+	}
+
+	return nil
 }
 
 // RemoveAttachments removes attachments with given id and returns true if anything removed.
@@ -188,6 +474,9 @@ func (ctx *Context) RemoveAttachments(ids []string) (bool, error) {
 	}
 
 	for _, id := range ids {
+		if err := ctx.detachSharedEmbeddedFile(id); err != nil {
+			return false, err
+		}
 		// EmbeddedFiles name tree containing at least one key value pair.
 		empty, ok, err := xRefTable.Names["EmbeddedFiles"].Remove(xRefTable, id)
 		if err != nil {
@@ -201,6 +490,148 @@ func (ctx *Context) RemoveAttachments(ids []string) (bool, error) {
 			if err := xRefTable.RemoveEmbeddedFilesNameTree(); err != nil {
 				return false, err
 			}
+			continue
+		}
+		d, s, err := xRefTable.collectionInitial()
+		if err != nil {
+			return false, err
+		}
+		if s == id {
+			d.Delete("D")
+		}
+	}
+
+	return true, nil
+}
+
+// detachSharedEmbeddedFile removes the embedded file stream from the file specification of attachment id
+// if another attachment shares the stream, so that removing id keeps the stream intact.
+func (ctx *Context) detachSharedEmbeddedFile(id string) error {
+	xRefTable := ctx.XRefTable
+	root := xRefTable.Names["EmbeddedFiles"]
+
+	o, found := root.Value(id)
+	if !found {
+		return nil
+	}
+
+	ir, err := embeddedFileStreamRef(xRefTable, o)
+	if err != nil || ir == nil {
+		return err
+	}
+
+	var shared bool
+
+	check := func(xRefTable *XRefTable, id1 string, o1 Object) error {
+		if shared || id1 == id {
+			return nil
+		}
+		ir1, err := embeddedFileStreamRef(xRefTable, o1)
+		if err != nil {
+			return err
+		}
+		shared = ir1 != nil && *ir1 == *ir
+		return nil
+	}
+
+	if err := root.Process(xRefTable, check); err != nil || !shared {
+		return err
+	}
+
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil {
+		return err
+	}
+	d.Delete("EF")
+
+	return nil
+}
+
+// RenameAttachments renames the attachments with ids matching the keys of m to the corresponding values
+// and returns true if anything renamed.
+// The file specification entries F and UF are updated along with the keys of the EmbeddedFiles name tree.
+func (ctx *Context) RenameAttachments(m map[string]string) (bool, error) {
+	xRefTable := ctx.XRefTable
+	if !xRefTable.Valid {
+		if err := xRefTable.LocateNameTree("EmbeddedFiles", false); err != nil {
+			return false, err
+		}
+	}
+	if xRefTable.Names["EmbeddedFiles"] == nil {
+		return false, errors.Errorf("pdfcpu: no attachments available.")
+	}
+
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	root := xRefTable.Names["EmbeddedFiles"]
+
+	// Collect the renames of existing attachments.
+	var renamed []string
+	vals := map[string]Object{}
+	for _, id := range ids {
+		if m[id] == id {
+			continue
+		}
+		v, found := root.Value(id)
+		if !found {
+			log.Info.Printf("pdfcpu: renameAttachments: %s not found", id)
+			continue
+		}
+		renamed = append(renamed, id)
+		vals[id] = v
+	}
+
+	if len(renamed) == 0 {
+		return false, nil
+	}
+
+	// New ids must be unique among the resulting ids.
+	// An id vacated by another rename may be reused, which allows for swapping ids.
+	newIDs := map[string]bool{}
+	for _, id := range renamed {
+		newID := m[id]
+		_, found := root.Value(newID)
+		_, vacated := vals[newID]
+		if newIDs[newID] || found && !vacated {
+			return false, errors.Errorf("pdfcpu: renameAttachments: %s already exists", newID)
+		}
+		newIDs[newID] = true
+	}
+
+	cd, initial, err := xRefTable.collectionInitial()
+	if err != nil {
+		return false, err
+	}
+
+	// Keep the file specifications, just move them to their new keys.
+	for _, id := range renamed {
+		if _, _, err := root.Remove(nil, id); err != nil {
+			return false, err
+		}
+	}
+
+	for _, id := range renamed {
+		newID, v := m[id], vals[id]
+
+		d, err := xRefTable.DereferenceDict(v)
+		if err != nil {
+			return false, err
+		}
+		if d != nil {
+			d.Update("F", StringLiteral(newID))
+			d.Update("UF", StringLiteral(newID))
+		}
+
+		if err := root.Add(xRefTable, newID, v); err != nil {
+			return false, err
+		}
+
+		if initial == id {
+			cd.Update("D", StringLiteral(newID))
 		}
 	}
 
@@ -209,6 +640,16 @@ func (ctx *Context) RemoveAttachments(ids []string) (bool, error) {
 
 // ExtractAttachments extracts attachments with id.
 func (ctx *Context) ExtractAttachments(ids []string) ([]Attachment, error) {
+	return ctx.extractAttachments(ids, false)
+}
+
+// ExtractAttachmentsVerified extracts attachments with id and verifies their data against stored checksums.
+// The cause of the error returned for corrupted attachments is ErrAttachmentChecksumMismatch.
+func (ctx *Context) ExtractAttachmentsVerified(ids []string) ([]Attachment, error) {
+	return ctx.extractAttachments(ids, true)
+}
+
+func (ctx *Context) extractAttachments(ids []string, verify bool) ([]Attachment, error) {
 	xRefTable := ctx.XRefTable
 	if !xRefTable.Valid {
 		if err := xRefTable.LocateNameTree("EmbeddedFiles", false); err != nil {
@@ -223,11 +664,16 @@ func (ctx *Context) ExtractAttachments(ids []string) ([]Attachment, error) {
 
 	createAttachment := func(xRefTable *XRefTable, id string, o Object) error {
 		decode := true
-		sd, desc, modTime, err := fileSpecStreamDictInfo(xRefTable, id, o, decode)
+		sd, desc, modTime, creationTime, err := fileSpecStreamDictInfo(xRefTable, id, o, decode)
 		if err != nil {
 			return err
 		}
-		a := Attachment{Reader: bytes.NewReader(sd.Content), ID: id, Desc: desc, ModTime: modTime}
+		if verify && sd != nil {
+			if err := verifyEmbeddedFile(id, sd); err != nil {
+				return err
+			}
+		}
+		a := Attachment{Reader: bytes.NewReader(sd.Content), ID: id, Desc: desc, ModTime: modTime, CreationTime: creationTime}
 		aa = append(aa, a)
 		return nil
 	}
@@ -253,3 +699,39 @@ func (ctx *Context) ExtractAttachments(ids []string) ([]Attachment, error) {
 
 	return aa, nil
 }
+
+// ExtractAttachment returns the attachment with id including its data.
+// ErrAttachmentNotFound is returned if there is no such attachment.
+func (ctx *Context) ExtractAttachment(id string) (*Attachment, error) {
+	xRefTable := ctx.XRefTable
+	if !xRefTable.Valid {
+		if err := xRefTable.LocateNameTree("EmbeddedFiles", false); err != nil {
+			return nil, err
+		}
+	}
+	if xRefTable.Names["EmbeddedFiles"] == nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	o, ok := xRefTable.Names["EmbeddedFiles"].Value(id)
+	if !ok {
+		return nil, ErrAttachmentNotFound
+	}
+
+	sd, desc, modTime, creationTime, err := fileSpecStreamDictInfo(xRefTable, id, o, true)
+	if err != nil {
+		return nil, err
+	}
+	if sd == nil {
+		return nil, errors.Errorf("pdfcpu: extractAttachment: %s: no embedded file data available", id)
+	}
+
+	return &Attachment{
+		Reader:       bytes.NewReader(sd.Content),
+		ID:           id,
+		Desc:         desc,
+		ModTime:      modTime,
+		CreationTime: creationTime,
+		MimeType:     attachmentMimeType(sd, id),
+	}, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// batesInset is the distance of Bates numbers from the page edges unless an offset is given.
+const batesInset = 10
+
+// ParseBatesStampDetails returns a stamp rendering prefix followed by the page number zero padded to digits.
+// desc is either a position anchor like "br" or a stamp description like "pos:br, points:10, fillcolor:#FF0000".
+// Bates numbers default to the bottom right corner using 12 points, no rotation and no scaling.
+// Use PageNrOffset to continue numbering across documents.
+func ParseBatesStampDetails(prefix string, digits int, desc string) (*Watermark, error) {
+
+	if digits < 0 {
+		return nil, errors.Errorf("pdfcpu: Bates numbering: invalid number of digits: %d", digits)
+	}
+
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		desc = "br"
+	}
+	if !strings.Contains(desc, ":") {
+		desc = "pos:" + desc
+	}
+
+	text := strings.ReplaceAll(prefix, "%", "%%") + fmt.Sprintf("%%0%dp", digits)
+
+	wm, err := ParseTextWatermarkDetails(text, "scale:1 abs, points:12, "+desc, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !wm.UserRotOrDiagonal {
+		wm.Diagonal = NoDiagonal
+	}
+
+	if wm.Dx == 0 && wm.Dy == 0 {
+		wm.Dx, wm.Dy = batesOffset(wm.Pos)
+	}
+
+	return wm, nil
+}
+
+// batesOffset moves Bates numbers anchored at a page edge towards the page center.
+func batesOffset(a anchor) (dx, dy int) {
+
+	switch a {
+	case TopLeft, Left, BottomLeft:
+		dx = batesInset
+	case TopRight, Right, BottomRight:
+		dx = -batesInset
+	}
+
+	switch a {
+	case TopLeft, TopCenter, TopRight:
+		dy = -batesInset
+	case BottomLeft, BottomCenter, BottomRight:
+		dy = batesInset
+	}
+
+	return dx, dy
+}
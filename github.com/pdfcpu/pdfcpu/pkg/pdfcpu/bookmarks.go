@@ -25,16 +25,79 @@ var (
 
 // Bookmark represents an outline item at some level including page span info.
 type Bookmark struct {
-	Title    string
-	PageFrom int
-	PageThru int // >= pageFrom and reaches until before pageFrom of the next bookmark.
+	Title    string       `json:"title"`
+	PageFrom int          `json:"page"`            // The page this bookmark points to.
+	PageThru int          `json:"-"`               // >= pageFrom and reaches until before pageFrom of the next bookmark.
+	Color    *SimpleColor `json:"color,omitempty"` // Color of the title text, nil for black.
+	Bold     bool         `json:"bold,omitempty"`
+	Italic   bool         `json:"italic,omitempty"`
+	Children []Bookmark   `json:"kids,omitempty"` // Nested bookmarks as returned by Bookmarks and used by AddBookmarks.
 }
 
+// outlineItemStyle copies the color and style flags of the outline item d into bm, see 12.3.3 Table 153.
+func (ctx *Context) outlineItemStyle(d Dict, bm *Bookmark) error {
+	a, err := ctx.DereferenceArray(d["C"])
+	if err != nil {
+		return err
+	}
+	if len(a) == 3 {
+		var ff [3]float64
+		for i, o := range a {
+			if ff[i], err = ctx.DereferenceNumber(o); err != nil {
+				return err
+			}
+		}
+		bm.Color = &SimpleColor{R: float32(ff[0]), G: float32(ff[1]), B: float32(ff[2])}
+	}
+
+	if f := d.IntEntry("F"); f != nil {
+		bm.Italic = *f&1 > 0
+		bm.Bold = *f&2 > 0
+	}
+
+	return nil
+}
+
+// dereferenceDestinationArray resolves the named destination key using the Dests name tree
+// or the Dests dict of the catalog, see 12.3.2.3
 func (ctx *Context) dereferenceDestinationArray(key string) (Array, error) {
-	o, ok := ctx.Names["Dests"].Value(key)
+	var (
+		o  Object
+		ok bool
+	)
+
+	if root := ctx.Names["Dests"]; root != nil {
+		o, ok = root.Value(key)
+	}
+
+	if !ok {
+		rootDict, err := ctx.Catalog()
+		if err != nil {
+			return nil, err
+		}
+		d, err := ctx.DereferenceDict(rootDict["Dests"])
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			o, ok = d.Find(key)
+		}
+	}
+
 	if !ok {
 		return nil, errCorruptedDests
 	}
+
+	o, err := ctx.Dereference(o)
+	if err != nil {
+		return nil, err
+	}
+
+	// The value may also be a dict holding the destination in D.
+	if d, ok := o.(Dict); ok {
+		o = d["D"]
+	}
+
 	return ctx.DereferenceArray(o)
 }
 
@@ -62,12 +125,21 @@ func (ctx *Context) positionToOutlineTreeLevel1() (Dict, *IndirectRef, error) {
 
 	first := d.IndirectRefEntry("First")
 	last := d.IndirectRefEntry("Last")
+	if first == nil || last == nil {
+		return nil, nil, errNoBookmarks
+	}
 
 	// We consider Bookmarks at level 1 or 2 only.
 	for *first == *last {
-		if d, err = ctx.DereferenceDict(*first); err != nil {
+		d1, err := ctx.DereferenceDict(*first)
+		if err != nil {
 			return nil, nil, err
 		}
+		if d1.IndirectRefEntry("First") == nil || d1.IndirectRefEntry("Last") == nil {
+			// A single outline item without children.
+			break
+		}
+		d = d1
 		first = d.IndirectRefEntry("First")
 		last = d.IndirectRefEntry("Last")
 	}
@@ -75,71 +147,359 @@ func (ctx *Context) positionToOutlineTreeLevel1() (Dict, *IndirectRef, error) {
 	return d, first, nil
 }
 
-// BookmarksForOutlineLevel1 returns bookmarks incliuding page span info.
-func (ctx *Context) BookmarksForOutlineLevel1() ([]Bookmark, error) {
-	d, first, err := ctx.positionToOutlineTreeLevel1()
+// outlineItemPageNr returns the number of the page the outline item d points to
+// either by destination or by GoTo action.
+func (ctx *Context) outlineItemPageNr(d Dict) (int, error) {
+	dest, found := d["Dest"]
+	if !found {
+		a, err := ctx.DereferenceDict(d["A"])
+		if err != nil {
+			return 0, err
+		}
+		if a == nil || a.NameEntry("S") == nil || *a.NameEntry("S") != "GoTo" {
+			return 0, errNoBookmarks
+		}
+		dest = a["D"]
+	}
+
+	dest, err := ctx.Dereference(dest)
+	if err != nil {
+		return 0, err
+	}
+
+	var arr Array
+
+	switch dest := dest.(type) {
+	case Name:
+		arr, err = ctx.dereferenceDestinationArray(dest.Value())
+	case StringLiteral:
+		arr, err = ctx.dereferenceDestinationArray(dest.Value())
+	case HexLiteral:
+		arr, err = ctx.dereferenceDestinationArray(dest.Value())
+	case Array:
+		arr = dest
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(arr) == 0 {
+		return 0, errCorruptedDests
+	}
+	ir, ok := arr[0].(IndirectRef)
+	if !ok {
+		return 0, errCorruptedDests
+	}
+
+	return ctx.PageNumber(ir.ObjectNumber.Value())
+}
+
+// outlineItemBookmark returns the bookmark for the outline item d without page span info.
+func (ctx *Context) outlineItemBookmark(d Dict) (Bookmark, error) {
+	title, _ := Text(d["Title"])
+
+	pageFrom, err := ctx.outlineItemPageNr(d)
+	if err != nil {
+		return Bookmark{}, err
+	}
+
+	bm := Bookmark{Title: title, PageFrom: pageFrom}
+	if err := ctx.outlineItemStyle(d, &bm); err != nil {
+		return Bookmark{}, err
+	}
+
+	return bm, nil
+}
+
+// outlineItemsForLevel appends the bookmarks for the outline item first and its siblings at level to bms.
+// Items at a level above depth get replaced by their children unless they start on an earlier page.
+func (ctx *Context) outlineItemsForLevel(first *IndirectRef, level, depth int, bms *[]Bookmark) error {
+	var d Dict
+
+	for ir := first; ir != nil; ir = d.IndirectRefEntry("Next") {
+
+		var err error
+		if d, err = ctx.DereferenceDict(*ir); err != nil {
+			return err
+		}
+
+		bm, err := ctx.outlineItemBookmark(d)
+		if err != nil {
+			return err
+		}
+
+		kids := d.IndirectRefEntry("First")
+		if level == depth || kids == nil {
+			*bms = append(*bms, bm)
+			continue
+		}
+
+		i := len(*bms)
+		if err := ctx.outlineItemsForLevel(kids, level+1, depth, bms); err != nil {
+			return err
+		}
+		if len(*bms) == i || (*bms)[i].PageFrom > bm.PageFrom {
+			*bms = append((*bms)[:i], append([]Bookmark{bm}, (*bms)[i:]...)...)
+		}
+	}
+
+	return nil
+}
+
+// BookmarksForOutlineLevel returns the bookmarks at outline level depth including page span info.
+// Level 1 is the first level holding more than one outline item.
+// Items without children at a level above depth are included in order to cover all pages.
+func (ctx *Context) BookmarksForOutlineLevel(depth int) ([]Bookmark, error) {
+	if depth < 1 {
+		return nil, errors.Errorf("pdfcpu: invalid outline level: %d", depth)
+	}
+
+	_, first, err := ctx.positionToOutlineTreeLevel1()
 	if err != nil {
 		return nil, err
 	}
 
 	bms := []Bookmark{}
 
-	// Process outline items.
+	if err := ctx.outlineItemsForLevel(first, 1, depth, &bms); err != nil {
+		return nil, err
+	}
+
+	for i := 1; i < len(bms); i++ {
+		if bms[i].PageFrom > bms[i-1].PageFrom {
+			bms[i-1].PageThru = bms[i].PageFrom - 1
+		} else {
+			bms[i-1].PageThru = bms[i-1].PageFrom
+		}
+	}
+
+	return bms, nil
+}
+
+// BookmarksForOutlineLevel1 returns bookmarks incliuding page span info.
+func (ctx *Context) BookmarksForOutlineLevel1() ([]Bookmark, error) {
+	return ctx.BookmarksForOutlineLevel(1)
+}
+
+// outlineItems returns the bookmarks for the outline item first and its siblings including all descendants.
+func (ctx *Context) outlineItems(first *IndirectRef) ([]Bookmark, error) {
+	var d Dict
+
+	bms := []Bookmark{}
+
 	for ir := first; ir != nil; ir = d.IndirectRefEntry("Next") {
 
+		var err error
 		if d, err = ctx.DereferenceDict(*ir); err != nil {
 			return nil, err
 		}
 
-		title, _ := Text(d["Title"])
+		bm, err := ctx.outlineItemBookmark(d)
+		if err != nil {
+			return nil, err
+		}
 
-		dest, found := d["Dest"]
-		if !found {
-			return nil, errNoBookmarks
+		if kids := d.IndirectRefEntry("First"); kids != nil {
+			if bm.Children, err = ctx.outlineItems(kids); err != nil {
+				return nil, err
+			}
 		}
 
-		var ir IndirectRef
+		bms = append(bms, bm)
+	}
 
-		dest, _ = ctx.Dereference(dest)
+	return bms, nil
+}
 
-		switch dest := dest.(type) {
-		case Name:
-			arr, err := ctx.dereferenceDestinationArray(dest.Value())
-			if err != nil {
-				return nil, err
-			}
-			ir = arr[0].(IndirectRef)
-		case StringLiteral:
-			arr, err := ctx.dereferenceDestinationArray(dest.Value())
-			if err != nil {
-				return nil, err
-			}
-			ir = arr[0].(IndirectRef)
-		case HexLiteral:
-			arr, err := ctx.dereferenceDestinationArray(dest.Value())
-			if err != nil {
-				return nil, err
-			}
-			ir = arr[0].(IndirectRef)
-		case Array:
-			ir = dest[0].(IndirectRef)
+// Bookmarks returns the complete outline tree as nested bookmarks without page span info.
+func (ctx *Context) Bookmarks() ([]Bookmark, error) {
+	// Load Dests nametree.
+	if err := ctx.LocateNameTree("Dests", false); err != nil {
+		return nil, err
+	}
 
+	ir, err := ctx.Outlines()
+	if err != nil {
+		return nil, err
+	}
+	if ir == nil {
+		return nil, errNoBookmarks
+	}
+
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.IndirectRefEntry("First") == nil {
+		return nil, errNoBookmarks
+	}
+
+	return ctx.outlineItems(d.IndirectRefEntry("First"))
+}
+
+func offsetBookmarks(bms []Bookmark, offset int) {
+	for i := range bms {
+		bms[i].PageFrom += offset
+		offsetBookmarks(bms[i].Children, offset)
+	}
+}
+
+// MergedBookmark returns a bookmark titled title pointing to the first page of ctx holding the outline of ctx as children.
+// All page numbers get offset by offset, the number of pages preceding ctx in a merged document.
+func (ctx *Context) MergedBookmark(title string, offset int) (Bookmark, error) {
+	bms, err := ctx.Bookmarks()
+	if err != nil && err != errNoBookmarks {
+		return Bookmark{}, err
+	}
+
+	offsetBookmarks(bms, offset)
+
+	return Bookmark{Title: title, PageFrom: offset + 1, Children: bms}, nil
+}
+
+// outlineItemDict returns an outline item for bm with parent pointing to the top of its page, see 12.3.3 Table 153.
+func (ctx *Context) outlineItemDict(bm Bookmark, parent IndirectRef) (Dict, error) {
+	if bm.PageFrom < 1 || bm.PageFrom > ctx.PageCount {
+		return nil, errors.Errorf("pdfcpu: AddBookmarks: invalid page number %d for bookmark %s", bm.PageFrom, bm.Title)
+	}
+
+	ir, err := ctx.PageDictIndRef(bm.PageFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	pbs, err := ctx.PageBoundaries(bm.PageFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Dict{
+		"Title":  textString(bm.Title),
+		"Parent": parent,
+		"Dest":   Array{*ir, Name("XYZ"), nil, Float(pbs.CropBox.Rect.UR.Y), nil},
+	}
+
+	if c := bm.Color; c != nil {
+		d.Insert("C", NewNumberArray(float64(c.R), float64(c.G), float64(c.B)))
+	}
+
+	f := 0
+	if bm.Italic {
+		f |= 1
+	}
+	if bm.Bold {
+		f |= 2
+	}
+	if f > 0 {
+		d.Insert("F", Integer(f))
+	}
+
+	return d, nil
+}
+
+// createOutlineItems creates a linked list of outline items for bms as children of parent.
+// It returns the first and last item along with the number of all items created, which are open.
+func (ctx *Context) createOutlineItems(parent IndirectRef, bms []Bookmark) (first, last *IndirectRef, count int, err error) {
+	var prev Dict
+
+	for _, bm := range bms {
+
+		d, err := ctx.outlineItemDict(bm, parent)
+		if err != nil {
+			return nil, nil, 0, err
 		}
 
-		pageFrom, err := ctx.PageNumber(ir.ObjectNumber.Value())
+		ir, err := ctx.IndRefForNewObject(d)
 		if err != nil {
-			return nil, err
+			return nil, nil, 0, err
 		}
 
-		if len(bms) > 0 {
-			if pageFrom > bms[len(bms)-1].PageFrom {
-				bms[len(bms)-1].PageThru = pageFrom - 1
-			} else {
-				bms[len(bms)-1].PageThru = bms[len(bms)-1].PageFrom
+		if len(bm.Children) > 0 {
+			f, l, c, err := ctx.createOutlineItems(*ir, bm.Children)
+			if err != nil {
+				return nil, nil, 0, err
 			}
+			d.Insert("First", *f)
+			d.Insert("Last", *l)
+			d.Insert("Count", Integer(c))
+			count += c
+		}
+
+		if prev == nil {
+			first = ir
+		} else {
+			prev.Insert("Next", *ir)
+			d.Insert("Prev", *last)
 		}
-		bms = append(bms, Bookmark{Title: title, PageFrom: pageFrom})
+
+		prev, last = d, ir
+		count++
 	}
 
-	return bms, nil
+	return first, last, count, nil
+}
+
+// AddBookmarks creates outline items for bms including their children.
+// The new items get appended to any existing outline unless replace is true.
+func AddBookmarks(ctx *Context, bms []Bookmark, replace bool) error {
+	if len(bms) == 0 {
+		return errors.New("pdfcpu: AddBookmarks: missing bookmarks")
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	ir, err := ctx.Outlines()
+	if err != nil {
+		return err
+	}
+
+	var d Dict
+
+	if ir != nil && !replace {
+		if d, err = ctx.DereferenceDict(*ir); err != nil {
+			return err
+		}
+	}
+
+	if d == nil {
+		d = Dict{"Type": Name("Outlines")}
+		if ir, err = ctx.IndRefForNewObject(d); err != nil {
+			return err
+		}
+		rootDict.Update("Outlines", *ir)
+	}
+
+	first, last, count, err := ctx.createOutlineItems(*ir, bms)
+	if err != nil {
+		return err
+	}
+
+	if irLast := d.IndirectRefEntry("Last"); irLast != nil {
+		d1, err := ctx.DereferenceDict(*irLast)
+		if err != nil {
+			return err
+		}
+		d1.Update("Next", *first)
+		if d1, err = ctx.DereferenceDict(*first); err != nil {
+			return err
+		}
+		d1.Insert("Prev", *irLast)
+		if c := d.IntEntry("Count"); c != nil && *c > 0 {
+			count += *c
+		}
+	} else {
+		d.Update("First", *first)
+	}
+
+	d.Update("Last", *last)
+	d.Update("Count", Integer(count))
+
+	return nil
 }
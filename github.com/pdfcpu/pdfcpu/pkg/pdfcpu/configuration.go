@@ -16,6 +16,14 @@ limitations under the License.
 
 package pdfcpu
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pkg/errors"
+)
+
 const (
 	// ValidationStrict ensures 100% compliance with the spec (PDF 32000-1:2008).
 	ValidationStrict int = iota
@@ -25,8 +33,80 @@ const (
 
 	// ValidationNone bypasses validation.
 	ValidationNone
+
+	// ValidationCollect validates like ValidationRelaxed but continues after failing violations where possible
+	// and returns all findings as ValidationErrors.
+	// Validation resumes with the next page dict, the next annotations of a page, the next entry of the root object
+	// or after the info dict. Any other failing violation, eg. within a resource dict, skips the rest of its enclosing unit.
+	ValidationCollect
+)
+
+const (
+	// CycleError aborts processing when encountering a recursive object reference.
+	CycleError int = iota
+
+	// CycleBreak drops the offending reference and continues reporting a warning.
+	CycleBreak
 )
 
+const (
+	// StreamLengthRecover corrects a wrong stream length by locating the endstream keyword and continues reporting a warning.
+	StreamLengthRecover int = iota
+
+	// StreamLengthError aborts processing when the stream data is not followed by endstream.
+	StreamLengthError
+)
+
+// ValidationSeverity classifies a finding reported during validation.
+type ValidationSeverity int
+
+const (
+	// SeverityWarning is a spec violation tolerated in relaxed mode.
+	SeverityWarning ValidationSeverity = iota
+
+	// SeverityError is a spec violation failing validation.
+	SeverityError
+)
+
+func (vs ValidationSeverity) String() string {
+	if vs == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// ValidationError represents a finding collected in ValidationCollect mode.
+// ObjNr is 0 if the finding can not be attributed to an indirect object.
+type ValidationError struct {
+	Severity ValidationSeverity
+	ObjNr    int
+	Msg      string
+}
+
+func (ve ValidationError) Error() string {
+	if ve.ObjNr == 0 {
+		return fmt.Sprintf("%s: %s", ve.Severity, ve.Msg)
+	}
+	return fmt.Sprintf("%s: obj#%d: %s", ve.Severity, ve.ObjNr, ve.Msg)
+}
+
+// ValidationErrors is returned by validation in ValidationCollect mode if at least one finding is an error.
+// It holds all findings including warnings in the order they occurred.
+type ValidationErrors []ValidationError
+
+func (ve ValidationErrors) Error() string {
+	ss := make([]string, len(ve))
+	for i, e := range ve {
+		ss[i] = e.Error()
+	}
+	return strings.Join(ss, "\n")
+}
+
+// ReportFunc receives validation findings as they occur.
+// objNr is 0 if the finding can not be attributed to an indirect object.
+// A non nil return value aborts validation with this error.
+type ReportFunc func(severity ValidationSeverity, objNr int, msg string) error
+
 const (
 
 	// StatsFileNameDefault is the standard stats filename.
@@ -85,6 +165,58 @@ const (
 	ADDPROPERTIES
 	REMOVEPROPERTIES
 	COLLECT
+	REGENERATEAPPEARANCES
+	SETFIELDFLAGS
+	GARBAGECOLLECT
+	UNIFYPAGESIZE
+	LISTTABORDER
+	SETTABORDER
+	SETALTTEXT
+	FIXANNOTATIONPAGEREFS
+	LISTROTATEDPAGES
+	REMOVETRANSPARENCY
+	LISTACTIONS
+	STRIPACTIONS
+	SETKIOSKMODE
+	LISTKIOSKMODE
+	LISTPERMS
+	SETDOCMDP
+	EXTRACTTEXTWITHLINKS
+	REDLINE
+	SETPAGEBOXES
+	LISTPAGEBOXES
+	ADDPRINTMARKS
+	EXTRACTTEXT
+	SETDIRECTION
+	LISTDIRECTION
+	LISTIMAGERESOLUTIONS
+	NORMALIZEDATES
+	ADDBOOKMARKS
+	EXPORTBOOKMARKS
+	BOOKLET
+	TOGRAYSCALE
+	RESAMPLEIMAGES
+	CROPTOCONTENT
+	REDACT
+	FILLFORM
+	EXPORTFORM
+	IMPORTFORM
+	LISTFORMFIELDS
+	CHANGEPW
+	LISTANNOTATIONS
+	REMOVEANNOTATIONS
+	ADDLINKANNOTATIONS
+	LISTPDFFONTS
+	NORMALIZEROTATION
+	REORDERPAGES
+	INSERTBLANKPAGES
+	SETINFO
+	SETXMP
+	CHECKPDFA
+	DIFF
+	IMAGESTOPDF
+	QUICKINFO
+	BATCH
 )
 
 // Configuration of a Context.
@@ -96,9 +228,18 @@ type Configuration struct {
 	// Enables decoding of all streams (fontfiles, images..) for logging purposes.
 	DecodeAllStreams bool
 
-	// Validate against ISO-32000: strict or relaxed
+	// Validate against ISO-32000: strict, relaxed or collect
 	ValidationMode int
 
+	// Optional callback receiving each validation finding as it occurs.
+	ReportFunc ReportFunc
+
+	// Handling of recursive object references: CycleError or CycleBreak
+	CycleMode int
+
+	// Handling of stream dicts declaring a wrong Length: StreamLengthRecover or StreamLengthError
+	StreamLengthMode int
+
 	// End of line char sequence for writing.
 	Eol string
 
@@ -111,6 +252,30 @@ type Configuration struct {
 	// Switches between xRefSection (<=V1.4) and objectStream/xRefStream (>=V1.5) writing.
 	WriteXRefStream bool
 
+	// Turns on writing linearized files optimized for fast web view, see Annex F.
+	// Linearized files use xRefSections and take precedence over WriteObjectStream and WriteXRefStream.
+	Linearize bool
+
+	// Keeps the catalog, page tree nodes and page objects out of object streams.
+	// Any other object still qualifies for object streams.
+	ExcludePagesFromObjectStreams bool
+
+	// Filter used for encoding object streams and xRefStreams generated by the writer.
+	// filter.Flate (default) or filter.LZW.
+	// Any other stream keeps the filters it was read or created with.
+	StreamFilter string
+
+	// Turns on trimming of embedded subset fonts during optimization:
+	// font files shared by identical copies get merged and Widths get trimmed to the range of used codes.
+	TrimFonts bool
+
+	// Turns on merging of identical objects during optimization:
+	// duplicate resources get collapsed into one object with all references updated.
+	DeduplicateObjects bool
+
+	// Keeps the annotations of a page whose content gets replaced by ReplacePage.
+	PreserveAnnotations bool
+
 	// Turns on stats collection.
 	// TODO Decision - unused.
 	CollectStats bool
@@ -126,6 +291,10 @@ type Configuration struct {
 	OwnerPW    string
 	OwnerPWNew *string
 
+	// Optional callback supplying the password of an encrypted file if neither UserPW nor OwnerPW are set.
+	// It gets called at most once per read and only if the file requires a password.
+	PasswordFunc func() (string, error)
+
 	// EncryptUsingAES ensures AES encryption.
 	// true: AES encryption
 	// false: RC4 encryption.
@@ -134,14 +303,48 @@ type Configuration struct {
 	// AES:40,128,256 RC4:40,128
 	EncryptKeyLength int
 
+	// EncryptUsingAES256 ensures AES-256 encryption using the PDF 2.0 security handler (revision 6)
+	// regardless of EncryptUsingAES and EncryptKeyLength.
+	EncryptUsingAES256 bool
+
 	// Supplied user access permissions, see Table 22
 	Permissions int16
 
+	// Supplied individual user access permissions taking precedence over Permissions.
+	// They are mapped to the permission bits of the security handler revision in use.
+	PermissionFlags *PermissionFlags
+
 	// Command being executed.
 	Cmd CommandMode
 
 	// Chosen units for outputting paper sizes.
 	Units DisplayUnit
+
+	// Maximum deviation in points for grouping pages of nearly identical size.
+	PageSizeTolerance float64
+
+	// Records relative paths of attachments using forward slashes instead of their base file names.
+	PreserveAttachmentPaths bool
+
+	// Quality factor 1..100 for JPEG encoding images, defaults to DefaultJPEGQuality.
+	JPEGQuality int
+
+	// Keeps the header and binary marker comment of the input file byte-identical
+	// and writes using its end of line marker instead of Eol.
+	PreserveHeader bool
+
+	// Reuses the stream of an already embedded file with identical content when adding attachments.
+	DedupAttachments bool
+
+	// Effective resolution below which placed images are considered low resolution for print, defaults to 150 DPI.
+	MinImageDPI float64
+
+	// Embeds a font program for standard fonts used by text stamps and watermarks instead of referencing them by name, eg. for PDF/A.
+	// The font program is taken from the installed TrueType font mapped to the standard font in StandardFontPrograms.
+	EmbedStandardFonts bool
+
+	// Installed TrueType fonts by standard font name serving as font programs for embedding standard fonts.
+	StandardFontPrograms map[string]string
 }
 
 // NewDefaultConfiguration returns the default pdfcpu configuration.
@@ -154,10 +357,14 @@ func NewDefaultConfiguration() *Configuration {
 		Eol:               EolLF,
 		WriteObjectStream: true,
 		WriteXRefStream:   true,
+		StreamFilter:      filter.Flate,
 		CollectStats:      true,
 		EncryptUsingAES:   true,
 		EncryptKeyLength:  256,
 		Permissions:       PermissionsNone,
+		PageSizeTolerance: 2,
+		JPEGQuality:       DefaultJPEGQuality,
+		MinImageDPI:       150,
 	}
 }
 
@@ -192,9 +399,30 @@ func (c *Configuration) ValidationModeString() string {
 		return "relaxed"
 	}
 
+	if c.ValidationMode == ValidationCollect {
+		return "collect"
+	}
+
 	return ""
 }
 
+// StreamFilterName returns the name of the filter to be used for encoding object streams and xRefStreams generated by the writer.
+func (c *Configuration) StreamFilterName() string {
+	if c.StreamFilter == "" {
+		return filter.Flate
+	}
+	return c.StreamFilter
+}
+
+// validateStreamFilter returns an error if StreamFilter is not supported for encoding.
+func (c *Configuration) validateStreamFilter() error {
+	switch c.StreamFilter {
+	case "", filter.Flate, filter.LZW:
+		return nil
+	}
+	return errors.Errorf("pdfcpu: unsupported stream filter: %s, want %s or %s", c.StreamFilter, filter.Flate, filter.LZW)
+}
+
 // ApplyReducedFeatureSet returns true if complex entries like annotations shall not be written.
 func (c *Configuration) ApplyReducedFeatureSet() bool {
 	switch c.Cmd {
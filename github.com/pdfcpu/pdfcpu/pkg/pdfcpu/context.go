@@ -55,6 +55,9 @@ func NewContext(rs io.ReadSeeker, conf *Configuration) (*Context, error) {
 		false,
 	}
 
+	ctx.XRefTable.ReportFunc = conf.ReportFunc
+	ctx.XRefTable.CycleMode = conf.CycleMode
+
 	return ctx, nil
 }
 
@@ -329,6 +332,8 @@ type ReadContext struct {
 	FileSize            int64         // Input file size.
 	rs                  io.ReadSeeker // Input read seeker.
 	EolCount            int           // 1 or 2 characters used for eol.
+	Eol                 string        // End of line marker of the header line, only set for PreserveHeader.
+	Header              []byte        // Header and binary marker comment verbatim, only set for PreserveHeader.
 	BinaryTotalSize     int64         // total stream data
 	BinaryImageSize     int64         // total image stream data
 	BinaryFontSize      int64         // total font stream data (fontfiles)
@@ -343,11 +348,15 @@ type ReadContext struct {
 }
 
 func newReadContext(rs io.ReadSeeker) *ReadContext {
-	return &ReadContext{
+	rc := &ReadContext{
 		rs:            rs,
 		ObjectStreams: IntSet{},
 		XRefStreams:   IntSet{},
 	}
+	if f, ok := rs.(*os.File); ok {
+		rc.FileName = f.Name()
+	}
+	return rc
 }
 
 // IsObjectStreamObject returns true if object i is a an object stream.
@@ -459,6 +468,11 @@ type OptimizationContext struct {
 
 	FixTable  map[int]bool // map for visited objects during xreftable traversal for fixing references to free objects.
 	NullObjNr *int         // objNr of a regular null object, to be used for fixing references to free objects.
+
+	TrimmedFontBytes int64 // Bytes saved by trimming embedded subset fonts.
+
+	DeduplicatedObjs  int   // Objects eliminated by deduplication.
+	DeduplicatedBytes int64 // Bytes saved by deduplication.
 }
 
 func newOptimizationContext() *OptimizationContext {
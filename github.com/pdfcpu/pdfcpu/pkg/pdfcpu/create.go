@@ -19,6 +19,7 @@ package pdfcpu
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"strconv"
 	"strings"
@@ -206,6 +207,31 @@ func DrawRect(b *bytes.Buffer, r *Rectangle) {
 	b.WriteString(fmt.Sprintf("%.2f %.2f %.2f %.2f re s ", r.LL.X, r.LL.Y, r.Width(), r.Height()))
 }
 
+// ellipsePath appends a closed path for the ellipse inscribed in r approximated by 4 bezier curves.
+func ellipsePath(w io.Writer, r *Rectangle) {
+	const k = 0.5523
+	rx, ry := r.Width()/2, r.Height()/2
+	cx, cy := r.LL.X+rx, r.LL.Y+ry
+	fmt.Fprintf(w, "%.2f %.2f m ", cx+rx, cy)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", cx+rx, cy+k*ry, cx+k*rx, cy+ry, cx, cy+ry)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", cx-k*rx, cy+ry, cx-rx, cy+k*ry, cx-rx, cy)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ", cx-rx, cy-k*ry, cx-k*rx, cy-ry, cx, cy-ry)
+	fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c h ", cx+k*rx, cy-ry, cx+rx, cy-k*ry, cx+rx, cy)
+}
+
+// roundedRectPath appends a closed path for r using rounded corners of radius rad.
+func roundedRectPath(w io.Writer, r *Rectangle, rad float64) {
+	const k = 0.5523
+	rad = math.Min(rad, math.Min(r.Width(), r.Height())/2)
+	x1, y1, x2, y2 := r.LL.X, r.LL.Y, r.UR.X, r.UR.Y
+	d := k * rad
+	fmt.Fprintf(w, "%.2f %.2f m ", x1+rad, y1)
+	fmt.Fprintf(w, "%.2f %.2f l %.2f %.2f %.2f %.2f %.2f %.2f c ", x2-rad, y1, x2-rad+d, y1, x2, y1+rad-d, x2, y1+rad)
+	fmt.Fprintf(w, "%.2f %.2f l %.2f %.2f %.2f %.2f %.2f %.2f c ", x2, y2-rad, x2, y2-rad+d, x2-rad+d, y2, x2-rad, y2)
+	fmt.Fprintf(w, "%.2f %.2f l %.2f %.2f %.2f %.2f %.2f %.2f c ", x1+rad, y2, x1+rad-d, y2, x1, y2-rad+d, x1, y2-rad)
+	fmt.Fprintf(w, "%.2f %.2f l %.2f %.2f %.2f %.2f %.2f %.2f c h ", x1, y1+rad, x1, y1+rad-d, x1+rad-d, y1, x1+rad, y1)
+}
+
 // DrawAndFillRect strokes and fills a rectangular path for r.
 func DrawAndFillRect(b *bytes.Buffer, r *Rectangle) {
 	b.WriteString(fmt.Sprintf("%.2f %.2f %.2f %.2f re B ", r.LL.X, r.LL.Y, r.Width(), r.Height()))
@@ -1970,10 +1970,8 @@ func CreateContextWithXRefTable(conf *Configuration, pageDim *Dim) (*Context, er
 }
 
 func createDemoContentStreamDict(xRefTable *XRefTable, pageDict Dict, b []byte) (*IndirectRef, error) {
+	// The writer takes care of encoding and Length.
 	sd, _ := xRefTable.NewStreamDictForBuf(b)
-	if err := sd.Encode(); err != nil {
-		return nil, err
-	}
 	return xRefTable.IndRefForNewObject(*sd)
 }
 
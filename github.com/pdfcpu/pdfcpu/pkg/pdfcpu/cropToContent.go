@@ -0,0 +1,281 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// whiteThreshold is the minimum 8 bit gray level of image pixels considered to be white paper.
+const whiteThreshold = 240
+
+// inkBox returns the bounding box of the pixels of a w x h image not considered white
+// as columns x0..x1 and rows y0..y1 (rows from top to bottom, exclusive upper bounds).
+func inkBox(w, h int, white func(x, y int) bool) (x0, y0, x1, y1 int, found bool) {
+	x0, y0 = w, h
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if white(x, y) {
+				continue
+			}
+			if x < x0 {
+				x0 = x
+			}
+			if x >= x1 {
+				x1 = x + 1
+			}
+			if y < y0 {
+				y0 = y
+			}
+			y1 = y + 1
+			found = true
+		}
+	}
+	return
+}
+
+// imageInkBox returns the region of the image sd not considered white in the unit square of image space
+// or the whole unit square if the image data is not accessible.
+// The returned rectangle is nil for images being white altogether.
+func (xRefTable *XRefTable) imageInkBox(sd *StreamDict) (*Rectangle, error) {
+
+	unit := Rect(0, 0, 1, 1)
+
+	if im := sd.BooleanEntry("ImageMask"); im != nil && *im {
+		return unit, nil
+	}
+
+	w, h, bpc := sd.IntEntry("Width"), sd.IntEntry("Height"), sd.IntEntry("BitsPerComponent")
+	if w == nil || h == nil || *w <= 0 || *h <= 0 {
+		return unit, nil
+	}
+
+	var white func(x, y int) bool
+
+	if len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == filter.DCT {
+		img, err := jpeg.Decode(bytes.NewReader(sd.Raw))
+		if err != nil {
+			return unit, nil
+		}
+		b := img.Bounds()
+		if b.Dx() != *w || b.Dy() != *h {
+			return unit, nil
+		}
+		white = func(x, y int) bool {
+			return color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray).Y >= whiteThreshold
+		}
+	} else {
+
+		n, indexed := xRefTable.sampleComponents(sd.Dict["ColorSpace"])
+		if n == 0 || indexed || bpc == nil || *bpc != 1 && *bpc != 8 || *bpc == 1 && n != 1 {
+			return unit, nil
+		}
+
+		if err := sd.Decode(); err != nil {
+			if err == filter.ErrUnsupportedFilter {
+				return unit, nil
+			}
+			return nil, err
+		}
+		bb := sd.Content
+
+		if *bpc == 1 {
+			// 1 means white unless the decode array inverts the samples.
+			var on byte = 1
+			if a := sd.ArrayEntry("Decode"); len(a) == 2 && xRefTable.number(a[0]) == 1 {
+				on = 0
+			}
+			stride := (*w + 7) / 8
+			if len(bb) < stride**h {
+				return unit, nil
+			}
+			white = func(x, y int) bool {
+				return bb[y*stride+x/8]>>(7-uint(x%8))&1 == on
+			}
+		} else {
+			if len(bb) < *w**h*n {
+				return unit, nil
+			}
+			white = func(x, y int) bool {
+				i := (y**w + x) * n
+				for _, c := range bb[i : i+n] {
+					if n == 4 && c > 255-whiteThreshold || n != 4 && c < whiteThreshold {
+						return false
+					}
+				}
+				return true
+			}
+		}
+	}
+
+	x0, y0, x1, y1, found := inkBox(*w, *h, white)
+	if !found {
+		return nil, nil
+	}
+
+	// Image space rows run from top to bottom.
+	fw, fh := float64(*w), float64(*h)
+	return Rect(float64(x0)/fw, 1-float64(y1)/fh, float64(x1)/fw, 1-float64(y0)/fh), nil
+}
+
+// ContentBox returns the bounding box of path, text and image content drawn on page pageNr in default user space
+// within the visible region of the page or nil if there is none.
+// White paths and the white parts of images are ignored.
+func (ctx *Context) ContentBox(pageNr int) (*Rectangle, error) {
+
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	var (
+		r    *Rectangle
+		err1 error
+	)
+
+	add := func(pp ...types.Point) {
+		for _, p := range pp {
+			r = unionRect(r, Rect(p.X, p.Y, p.X, p.Y))
+		}
+	}
+
+	glyph := func(s string, start, end, center types.Point, size float64) {
+		if strings.TrimSpace(s) == "" {
+			return
+		}
+		add(glyphCorners(start, end, center)...)
+	}
+
+	te, err := newTextExtractor(ctx.XRefTable, glyph)
+	if err != nil {
+		return nil, err
+	}
+
+	inkBoxes := map[int]*Rectangle{}
+
+	te.image = func(id string, o Object, sd *StreamDict, ctm matrix) {
+		ir, ok := o.(IndirectRef)
+		b, cached := inkBoxes[ir.ObjectNumber.Value()]
+		if !ok || !cached {
+			var err error
+			if b, err = ctx.imageInkBox(sd); err != nil {
+				err1 = err
+				return
+			}
+			if ok {
+				inkBoxes[ir.ObjectNumber.Value()] = b
+			}
+		}
+		if b != nil {
+			add(ctm.transform(b.LL.X, b.LL.Y), ctm.transform(b.UR.X, b.LL.Y), ctm.transform(b.LL.X, b.UR.Y), ctm.transform(b.UR.X, b.UR.Y))
+		}
+	}
+
+	te.path = func(pp []types.Point) {
+		add(pp...)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == errNoContent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gs := textGState{ctm: identMatrix, hScale: 1}
+
+	if err := te.processContent(string(bb), inhPAttrs.resources, gs, 0); err != nil {
+		return nil, err
+	}
+	if err1 != nil {
+		return nil, err1
+	}
+
+	if r == nil {
+		return nil, nil
+	}
+
+	// Clip to the visible region of the page.
+	vr := inhPAttrs.cropBox
+	if vr == nil {
+		vr = inhPAttrs.mediaBox
+	}
+	if vr != nil {
+		r = Rect(math.Max(r.LL.X, vr.LL.X), math.Max(r.LL.Y, vr.LL.Y), math.Min(r.UR.X, vr.UR.X), math.Min(r.UR.Y, vr.UR.Y))
+		if r.Width() <= 0 || r.Height() <= 0 {
+			return nil, nil
+		}
+	}
+
+	return r, nil
+}
+
+// CropToContent sets the crop box of selected pages to the bounding box of their content enlarged by padding on each side
+// limited to the media box and returns the numbers of pages left unchanged because of missing content.
+func CropToContent(ctx *Context, selectedPages IntSet, padding float64) ([]int, error) {
+
+	if padding < 0 {
+		return nil, errors.Errorf("pdfcpu: CropToContent: invalid padding: %.2f", padding)
+	}
+
+	skipped := []int{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+
+		if selectedPages != nil && !selectedPages[i] {
+			continue
+		}
+
+		r, err := ctx.ContentBox(i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pdfcpu: CropToContent: page %d", i)
+		}
+		if r == nil {
+			skipped = append(skipped, i)
+			continue
+		}
+
+		d, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+
+		r = r.CroppedCopy(-padding)
+		if mb := inhPAttrs.mediaBox; mb != nil {
+			r = Rect(math.Max(r.LL.X, mb.LL.X), math.Max(r.LL.Y, mb.LL.Y), math.Min(r.UR.X, mb.UR.X), math.Min(r.UR.Y, mb.UR.Y))
+		}
+
+		log.Debug.Printf("CropToContent page %d: %s\n", i, r)
+
+		d.Update("CropBox", r.Array())
+	}
+
+	return skipped, nil
+}
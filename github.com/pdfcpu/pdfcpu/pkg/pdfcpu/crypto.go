@@ -26,6 +26,7 @@ import (
 	"crypto/rand"
 	"crypto/rc4"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -81,7 +82,8 @@ var (
 )
 
 // NewEncryptDict creates a new EncryptDict using the standard security handler.
-func newEncryptDict(needAES bool, keyLength int, permissions int16) Dict {
+// pdf20 selects revision 6 for AES-256 as defined by PDF 2.0.
+func newEncryptDict(needAES bool, keyLength int, pdf20 bool, permissions int16) Dict {
 
 	d := NewDict()
 
@@ -93,8 +95,11 @@ func newEncryptDict(needAES bool, keyLength int, permissions int16) Dict {
 		if keyLength == 256 {
 			i = 5
 		}
-		d.Insert("R", Integer(i))
 		d.Insert("V", Integer(i))
+		if keyLength == 256 && pdf20 {
+			i = 6
+		}
+		d.Insert("R", Integer(i))
 	} else {
 		d.Insert("R", Integer(2))
 		d.Insert("V", Integer(1))
@@ -194,7 +199,7 @@ func encKey(userpw string, e *Enc) (key []byte) {
 // validateUserPassword validates the user password aka document open password.
 func validateUserPassword(ctx *Context) (ok bool, err error) {
 
-	if ctx.E.R == 5 {
+	if ctx.E.R >= 5 {
 		return validateUserPasswordAES256(ctx)
 	}
 
@@ -375,6 +380,80 @@ func keySalt(bb []byte) []byte {
 	return bb[40:]
 }
 
+// hashAES256 computes the hash of a password for the AES-256 security handlers.
+// Revision 5 uses a plain SHA-256 digest, revision 6 the hardened hash of Algorithm 2.B.
+// u is the 48 byte user key when hashing an owner password and nil otherwise.
+func hashAES256(pw, salt, u []byte, r int) ([]byte, error) {
+
+	b := append(append(append([]byte{}, pw...), salt...), u...)
+	h := sha256.Sum256(b)
+	k := h[:]
+
+	if r < 6 {
+		return k, nil
+	}
+
+	// Algorithm 2.B
+	for i := 0; ; {
+
+		// a) Build K1 as 64 repetitions of password, K and u.
+		k1 := append(append(append([]byte{}, pw...), k...), u...)
+		k1 = bytes.Repeat(k1, 64)
+
+		// b) Encrypt K1 using AES-128 in CBC mode without padding.
+		cb, err := aes.NewCipher(k[:16])
+		if err != nil {
+			return nil, err
+		}
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(cb, k[16:32]).CryptBlocks(e, k1)
+
+		// c) The first 16 bytes of E taken as a big endian number modulo 3 select the hash function.
+		var sum int
+		for _, c := range e[:16] {
+			sum += int(c)
+		}
+
+		// d) Take the hash of E as the new K.
+		switch sum % 3 {
+		case 0:
+			h := sha256.Sum256(e)
+			k = h[:]
+		case 1:
+			h := sha512.Sum384(e)
+			k = h[:]
+		case 2:
+			h := sha512.Sum512(e)
+			k = h[:]
+		}
+
+		// e) Run at least 64 rounds and continue while the last byte of E exceeds the round number - 32.
+		i++
+		if i >= 64 && int(e[len(e)-1]) <= i-32 {
+			break
+		}
+	}
+
+	return k[:32], nil
+}
+
+// fileEncKeyAES256 decrypts the file encryption key from ue, which is either UE or OE.
+func fileEncKeyAES256(key, ue []byte) ([]byte, error) {
+
+	cb, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, 16)
+	k := make([]byte, 32)
+
+	mode := cipher.NewCBCDecrypter(cb, iv)
+	mode.CryptBlocks(k, ue)
+
+	return k, nil
+}
+
 func validateOwnerPasswordAES256(ctx *Context) (ok bool, err error) {
 
 	if len(ctx.OwnerPW) == 0 {
@@ -389,28 +468,23 @@ func validateOwnerPasswordAES256(ctx *Context) (ok bool, err error) {
 	//fmt.Printf("opw <%s> isValidUTF8String: %t\n", opw, utf8.Valid(opw))
 
 	// Algorithm 3.2a 3.
-	b := append(opw, validationSalt(ctx.E.O)...)
-	b = append(b, ctx.E.U...)
-	s := sha256.Sum256(b)
+	s, err := hashAES256(opw, validationSalt(ctx.E.O), ctx.E.U, ctx.E.R)
+	if err != nil {
+		return false, err
+	}
 
-	if !bytes.HasPrefix(ctx.E.O, s[:]) {
+	if !bytes.HasPrefix(ctx.E.O, s) {
 		return false, nil
 	}
 
-	b = append(opw, keySalt(ctx.E.O)...)
-	b = append(b, ctx.E.U...)
-	key := sha256.Sum256(b)
-
-	cb, err := aes.NewCipher(key[:])
+	key, err := hashAES256(opw, keySalt(ctx.E.O), ctx.E.U, ctx.E.R)
 	if err != nil {
 		return false, err
 	}
 
-	iv := make([]byte, 16)
-	ctx.EncKey = make([]byte, 32)
-
-	mode := cipher.NewCBCDecrypter(cb, iv)
-	mode.CryptBlocks(ctx.EncKey, ctx.E.OE)
+	if ctx.EncKey, err = fileEncKeyAES256(key, ctx.E.OE); err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
@@ -425,24 +499,23 @@ func validateUserPasswordAES256(ctx *Context) (ok bool, err error) {
 	//fmt.Printf("upw <%s> isValidUTF8String: %t\n", upw, utf8.Valid(upw))
 
 	// Algorithm 3.2a 4,
-	s := sha256.Sum256(append(upw, validationSalt(ctx.E.U)...))
+	s, err := hashAES256(upw, validationSalt(ctx.E.U), nil, ctx.E.R)
+	if err != nil {
+		return false, err
+	}
 
-	if !bytes.HasPrefix(ctx.E.U, s[:]) {
+	if !bytes.HasPrefix(ctx.E.U, s) {
 		return false, nil
 	}
 
-	key := sha256.Sum256(append(upw, keySalt(ctx.E.U)...))
-
-	cb, err := aes.NewCipher(key[:])
+	key, err := hashAES256(upw, keySalt(ctx.E.U), nil, ctx.E.R)
 	if err != nil {
 		return false, err
 	}
 
-	iv := make([]byte, 16)
-	ctx.EncKey = make([]byte, 32)
-
-	mode := cipher.NewCBCDecrypter(cb, iv)
-	mode.CryptBlocks(ctx.EncKey, ctx.E.UE)
+	if ctx.EncKey, err = fileEncKeyAES256(key, ctx.E.UE); err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
@@ -452,7 +525,7 @@ func validateOwnerPassword(ctx *Context) (ok bool, err error) {
 
 	e := ctx.E
 
-	if e.R == 5 {
+	if e.R >= 5 {
 		return validateOwnerPasswordAES256(ctx)
 	}
 
@@ -548,6 +621,80 @@ func perms(p int) (list []string) {
 	return list
 }
 
+// PermissionFlags represents user access permissions of an encrypted file, see Table 22.
+type PermissionFlags struct {
+	Print        bool // Print the document, in degraded quality unless HighResPrint is set too.
+	HighResPrint bool // Print at full quality (rev>=3).
+	Modify       bool // Modify the document other than controlled by Annotate, FillForms and Assemble.
+	Copy         bool // Copy or otherwise extract text and graphics.
+	Annotate     bool // Add or modify annotations and fill in form fields.
+	FillForms    bool // Fill in form fields even if Annotate is not set (rev>=3).
+	Extract      bool // Extract text and graphics for accessibility (rev>=3).
+	Assemble     bool // Insert, rotate or delete pages and create bookmarks or thumbnails (rev>=3).
+}
+
+// Bits returns the user access permission bits for security handler revision r.
+// Revision 2 does not know bits 9-12, these flags are folded into the bits covering them.
+func (pf PermissionFlags) Bits(r int) int16 {
+
+	p := PermissionsNone
+
+	set := func(ok bool, bit uint) {
+		if ok {
+			p |= 1 << (bit - 1)
+		}
+	}
+
+	if r < 3 {
+		set(pf.Print || pf.HighResPrint, 3)
+		set(pf.Modify || pf.Assemble, 4)
+		set(pf.Copy || pf.Extract, 5)
+		set(pf.Annotate || pf.FillForms, 6)
+		return p
+	}
+
+	set(pf.Print, 3)
+	set(pf.Modify, 4)
+	set(pf.Copy, 5)
+	set(pf.Annotate, 6)
+	set(pf.FillForms, 9)
+	set(pf.Extract, 10)
+	set(pf.Assemble, 11)
+	set(pf.HighResPrint, 12)
+
+	return p
+}
+
+// NewPermissionFlags decodes the user access permission bits p of security handler revision r.
+func NewPermissionFlags(p, r int) PermissionFlags {
+
+	bit := func(bit uint) bool {
+		return p&(1<<(bit-1)) > 0
+	}
+
+	pf := PermissionFlags{
+		Print:    bit(3),
+		Modify:   bit(4),
+		Copy:     bit(5),
+		Annotate: bit(6),
+	}
+
+	if r < 3 {
+		pf.HighResPrint = pf.Print
+		pf.FillForms = pf.Annotate
+		pf.Extract = pf.Copy
+		pf.Assemble = pf.Modify
+		return pf
+	}
+
+	pf.FillForms = bit(9)
+	pf.Extract = bit(10)
+	pf.Assemble = bit(11)
+	pf.HighResPrint = bit(12)
+
+	return pf
+}
+
 // Permissions returns a list of set permissions.
 func Permissions(ctx *Context) (list []string) {
 
@@ -562,7 +709,7 @@ func validatePermissions(ctx *Context) (bool, error) {
 
 	// Algorithm 3.2a 5.
 
-	if ctx.E.R != 5 {
+	if ctx.E.R < 5 {
 		return true, nil
 	}
 
@@ -585,7 +732,7 @@ func writePermissions(ctx *Context, d Dict) error {
 
 	// Algorithm 3.10
 
-	if ctx.E.R != 5 {
+	if ctx.E.R < 5 {
 		return nil
 	}
 
@@ -722,6 +869,8 @@ func checkV(ctx *Context, d Dict) (*int, error) {
 		return nil, err
 	}
 
+	ctx.IdentityStmF, ctx.IdentityStrF = false, false
+
 	// v == 2 implies RC4
 	if *v != 4 && *v != 5 {
 		return v, nil
@@ -739,9 +888,12 @@ func checkV(ctx *Context, d Dict) (*int, error) {
 	if err != nil {
 		return nil, err
 	}
+	// StmF and StrF both default to Identity.
+	ctx.IdentityStmF = stmf == nil || *stmf == "Identity"
 
 	// StrF
 	strf := d.NameEntry("StrF")
+	ctx.IdentityStrF = strf == nil || *strf == "Identity"
 	if strf != nil && *strf != "Identity" {
 		d1 := cfDict.DictEntry(*strf)
 		if d1 == nil {
@@ -771,6 +923,16 @@ func checkV(ctx *Context, d Dict) (*int, error) {
 	return v, nil
 }
 
+// encryptedStrings returns true if strings need to be en/decrypted.
+func (xRefTable *XRefTable) encryptedStrings() bool {
+	return xRefTable.EncKey != nil && !xRefTable.IdentityStrF
+}
+
+// encryptedStreams returns true if streams need to be en/decrypted.
+func (xRefTable *XRefTable) encryptedStreams() bool {
+	return xRefTable.EncKey != nil && !xRefTable.IdentityStmF
+}
+
 func length(d Dict) (int, error) {
 
 	l := d.IntEntry("Length")
@@ -788,11 +950,8 @@ func length(d Dict) (int, error) {
 func getR(d Dict) (int, error) {
 
 	r := d.IntEntry("R")
-	if r == nil || *r < 2 || *r > 5 {
-		if *r > 5 {
-			return 0, errors.New("pdfcpu: PDF 2.0 encryption not supported")
-		}
-		return 0, errors.New("pdfcpu: encryption: \"R\" must be 2,3,4,5")
+	if r == nil || *r < 2 || *r > 6 {
+		return 0, errors.New("pdfcpu: encryption: \"R\" must be 2,3,4,5,6")
 	}
 
 	return *r, nil
@@ -802,6 +961,10 @@ func validateAlgorithm(ctx *Context) (ok bool) {
 
 	k := ctx.EncryptKeyLength
 
+	if ctx.EncryptUsingAES256 {
+		return true
+	}
+
 	if ctx.EncryptUsingAES {
 		return k == 40 || k == 128 || k == 256
 	}
@@ -915,7 +1078,7 @@ func supportedEncryption(ctx *Context, d Dict) (*Enc, error) {
 	}
 
 	var oe, ue, perms []byte
-	if r == 5 {
+	if r >= 5 {
 		oe, ue, perms, err = validateAES256Parameters(d)
 		if err != nil {
 			return nil, err
@@ -982,7 +1145,7 @@ func encryptBytes(b []byte, objNr, genNr int, encKey []byte, needAES bool, r int
 
 	if needAES {
 		k := encKey
-		if r != 5 {
+		if r < 5 {
 			k = decryptKey(objNr, genNr, encKey, needAES)
 		}
 		bb, err := encryptAESBytes(b, k)
@@ -1016,7 +1179,7 @@ func decryptBytes(b []byte, objNr, genNr int, encKey []byte, needAES bool, r int
 
 	if needAES {
 		k := encKey
-		if r != 5 {
+		if r < 5 {
 			k = decryptKey(objNr, genNr, encKey, needAES)
 		}
 		bb, err := decryptAESBytes(b, k)
@@ -1200,7 +1363,7 @@ func decryptDeepObject(objIn Object, objNr, genNr int, key []byte, needAES bool,
 func encryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r int) ([]byte, error) {
 
 	k := encKey
-	if r != 5 {
+	if r < 5 {
 		k = decryptKey(objNr, genNr, encKey, needAES)
 	}
 
@@ -1215,7 +1378,7 @@ func encryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r
 func decryptStream(buf []byte, objNr, genNr int, encKey []byte, needAES bool, r int) ([]byte, error) {
 
 	k := encKey
-	if r != 5 {
+	if r < 5 {
 		k = decryptKey(objNr, genNr, encKey, needAES)
 	}
 
@@ -1442,8 +1605,14 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 
 	u := append(make([]byte, 32), b...)
 	upw := []byte(ctx.UserPW)
-	h := sha256.Sum256(append(upw, validationSalt(u)...))
-	ctx.E.U = append(h[:], b...)
+	if len(upw) > 127 {
+		upw = upw[:127]
+	}
+	h, err := hashAES256(upw, validationSalt(u), nil, ctx.E.R)
+	if err != nil {
+		return err
+	}
+	ctx.E.U = append(h, b...)
 	d.Update("U", HexLiteral(hex.EncodeToString(ctx.E.U)))
 
 	// 2) Calc O (depends on U).
@@ -1455,19 +1624,27 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 
 	o := append(make([]byte, 32), b...)
 	opw := []byte(ctx.OwnerPW)
-	c := append(opw, validationSalt(o)...)
-	h = sha256.Sum256(append(c, ctx.E.U...))
-	ctx.E.O = append(h[:], b...)
+	if len(opw) > 127 {
+		opw = opw[:127]
+	}
+	if h, err = hashAES256(opw, validationSalt(o), ctx.E.U, ctx.E.R); err != nil {
+		return err
+	}
+	ctx.E.O = append(h, b...)
 	d.Update("O", HexLiteral(hex.EncodeToString(ctx.E.O)))
 
-	err = calcFileEncKey(ctx, d)
-	if err != nil {
-		return err
+	// Rewrap an existing file encryption key.
+	if ctx.EncKey == nil {
+		if err = calcFileEncKey(ctx, d); err != nil {
+			return err
+		}
 	}
 
 	// Encrypt file encryption key into UE.
-	h = sha256.Sum256(append(upw, keySalt(u)...))
-	cb, err := aes.NewCipher(h[:])
+	if h, err = hashAES256(upw, keySalt(u), nil, ctx.E.R); err != nil {
+		return err
+	}
+	cb, err := aes.NewCipher(h)
 	if err != nil {
 		return err
 	}
@@ -1478,9 +1655,10 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 	d.Update("UE", HexLiteral(hex.EncodeToString(ctx.E.UE)))
 
 	// Encrypt file encryption key into OE.
-	c = append(opw, keySalt(o)...)
-	h = sha256.Sum256(append(c, ctx.E.U...))
-	cb, err = aes.NewCipher(h[:])
+	if h, err = hashAES256(opw, keySalt(o), ctx.E.U, ctx.E.R); err != nil {
+		return err
+	}
+	cb, err = aes.NewCipher(h)
 	if err != nil {
 		return err
 	}
@@ -1494,7 +1672,7 @@ func calcOAndUAES256(ctx *Context, d Dict) (err error) {
 
 func calcOAndU(ctx *Context, d Dict) (err error) {
 
-	if ctx.E.R == 5 {
+	if ctx.E.R >= 5 {
 		return calcOAndUAES256(ctx, d)
 	}
 
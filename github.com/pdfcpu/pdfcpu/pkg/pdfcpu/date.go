@@ -26,10 +26,14 @@ import (
 // DateString returns a string representation of t.
 func DateString(t time.Time) string {
 	_, tz := t.Zone()
-	return fmt.Sprintf("D:%d%02d%02d%02d%02d%02d+%02d'%02d'",
+	o := '+'
+	if tz < 0 {
+		o, tz = '-', -tz
+	}
+	return fmt.Sprintf("D:%d%02d%02d%02d%02d%02d%c%02d'%02d'",
 		t.Year(), t.Month(), t.Day(),
 		t.Hour(), t.Minute(), t.Second(),
-		tz/60/60, tz/60%60)
+		o, tz/60/60, tz/60%60)
 }
 
 func prevalidateDate(s string) (string, bool) {
@@ -129,6 +133,10 @@ func parseTimezone(s string) (h, m int, ok bool) {
 		return 0, 0, false
 	}
 
+	if neg {
+		tzm *= -1
+	}
+
 	return tzh, tzm, true
 }
 
@@ -361,3 +369,89 @@ func DateTime(s string) (time.Time, bool) {
 
 	return d, true
 }
+
+// lenientTimezone returns the timezone designator tz of a date string in the form "Z" or "OHH'mm'".
+// Accepted inputs are Z, OHH, OHHmm, OHH'mm, OHH'mm' and OHH:mm with O being + or -.
+func lenientTimezone(tz string) (string, bool) {
+	tz = strings.NewReplacer("'", "", ":", "").Replace(strings.TrimSpace(tz))
+
+	if tz == "" {
+		return "", true
+	}
+
+	if tz[0] == 'Z' && strings.Trim(tz[1:], "0") == "" {
+		return "Z", true
+	}
+
+	if tz[0] != '+' && tz[0] != '-' {
+		return "", false
+	}
+
+	o, tz := tz[:1], tz[1:]
+
+	if len(tz) == 1 {
+		// Single digit hour.
+		tz = "0" + tz
+	}
+	if len(tz) == 2 {
+		tz += "00"
+	}
+	if len(tz) != 4 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(tz); err != nil {
+		return "", false
+	}
+
+	return o + tz[:2] + "'" + tz[2:] + "'", true
+}
+
+// DateTimeLenient decodes s into a time.Time tolerating common deviations from 7.9.4 Dates
+// like a missing "D:" prefix, an incomplete or malformed timezone or dates in RFC 3339 format.
+func DateTimeLenient(s string) (time.Time, bool) {
+	if d, ok := DateTime(s); ok {
+		return d, true
+	}
+
+	if IsStringUTF16BE(s) {
+		utf16s, err := DecodeUTF16String(s)
+		if err != nil {
+			return time.Time{}, false
+		}
+		s = utf16s
+	}
+
+	s = strings.TrimSpace(s)
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d, true
+		}
+	}
+
+	s = strings.TrimPrefix(s, "D:")
+
+	i := 0
+	for i < len(s) && i < 14 && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	digits := s[:i]
+	if len(digits) < 4 || len(digits)%2 != 0 {
+		return time.Time{}, false
+	}
+
+	tz, ok := lenientTimezone(s[i:])
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if tz == "" {
+		return DateTime("D:" + digits)
+	}
+
+	// A timezone requires month, day, hour, minute and second.
+	digits += "0101000000"[len(digits)-4:]
+
+	return DateTime("D:" + digits + tz)
+}
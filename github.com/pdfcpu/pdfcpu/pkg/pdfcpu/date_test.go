@@ -16,7 +16,10 @@ limitations under the License.
 
 package pdfcpu
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func doParseDateTimeOK(s string, t *testing.T) {
 	t.Helper()
@@ -83,3 +86,74 @@ func TestDateTime(t *testing.T) {
 	s = "D:20170430155901+66'A9'"
 	doParseDateTimeFail(s, t)
 }
+
+func TestDateTimeTimezone(t *testing.T) {
+	for _, tt := range []struct {
+		s      string
+		offset int
+	}{
+		{"D:20170430155901Z", 0},
+		{"D:20170430155901+06'", 6 * 3600},
+		{"D:20170430155901+05'30'", 5*3600 + 30*60},
+		{"D:20170430155901-03'30'", -(3*3600 + 30*60)},
+		{"D:20170430155901-08'00'", -8 * 3600},
+	} {
+		d, ok := DateTime(tt.s)
+		if !ok {
+			t.Fatalf("DateTime(%s) invalid\n", tt.s)
+		}
+		if _, offset := d.Zone(); offset != tt.offset {
+			t.Errorf("DateTime(%s): want offset %d, got %d\n", tt.s, tt.offset, offset)
+		}
+		if d.Hour() != 15 || d.Minute() != 59 || d.Second() != 1 {
+			t.Errorf("DateTime(%s): unexpected local time %s\n", tt.s, d)
+		}
+	}
+}
+
+func TestDateStringRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 2 * 3600, 5*3600 + 45*60, -(3*3600 + 30*60), -11 * 3600} {
+		want := time.Date(2020, 2, 29, 23, 5, 7, 0, time.FixedZone("", offset))
+		s := DateString(want)
+		got, ok := DateTime(s)
+		if !ok {
+			t.Fatalf("DateTime(%s) invalid\n", s)
+		}
+		if !got.Equal(want) {
+			t.Errorf("DateString(%s) = %s: got %s\n", want, s, got)
+		}
+	}
+}
+
+func TestDateTimeLenient(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want string
+	}{
+		{"D:20170430155901+06'59'", "D:20170430155901+06'59'"},
+		{"20170430155901", "D:20170430155901+00'00'"},
+		{" D:20170430155901Z ", "D:20170430155901+00'00'"},
+		{"D:20170430155901+06'59", "D:20170430155901+06'59'"},
+		{"D:20170430155901-0730", "D:20170430155901-07'30'"},
+		{"D:20170430155901+05:30", "D:20170430155901+05'30'"},
+		{"D:20170430155901-8", "D:20170430155901-08'00'"},
+		{"D:20170430+02", "D:20170430000000+02'00'"},
+		{"2017-04-30T15:59:01-04:00", "D:20170430155901-04'00'"},
+		{"2017-04-30", "D:20170430000000+00'00'"},
+	} {
+		d, ok := DateTimeLenient(tt.s)
+		if !ok {
+			t.Errorf("DateTimeLenient(%s) invalid\n", tt.s)
+			continue
+		}
+		if got := DateString(d); got != tt.want {
+			t.Errorf("DateTimeLenient(%s): want %s, got %s\n", tt.s, tt.want, got)
+		}
+	}
+
+	for _, s := range []string{"", "yesterday", "D:201", "D:20170430155901+6'5A'", "D:20171330"} {
+		if d, ok := DateTimeLenient(s); ok {
+			t.Errorf("DateTimeLenient(%s) valid => not ok! %s\n", s, d)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+)
+
+// dedupDictTypes are the types of dicts qualifying for deduplication.
+// Any other dict like pages, annotations or optional content groups has an identity of its own.
+var dedupDictTypes = []string{"Font", "FontDescriptor", "ExtGState", "Encoding", "Pattern", "Halftone", "Group"}
+
+// dedupColorSpaceFamilies are the color space families of arrays qualifying for deduplication.
+var dedupColorSpaceFamilies = []string{"ICCBased", "Indexed", "Separation", "DeviceN", "CalRGB", "CalGray", "Lab", "Pattern"}
+
+func dedupCandidate(o Object) bool {
+
+	switch o := o.(type) {
+
+	case StreamDict:
+		t := o.Type()
+		return t == nil || *t != "XRef" && *t != "ObjStm"
+
+	case Dict:
+		if t := o.Type(); t != nil {
+			return MemberOf(*t, dedupDictTypes)
+		}
+		for _, k := range []string{"ShadingType", "FunctionType", "PatternType"} {
+			if _, found := o.Find(k); found {
+				return true
+			}
+		}
+
+	case Array:
+		if len(o) > 0 {
+			if n, ok := o[0].(Name); ok {
+				return MemberOf(n.Value(), dedupColorSpaceFamilies)
+			}
+		}
+
+	}
+
+	return false
+}
+
+// streamContentKey returns a hash of the decoded content of sd or of its raw content
+// if sd cannot be decoded, along with the dict entries to be excluded from comparison.
+func streamContentKey(sd StreamDict) (string, []string) {
+
+	sd.Content = nil
+	if err := sd.Decode(); err == nil {
+		return fmt.Sprintf("%x", sha256.Sum256(sd.Content)), []string{"Length", "Filter", "DecodeParms", "DL"}
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(sd.Raw)), []string{"Length"}
+}
+
+func dedupKey(o Object, contentKeys map[int]string, excludes map[int][]string, objNr int) string {
+
+	sd, ok := o.(StreamDict)
+	if !ok {
+		return fmt.Sprintf("%T%s", o, o.PDFString())
+	}
+
+	ck, found := contentKeys[objNr]
+	if !found {
+		ck, excludes[objNr] = streamContentKey(sd)
+		contentKeys[objNr] = ck
+	}
+
+	d := sd.Dict.Clone().(Dict)
+	for _, k := range excludes[objNr] {
+		d.Delete(k)
+	}
+
+	return "stream" + d.PDFString() + ck
+}
+
+// replaceIndRefs replaces references to the keys of m in o with the corresponding references.
+func replaceIndRefs(o Object, m map[int]IndirectRef) Object {
+
+	switch o := o.(type) {
+
+	case IndirectRef:
+		if ir, ok := m[o.ObjectNumber.Value()]; ok {
+			return ir
+		}
+
+	case Dict:
+		for k, v := range o {
+			o[k] = replaceIndRefs(v, m)
+		}
+
+	case StreamDict:
+		replaceIndRefs(o.Dict, m)
+
+	case Array:
+		for i, v := range o {
+			o[i] = replaceIndRefs(v, m)
+		}
+
+	}
+
+	return o
+}
+
+func objectSize(o Object) int64 {
+	if sd, ok := o.(StreamDict); ok {
+		return int64(len(sd.PDFString()) + len(sd.Raw))
+	}
+	return int64(len(o.PDFString()))
+}
+
+// deduplicateObjects collapses identical resource objects into one.
+// Streams get compared by decoded content, so differently compressed copies are identified as well.
+// Since merging objects may turn their referring objects into duplicates this repeats until nothing changes.
+// The numbers of objects and bytes eliminated are recorded in ctx.Optimize.
+func deduplicateObjects(ctx *Context) error {
+
+	log.Optimize.Println("deduplicateObjects begin")
+
+	var objNrs []int
+	for objNr, entry := range ctx.Table {
+		if objNr > 0 && !entry.Free && entry.Object != nil && dedupCandidate(entry.Object) {
+			objNrs = append(objNrs, objNr)
+		}
+	}
+	sort.Ints(objNrs)
+
+	contentKeys := map[int]string{}
+	excludes := map[int][]string{}
+	duplicates := IntSet{}
+
+	for {
+		canonical := map[string]int{}
+		m := map[int]IndirectRef{}
+
+		for _, objNr := range objNrs {
+			if duplicates[objNr] {
+				continue
+			}
+			o := ctx.Table[objNr].Object
+			k := dedupKey(o, contentKeys, excludes, objNr)
+			if c, ok := canonical[k]; ok {
+				m[objNr] = *NewIndirectRef(c, *ctx.Table[c].Generation)
+				duplicates[objNr] = true
+				ctx.Optimize.DeduplicatedObjs++
+				ctx.Optimize.DeduplicatedBytes += objectSize(o)
+				continue
+			}
+			canonical[k] = objNr
+		}
+
+		if len(m) == 0 {
+			break
+		}
+
+		log.Optimize.Printf("deduplicateObjects: %v\n", m)
+
+		for objNr, entry := range ctx.Table {
+			if objNr > 0 && !entry.Free && !duplicates[objNr] {
+				entry.Object = replaceIndRefs(entry.Object, m)
+			}
+		}
+	}
+
+	log.Info.Printf("deduplicating objects saved %d objects, %s (%d bytes)\n",
+		ctx.Optimize.DeduplicatedObjs, ByteSize(ctx.Optimize.DeduplicatedBytes), ctx.Optimize.DeduplicatedBytes)
+
+	log.Optimize.Println("deduplicateObjects end")
+
+	return nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff represents the differences between two documents A and B
+// regarding page count, page text, annotations and document info.
+type Diff struct {
+	PageCountA int
+	PageCountB int
+	Pages      []PageDiff   // differing pages in ascending order
+	Info       []InfoChange // differing document info entries
+}
+
+// PageDiff describes the differences between page Page of document A and page Page of document B.
+type PageDiff struct {
+	Page               int
+	Added              bool     // the page exists in B only
+	Removed            bool     // the page exists in A only
+	RemovedLines       []string // whitespace normalized text lines of A missing in B
+	AddedLines         []string // whitespace normalized text lines of B missing in A
+	RemovedAnnotations []AnnotationInfo
+	AddedAnnotations   []AnnotationInfo
+}
+
+// InfoChange describes a differing document info entry, an empty value stands for a missing entry.
+type InfoChange struct {
+	Key string
+	A   string
+	B   string
+}
+
+// Equal returns true if no differences have been found.
+func (d Diff) Equal() bool {
+	return d.PageCountA == d.PageCountB && len(d.Pages) == 0 && len(d.Info) == 0
+}
+
+// TextChanged returns true if the text of the page differs.
+func (pd PageDiff) TextChanged() bool {
+	return len(pd.RemovedLines) > 0 || len(pd.AddedLines) > 0
+}
+
+func (d Diff) String() string {
+
+	var sb strings.Builder
+
+	if d.PageCountA != d.PageCountB {
+		fmt.Fprintf(&sb, "page count: %d -> %d\n", d.PageCountA, d.PageCountB)
+	}
+
+	for _, ic := range d.Info {
+		fmt.Fprintf(&sb, "info %s: %q -> %q\n", ic.Key, ic.A, ic.B)
+	}
+
+	for _, pd := range d.Pages {
+		switch {
+		case pd.Added:
+			fmt.Fprintf(&sb, "page %d: added\n", pd.Page)
+		case pd.Removed:
+			fmt.Fprintf(&sb, "page %d: removed\n", pd.Page)
+		default:
+			fmt.Fprintf(&sb, "page %d: changed\n", pd.Page)
+		}
+		for _, s := range pd.RemovedLines {
+			fmt.Fprintf(&sb, "  - %s\n", s)
+		}
+		for _, s := range pd.AddedLines {
+			fmt.Fprintf(&sb, "  + %s\n", s)
+		}
+		for _, ai := range pd.RemovedAnnotations {
+			fmt.Fprintf(&sb, "  - %s\n", ai)
+		}
+		for _, ai := range pd.AddedAnnotations {
+			fmt.Fprintf(&sb, "  + %s\n", ai)
+		}
+	}
+
+	return sb.String()
+}
+
+// normalizedPageText returns the non empty text lines of page pageNr with whitespace collapsed.
+func (ctx *Context) normalizedPageText(pageNr int) ([]string, error) {
+
+	s, err := ctx.ExtractPageText(pageNr)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{}
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			ss = append(ss, l)
+		}
+	}
+
+	return ss, nil
+}
+
+// diffLines returns the lines of a missing in b and the lines of b missing in a
+// based on a longest common subsequence.
+func diffLines(a, b []string) (removed, added []string) {
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+
+	return append(removed, a[i:]...), append(added, b[j:]...)
+}
+
+// annotationKey identifies an annotation independently of its object number.
+func annotationKey(ai AnnotationInfo) string {
+	ai.IndRef = nil
+	return ai.String()
+}
+
+// diffAnnotations returns the annotations of aa missing in bb and the annotations of bb missing in aa.
+func diffAnnotations(aa, bb []AnnotationInfo) (removed, added []AnnotationInfo) {
+
+	count := map[string]int{}
+	for _, ai := range bb {
+		count[annotationKey(ai)]++
+	}
+
+	for _, ai := range aa {
+		k := annotationKey(ai)
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		removed = append(removed, ai)
+	}
+
+	for _, ai := range bb {
+		k := annotationKey(ai)
+		if count[k] > 0 {
+			count[k]--
+			added = append(added, ai)
+		}
+	}
+
+	return removed, added
+}
+
+func annotationsByPage(ctx *Context) (map[int][]AnnotationInfo, error) {
+
+	aa, err := ctx.ListAnnotations(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[int][]AnnotationInfo{}
+	for _, ai := range aa {
+		m[ai.Page] = append(m[ai.Page], ai)
+	}
+
+	return m, nil
+}
+
+func diffInfo(ctxA, ctxB *Context) ([]InfoChange, error) {
+
+	diA, err := ctxA.DocumentInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	diB, err := ctxB.DocumentInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	ii := []InfoChange{}
+	for _, e := range docInfoEntries {
+		a, b := value(*e.value(diA)), value(*e.value(diB))
+		if a != b {
+			ii = append(ii, InfoChange{Key: e.key, A: a, B: b})
+		}
+	}
+
+	return ii, nil
+}
+
+// Compare returns the differences between ctxA and ctxB page by page.
+// Text gets compared line by line with whitespace normalized, annotations by type, location, content and targets.
+func Compare(ctxA, ctxB *Context) (*Diff, error) {
+
+	for _, ctx := range []*Context{ctxA, ctxB} {
+		if err := ctx.EnsurePageCount(); err != nil {
+			return nil, err
+		}
+	}
+
+	d := &Diff{PageCountA: ctxA.PageCount, PageCountB: ctxB.PageCount, Pages: []PageDiff{}}
+
+	info, err := diffInfo(ctxA, ctxB)
+	if err != nil {
+		return nil, err
+	}
+	d.Info = info
+
+	annotsA, err := annotationsByPage(ctxA)
+	if err != nil {
+		return nil, err
+	}
+
+	annotsB, err := annotationsByPage(ctxB)
+	if err != nil {
+		return nil, err
+	}
+
+	n := ctxA.PageCount
+	if ctxB.PageCount > n {
+		n = ctxB.PageCount
+	}
+
+	for i := 1; i <= n; i++ {
+
+		pd := PageDiff{Page: i, Added: i > ctxA.PageCount, Removed: i > ctxB.PageCount}
+
+		var linesA, linesB []string
+
+		if !pd.Added {
+			if linesA, err = ctxA.normalizedPageText(i); err != nil {
+				return nil, err
+			}
+		}
+
+		if !pd.Removed {
+			if linesB, err = ctxB.normalizedPageText(i); err != nil {
+				return nil, err
+			}
+		}
+
+		pd.RemovedLines, pd.AddedLines = diffLines(linesA, linesB)
+		pd.RemovedAnnotations, pd.AddedAnnotations = diffAnnotations(annotsA[i], annotsB[i])
+
+		if pd.Added || pd.Removed || pd.TextChanged() || len(pd.RemovedAnnotations) > 0 || len(pd.AddedAnnotations) > 0 {
+			d.Pages = append(d.Pages, pd)
+		}
+	}
+
+	return d, nil
+}
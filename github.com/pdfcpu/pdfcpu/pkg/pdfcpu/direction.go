@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Reading directions, see 12.2 Table 150.
+const (
+	L2R = "L2R" // Left to right, the default.
+	R2L = "R2L" // Right to left, including vertical writing systems such as Chinese, Japanese and Korean.
+)
+
+// SetReadingDirection sets the predominant reading order of text, which also determines
+// the relative positioning of pages displayed side by side or printed n-up.
+// Right to left documents need R2L in order to display two-page spreads like their printed counterparts,
+// eg. for a right to left n-up layout using orientation ld or dl.
+func SetReadingDirection(ctx *Context, dir string) error {
+
+	if dir != L2R && dir != R2L {
+		return errors.Errorf("pdfcpu: invalid reading direction: %s, use L2R or R2L", dir)
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	if dir == L2R {
+		// L2R is the default.
+		return ctx.setViewerPreference(rootDict, "Direction", nil)
+	}
+
+	return ctx.setViewerPreference(rootDict, "Direction", Name(R2L))
+}
+
+// ReadingDirection returns the predominant reading order of text, either L2R or R2L.
+func ReadingDirection(ctx *Context) (string, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return "", err
+	}
+
+	d, err := ctx.DereferenceDict(rootDict["ViewerPreferences"])
+	if err != nil || d == nil {
+		return L2R, err
+	}
+
+	if dir := d.NameEntry("Direction"); dir != nil && *dir == R2L {
+		return R2L, nil
+	}
+
+	return L2R, nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pkg/errors"
+)
+
+// DocumentInfo represents the standard entries of the document info dict, see 14.3.3
+// A nil field leaves the entry untouched, an empty string removes it.
+type DocumentInfo struct {
+	Title    *string
+	Author   *string
+	Subject  *string
+	Keywords *string
+	Creator  *string // the application that created the original document
+	Producer *string // the application that converted the document to PDF
+}
+
+// docInfoEntry relates a document info dict entry to its XMP property, see XMP Specification Part 2
+type docInfoEntry struct {
+	key   string
+	value func(di *DocumentInfo) **string
+	ns    string // XMP namespace URI
+	name  string // XMP property name
+	array string // Alt, Seq or empty for simple properties
+}
+
+const (
+	nsDC  = "http://purl.org/dc/elements/1.1/"
+	nsPDF = "http://ns.adobe.com/pdf/1.3/"
+	nsXMP = "http://ns.adobe.com/xap/1.0/"
+	nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+)
+
+var docInfoEntries = []docInfoEntry{
+	{"Title", func(di *DocumentInfo) **string { return &di.Title }, nsDC, "title", "Alt"},
+	{"Author", func(di *DocumentInfo) **string { return &di.Author }, nsDC, "creator", "Seq"},
+	{"Subject", func(di *DocumentInfo) **string { return &di.Subject }, nsDC, "description", "Alt"},
+	{"Keywords", func(di *DocumentInfo) **string { return &di.Keywords }, nsPDF, "Keywords", ""},
+	{"Creator", func(di *DocumentInfo) **string { return &di.Creator }, nsXMP, "CreatorTool", ""},
+	{"Producer", func(di *DocumentInfo) **string { return &di.Producer }, nsPDF, "Producer", ""},
+}
+
+// DocumentInfo returns the standard entries of the document info dict of ctx.
+func (ctx *Context) DocumentInfo() (*DocumentInfo, error) {
+
+	di := &DocumentInfo{}
+
+	if ctx.Info == nil {
+		return di, nil
+	}
+
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || d == nil {
+		return di, err
+	}
+
+	for _, e := range docInfoEntries {
+		o, found := d.Find(e.key)
+		if !found {
+			continue
+		}
+		s, err := ctx.DereferenceText(o)
+		if err != nil {
+			return nil, err
+		}
+		*e.value(di) = &s
+	}
+
+	return di, nil
+}
+
+// catalogMetadata returns the decoded XMP metadata stream of the catalog or nil.
+func (ctx *Context) catalogMetadata() (*StreamDict, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := ctx.DereferenceStreamDict(rootDict["Metadata"])
+	if err != nil || sd == nil {
+		return nil, err
+	}
+
+	if err := sd.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return nil, errors.New("pdfcpu: unsupported filter: unable to decode XMP metadata")
+		}
+		return nil, err
+	}
+
+	return sd, nil
+}
+
+// SetDocumentInfo updates the document info dict of ctx with the non nil fields of di.
+// Any XMP metadata stream of the catalog gets updated accordingly.
+func (ctx *Context) SetDocumentInfo(di DocumentInfo) error {
+
+	if ctx.Info == nil {
+		ir, err := ctx.IndRefForNewObject(NewDict())
+		if err != nil {
+			return err
+		}
+		ctx.Info = ir
+	}
+
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.New("pdfcpu: SetDocumentInfo: corrupt info dict")
+	}
+
+	fields := map[string]*string{
+		"Title":    &ctx.Title,
+		"Author":   &ctx.Author,
+		"Subject":  &ctx.Subject,
+		"Keywords": &ctx.Keywords,
+		"Creator":  &ctx.Creator,
+		"Producer": &ctx.Producer,
+	}
+
+	for _, e := range docInfoEntries {
+		v := *e.value(&di)
+		if v == nil {
+			continue
+		}
+		*fields[e.key] = *v
+		if *v == "" {
+			d.Delete(e.key)
+			continue
+		}
+		d.Update(e.key, textString(*v))
+	}
+
+	sd, err := ctx.catalogMetadata()
+	if err != nil || sd == nil {
+		return err
+	}
+
+	b, err := updateXMPInfo(sd.Content, di)
+	if err != nil {
+		return err
+	}
+
+	sd.Content = b
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	return ctx.updateCatalogMetadata(sd)
+}
+
+// updateCatalogMetadata replaces the XMP metadata stream object of the catalog with sd.
+func (ctx *Context) updateCatalogMetadata(sd *StreamDict) error {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	ir, ok := rootDict["Metadata"].(IndirectRef)
+	if !ok {
+		// Streams must be indirect objects.
+		indRef, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			return err
+		}
+		rootDict["Metadata"] = *indRef
+		return nil
+	}
+
+	entry, found := ctx.FindTableEntryForIndRef(&ir)
+	if !found {
+		return errors.Errorf("pdfcpu: updateCatalogMetadata: missing obj#%d", ir.ObjectNumber)
+	}
+	entry.Object = *sd
+
+	return nil
+}
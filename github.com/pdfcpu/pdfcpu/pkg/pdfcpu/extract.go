@@ -18,7 +18,9 @@ package pdfcpu
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/pdfcpu/pdfcpu/pkg/filter"
@@ -156,66 +158,153 @@ func (ctx *Context) FontObjNrs(pageNr int) []int {
 	return objNrs
 }
 
-// ExtractFont extracts a font from font dict by objNr.
-func (ctx *Context) ExtractFont(objNr int) (*Font, error) {
-	fontObject := ctx.Optimize.FontObjects[objNr]
+// fontFile returns the font program of the font descriptor d along with its file type, see 9.9
+func (ctx *Context) fontFile(d Dict, fontName string) (*IndirectRef, string, error) {
 
-	// Only embedded fonts have binary data.
-	if !fontObject.Embedded() {
-		log.Debug.Printf("ExtractFont: ignoring obj#%d - non embedded font: %s\n", objNr, fontObject.FontName)
-		return nil, nil
+	if ir := d.IndirectRefEntry("FontFile"); ir != nil {
+		// Type 1 font program
+		return ir, "pfb", nil
 	}
 
-	d, err := fontDescriptor(ctx.XRefTable, fontObject.FontDict, objNr)
-	if err != nil {
-		return nil, err
+	if ir := d.IndirectRefEntry("FontFile2"); ir != nil {
+		// TrueType font program
+		return ir, "ttf", nil
 	}
 
-	if d == nil {
-		log.Debug.Printf("ExtractFont: ignoring obj#%d - no fontDescriptor available for font: %s\n", objNr, fontObject.FontName)
-		return nil, nil
+	ir := d.IndirectRefEntry("FontFile3")
+	if ir == nil {
+		return nil, "", nil
 	}
 
-	ir := fontDescriptorFontFileIndirectObjectRef(d)
-	if ir == nil {
-		log.Debug.Printf("ExtractFont: ignoring obj#%d - no font file available for font: %s\n", objNr, fontObject.FontName)
-		return nil, nil
+	sd, err := ctx.DereferenceStreamDict(*ir)
+	if err != nil || sd == nil {
+		return nil, "", err
 	}
 
-	var f *Font
+	switch st := sd.Subtype(); {
 
-	fontType := fontObject.SubType()
+	case st == nil:
+		log.Info.Printf("extractFontData: ignoring font file obj#%d - missing subtype - font: %s\n", ir.ObjectNumber, fontName)
 
-	switch fontType {
+	case *st == "OpenType":
+		return ir, "otf", nil
 
-	case "TrueType":
-		// ttf ... true type file
-		// ttc ... true type collection
-		sd, err := ctx.DereferenceStreamDict(*ir)
-		if err != nil {
-			return nil, err
+	case *st == "Type1C" || *st == "CIDFontType0C":
+		// Compact font format
+		return ir, "cff", nil
+
+	default:
+		log.Info.Printf("extractFontData: ignoring font file obj#%d - unsupported subtype %s - font: %s\n", ir.ObjectNumber, *st, fontName)
+	}
+
+	return nil, "", nil
+}
+
+// pfb wraps the decoded Type 1 font program of sd into the segments of a printer font binary.
+func pfb(sd *StreamDict) []byte {
+
+	b := sd.Content
+	segs := []int{}
+	for _, k := range []string{"Length1", "Length2", "Length3"} {
+		l := 0
+		if i := sd.IntEntry(k); i != nil && *i > 0 {
+			l = *i
 		}
-		if sd == nil {
-			return nil, errors.Errorf("extractFontData: corrupt font obj#%d for font: %s\n", objNr, fontObject.FontName)
+		segs = append(segs, l)
+	}
+
+	if segs[0]+segs[1] > len(b) {
+		// Corrupt lengths, keep the font program as is.
+		return b
+	}
+	segs[2] = len(b) - segs[0] - segs[1]
+
+	var buf bytes.Buffer
+	off := 0
+	for i, l := range segs {
+		if l == 0 {
+			continue
+		}
+		segType := byte(1) // ASCII
+		if i == 1 {
+			segType = 2 // binary
 		}
+		buf.Write([]byte{0x80, segType, byte(l), byte(l >> 8), byte(l >> 16), byte(l >> 24)})
+		buf.Write(b[off : off+l])
+		off += l
+	}
+	buf.Write([]byte{0x80, 3})
 
-		// Decode streamDict if used filter is supported only.
-		err = sd.Decode()
-		if err == filter.ErrUnsupportedFilter {
-			return nil, nil
+	return buf.Bytes()
+}
+
+// extractFont extracts a font program from font dict by objNr
+// and returns it along with the object number of its font file.
+func (ctx *Context) extractFont(objNr int) (*Font, int, error) {
+	fontObject := ctx.Optimize.FontObjects[objNr]
+
+	fontName := fontObject.FontName
+	if fontObject.Prefix != "" {
+		// Keep the subset tag.
+		fontName = fontObject.Prefix + "+" + fontName
+	}
+
+	var d Dict
+	if fontObject.SubType() != "Type3" {
+		var err error
+		if d, err = fontDescriptor(ctx.XRefTable, fontObject.FontDict, objNr); err != nil {
+			return nil, 0, err
 		}
-		if err != nil {
-			return nil, err
+	}
+
+	var (
+		ir       *IndirectRef
+		fileType string
+	)
+
+	if d != nil {
+		var err error
+		if ir, fileType, err = ctx.fontFile(d, fontName); err != nil {
+			return nil, 0, err
 		}
+	}
 
-		f = &Font{bytes.NewReader(sd.Content), fontObject.FontName, "ttf"}
+	if ir == nil {
+		log.CLI.Printf("warning: skipping non embedded font: %s\n", fontName)
+		return nil, 0, nil
+	}
 
-	default:
-		log.Info.Printf("extractFontData: ignoring obj#%d - unsupported fonttype %s -  font: %s\n", objNr, fontType, fontObject.FontName)
-		return nil, nil
+	sd, err := ctx.DereferenceStreamDict(*ir)
+	if err != nil {
+		return nil, 0, err
+	}
+	if sd == nil {
+		return nil, 0, errors.Errorf("extractFontData: corrupt font obj#%d for font: %s\n", objNr, fontName)
+	}
+
+	// Decode streamDict if used filter is supported only.
+	err = sd.Decode()
+	if err == filter.ErrUnsupportedFilter {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return f, nil
+	b := sd.Content
+	if fileType == "pfb" {
+		b = pfb(sd)
+	}
+
+	return &Font{bytes.NewReader(b), fontName, fileType}, ir.ObjectNumber.Value(), nil
+}
+
+// ExtractFont extracts the embedded font program of the font dict by objNr.
+// Type 1 fonts are extracted as pfb, TrueType fonts as ttf, OpenType fonts as otf and
+// compact fonts as cff files. The name of a subsetted font keeps its subset tag.
+func (ctx *Context) ExtractFont(objNr int) (*Font, error) {
+	f, _, err := ctx.extractFont(objNr)
+	return f, err
 }
 
 // ExtractPageFonts extracts all fonts used by pageNr.
@@ -233,6 +322,45 @@ func (ctx *Context) ExtractPageFonts(pageNr int) ([]Font, error) {
 	return ff, nil
 }
 
+// ExtractFonts extracts all fonts used by the selected pages.
+// Font programs shared by several fonts or pages are extracted once.
+// Requires an optimized context.
+func (ctx *Context) ExtractFonts(selectedPages IntSet) ([]Font, error) {
+	ff := []Font{}
+	fonts, fontFiles := IntSet{}, IntSet{}
+	names := map[string]bool{}
+
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		if len(selectedPages) > 0 && !selectedPages[pageNr] {
+			continue
+		}
+		objNrs := ctx.FontObjNrs(pageNr)
+		sort.Ints(objNrs)
+		for _, objNr := range objNrs {
+			if fonts[objNr] {
+				continue
+			}
+			fonts[objNr] = true
+			f, fontFileObjNr, err := ctx.extractFont(objNr)
+			if err != nil {
+				return nil, err
+			}
+			if f == nil || fontFiles[fontFileObjNr] {
+				continue
+			}
+			fontFiles[fontFileObjNr] = true
+			if names[f.Name+"."+f.Type] {
+				// Distinct font programs sharing a font name.
+				f.Name = fmt.Sprintf("%s_%d", f.Name, fontFileObjNr)
+			}
+			names[f.Name+"."+f.Type] = true
+			ff = append(ff, *f)
+		}
+	}
+
+	return ff, nil
+}
+
 // ExtractPage extracts pageNr into a new single page context.
 func (ctx *Context) ExtractPage(pageNr int) (*Context, error) {
 	return ctx.ExtractPages([]int{pageNr}, false)
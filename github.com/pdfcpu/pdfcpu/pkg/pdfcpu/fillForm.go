@@ -0,0 +1,547 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// choiceOptions returns the export values of the options of the choice field f, see 12.7.4.4
+func (xRefTable *XRefTable) choiceOptions(f *formField) ([]string, error) {
+
+	o, found := f.Dict.Find("Opt")
+	if !found {
+		return nil, nil
+	}
+
+	a, err := xRefTable.DereferenceArray(o)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{}
+	for _, o := range a {
+		o, err := xRefTable.Dereference(o)
+		if err != nil {
+			return nil, err
+		}
+		if a1, ok := o.(Array); ok {
+			// [export value, display text]
+			if len(a1) == 0 {
+				continue
+			}
+			o = a1[0]
+		}
+		s, err := xRefTable.DereferenceText(o)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+
+	return ss, nil
+}
+
+// buttonState returns the appearance state of the check box or radio button f for v or an error if v is invalid.
+// Check boxes accept "on", "true", "off", "false" and their on state,
+// radio buttons accept the on state or export value of one of their widgets and "off".
+func (xRefTable *XRefTable) buttonState(f *formField, v string) (string, error) {
+
+	switch strings.ToLower(v) {
+	case "off", "false":
+		return "Off", nil
+	case "on", "true":
+		if f.Ff&FieldRadio > 0 || len(f.Widgets) == 0 {
+			break
+		}
+		return xRefTable.widgetOnState(f.Widgets[0])
+	}
+
+	var opt Array
+	if o, found := f.Dict.Find("Opt"); found {
+		a, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return "", err
+		}
+		opt = a
+	}
+
+	for i, wd := range f.Widgets {
+		on, err := xRefTable.widgetOnState(wd)
+		if err != nil {
+			return "", err
+		}
+		if on == v {
+			return on, nil
+		}
+		if i < len(opt) {
+			if s, err := xRefTable.DereferenceText(opt[i]); err == nil && s == v {
+				return on, nil
+			}
+		}
+	}
+
+	return "", errors.Errorf("pdfcpu: form field %s: invalid button value: %q", f.Name, v)
+}
+
+// fieldValueObject returns the value object for setting the field f to vv.
+// Only multiple selection list boxes take more than one value.
+func (xRefTable *XRefTable) fieldValueObject(f *formField, vv []string) (Object, error) {
+
+	multiSelect := f.FT == "Ch" && f.Ff&FieldMultiSelect > 0
+
+	if len(vv) > 1 && !multiSelect {
+		return nil, errors.Errorf("pdfcpu: form field %s: takes a single value", f.Name)
+	}
+
+	var v string
+	if len(vv) > 0 {
+		v = vv[0]
+	}
+
+	switch f.FT {
+
+	case "Tx":
+		if i := f.Dict.IntEntry("MaxLen"); i != nil && len([]rune(v)) > *i {
+			return nil, errors.Errorf("pdfcpu: form field %s: value exceeds MaxLen %d", f.Name, *i)
+		}
+		return textString(v), nil
+
+	case "Btn":
+		if f.Ff&FieldPushbutton > 0 {
+			break
+		}
+		s, err := xRefTable.buttonState(f, v)
+		if err != nil {
+			return nil, err
+		}
+		return Name(s), nil
+
+	case "Ch":
+		var ss []string
+		switch {
+		case multiSelect:
+			ss = vv
+		case v != "":
+			ss = []string{v}
+		}
+		if f.Ff&FieldEdit == 0 {
+			opts, err := xRefTable.choiceOptions(f)
+			if err != nil {
+				return nil, err
+			}
+			if len(opts) > 0 {
+				for _, s := range ss {
+					if !MemberOf(s, opts) {
+						return nil, errors.Errorf("pdfcpu: form field %s: invalid option: %q", f.Name, s)
+					}
+				}
+			}
+		}
+		if multiSelect {
+			a := Array{}
+			for _, s := range ss {
+				a = append(a, textString(s))
+			}
+			return a, nil
+		}
+		return textString(v), nil
+
+	}
+
+	return nil, errors.Errorf("pdfcpu: form field %s: unsupported field type", f.Name)
+}
+
+// normalAppearance returns the normal appearance of the widget wd for its current appearance state or nil.
+func (xRefTable *XRefTable) normalAppearance(wd Dict) (*IndirectRef, error) {
+
+	ap, err := xRefTable.DereferenceDict(wd["AP"])
+	if err != nil || ap == nil {
+		return nil, err
+	}
+
+	o, found := ap.Find("N")
+	if !found {
+		return nil, nil
+	}
+
+	if ir, ok := o.(IndirectRef); ok {
+		o1, err := xRefTable.Dereference(ir)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := o1.(StreamDict); ok {
+			return &ir, nil
+		}
+		o = o1
+	}
+
+	d, ok := o.(Dict)
+	if !ok {
+		return nil, nil
+	}
+
+	as := wd.NameEntry("AS")
+	if as == nil {
+		return nil, nil
+	}
+
+	if ir, ok := d[*as].(IndirectRef); ok {
+		return &ir, nil
+	}
+
+	return nil, nil
+}
+
+// flattenWidget returns the content drawing the normal appearance of the widget wd onto the page using resDict, see 12.5.5
+func (ag *appearanceGenerator) flattenWidget(wd, resDict Dict) ([]byte, error) {
+
+	// Hidden or not to be displayed.
+	if f := wd.IntEntry("F"); f != nil && *f&(1<<1|1<<5) > 0 {
+		return nil, nil
+	}
+
+	ir, err := ag.xRefTable.normalAppearance(wd)
+	if err != nil || ir == nil {
+		return nil, err
+	}
+
+	sd, err := ag.xRefTable.DereferenceStreamDict(*ir)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+
+	a, err := ag.xRefTable.DereferenceArray(sd.Dict["BBox"])
+	if err != nil || len(a) != 4 {
+		return nil, err
+	}
+	bb, err := rect(ag.xRefTable, a)
+	if err != nil {
+		return nil, err
+	}
+
+	m := identMatrix
+	if a, err := ag.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		ff := make([]float64, 6)
+		for i, o := range a {
+			ff[i] = ag.xRefTable.number(o)
+		}
+		m = matrixForNumbers(ff)
+	}
+
+	// The transformed appearance box gets mapped onto the annotation rectangle.
+	var tb *Rectangle
+	for _, p := range [][2]float64{{bb.LL.X, bb.LL.Y}, {bb.UR.X, bb.LL.Y}, {bb.LL.X, bb.UR.Y}, {bb.UR.X, bb.UR.Y}} {
+		q := m.transform(p[0], p[1])
+		tb = unionRect(tb, Rect(q.X, q.Y, q.X, q.Y))
+	}
+	if tb.Width() == 0 || tb.Height() == 0 {
+		return nil, nil
+	}
+
+	r, err := ag.rect(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	sx, sy := r.Width()/tb.Width(), r.Height()/tb.Height()
+
+	d, err := ag.xRefTable.DereferenceDict(resDict["XObject"])
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		d = Dict{}
+		resDict.Update("XObject", d)
+	}
+
+	var id string
+	for i := 0; ; i++ {
+		id = fmt.Sprintf("Fm%d", i)
+		if _, found := d.Find(id); !found {
+			break
+		}
+	}
+	d.Insert(id, *ir)
+
+	return []byte(fmt.Sprintf("q %.4f 0 0 %.4f %.4f %.4f cm /%s Do Q\n",
+		sx, sy, r.LL.X-tb.LL.X*sx, r.LL.Y-tb.LL.Y*sy, id)), nil
+}
+
+// flattenPage draws the widgets of page pageNr whose object numbers are in objNrs onto the page content
+// and removes them from the page annotations.
+func (ag *appearanceGenerator) flattenPage(pageNr int, objNrs IntSet) error {
+
+	d, inhPAttrs, err := ag.xRefTable.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+
+	o, found := d.Find("Annots")
+	if !found {
+		return nil
+	}
+
+	annots, err := ag.xRefTable.DereferenceArray(o)
+	if err != nil {
+		return err
+	}
+
+	var resDict Dict
+
+	a := Array{}
+	buf := new(bytes.Buffer)
+
+	for _, o := range annots {
+
+		ir, ok := o.(IndirectRef)
+		if !ok || !objNrs[ir.ObjectNumber.Value()] {
+			a = append(a, o)
+			continue
+		}
+
+		wd, err := ag.xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+
+		if resDict == nil {
+			if resDict, err = ag.xRefTable.DereferenceDict(d["Resources"]); err != nil {
+				return err
+			}
+			if resDict == nil {
+				// Don't touch inherited resources.
+				resDict = Dict{}
+				if inhPAttrs.resources != nil {
+					resDict = inhPAttrs.resources.Clone().(Dict)
+				}
+				d.Update("Resources", resDict)
+			}
+		}
+
+		bb, err := ag.flattenWidget(wd, resDict)
+		if err != nil {
+			return err
+		}
+		buf.Write(bb)
+	}
+
+	if len(a) == len(annots) {
+		return nil
+	}
+
+	if len(a) == 0 {
+		d.Delete("Annots")
+	} else {
+		d.Update("Annots", a)
+	}
+
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	return ag.xRefTable.appendPageContent(d, buf.Bytes())
+}
+
+// removeArrayRef returns a without any reference to objNr.
+func removeArrayRef(a Array, objNr int) Array {
+	a1 := Array{}
+	for _, o := range a {
+		if ir, ok := o.(IndirectRef); ok && ir.ObjectNumber.Value() == objNr {
+			continue
+		}
+		a1 = append(a1, o)
+	}
+	return a1
+}
+
+// removeField removes the field d referenced by ir from the field tree of acroForm
+// along with any ancestors left without kids.
+func (xRefTable *XRefTable) removeField(acroForm Dict, d Dict, ir IndirectRef) error {
+
+	objNr := ir.ObjectNumber.Value()
+
+	o, found := d.Find("Parent")
+	if !found {
+		a, err := xRefTable.DereferenceArray(acroForm["Fields"])
+		if err != nil {
+			return err
+		}
+		acroForm.Update("Fields", removeArrayRef(a, objNr))
+		return nil
+	}
+
+	pir, ok := o.(IndirectRef)
+	if !ok {
+		return nil
+	}
+
+	pd, err := xRefTable.DereferenceDict(pir)
+	if err != nil || pd == nil {
+		return err
+	}
+
+	kids := removeArrayRef(pd.ArrayEntry("Kids"), objNr)
+	if len(kids) > 0 {
+		pd.Update("Kids", kids)
+		return nil
+	}
+
+	return xRefTable.removeField(acroForm, pd, pir)
+}
+
+// flattenFields draws the widgets of fields onto the page content and removes fields and widgets from the document.
+// The interactive form gets removed once it is left without fields.
+func (ag *appearanceGenerator) flattenFields(acroForm Dict, fields []*formField) error {
+
+	objNrs := IntSet{}
+	for _, f := range fields {
+		for _, ir := range f.Refs {
+			if ir != nil {
+				objNrs[ir.ObjectNumber.Value()] = true
+			}
+		}
+	}
+
+	for i := 1; i <= ag.xRefTable.PageCount; i++ {
+		if err := ag.flattenPage(i, objNrs); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range fields {
+		if f.IndRef == nil {
+			continue
+		}
+		if err := ag.xRefTable.removeField(acroForm, f.Dict, *f.IndRef); err != nil {
+			return err
+		}
+	}
+
+	a, err := ag.xRefTable.DereferenceArray(acroForm["Fields"])
+	if err != nil {
+		return err
+	}
+	if len(a) > 0 {
+		return nil
+	}
+
+	rootDict, err := ag.xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+	rootDict.Delete("AcroForm")
+
+	return nil
+}
+
+// FillForm sets the values of the text, check box, radio button and choice fields named by the keys of values
+// and regenerates their appearances.
+// Fields are identified by their fully qualified field names.
+// The items to be selected in multiple selection list boxes are joined by ",".
+// If flatten is true the filled fields get converted to static page content and their widgets get removed.
+func (ctx *Context) FillForm(values map[string]string, flatten bool) error {
+	m := make(map[string][]string, len(values))
+	for k, v := range values {
+		m[k] = []string{v}
+	}
+	return ctx.fillForm(m, true, flatten)
+}
+
+// fillForm sets the values of the fields named by the keys of values.
+// If splitMultiSelect is true the single value given for a multiple selection list box holds its items joined by ",".
+func (ctx *Context) fillForm(values map[string][]string, splitMultiSelect, flatten bool) error {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	acroForm, err := ctx.acroForm()
+	if err != nil {
+		return err
+	}
+
+	fields, err := ctx.formFields()
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]*formField{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	unknown := []string{}
+	for name := range values {
+		if _, ok := byName[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.Errorf("pdfcpu: unknown form fields: %s", strings.Join(unknown, ", "))
+	}
+
+	// Validate all values before modifying the form.
+	vv := map[*formField]Object{}
+	for name, ss := range values {
+		f := byName[name]
+		if splitMultiSelect && f.FT == "Ch" && f.Ff&FieldMultiSelect > 0 && len(ss) == 1 {
+			if ss[0] == "" {
+				ss = nil
+			} else {
+				ss = strings.Split(ss[0], ",")
+			}
+		}
+		o, err := ctx.fieldValueObject(f, ss)
+		if err != nil {
+			return err
+		}
+		vv[f] = o
+	}
+
+	ag, err := ctx.newAppearanceGenerator(acroForm)
+	if err != nil {
+		return err
+	}
+
+	filled := []*formField{}
+
+	for _, f := range fields {
+		o, ok := vv[f]
+		if !ok {
+			continue
+		}
+		f.V = o
+		f.Dict.Update("V", o)
+		if err := ag.fieldAppearances(f); err != nil {
+			return err
+		}
+		filled = append(filled, f)
+	}
+
+	if !flatten {
+		return nil
+	}
+
+	return ag.flattenFields(acroForm, filled)
+}
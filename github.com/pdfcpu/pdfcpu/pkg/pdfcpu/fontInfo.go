@@ -0,0 +1,224 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FontInfo describes a font used by the pages of a document.
+type FontInfo struct {
+	Name     string // base font name including a subset tag
+	Type     string // font subtype, eg. Type1, TrueType, Type0, Type3
+	Embedded bool   // the font program is part of the document
+	Subset   bool   // the font program is restricted to the glyphs used
+	Encoding string // eg. WinAnsiEncoding, Identity-H, Built-in, Custom
+	Pages    []int  // pages using this font in ascending order
+	ObjNr    int    // object number of the font dict or 0 for direct font dicts
+}
+
+func (fi FontInfo) String() string {
+	pp := make([]string, len(fi.Pages))
+	for i, p := range fi.Pages {
+		pp[i] = fmt.Sprintf("%d", p)
+	}
+	return fmt.Sprintf("%s %s embedded:%t subset:%t encoding:%s pages:%s",
+		fi.Name, fi.Type, fi.Embedded, fi.Subset, fi.Encoding, strings.Join(pp, ","))
+}
+
+// isSubsetFontName returns true if the PostScript name s starts with a subset tag like ABCDEF+, see 9.6.4
+func isSubsetFontName(s string) bool {
+	if len(s) < 8 || s[6] != '+' {
+		return false
+	}
+	for _, c := range s[:6] {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// fontEmbedded returns true if the font program of fontDict is part of the document.
+func (xRefTable *XRefTable) fontEmbedded(fontDict Dict, objNr int) (bool, error) {
+
+	if st := fontDict.Subtype(); st != nil && *st == "Type3" {
+		// Glyphs are described by content streams.
+		return true, nil
+	}
+
+	d, err := fontDescriptor(xRefTable, fontDict, objNr)
+	if err != nil || d == nil {
+		return false, err
+	}
+
+	for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		if _, found := d.Find(k); found {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (xRefTable *XRefTable) fontInfo(fontDict Dict, objNr int) (*FontInfo, error) {
+
+	fi := &FontInfo{ObjNr: objNr, Encoding: FontObject{FontDict: fontDict}.Encoding()}
+
+	if st := fontDict.Subtype(); st != nil {
+		fi.Type = *st
+	}
+
+	if n := fontDict.NameEntry("BaseFont"); n != nil {
+		fi.Name = *n
+	} else if n := fontDict.NameEntry("Name"); n != nil {
+		fi.Name = *n
+	} else {
+		fi.Name = fmt.Sprintf("%s_%d", fi.Type, objNr)
+	}
+	fi.Subset = isSubsetFontName(fi.Name)
+
+	embedded, err := xRefTable.fontEmbedded(fontDict, objNr)
+	if err != nil {
+		return nil, err
+	}
+	fi.Embedded = embedded
+
+	return fi, nil
+}
+
+// collectFonts registers the fonts of the resource dict o and of the resources of its form XObjects for pageNr.
+func (xRefTable *XRefTable) collectFonts(o Object, pageNr int, ff map[string]*FontInfo, visited IntSet) error {
+
+	resDict, err := xRefTable.DereferenceDict(o)
+	if err != nil || resDict == nil {
+		return err
+	}
+
+	fontResDict, err := xRefTable.DereferenceDict(resDict["Font"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range fontResDict {
+
+		var objNr int
+		if ir, ok := o.(IndirectRef); ok {
+			objNr = ir.ObjectNumber.Value()
+		}
+
+		fontDict, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if fontDict == nil {
+			continue
+		}
+
+		k := fmt.Sprintf("%d", objNr)
+		if objNr == 0 {
+			// Direct font dicts are considered identical if they share name and type.
+			k = fontDict.String()
+			if n := fontDict.NameEntry("BaseFont"); n != nil {
+				k = *n
+			}
+			if st := fontDict.Subtype(); st != nil {
+				k += "/" + *st
+			}
+		}
+
+		fi, ok := ff[k]
+		if !ok {
+			if fi, err = xRefTable.fontInfo(fontDict, objNr); err != nil {
+				return err
+			}
+			ff[k] = fi
+		}
+
+		if len(fi.Pages) == 0 || fi.Pages[len(fi.Pages)-1] != pageNr {
+			fi.Pages = append(fi.Pages, pageNr)
+		}
+	}
+
+	xObjResDict, err := xRefTable.DereferenceDict(resDict["XObject"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range xObjResDict {
+
+		ir, ok := o.(IndirectRef)
+		if !ok || visited[ir.ObjectNumber.Value()] {
+			continue
+		}
+		visited[ir.ObjectNumber.Value()] = true
+
+		sd, err := xRefTable.DereferenceStreamDict(ir)
+		if err != nil {
+			return err
+		}
+		if sd == nil || sd.Subtype() == nil || *sd.Subtype() != "Form" {
+			continue
+		}
+
+		if err := xRefTable.collectFonts(sd.Dict["Resources"], pageNr, ff, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListFonts returns the fonts used by the pages of ctx, each distinct font once along with its pages.
+// Fonts are collected from the page resources including the resources of form XObjects
+// without parsing any content streams.
+func (ctx *Context) ListFonts() ([]FontInfo, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ff := map[string]*FontInfo{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+
+		_, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ctx.collectFonts(inhPAttrs.resources, i, ff, IntSet{}); err != nil {
+			return nil, err
+		}
+	}
+
+	fonts := []FontInfo{}
+	for _, fi := range ff {
+		fonts = append(fonts, *fi)
+	}
+
+	sort.Slice(fonts, func(i, j int) bool {
+		if fonts[i].Name != fonts[j].Name {
+			return fonts[i].Name < fonts[j].Name
+		}
+		return fonts[i].ObjNr < fonts[j].ObjNr
+	})
+
+	return fonts, nil
+}
@@ -0,0 +1,634 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Field flags, see 12.7.3.1 Table 221, 12.7.4.2.1 Table 226, 12.7.4.3 Table 228, 12.7.4.4 Table 230
+const (
+	FieldReadOnly        = 1
+	FieldRequired        = 1 << 1
+	FieldNoExport        = 1 << 2
+	FieldMultiline       = 1 << 12
+	FieldPassword        = 1 << 13
+	FieldNoToggleToOff   = 1 << 14
+	FieldRadio           = 1 << 15
+	FieldPushbutton      = 1 << 16
+	FieldCombo           = 1 << 17
+	FieldEdit            = 1 << 18
+	FieldSort            = 1 << 19
+	FieldFileSelect      = 1 << 20
+	FieldMultiSelect     = 1 << 21
+	FieldDoNotSpellCheck = 1 << 22
+	FieldDoNotScroll     = 1 << 23
+	FieldComb            = 1 << 24
+)
+
+// formField represents a terminal field of an interactive form
+// together with its inheritable attributes resolved.
+type formField struct {
+	Dict    Dict
+	IndRef  *IndirectRef
+	Name    string // fully qualified field name
+	FT      string
+	Ff      int
+	V       Object
+	DA      string
+	Q       int
+	Widgets []Dict
+	Refs    []*IndirectRef // indirect references of Widgets or nil for direct widget dicts
+}
+
+// inheritedFieldAttrs holds the inheritable field attributes, see 12.7.3.1 and 12.7.3.3.
+type inheritedFieldAttrs struct {
+	ft string
+	ff int
+	v  Object
+	da string
+	q  int
+}
+
+// acroForm returns the document's interactive form dict or nil.
+func (xRefTable *XRefTable) acroForm() (Dict, error) {
+
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := rootDict.Find("AcroForm")
+	if !found {
+		return nil, nil
+	}
+
+	return xRefTable.DereferenceDict(o)
+}
+
+// isTerminalField returns true if the kids of d are widget annotations only.
+func (xRefTable *XRefTable) isTerminalField(kids Array) (bool, error) {
+
+	for _, o := range kids {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return false, err
+		}
+		if d == nil {
+			continue
+		}
+		if _, found := d.Find("T"); found {
+			return false, nil
+		}
+		if _, found := d.Find("Kids"); found {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (xRefTable *XRefTable) inheritFieldAttrs(d Dict, parent inheritedFieldAttrs) (inheritedFieldAttrs, error) {
+
+	attrs := parent
+
+	if ft := d.NameEntry("FT"); ft != nil {
+		attrs.ft = *ft
+	}
+
+	if ff := d.IntEntry("Ff"); ff != nil {
+		attrs.ff = *ff
+	}
+
+	if o, found := d.Find("V"); found {
+		o, err := xRefTable.Dereference(o)
+		if err != nil {
+			return attrs, err
+		}
+		attrs.v = o
+	}
+
+	if o, found := d.Find("DA"); found {
+		s, err := xRefTable.DereferenceText(o)
+		if err != nil {
+			return attrs, err
+		}
+		attrs.da = s
+	}
+
+	if q := d.IntEntry("Q"); q != nil {
+		attrs.q = *q
+	}
+
+	return attrs, nil
+}
+
+func (xRefTable *XRefTable) collectFormFields(o Object, parentName string, parent inheritedFieldAttrs, fields *[]*formField) error {
+
+	var indRef *IndirectRef
+	if ir, ok := o.(IndirectRef); ok {
+		indRef = &ir
+
+		// Skip field dicts closing a reference cycle via /Kids.
+		ok, err := xRefTable.EnterObject(ir, "collectFormFields")
+		if err != nil || !ok {
+			return err
+		}
+		defer xRefTable.LeaveObject(ir)
+	}
+
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return nil
+	}
+
+	attrs, err := xRefTable.inheritFieldAttrs(d, parent)
+	if err != nil {
+		return err
+	}
+
+	name := parentName
+	if o, found := d.Find("T"); found {
+		s, err := xRefTable.DereferenceText(o)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			name += "."
+		}
+		name += s
+	}
+
+	kids := d.ArrayEntry("Kids")
+
+	terminal, err := xRefTable.isTerminalField(kids)
+	if err != nil {
+		return err
+	}
+
+	if !terminal {
+		for _, o := range kids {
+			if err := xRefTable.collectFormFields(o, name, attrs, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f := &formField{
+		Dict:   d,
+		IndRef: indRef,
+		Name:   name,
+		FT:     attrs.ft,
+		Ff:     attrs.ff,
+		V:      attrs.v,
+		DA:     attrs.da,
+		Q:      attrs.q,
+	}
+
+	if len(kids) == 0 {
+		// Merged field and widget annotation dict.
+		f.Widgets = []Dict{d}
+		f.Refs = []*IndirectRef{indRef}
+	}
+
+	for _, o := range kids {
+		wd, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if wd != nil {
+			var ir *IndirectRef
+			if ir1, ok := o.(IndirectRef); ok {
+				ir = &ir1
+			}
+			f.Widgets = append(f.Widgets, wd)
+			f.Refs = append(f.Refs, ir)
+		}
+	}
+
+	*fields = append(*fields, f)
+
+	return nil
+}
+
+// widgetOnState returns the name of the on state of a check box or radio button widget.
+// A set /AS wins, otherwise the first non Off normal appearance state in sort order is used.
+func (xRefTable *XRefTable) widgetOnState(wd Dict) (string, error) {
+
+	if as := wd.NameEntry("AS"); as != nil && *as != "Off" {
+		return *as, nil
+	}
+
+	if o, found := wd.Find("AP"); found {
+		ap, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return "", err
+		}
+		if ap != nil {
+			if o, found := ap.Find("N"); found {
+				o, err := xRefTable.Dereference(o)
+				if err != nil {
+					return "", err
+				}
+				if d, ok := o.(Dict); ok {
+					var states []string
+					for k := range d {
+						if k != "Off" {
+							states = append(states, k)
+						}
+					}
+					if len(states) > 0 {
+						sort.Strings(states)
+						return states[0], nil
+					}
+				}
+			}
+		}
+	}
+
+	return "Yes", nil
+}
+
+// formFields returns all terminal fields of the document's interactive form in field tree order.
+func (xRefTable *XRefTable) formFields() ([]*formField, error) {
+
+	d, err := xRefTable.acroForm()
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	o, found := d.Find("Fields")
+	if !found {
+		return nil, nil
+	}
+
+	a, err := xRefTable.DereferenceArray(o)
+	if err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: formFields")
+	}
+
+	// Document wide defaults.
+	var attrs inheritedFieldAttrs
+	if o, found := d.Find("DA"); found {
+		if attrs.da, err = xRefTable.DereferenceText(o); err != nil {
+			return nil, err
+		}
+	}
+	if q := d.IntEntry("Q"); q != nil {
+		attrs.q = *q
+	}
+
+	fields := []*formField{}
+
+	for _, o := range a {
+		if err := xRefTable.collectFormFields(o, "", attrs, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+// buttonValue returns the export value of the selected check box or radio button widget of f or "".
+func (xRefTable *XRefTable) buttonValue(f *formField) (string, error) {
+
+	n, ok := f.V.(Name)
+	if !ok || n.Value() == "Off" {
+		return "", nil
+	}
+	v := n.Value()
+
+	// Export values may be provided by Opt, one per widget, see 12.7.4.2.3
+	var opt Array
+	if o, found := f.Dict.Find("Opt"); found {
+		a, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return "", err
+		}
+		opt = a
+	}
+
+	for i, wd := range f.Widgets {
+		on, err := xRefTable.widgetOnState(wd)
+		if err != nil {
+			return "", err
+		}
+		if on != v {
+			continue
+		}
+		if i < len(opt) {
+			return xRefTable.DereferenceText(opt[i])
+		}
+		return v, nil
+	}
+
+	if len(f.Widgets) > 0 {
+		// None of the widgets is selected.
+		return "", nil
+	}
+
+	return v, nil
+}
+
+// fieldValue returns a string representation of the value of f.
+func (xRefTable *XRefTable) fieldValue(f *formField) (string, error) {
+
+	switch f.FT {
+
+	case "Btn":
+		return xRefTable.buttonValue(f)
+
+	case "Tx", "Ch":
+		switch v := f.V.(type) {
+		case StringLiteral, HexLiteral:
+			return Text(v)
+		case Name:
+			return v.Value(), nil
+		case Array:
+			// Multiple selection list box.
+			ss, err := xRefTable.selectedItems(f)
+			if err != nil {
+				return "", err
+			}
+			return strings.Join(ss, ","), nil
+		}
+
+	}
+
+	return "", nil
+}
+
+// selectedItems returns the selected items of the choice field f.
+func (xRefTable *XRefTable) selectedItems(f *formField) ([]string, error) {
+
+	a, ok := f.V.(Array)
+	if !ok {
+		v, err := xRefTable.fieldValue(f)
+		if err != nil || v == "" {
+			return []string{}, err
+		}
+		return []string{v}, nil
+	}
+
+	ss := make([]string, len(a))
+	for i, o := range a {
+		s, err := xRefTable.DereferenceText(o)
+		if err != nil {
+			return nil, err
+		}
+		ss[i] = s
+	}
+
+	return ss, nil
+}
+
+// FormValues returns the values of all terminal form fields keyed by their fully qualified field names.
+// Check boxes and radio buttons return their selected export value or "".
+// The selected items of multiple selection list boxes are joined by ",".
+// Signature fields are skipped.
+func (ctx *Context) FormValues() (map[string]string, error) {
+
+	fields, err := ctx.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]string{}
+
+	for _, f := range fields {
+		if f.FT == "Sig" {
+			continue
+		}
+		if f.FT == "Btn" && f.Ff&FieldPushbutton > 0 {
+			continue
+		}
+		v, err := ctx.fieldValue(f)
+		if err != nil {
+			return nil, err
+		}
+		m[f.Name] = v
+	}
+
+	return m, nil
+}
+
+// FieldFlags represents the field flags common to all field types, see 12.7.3.1 Table 221
+type FieldFlags struct {
+	ReadOnly bool
+	Required bool
+	NoExport bool
+}
+
+func (ff FieldFlags) apply(i int) int {
+	for _, f := range []struct {
+		on  bool
+		bit int
+	}{
+		{ff.ReadOnly, FieldReadOnly},
+		{ff.Required, FieldRequired},
+		{ff.NoExport, FieldNoExport},
+	} {
+		if f.on {
+			i |= f.bit
+		} else {
+			i &^= f.bit
+		}
+	}
+	return i
+}
+
+// SetFieldFlags sets the ReadOnly, Required and NoExport field flags of the fields named by the keys of m
+// to the corresponding values preserving any other field flags.
+// Fields are identified by their fully qualified field names.
+func (ctx *Context) SetFieldFlags(m map[string]FieldFlags) error {
+
+	fields, err := ctx.formFields()
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]*formField{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	for name, ff := range m {
+		f, ok := byName[name]
+		if !ok {
+			return errors.Errorf("pdfcpu: unknown form field: %s", name)
+		}
+		// Ff may have been inherited.
+		f.Ff = ff.apply(f.Ff)
+		f.Dict.Update("Ff", Integer(f.Ff))
+	}
+
+	return nil
+}
+
+// FormField represents a terminal form field as listed by ListFormFields.
+type FormField struct {
+	Name     string   // fully qualified field name
+	Type     string   // text, checkbox, radio, choice, signature or button
+	Value    string   // see FormValues
+	Options  []string // export values of choice fields and radio buttons
+	Required bool
+	ReadOnly bool
+	Page     int // page number of the first widget or 0
+}
+
+func fieldType(f *formField) string {
+	switch f.FT {
+	case "Tx":
+		return "text"
+	case "Ch":
+		return "choice"
+	case "Sig":
+		return "signature"
+	case "Btn":
+		switch {
+		case f.Ff&FieldPushbutton > 0:
+			return "button"
+		case f.Ff&FieldRadio > 0:
+			return "radio"
+		}
+		return "checkbox"
+	}
+	return f.FT
+}
+
+// radioExportValues returns the export values of the widgets of the radio button field f.
+func (xRefTable *XRefTable) radioExportValues(f *formField) ([]string, error) {
+
+	var opt Array
+	if o, found := f.Dict.Find("Opt"); found {
+		a, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return nil, err
+		}
+		opt = a
+	}
+
+	ss := []string{}
+	for i, wd := range f.Widgets {
+		if i < len(opt) {
+			s, err := xRefTable.DereferenceText(opt[i])
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, s)
+			continue
+		}
+		on, err := xRefTable.widgetOnState(wd)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, on)
+	}
+
+	return ss, nil
+}
+
+// annotPages returns the page numbers of all annotations by object number.
+func (ctx *Context) annotPages() (map[int]int, error) {
+
+	m := map[int]int{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+		a, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range a {
+			if ir, ok := o.(IndirectRef); ok {
+				if _, found := m[ir.ObjectNumber.Value()]; !found {
+					m[ir.ObjectNumber.Value()] = i
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// ListFormFields returns all terminal fields of the interactive form in field tree order.
+// Radio buttons sharing a field get listed once along with the export values of their widgets.
+func (ctx *Context) ListFormFields() ([]FormField, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	fields, err := ctx.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := ctx.annotPages()
+	if err != nil {
+		return nil, err
+	}
+
+	ff := []FormField{}
+
+	for _, f := range fields {
+
+		fld := FormField{
+			Name:     f.Name,
+			Type:     fieldType(f),
+			Required: f.Ff&FieldRequired > 0,
+			ReadOnly: f.Ff&FieldReadOnly > 0,
+		}
+
+		if fld.Type != "button" && fld.Type != "signature" {
+			if fld.Value, err = ctx.fieldValue(f); err != nil {
+				return nil, err
+			}
+		}
+
+		switch fld.Type {
+		case "choice":
+			fld.Options, err = ctx.choiceOptions(f)
+		case "radio":
+			fld.Options, err = ctx.radioExportValues(f)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ir := range f.Refs {
+			if ir != nil && pages[ir.ObjectNumber.Value()] > 0 {
+				fld.Page = pages[ir.ObjectNumber.Value()]
+				break
+			}
+		}
+
+		ff = append(ff, fld)
+	}
+
+	return ff, nil
+}
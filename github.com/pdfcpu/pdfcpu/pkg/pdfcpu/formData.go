@@ -0,0 +1,430 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FormData represents the values of form fields keyed by their fully qualified field names
+// as exchanged using FDF or XFDF, see 12.7.8
+// Check boxes and radio buttons use their appearance state, "Off" if not selected.
+// Multiple selection list boxes may have more than one value.
+type FormData struct {
+	File   string // optional file name of the source document
+	Values map[string][]string
+	names  map[string]bool // fields of which the value gets written to FDF as name
+}
+
+// FormData returns the values of all terminal form fields except signatures and push buttons.
+func (ctx *Context) FormData() (*FormData, error) {
+
+	fields, err := ctx.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	fd := &FormData{Values: map[string][]string{}, names: map[string]bool{}}
+
+	for _, f := range fields {
+
+		if f.FT == "Sig" || f.FT == "Btn" && f.Ff&FieldPushbutton > 0 {
+			continue
+		}
+
+		if f.FT == "Ch" && f.Ff&FieldMultiSelect > 0 {
+			ss, err := ctx.selectedItems(f)
+			if err != nil {
+				return nil, err
+			}
+			fd.Values[f.Name] = ss
+			continue
+		}
+
+		v, err := ctx.fieldValue(f)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+
+		case f.FT == "Btn":
+			// The export value of the selected widget is known, the state name is needed.
+			s := "Off"
+			if n, ok := f.V.(Name); ok && v != "" {
+				s = n.Value()
+			}
+			fd.Values[f.Name] = []string{s}
+			fd.names[f.Name] = true
+
+		default:
+			fd.Values[f.Name] = []string{v}
+		}
+	}
+
+	return fd, nil
+}
+
+// ImportFormData sets the values of the form fields named in fd and regenerates their appearances.
+func (ctx *Context) ImportFormData(fd *FormData) error {
+	return ctx.fillForm(fd.Values, false, false)
+}
+
+// formDataNode represents a node of the field hierarchy implied by dotted field names.
+type formDataNode struct {
+	name   string // partial field name
+	values []string
+	leaf   bool
+	kids   []*formDataNode
+}
+
+func (n *formDataNode) kid(name string) *formDataNode {
+	for _, k := range n.kids {
+		if k.name == name {
+			return k
+		}
+	}
+	k := &formDataNode{name: name}
+	n.kids = append(n.kids, k)
+	return k
+}
+
+// fieldTree returns the field hierarchy of fd with kids in alphabetical order.
+func (fd *FormData) fieldTree() *formDataNode {
+
+	names := make([]string, 0, len(fd.Values))
+	for k := range fd.Values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	root := &formDataNode{}
+	for _, name := range names {
+		n := root
+		for _, s := range strings.Split(name, ".") {
+			n = n.kid(s)
+		}
+		n.values, n.leaf = fd.Values[name], true
+	}
+
+	return root
+}
+
+func (n *formDataNode) fdfValue() Object {
+	if len(n.values) == 1 {
+		return textString(n.values[0])
+	}
+	a := Array{}
+	for _, s := range n.values {
+		a = append(a, textString(s))
+	}
+	return a
+}
+
+func (n *formDataNode) fdfFields(names map[string]bool, prefix string) Array {
+	a := Array{}
+	for _, k := range n.kids {
+		name := k.name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		d := Dict{"T": textString(k.name)}
+		if k.leaf {
+			if names[name] && len(k.values) == 1 {
+				d["V"] = Name(k.values[0])
+			} else {
+				d["V"] = k.fdfValue()
+			}
+		}
+		if len(k.kids) > 0 {
+			d["Kids"] = k.fdfFields(names, name)
+		}
+		a = append(a, d)
+	}
+	return a
+}
+
+// WriteFDF writes fd as FDF file to w, see 12.7.8.3
+func (fd *FormData) WriteFDF(w io.Writer) error {
+
+	d := Dict{"Fields": fd.fieldTree().fdfFields(fd.names, "")}
+	if fd.File != "" {
+		d["F"] = textString(fd.File)
+	}
+
+	_, err := fmt.Fprintf(w, "%%FDF-1.2\n%%\xe2\xe3\xcf\xd3\n1 0 obj\n%s\nendobj\ntrailer\n<</Root 1 0 R>>\n%%%%EOF\n",
+		Dict{"FDF": d}.PDFString())
+
+	return err
+}
+
+var fdfObjHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// fdfObjects returns the indirect objects of the FDF file s by object number along with the trailer dict.
+func fdfObjects(s string) (map[int]Object, Dict, error) {
+
+	oo := map[int]Object{}
+
+	for _, m := range fdfObjHeader.FindAllStringSubmatchIndex(s, -1) {
+		objNr, err := strconv.Atoi(s[m[2]:m[3]])
+		if err != nil {
+			return nil, nil, err
+		}
+		l := s[m[1]:]
+		o, err := parseObject(&l)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "pdfcpu: FDF obj#%d", objNr)
+		}
+		oo[objNr] = o
+	}
+
+	var trailer Dict
+	if i := strings.LastIndex(s, "trailer"); i >= 0 {
+		l := s[i+len("trailer"):]
+		o, err := parseObject(&l)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "pdfcpu: FDF trailer")
+		}
+		trailer, _ = o.(Dict)
+	}
+
+	return oo, trailer, nil
+}
+
+// fdfReader resolves the objects of a FDF file.
+type fdfReader struct {
+	objs map[int]Object
+}
+
+func (r fdfReader) deref(o Object) Object {
+	for i := 0; i < 8; i++ {
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			break
+		}
+		o = r.objs[ir.ObjectNumber.Value()]
+	}
+	return o
+}
+
+func (r fdfReader) values(o Object) ([]string, error) {
+	switch o := r.deref(o).(type) {
+	case Name:
+		return []string{o.Value()}, nil
+	case StringLiteral, HexLiteral:
+		s, err := Text(o)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	case Array:
+		ss := []string{}
+		for _, o := range o {
+			s, err := Text(r.deref(o))
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, s)
+		}
+		return ss, nil
+	}
+	return nil, errors.New("pdfcpu: FDF: corrupt field value")
+}
+
+func (r fdfReader) collectFields(o Object, prefix string, m map[string][]string) error {
+
+	a, ok := r.deref(o).(Array)
+	if !ok {
+		return errors.New("pdfcpu: FDF: corrupt field array")
+	}
+
+	for _, o := range a {
+
+		d, ok := r.deref(o).(Dict)
+		if !ok {
+			return errors.New("pdfcpu: FDF: corrupt field dict")
+		}
+
+		name := prefix
+		if t, found := d.Find("T"); found {
+			s, err := Text(r.deref(t))
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				name += "."
+			}
+			name += s
+		}
+
+		if v, found := d.Find("V"); found {
+			ss, err := r.values(v)
+			if err != nil {
+				return errors.Wrapf(err, "field %s", name)
+			}
+			m[name] = ss
+		}
+
+		if kids, found := d.Find("Kids"); found {
+			if err := r.collectFields(kids, name, m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReadFDF returns the form data of the FDF file rd.
+func ReadFDF(rd io.Reader) (*FormData, error) {
+
+	bb, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	s := string(bb)
+	if !strings.HasPrefix(strings.TrimSpace(s), "%FDF-") {
+		return nil, errors.New("pdfcpu: missing FDF header")
+	}
+
+	objs, trailer, err := fdfObjects(s)
+	if err != nil {
+		return nil, err
+	}
+
+	r := fdfReader{objs: objs}
+
+	root, ok := r.deref(trailer["Root"]).(Dict)
+	if !ok {
+		return nil, errors.New("pdfcpu: FDF: missing catalog")
+	}
+
+	d, ok := r.deref(root["FDF"]).(Dict)
+	if !ok {
+		return nil, errors.New("pdfcpu: FDF: missing FDF dict")
+	}
+
+	fd := &FormData{Values: map[string][]string{}}
+
+	if o, found := d.Find("F"); found {
+		if s, err := Text(r.deref(o)); err == nil {
+			fd.File = s
+		}
+	}
+
+	if o, found := d.Find("Fields"); found {
+		if err := r.collectFields(o, "", fd.Values); err != nil {
+			return nil, err
+		}
+	}
+
+	return fd, nil
+}
+
+// xfdfField represents a field element of a XFDF file.
+type xfdfField struct {
+	Name   string      `xml:"name,attr"`
+	Values []string    `xml:"value"`
+	Fields []xfdfField `xml:"field"`
+}
+
+// xfdf represents a XFDF file, see XML Forms Data Format Specification 3.0
+type xfdf struct {
+	XMLName xml.Name `xml:"http://ns.adobe.com/xfdf/ xfdf"`
+	Space   string   `xml:"xml:space,attr,omitempty"`
+	F       *struct {
+		Href string `xml:"href,attr"`
+	} `xml:"f"`
+	Fields []xfdfField `xml:"fields>field"`
+}
+
+func (n *formDataNode) xfdfFields() []xfdfField {
+	ff := []xfdfField{}
+	for _, k := range n.kids {
+		f := xfdfField{Name: k.name, Fields: k.xfdfFields()}
+		if k.leaf {
+			f.Values = k.values
+		}
+		ff = append(ff, f)
+	}
+	return ff
+}
+
+// WriteXFDF writes fd as XFDF file to w.
+func (fd *FormData) WriteXFDF(w io.Writer) error {
+
+	x := xfdf{Space: "preserve", Fields: fd.fieldTree().xfdfFields()}
+	if fd.File != "" {
+		x.F = &struct {
+			Href string `xml:"href,attr"`
+		}{Href: fd.File}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(x); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func collectXFDFFields(ff []xfdfField, prefix string, m map[string][]string) {
+	for _, f := range ff {
+		name := f.Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if len(f.Fields) == 0 || len(f.Values) > 0 {
+			m[name] = append([]string{}, f.Values...)
+		}
+		collectXFDFFields(f.Fields, name, m)
+	}
+}
+
+// ReadXFDF returns the form data of the XFDF file rd.
+func ReadXFDF(rd io.Reader) (*FormData, error) {
+
+	var x xfdf
+	if err := xml.NewDecoder(rd).Decode(&x); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: XFDF")
+	}
+
+	fd := &FormData{Values: map[string][]string{}}
+	if x.F != nil {
+		fd.File = x.F.Href
+	}
+
+	collectXFDFFields(x.Fields, "", fd.Values)
+
+	return fd, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"testing"
+)
+
+func TestWidgetOnState(t *testing.T) {
+
+	xRefTable := &XRefTable{Table: map[int]*XRefTableEntry{}}
+
+	n := Dict{"Off": nil, "b": nil, "a": nil, "c": nil}
+
+	for _, tt := range []struct {
+		wd   Dict
+		want string
+	}{
+		{Dict{"AP": Dict{"N": n}}, "a"},
+		{Dict{"AP": Dict{"N": n}, "AS": Name("Off")}, "a"},
+		{Dict{"AP": Dict{"N": n}, "AS": Name("c")}, "c"},
+		{Dict{"AP": Dict{"N": Dict{"Off": nil}}}, "Yes"},
+		{Dict{}, "Yes"},
+	} {
+		// Repeat to catch any dependency on map iteration order.
+		for i := 0; i < 20; i++ {
+			got, err := xRefTable.widgetOnState(tt.wd)
+			if err != nil {
+				t.Fatalf("widgetOnState(%v): %v", tt.wd, err)
+			}
+			if got != tt.want {
+				t.Fatalf("widgetOnState(%v): got %s want %s", tt.wd, got, tt.want)
+			}
+		}
+	}
+}
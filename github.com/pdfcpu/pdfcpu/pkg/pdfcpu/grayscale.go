@@ -0,0 +1,503 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// grayFromRGB returns the gray level of an RGB color, see 10.3.2
+func grayFromRGB(r, g, b float64) float64 {
+	return 0.3*r + 0.59*g + 0.11*b
+}
+
+// grayFromCMYK returns the gray level of a CMYK color, see 10.3.4
+func grayFromCMYK(c, m, y, k float64) float64 {
+	return 1 - math.Min(1, 0.3*c+0.59*m+0.11*y+k)
+}
+
+// grayFromComponents returns the gray level for the 3 or 4 color components ff.
+func grayFromComponents(ff []float64) float64 {
+	if len(ff) == 4 {
+		return grayFromCMYK(ff[0], ff[1], ff[2], ff[3])
+	}
+	return grayFromRGB(ff[0], ff[1], ff[2])
+}
+
+// colorComponents returns 3 for RGB and 4 for CMYK based color spaces of which colors get converted to gray
+// and 0 for any other color space.
+func (xRefTable *XRefTable) colorComponents(o Object) int {
+	o, err := xRefTable.Dereference(o)
+	if err != nil {
+		return 0
+	}
+
+	switch o := o.(type) {
+
+	case Name:
+		switch o.Value() {
+		case DeviceRGBCS:
+			return 3
+		case DeviceCMYKCS:
+			return 4
+		}
+
+	case Array:
+		if len(o) < 2 {
+			return 0
+		}
+		n, ok := o[0].(Name)
+		if !ok {
+			return 0
+		}
+		switch n.Value() {
+		case CalRGBCS:
+			return 3
+		case ICCBasedCS:
+			sd, err := xRefTable.DereferenceStreamDict(o[1])
+			if err != nil || sd == nil {
+				return 0
+			}
+			if i := sd.IntEntry("N"); i != nil && (*i == 3 || *i == 4) {
+				return *i
+			}
+		}
+	}
+
+	return 0
+}
+
+// resourceColorComponents returns the number of color components of the color space named id
+// if it gets converted to gray.
+func (xRefTable *XRefTable) resourceColorComponents(resDict Dict, id string) int {
+	if id == DeviceRGBCS || id == DeviceCMYKCS {
+		return xRefTable.colorComponents(Name(id))
+	}
+	d, err := xRefTable.DereferenceDict(resDict["ColorSpace"])
+	if err != nil || d == nil {
+		return 0
+	}
+	o, found := d.Find(id)
+	if !found {
+		return 0
+	}
+	return xRefTable.colorComponents(o)
+}
+
+func grayOperator(gray float64, op string) string {
+	return fmt.Sprintf(" %.3f %s", gray, op)
+}
+
+// grayContent rewrites the color operators of the content stream s using the resources resDict to their gray equivalents.
+// Colors of shadings, patterns, separations and inline images remain unchanged.
+func (xRefTable *XRefTable) grayContent(s string, resDict Dict) (string, bool, error) {
+
+	// Components of the current nonstroking and stroking color space if converted to gray.
+	type colorState struct{ fill, stroke int }
+
+	var (
+		b       strings.Builder
+		cs      colorState
+		stack   []colorState
+		changed bool
+	)
+
+	oo := []Object{}
+	start := s
+
+	for {
+		o, op, err := nextTextToken(&s)
+		if err != nil {
+			return "", false, err
+		}
+
+		if o != nil {
+			oo = append(oo, o)
+			continue
+		}
+
+		if op == "" {
+			b.WriteString(start)
+			break
+		}
+
+		var repl string
+
+		switch op {
+
+		case "BI":
+			if err := skipBI(&s, NewPageResourceNames()); err != nil {
+				return "", false, err
+			}
+
+		case "q":
+			stack = append(stack, cs)
+
+		case "Q":
+			if len(stack) > 0 {
+				cs, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+
+		case "rg", "RG", "k", "K":
+			n := 3
+			if op == "k" || op == "K" {
+				n = 4
+			}
+			if ff, ok := numbers(oo, n); ok {
+				gop := "g"
+				if op == "RG" || op == "K" {
+					gop = "G"
+				}
+				repl = grayOperator(grayFromComponents(ff), gop)
+			}
+			if op == "rg" || op == "k" {
+				cs.fill = 0
+			} else {
+				cs.stroke = 0
+			}
+
+		case "cs", "CS":
+			n := 0
+			if len(oo) > 0 {
+				if id, ok := oo[len(oo)-1].(Name); ok {
+					n = xRefTable.resourceColorComponents(resDict, id.Value())
+				}
+			}
+			if n > 0 {
+				repl = fmt.Sprintf(" /%s %s", DeviceGrayCS, op)
+			}
+			if op == "cs" {
+				cs.fill = n
+			} else {
+				cs.stroke = n
+			}
+
+		case "sc", "scn", "SC", "SCN":
+			n := cs.fill
+			if op == "SC" || op == "SCN" {
+				n = cs.stroke
+			}
+			if ff, ok := numbers(oo, n); ok && n > 0 && len(oo) == n {
+				repl = grayOperator(grayFromComponents(ff), op)
+			}
+		}
+
+		if repl != "" {
+			b.WriteString(repl)
+			changed = true
+		} else {
+			b.WriteString(start[:len(start)-len(s)])
+		}
+
+		start = s
+		oo = oo[:0]
+	}
+
+	return b.String(), changed, nil
+}
+
+// graySamples converts 8 bit RGB or CMYK samples to gray.
+func graySamples(lookup []byte, n int) []byte {
+	bb := make([]byte, len(lookup)/n)
+	ff := make([]float64, n)
+	for i := range bb {
+		for j := range ff {
+			ff[j] = float64(lookup[i*n+j]) / 255
+		}
+		bb[i] = uint8(math.Round(grayFromComponents(ff) * 255))
+	}
+	return bb
+}
+
+// grayIndexedColorSpace returns the indexed color space a with its lookup table converted to gray
+// or nil if a is not based on RGB or CMYK.
+func (xRefTable *XRefTable) grayIndexedColorSpace(a Array) (Array, error) {
+	if len(a) != 4 {
+		return nil, nil
+	}
+	if n, ok := a[0].(Name); !ok || n.Value() != IndexedCS {
+		return nil, nil
+	}
+	n := xRefTable.colorComponents(a[1])
+	if n == 0 {
+		return nil, nil
+	}
+	lookup, err := colorLookupTable(xRefTable, a[3])
+	if err != nil {
+		return nil, err
+	}
+	if lookup == nil {
+		return nil, nil
+	}
+	return Array{a[0], Name(DeviceGrayCS), a[2], HexLiteral(hex.EncodeToString(graySamples(lookup, n)))}, nil
+}
+
+// grayImage converts the RGB or CMYK image sd to DeviceGray and returns true if sd got modified.
+// Images using other color spaces, bit depths other than 8 or a Decode array remain unchanged.
+// DCT encoded images get reencoded as JPEG, any other image gets flate encoded.
+func (xRefTable *XRefTable) grayImage(sd *StreamDict, quality int) (bool, error) {
+
+	if im := sd.BooleanEntry("ImageMask"); im != nil && *im {
+		return false, nil
+	}
+
+	if bpc := sd.IntEntry("BitsPerComponent"); bpc == nil || *bpc != 8 {
+		return false, nil
+	}
+
+	o, err := xRefTable.Dereference(sd.Dict["ColorSpace"])
+	if err != nil {
+		return false, err
+	}
+
+	if a, ok := o.(Array); ok {
+		a1, err := xRefTable.grayIndexedColorSpace(a)
+		if err != nil || a1 == nil {
+			return false, err
+		}
+		sd.Update("ColorSpace", a1)
+		return true, nil
+	}
+
+	n := xRefTable.colorComponents(o)
+	if n == 0 {
+		return false, nil
+	}
+
+	if _, found := sd.Find("Decode"); found {
+		return false, nil
+	}
+
+	w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+	if w == nil || h == nil {
+		return false, nil
+	}
+
+	if len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == filter.DCT {
+		img, err := jpeg.Decode(bytes.NewReader(sd.Raw))
+		if err != nil {
+			return false, err
+		}
+		gray := image.NewGray(img.Bounds())
+		for y := gray.Rect.Min.Y; y < gray.Rect.Max.Y; y++ {
+			for x := gray.Rect.Min.X; x < gray.Rect.Max.X; x++ {
+				gray.Set(x, y, color.GrayModel.Convert(img.At(x, y)))
+			}
+		}
+		bb, err := encodeJPEG(gray, quality)
+		if err != nil {
+			return false, err
+		}
+		sd.Raw, sd.Content = bb, nil
+		streamLength := int64(len(sd.Raw))
+		sd.StreamLength = &streamLength
+		sd.Update("Length", Integer(streamLength))
+		sd.Update("ColorSpace", Name(DeviceGrayCS))
+		return true, nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if len(sd.Content) < *w**h*n {
+		return false, errors.Errorf("pdfcpu: grayImage: corrupt image data: %d bytes", len(sd.Content))
+	}
+
+	sd.Content = graySamples(sd.Content[:*w**h*n], n)
+	sd.FilterPipeline = []PDFFilter{{Name: filter.Flate, DecodeParms: nil}}
+	sd.Update("Filter", Name(filter.Flate))
+	sd.Delete("DecodeParms")
+	sd.Update("ColorSpace", Name(DeviceGrayCS))
+
+	return true, sd.Encode()
+}
+
+// grayscaler converts the content and images of pages to gray.
+type grayscaler struct {
+	*XRefTable
+	quality int
+	visited IntSet
+}
+
+// grayForm converts the content of the form XObject sd using resDict unless sd brings its own resources.
+func (g *grayscaler) grayForm(sd *StreamDict, resDict Dict) (bool, error) {
+
+	d, err := g.DereferenceDict(sd.Dict["Resources"])
+	if err != nil {
+		return false, err
+	}
+	if d != nil {
+		resDict = d
+	}
+
+	if err := g.grayXObjects(resDict); err != nil {
+		return false, err
+	}
+
+	if err := sd.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return false, nil
+		}
+		return false, err
+	}
+
+	s, changed, err := g.grayContent(string(sd.Content), resDict)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	sd.Content = []byte(s)
+
+	return true, sd.Encode()
+}
+
+// grayXObjects converts the images and forms referenced by resDict.
+// XObjects shared with other pages get converted for all of them.
+func (g *grayscaler) grayXObjects(resDict Dict) error {
+
+	d, err := g.DereferenceDict(resDict["XObject"])
+	if err != nil || d == nil {
+		return err
+	}
+
+	for id, o := range d {
+
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			continue
+		}
+
+		objNr := ir.ObjectNumber.Value()
+		if g.visited[objNr] {
+			continue
+		}
+		g.visited[objNr] = true
+
+		entry, found := g.FindTableEntry(objNr, ir.GenerationNumber.Value())
+		if !found || entry.Free {
+			continue
+		}
+
+		sd, ok := entry.Object.(StreamDict)
+		if !ok {
+			continue
+		}
+
+		var changed bool
+
+		switch st := sd.Subtype(); {
+
+		case st != nil && *st == "Image":
+			changed, err = g.grayImage(&sd, g.quality)
+
+		case st != nil && *st == "Form":
+			changed, err = g.grayForm(&sd, resDict)
+
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "pdfcpu: XObject %s obj#%d", id, objNr)
+		}
+
+		if changed {
+			log.Debug.Printf("ToGrayscale: converted XObject %s obj#%d\n", id, objNr)
+			entry.Object = sd
+		}
+	}
+
+	return nil
+}
+
+func (g *grayscaler) grayPage(ctx *Context, pageNr int) error {
+
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: ToGrayscale: unknown page number: %d", pageNr)
+	}
+
+	resDict := inhPAttrs.resources
+	if resDict == nil {
+		resDict = Dict{}
+	}
+
+	if err := g.grayXObjects(resDict); err != nil {
+		return errors.Wrapf(err, "page %d", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == errNoContent {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s, changed, err := g.grayContent(string(bb), resDict)
+	if err != nil || !changed {
+		return err
+	}
+
+	sd, _ := ctx.NewStreamDictForBuf([]byte(s))
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	d.Update("Contents", *ir)
+
+	return nil
+}
+
+// ToGrayscale converts the colors used by the content of selected pages including any form XObjects to gray
+// along with RGB and CMYK images which get converted to DeviceGray.
+func ToGrayscale(ctx *Context, selectedPages IntSet) error {
+
+	g := &grayscaler{XRefTable: ctx.XRefTable, quality: ctx.JPEGQuality, visited: IntSet{}}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if selectedPages != nil && !selectedPages[i] {
+			continue
+		}
+		if err := g.grayPage(ctx, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// ImagePlacement represents an image XObject drawn on a page along with its effective resolution.
+type ImagePlacement struct {
+	Page          int     // page number
+	ObjNr         int     // object number of the image XObject or 0
+	ID            string  // resource name
+	Width, Height int     // image dimensions in pixels
+	W, H          float64 // rendered dimensions in points
+	DPIX, DPIY    float64 // effective horizontal and vertical resolution
+	LowRes        bool    // effective resolution below the minimum
+}
+
+// DPI returns the lower one of the horizontal and vertical effective resolution of ip.
+func (ip ImagePlacement) DPI() float64 {
+	return math.Min(ip.DPIX, ip.DPIY)
+}
+
+func (ip ImagePlacement) String() string {
+	s := fmt.Sprintf("page %d: %s obj#%d %dx%d px rendered %.2fx%.2f pt: %.0fx%.0f dpi",
+		ip.Page, ip.ID, ip.ObjNr, ip.Width, ip.Height, ip.W, ip.H, ip.DPIX, ip.DPIY)
+	if ip.LowRes {
+		s += " low resolution"
+	}
+	return s
+}
+
+// pageImagePlacements returns the image XObjects drawn by page pageNr in content stream order.
+func (ctx *Context) pageImagePlacements(pageNr int, minDPI float64) ([]ImagePlacement, error) {
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	// Default user space units may be larger than 1/72 inch, see 14.11.2
+	userUnit := 1.
+	if o, found := d.Find("UserUnit"); found {
+		if f := ctx.number(o); f > 0 {
+			userUnit = f
+		}
+	}
+
+	ii := []ImagePlacement{}
+
+	image := func(id string, o Object, sd *StreamDict, ctm matrix) {
+		// The image occupies the unit square of image space.
+		w := math.Hypot(ctm[0][0], ctm[0][1]) * userUnit
+		h := math.Hypot(ctm[1][0], ctm[1][1]) * userUnit
+		if w == 0 || h == 0 {
+			return
+		}
+		ip := ImagePlacement{
+			Page:   pageNr,
+			ID:     id,
+			Width:  int(ctx.number(sd.Dict["Width"])),
+			Height: int(ctx.number(sd.Dict["Height"])),
+			W:      w,
+			H:      h,
+		}
+		if ir, ok := o.(IndirectRef); ok {
+			ip.ObjNr = ir.ObjectNumber.Value()
+		}
+		ip.DPIX = float64(ip.Width) / (w / 72)
+		ip.DPIY = float64(ip.Height) / (h / 72)
+		ip.LowRes = ip.DPI() < minDPI
+		ii = append(ii, ip)
+	}
+
+	glyph := func(s string, start, end, center types.Point, size float64) {}
+
+	te, err := newTextExtractor(ctx.XRefTable, glyph)
+	if err != nil {
+		return nil, err
+	}
+	te.image = image
+
+	bb, err := ctx.PageContent(d)
+	if err == errNoContent {
+		return ii, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gs := textGState{ctm: identMatrix, hScale: 1}
+
+	if err := te.processContent(string(bb), inhPAttrs.resources, gs, 0); err != nil {
+		return nil, err
+	}
+
+	return ii, nil
+}
+
+// ImageResolutions returns all placements of image XObjects on selected pages in page and content stream order
+// along with their effective resolution derived from the pixel dimensions and the rendered size of each placement.
+// Placements with an effective resolution below minDPI are flagged as low resolution.
+func (ctx *Context) ImageResolutions(selectedPages IntSet, minDPI float64) ([]ImagePlacement, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	if len(selectedPages) == 0 {
+		selectedPages = IntSet{}
+		for i := 1; i <= ctx.PageCount; i++ {
+			selectedPages[i] = true
+		}
+	}
+
+	ii := []ImagePlacement{}
+
+	for _, i := range sortedSelectedPages(selectedPages) {
+		ii1, err := ctx.pageImagePlacements(i, minDPI)
+		if err != nil {
+			return nil, err
+		}
+		ii = append(ii, ii1...)
+	}
+
+	return ii, nil
+}
@@ -20,8 +20,10 @@ import (
 	"bytes"
 	"fmt"
 	"image"
+	"image/jpeg"
 	"image/png"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -486,3 +488,32 @@ func TestReadWriteJPEG(t *testing.T) {
 		fmt.Printf("fileName: %s\n", fn)
 	}
 }
+
+func TestJPEGQuality(t *testing.T) {
+
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	r := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(r.Intn(256))
+	}
+
+	size := func(q int) int {
+		bb, err := encodeJPEG(img, q)
+		if err != nil {
+			t.Fatalf("err: %v\n", err)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(bb)); err != nil {
+			t.Fatalf("quality %d: %v\n", q, err)
+		}
+		return len(bb)
+	}
+
+	low, high := size(30), size(95)
+	if low >= high {
+		t.Fatalf("want quality 30 (%d bytes) smaller than quality 95 (%d bytes)\n", low, high)
+	}
+
+	if got := size(0); got != size(DefaultJPEGQuality) {
+		t.Fatalf("quality 0: want default quality, got %d bytes\n", got)
+	}
+}
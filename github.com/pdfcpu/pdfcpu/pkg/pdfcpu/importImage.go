@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 
@@ -119,7 +120,8 @@ func parsePageFormat(v string) (*Dim, string, error) {
 	}
 
 	if d.Portrait() && land || d.Landscape() && port {
-		d.Width, d.Height = d.Height, d.Width
+		// Leave the shared paper size untouched.
+		d = &Dim{d.Height, d.Width}
 	}
 
 	return d, v, nil
@@ -365,18 +367,22 @@ func lowerLeftCorner(vpw, vph, bbw, bbh float64, a anchor) types.Point {
 	return p
 }
 
-func importImagePDFBytes(wr io.Writer, pageDim *Dim, imgWidth, imgHeight float64, imp *Import) {
+// importImageMatrix returns the transformation of the unit square onto the image bounding box of the page.
+func importImageMatrix(pageDim *Dim, imgWidth, imgHeight float64, imp *Import) matrix {
 
 	vpw := float64(pageDim.Width)
 	vph := float64(pageDim.Height)
 
+	m := identMatrix
+
 	if imp.Pos == Full {
 		// The bounding box equals the page dimensions.
 		bb := types.NewRectangle(0, 0, vpw, vph)
 		bb.UR.X = bb.Width()
 		bb.UR.Y = bb.UR.X / bb.AspectRatio()
-		fmt.Fprintf(wr, "q %f 0 0 %f 0 0 cm /Im0 Do Q", bb.Width(), bb.Height())
-		return
+		m[0][0] = bb.Width()
+		m[1][1] = bb.Height()
+		return m
 	}
 
 	if imp.DPI > 0 {
@@ -401,8 +407,6 @@ func importImagePDFBytes(wr io.Writer, pageDim *Dim, imgWidth, imgHeight float64
 		}
 	}
 
-	m := identMatrix
-
 	// Scale
 	m[0][0] = bb.Width()
 	m[1][1] = bb.Height()
@@ -412,12 +416,28 @@ func importImagePDFBytes(wr io.Writer, pageDim *Dim, imgWidth, imgHeight float64
 	m[2][0] = ll.X + float64(imp.Dx)
 	m[2][1] = ll.Y + float64(imp.Dy)
 
+	return m
+}
+
+func importImagePDFBytes(wr io.Writer, m matrix, imp *Import) {
+
+	if imp.Pos == Full {
+		fmt.Fprintf(wr, "q %f 0 0 %f 0 0 cm /Im0 Do Q", m[0][0], m[1][1])
+		return
+	}
+
 	fmt.Fprintf(wr, "q %.2f %.2f %.2f %.2f %.2f %.2f cm /Im0 Do Q",
 		m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1])
 }
 
 // NewPageForImage creates a new page dict in xRefTable for given image reader r.
 func NewPageForImage(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRef, imp *Import) (*IndirectRef, error) {
+	return newPageForImage(xRefTable, r, parentIndRef, imp, nil, 0)
+}
+
+// newPageForImage creates a new page dict in xRefTable for given image reader r
+// overlaying the image with the invisible text of lines unless nil.
+func newPageForImage(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRef, imp *Import, lines []OCRLine, structParents int) (*IndirectRef, error) {
 
 	// create image dict.
 	imgIndRef, w, h, err := createImageResource(xRefTable, r)
@@ -425,6 +445,34 @@ func NewPageForImage(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRe
 		return nil, err
 	}
 
+	dim := &Dim{float64(w), float64(h)}
+	if imp.Pos != Full {
+		dim = imp.PageDim
+	}
+
+	m := importImageMatrix(dim, float64(w), float64(h), imp)
+
+	var buf bytes.Buffer
+	importImagePDFBytes(&buf, m, imp)
+	if lines != nil {
+		ocrTextPDFBytes(&buf, m, float64(w), float64(h), lines)
+	}
+
+	pageDict, err := newImagePageDict(xRefTable, imgIndRef, parentIndRef, dim, buf.Bytes(), lines != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if lines != nil {
+		pageDict["StructParents"] = Integer(structParents)
+	}
+
+	return xRefTable.IndRefForNewObject(pageDict)
+}
+
+// newImagePageDict returns a page dict of dimensions dim rendering the image imgIndRef by content.
+func newImagePageDict(xRefTable *XRefTable, imgIndRef, parentIndRef *IndirectRef, dim *Dim, content []byte, ocrFont bool) (Dict, error) {
+
 	// create resource dict for XObject.
 	d := Dict(
 		map[string]Object{
@@ -433,21 +481,19 @@ func NewPageForImage(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRe
 		},
 	)
 
+	if ocrFont {
+		d["Font"] = Dict(map[string]Object{ocrFontID: ocrFontDict()})
+	}
+
 	resIndRef, err := xRefTable.IndRefForNewObject(d)
 	if err != nil {
 		return nil, err
 	}
 
-	dim := &Dim{float64(w), float64(h)}
-	if imp.Pos != Full {
-		dim = imp.PageDim
-	}
 	// mediabox = physical page dimensions
 	mediaBox := RectForDim(dim.Width, dim.Height)
 
-	var buf bytes.Buffer
-	importImagePDFBytes(&buf, dim, float64(w), float64(h), imp)
-	sd, _ := xRefTable.NewStreamDictForBuf(buf.Bytes())
+	sd, _ := xRefTable.NewStreamDictForBuf(content)
 	if err = sd.Encode(); err != nil {
 		return nil, err
 	}
@@ -467,5 +513,73 @@ func NewPageForImage(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRe
 		},
 	)
 
+	return pageDict, nil
+}
+
+// Ways of fitting an image into a page.
+const (
+	FitContain = "contain" // scale the image to fit the page preserving its aspect ratio.
+	FitCover   = "cover"   // scale the image to cover the page preserving its aspect ratio, cropping the excess.
+	FitActual  = "actual"  // keep the image at 72 DPI.
+)
+
+// fitImageMatrix returns the transformation of the unit square onto the image bounding box
+// centered on a page of dimensions pageDim.
+func fitImageMatrix(pageDim *Dim, imgWidth, imgHeight float64, fit string) (matrix, error) {
+
+	vpw, vph := pageDim.Width, pageDim.Height
+
+	var s float64
+
+	switch fit {
+	case FitContain, "":
+		s = math.Min(vpw/imgWidth, vph/imgHeight)
+	case FitCover:
+		s = math.Max(vpw/imgWidth, vph/imgHeight)
+	case FitActual:
+		s = 1
+	default:
+		return identMatrix, errors.Errorf("pdfcpu: unknown fit mode \"%s\", please use one of: contain, cover, actual", fit)
+	}
+
+	m := identMatrix
+	m[0][0] = s * imgWidth
+	m[1][1] = s * imgHeight
+	m[2][0] = (vpw - m[0][0]) / 2
+	m[2][1] = (vph - m[1][1]) / 2
+
+	return m, nil
+}
+
+// NewPageForImageFit creates a new page dict in xRefTable for given image reader r fitting the image into pageDim.
+// If pageDim is nil the page takes on the image dimensions at 72 DPI.
+// JPEG images are embedded as they are.
+func NewPageForImageFit(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRef, pageDim *Dim, fit string) (*IndirectRef, error) {
+
+	imgIndRef, w, h, err := createImageResource(xRefTable, r)
+	if err != nil {
+		return nil, err
+	}
+
+	dim := pageDim
+	if dim == nil {
+		dim = &Dim{float64(w), float64(h)}
+	}
+
+	m, err := fitImageMatrix(dim, float64(w), float64(h), fit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clip to the page since the image may exceed it.
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "q 0 0 %.2f %.2f re W n %.2f 0 0 %.2f %.2f %.2f cm /Im0 Do Q",
+		dim.Width, dim.Height, m[0][0], m[1][1], m[2][0], m[2][1])
+
+	pageDict, err := newImagePageDict(xRefTable, imgIndRef, parentIndRef, dim, buf.Bytes(), false)
+	if err != nil {
+		return nil, err
+	}
+
 	return xRefTable.IndRefForNewObject(pageDict)
 }
@@ -0,0 +1,412 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// incrementalUpdate represents the state of an incremental update of a PDF file.
+// Cross reference sections are parsed on demand walking back from the most recent one.
+type incrementalUpdate struct {
+	ctx        *Context
+	prev       int64  // offset of the most recent xref section.
+	next       *int64 // offset of the next xref section not parsed yet.
+	xRefStream bool   // true if the most recent xref section is an xref stream.
+}
+
+// find returns the xref table entry for objNr parsing as many xref sections as needed.
+func (iu *incrementalUpdate) find(objNr int) (*XRefTableEntry, error) {
+
+	for {
+		if entry, ok := iu.ctx.Find(objNr); ok {
+			return entry, nil
+		}
+		if iu.next == nil {
+			return nil, errors.Errorf("pdfcpu: incremental update: missing obj#%d", objNr)
+		}
+		var err error
+		if iu.next, err = parseXRefSectionAt(iu.ctx, iu.next); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resolveObject returns the object for objNr and parses it from file on demand.
+// Any object stream involved gets decoded lazily.
+func (iu *incrementalUpdate) resolveObject(objNr int) (Object, error) {
+
+	entry, err := iu.find(objNr)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Free {
+		return nil, errors.Errorf("pdfcpu: incremental update: obj#%d is free", objNr)
+	}
+
+	if entry.Compressed {
+		osEntry, err := iu.find(*entry.ObjectStream)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := osEntry.Object.(ObjectStreamDict); !ok {
+			if err := decodeObjectStream(iu.ctx, *entry.ObjectStream); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return dereferencedObject(iu.ctx, objNr)
+}
+
+func (iu *incrementalUpdate) resolveDict(o Object) (Dict, *IndirectRef, error) {
+
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		return nil, nil, errors.New("pdfcpu: incremental update: missing indirect reference")
+	}
+
+	o, err := iu.resolveObject(ir.ObjectNumber.Value())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d, ok := o.(Dict)
+	if !ok {
+		return nil, nil, errors.Errorf("pdfcpu: incremental update: obj#%d is not a dict", ir.ObjectNumber.Value())
+	}
+
+	return d, &ir, nil
+}
+
+// newIncrementalUpdate prepares rs for an incremental update by parsing its most recent cross reference section.
+func newIncrementalUpdate(rs io.ReadSeeker, conf *Configuration) (*incrementalUpdate, error) {
+
+	ctx, err := NewContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.validateStreamFilter(); err != nil {
+		return nil, err
+	}
+
+	prev, err := offsetLastXRefSection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.HeaderVersion, ctx.Read.EolCount, err = headerVersion(rs); err != nil {
+		return nil, err
+	}
+
+	if ctx.PreserveHeader {
+		// The update uses the line endings of the original file.
+		if ctx.Read.Header, ctx.Read.Eol, err = headerBytes(rs); err != nil {
+			return nil, err
+		}
+		ctx.Write.Eol = ctx.Read.Eol
+	}
+
+	next, err := parseXRefSectionAt(ctx, prev)
+	if err != nil {
+		return nil, errors.Wrap(err, "newIncrementalUpdate: xRefTable failed")
+	}
+
+	if ctx.Encrypt != nil {
+		return nil, errors.New("pdfcpu: incremental updates of encrypted files are not supported")
+	}
+
+	// New objects always get new object numbers.
+	// Objects in the free list stay untouched.
+	var zero int64
+	ctx.Table[0] = NewFreeHeadXRefTableEntry()
+	ctx.Table[0].Offset = &zero
+
+	return &incrementalUpdate{ctx: ctx, prev: *prev, next: next, xRefStream: ctx.Read.UsingXRefStreams}, nil
+}
+
+func writeIncrementalObject(ctx *Context, objNr int) error {
+
+	entry, ok := ctx.Find(objNr)
+	if !ok {
+		return errors.Errorf("pdfcpu: writeIncrementalObject: missing obj#%d", objNr)
+	}
+
+	genNr := *entry.Generation
+
+	switch o := entry.Object.(type) {
+
+	case Dict:
+		return writeDictObject(ctx, objNr, genNr, o)
+
+	case StreamDict:
+		return writeStreamDictObject(ctx, objNr, genNr, o)
+
+	case Array:
+		return writeArrayObject(ctx, objNr, genNr, o)
+
+	case Integer:
+		return writeIntegerObject(ctx, objNr, genNr, o)
+
+	}
+
+	return errors.Errorf("pdfcpu: writeIncrementalObject: unsupported obj#%d %T", objNr, entry.Object)
+}
+
+func writeIncrementalTrailer(ctx *Context, prev, xRefOffset int64) error {
+
+	w := ctx.Write
+
+	d := NewDict()
+	d.Insert("Size", Integer(*ctx.Size))
+	d.Insert("Root", *ctx.Root)
+	d.Insert("Prev", Integer(prev))
+
+	if ctx.Info != nil {
+		d.Insert("Info", *ctx.Info)
+	}
+
+	if ctx.ID != nil {
+		d.Insert("ID", ctx.ID)
+	}
+
+	_, err := w.WriteString(fmt.Sprintf("trailer%s%s%sstartxref%s%d%s%%%%EOF%s", w.Eol, d.PDFString(), w.Eol, w.Eol, xRefOffset, w.Eol, w.Eol))
+
+	return err
+}
+
+// xRefSubsections returns start and size of each run of consecutive object numbers in the sorted objNrs.
+func xRefSubsections(objNrs []int) [][2]int {
+
+	var ss [][2]int
+
+	for i := 0; i < len(objNrs); {
+		j := i + 1
+		for j < len(objNrs) && objNrs[j] == objNrs[j-1]+1 {
+			j++
+		}
+		ss = append(ss, [2]int{objNrs[i], j - i})
+		i = j
+	}
+
+	return ss
+}
+
+// writeIncrementalXRefSection writes a cross reference section and trailer for the sorted objNrs.
+func writeIncrementalXRefSection(ctx *Context, objNrs []int, prev int64) error {
+
+	w := ctx.Write
+	xRefOffset := w.Offset
+
+	if _, err := w.WriteString("xref" + w.Eol); err != nil {
+		return err
+	}
+
+	for _, ss := range xRefSubsections(objNrs) {
+		if err := writeXRefSubsection(ctx, ss[0], ss[1]); err != nil {
+			return err
+		}
+	}
+
+	return writeIncrementalTrailer(ctx, prev, xRefOffset)
+}
+
+// writeIncrementalXRefStream writes an xref stream for the sorted objNrs.
+// The xref stream is a new object itself and gets covered too.
+func writeIncrementalXRefStream(ctx *Context, objNrs []int, prev int64) error {
+
+	w := ctx.Write
+	xRefOffset := w.Offset
+
+	sd := NewXRefStreamDict(ctx)
+	sd.setFilter(ctx.StreamFilterName())
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+	objNr := ir.ObjectNumber.Value()
+	w.SetWriteOffset(objNr)
+	objNrs = append(objNrs, objNr)
+
+	i2 := 0
+	for off := xRefOffset; off > 0; off >>= 8 {
+		i2++
+	}
+	i3 := 2 // generation numbers <= 0xffff
+
+	var buf []byte
+	for _, i := range objNrs {
+		buf = append(buf, int64ToBuf(1, 1)...)
+		buf = append(buf, int64ToBuf(w.Table[i], i2)...)
+		buf = append(buf, int64ToBuf(int64(*ctx.Table[i].Generation), i3)...)
+	}
+
+	var index Array
+	for _, ss := range xRefSubsections(objNrs) {
+		index = append(index, Integer(ss[0]), Integer(ss[1]))
+	}
+
+	sd.Insert("Size", Integer(*ctx.Size))
+	sd.Insert("Prev", Integer(prev))
+	sd.Insert("W", Array{Integer(1), Integer(i2), Integer(i3)})
+	sd.Insert("Index", index)
+	sd.Content = buf
+
+	if err = sd.StreamDict.Encode(); err != nil {
+		return err
+	}
+
+	if err = writeStreamDictObject(ctx, objNr, 0, sd.StreamDict); err != nil {
+		return err
+	}
+
+	_, err = w.WriteString(fmt.Sprintf("startxref%s%d%s%%%%EOF%s", w.Eol, xRefOffset, w.Eol, w.Eol))
+
+	return err
+}
+
+// write appends the objects objNrs together with a cross reference section and trailer
+// for an incremental update to ws. An xref stream continues a chain of xref streams.
+func (iu *incrementalUpdate) write(ws io.WriteSeeker, objNrs []int) error {
+
+	ctx := iu.ctx
+
+	off, err := ws.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	w := ctx.Write
+	w.Writer = bufio.NewWriter(ws)
+
+	// The original file may not end with an eol.
+	if err = w.WriteEol(); err != nil {
+		return err
+	}
+	w.Offset = off + int64(len(w.Eol))
+
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		if err = writeIncrementalObject(ctx, objNr); err != nil {
+			return err
+		}
+	}
+
+	if iu.xRefStream {
+		err = writeIncrementalXRefStream(ctx, objNrs, iu.prev)
+	} else {
+		err = writeIncrementalXRefSection(ctx, objNrs, iu.prev)
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// AppendPage adds p as new last page to the PDF file represented by rws.
+// Only the cross reference data, the catalog and the root of the page tree are read.
+// The new page gets written as an incremental update to the end of the file leaving any prior file content untouched.
+func AppendPage(rws io.ReadWriteSeeker, p Page, conf *Configuration) error {
+
+	log.Info.Println("AppendPage: begin")
+
+	iu, err := newIncrementalUpdate(rws, conf)
+	if err != nil {
+		return err
+	}
+
+	ctx := iu.ctx
+
+	rootDict, rootIndRef, err := iu.resolveDict(*ctx.Root)
+	if err != nil {
+		return err
+	}
+
+	o, found := rootDict.Find("Pages")
+	if !found {
+		return errors.New("pdfcpu: AppendPage: missing page tree")
+	}
+
+	pagesDict, pagesIndRef, err := iu.resolveDict(o)
+	if err != nil {
+		return err
+	}
+
+	// Also rewrite the catalog so the next update finds
+	// everything it needs in the most recent xref section.
+	firstNewObjNr := *ctx.Size
+	updated := []int{rootIndRef.ObjectNumber.Value(), pagesIndRef.ObjectNumber.Value()}
+
+	pageIndRef, err := ctx.newPage(*pagesIndRef, p)
+	if err != nil {
+		return err
+	}
+
+	o, _ = pagesDict.Find("Kids")
+	switch kids := o.(type) {
+
+	case Array:
+		pagesDict.Update("Kids", append(kids, *pageIndRef))
+
+	case IndirectRef:
+		// Kids may be an indirect array.
+		objNr := kids.ObjectNumber.Value()
+		o, err := iu.resolveObject(objNr)
+		if err != nil {
+			return err
+		}
+		a, ok := o.(Array)
+		if !ok {
+			return errors.New("pdfcpu: AppendPage: corrupt page tree root")
+		}
+		ctx.Table[objNr].Object = append(a, *pageIndRef)
+		updated = append(updated, objNr)
+
+	default:
+		return errors.New("pdfcpu: AppendPage: corrupt page tree root")
+	}
+
+	o, _ = pagesDict.Find("Count")
+	count, ok := o.(Integer)
+	if !ok {
+		return errors.New("pdfcpu: AppendPage: missing page count")
+	}
+	pagesDict.Update("Count", count+1)
+
+	for objNr := firstNewObjNr; objNr < *ctx.Size; objNr++ {
+		updated = append(updated, objNr)
+	}
+
+	if err = iu.write(rws, updated); err != nil {
+		return err
+	}
+
+	log.Info.Println("AppendPage: end")
+
+	return nil
+}
@@ -129,7 +129,10 @@ func ensureInfoDict(ctx *Context) error {
 
 	d.Update("CreationDate", StringLiteral(now))
 	d.Update("ModDate", StringLiteral(now))
-	d.Update("Producer", StringLiteral(v))
+	if ctx.Cmd != SETINFO {
+		// SETINFO takes care of Producer.
+		d.Update("Producer", StringLiteral(v))
+	}
 
 	return nil
 }
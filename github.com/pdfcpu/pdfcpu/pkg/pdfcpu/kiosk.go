@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pkg/errors"
+)
+
+// setViewerPreference sets the viewer preference key of the catalog to o or removes it if o is nil, see 12.2
+func (xRefTable *XRefTable) setViewerPreference(rootDict Dict, key string, o Object) error {
+
+	d, err := xRefTable.DereferenceDict(rootDict["ViewerPreferences"])
+	if err != nil {
+		return err
+	}
+
+	if d == nil {
+		if o == nil {
+			return nil
+		}
+		d = NewDict()
+		rootDict.Insert("ViewerPreferences", d)
+	}
+
+	if o != nil {
+		d.Update(key, o)
+		return nil
+	}
+
+	d.Delete(key)
+	if len(d) == 0 {
+		rootDict.Delete("ViewerPreferences")
+	}
+
+	return nil
+}
+
+// SetKioskMode makes all pages advance automatically after seconds and opens the document in full screen mode.
+// A duration of 0 clears the page durations and the full screen mode.
+// Looping back to the first page after the last one is a viewer setting and cannot be expressed in PDF.
+func SetKioskMode(ctx *Context, seconds float64) error {
+
+	if seconds < 0 {
+		return errors.Errorf("pdfcpu: invalid page duration: %f", seconds)
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return errors.Errorf("pdfcpu: unknown page number: %d", i)
+		}
+		if seconds > 0 {
+			d.Update("Dur", Float(seconds))
+		} else {
+			d.Delete("Dur")
+		}
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	if seconds > 0 {
+		rootDict.Update("PageMode", Name("FullScreen"))
+		// Page mode to use on exiting full screen mode.
+		return ctx.setViewerPreference(rootDict, "NonFullScreenPageMode", Name("UseNone"))
+	}
+
+	if pm := rootDict.NameEntry("PageMode"); pm != nil && *pm == "FullScreen" {
+		rootDict.Delete("PageMode")
+	}
+
+	return ctx.setViewerPreference(rootDict, "NonFullScreenPageMode", nil)
+}
+
+// KioskMode returns the page duration in seconds if the document opens in full screen mode
+// and all pages advance automatically after the same duration, or 0 otherwise.
+func KioskMode(ctx *Context) (float64, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return 0, err
+	}
+
+	if pm := rootDict.NameEntry("PageMode"); pm == nil || *pm != "FullScreen" {
+		return 0, nil
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return 0, err
+	}
+
+	var dur float64
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return 0, err
+		}
+		if d == nil {
+			return 0, errors.Errorf("pdfcpu: unknown page number: %d", i)
+		}
+		o, found := d.Find("Dur")
+		if !found {
+			return 0, nil
+		}
+		f, err := ctx.DereferenceNumber(o)
+		if err != nil {
+			return 0, err
+		}
+		if i > 1 && f != dur {
+			return 0, nil
+		}
+		dur = f
+	}
+
+	return dur, nil
+}
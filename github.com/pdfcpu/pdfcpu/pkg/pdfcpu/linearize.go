@@ -0,0 +1,789 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pkg/errors"
+)
+
+// linearization assigns the objects of a document to the parts of a linearized file, see F.3
+type linearization struct {
+	docObjs    []int   // part 4: catalog and document level objects
+	firstPage  []int   // part 6: first page object and all objects needed to display the first page
+	pageObjs   [][]int // part 7: page object and objects exclusively used by this page for any page but the first
+	shared     []int   // part 8: objects shared by pages other than the first page
+	other      []int   // part 9: page tree nodes and all remaining objects
+	pageShared [][]int // objects of part 6 and part 8 used by any page but the first
+}
+
+// collectObjects appends the objects reachable from o to objNrs in depth first order.
+// accept decides whether an object gets collected and traversed.
+func (xRefTable *XRefTable) collectObjects(o Object, accept func(objNr int) bool, objNrs *[]int) {
+
+	switch o := o.(type) {
+
+	case IndirectRef:
+		objNr := o.ObjectNumber.Value()
+		entry, found := xRefTable.FindTableEntryLight(objNr)
+		if !found || entry.Free || entry.Object == nil || !accept(objNr) {
+			return
+		}
+		if sd, ok := entry.Object.(StreamDict); ok {
+			// An indirect stream length gets written right before its stream.
+			if ir := sd.IndirectRefEntry("Length"); ir != nil {
+				xRefTable.collectObjects(*ir, accept, objNrs)
+			}
+		}
+		*objNrs = append(*objNrs, objNr)
+		xRefTable.collectObjects(entry.Object, accept, objNrs)
+
+	case Dict:
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			xRefTable.collectObjects(o[k], accept, objNrs)
+		}
+
+	case StreamDict:
+		xRefTable.collectObjects(o.Dict, accept, objNrs)
+
+	case Array:
+		for _, o1 := range o {
+			xRefTable.collectObjects(o1, accept, objNrs)
+		}
+
+	}
+}
+
+// pageTreeObjNrs returns the object numbers of all page tree nodes and page objects.
+func (xRefTable *XRefTable) pageTreeObjNrs(o Object, m IntSet) error {
+
+	ir, ok := o.(IndirectRef)
+	if !ok || m[ir.ObjectNumber.Value()] {
+		return nil
+	}
+	m[ir.ObjectNumber.Value()] = true
+
+	d, err := xRefTable.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return err
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+		if err := xRefTable.pageTreeObjNrs(o, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectPageObjects returns the page object for pageNr followed by the objects reachable from it.
+func (ctx *Context) collectPageObjects(pageNr int, accept func(objNr int) bool) ([]int, error) {
+
+	ir, err := ctx.PageDictIndRef(pageNr)
+	if err != nil {
+		return nil, err
+	}
+
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	objNrs := []int{ir.ObjectNumber.Value()}
+	ctx.collectObjects(d, accept, &objNrs)
+
+	// Inherited resources are needed for rendering too.
+	ctx.collectObjects(inhPAttrs.resources, accept, &objNrs)
+
+	return objNrs, nil
+}
+
+func (ctx *Context) linearization() (*linearization, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	// Page objects and page tree nodes are never collected along with other objects.
+	stop := IntSet{}
+	if err := ctx.pageTreeObjNrs(rootDict["Pages"], stop); err != nil {
+		return nil, err
+	}
+
+	l := &linearization{}
+	assigned := IntSet{}
+
+	collect := func(objNr int) bool {
+		if stop[objNr] || assigned[objNr] {
+			return false
+		}
+		assigned[objNr] = true
+		return true
+	}
+
+	// Part 4
+	rootObjNr := ctx.Root.ObjectNumber.Value()
+	assigned[rootObjNr] = true
+	l.docObjs = []int{rootObjNr}
+
+	if ctx.Encrypt != nil && ctx.EncKey != nil {
+		encObjNr := ctx.Encrypt.ObjectNumber.Value()
+		assigned[encObjNr] = true
+		l.docObjs = append(l.docObjs, encObjNr)
+	}
+
+	keys := []string{"ViewerPreferences", "Threads", "OpenAction", "AcroForm"}
+	if pm := rootDict.NameEntry("PageMode"); pm != nil && *pm == "UseOutlines" {
+		keys = append(keys, "Outlines")
+	}
+	for _, k := range keys {
+		ctx.collectObjects(rootDict[k], collect, &l.docObjs)
+	}
+
+	// Part 6
+	if l.firstPage, err = ctx.collectPageObjects(1, collect); err != nil {
+		return nil, err
+	}
+	for _, objNr := range l.firstPage {
+		assigned[objNr] = true
+	}
+
+	// Parts 7 and 8
+	inFirstPage := IntSet{}
+	for _, objNr := range l.firstPage {
+		inFirstPage[objNr] = true
+	}
+
+	reachable := make([][]int, ctx.PageCount)
+	l.pageShared = make([][]int, ctx.PageCount)
+	owners := map[int]int{}
+
+	for i := 2; i <= ctx.PageCount; i++ {
+
+		visited := IntSet{}
+
+		accept := func(objNr int) bool {
+			if stop[objNr] || visited[objNr] {
+				return false
+			}
+			visited[objNr] = true
+			if inFirstPage[objNr] {
+				l.pageShared[i-1] = append(l.pageShared[i-1], objNr)
+				return false
+			}
+			return !assigned[objNr]
+		}
+
+		objNrs, err := ctx.collectPageObjects(i, accept)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, objNr := range objNrs {
+			owners[objNr]++
+		}
+		reachable[i-1] = objNrs
+	}
+
+	l.pageObjs = make([][]int, ctx.PageCount)
+	shared := IntSet{}
+
+	for i := 1; i < ctx.PageCount; i++ {
+		for _, objNr := range reachable[i] {
+			if owners[objNr] == 1 {
+				l.pageObjs[i] = append(l.pageObjs[i], objNr)
+				continue
+			}
+			if !shared[objNr] {
+				shared[objNr] = true
+				l.shared = append(l.shared, objNr)
+			}
+			l.pageShared[i] = append(l.pageShared[i], objNr)
+		}
+	}
+
+	for _, objNr := range l.shared {
+		assigned[objNr] = true
+	}
+	for _, objNrs := range l.pageObjs {
+		for _, objNr := range objNrs {
+			assigned[objNr] = true
+		}
+	}
+
+	// Part 9
+	remaining := func(objNr int) bool {
+		if assigned[objNr] {
+			return false
+		}
+		assigned[objNr] = true
+		return true
+	}
+
+	ctx.collectObjects(rootDict, remaining, &l.other)
+
+	if ctx.Info != nil {
+		ctx.collectObjects(*ctx.Info, remaining, &l.other)
+	}
+
+	if ctx.AdditionalStreams != nil {
+		ctx.collectObjects(*ctx.AdditionalStreams, remaining, &l.other)
+	}
+
+	return l, nil
+}
+
+// renumber assigns consecutive object numbers in file order with the first page section numbered last.
+// It returns the object number of the linearization parameter dict which is also the size of the main xref section.
+func (ctx *Context) renumber(l *linearization) int {
+
+	lookup := map[int]int{}
+	i := 1
+
+	add := func(objNrs []int) {
+		for _, objNr := range objNrs {
+			lookup[objNr] = i
+			i++
+		}
+	}
+
+	for _, objNrs := range l.pageObjs {
+		add(objNrs)
+	}
+	add(l.shared)
+	add(l.other)
+
+	linObjNr := i
+	i++
+
+	add(l.docObjs)
+	add(l.firstPage)
+
+	// The free list consists of obj#0 only.
+	head := *ctx.Table[0]
+	zero := int64(0)
+	head.Offset = &zero
+	table := map[int]*XRefTableEntry{0: &head}
+
+	for objNr, newObjNr := range lookup {
+		entry := *ctx.Table[objNr]
+		entry.Object = entry.Object.Clone()
+		if o := patchObject(entry.Object, lookup); o != nil {
+			entry.Object = o
+		}
+		table[newObjNr] = &entry
+	}
+
+	ctx.Table = table
+
+	patchIndRef(ctx.Root, lookup)
+	if d, ok := table[ctx.Root.ObjectNumber.Value()].Object.(Dict); ok {
+		ctx.RootDict = d
+	}
+
+	if ctx.Info != nil {
+		patchIndRef(ctx.Info, lookup)
+	}
+
+	if ctx.Encrypt != nil {
+		patchIndRef(ctx.Encrypt, lookup)
+	}
+
+	if ctx.AdditionalStreams != nil {
+		patchArray(*ctx.AdditionalStreams, lookup)
+	}
+
+	patch := func(objNrs []int) {
+		for j, objNr := range objNrs {
+			objNrs[j] = lookup[objNr]
+		}
+	}
+
+	patch(l.docObjs)
+	patch(l.firstPage)
+	for _, objNrs := range l.pageObjs {
+		patch(objNrs)
+	}
+	for _, objNrs := range l.pageShared {
+		patch(objNrs)
+	}
+	patch(l.shared)
+	patch(l.other)
+
+	return linObjNr
+}
+
+// writeShallowObject writes the object objNr without any objects it refers to.
+func writeShallowObject(ctx *Context, objNr int) error {
+
+	if ctx.Write.HasWriteOffset(objNr) {
+		// eg. an indirect stream length.
+		return nil
+	}
+
+	entry := ctx.Table[objNr]
+	genNr := *entry.Generation
+
+	if ctx.Encrypt != nil && objNr == ctx.Encrypt.ObjectNumber.Value() {
+		d, err := ctx.DereferenceDict(*ctx.Encrypt)
+		if err != nil {
+			return err
+		}
+		return writeObject(ctx, objNr, genNr, d.PDFString())
+	}
+
+	switch o := entry.Object.(type) {
+
+	case Dict:
+		return writeDictObject(ctx, objNr, genNr, o)
+
+	case StreamDict:
+		if ctx.encryptedStrings() {
+			if _, err := encryptDeepObject(o, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R); err != nil {
+				return err
+			}
+		}
+		return writeStreamDictObject(ctx, objNr, genNr, o)
+
+	case Array:
+		return writeArrayObject(ctx, objNr, genNr, o)
+
+	case Integer:
+		return writeIntegerObject(ctx, objNr, genNr, o)
+
+	case Float:
+		return writeFloatObject(ctx, objNr, genNr, o)
+
+	case StringLiteral:
+		return writeStringLiteralObject(ctx, objNr, genNr, o)
+
+	case HexLiteral:
+		return writeHexLiteralObject(ctx, objNr, genNr, o)
+
+	case Boolean:
+		return writeBooleanObject(ctx, objNr, genNr, o)
+
+	case Name:
+		return writeNameObject(ctx, objNr, genNr, o)
+
+	}
+
+	return errors.Errorf("pdfcpu: writeShallowObject: undefined PDF object #%d %T\n", objNr, entry.Object)
+}
+
+func writeShallowObjects(ctx *Context, objNrs []int) error {
+	for _, objNr := range objNrs {
+		if err := writeShallowObject(ctx, objNr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bitWriter packs unsigned integers of arbitrary bit widths most significant bit first.
+type bitWriter struct {
+	bytes.Buffer
+	cur byte
+	n   uint
+}
+
+func (w *bitWriter) writeBits(v, nbits int) {
+	for i := nbits - 1; i >= 0; i-- {
+		w.cur = w.cur<<1 | byte(v>>uint(i)&1)
+		w.n++
+		if w.n == 8 {
+			w.WriteByte(w.cur)
+			w.cur, w.n = 0, 0
+		}
+	}
+}
+
+// flush pads the current byte with zero bits.
+func (w *bitWriter) flush() {
+	if w.n > 0 {
+		w.writeBits(0, int(8-w.n))
+	}
+}
+
+func (w *bitWriter) writeInts(ii []int, nbits int) {
+	for _, i := range ii {
+		w.writeBits(i, nbits)
+	}
+	w.flush()
+}
+
+func minMax(ii []int) (int, int) {
+	min, max := 0, 0
+	for j, i := range ii {
+		if j == 0 || i < min {
+			min = i
+		}
+		if j == 0 || i > max {
+			max = i
+		}
+	}
+	return min, max
+}
+
+func deltas(ii []int, min int) []int {
+	dd := make([]int, len(ii))
+	for j, i := range ii {
+		dd[j] = i - min
+	}
+	return dd
+}
+
+// linearizedLayout records the position of the objects written for a linearized file
+// as if there were no hint stream, see F.4
+type linearizedLayout struct {
+	base       int64         // offset of part 4
+	offsets    map[int]int64 // object offsets relative to base
+	pageStart  []int64       // page section offsets relative to base
+	pageEnd    []int64
+	objLengths map[int]int64
+}
+
+// hintStream returns the content of the primary hint stream and the offset of its shared object hint table.
+func (ll linearizedLayout) hintStream(l *linearization) ([]byte, int) {
+
+	// Page offset hint table, see F.4.1
+	n := len(l.pageObjs)
+
+	nobjs := make([]int, n)
+	lengths := make([]int, n)
+	nshared := make([]int, n)
+
+	nobjs[0] = len(l.firstPage)
+	for i := range l.pageObjs {
+		if i > 0 {
+			nobjs[i] = len(l.pageObjs[i])
+		}
+		lengths[i] = int(ll.pageEnd[i] - ll.pageStart[i])
+		nshared[i] = len(l.pageShared[i])
+	}
+
+	// Shared object identifiers are indices into the shared object hint table
+	// starting with the objects of the first page section.
+	ids := map[int]int{}
+	for i, objNr := range append(append([]int{}, l.firstPage...), l.shared...) {
+		ids[objNr] = i
+	}
+
+	maxID := 0
+	for _, objNrs := range l.pageShared {
+		for _, objNr := range objNrs {
+			if ids[objNr] > maxID {
+				maxID = ids[objNr]
+			}
+		}
+	}
+
+	minObjs, maxObjs := minMax(nobjs)
+	minLen, maxLen := minMax(lengths)
+	_, maxShared := minMax(nshared)
+
+	nbitsObjs := bits.Len(uint(maxObjs - minObjs))
+	nbitsLen := bits.Len(uint(maxLen - minLen))
+	nbitsShared := bits.Len(uint(maxShared))
+	nbitsID := bits.Len(uint(maxID))
+
+	w := &bitWriter{}
+
+	w.writeBits(minObjs, 32)
+	w.writeBits(int(ll.base+ll.pageStart[0]), 32)
+	w.writeBits(nbitsObjs, 16)
+	w.writeBits(minLen, 32)
+	w.writeBits(nbitsLen, 16)
+	w.writeBits(0, 32) // Content stream offsets are not used by viewers.
+	w.writeBits(0, 16)
+	w.writeBits(minLen, 32) // Content stream lengths are approximated by the page lengths.
+	w.writeBits(nbitsLen, 16)
+	w.writeBits(nbitsShared, 16)
+	w.writeBits(nbitsID, 16)
+	w.writeBits(0, 16) // No fractional positions of shared object references.
+	w.writeBits(4, 16)
+
+	w.writeInts(deltas(nobjs, minObjs), nbitsObjs)
+	w.writeInts(deltas(lengths, minLen), nbitsLen)
+	w.writeInts(nshared, nbitsShared)
+	for _, objNrs := range l.pageShared {
+		for _, objNr := range objNrs {
+			w.writeBits(ids[objNr], nbitsID)
+		}
+	}
+	w.flush()
+	w.writeInts(make([]int, n), 0)
+	w.writeInts(deltas(lengths, minLen), nbitsLen)
+
+	w.flush()
+	s := w.Len()
+
+	// Shared object hint table, see F.4.2
+	groups := append(append([]int{}, l.firstPage...), l.shared...)
+	groupLengths := make([]int, len(groups))
+	for i, objNr := range groups {
+		groupLengths[i] = int(ll.objLengths[objNr])
+	}
+	minGroupLen, maxGroupLen := minMax(groupLengths)
+	nbitsGroupLen := bits.Len(uint(maxGroupLen - minGroupLen))
+
+	var firstShared, firstSharedOffset int
+	if len(l.shared) > 0 {
+		firstShared = l.shared[0]
+		firstSharedOffset = int(ll.base + ll.offsets[firstShared])
+	}
+
+	w.writeBits(firstShared, 32)
+	w.writeBits(firstSharedOffset, 32)
+	w.writeBits(len(l.firstPage), 32)
+	w.writeBits(len(groups), 32)
+	w.writeBits(0, 16) // Each group consists of a single object.
+	w.writeBits(minGroupLen, 32)
+	w.writeBits(nbitsGroupLen, 16)
+
+	w.writeInts(deltas(groupLengths, minGroupLen), nbitsGroupLen)
+	w.writeInts(make([]int, len(groups)), 1) // No MD5 signatures.
+
+	return w.Bytes(), s
+}
+
+// padded returns s padded with trailing blanks to length n.
+func padded(s string, n int) string {
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+func xrefEntry(offset int64, genNr int, eol string) string {
+	return fmt.Sprintf("%010d %05d n%2s", offset, genNr, eol)
+}
+
+// writeLinearized generates a linearized PDF file for fast web view, see Annex F.
+func writeLinearized(ctx *Context) error {
+
+	if len(ctx.Write.SelectedPages) > 0 {
+		return errors.New("pdfcpu: linearization of page selections is not supported")
+	}
+
+	// Ensure there is no root version.
+	if ctx.RootVersion != nil {
+		ctx.RootDict.Delete("Version")
+	}
+
+	if err := ctx.BindNameTrees(); err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	l, err := ctx.linearization()
+	if err != nil {
+		return err
+	}
+
+	linObjNr := ctx.renumber(l)
+	hintObjNr := linObjNr + 1 + len(l.docObjs) + len(l.firstPage)
+	size := hintObjNr + 1
+	ctx.Size = &size
+
+	w := ctx.Write
+	eol := w.Eol
+	out := w.Writer
+
+	// Write parts 4 and 6 to 9 into memory for the offsets to be known upfront.
+	var body bytes.Buffer
+	w.Writer = bufio.NewWriter(&body)
+	w.Offset = 0
+	w.Table = map[int]int64{}
+
+	ll := linearizedLayout{pageStart: make([]int64, ctx.PageCount), pageEnd: make([]int64, ctx.PageCount)}
+
+	if err := writeShallowObjects(ctx, l.docObjs); err != nil {
+		return err
+	}
+
+	ll.pageStart[0] = w.Offset
+	if err := writeShallowObjects(ctx, l.firstPage); err != nil {
+		return err
+	}
+	ll.pageEnd[0] = w.Offset
+
+	for i := 1; i < ctx.PageCount; i++ {
+		ll.pageStart[i] = w.Offset
+		if err := writeShallowObjects(ctx, l.pageObjs[i]); err != nil {
+			return err
+		}
+		ll.pageEnd[i] = w.Offset
+	}
+
+	if err := writeShallowObjects(ctx, l.shared); err != nil {
+		return err
+	}
+
+	if err := writeShallowObjects(ctx, l.other); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	ll.offsets = w.Table
+	ll.objLengths = map[int]int64{}
+
+	objNrs := make([]int, 0, len(w.Table))
+	for objNr := range w.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Slice(objNrs, func(i, j int) bool { return w.Table[objNrs[i]] < w.Table[objNrs[j]] })
+	for i, objNr := range objNrs {
+		end := int64(body.Len())
+		if i < len(objNrs)-1 {
+			end = w.Table[objNrs[i+1]]
+		}
+		ll.objLengths[objNr] = end - w.Table[objNr]
+	}
+
+	header := "%PDF-" + V17.String() + eol + "%\xe2\xe3\xcf\xd3" + eol
+
+	// The linearization parameter dict and the first page trailer get padded for a fixed length.
+	const max = 9999999999
+	linDictFormat := "<</Linearized 1/L %d/H[%d %d]/O %d/E %d/N %d/T %d>>"
+	linDictLen := len(fmt.Sprintf(linDictFormat, max, max, max, max, max, max, max))
+	linObjLen := len(fmt.Sprintf("%d 0 obj%s%sendobj%s", linObjNr, eol, eol, eol)) + linDictLen
+
+	trailerDict := func(prev int64) string {
+		d := NewDict()
+		d.Insert("Size", Integer(size))
+		d.Insert("Root", *ctx.Root)
+		if ctx.Info != nil {
+			d.Insert("Info", *ctx.Info)
+		}
+		if ctx.Encrypt != nil && ctx.EncKey != nil {
+			d.Insert("Encrypt", *ctx.Encrypt)
+		}
+		if ctx.ID != nil {
+			d.Insert("ID", ctx.ID)
+		}
+		s := d.PDFString()
+		return s[:len(s)-2] + fmt.Sprintf("/Prev %d>>", prev)
+	}
+	trailerLen := len(trailerDict(max))
+
+	firstXRefLen := len("xref"+eol+fmt.Sprintf("%d %d", linObjNr, size-linObjNr)+eol) +
+		20*(size-linObjNr) +
+		len("trailer"+eol+eol+"startxref"+eol+"0"+eol+"%%EOF"+eol) + trailerLen
+
+	ll.base = int64(len(header) + linObjLen + firstXRefLen)
+
+	// Offsets in hint tables are computed as if the hint stream was absent.
+	hints, s := ll.hintStream(l)
+
+	sd := NewStreamDict(Dict(map[string]Object{"S": Integer(s)}), 0, nil, nil, []PDFFilter{{Name: filter.Flate, DecodeParms: nil}})
+	sd.Content = hints
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	var hint bytes.Buffer
+	w.Writer = bufio.NewWriter(&hint)
+	if err := writeStreamDictObject(ctx, hintObjNr, 0, sd); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	hintOffset := ll.base + ll.pageStart[0]
+	hintLen := int64(hint.Len())
+
+	offset := func(objNr int) int64 {
+		if ll.offsets[objNr] < ll.pageStart[0] {
+			return ll.base + ll.offsets[objNr]
+		}
+		return ll.base + hintLen + ll.offsets[objNr]
+	}
+
+	mainXRefOffset := ll.base + hintLen + int64(body.Len())
+
+	var mainXRef strings.Builder
+	mainXRef.WriteString("xref" + eol + fmt.Sprintf("0 %d", linObjNr) + eol)
+	firstEntryOffset := mainXRefOffset + int64(mainXRef.Len()) - 1
+	mainXRef.WriteString(fmt.Sprintf("%010d %05d f%2s", 0, 65535, eol))
+	for objNr := 1; objNr < linObjNr; objNr++ {
+		mainXRef.WriteString(xrefEntry(offset(objNr), *ctx.Table[objNr].Generation, eol))
+	}
+	mainXRef.WriteString("trailer" + eol + fmt.Sprintf("<</Size %d>>", linObjNr) + eol)
+	mainXRef.WriteString("startxref" + eol + fmt.Sprintf("%d", len(header)+linObjLen) + eol + "%%EOF" + eol)
+
+	fileLen := mainXRefOffset + int64(mainXRef.Len())
+
+	var firstXRef strings.Builder
+	firstXRef.WriteString("xref" + eol + fmt.Sprintf("%d %d", linObjNr, size-linObjNr) + eol)
+	firstXRef.WriteString(xrefEntry(int64(len(header)), 0, eol))
+	for objNr := linObjNr + 1; objNr < hintObjNr; objNr++ {
+		firstXRef.WriteString(xrefEntry(offset(objNr), *ctx.Table[objNr].Generation, eol))
+	}
+	firstXRef.WriteString(xrefEntry(hintOffset, 0, eol))
+	firstXRef.WriteString("trailer" + eol + padded(trailerDict(mainXRefOffset), trailerLen) + eol + "startxref" + eol + "0" + eol + "%%EOF" + eol)
+
+	linDict := padded(fmt.Sprintf(linDictFormat,
+		fileLen,
+		hintOffset, hintLen,
+		l.firstPage[0],
+		ll.base+hintLen+ll.pageEnd[0],
+		ctx.PageCount,
+		firstEntryOffset), linDictLen)
+
+	// Assemble the file.
+	w.Writer = out
+	for _, s := range []string{
+		header,
+		fmt.Sprintf("%d 0 obj%s%s%sendobj%s", linObjNr, eol, linDict, eol, eol),
+		firstXRef.String(),
+		body.String()[:ll.pageStart[0]],
+		hint.String(),
+		body.String()[ll.pageStart[0]:],
+		mainXRef.String(),
+	} {
+		if _, err := w.WriteString(s); err != nil {
+			return err
+		}
+	}
+	w.Offset = fileLen
+
+	w.Table = map[int]int64{linObjNr: int64(len(header)), hintObjNr: hintOffset}
+	for objNr := range ll.offsets {
+		w.Table[objNr] = offset(objNr)
+	}
+
+	return nil
+}
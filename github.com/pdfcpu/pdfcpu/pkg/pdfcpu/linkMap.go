@@ -0,0 +1,286 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// LinkArea represents the clickable area of a link annotation in the pixel space of a rendered page
+// with the origin in the upper left corner.
+type LinkArea struct {
+	X0, Y0, X1, Y1 int    // upper left and lower right corner in pixels
+	URI            string // target of a URI action
+	Dest           string // named destination
+	Page           int    // target page of a destination or 0
+}
+
+func (la LinkArea) String() string {
+	s := fmt.Sprintf("%d,%d,%d,%d", la.X0, la.Y0, la.X1, la.Y1)
+	if la.URI != "" {
+		s += " uri:" + la.URI
+	}
+	if la.Dest != "" {
+		s += " dest:" + la.Dest
+	}
+	if la.Page > 0 {
+		s += fmt.Sprintf(" page:%d", la.Page)
+	}
+	return s
+}
+
+// pixelPoint transforms x,y from user space into the pixel space of the visible region vr
+// rendered at scale s after applying the page rotation rot.
+func pixelPoint(x, y float64, vr *Rectangle, rot int, s float64) (float64, float64) {
+
+	u, v := x-vr.LL.X, y-vr.LL.Y
+	w, h := vr.Width(), vr.Height()
+
+	switch rot {
+	case 90:
+		return v * s, u * s
+	case 180:
+		return (w - u) * s, v * s
+	case 270:
+		return (h - v) * s, (w - u) * s
+	}
+
+	return u * s, (h - v) * s
+}
+
+func (xRefTable *XRefTable) destPage(o Object) (int, error) {
+
+	o, err := xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return 0, err
+	}
+
+	switch o := o.(type) {
+
+	case Array:
+		if len(o) == 0 {
+			return 0, nil
+		}
+		ir, ok := o[0].(IndirectRef)
+		if !ok {
+			// Destinations of remote go-to actions use page numbers.
+			return 0, nil
+		}
+		return xRefTable.PageNumber(ir.ObjectNumber.Value())
+
+	case Dict:
+		return xRefTable.destPage(o["D"])
+
+	}
+
+	return 0, nil
+}
+
+// resolveDest sets the target of la for the destination o, see 12.3.2
+func (xRefTable *XRefTable) resolveDest(la *LinkArea, o Object) error {
+
+	o, err := xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return err
+	}
+
+	switch o := o.(type) {
+
+	case Name, StringLiteral, HexLiteral:
+		var name string
+		if n, ok := o.(Name); ok {
+			name = n.Value()
+		} else if name, err = Text(o); err != nil {
+			return err
+		}
+		la.Dest = name
+		if err := xRefTable.LocateNameTree("Dests", false); err != nil {
+			return err
+		}
+		if xRefTable.Names["Dests"] == nil {
+			return nil
+		}
+		if dest, ok := xRefTable.Names["Dests"].Value(name); ok {
+			la.Page, err = xRefTable.destPage(dest)
+		}
+		return err
+
+	case Array:
+		la.Page, err = xRefTable.destPage(o)
+		return err
+
+	}
+
+	return nil
+}
+
+func (xRefTable *XRefTable) resolveLinkTarget(la *LinkArea, d Dict) error {
+
+	if o, found := d.Find("Dest"); found {
+		return xRefTable.resolveDest(la, o)
+	}
+
+	o, found := d.Find("A")
+	if !found {
+		return nil
+	}
+
+	action, err := xRefTable.DereferenceDict(o)
+	if err != nil || action == nil {
+		return err
+	}
+
+	s := action.NameEntry("S")
+	if s == nil {
+		return nil
+	}
+
+	switch *s {
+
+	case "URI":
+		o, found := action.Find("URI")
+		if !found {
+			return nil
+		}
+		la.URI, err = xRefTable.DereferenceText(o)
+		return err
+
+	case "GoTo":
+		return xRefTable.resolveDest(la, action["D"])
+
+	}
+
+	return nil
+}
+
+// annotRect returns the normalized rectangle of the annotation dict d.
+func (xRefTable *XRefTable) annotRect(d Dict) (*Rectangle, error) {
+
+	a, err := xRefTable.DereferenceArray(d["Rect"])
+	if err != nil || len(a) != 4 {
+		return nil, errors.New("pdfcpu: corrupt annotation rect")
+	}
+
+	r, err := rect(xRefTable, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return Rect(
+		math.Min(r.LL.X, r.UR.X), math.Min(r.LL.Y, r.UR.Y),
+		math.Max(r.LL.X, r.UR.X), math.Max(r.LL.Y, r.UR.Y)), nil
+}
+
+// pageLink is a link annotation of a page along with its resolved target.
+type pageLink struct {
+	r      *Rectangle // normalized annotation rectangle in user space
+	target LinkArea   // target only
+}
+
+// pageLinks returns all link annotations of the page dict d.
+func (xRefTable *XRefTable) pageLinks(d Dict) ([]pageLink, error) {
+
+	o, found := d.Find("Annots")
+	if !found {
+		return nil, nil
+	}
+
+	annots, err := xRefTable.DereferenceArray(o)
+	if err != nil {
+		return nil, err
+	}
+
+	ll := []pageLink{}
+
+	for _, o := range annots {
+
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil || d.Subtype() == nil || *d.Subtype() != "Link" {
+			continue
+		}
+
+		r, err := xRefTable.annotRect(d)
+		if err != nil {
+			return nil, err
+		}
+
+		l := pageLink{r: r}
+
+		if err := xRefTable.resolveLinkTarget(&l.target, d); err != nil {
+			return nil, err
+		}
+
+		ll = append(ll, l)
+	}
+
+	return ll, nil
+}
+
+// LinkMap returns the clickable areas of all link annotations of page pageNr rendered at dpi.
+// The page rotation and the crop box in effect are taken into account.
+func LinkMap(ctx *Context, pageNr, dpi int) ([]LinkArea, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return nil, errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	if dpi <= 0 {
+		return nil, errors.Errorf("pdfcpu: invalid dpi: %d", dpi)
+	}
+
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	vr := viewPort(ctx.XRefTable, inhPAttrs)
+	rot := (inhPAttrs.rotate%360 + 360) % 360
+	s := float64(dpi) / 72
+
+	ll, err := ctx.pageLinks(d)
+	if err != nil || ll == nil {
+		return nil, err
+	}
+
+	laa := []LinkArea{}
+
+	for _, l := range ll {
+
+		x0, y0 := pixelPoint(l.r.LL.X, l.r.LL.Y, vr, rot, s)
+		x1, y1 := pixelPoint(l.r.UR.X, l.r.UR.Y, vr, rot, s)
+
+		la := l.target
+		la.X0 = int(math.Round(math.Min(x0, x1)))
+		la.Y0 = int(math.Round(math.Min(y0, y1)))
+		la.X1 = int(math.Round(math.Max(x0, x1)))
+		la.Y1 = int(math.Round(math.Max(y0, y1)))
+
+		laa = append(laa, la)
+	}
+
+	return laa, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestID is the reserved id of the embedded file holding the assembly manifest.
+const ManifestID = "pdfcpu-manifest.json"
+
+var errNoManifest = errors.New("pdfcpu: no manifest available")
+
+// ManifestSource describes a source file contributing pages to an assembled PDF.
+type ManifestSource struct {
+	FileName string `json:"file"`
+	Pages    string `json:"pages,omitempty"`    // page selection eg. 1-3,5
+	Checksum string `json:"checksum,omitempty"` // checksum of the source file
+}
+
+// Manifest is a machine readable description of how a PDF has been assembled.
+type Manifest struct {
+	Sources  []ManifestSource `json:"sources"`
+	Checksum string           `json:"checksum,omitempty"` // checksum of the assembled file
+}
+
+// SetManifest embeds m as JSON file using the reserved id ManifestID replacing any existing manifest.
+func (ctx *Context) SetManifest(m Manifest) error {
+
+	bb, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	xRefTable := ctx.XRefTable
+	if !xRefTable.Valid {
+		if err := xRefTable.LocateNameTree("EmbeddedFiles", false); err != nil {
+			return err
+		}
+	}
+
+	if root := xRefTable.Names["EmbeddedFiles"]; root != nil {
+		if _, found := root.Value(ManifestID); found {
+			if _, err := ctx.RemoveAttachments([]string{ManifestID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	a := Attachment{Reader: bytes.NewReader(bb), ID: ManifestID, Desc: "assembly manifest", ModTime: &now}
+
+	return ctx.AddAttachment(a, false)
+}
+
+// Manifest returns the embedded assembly manifest.
+func (ctx *Context) Manifest() (*Manifest, error) {
+
+	xRefTable := ctx.XRefTable
+	if !xRefTable.Valid {
+		if err := xRefTable.LocateNameTree("EmbeddedFiles", false); err != nil {
+			return nil, err
+		}
+	}
+
+	root := xRefTable.Names["EmbeddedFiles"]
+	if root == nil {
+		return nil, errNoManifest
+	}
+
+	o, found := root.Value(ManifestID)
+	if !found {
+		return nil, errNoManifest
+	}
+
+	decode := true
+	sd, _, _, _, err := fileSpecStreamDictInfo(xRefTable, ManifestID, o, decode)
+	if err != nil {
+		return nil, err
+	}
+	if sd == nil {
+		return nil, errors.New("pdfcpu: corrupt manifest")
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(sd.Content, m); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: corrupt manifest")
+	}
+
+	return m, nil
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"fmt"
+
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 )
 
@@ -288,6 +290,131 @@ func mergeDuplicateObjNumberIntSets(ctxSource, ctxDest *Context) {
 	log.Debug.Println("mergeDuplicateObjNumberIntSets end")
 }
 
+// mergeResourceDicts adds the resources of src missing in dest.
+func mergeResourceDicts(xRefTable *XRefTable, src, dest Dict) error {
+	for k, o := range src {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		destObj, found := dest.Find(k)
+		if !found || d == nil {
+			if !found {
+				dest.Insert(k, o)
+			}
+			continue
+		}
+		destDict, err := xRefTable.DereferenceDict(destObj)
+		if err != nil || destDict == nil {
+			continue
+		}
+		for k1, o1 := range d {
+			if _, found := destDict.Find(k1); !found {
+				destDict.Insert(k1, o1)
+			}
+		}
+	}
+	return nil
+}
+
+// appendArrayEntry appends the array entry key of src to the array entry key of dest.
+func appendArrayEntry(xRefTable *XRefTable, src, dest Dict, key string) error {
+	a1, err := xRefTable.DereferenceArray(src[key])
+	if err != nil || len(a1) == 0 {
+		return err
+	}
+	a, err := xRefTable.DereferenceArray(dest[key])
+	if err != nil {
+		return err
+	}
+	dest.Update(key, append(append(Array{}, a...), a1...))
+	return nil
+}
+
+// mergeAcroForms merges the interactive form of ctxSource into the interactive form of ctxDest.
+// Fields and calculation order get appended, NeedAppearances and SigFlags get combined.
+// ctxSource's objects need to be patched and appended to ctxDest.
+func mergeAcroForms(ctxSource, ctxDest *Context) error {
+
+	rootDictSource, err := ctxSource.Catalog()
+	if err != nil {
+		return err
+	}
+
+	o, found := rootDictSource.Find("AcroForm")
+	if !found {
+		return nil
+	}
+
+	srcForm, err := ctxDest.DereferenceDict(o)
+	if err != nil || srcForm == nil {
+		return err
+	}
+
+	rootDictDest, err := ctxDest.Catalog()
+	if err != nil {
+		return err
+	}
+
+	destForm, err := ctxDest.DereferenceDict(rootDictDest["AcroForm"])
+	if err != nil {
+		return err
+	}
+
+	if destForm == nil {
+		rootDictDest.Update("AcroForm", o)
+		return nil
+	}
+
+	// Field references are valid since ctxSource's object numbers have been patched.
+	for _, k := range []string{"Fields", "CO"} {
+		if err := appendArrayEntry(ctxDest.XRefTable, srcForm, destForm, k); err != nil {
+			return err
+		}
+	}
+
+	if b := srcForm.BooleanEntry("NeedAppearances"); b != nil && *b {
+		destForm.Update("NeedAppearances", Boolean(true))
+	}
+
+	if i := srcForm.IntEntry("SigFlags"); i != nil {
+		f := *i
+		if j := destForm.IntEntry("SigFlags"); j != nil {
+			f |= *j
+		}
+		destForm.Update("SigFlags", Integer(f))
+	}
+
+	if _, found := destForm.Find("DA"); !found {
+		if o, found := srcForm.Find("DA"); found {
+			destForm.Insert("DA", o)
+		}
+	}
+
+	if o, found := srcForm.Find("DR"); found {
+		dr, err := ctxDest.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		destDR, err := ctxDest.DereferenceDict(destForm["DR"])
+		if err != nil {
+			return err
+		}
+		if destDR == nil {
+			destForm.Update("DR", o)
+		} else if dr != nil {
+			if err := mergeResourceDicts(ctxDest.XRefTable, dr, destDR); err != nil {
+				return err
+			}
+		}
+	}
+
+	// XFA forms do not describe the merged fields.
+	destForm.Delete("XFA")
+
+	return nil
+}
+
 // MergeXRefTables merges Context ctxSource into ctxDest by appending its page tree.
 func MergeXRefTables(ctxSource, ctxDest *Context) (err error) {
 
@@ -305,6 +432,12 @@ func MergeXRefTables(ctxSource, ctxDest *Context) (err error) {
 	log.Debug.Println("appendSourceObjectsToDest")
 	appendSourceObjectsToDest(ctxSource, ctxDest)
 
+	// Merge ctxSource's interactive form into ctxDest's.
+	log.Debug.Println("mergeAcroForms")
+	if err = mergeAcroForms(ctxSource, ctxDest); err != nil {
+		return err
+	}
+
 	// Mark source's root object as free.
 	err = ctxDest.DeleteObject(int(ctxSource.Root.ObjectNumber))
 	if err != nil {
@@ -328,3 +461,132 @@ func MergeXRefTables(ctxSource, ctxDest *Context) (err error) {
 
 	return nil
 }
+
+// renameDestRefs renames references to named destinations within o according to m.
+func renameDestRefs(o Object, m map[string]string) {
+	switch o := o.(type) {
+
+	case Dict:
+		for k, v := range o {
+			// Destinations of outline items and link annotations or GoTo actions, see 12.3.2.3
+			if k == "Dest" || k == "D" && o.NameEntry("S") != nil && *o.NameEntry("S") == "GoTo" {
+				switch v := v.(type) {
+				case Name:
+					if s, ok := m[v.Value()]; ok {
+						o[k] = Name(s)
+					}
+				case StringLiteral:
+					if s, ok := m[v.Value()]; ok {
+						o[k] = StringLiteral(s)
+					}
+				}
+			}
+			renameDestRefs(v, m)
+		}
+
+	case StreamDict:
+		renameDestRefs(o.Dict, m)
+
+	case Array:
+		for _, o := range o {
+			renameDestRefs(o, m)
+		}
+	}
+}
+
+// renameCollidingDests renames all named destinations of ctxSource also defined by ctxDest
+// and returns the resulting entries of the Dests name tree of ctxSource as key value pairs.
+// The returned array is registered with ctxSource in order to get patched during the merge.
+func renameCollidingDests(ctxSource, ctxDest *Context) (Array, error) {
+	src := ctxSource.Names["Dests"]
+	if src == nil {
+		return nil, nil
+	}
+
+	destKeys, used := map[string]bool{}, map[string]bool{}
+	if dest := ctxDest.Names["Dests"]; dest != nil {
+		kk, err := dest.KeyList()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range kk {
+			destKeys[k], used[k] = true, true
+		}
+	}
+
+	kk, err := src.KeyList()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range kk {
+		used[k] = true
+	}
+
+	m := map[string]string{}
+	a := Array{}
+
+	err = src.Process(ctxSource.XRefTable, func(xRefTable *XRefTable, k string, v Object) error {
+		if destKeys[k] {
+			k1 := k
+			for i := 2; used[k1]; i++ {
+				k1 = fmt.Sprintf("%s_%d", k, i)
+			}
+			used[k1] = true
+			m[k] = k1
+			k = k1
+		}
+		a = append(a, StringLiteral(k), v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m) > 0 {
+		for _, e := range ctxSource.Table {
+			if e != nil && !e.Free && e.Object != nil {
+				renameDestRefs(e.Object, m)
+			}
+		}
+	}
+
+	if _, err := ctxSource.IndRefForNewObject(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// MergeXRefTablesWithNamedDests merges Context ctxSource into ctxDest by appending its page tree
+// and adding its named destinations to the Dests name tree of ctxDest.
+// Named destinations already defined by ctxDest get renamed using a numeric suffix.
+func MergeXRefTablesWithNamedDests(ctxSource, ctxDest *Context) error {
+
+	a, err := renameCollidingDests(ctxSource, ctxDest)
+	if err != nil {
+		return err
+	}
+
+	if err := MergeXRefTables(ctxSource, ctxDest); err != nil {
+		return err
+	}
+
+	if len(a) == 0 {
+		return nil
+	}
+
+	if err := ctxDest.LocateNameTree("Dests", true); err != nil {
+		return err
+	}
+
+	n := ctxDest.Names["Dests"]
+
+	for i := 0; i < len(a); i += 2 {
+		if err := n.Add(ctxDest.XRefTable, a[i].(StringLiteral).Value(), a[i+1]); err != nil {
+			return err
+		}
+	}
+
+	// Sync up right away because validation internalizes name trees from scratch.
+	return ctxDest.bindNameTreeNode("Dests", n, true)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+)
+
+// dateKeys are the keys of date entries in the document info dict, annotations,
+// signatures, embedded file parameters and page piece dicts.
+var dateKeys = []string{"CreationDate", "ModDate", "M", "LastModified"}
+
+// normalizeDates rewrites malformed date strings of o found by dateKeys in canonical form,
+// including any direct objects nested in o.
+// The keys of date strings that could not be parsed get passed to report.
+func normalizeDates(o Object, report func(key, s string)) int {
+	c := 0
+
+	switch o := o.(type) {
+
+	case Dict:
+		for _, k := range dateKeys {
+			v, found := o.Find(k)
+			if !found {
+				continue
+			}
+			var s string
+			switch v := v.(type) {
+			case StringLiteral:
+				s = v.Value()
+			case HexLiteral:
+				bb, err := v.Bytes()
+				if err != nil {
+					report(k, v.String())
+					continue
+				}
+				s = string(bb)
+			default:
+				continue
+			}
+			if _, ok := DateTime(s); ok {
+				continue
+			}
+			d, ok := DateTimeLenient(s)
+			if !ok {
+				report(k, s)
+				continue
+			}
+			o.Update(k, StringLiteral(DateString(d)))
+			c++
+		}
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			c += normalizeDates(o[k], func(key, s string) { report(k+"."+key, s) })
+		}
+
+	case StreamDict:
+		c += normalizeDates(o.Dict, report)
+
+	case Array:
+		for i, v := range o {
+			c += normalizeDates(v, func(key, s string) { report(fmt.Sprintf("[%d].%s", i, key), s) })
+		}
+	}
+
+	return c
+}
+
+// NormalizeDates rewrites malformed date strings throughout the document in canonical
+// D:YYYYMMDDHHmmSS+HH'mm' form using a lenient date parser.
+// Valid dates are left untouched.
+// It returns the number of rewritten dates and a description of each date that could not be parsed.
+func (xRefTable *XRefTable) NormalizeDates() (int, []string) {
+	c := 0
+	ss := []string{}
+
+	objNrs := make([]int, 0, len(xRefTable.Table))
+	for objNr := range xRefTable.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		entry := xRefTable.Table[objNr]
+		if entry == nil || entry.Free || entry.Object == nil {
+			continue
+		}
+		c += normalizeDates(entry.Object, func(key, s string) {
+			ss = append(ss, fmt.Sprintf("obj#%d %s: invalid date <%s>", objNr, key, s))
+		})
+	}
+
+	return c, ss
+}
@@ -60,6 +60,9 @@ var nupParamMap = nUpParamMap{
 	"orientation": parseOrientation,
 	"border":      parseElementBorder,
 	"margin":      parseElementMargin,
+	"gutter":      parseElementGutter,
+	"creep":       parseCreep,
+	"inline":      parseInlineContent,
 }
 
 // Handle applies parameter completion and if successful
@@ -95,8 +98,12 @@ type NUp struct {
 	Grid         *Dim        // Intra page grid dimensions eg (2,2)
 	PageGrid     bool        // Create a mxn grid of pages for PDF inputfiles only (think "extra page n-Up").
 	ImgInputFile bool        // Process image or PDF input files.
-	Margin       int         // Cropbox for n-Up content.
-	Border       bool        // Draw bounding box.
+	Margin       float64     // Space between the page edges and the grid in points.
+	Gutter       float64     // Space between adjacent grid cells in points.
+	Border       bool        // Draw a bounding box around each grid cell.
+	Inline       bool        // Copy single stream page content inline instead of wrapping it into a form XObject.
+	Booklet      bool        // Impose pages 2-up for saddle stitch binding.
+	Creep        float64     // Booklet creep: shift in points of the pages of the innermost sheet towards the spine.
 }
 
 // DefaultNUpConfig returns the default NUp configuration.
@@ -105,6 +112,7 @@ func DefaultNUpConfig() *NUp {
 		PageSize: "A4",
 		Orient:   RightDown,
 		Margin:   3,
+		Gutter:   6,
 		Border:   true,
 	}
 }
@@ -195,18 +203,64 @@ func parseElementBorder(s string, nup *NUp) error {
 	return nil
 }
 
+func parseInlineContent(s string, nup *NUp) error {
+
+	switch strings.ToLower(s) {
+	case "on", "true":
+		nup.Inline = true
+	case "off", "false":
+		nup.Inline = false
+	default:
+		return errors.New("pdfcpu: nUp inline, please provide one of: on/off true/false")
+	}
+
+	return nil
+}
+
 func parseElementMargin(s string, nup *NUp) error {
 
-	i, err := strconv.Atoi(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	if f < 0 {
+		return errors.New("pdfcpu: nUp margin, Please provide a positive value")
+	}
+
+	nup.Margin = f
+
+	return nil
+}
+
+func parseCreep(s string, nup *NUp) error {
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	if f < 0 {
+		return errors.New("pdfcpu: booklet creep, Please provide a positive value")
+	}
+
+	nup.Creep = f
+
+	return nil
+}
+
+func parseElementGutter(s string, nup *NUp) error {
+
+	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		return err
 	}
 
-	if i < 0 {
-		return errors.New("pdfcpu: nUp margin, Please provide a positive int value")
+	if f < 0 {
+		return errors.New("pdfcpu: nUp gutter, Please provide a positive value")
 	}
 
-	nup.Margin = i
+	nup.Gutter = f
 
 	return nil
 }
@@ -225,6 +279,8 @@ func ParseNUpDetails(s string, nup *NUp) error {
 
 	ss := strings.Split(s, ",")
 
+	var gutter bool
+
 	for _, s := range ss {
 
 		ss1 := strings.Split(s, ":")
@@ -238,6 +294,15 @@ func ParseNUpDetails(s string, nup *NUp) error {
 		if err := nupParamMap.Handle(paramPrefix, paramValueStr, nup); err != nil {
 			return err
 		}
+
+		if strings.HasPrefix("gutter", paramPrefix) {
+			gutter = true
+		}
+	}
+
+	// Unless provided the gutter spaces adjacent cells like the margin applied to both of them.
+	if !gutter {
+		nup.Gutter = 2 * nup.Margin
 	}
 
 	return nil
@@ -257,6 +322,20 @@ func PDFNUpConfig(val int, desc string) (*NUp, error) {
 	return nup, nil
 }
 
+// PDFBookletConfig returns an NUp configuration for imposing PDF files as saddle stitch booklets.
+// Unless a paper size or dimensions are given, each sheet holds two pages side by side at their original size.
+func PDFBookletConfig(desc string) (*NUp, error) {
+	nup := DefaultNUpConfig()
+	nup.Booklet = true
+	if desc != "" {
+		if err := ParseNUpDetails(desc, nup); err != nil {
+			return nil, err
+		}
+	}
+	nup.Grid = &Dim{2, 1}
+	return nup, nil
+}
+
 // ImageNUpConfig returns an NUp configuration for Nup-ing image files.
 func ImageNUpConfig(val int, desc string) (*NUp, error) {
 	nup, err := PDFNUpConfig(val, desc)
@@ -333,7 +412,9 @@ func ParseNUpGridDefinition(cols, rows int, nUp *NUp) error {
 	return nil
 }
 
-func rectsForGrid(nup *NUp) []*Rectangle {
+// rectsForGrid returns the grid cells in the order of placement.
+// The cells share the page area left after reserving the margin along the page edges and the gutter between adjacent cells.
+func rectsForGrid(nup *NUp) ([]*Rectangle, error) {
 
 	cols := int(nup.Grid.Width)
 	rows := int(nup.Grid.Height)
@@ -341,19 +422,29 @@ func rectsForGrid(nup *NUp) []*Rectangle {
 	maxX := float64(nup.PageDim.Width)
 	maxY := float64(nup.PageDim.Height)
 
-	gw := maxX / float64(cols)
-	gh := maxY / float64(rows)
+	gutter := nup.Gutter
+
+	gw := (maxX - 2*nup.Margin - float64(cols-1)*gutter) / float64(cols)
+	gh := (maxY - 2*nup.Margin - float64(rows-1)*gutter) / float64(rows)
+
+	if gw <= 0 || gh <= 0 {
+		return nil, errors.Errorf("pdfcpu: nup: margin %.2f and gutter %.2f exceed page dimensions %s", nup.Margin, gutter, *nup.PageDim)
+	}
 
 	var llx, lly float64
 	rr := []*Rectangle{}
 
+	// Cell origins advance by cell size plus gutter.
+	ox := func(j int) float64 { return nup.Margin + float64(j)*(gw+gutter) }
+	oy := func(i int) float64 { return nup.Margin + float64(i)*(gh+gutter) }
+
 	switch nup.Orient {
 
 	case RightDown:
 		for i := rows - 1; i >= 0; i-- {
 			for j := 0; j < cols; j++ {
-				llx = float64(j) * gw
-				lly = float64(i) * gh
+				llx = ox(j)
+				lly = oy(i)
 				rr = append(rr, Rect(llx, lly, llx+gw, lly+gh))
 			}
 		}
@@ -361,8 +452,8 @@ func rectsForGrid(nup *NUp) []*Rectangle {
 	case DownRight:
 		for i := 0; i < cols; i++ {
 			for j := rows - 1; j >= 0; j-- {
-				llx = float64(i) * gw
-				lly = float64(j) * gh
+				llx = ox(i)
+				lly = oy(j)
 				rr = append(rr, Rect(llx, lly, llx+gw, lly+gh))
 			}
 		}
@@ -370,8 +461,8 @@ func rectsForGrid(nup *NUp) []*Rectangle {
 	case LeftDown:
 		for i := rows - 1; i >= 0; i-- {
 			for j := cols - 1; j >= 0; j-- {
-				llx = float64(j) * gw
-				lly = float64(i) * gh
+				llx = ox(j)
+				lly = oy(i)
 				rr = append(rr, Rect(llx, lly, llx+gw, lly+gh))
 			}
 		}
@@ -379,14 +470,14 @@ func rectsForGrid(nup *NUp) []*Rectangle {
 	case DownLeft:
 		for i := cols - 1; i >= 0; i-- {
 			for j := rows - 1; j >= 0; j-- {
-				llx = float64(i) * gw
-				lly = float64(j) * gh
+				llx = ox(i)
+				lly = oy(j)
 				rr = append(rr, Rect(llx, lly, llx+gw, lly+gh))
 			}
 		}
 	}
 
-	return rr
+	return rr, nil
 }
 
 // Calculate the matrix for transforming rectangle r1 with lower left corner in the origin into rectangle r2.
@@ -456,7 +547,8 @@ func calcTransMatrixForRect(r1, r2 *Rectangle, image bool) matrix {
 	return m1.multiply(m2).multiply(m3)
 }
 
-func nUpTilePDFBytes(wr io.Writer, r1, r2 *Rectangle, formResID string, nup *NUp) {
+// nUpTileMatrix paints the optional bounding box of tile r2 and returns the matrix fitting r1 into r2.
+func nUpTileMatrix(wr io.Writer, r1, r2 *Rectangle, nup *NUp) matrix {
 
 	// paint bounding box
 	if nup.Border {
@@ -465,19 +557,48 @@ func nUpTilePDFBytes(wr io.Writer, r1, r2 *Rectangle, formResID string, nup *NUp
 		)
 	}
 
-	// Apply margin.
-	croppedRect := r2.CroppedCopy(float64(nup.Margin))
+	return nUpTileTransform(r1, r2, nup)
+}
+
+// nUpTileTransform returns the matrix fitting r1 into tile r2.
+func nUpTileTransform(r1, r2 *Rectangle, nup *NUp) matrix {
+
+	return calcTransMatrixForRect(r1, r2, nup.ImgInputFile)
+}
 
-	m := calcTransMatrixForRect(r1, croppedRect, nup.ImgInputFile)
+func nUpTilePDFBytes(wr io.Writer, r1, r2 *Rectangle, formResID string, nup *NUp) {
+
+	m := nUpTileMatrix(wr, r1, r2, nup)
 
 	fmt.Fprintf(wr, "q %.2f %.2f %.2f %.2f %.2f %.2f cm /%s Do Q ",
 		m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1], formResID)
 }
 
-func nUpImagePDFBytes(wr io.Writer, imgWidth, imgHeight int, nup *NUp, formResID string) {
-	for _, r := range rectsForGrid(nup) {
+// nUpTileInlinePDFBytes places the page content bb with mediaBox r1 into tile r2 clipped to r1 like a form would.
+func nUpTileInlinePDFBytes(wr io.Writer, r1, r2 *Rectangle, bb []byte, nup *NUp) {
+
+	clip := *r1
+
+	m := nUpTileMatrix(wr, r1, r2, nup)
+
+	fmt.Fprintf(wr, "q %.2f %.2f %.2f %.2f %.2f %.2f cm %.2f %.2f %.2f %.2f re W n\n",
+		m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1],
+		clip.LL.X, clip.LL.Y, clip.Width(), clip.Height())
+
+	wr.Write(bb)
+
+	fmt.Fprint(wr, "\nQ ")
+}
+
+func nUpImagePDFBytes(wr io.Writer, imgWidth, imgHeight int, nup *NUp, formResID string) error {
+	rr, err := rectsForGrid(nup)
+	if err != nil {
+		return err
+	}
+	for _, r := range rr {
 		nUpTilePDFBytes(wr, RectForDim(float64(imgWidth), float64(imgHeight)), r, formResID, nup)
 	}
+	return nil
 }
 
 func createNUpForm(xRefTable *XRefTable, imgIndRef *IndirectRef, w, h, i int) (*IndirectRef, error) {
@@ -585,7 +706,9 @@ func NewNUpPageForImage(xRefTable *XRefTable, fileName string, parentIndRef *Ind
 	}
 
 	var buf bytes.Buffer
-	nUpImagePDFBytes(&buf, w, h, nup, formResID)
+	if err := nUpImagePDFBytes(&buf, w, h, nup, formResID); err != nil {
+		return nil, err
+	}
 	sd, _ := xRefTable.NewStreamDictForBuf(buf.Bytes())
 	if err = sd.Encode(); err != nil {
 		return nil, err
@@ -630,16 +753,10 @@ func nupFromOneImage(ctx *Context, fileName string, nup *NUp, pagesDict Dict, pa
 	return nil
 }
 
-func wrapUpPage(ctx *Context, nup *NUp, d Dict, buf bytes.Buffer, pagesDict Dict, pagesIndRef *IndirectRef) error {
+func wrapUpPage(ctx *Context, nup *NUp, resourceDict Dict, buf bytes.Buffer, annots Array, pagesDict Dict, pagesIndRef *IndirectRef) error {
 
 	xRefTable := ctx.XRefTable
 
-	resourceDict := Dict(
-		map[string]Object{
-			"XObject": d,
-		},
-	)
-
 	resIndRef, err := xRefTable.IndRefForNewObject(resourceDict)
 	if err != nil {
 		return err
@@ -674,6 +791,17 @@ func wrapUpPage(ctx *Context, nup *NUp, d Dict, buf bytes.Buffer, pagesDict Dict
 		return err
 	}
 
+	if len(annots) > 0 {
+		for _, o := range annots {
+			d, err := xRefTable.DereferenceDict(o)
+			if err != nil {
+				return err
+			}
+			d.Update("P", *indRef)
+		}
+		pageDict.Insert("Annots", annots)
+	}
+
 	if err = AppendPageTree(indRef, 1, pagesDict); err != nil {
 		return err
 	}
@@ -695,14 +823,17 @@ func nupFromMultipleImages(ctx *Context, fileNames []string, nup *NUp, pagesDict
 
 	var buf bytes.Buffer
 
-	rr := rectsForGrid(nup)
+	rr, err := rectsForGrid(nup)
+	if err != nil {
+		return err
+	}
 
 	for i, fileName := range fileNames {
 
 		if i > 0 && i%len(rr) == 0 {
 
 			// Wrap complete nUp page.
-			if err := wrapUpPage(ctx, nup, formsResDict, buf, pagesDict, pagesIndRef); err != nil {
+			if err := wrapUpPage(ctx, nup, Dict{"XObject": formsResDict}, buf, nil, pagesDict, pagesIndRef); err != nil {
 				return err
 			}
 
@@ -736,7 +867,7 @@ func nupFromMultipleImages(ctx *Context, fileNames []string, nup *NUp, pagesDict
 	}
 
 	// Wrap incomplete nUp page.
-	return wrapUpPage(ctx, nup, formsResDict, buf, pagesDict, pagesIndRef)
+	return wrapUpPage(ctx, nup, Dict{"XObject": formsResDict}, buf, nil, pagesDict, pagesIndRef)
 }
 
 // NUpFromImage creates a single page n-up PDF for one image
@@ -788,64 +919,265 @@ func sortedSelectedPages(selectedPages IntSet) []int {
 	return pageNumbers
 }
 
-func nupPages(ctx *Context, selectedPages IntSet, nup *NUp, pagesDict Dict, pagesIndRef *IndirectRef) error {
+// sameResource returns true if o1 and o2 refer to the same resource.
+func sameResource(o1, o2 Object) bool {
+	ir1, ok1 := o1.(IndirectRef)
+	ir2, ok2 := o2.(IndirectRef)
+	if ok1 || ok2 {
+		return ok1 && ok2 && ir1.ObjectNumber == ir2.ObjectNumber && ir1.GenerationNumber == ir2.GenerationNumber
+	}
+	return o1.PDFString() == o2.PDFString()
+}
 
-	var buf bytes.Buffer
+// mergeNUpResources merges the resources of a page into resDict unless any of its resource names
+// is already taken by another resource and returns true on success.
+func mergeNUpResources(xRefTable *XRefTable, resDict, pageResDict Dict) (bool, error) {
+
+	m := map[string]Dict{}
+
+	for cat, o := range pageResDict {
+		if cat == "ProcSet" {
+			continue
+		}
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return false, err
+		}
+		if d == nil {
+			continue
+		}
+		d1 := resDict.DictEntry(cat)
+		for id, o := range d {
+			if o1, found := d1.Find(id); found && !sameResource(o, o1) {
+				return false, nil
+			}
+		}
+		m[cat] = d
+	}
+
+	for cat, d := range m {
+		d1 := resDict.DictEntry(cat)
+		if d1 == nil {
+			d1 = NewDict()
+			resDict.Insert(cat, d1)
+		}
+		for id, o := range d {
+			d1[id] = o
+		}
+	}
+
+	return true, nil
+}
+
+// inlineNUpContent returns the content of the page d if it consists of a single stream
+// and its resources could be merged into resDict.
+func inlineNUpContent(xRefTable *XRefTable, d Dict, resDict, pageResDict Dict) ([]byte, error) {
+
+	o, found := d.Find("Contents")
+	if !found {
+		return nil, nil
+	}
+
+	o, err := xRefTable.Dereference(o)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := o.(StreamDict); !ok {
+		return nil, nil
+	}
+
+	ok, err := mergeNUpResources(xRefTable, resDict, pageResDict)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return xRefTable.PageContent(d)
+}
+
+// nUpAnnotations appends the annotations of the page d transformed by m to annots.
+func nUpAnnotations(xRefTable *XRefTable, d Dict, m matrix, annots Array, visited IntSet) (Array, error) {
+
+	a, err := xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || a == nil {
+		return annots, err
+	}
+
+	for _, o := range a {
+		ad, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if ad == nil {
+			continue
+		}
+		if _, ok := o.(IndirectRef); !ok {
+			ad = ad.Clone().(Dict)
+			o = ad
+		}
+		if err := xRefTable.transformAnnotation(ad, m, visited); err != nil {
+			return nil, err
+		}
+		annots = append(annots, o)
+	}
+
+	return annots, nil
+}
+
+// bookletPageNrs returns pageNrs in the order of placement on the front and back sides of booklet sheets.
+// pageNrs get padded with blank pages (0) to complete the last sheet.
+func bookletPageNrs(pageNrs []int) []int {
+	n := (len(pageNrs) + 3) / 4 * 4
+
+	pageNr := func(i int) int {
+		if i > len(pageNrs) {
+			return 0
+		}
+		return pageNrs[i-1]
+	}
+
+	pp := make([]int, 0, n)
+	for s := 0; s < n/4; s++ {
+		// front side: last and first page, back side: second and second to last page.
+		pp = append(pp, pageNr(n-2*s), pageNr(1+2*s), pageNr(2+2*s), pageNr(n-1-2*s))
+	}
+
+	return pp
+}
+
+// bookletCreep returns the horizontal shift towards the spine for tile r holding the i-th of n booklet pages.
+// Creep grows linearly from the outermost sheet to nup.Creep for the innermost sheet.
+func bookletCreep(nup *NUp, r *Rectangle, i, n int) float64 {
+	sheets := n / 4
+	if !nup.Booklet || nup.Creep == 0 || sheets < 2 {
+		return 0
+	}
+	dx := nup.Creep * float64(i/4) / float64(sheets-1)
+	if r.Center().X > nup.PageDim.Width/2 {
+		dx = -dx
+	}
+	return dx
+}
+
+// nupTile places page pageNr into tile r of the nUp page under construction.
+// formResID is unique for this page and names its form XObject.
+func nupTile(ctx *Context, pageNr int, r *Rectangle, formResID string, nup *NUp, buf *bytes.Buffer, resDict Dict, annots Array, visited IntSet) (Array, error) {
 
 	xRefTable := ctx.XRefTable
-	formsResDict := NewDict()
-	rr := rectsForGrid(nup)
+	formsResDict := resDict.DictEntry("XObject")
 
-	for i, p := range sortedSelectedPages(selectedPages) {
+	consolidateRes := true
+	d, inhPAttrs, err := ctx.PageDict(pageNr, consolidateRes)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	// Annotations follow the page content into its tile.
+	m := nUpTileTransform(inhPAttrs.mediaBox, r, nup)
+	if annots, err = nUpAnnotations(xRefTable, d, m, annots, visited); err != nil {
+		return nil, err
+	}
+
+	if nup.Inline {
+		bb, err := inlineNUpContent(xRefTable, d, resDict, inhPAttrs.resources)
+		if err != nil && err != errNoContent {
+			return nil, err
+		}
+		if bb != nil {
+			nUpTileInlinePDFBytes(buf, inhPAttrs.mediaBox, r, bb, nup)
+			return annots, nil
+		}
+	}
+
+	// Retrieve content stream bytes.
+	bb, err := xRefTable.PageContent(d)
+	if err == errNoContent {
+		return annots, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Create an object for this resDict in xRefTable.
+	ir, err := ctx.IndRefForNewObject(inhPAttrs.resources)
+	if err != nil {
+		return nil, err
+	}
+
+	formIndRef, err := createNUpFormForPDFResource(xRefTable, ir, bb, inhPAttrs.mediaBox)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inlined page content may already use this name.
+	id := formResID
+	for j := 0; formsResDict[id] != nil; j++ {
+		id = fmt.Sprintf("%s_%d", formResID, j)
+	}
+	formsResDict.Insert(id, *formIndRef)
+
+	nUpTilePDFBytes(buf, inhPAttrs.mediaBox, r, id, nup)
+
+	return annots, nil
+}
+
+// nupPages places pageNrs into consecutive tiles of new nUp pages, page number 0 leaves its tile blank.
+func nupPages(ctx *Context, pageNrs []int, nup *NUp, pagesDict Dict, pagesIndRef *IndirectRef) error {
+
+	var buf bytes.Buffer
+
+	resDict := Dict{"XObject": NewDict()}
+
+	rr, err := rectsForGrid(nup)
+	if err != nil {
+		return err
+	}
+
+	// Annotations of the current nUp page.
+	var annots Array
+	visited := IntSet{}
+
+	for i, p := range pageNrs {
 
 		if i > 0 && i%len(rr) == 0 {
 
 			// Wrap complete nUp page.
-			if err := wrapUpPage(ctx, nup, formsResDict, buf, pagesDict, pagesIndRef); err != nil {
+			if err := wrapUpPage(ctx, nup, resDict, buf, annots, pagesDict, pagesIndRef); err != nil {
 				return err
 			}
 
 			buf.Reset()
-			formsResDict = NewDict()
+			resDict = Dict{"XObject": NewDict()}
+			annots = nil
 		}
 
-		consolidateRes := true
-		d, inhPAttrs, err := ctx.PageDict(p, consolidateRes)
-		if err != nil {
-			return err
-		}
-		if d == nil {
-			return errors.Errorf("pdfcpu: unknown page number: %d\n", i)
-		}
-
-		// Retrieve content stream bytes.
-		bb, err := xRefTable.PageContent(d)
-		if err == errNoContent {
+		if p == 0 {
+			// Blank booklet page.
 			continue
 		}
-		if err != nil {
-			return err
-		}
 
-		// Create an object for this resDict in xRefTable.
-		ir, err := ctx.IndRefForNewObject(inhPAttrs.resources)
-		if err != nil {
-			return err
+		r := rr[i%len(rr)]
+
+		dx := bookletCreep(nup, r, i, len(pageNrs))
+		if dx != 0 {
+			// Keep shifted content within its cell.
+			fmt.Fprintf(&buf, "q %.2f %.2f %.2f %.2f re W n ", r.LL.X, r.LL.Y, r.Width(), r.Height())
+			r = Rect(r.LL.X+dx, r.LL.Y, r.UR.X+dx, r.UR.Y)
 		}
 
-		formIndRef, err := createNUpFormForPDFResource(xRefTable, ir, bb, inhPAttrs.mediaBox)
-		if err != nil {
+		if annots, err = nupTile(ctx, p, r, fmt.Sprintf("Fm%d", i), nup, &buf, resDict, annots, visited); err != nil {
 			return err
 		}
 
-		formResID := fmt.Sprintf("Fm%d", i)
-		formsResDict.Insert(formResID, *formIndRef)
-
-		nUpTilePDFBytes(&buf, inhPAttrs.mediaBox, rr[i%len(rr)], formResID, nup)
+		if dx != 0 {
+			buf.WriteString("Q ")
+		}
 	}
 
 	// Wrap incomplete nUp page.
-	return wrapUpPage(ctx, nup, formsResDict, buf, pagesDict, pagesIndRef)
+	return wrapUpPage(ctx, nup, resDict, buf, annots, pagesDict, pagesIndRef)
 }
 
 // NUpFromPDF creates an n-up version of the PDF represented by xRefTable.
@@ -864,6 +1196,10 @@ func NUpFromPDF(ctx *Context, selectedPages IntSet, nup *NUp) error {
 			return errors.Errorf("unknown page number: %d\n", 1)
 		}
 		mb = inhPAttrs.mediaBox
+		if nup.Booklet {
+			// Two pages side by side.
+			mb = Rect(mb.LL.X, mb.LL.Y, mb.UR.X+mb.Width(), mb.UR.Y)
+		}
 	} else {
 		mb = RectForDim(nup.PageDim.Width, nup.PageDim.Height)
 	}
@@ -888,7 +1224,12 @@ func NUpFromPDF(ctx *Context, selectedPages IntSet, nup *NUp) error {
 
 	nup.PageDim = &Dim{mb.Width(), mb.Height()}
 
-	if err = nupPages(ctx, selectedPages, nup, pagesDict, pagesIndRef); err != nil {
+	pageNrs := sortedSelectedPages(selectedPages)
+	if nup.Booklet {
+		pageNrs = bookletPageNrs(pageNrs)
+	}
+
+	if err = nupPages(ctx, pageNrs, nup, pagesDict, pagesIndRef); err != nil {
 		return err
 	}
 
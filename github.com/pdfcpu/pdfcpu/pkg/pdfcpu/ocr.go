@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pkg/errors"
+)
+
+const (
+	ocrFontID   = "F0"
+	ocrFontName = "Helvetica"
+)
+
+// OCRWord is a word recognized on a scanned image.
+type OCRWord struct {
+	Text string
+	Box  *Rectangle // Bounding box in image pixels with the origin in the upper left corner as reported by OCR engines.
+}
+
+// OCRLine is a line of words recognized on a scanned image.
+type OCRLine []OCRWord
+
+func ocrFontDict() Dict {
+	return Dict(
+		map[string]Object{
+			"Type":     Name("Font"),
+			"Subtype":  Name("Type1"),
+			"BaseFont": Name(ocrFontName),
+			"Encoding": Name("WinAnsiEncoding"),
+		},
+	)
+}
+
+// ocrTextPDFBytes writes the words of lines as invisible text fitted into their bounding boxes
+// of the image of w x h pixels mapped onto the page by m.
+// Each line is a marked-content sequence identified by its index for use by the structure tree.
+func ocrTextPDFBytes(wr io.Writer, m matrix, w, h float64, lines []OCRLine) {
+
+	// Map image pixels onto the page.
+	pm := matrix{{1 / w, 0, 0}, {0, -1 / h, 0}, {0, 1, 1}}.multiply(m)
+
+	fmt.Fprint(wr, "\n")
+
+	for mcid, l := range lines {
+
+		fmt.Fprintf(wr, "/P <</MCID %d>> BDC BT 3 Tr\n", mcid)
+
+		for _, wd := range l {
+
+			s := winAnsiString(wd.Text)
+			if s == "" || wd.Box == nil {
+				continue
+			}
+
+			ll := pm.transform(wd.Box.LL.X, wd.Box.UR.Y)
+			ur := pm.transform(wd.Box.UR.X, wd.Box.LL.Y)
+			bw, bh := ur.X-ll.X, ur.Y-ll.Y
+			if bw <= 0 || bh <= 0 {
+				continue
+			}
+
+			// Fit the text into the box by horizontal scaling.
+			tw := font.TextWidth(s, ocrFontName, 1000) / 1000 * bh
+			hs := 100.
+			if tw > 0 {
+				hs = bw / tw * 100
+			}
+
+			// Leave room for descenders below the baseline.
+			fmt.Fprintf(wr, "/%s %.2f Tf %.2f Tz 1 0 0 1 %.2f %.2f Tm (%s) Tj\n", ocrFontID, bh, hs, ll.X, ll.Y+.2*bh, escapedText(s))
+		}
+
+		fmt.Fprint(wr, "ET EMC\n")
+	}
+}
+
+// ocrStructTree returns the structure tree root along with the document element taking the paragraphs of OCR text.
+// The structure tree gets created if necessary.
+func (xRefTable *XRefTable) ocrStructTree() (Dict, Dict, *IndirectRef, error) {
+
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if o, found := rootDict.Find("StructTreeRoot"); found {
+
+		root, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// Only structure trees as created by a previous import are supported.
+		ir, ok := root["K"].(IndirectRef)
+		if !ok {
+			return nil, nil, nil, errors.New("pdfcpu: unsupported structure tree")
+		}
+		doc, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pt, err := xRefTable.DereferenceDict(root["ParentTree"])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if doc == nil || pt == nil || pt.ArrayEntry("Nums") == nil || root.IntEntry("ParentTreeNextKey") == nil {
+			return nil, nil, nil, errors.New("pdfcpu: unsupported structure tree")
+		}
+		if s := doc.NameEntry("S"); s == nil || *s != "Document" {
+			return nil, nil, nil, errors.New("pdfcpu: unsupported structure tree")
+		}
+
+		return root, doc, &ir, nil
+	}
+
+	root := Dict(
+		map[string]Object{
+			"Type":              Name("StructTreeRoot"),
+			"ParentTree":        Dict(map[string]Object{"Nums": Array{}}),
+			"ParentTreeNextKey": Integer(0),
+		},
+	)
+
+	rootIndRef, err := xRefTable.IndRefForNewObject(root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	doc := Dict(
+		map[string]Object{
+			"Type": Name("StructElem"),
+			"S":    Name("Document"),
+			"P":    *rootIndRef,
+			"K":    Array{},
+		},
+	)
+
+	docIndRef, err := xRefTable.IndRefForNewObject(doc)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	root["K"] = *docIndRef
+
+	rootDict["StructTreeRoot"] = *rootIndRef
+	rootDict["MarkInfo"] = Dict(map[string]Object{"Marked": Boolean(true)})
+
+	return root, doc, docIndRef, nil
+}
+
+// NewPageForImageWithText creates a new page dict in xRefTable for given image reader r
+// overlaying the image with the invisible OCR text of lines which makes the text searchable and selectable.
+// Each line gets tagged as paragraph of the document's structure tree which will be created if necessary.
+func NewPageForImageWithText(xRefTable *XRefTable, r io.Reader, parentIndRef *IndirectRef, imp *Import, lines []OCRLine) (*IndirectRef, error) {
+
+	root, doc, docIndRef, err := xRefTable.ocrStructTree()
+	if err != nil {
+		return nil, err
+	}
+
+	key := *root.IntEntry("ParentTreeNextKey")
+
+	if lines == nil {
+		lines = []OCRLine{}
+	}
+
+	pageIndRef, err := newPageForImage(xRefTable, r, parentIndRef, imp, lines, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parent tree maps the marked-content identifiers of the page to their structure elements.
+	kids := doc.ArrayEntry("K")
+	pp := Array{}
+
+	for mcid := range lines {
+		d := Dict(
+			map[string]Object{
+				"Type": Name("StructElem"),
+				"S":    Name("P"),
+				"P":    *docIndRef,
+				"Pg":   *pageIndRef,
+				"K":    Integer(mcid),
+			},
+		)
+		ir, err := xRefTable.IndRefForNewObject(d)
+		if err != nil {
+			return nil, err
+		}
+		kids = append(kids, *ir)
+		pp = append(pp, *ir)
+	}
+
+	doc["K"] = kids
+
+	pt, err := xRefTable.DereferenceDict(root["ParentTree"])
+	if err != nil {
+		return nil, err
+	}
+	pt["Nums"] = append(pt.ArrayEntry("Nums"), Integer(key), pp)
+
+	root["ParentTreeNextKey"] = Integer(key + 1)
+
+	return pageIndRef, nil
+}
@@ -454,9 +454,18 @@ func optimizeXObjectResourcesDict(ctx *Context, rDict Dict, pageNumber, pageObjN
 			continue
 		}
 
+		// Skip forms using themselves as resource.
+		if ok, err = ctx.EnterObject(indRef, "optimizeXObjectResourcesDict"); err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
 		// Process form dict
 		log.Optimize.Printf("optimizeXObjectResourcesDict: parsing form dict obj:%d\n", objNr)
 		parseResourcesDict(ctx, osd.Dict, pageNumber, objNr)
+		ctx.LeaveObject(indRef)
 	}
 
 	log.Optimize.Println("optimizeXObjectResourcesDict end")
@@ -1189,6 +1198,20 @@ func OptimizeXRefTable(ctx *Context) error {
 		return err
 	}
 
+	// Get rid of redundant data of embedded subset fonts.
+	if ctx.TrimFonts {
+		if err := trimFonts(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Merge identical objects.
+	if ctx.DeduplicateObjects {
+		if err := deduplicateObjects(ctx); err != nil {
+			return err
+		}
+	}
+
 	// Get rid of PieceInfo dict from root.
 	if err := ctx.deleteDictEntry(ctx.RootDict, "PieceInfo"); err != nil {
 		return err
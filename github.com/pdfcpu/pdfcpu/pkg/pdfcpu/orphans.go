@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OrphanObject represents an object of the cross reference table not reachable from the trailer.
+type OrphanObject struct {
+	ObjNr int
+	GenNr int
+	Type  string // Go type optionally followed by /Type and /Subtype.
+	Size  int64  // Approximate size in bytes of the serialized object.
+}
+
+func (oo OrphanObject) String() string {
+	return fmt.Sprintf("obj#%d gen#%d %s %d bytes", oo.ObjNr, oo.GenNr, oo.Type, oo.Size)
+}
+
+// isStructuralObject returns true for objects implementing the file structure rather than document content.
+func isStructuralObject(o Object) bool {
+
+	switch o := o.(type) {
+
+	case ObjectStreamDict, XRefStreamDict:
+		return true
+
+	case StreamDict:
+		t := o.Type()
+		return t != nil && (*t == "ObjStm" || *t == "XRef")
+	}
+
+	return false
+}
+
+// reachableObjects returns the set of object numbers reachable from the trailer entries Root, Info and Encrypt.
+func (xRefTable *XRefTable) reachableObjects() map[int]bool {
+
+	m := map[int]bool{}
+	queue := []Object{}
+
+	for _, ir := range []*IndirectRef{xRefTable.Root, xRefTable.Info, xRefTable.Encrypt} {
+		if ir != nil {
+			queue = append(queue, *ir)
+		}
+	}
+
+	for len(queue) > 0 {
+
+		o := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		switch o := o.(type) {
+
+		case IndirectRef:
+			objNr := o.ObjectNumber.Value()
+			if m[objNr] {
+				continue
+			}
+			m[objNr] = true
+			entry, found := xRefTable.FindTableEntryLight(objNr)
+			if found && !entry.Free && entry.Object != nil {
+				queue = append(queue, entry.Object)
+			}
+
+		case Dict:
+			for _, v := range o {
+				queue = append(queue, v)
+			}
+
+		case StreamDict:
+			for _, v := range o.Dict {
+				queue = append(queue, v)
+			}
+
+		case Array:
+			queue = append(queue, o...)
+
+		}
+	}
+
+	return m
+}
+
+func orphanSize(o Object) int64 {
+	if o == nil {
+		return 0
+	}
+	if sd, ok := o.(StreamDict); ok {
+		size := int64(len(sd.Dict.PDFString()))
+		if sd.StreamLength != nil {
+			size += *sd.StreamLength
+		} else {
+			size += int64(len(sd.Raw))
+		}
+		return size
+	}
+	return int64(len(o.PDFString()))
+}
+
+func orphanType(o Object) string {
+
+	if o == nil {
+		return "null"
+	}
+
+	var d Dict
+	s := strings.TrimPrefix(fmt.Sprintf("%T", o), "pdfcpu.")
+
+	switch o := o.(type) {
+	case Dict:
+		d = o
+	case StreamDict:
+		d = o.Dict
+	}
+
+	if d != nil {
+		if t := d.Type(); t != nil {
+			s += " /" + *t
+		}
+		if st := d.Subtype(); st != nil {
+			s += " /" + *st
+		}
+	}
+
+	return s
+}
+
+// OrphanObjects returns all objects of the cross reference table that are not reachable from the trailer sorted by object number.
+func (ctx *Context) OrphanObjects() []OrphanObject {
+
+	reachable := ctx.reachableObjects()
+
+	oo := []OrphanObject{}
+
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry.Free || reachable[objNr] || isStructuralObject(entry.Object) {
+			continue
+		}
+		genNr := 0
+		if entry.Generation != nil {
+			genNr = *entry.Generation
+		}
+		oo = append(oo, OrphanObject{
+			ObjNr: objNr,
+			GenNr: genNr,
+			Type:  orphanType(entry.Object),
+			Size:  orphanSize(entry.Object),
+		})
+	}
+
+	sort.Slice(oo, func(i, j int) bool { return oo[i].ObjNr < oo[j].ObjNr })
+
+	return oo
+}
+
+// GarbageCollect frees all objects not reachable from the trailer and returns the orphans removed.
+// Unreachable object streams and xref streams get freed too since the writer generates new ones.
+func (ctx *Context) GarbageCollect() ([]OrphanObject, error) {
+
+	oo := ctx.OrphanObjects()
+
+	for _, o := range oo {
+		if err := ctx.DeleteObject(o.ObjNr); err != nil {
+			return nil, err
+		}
+	}
+
+	reachable := ctx.reachableObjects()
+
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry.Free || reachable[objNr] || !isStructuralObject(entry.Object) {
+			continue
+		}
+		if err := ctx.DeleteObject(objNr); err != nil {
+			return nil, err
+		}
+	}
+
+	return oo, nil
+}
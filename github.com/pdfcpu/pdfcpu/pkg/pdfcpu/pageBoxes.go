@@ -0,0 +1,315 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// PageBox specifies a page boundary either by absolute coordinates in default user space
+// or by insets relative to the media box of a page.
+type PageBox struct {
+	Rect  *Rectangle // Absolute boundary, takes precedence over Inset.
+	Inset [4]float64 // Top, right, bottom and left inset relative to the media box.
+}
+
+// PageBoxes represents the page boundaries of a page, see 14.11.2.
+// Unspecified boxes are nil.
+type PageBoxes struct {
+	MediaBox *PageBox
+	CropBox  *PageBox
+	BleedBox *PageBox
+	TrimBox  *PageBox
+	ArtBox   *PageBox
+}
+
+func (pb PageBox) String() string {
+	if pb.Rect != nil {
+		return fmt.Sprintf("[%.2f %.2f %.2f %.2f]", pb.Rect.LL.X, pb.Rect.LL.Y, pb.Rect.UR.X, pb.Rect.UR.Y)
+	}
+	return fmt.Sprintf("%.2f %.2f %.2f %.2f", pb.Inset[0], pb.Inset[1], pb.Inset[2], pb.Inset[3])
+}
+
+// rect returns the boundary pb within the media box mb.
+func (pb PageBox) rect(mb *Rectangle) *Rectangle {
+	if pb.Rect != nil {
+		return Rect(pb.Rect.LL.X, pb.Rect.LL.Y, pb.Rect.UR.X, pb.Rect.UR.Y)
+	}
+	t, r, b, l := pb.Inset[0], pb.Inset[1], pb.Inset[2], pb.Inset[3]
+	return Rect(mb.LL.X+l, mb.LL.Y+b, mb.UR.X-r, mb.UR.Y-t)
+}
+
+// ParsePageBox parses a page boundary.
+// Absolute boundaries are written as "[llx lly urx ury]".
+// Relative boundaries are written as insets relative to the media box, either
+// "all", "vertical horizontal" or "top right bottom left".
+func ParsePageBox(s string) (*PageBox, error) {
+
+	s = strings.TrimSpace(s)
+
+	abs := strings.HasPrefix(s, "[")
+	if abs {
+		if !strings.HasSuffix(s, "]") {
+			return nil, errors.Errorf("pdfcpu: invalid page box: %s", s)
+		}
+		s = s[1 : len(s)-1]
+	}
+
+	ss := strings.Fields(s)
+	ff := make([]float64, len(ss))
+	for i, s1 := range ss {
+		f, err := strconv.ParseFloat(s1, 64)
+		if err != nil {
+			return nil, errors.Errorf("pdfcpu: invalid page box: %s", s)
+		}
+		ff[i] = f
+	}
+
+	if abs {
+		if len(ff) != 4 {
+			return nil, errors.Errorf("pdfcpu: invalid page box: need 4 coordinates: %s", s)
+		}
+		r := Rect(ff[0], ff[1], ff[2], ff[3])
+		if r.Width() <= 0 || r.Height() <= 0 {
+			return nil, errors.Errorf("pdfcpu: invalid page box: empty rectangle: %s", s)
+		}
+		return &PageBox{Rect: r}, nil
+	}
+
+	pb := &PageBox{}
+
+	switch len(ff) {
+	case 1:
+		pb.Inset = [4]float64{ff[0], ff[0], ff[0], ff[0]}
+	case 2:
+		pb.Inset = [4]float64{ff[0], ff[1], ff[0], ff[1]}
+	case 4:
+		pb.Inset = [4]float64{ff[0], ff[1], ff[2], ff[3]}
+	default:
+		return nil, errors.Errorf("pdfcpu: invalid page box: need 1, 2 or 4 insets: %s", s)
+	}
+
+	return pb, nil
+}
+
+// ParsePageBoxes parses a comma separated list of page boundaries like "trim:10, bleed:[0 0 620 860]".
+// Valid keys are media, crop, bleed, trim and art.
+func ParsePageBoxes(s string) (*PageBoxes, error) {
+
+	pbs := &PageBoxes{}
+
+	for _, s1 := range strings.Split(s, ",") {
+
+		ss := strings.SplitN(s1, ":", 2)
+		if len(ss) != 2 {
+			return nil, errors.Errorf("pdfcpu: invalid page boxes: %s", s1)
+		}
+
+		pb, err := ParsePageBox(ss[1])
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.TrimSpace(ss[0]) {
+		case "media":
+			pbs.MediaBox = pb
+		case "crop":
+			pbs.CropBox = pb
+		case "bleed":
+			pbs.BleedBox = pb
+		case "trim":
+			pbs.TrimBox = pb
+		case "art":
+			pbs.ArtBox = pb
+		default:
+			return nil, errors.Errorf("pdfcpu: unknown page box: %s", ss[0])
+		}
+	}
+
+	return pbs, nil
+}
+
+func (xRefTable *XRefTable) pageBox(d Dict, key string) (*Rectangle, error) {
+	a, err := xRefTable.DereferenceArray(d[key])
+	if err != nil || len(a) != 4 {
+		return nil, err
+	}
+	return rect(xRefTable, a)
+}
+
+// explicitPageBoxes returns the media, crop, bleed, trim and art box of the page dict d.
+// Missing boxes other than the media box are nil.
+func (xRefTable *XRefTable) explicitPageBoxes(d Dict, inhPAttrs *InheritedPageAttrs) ([]*Rectangle, error) {
+
+	if inhPAttrs.mediaBox == nil {
+		return nil, errors.New("pdfcpu: missing MediaBox")
+	}
+
+	rr := []*Rectangle{inhPAttrs.mediaBox, inhPAttrs.cropBox}
+
+	for _, k := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+		r, err := xRefTable.pageBox(d, k)
+		if err != nil {
+			return nil, err
+		}
+		rr = append(rr, r)
+	}
+
+	return rr, nil
+}
+
+// effectivePageBoxes applies the default values of 14.11.2 to the explicit page boxes rr.
+// The crop box defaults to the media box, bleed box, trim box and art box default to the crop box.
+func effectivePageBoxes(rr []*Rectangle) []*Rectangle {
+	rr1 := append([]*Rectangle{}, rr...)
+	if rr1[1] == nil {
+		rr1[1] = rr1[0]
+	}
+	for i := 2; i < len(rr1); i++ {
+		if rr1[i] == nil {
+			rr1[i] = rr1[1]
+		}
+	}
+	return rr1
+}
+
+func within(r1, r2 *Rectangle) bool {
+	const tol = 0.01
+	return r1.LL.X >= r2.LL.X-tol && r1.LL.Y >= r2.LL.Y-tol && r1.UR.X <= r2.UR.X+tol && r1.UR.Y <= r2.UR.Y+tol
+}
+
+var pageBoxNames = []string{"MediaBox", "CropBox", "BleedBox", "TrimBox", "ArtBox"}
+
+// pageBoxViolations returns the nesting violations of the explicit media, crop, bleed, trim and art box rr.
+// Crop box and bleed box have to lie within the media box, trim box and art box within the bleed box.
+// Boxes taking their default values are not checked.
+func pageBoxViolations(rr []*Rectangle) []string {
+
+	ss := []string{}
+
+	for i, r := range rr {
+		if r != nil && (r.Width() <= 0 || r.Height() <= 0) {
+			ss = append(ss, fmt.Sprintf("empty %s", pageBoxNames[i]))
+		}
+	}
+
+	er := effectivePageBoxes(rr)
+
+	for _, c := range [][2]int{{1, 0}, {2, 0}, {3, 2}, {4, 2}} {
+		if rr[c[0]] != nil && !within(er[c[0]], er[c[1]]) {
+			ss = append(ss, fmt.Sprintf("%s %s exceeds %s %s", pageBoxNames[c[0]], er[c[0]].Array(), pageBoxNames[c[1]], er[c[1]].Array()))
+		}
+	}
+
+	return ss
+}
+
+// PageBoundaries returns the media, crop, bleed, trim and art box of page pageNr
+// applying the default values for missing boxes.
+func (xRefTable *XRefTable) PageBoundaries(pageNr int) (*PageBoxes, error) {
+
+	d, inhPAttrs, err := xRefTable.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	rr, err := xRefTable.explicitPageBoxes(d, inhPAttrs)
+	if err != nil {
+		return nil, err
+	}
+	rr = effectivePageBoxes(rr)
+
+	return &PageBoxes{
+		MediaBox: &PageBox{Rect: rr[0]},
+		CropBox:  &PageBox{Rect: rr[1]},
+		BleedBox: &PageBox{Rect: rr[2]},
+		TrimBox:  &PageBox{Rect: rr[3]},
+		ArtBox:   &PageBox{Rect: rr[4]},
+	}, nil
+}
+
+// SetPageBoxes sets the page boundaries specified by pbs for selected pages.
+// Relative boundaries are applied to the resulting media box of each page.
+// All pages get checked for violations of the nesting constraints of their boxes before anything gets modified.
+func SetPageBoxes(ctx *Context, selectedPages IntSet, pbs PageBoxes) error {
+
+	type pageUpdate struct {
+		d  Dict
+		rr []*Rectangle
+	}
+
+	updates := []pageUpdate{}
+	violations := []string{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+
+		if selectedPages != nil && !selectedPages[i] {
+			continue
+		}
+
+		log.Debug.Printf("SetPageBoxes page:%d\n", i)
+
+		d, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			return err
+		}
+
+		rr, err := ctx.explicitPageBoxes(d, inhPAttrs)
+		if err != nil {
+			return errors.Wrapf(err, "pdfcpu: SetPageBoxes: page %d", i)
+		}
+
+		mb := rr[0]
+		if pbs.MediaBox != nil {
+			mb = pbs.MediaBox.rect(mb)
+		}
+
+		for j, pb := range []*PageBox{pbs.MediaBox, pbs.CropBox, pbs.BleedBox, pbs.TrimBox, pbs.ArtBox} {
+			if pb != nil {
+				rr[j] = pb.rect(mb)
+			}
+		}
+
+		for _, s := range pageBoxViolations(rr) {
+			violations = append(violations, fmt.Sprintf("page %d: %s", i, s))
+		}
+
+		updates = append(updates, pageUpdate{d, rr})
+	}
+
+	if len(violations) > 0 {
+		return errors.Errorf("pdfcpu: SetPageBoxes: invalid page boxes:\n%s", strings.Join(violations, "\n"))
+	}
+
+	for _, u := range updates {
+		for j, pb := range []*PageBox{pbs.MediaBox, pbs.CropBox, pbs.BleedBox, pbs.TrimBox, pbs.ArtBox} {
+			if pb != nil {
+				u.d.Update(pageBoxNames[j], u.rr[j].Array())
+			}
+		}
+	}
+
+	return nil
+}
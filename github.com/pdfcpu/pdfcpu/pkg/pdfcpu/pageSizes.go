@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// preferredPaperSizes take precedence over aliases sharing their dimensions when naming page sizes.
+var preferredPaperSizes = []string{
+	"A0", "A1", "A2", "A3", "A4", "A5", "A6", "A7", "A8", "A9", "A10",
+	"B0", "B1", "B2", "B3", "B4", "B5", "B6", "B7", "B8", "B9", "B10",
+	"Letter", "Legal", "Tabloid", "Ledger", "HalfLetter",
+}
+
+// PageSizeGroup represents the pages of a document sharing their visible page size and orientation.
+type PageSizeGroup struct {
+	Dim     Dim   // Visible dimensions of the first page of this group.
+	Count   int   // Number of pages.
+	PageNrs []int // Sorted page numbers.
+}
+
+func orientationString(d Dim) string {
+	if d.Landscape() {
+		return "landscape"
+	}
+	if d.Portrait() {
+		return "portrait"
+	}
+	return "square"
+}
+
+// paperSizeName returns the name of the known paper size matching d within tolerance tol regardless of orientation.
+func paperSizeName(d Dim, tol float64) (string, bool) {
+
+	w, h := math.Min(d.Width, d.Height), math.Max(d.Width, d.Height)
+
+	names := make([]string, 0, len(PaperSize))
+	for k := range PaperSize {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	names = append(append([]string{}, preferredPaperSizes...), names...)
+
+	var (
+		name string
+		dev  float64
+	)
+
+	for _, k := range names {
+		ps, ok := PaperSize[k]
+		if !ok {
+			continue
+		}
+		pw, ph := math.Min(ps.Width, ps.Height), math.Max(ps.Width, ps.Height)
+		dw, dh := math.Abs(w-pw), math.Abs(h-ph)
+		if dw > tol || dh > tol {
+			continue
+		}
+		if name == "" || dw+dh < dev {
+			name, dev = k, dw+dh
+		}
+	}
+
+	return name, name != ""
+}
+
+func pageSizeName(d Dim, tol float64) string {
+	if n, ok := paperSizeName(d, tol); ok {
+		return n + " " + orientationString(d)
+	}
+	f := func(x float64) string { return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64) }
+	return fmt.Sprintf("%sx%s %s", f(d.Width), f(d.Height), orientationString(d))
+}
+
+// PageSizeHistogram groups the pages of xRefTable by their visible page size and orientation
+// taking into account crop box and page rotation.
+// Pages with dimensions deviating by no more than tol from a known paper size or the first page of a group are grouped together.
+// Groups are keyed by paper size name or dimensions in points followed by the orientation, eg. "A4 portrait" or "500x300 landscape".
+func (xRefTable *XRefTable) PageSizeHistogram(tol float64) (map[string]PageSizeGroup, error) {
+
+	dims, err := xRefTable.PageDims()
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]PageSizeGroup{}
+
+	// Pages not matching a known paper size.
+	var reps []string
+
+	for i, d := range dims {
+
+		k := pageSizeName(d, tol)
+
+		if _, ok := paperSizeName(d, tol); !ok {
+			for _, r := range reps {
+				dr := m[r].Dim
+				if math.Abs(d.Width-dr.Width) <= tol && math.Abs(d.Height-dr.Height) <= tol {
+					k = r
+					break
+				}
+			}
+			if _, ok := m[k]; !ok {
+				reps = append(reps, k)
+			}
+		}
+
+		g, ok := m[k]
+		if !ok {
+			g.Dim = d
+		}
+		g.Count++
+		g.PageNrs = append(g.PageNrs, i+1)
+		m[k] = g
+	}
+
+	return m, nil
+}
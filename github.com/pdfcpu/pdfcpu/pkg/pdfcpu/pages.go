@@ -17,6 +17,8 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"sort"
+
 	"github.com/pdfcpu/pdfcpu/pkg/filter"
 	"github.com/pkg/errors"
 )
@@ -67,6 +69,10 @@ func (xRefTable *XRefTable) PageContent(d Dict) ([]byte, error) {
 			if err != nil {
 				return nil, err
 			}
+			if len(bb) > 0 {
+				// Content streams break at token boundaries only, see 7.8.2
+				bb = append(bb, '\n')
+			}
 			bb = append(bb, o.Content...)
 		}
 
@@ -163,3 +169,128 @@ func AddPages(ctx, ctxDest *Context, pages []int, usePgCache bool) error {
 
 	return nil
 }
+
+// replacedPageAttrs are the page dict entries taken on from the source page by ReplacePage.
+var replacedPageAttrs = []string{"Contents", "Resources", "MediaBox", "CropBox", "BleedBox", "TrimBox", "ArtBox", "Rotate", "Group", "UserUnit", "Thumb"}
+
+// ReplacePage replaces the content and resources of page pageNr with those of page srcPageNr of src.
+// The page takes on the page boundaries and rotation of the source page and keeps its position in the page tree.
+// Annotations of the replaced page get removed unless PreserveAnnotations is set.
+// The replaced content and resources are left to the writer, which drops whatever is no longer reachable.
+func (ctx *Context) ReplacePage(pageNr int, src *Context, srcPageNr int) error {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	if err := src.EnsurePageCount(); err != nil {
+		return err
+	}
+	if srcPageNr < 1 || srcPageNr > src.PageCount {
+		return errors.Errorf("pdfcpu: invalid source page number: %d", srcPageNr)
+	}
+
+	d, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+
+	consolidateRes := true
+	srcDict, inhPAttrs, err := src.PageDict(srcPageNr, consolidateRes)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range replacedPageAttrs {
+		d.Delete(k)
+	}
+
+	// Migrate a copy so src stays intact.
+	srcDict = srcDict.Clone().(Dict)
+	srcDict["Resources"] = inhPAttrs.resources.Clone()
+
+	migrated := map[int]int{}
+
+	for _, k := range replacedPageAttrs {
+		v, found := srcDict[k]
+		if !found || k == "Thumb" {
+			continue
+		}
+		if v, err = migrateObject(v, src, ctx, migrated); err != nil {
+			return err
+		}
+		d[k] = v
+	}
+
+	// Handle inherited page attributes.
+	d["MediaBox"] = inhPAttrs.mediaBox.Array()
+	if inhPAttrs.cropBox != nil {
+		d["CropBox"] = inhPAttrs.cropBox.Array()
+	}
+	if inhPAttrs.rotate%360 > 0 {
+		d["Rotate"] = Integer(inhPAttrs.rotate)
+	}
+
+	if !ctx.PreserveAnnotations {
+		if _, err := ctx.RemoveAnnotations(IntSet{pageNr: true}, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newPage creates the page dict for p as kid of the page tree node parentIndRef.
+// Any font used by p is expected to be a core font.
+func (xRefTable *XRefTable) newPage(parentIndRef IndirectRef, p Page) (*IndirectRef, error) {
+
+	pageDict := Dict(
+		map[string]Object{
+			"Type":   Name("Page"),
+			"Parent": parentIndRef,
+		},
+	)
+
+	if p.MediaBox != nil {
+		pageDict.Insert("MediaBox", p.MediaBox.Array())
+	}
+
+	if len(p.Fm) > 0 {
+		keys := make([]string, 0, len(p.Fm))
+		for k := range p.Fm {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fontRes := NewDict()
+		for _, k := range keys {
+			ir, err := xRefTable.IndRefForNewObject(coreFontDict(p.Fm[k]))
+			if err != nil {
+				return nil, err
+			}
+			fontRes.Insert(k, *ir)
+		}
+		pageDict.Insert("Resources", Dict(map[string]Object{"Font": fontRes}))
+	}
+
+	var b []byte
+	if p.Buf != nil {
+		b = p.Buf.Bytes()
+	}
+
+	sd, _ := xRefTable.NewStreamDictForBuf(b)
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	ir, err := xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+	pageDict.Insert("Contents", *ir)
+
+	return xRefTable.IndRefForNewObject(pageDict)
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
 	"github.com/pdfcpu/pdfcpu/pkg/log"
 	"github.com/pkg/errors"
 )
@@ -27,7 +29,7 @@ import (
 var (
 	errPageContentCorrupt  = errors.New("pdfcpu: corrupt page content")
 	errTJExpressionCorrupt = errors.New("pdfcpu: corrupt TJ expression")
-	errBIExpressionCorrupt = errors.New("pdfcpu: corrupt TJ expression")
+	errBIExpressionCorrupt = errors.New("pdfcpu: corrupt BI expression")
 )
 
 func whitespaceOrEOL(c rune) bool {
@@ -111,42 +113,168 @@ func skipTJ(l *string) error {
 	return nil
 }
 
+// inlineImageName returns the name at the start of s without the leading solidus and the remainder of s.
+func inlineImageName(s string) (string, string) {
+	i, _ := positionToNextWhitespaceOrChar(s, "/[]<>(")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// skipInlineImageValue skips the value of an inline image dict entry
+// and returns any names or tokens making up this value.
+func skipInlineImageValue(l *string) ([]string, error) {
+	s := strings.TrimLeftFunc(*l, whitespaceOrEOL)
+	if len(s) == 0 {
+		return nil, errBIExpressionCorrupt
+	}
+
+	var vv []string
+
+	switch {
+
+	case s[0] == '/':
+		var n string
+		n, s = inlineImageName(s[1:])
+		vv = append(vv, n)
+
+	case s[0] == '[':
+		i := strings.IndexByte(s, ']')
+		if i < 0 {
+			return nil, errBIExpressionCorrupt
+		}
+		vv = strings.FieldsFunc(s[1:i], func(c rune) bool { return c == '/' || whitespaceOrEOL(c) })
+		s = s[i+1:]
+
+	case strings.HasPrefix(s, "<<"):
+		if err := skipDict(&s); err != nil {
+			return nil, err
+		}
+
+	default:
+		i, _ := positionToNextWhitespaceOrChar(s, "/[")
+		if i < 0 {
+			i = len(s)
+		}
+		vv = append(vv, s[:i])
+		s = s[i:]
+	}
+
+	*l = s
+	return vv, nil
+}
+
+// pdfWhitespace returns true for the white-space characters of 7.2.2 Table 1.
+func pdfWhitespace(b byte) bool {
+	return b == 0x00 || b == 0x09 || b == 0x0A || b == 0x0C || b == 0x0D || b == 0x20
+}
+
+// endOfInlineImage returns true if s starts with EI followed by white space or the end of s.
+func endOfInlineImage(s string) bool {
+	return strings.HasPrefix(s, "EI") && (len(s) == 2 || pdfWhitespace(s[2]))
+}
+
+// skipInlineImageData skips the image data following ID up to and including EI.
+// A declared length is honoured if EI follows, ASCII encoded data ends with its EOD marker,
+// anything else ends at the first EI delimited by white space.
+func skipInlineImageData(l *string, filters []string, length int) error {
+	s := *l
+
+	if length >= 0 && length <= len(s) {
+		t := strings.TrimLeftFunc(s[length:], whitespaceOrEOL)
+		if endOfInlineImage(t) {
+			*l = t[2:]
+			return nil
+		}
+	}
+
+	if len(filters) > 0 {
+		eod := ""
+		switch filters[0] {
+		case "AHx", filter.ASCIIHex:
+			eod = ">"
+		case "A85", filter.ASCII85:
+			eod = "~>"
+		}
+		if eod != "" {
+			if i := strings.Index(s, eod); i >= 0 {
+				s = s[i+len(eod):]
+			}
+		}
+	}
+
+	for i := 0; ; {
+		j := strings.Index(s[i:], "EI")
+		if j < 0 {
+			return errBIExpressionCorrupt
+		}
+		j += i
+		if (j == 0 || pdfWhitespace(s[j-1])) && endOfInlineImage(s[j:]) {
+			*l = s[j+2:]
+			return nil
+		}
+		i = j + 1
+	}
+}
+
+// skipBI skips an inline image object up to and including EI, see 8.9.7
+// Any named color space gets recorded in prn.
 func skipBI(l *string, prn PageResourceNames) error {
 	s := *l
-	cs := false
+	var filters []string
+	length := -1
+
 	for {
 		s = strings.TrimLeftFunc(s, whitespaceOrEOL)
-		if strings.HasPrefix(s, "EI") && whitespaceOrEOL(rune(s[2])) {
+		if len(s) < 2 {
+			return errBIExpressionCorrupt
+		}
+
+		if strings.HasPrefix(s, "ID") && (len(s) == 2 || pdfWhitespace(s[2])) {
+			// The image data starts after a single white space character.
 			s = s[2:]
+			if len(s) > 0 {
+				s = s[1:]
+			}
+			if err := skipInlineImageData(&s, filters, length); err != nil {
+				return err
+			}
 			break
 		}
-		if s[0] == '/' {
-			s = s[1:]
-			i, _ := positionToNextWhitespaceOrChar(s, "/")
-			if i < 0 {
-				return errBIExpressionCorrupt
+
+		if s[0] != '/' {
+			return errBIExpressionCorrupt
+		}
+
+		var key string
+		key, s = inlineImageName(s[1:])
+
+		vv, err := skipInlineImageValue(&s)
+		if err != nil {
+			return err
+		}
+
+		switch key {
+
+		case "CS", "ColorSpace":
+			if len(vv) == 1 && !MemberOf(vv[0], []string{"G", "RGB", "Gray", "CMYK", "DeviceRGB", "DeviceGray", "DeviceCMYK"}) {
+				prn["ColorSpace"][vv[0]] = true
 			}
-			n := s[:i]
-			s = s[i:]
-			if cs {
-				if !MemberOf(n, []string{"RGB", "Gray", "CMYK", "DeviceRGB", "DeviceGray", "DeviceCMYK"}) {
-					prn["ColorSpace"][n] = true
+
+		case "F", "Filter":
+			filters = vv
+
+		case "L", "Length":
+			if len(vv) == 1 {
+				if i, err := strconv.Atoi(vv[0]); err == nil {
+					length = i
 				}
-				cs = false
-				continue
-			}
-			if n == "CS" {
-				cs = true
 			}
-			continue
-		}
-		i, _ := positionToNextWhitespaceOrChar(s, "/")
-		if i < 0 {
-			return errBIExpressionCorrupt
+
 		}
-		cs = false
-		s = s[i:]
 	}
+
 	*l = s
 	return nil
 }
@@ -181,7 +309,7 @@ func nextContentToken(line *string, prn PageResourceNames) (string, error) {
 			}
 			continue
 		}
-		if strings.HasPrefix(l, "BI") && (l[2] == '/' || whitespaceOrEOL(rune(l[2]))) {
+		if strings.HasPrefix(l, "BI") && len(l) > 2 && (l[2] == '/' || whitespaceOrEOL(rune(l[2]))) {
 			// Handle inline image
 			l = l[2:]
 			if err := skipBI(&l, prn); err != nil {
@@ -50,3 +50,32 @@ func TestParseContent(t *testing.T) {
 		t.Fatalf("want:\n%s\ngot:\n%s\n", want, got)
 	}
 }
+
+func TestParseContentInlineImage(t *testing.T) {
+	for _, tt := range []struct {
+		msg string
+		s   string
+	}{
+		{"length",
+			"q BI /W 2 /H 1 /BPC 8 /CS /RGB /L 6 ID \x00/X Do\x00 EI Q BT /F1 12 Tf (Hello) Tj ET"},
+		{"binary",
+			"q BI /W 2 /H 1 /BPC 8 /CS /CS1 /F /Fl ID x\x9cEI\x85/X Do(\xa0EI\x00\n EI Q BT /F1 12 Tf (Hello) Tj ET"},
+		{"asciiHex",
+			"q BI /W 2 /H 1 /BPC 8 /CS /RGB /F [/AHx] ID 2f58 EI 20446f> EI Q BT /F1 12 Tf (Hello) Tj ET"},
+	} {
+		want := NewPageResourceNames()
+		want["Font"]["F1"] = true
+		if tt.msg == "binary" {
+			want["ColorSpace"]["CS1"] = true
+		}
+
+		got, err := parseContent(tt.s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", tt.msg, err)
+		}
+
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("%s: want:\n%s\ngot:\n%s\n", tt.msg, want, got)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const nsPDFAID = "http://www.aiim.org/pdfa/ns/id/"
+
+// ConformanceIssue describes a violation of an archival standard like PDF/A.
+type ConformanceIssue struct {
+	ObjNr   int // object number of the offending object or 0 if not applicable
+	Message string
+}
+
+func (ci ConformanceIssue) String() string {
+	if ci.ObjNr == 0 {
+		return ci.Message
+	}
+	return fmt.Sprintf("obj#%d: %s", ci.ObjNr, ci.Message)
+}
+
+// pdfaChecker collects the PDF/A conformance issues of a document.
+type pdfaChecker struct {
+	ctx     *Context
+	part    int // 1 for PDF/A-1 (ISO 19005-1), 2 for PDF/A-2 (ISO 19005-2)
+	issues  []ConformanceIssue
+	visited IntSet
+}
+
+func (c *pdfaChecker) add(objNr int, format string, args ...interface{}) {
+	c.issues = append(c.issues, ConformanceIssue{ObjNr: objNr, Message: fmt.Sprintf(format, args...)})
+}
+
+func objNrOf(o Object, defObjNr int) int {
+	if ir, ok := o.(IndirectRef); ok {
+		return ir.ObjectNumber.Value()
+	}
+	return defObjNr
+}
+
+// parsePDFALevel returns the part of a conformance level like 1b, 2b or PDF/A-2b.
+func parsePDFALevel(level string) (int, error) {
+	switch strings.TrimPrefix(strings.ToLower(level), "pdf/a-") {
+	case "1b":
+		return 1, nil
+	case "2b":
+		return 2, nil
+	}
+	return 0, errors.Errorf("pdfcpu: unsupported PDF/A conformance level: %s", level)
+}
+
+// checkEncryption: encryption is not permitted, see ISO 19005-1 6.1.3 and ISO 19005-2 6.1.3
+func (c *pdfaChecker) checkEncryption() {
+	if c.ctx.Encrypt != nil {
+		c.add(c.ctx.Encrypt.ObjectNumber.Value(), "encryption is not permitted")
+	}
+}
+
+// checkOutputIntent: a PDF/A output intent with an ICC profile is required, see ISO 19005-1 6.2.2
+func (c *pdfaChecker) checkOutputIntent(rootDict Dict, rootObjNr int) error {
+
+	a, err := c.ctx.DereferenceArray(rootDict["OutputIntents"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range a {
+		d, err := c.ctx.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+		if s := d.NameEntry("S"); s == nil || *s != "GTS_PDFA1" {
+			continue
+		}
+		if _, found := d.Find("DestOutputProfile"); !found {
+			c.add(objNrOf(o, rootObjNr), "PDF/A output intent without DestOutputProfile")
+		}
+		return nil
+	}
+
+	c.add(rootObjNr, "missing PDF/A output intent (GTS_PDFA1)")
+
+	return nil
+}
+
+// checkMetadata: the XMP metadata of the catalog has to identify the PDF/A part and conformance level,
+// see ISO 19005-1 6.7.11
+func (c *pdfaChecker) checkMetadata(rootDict Dict, rootObjNr int) error {
+
+	sd, err := c.ctx.catalogMetadata()
+	if err != nil {
+		return err
+	}
+	if sd == nil {
+		c.add(rootObjNr, "missing XMP metadata")
+		return nil
+	}
+
+	objNr := objNrOf(rootDict["Metadata"], rootObjNr)
+
+	if sd.FilterPipeline != nil && c.part == 1 {
+		c.add(objNr, "XMP metadata stream must not be filtered")
+	}
+
+	s := string(sd.Content)
+
+	part, ok := xmpSimpleProperty(s, nsPDFAID, "part")
+	if !ok {
+		c.add(objNr, "XMP metadata lacks PDF/A identification (pdfaid:part)")
+		return nil
+	}
+	if part != fmt.Sprintf("%d", c.part) {
+		c.add(objNr, "XMP metadata claims PDF/A-%s", part)
+	}
+
+	conformance, ok := xmpSimpleProperty(s, nsPDFAID, "conformance")
+	if !ok {
+		c.add(objNr, "XMP metadata lacks PDF/A identification (pdfaid:conformance)")
+		return nil
+	}
+
+	// Levels A and U include the requirements of level B.
+	if !MemberOf(conformance, []string{"A", "B", "U"}) || c.part == 1 && conformance == "U" {
+		c.add(objNr, "XMP metadata claims invalid conformance level %s", conformance)
+	}
+
+	return nil
+}
+
+// checkNames: JavaScript is not permitted, PDF/A-1 also prohibits embedded files, see ISO 19005-1 6.1.11 and 6.6.1
+func (c *pdfaChecker) checkNames(rootDict Dict, rootObjNr int) error {
+
+	d, err := c.ctx.DereferenceDict(rootDict["Names"])
+	if err != nil || d == nil {
+		return err
+	}
+
+	objNr := objNrOf(rootDict["Names"], rootObjNr)
+
+	if _, found := d.Find("JavaScript"); found {
+		c.add(objNr, "JavaScript is not permitted")
+	}
+
+	if _, found := d.Find("EmbeddedFiles"); found && c.part == 1 {
+		c.add(objNr, "embedded files are not permitted")
+	}
+
+	return nil
+}
+
+// checkFonts: all fonts have to be embedded, see ISO 19005-1 6.3.4
+func (c *pdfaChecker) checkFonts() error {
+
+	ff, err := c.ctx.ListFonts()
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range ff {
+		if !fi.Embedded {
+			c.add(fi.ObjNr, "font %s is not embedded", fi.Name)
+		}
+	}
+
+	return nil
+}
+
+func (c *pdfaChecker) checkTransparencyGroup(d Dict, objNr int) error {
+
+	gd, err := c.ctx.DereferenceDict(d["Group"])
+	if err != nil || gd == nil {
+		return err
+	}
+
+	if s := gd.NameEntry("S"); s != nil && *s == "Transparency" {
+		c.add(objNr, "transparency groups are not permitted")
+	}
+
+	return nil
+}
+
+func (c *pdfaChecker) checkExtGState(o Object, objNr int) error {
+
+	d, err := c.ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return err
+	}
+
+	objNr = objNrOf(o, objNr)
+
+	if o, found := d.Find("SMask"); found {
+		if n, ok := o.(Name); !ok || n != "None" {
+			c.add(objNr, "soft masks are not permitted")
+		}
+	}
+
+	for _, k := range []string{"CA", "ca"} {
+		if o, found := d.Find(k); found {
+			if f, ok := numbers([]Object{o}, 1); ok && f[0] != 1 {
+				c.add(objNr, "%s %.2f is not permitted", k, f[0])
+			}
+		}
+	}
+
+	o, found := d.Find("BM")
+	if !found {
+		return nil
+	}
+
+	bm := Array{o}
+	if a, ok := o.(Array); ok {
+		bm = a
+	}
+	for _, o := range bm {
+		if n, ok := o.(Name); ok && n != "Normal" && n != "Compatible" {
+			c.add(objNr, "blend mode %s is not permitted", n)
+		}
+	}
+
+	return nil
+}
+
+func (c *pdfaChecker) checkXObject(ir IndirectRef) error {
+
+	objNr := ir.ObjectNumber.Value()
+	if c.visited[objNr] {
+		return nil
+	}
+	c.visited[objNr] = true
+
+	sd, err := c.ctx.DereferenceStreamDict(ir)
+	if err != nil || sd == nil || sd.Subtype() == nil {
+		return err
+	}
+
+	switch *sd.Subtype() {
+
+	case "Image":
+		if _, found := sd.Find("SMask"); found {
+			c.add(objNr, "image soft masks are not permitted")
+		}
+
+	case "Form":
+		if err := c.checkTransparencyGroup(sd.Dict, objNr); err != nil {
+			return err
+		}
+		return c.checkResources(sd.Dict["Resources"], objNr)
+	}
+
+	return nil
+}
+
+func (c *pdfaChecker) checkResources(o Object, objNr int) error {
+
+	resDict, err := c.ctx.DereferenceDict(o)
+	if err != nil || resDict == nil {
+		return err
+	}
+
+	gsResDict, err := c.ctx.DereferenceDict(resDict["ExtGState"])
+	if err != nil {
+		return err
+	}
+	for _, o := range gsResDict {
+		if err := c.checkExtGState(o, objNr); err != nil {
+			return err
+		}
+	}
+
+	xObjResDict, err := c.ctx.DereferenceDict(resDict["XObject"])
+	if err != nil {
+		return err
+	}
+	for _, o := range xObjResDict {
+		if ir, ok := o.(IndirectRef); ok {
+			if err := c.checkXObject(ir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkTransparency: PDF/A-1 prohibits transparency, see ISO 19005-1 6.4
+func (c *pdfaChecker) checkTransparency() error {
+
+	for i := 1; i <= c.ctx.PageCount; i++ {
+
+		d, inhPAttrs, err := c.ctx.PageDict(i, false)
+		if err != nil {
+			return err
+		}
+
+		ir, err := c.ctx.PageDictIndRef(i)
+		if err != nil {
+			return err
+		}
+		objNr := ir.ObjectNumber.Value()
+
+		if err := c.checkTransparencyGroup(d, objNr); err != nil {
+			return err
+		}
+
+		if err := c.checkResources(inhPAttrs.resources, objNr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckPDFA returns the violations of the PDF/A conformance level (1b or 2b) by ctx.
+// Only the constraints PDF/A adds to regular PDF are being checked, see ISO 19005.
+func (ctx *Context) CheckPDFA(level string) ([]ConformanceIssue, error) {
+
+	part, err := parsePDFALevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	rootObjNr := ctx.Root.ObjectNumber.Value()
+
+	c := &pdfaChecker{ctx: ctx, part: part, issues: []ConformanceIssue{}, visited: IntSet{}}
+
+	c.checkEncryption()
+
+	if err := c.checkOutputIntent(rootDict, rootObjNr); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkMetadata(rootDict, rootObjNr); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkNames(rootDict, rootObjNr); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkFonts(); err != nil {
+		return nil, err
+	}
+
+	if part == 1 {
+		if err := c.checkTransparency(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.issues, nil
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DocMDP access permissions granted by a certification signature, see 12.8.2.2 Table 254
+const (
+	// DocMDPNoChanges permits no changes to the document.
+	DocMDPNoChanges = 1 + iota
+
+	// DocMDPFormFill permits filling in forms, instantiating page templates and signing.
+	DocMDPFormFill
+
+	// DocMDPAnnotations permits in addition annotation creation, deletion and modification.
+	DocMDPAnnotations
+)
+
+// DocMDPString returns a string rep for the DocMDP access permissions p.
+func DocMDPString(p int) string {
+	switch p {
+	case DocMDPNoChanges:
+		return "no changes"
+	case DocMDPFormFill:
+		return "form fill"
+	case DocMDPAnnotations:
+		return "annotations"
+	}
+	return "unknown"
+}
+
+// Perms represents the permissions dict of a document, see 12.8.4
+type Perms struct {
+	DocMDP      bool // Document is certified by a signature with modification detection and prevention.
+	DocMDPObjNr int  // Object number of the DocMDP signature dict or 0 for a direct dict.
+	DocMDPLevel int  // DocMDP access permissions.
+	UR3         bool // Document carries a usage rights signature.
+	UR3ObjNr    int  // Object number of the UR3 signature dict or 0 for a direct dict.
+}
+
+func (p Perms) list() []string {
+	ss := []string{}
+	if p.DocMDP {
+		ss = append(ss, fmt.Sprintf("DocMDP (obj#%d): %s (%d)", p.DocMDPObjNr, DocMDPString(p.DocMDPLevel), p.DocMDPLevel))
+	}
+	if p.UR3 {
+		ss = append(ss, fmt.Sprintf("UR3 (obj#%d)", p.UR3ObjNr))
+	}
+	if len(ss) == 0 {
+		ss = append(ss, "no permissions")
+	}
+	return ss
+}
+
+func (p Perms) String() string {
+	return strings.Join(p.list(), "\n")
+}
+
+func (xRefTable *XRefTable) permsDict(create bool) (Dict, error) {
+
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := rootDict.Find("Perms")
+	if found {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil || d != nil || !create {
+			return d, err
+		}
+	}
+
+	if !create {
+		return nil, nil
+	}
+
+	d := NewDict()
+	rootDict.Update("Perms", d)
+
+	return d, nil
+}
+
+// docMDPTransformParams returns the transform parameters dict of the DocMDP signature reference of the signature dict d, see 12.8.1 Table 253
+func (xRefTable *XRefTable) docMDPTransformParams(d Dict) (Dict, error) {
+
+	o, found := d.Find("Reference")
+	if !found {
+		return nil, nil
+	}
+
+	a, err := xRefTable.DereferenceArray(o)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range a {
+		sr, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if sr == nil {
+			continue
+		}
+		if tm := sr.NameEntry("TransformMethod"); tm == nil || *tm != "DocMDP" {
+			continue
+		}
+		tp, err := xRefTable.DereferenceDict(sr["TransformParams"])
+		if err != nil {
+			return nil, err
+		}
+		if tp == nil {
+			tp = Dict(map[string]Object{"Type": Name("TransformParams")})
+			sr.Update("TransformParams", tp)
+		}
+		return tp, nil
+	}
+
+	return nil, nil
+}
+
+func indRefObjNr(o Object) int {
+	if ir, ok := o.(IndirectRef); ok {
+		return ir.ObjectNumber.Value()
+	}
+	return 0
+}
+
+// Perms returns the permissions of the document declared by its permissions dict.
+func (ctx *Context) Perms() (*Perms, error) {
+
+	p := &Perms{}
+
+	d, err := ctx.permsDict(false)
+	if err != nil || d == nil {
+		return p, err
+	}
+
+	if o, found := d.Find("DocMDP"); found {
+		sd, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if sd != nil {
+			p.DocMDP, p.DocMDPObjNr, p.DocMDPLevel = true, indRefObjNr(o), DocMDPFormFill
+			tp, err := ctx.docMDPTransformParams(sd)
+			if err != nil {
+				return nil, err
+			}
+			if tp != nil {
+				if i := tp.IntEntry("P"); i != nil {
+					p.DocMDPLevel = *i
+				}
+			}
+		}
+	}
+
+	if o, found := d.Find("UR3"); found {
+		sd, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if sd != nil {
+			p.UR3, p.UR3ObjNr = true, indRefObjNr(o)
+		}
+	}
+
+	return p, nil
+}
+
+// SetDocMDP sets the access permissions of a certification signature.
+// An existing DocMDP signature dict gets updated, otherwise a signature dict gets prepared
+// referring to the DocMDP transform method with the access permissions p.
+// The prepared signature dict still needs to be completed and referred to by a signature field when signing.
+func (ctx *Context) SetDocMDP(p int) error {
+
+	if p < DocMDPNoChanges || p > DocMDPAnnotations {
+		return errors.Errorf("pdfcpu: invalid DocMDP access permissions: %d", p)
+	}
+
+	d, err := ctx.permsDict(true)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Version() < V15 {
+		ctx.EnsureVersionForWriting()
+	}
+
+	if o, found := d.Find("DocMDP"); found {
+		sd, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if sd != nil {
+			tp, err := ctx.docMDPTransformParams(sd)
+			if err != nil {
+				return err
+			}
+			if tp == nil {
+				return errors.New("pdfcpu: DocMDP signature without DocMDP signature reference")
+			}
+			tp.Update("P", Integer(p))
+			return nil
+		}
+	}
+
+	sd := Dict(
+		map[string]Object{
+			"Type":      Name("Sig"),
+			"Filter":    Name("Adobe.PPKLite"),
+			"SubFilter": Name("adbe.pkcs7.detached"),
+			"Reference": Array{
+				Dict(
+					map[string]Object{
+						"Type":            Name("SigRef"),
+						"TransformMethod": Name("DocMDP"),
+						"TransformParams": Dict(
+							map[string]Object{
+								"Type": Name("TransformParams"),
+								"P":    Integer(p),
+								"V":    Name("1.2"),
+							},
+						),
+					},
+				),
+			},
+		},
+	)
+
+	// The DocMDP signature dict shall be an indirect reference.
+	ir, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		return err
+	}
+
+	d.Update("DocMDP", *ir)
+
+	return nil
+}
+
+// ListPerms returns a list of the permissions declared by the permissions dict of ctx.
+func ListPerms(ctx *Context) ([]string, error) {
+
+	p, err := ctx.Perms()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.list(), nil
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// PrintMarks represents the printer's marks drawn around the trim box of a page.
+type PrintMarks struct {
+	CropMarks         bool    // Draw crop marks at the corners of the trim box.
+	RegistrationMarks bool    // Draw registration targets centered along each side of the trim box.
+	ColorBars         bool    // Draw CMYK color bars below the trim box.
+	Length            float64 // Length of crop marks in points, also sizes registration marks and color bars.
+	Offset            float64 // Minimum distance of all marks from the trim box in points.
+	LineWidth         float64 // Line width of crop and registration marks in points.
+}
+
+// DefaultPrintMarks returns the default printer's marks configuration.
+func DefaultPrintMarks() *PrintMarks {
+	return &PrintMarks{
+		CropMarks:         true,
+		RegistrationMarks: true,
+		Length:            18,
+		Offset:            6,
+		LineWidth:         0.25,
+	}
+}
+
+func (pm PrintMarks) String() string {
+	return fmt.Sprintf("PrintMarks: crop=%t reg=%t colorBars=%t length=%.2f offset=%.2f lineWidth=%.2f",
+		pm.CropMarks, pm.RegistrationMarks, pm.ColorBars, pm.Length, pm.Offset, pm.LineWidth)
+}
+
+// colorBarColors are the CMYK patches of a color bar: solid process colors, overprints and tints of black.
+var colorBarColors = [][4]float64{
+	{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}, {0, 0, 0, 1},
+	{1, 1, 0, 0}, {1, 0, 1, 0}, {0, 1, 1, 0},
+	{0, 0, 0, .75}, {0, 0, 0, .5}, {0, 0, 0, .25},
+}
+
+// printMarksMargins returns the distance of the marks from the trim box t for the left, bottom, right and top side
+// taking into account the bleed box b.
+func printMarksMargins(t, b *Rectangle, offset float64) [4]float64 {
+	return [4]float64{
+		math.Max(offset, t.LL.X-b.LL.X),
+		math.Max(offset, t.LL.Y-b.LL.Y),
+		math.Max(offset, b.UR.X-t.UR.X),
+		math.Max(offset, b.UR.Y-t.UR.Y),
+	}
+}
+
+// printMarksBox returns the region covered by marks drawn around the trim box t using margins mm.
+func printMarksBox(t *Rectangle, mm [4]float64, length float64) *Rectangle {
+	return Rect(t.LL.X-mm[0]-length, t.LL.Y-mm[1]-length, t.UR.X+mm[2]+length, t.UR.Y+mm[3]+length)
+}
+
+func drawCropMarks(b *bytes.Buffer, t *Rectangle, mm [4]float64, l float64) {
+	for _, x := range []float64{t.LL.X, t.UR.X} {
+		DrawLine(b, x, t.LL.Y-mm[1]-l, x, t.LL.Y-mm[1])
+		DrawLine(b, x, t.UR.Y+mm[3], x, t.UR.Y+mm[3]+l)
+	}
+	for _, y := range []float64{t.LL.Y, t.UR.Y} {
+		DrawLine(b, t.LL.X-mm[0]-l, y, t.LL.X-mm[0], y)
+		DrawLine(b, t.UR.X+mm[2], y, t.UR.X+mm[2]+l, y)
+	}
+}
+
+func drawRegistrationMark(b *bytes.Buffer, x, y, l float64) {
+	r := l / 4
+	ellipsePath(b, Rect(x-r, y-r, x+r, y+r))
+	b.WriteString("S ")
+	DrawLine(b, x-l/2, y, x+l/2, y)
+	DrawLine(b, x, y-l/2, x, y+l/2)
+}
+
+func drawRegistrationMarks(b *bytes.Buffer, t *Rectangle, mm [4]float64, l float64) {
+	c := t.Center()
+	drawRegistrationMark(b, t.LL.X-mm[0]-l/2, c.Y, l)
+	drawRegistrationMark(b, t.UR.X+mm[2]+l/2, c.Y, l)
+	drawRegistrationMark(b, c.X, t.LL.Y-mm[1]-l/2, l)
+	drawRegistrationMark(b, c.X, t.UR.Y+mm[3]+l/2, l)
+}
+
+// drawColorBars draws a row of color patches centered in the bottom margin starting next to the left crop marks.
+func drawColorBars(b *bytes.Buffer, t *Rectangle, mm [4]float64, l float64) {
+	s := l / 2
+	y := t.LL.Y - mm[1] - l/2 - s/2
+	for i, c := range colorBarColors {
+		fmt.Fprintf(b, "%.2f %.2f %.2f %.2f k %.2f %.2f %.2f %.2f re f ", c[0], c[1], c[2], c[3], t.LL.X+l+float64(i)*s, y, s, s)
+	}
+}
+
+func printMarksContent(t, bb *Rectangle, pm *PrintMarks) []byte {
+
+	mm := printMarksMargins(t, bb, pm.Offset)
+
+	var b bytes.Buffer
+
+	b.WriteString("/Artifact <</Type /Page >>BDC q ")
+	SetLineWidth(&b, pm.LineWidth)
+
+	// Registration color shows up on all separations.
+	b.WriteString("1 1 1 1 K ")
+
+	if pm.CropMarks {
+		drawCropMarks(&b, t, mm, pm.Length)
+	}
+
+	if pm.RegistrationMarks {
+		drawRegistrationMarks(&b, t, mm, pm.Length)
+	}
+
+	if pm.ColorBars {
+		drawColorBars(&b, t, mm, pm.Length)
+	}
+
+	b.WriteString("Q EMC ")
+
+	return b.Bytes()
+}
+
+// appendPageContent adds buf to the content of the page dict d.
+// The existing content gets wrapped into q/Q so that buf is rendered using the initial graphics state.
+func (xRefTable *XRefTable) appendPageContent(d Dict, buf []byte) error {
+
+	ir := func(buf []byte) (*IndirectRef, error) {
+		sd, _ := xRefTable.NewStreamDictForBuf(buf)
+		if err := sd.Encode(); err != nil {
+			return nil, err
+		}
+		return xRefTable.IndRefForNewObject(*sd)
+	}
+
+	o, found := d.Find("Contents")
+	if !found {
+		irContent, err := ir(buf)
+		if err != nil {
+			return err
+		}
+		d.Insert("Contents", *irContent)
+		return nil
+	}
+
+	var a Array
+
+	switch o1 := o.(type) {
+	case IndirectRef:
+		o2, err := xRefTable.Dereference(o1)
+		if err != nil {
+			return err
+		}
+		if a1, ok := o2.(Array); ok {
+			a = append(a, a1...)
+		} else {
+			a = Array{o1}
+		}
+	case Array:
+		a = append(a, o1...)
+	default:
+		return errors.New("pdfcpu: appendPageContent: corrupt page content")
+	}
+
+	irSave, err := ir([]byte("q "))
+	if err != nil {
+		return err
+	}
+
+	irContent, err := ir(append([]byte("Q "), buf...))
+	if err != nil {
+		return err
+	}
+
+	a = append(append(Array{*irSave}, a...), *irContent)
+
+	d.Update("Contents", a)
+
+	return nil
+}
+
+func addPagePrintMarks(xRefTable *XRefTable, pageNr int, pm *PrintMarks) error {
+
+	d, inhPAttrs, err := xRefTable.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: AddPrintMarks: unknown page number: %d", pageNr)
+	}
+
+	rr, err := xRefTable.explicitPageBoxes(d, inhPAttrs)
+	if err != nil {
+		return errors.Wrapf(err, "pdfcpu: AddPrintMarks: page %d", pageNr)
+	}
+	er := effectivePageBoxes(rr)
+
+	mb, t, bb := er[0], er[3], er[2]
+
+	r := printMarksBox(t, printMarksMargins(t, bb, pm.Offset), pm.Length)
+
+	if within(r, er[1]) {
+		return xRefTable.appendPageContent(d, printMarksContent(t, bb, pm))
+	}
+
+	if !within(r, mb) {
+		// Enlarge the media box to make room for the marks.
+		mb = unionRect(mb, r)
+		log.Debug.Printf("AddPrintMarks page %d: new media box %s\n", pageNr, mb)
+		d.Update("MediaBox", mb.Array())
+	}
+
+	if rr[1] != nil {
+		d.Update("CropBox", unionRect(rr[1], r).Array())
+	}
+
+	// Keep boxes defaulting to the crop box in place.
+	for i, k := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+		if rr[i+2] == nil {
+			d.Update(k, er[i+2].Array())
+		}
+	}
+
+	return xRefTable.appendPageContent(d, printMarksContent(t, bb, pm))
+}
+
+// AddPrintMarks draws crop marks, registration marks and color bars as configured by pm outside the trim box
+// and bleed box of selected pages.
+// The media box of a page gets enlarged if it is too small to accommodate the marks.
+func AddPrintMarks(ctx *Context, selectedPages IntSet, pm *PrintMarks) error {
+
+	if pm == nil {
+		pm = DefaultPrintMarks()
+	}
+
+	log.Debug.Printf("AddPrintMarks %s\n", pm)
+
+	if pm.Length <= 0 || pm.Offset < 0 || pm.LineWidth < 0 {
+		return errors.Errorf("pdfcpu: AddPrintMarks: invalid configuration: %s", pm)
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if selectedPages != nil && !selectedPages[i] {
+			continue
+		}
+		if err := addPagePrintMarks(ctx.XRefTable, i, pm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
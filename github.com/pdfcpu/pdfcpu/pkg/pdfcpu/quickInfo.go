@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// QuickInfo represents basic properties of a PDF file available without reading all of its objects.
+type QuickInfo struct {
+	PageCount int    // -1 if unavailable, e.g. for encrypted files using object streams opened without the password.
+	Version   string // the effective PDF version.
+	Encrypted bool
+	AcroForm  bool
+}
+
+func (qi QuickInfo) String() string {
+	return fmt.Sprintf("pages: %d, version: %s, encrypted: %t, acroForm: %t", qi.PageCount, qi.Version, qi.Encrypted, qi.AcroForm)
+}
+
+// quickObject resolves o loading just the referenced object and, if compressed, its object stream.
+func quickObject(ctx *Context, o Object) (Object, error) {
+
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		return o, nil
+	}
+
+	objNr := ir.ObjectNumber.Value()
+
+	entry, found := ctx.Find(objNr)
+	if !found || entry.Free {
+		return nil, errors.Errorf("pdfcpu: quickObject: missing obj#%d", objNr)
+	}
+
+	if entry.Compressed {
+		osEntry, found := ctx.Find(*entry.ObjectStream)
+		if !found {
+			return nil, errors.Errorf("pdfcpu: quickObject: missing object stream obj#%d", *entry.ObjectStream)
+		}
+		if _, ok := osEntry.Object.(ObjectStreamDict); !ok {
+			if err := decodeObjectStream(ctx, *entry.ObjectStream); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return dereferencedObject(ctx, objNr)
+}
+
+func quickDict(ctx *Context, o Object) (Dict, error) {
+
+	o, err := quickObject(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := o.(Dict)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: quickDict: want dict, got: %T", o)
+	}
+
+	return d, nil
+}
+
+// quickEncryptionKey sets up the encryption key for the configured passwords.
+func quickEncryptionKey(ctx *Context) error {
+
+	d, err := dereferencedDict(ctx, ctx.Encrypt.ObjectNumber.Value())
+	if err != nil {
+		return err
+	}
+
+	return setupEncryptionKey(ctx, d)
+}
+
+// quickCatalogInfo fills in the properties of qi provided by the catalog and the page tree root.
+func quickCatalogInfo(ctx *Context, qi *QuickInfo) error {
+
+	if ctx.Root == nil {
+		return errors.New("pdfcpu: missing root object")
+	}
+
+	rootDict, err := quickDict(ctx, *ctx.Root)
+	if err != nil {
+		return err
+	}
+
+	if v := rootDict.NameEntry("Version"); v != nil {
+		if rv, err := PDFVersion(*v); err == nil && *ctx.HeaderVersion >= V14 {
+			ctx.RootVersion = &rv
+		}
+	}
+
+	_, qi.AcroForm = rootDict.Find("AcroForm")
+
+	pagesDict, err := quickDict(ctx, rootDict["Pages"])
+	if err != nil {
+		return err
+	}
+
+	o, err := quickObject(ctx, pagesDict["Count"])
+	if err != nil {
+		return err
+	}
+
+	i, ok := o.(Integer)
+	if !ok {
+		return errors.New("pdfcpu: missing page count")
+	}
+	qi.PageCount = i.Value()
+
+	return nil
+}
+
+// ReadQuickInfo returns the page count, version, encryption status and form status of rs
+// looking at the cross reference table, the catalog and the root of the page tree only.
+// Encrypted files are opened using the configured passwords.
+// If this fails the page count is still available as long as the involved objects are not compressed.
+func ReadQuickInfo(rs io.ReadSeeker, conf *Configuration) (*QuickInfo, error) {
+
+	ctx, err := NewContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = readXRefTable(ctx); err != nil {
+		return nil, errors.Wrap(err, "ReadQuickInfo: xRefTable failed")
+	}
+
+	qi := &QuickInfo{PageCount: -1, Encrypted: ctx.Encrypt != nil}
+
+	if qi.Encrypted {
+		if err := quickEncryptionKey(ctx); err != nil {
+			// Integers and names are not encrypted, so we may still get at the page count.
+			log.Read.Printf("ReadQuickInfo: %v\n", err)
+			ctx.EncKey = nil
+		}
+	}
+
+	if err = quickCatalogInfo(ctx, qi); err != nil {
+		if !qi.Encrypted || ctx.EncKey != nil {
+			return nil, err
+		}
+		log.Read.Printf("ReadQuickInfo: %v\n", err)
+	}
+
+	qi.Version = ctx.VersionString()
+
+	return qi, nil
+}
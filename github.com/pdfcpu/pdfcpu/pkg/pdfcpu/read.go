@@ -19,6 +19,7 @@ package pdfcpu
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"sort"
@@ -35,6 +36,9 @@ const (
 	//unknownDelimiter = byte(0)
 )
 
+// ErrWrongPassword is returned when reading an encrypted file with neither a correct user nor owner password.
+var ErrWrongPassword = errors.New("pdfcpu: please provide the correct password")
+
 // ReadFile reads in a PDF file and builds an internal structure holding its cross reference table aka the Context.
 func ReadFile(inFile string, conf *Configuration) (*Context, error) {
 
@@ -80,6 +84,12 @@ func Read(rs io.ReadSeeker, conf *Configuration) (*Context, error) {
 		return nil, err
 	}
 
+	if ctx.PreserveHeader {
+		if ctx.Read.Header, ctx.Read.Eol, err = headerBytes(rs); err != nil {
+			return nil, err
+		}
+	}
+
 	// Some PDFWriters write an incorrent Size into trailer.
 	if *ctx.XRefTable.Size < len(ctx.XRefTable.Table) {
 		*ctx.XRefTable.Size = len(ctx.XRefTable.Table)
@@ -545,7 +555,7 @@ func xRefStreamDict(ctx *Context, o Object, objNr int, streamOffset int64) (*XRe
 	log.Read.Printf("xRefStreamDict: streamobject #%d\n", objNr)
 	sd := NewStreamDict(d, streamOffset, streamLength, streamLengthObjNr, filterPipeline)
 
-	if _, err = loadEncodedStreamContent(ctx, &sd); err != nil {
+	if _, err = loadEncodedStreamContent(ctx, &sd, objNr); err != nil {
 		return nil, err
 	}
 
@@ -1046,6 +1056,53 @@ func headerVersion(rs io.ReadSeeker) (v *Version, eolCount int, err error) {
 	return &pdfVersion, eolCount, nil
 }
 
+// headerBytes returns the header line of rs and an optional binary marker comment line following it
+// verbatim including their end of line markers along with the end of line marker of the header line.
+func headerBytes(rs io.ReadSeeker) ([]byte, string, error) {
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	buf := make([]byte, 1024)
+	n, err := io.ReadFull(rs, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, "", err
+	}
+	buf = buf[:n]
+
+	line := func(bb []byte) (int, string) {
+		for i, b := range bb {
+			if b == 0x0A {
+				return i + 1, EolLF
+			}
+			if b == 0x0D {
+				if i+1 < len(bb) && bb[i+1] == 0x0A {
+					return i + 2, EolCRLF
+				}
+				return i + 1, EolCR
+			}
+		}
+		return -1, ""
+	}
+
+	i, eol := line(buf)
+	if i < 0 || !bytes.HasPrefix(buf, []byte("%PDF-")) {
+		return nil, "", errors.New("pdfcpu: headerBytes: corrupt pdf stream - no header available")
+	}
+
+	if j, _ := line(buf[i:]); j > 0 && buf[i] == '%' {
+		for _, b := range buf[i : i+j] {
+			if b >= 0x80 {
+				i += j
+				break
+			}
+		}
+	}
+
+	return buf[:i], eol, nil
+}
+
 // bypassXrefSection is a hack for digesting corrupt xref sections.
 // It populates the xRefTable by reading in all indirect objects line by line
 // and works on the assumption of a single xref section - meaning no incremental updates have been made.
@@ -1142,6 +1199,47 @@ func bypassXrefSection(ctx *Context) error {
 	return nil
 }
 
+// Parse the xref section or xref stream at offset and return the offset of any previous xref section.
+func parseXRefSectionAt(ctx *Context, offset *int64) (*int64, error) {
+
+	rs := ctx.Read.rs
+
+	rd, err := newPositionedReader(rs, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s := bufio.NewScanner(rd)
+	s.Split(scanLines)
+
+	line, err := scanLine(s)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Read.Printf("line: <%s>\n", line)
+
+	if strings.TrimSpace(line) == "xref" {
+		log.Read.Println("buildXRefTableStartingAt: found xref section")
+		return parseXRefSection(s, ctx)
+	}
+
+	log.Read.Println("buildXRefTableStartingAt: found xref stream")
+	ctx.Read.UsingXRefStreams = true
+	rd, err = newPositionedReader(rs, offset)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := parseXRefStream(rd, offset, ctx)
+	if err != nil {
+		log.Read.Printf("bypassXRefSection after %v\n", err)
+		// Try fix for corrupt single xref section.
+		return nil, bypassXrefSection(ctx)
+	}
+
+	return prev, nil
+}
+
 // Build XRefTable by reading XRef streams or XRef sections.
 func buildXRefTableStartingAt(ctx *Context, offset *int64) error {
 
@@ -1158,41 +1256,9 @@ func buildXRefTableStartingAt(ctx *Context, offset *int64) error {
 	ctx.Read.EolCount = eolCount
 
 	for offset != nil {
-
-		rd, err := newPositionedReader(rs, offset)
-		if err != nil {
+		if offset, err = parseXRefSectionAt(ctx, offset); err != nil {
 			return err
 		}
-
-		s := bufio.NewScanner(rd)
-		s.Split(scanLines)
-
-		line, err := scanLine(s)
-		if err != nil {
-			return err
-		}
-
-		log.Read.Printf("line: <%s>\n", line)
-
-		if strings.TrimSpace(line) == "xref" {
-			log.Read.Println("buildXRefTableStartingAt: found xref section")
-			if offset, err = parseXRefSection(s, ctx); err != nil {
-				return err
-			}
-		} else {
-
-			log.Read.Println("buildXRefTableStartingAt: found xref stream")
-			ctx.Read.UsingXRefStreams = true
-			rd, err = newPositionedReader(rs, offset)
-			if err != nil {
-				return err
-			}
-			if offset, err = parseXRefStream(rd, offset, ctx); err != nil {
-				log.Read.Printf("bypassXRefSection after %v\n", err)
-				// Try fix for corrupt single xref section.
-				return bypassXrefSection(ctx)
-			}
-		}
 	}
 
 	log.Read.Println("buildXRefTableStartingAt: end")
@@ -1534,7 +1600,7 @@ func streamDictForObject(ctx *Context, d Dict, objNr, streamInd int, streamOffse
 
 func dict(ctx *Context, d1 Dict, objNr, genNr, endInd, streamInd int) (d2 Dict, err error) {
 
-	if ctx.EncKey != nil {
+	if ctx.encryptedStrings() {
 		_, err := decryptDeepObject(d1, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return nil, err
@@ -1639,7 +1705,7 @@ func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 		return streamDictForObject(ctx, o, objNr, streamInd, streamOffset, offset)
 
 	case Array:
-		if ctx.EncKey != nil {
+		if ctx.encryptedStrings() {
 			if _, err = decryptDeepObject(o, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R); err != nil {
 				return nil, err
 			}
@@ -1647,7 +1713,7 @@ func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 		return o, nil
 
 	case StringLiteral:
-		if ctx.EncKey != nil {
+		if ctx.encryptedStrings() {
 			s1, err := decryptString(o.Value(), objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 			if err != nil {
 				return nil, err
@@ -1657,7 +1723,7 @@ func ParseObject(ctx *Context, offset int64, objNr, genNr int) (Object, error) {
 		return o, nil
 
 	case HexLiteral:
-		if ctx.EncKey != nil {
+		if ctx.encryptedStrings() {
 			bb, err := decryptHexLiteral(o, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 			if err != nil {
 				return nil, err
@@ -1784,8 +1850,96 @@ func readContentStream(rd io.Reader, streamLength int) ([]byte, error) {
 	return buf, nil
 }
 
+// endstreamFollows returns true if the keyword endstream follows offset, optionally preceded by white space.
+func endstreamFollows(rs io.ReadSeeker, offset int64) bool {
+
+	if offset < 0 {
+		return false
+	}
+
+	if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+		return false
+	}
+
+	buf := make([]byte, 64)
+	n, _ := io.ReadFull(rs, buf)
+
+	return bytes.HasPrefix(bytes.TrimLeft(buf[:n], "\x00\t\n\f\r "), []byte("endstream"))
+}
+
+// streamDataUpToEndstream returns the stream data starting at offset up to the next endstream keyword
+// excluding the EOL marker preceding endstream.
+func streamDataUpToEndstream(rs io.ReadSeeker, offset int64) ([]byte, error) {
+
+	rd, err := newPositionedReader(rs, &offset)
+	if err != nil {
+		return nil, err
+	}
+
+	kw := []byte("endstream")
+	buf := []byte{}
+	chunk := make([]byte, 4096)
+
+	for {
+		n, err := rd.Read(chunk)
+
+		from := len(buf) - len(kw) + 1
+		if from < 0 {
+			from = 0
+		}
+		buf = append(buf, chunk[:n]...)
+
+		if i := bytes.Index(buf[from:], kw); i >= 0 {
+			i += from
+			if i > 0 && buf[i-1] == '\n' {
+				i--
+			}
+			if i > 0 && buf[i-1] == '\r' {
+				i--
+			}
+			return buf[:i], nil
+		}
+
+		if err == io.EOF {
+			return nil, errors.New("pdfcpu: streamDataUpToEndstream: missing endstream")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// recoverStreamContent returns the stream data of sd located by scanning for the endstream keyword
+// and corrects the stream length accordingly.
+func recoverStreamContent(ctx *Context, sd *StreamDict, objNr int) ([]byte, error) {
+
+	msg := fmt.Sprintf("obj#%d: stream data not followed by endstream for Length %d", objNr, *sd.StreamLength)
+
+	if ctx.StreamLengthMode == StreamLengthError {
+		return nil, errors.New("pdfcpu: " + msg)
+	}
+
+	rawContent, err := streamDataUpToEndstream(ctx.Read.rs, sd.StreamOffset)
+	if err != nil {
+		return nil, errors.Wrapf(err, "obj#%d", objNr)
+	}
+
+	l := int64(len(rawContent))
+	msg = fmt.Sprintf("%s, corrected to %d", msg, l)
+	log.Info.Println("pdfcpu: " + msg)
+	if err := ctx.Report(SeverityWarning, objNr, msg); err != nil {
+		return nil, err
+	}
+
+	sd.StreamLength = &l
+	sd.Dict["Length"] = Integer(l)
+
+	return rawContent, nil
+}
+
 // LoadEncodedStreamContent loads the encoded stream content from file into StreamDict.
-func loadEncodedStreamContent(ctx *Context, sd *StreamDict) ([]byte, error) {
+// A stream length not matching the position of endstream is handled according to ctx.StreamLengthMode.
+func loadEncodedStreamContent(ctx *Context, sd *StreamDict, objNr int) ([]byte, error) {
 
 	log.Read.Printf("LoadEncodedStreamContent: begin\n%v\n", sd)
 
@@ -1812,6 +1966,15 @@ func loadEncodedStreamContent(ctx *Context, sd *StreamDict) ([]byte, error) {
 		log.Read.Printf("LoadEncodedStreamContent: new indirect streamLength:%d\n", *sd.StreamLength)
 	}
 
+	if !endstreamFollows(ctx.Read.rs, sd.StreamOffset+*sd.StreamLength) {
+		rawContent, err := recoverStreamContent(ctx, sd, objNr)
+		if err != nil {
+			return nil, err
+		}
+		sd.Raw = rawContent
+		return rawContent, nil
+	}
+
 	newOffset := sd.StreamOffset
 	rd, err := newPositionedReader(ctx.Read.rs, &newOffset)
 	if err != nil {
@@ -1866,7 +2029,7 @@ func saveDecodedStreamContent(ctx *Context, sd *StreamDict, objNr, genNr int, de
 
 	// ctx gets created after XRefStream parsing.
 	// XRefStreams are not encrypted.
-	if ctx != nil && ctx.EncKey != nil {
+	if ctx != nil && ctx.encryptedStreams() {
 		sd.Raw, err = decryptStream(sd.Raw, objNr, genNr, ctx.EncKey, ctx.AES4Streams, ctx.E.R)
 		if err != nil {
 			return err
@@ -1963,86 +2126,95 @@ func logStream(o Object) {
 
 }
 
-// Decode all object streams so contained objects are ready to be used.
-func decodeObjectStreams(ctx *Context) error {
+// Decode object stream objectNumber and save the resulting ObjectStreamDict to its xRefTable entry.
+func decodeObjectStream(ctx *Context, objectNumber int) error {
 
-	// Note:
-	// Entry "Extends" intentionally left out.
-	// No object stream collection validation necessary.
+	// Get XRefTableEntry.
+	entry := ctx.XRefTable.Table[objectNumber]
+	if entry == nil {
+		return errors.Errorf("decodeObjectStream: missing entry for obj#%d\n", objectNumber)
+	}
 
-	log.Read.Println("decodeObjectStreams: begin")
+	log.Read.Printf("decodeObjectStreams: parsing object stream for obj#%d\n", objectNumber)
 
-	// Get sorted slice of object numbers.
-	var keys []int
-	for k := range ctx.Read.ObjectStreams {
-		keys = append(keys, k)
+	// Parse object stream from file.
+	o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
+	if err != nil || o == nil {
+		return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
 	}
-	sort.Ints(keys)
 
-	for _, objectNumber := range keys {
+	// Ensure StreamDict
+	sd, ok := o.(StreamDict)
+	if !ok {
+		return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
+	}
 
-		// Get XRefTableEntry.
-		entry := ctx.XRefTable.Table[objectNumber]
-		if entry == nil {
-			return errors.Errorf("decodeObjectStream: missing entry for obj#%d\n", objectNumber)
-		}
+	// Load encoded stream content to xRefTable.
+	if _, err = loadEncodedStreamContent(ctx, &sd, objectNumber); err != nil {
+		return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
+	}
+
+	// Save decoded stream content to xRefTable.
+	if err = saveDecodedStreamContent(ctx, &sd, objectNumber, *entry.Generation, true); err != nil {
+		log.Read.Printf("obj %d: %s", objectNumber, err)
+		return err
+	}
 
-		log.Read.Printf("decodeObjectStreams: parsing object stream for obj#%d\n", objectNumber)
+	// Ensure decoded objectArray for object stream dicts.
+	if !sd.IsObjStm() {
+		return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
+	}
 
-		// Parse object stream from file.
-		o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
-		if err != nil || o == nil {
-			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
-		}
+	// We have an object stream.
+	log.Read.Printf("decodeObjectStreams: object stream #%d\n", objectNumber)
 
-		// Ensure StreamDict
-		sd, ok := o.(StreamDict)
-		if !ok {
-			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
-		}
+	ctx.Read.UsingObjectStreams = true
 
-		// Load encoded stream content to xRefTable.
-		if _, err = loadEncodedStreamContent(ctx, &sd); err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
-		}
+	// Create new object stream dict.
+	osd, err := objectStreamDict(&sd)
+	if err != nil {
+		return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
+	}
 
-		// Save decoded stream content to xRefTable.
-		if err = saveDecodedStreamContent(ctx, &sd, objectNumber, *entry.Generation, true); err != nil {
-			log.Read.Printf("obj %d: %s", objectNumber, err)
-			return err
-		}
+	log.Read.Printf("decodeObjectStreams: decoding object stream %d:\n", objectNumber)
 
-		// Ensure decoded objectArray for object stream dicts.
-		if !sd.IsObjStm() {
-			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
-		}
+	// Parse all objects of this object stream and save them to ObjectStreamDict.ObjArray.
+	if err = parseObjectStream(osd); err != nil {
+		return errors.Wrapf(err, "decodeObjectStreams: problem decoding object stream %d\n", objectNumber)
+	}
 
-		// We have an object stream.
-		log.Read.Printf("decodeObjectStreams: object stream #%d\n", objectNumber)
+	if osd.ObjArray == nil {
+		return errors.Wrap(err, "decodeObjectStreams: objArray should be set!")
+	}
 
-		ctx.Read.UsingObjectStreams = true
+	log.Read.Printf("decodeObjectStreams: decoded object stream %d:\n", objectNumber)
 
-		// Create new object stream dict.
-		osd, err := objectStreamDict(&sd)
-		if err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
-		}
+	// Save object stream dict to xRefTableEntry.
+	entry.Object = *osd
 
-		log.Read.Printf("decodeObjectStreams: decoding object stream %d:\n", objectNumber)
+	return nil
+}
 
-		// Parse all objects of this object stream and save them to ObjectStreamDict.ObjArray.
-		if err = parseObjectStream(osd); err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem decoding object stream %d\n", objectNumber)
-		}
+// Decode all object streams so contained objects are ready to be used.
+func decodeObjectStreams(ctx *Context) error {
 
-		if osd.ObjArray == nil {
-			return errors.Wrap(err, "decodeObjectStreams: objArray should be set!")
-		}
+	// Note:
+	// Entry "Extends" intentionally left out.
+	// No object stream collection validation necessary.
+
+	log.Read.Println("decodeObjectStreams: begin")
 
-		log.Read.Printf("decodeObjectStreams: decoded object stream %d:\n", objectNumber)
+	// Get sorted slice of object numbers.
+	var keys []int
+	for k := range ctx.Read.ObjectStreams {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
 
-		// Save object stream dict to xRefTableEntry.
-		entry.Object = *osd
+	for _, objectNumber := range keys {
+		if err := decodeObjectStream(ctx, objectNumber); err != nil {
+			return err
+		}
 	}
 
 	log.Read.Println("decodeObjectStreams: end")
@@ -2102,7 +2274,7 @@ func loadStreamDict(ctx *Context, sd *StreamDict, objNr, genNr int) error {
 	var err error
 
 	// Load encoded stream content for stream dicts into xRefTable entry.
-	if _, err = loadEncodedStreamContent(ctx, sd); err != nil {
+	if _, err = loadEncodedStreamContent(ctx, sd, objNr); err != nil {
 		return errors.Wrapf(err, "dereferenceObject: problem dereferencing stream %d", objNr)
 	}
 
@@ -2456,16 +2628,47 @@ func setupEncryptionKey(ctx *Context, d Dict) (err error) {
 		return err
 	}
 
-	var ok bool
+	err = validatePasswords(ctx)
+	if err != ErrWrongPassword || ctx.PasswordFunc == nil || ctx.UserPW != "" || ctx.OwnerPW != "" {
+		return err
+	}
+
+	// Fetch the missing password and try it as user password first.
+	pw, err := ctx.PasswordFunc()
+	if err != nil {
+		return err
+	}
+
+	// ctx shares the caller's configuration which must not retain the fetched password.
+	defer func() {
+		ctx.UserPW, ctx.OwnerPW = "", ""
+	}()
+
+	ctx.UserPW = pw
+	if err = validatePasswords(ctx); err != ErrWrongPassword {
+		return err
+	}
+
+	ctx.UserPW, ctx.OwnerPW = "", pw
+
+	return validatePasswords(ctx)
+}
+
+func validatePasswords(ctx *Context) error {
 
 	//fmt.Printf("opw: <%s> upw: <%s> \n", ctx.OwnerPW, ctx.UserPW)
 
 	// Validate the owner password aka. permissions/master password.
-	ok, err = validateOwnerPassword(ctx)
+	ok, err := validateOwnerPassword(ctx)
 	if err != nil {
 		return err
 	}
 
+	// Changing passwords using the owner password only requires it to be correct.
+	if !ok && ctx.Cmd == CHANGEPW {
+		return errors.New("pdfcpu: please provide the correct owner password")
+	}
+
 	// If the owner password does not match we generally move on if the user password is correct
 	// unless we need to insist on a correct owner password due to the specific command in progress.
 	if !ok && needsOwnerAndUserPassword(ctx.Cmd) {
@@ -2492,7 +2695,7 @@ func setupEncryptionKey(ctx *Context, d Dict) (err error) {
 		return err
 	}
 	if !ok {
-		return errors.New("pdfcpu: please provide the correct password")
+		return ErrWrongPassword
 	}
 
 	//fmt.Printf("upw ok: %t\n", ok)
@@ -2527,3 +2730,57 @@ func checkForEncryption(ctx *Context) error {
 	// We need to decrypt this file in order to read it.
 	return setupEncryptionKey(ctx, d)
 }
+
+// EncryptionStatus reads the cross reference table of rs and inspects the encrypt dictionary referenced by the trailer
+// without decrypting any objects.
+// For supported security handlers hasUserPW reports if a user password is required to open the file
+// and hasOwnerPW reports if an owner password other than the empty string protects the access permissions.
+// Files using an unsupported security handler are reported as protected by both passwords.
+func EncryptionStatus(rs io.ReadSeeker, conf *Configuration) (encrypted, hasUserPW, hasOwnerPW bool, err error) {
+
+	ctx, err := NewContext(rs, conf)
+	if err != nil {
+		return false, false, false, err
+	}
+
+	if err = readXRefTable(ctx); err != nil {
+		return false, false, false, errors.Wrap(err, "EncryptionStatus: xRefTable failed")
+	}
+
+	ir := ctx.Encrypt
+	if ir == nil {
+		return false, false, false, nil
+	}
+
+	d, err := dereferencedDict(ctx, ir.ObjectNumber.Value())
+	if err != nil {
+		return true, false, false, err
+	}
+
+	if ctx.E, err = supportedEncryption(ctx, d); err != nil {
+		log.Read.Printf("EncryptionStatus: %v\n", err)
+		return true, true, true, nil
+	}
+
+	if ctx.E.R < 5 {
+		if ctx.E.ID, err = idBytes(ctx); err != nil {
+			return true, false, false, err
+		}
+	}
+
+	// Try the empty passwords.
+	ctx.UserPW, ctx.OwnerPW = "", ""
+
+	ok, err := validateUserPassword(ctx)
+	if err != nil {
+		return true, false, false, err
+	}
+	hasUserPW = !ok
+
+	if ok, err = validateOwnerPassword(ctx); err != nil {
+		return true, false, false, err
+	}
+	hasOwnerPW = !ok
+
+	return true, hasUserPW, hasOwnerPW, nil
+}
@@ -17,10 +17,11 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"bytes"
 	"image"
 	"image/color"
 	"image/draw"
-	_ "image/jpeg"
+	"image/jpeg"
 	_ "image/png"
 
 	"github.com/pdfcpu/pdfcpu/pkg/filter"
@@ -121,6 +122,31 @@ func createDCTImageObject(xRefTable *XRefTable, buf []byte, w, h int, cs string)
 	return sd, nil
 }
 
+// DefaultJPEGQuality is the quality factor used for JPEG encoding unless configured otherwise.
+const DefaultJPEGQuality = 85
+
+func jpegQuality(q int) int {
+	if q <= 0 {
+		return DefaultJPEGQuality
+	}
+	if q > 100 {
+		return 100
+	}
+	return q
+}
+
+// encodeJPEG JPEG encodes img using quality factor q.
+// Gray images stay gray, any other image gets encoded as YCbCr.
+func encodeJPEG(img image.Image, q int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality(q)}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 func writeRGBAImageBuf(img image.Image) []byte {
 	w := img.Bounds().Dx()
 	h := img.Bounds().Dy()
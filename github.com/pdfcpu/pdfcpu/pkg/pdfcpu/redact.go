@@ -0,0 +1,607 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pdfcpu/pdfcpu/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// redactor removes the content of a page beneath redaction areas given in user space.
+type redactor struct {
+	*textExtractor
+	areas   []Rectangle
+	quality int // JPEG quality of masked DCT images
+}
+
+// covers returns true if p lies within a redaction area.
+func (r *redactor) covers(p types.Point) bool {
+	for _, a := range r.areas {
+		if a.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps returns true if the bounding box of pp overlaps a redaction area.
+func (r *redactor) overlaps(pp ...types.Point) bool {
+	var b *Rectangle
+	for _, p := range pp {
+		b = unionRect(b, Rect(p.X, p.Y, p.X, p.Y))
+	}
+	if b == nil {
+		return false
+	}
+	for _, a := range r.areas {
+		if b.LL.X < a.UR.X && a.LL.X < b.UR.X && b.LL.Y < a.UR.Y && a.LL.Y < b.UR.Y {
+			return true
+		}
+	}
+	return false
+}
+
+// within returns true if all points of pp lie within the same redaction area.
+func (r *redactor) within(pp []types.Point) bool {
+	for _, a := range r.areas {
+		i := 0
+		for ; i < len(pp) && a.Contains(pp[i]); i++ {
+		}
+		if i == len(pp) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1000)/1000, 'f', -1, 64)
+}
+
+// redactText shows bb using tc and returns the elements of an equivalent TJ array
+// where covered glyphs are replaced by their displacement along with true if any glyph is covered.
+// A glyph is covered if its center lies within a redaction area.
+func (r *redactor) redactText(tc *textContent, bb []byte) (string, bool) {
+	gs := tc.gs
+	f := gs.font
+	if f == nil {
+		f = r.defaultFont
+	}
+
+	centers := []types.Point{}
+	r.glyph = func(s string, start, end, center types.Point, size float64) {
+		centers = append(centers, center)
+	}
+	r.showText(tc, bb)
+
+	var (
+		sb      strings.Builder
+		kept    []byte
+		adj     float64 // displacement of consecutive covered glyphs
+		covered bool
+	)
+
+	flush := func() {
+		if adj != 0 {
+			sb.WriteString(" " + redactNumber(adj))
+			adj = 0
+		}
+		if len(kept) > 0 {
+			sb.WriteString(" <" + hex.EncodeToString(kept) + ">")
+			kept = kept[:0]
+		}
+	}
+
+	for i := 0; len(bb) > 0; i++ {
+		c, n := f.nextCode(bb)
+		if i >= len(centers) || !r.covers(centers[i]) {
+			if adj != 0 {
+				flush()
+			}
+			kept = append(kept, bb[:n]...)
+			bb = bb[n:]
+			continue
+		}
+		covered = true
+		if len(kept) > 0 {
+			flush()
+		}
+		tx := f.width(c)/1000*gs.fontSize + gs.charSpace
+		if n == 1 && c == 32 {
+			tx += gs.wordSpace
+		}
+		if gs.fontSize != 0 {
+			adj -= tx * 1000 / gs.fontSize
+		}
+		bb = bb[n:]
+	}
+	flush()
+
+	return sb.String(), covered
+}
+
+// redactTextOperator returns the replacement for a Tj, ', " or TJ operator with covered glyphs removed
+// or "" if no glyph is covered.
+func (r *redactor) redactTextOperator(tc *textContent, op string, oo []Object) string {
+	if len(oo) == 0 {
+		return ""
+	}
+
+	gs := &tc.gs
+	var prefix string
+
+	if op == "\"" {
+		if ff, ok := numbers(oo[:len(oo)-1], 2); ok {
+			gs.wordSpace, gs.charSpace = ff[0], ff[1]
+			prefix = fmt.Sprintf(" %s Tw %s Tc", redactNumber(ff[0]), redactNumber(ff[1]))
+		}
+	}
+	if op == "'" || op == "\"" {
+		tc.nextLine(0, -gs.leading)
+		prefix += " T*"
+	}
+
+	var (
+		sb      strings.Builder
+		covered bool
+	)
+
+	switch o := oo[len(oo)-1].(type) {
+
+	case Array:
+		for _, o := range o {
+			if bb, ok := stringBytes(o); ok {
+				s, c := r.redactText(tc, bb)
+				sb.WriteString(s)
+				covered = covered || c
+				continue
+			}
+			if ff, ok := numbers([]Object{o}, 1); ok {
+				tx := -ff[0] / 1000 * gs.fontSize * gs.hScale
+				tc.tm = translationMatrix(tx, 0).multiply(tc.tm)
+				sb.WriteString(" " + redactNumber(ff[0]))
+			}
+		}
+
+	default:
+		bb, ok := stringBytes(o)
+		if !ok {
+			return ""
+		}
+		s, c := r.redactText(tc, bb)
+		sb.WriteString(s)
+		covered = c
+	}
+
+	if !covered {
+		return ""
+	}
+
+	return prefix + " [" + strings.TrimSpace(sb.String()) + "] TJ"
+}
+
+// replaceXObject replaces the XObject id of resDict by o or removes it for o == nil.
+// The XObject dict gets copied first since it may be shared with any other resource dict.
+// resDict itself is a private copy.
+func replaceXObject(resDict, d Dict, id string, o Object) {
+	d1 := d.Clone().(Dict)
+	if o == nil {
+		d1.Delete(id)
+	} else {
+		d1.Update(id, o)
+	}
+	resDict.Update("XObject", d1)
+}
+
+// maskImage returns a copy of the image sd with all pixels covered by redaction areas when drawn using ctm set to zero
+// or nil if the samples of sd can't be decoded.
+func (r *redactor) maskImage(sd *StreamDict, ctm matrix) (*StreamDict, error) {
+	w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+	if w == nil || h == nil || *w <= 0 || *h <= 0 {
+		return nil, nil
+	}
+
+	covered := func(x, y int) bool {
+		return r.covers(ctm.transform((float64(x)+.5)/float64(*w), 1-(float64(y)+.5)/float64(*h)))
+	}
+
+	sd1 := sd.Clone().(StreamDict)
+
+	if len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == filter.DCT {
+		img, err := jpeg.Decode(bytes.NewReader(sd.Raw))
+		if err != nil {
+			return nil, err
+		}
+		var m draw.Image
+		switch img := img.(type) {
+		case *image.Gray:
+			m = img
+		case *image.YCbCr:
+			rgba := image.NewRGBA(img.Bounds())
+			draw.Draw(rgba, rgba.Rect, img, img.Bounds().Min, draw.Src)
+			m = rgba
+		default:
+			return nil, nil
+		}
+		b := m.Bounds()
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if covered(x, y) {
+					m.Set(b.Min.X+x, b.Min.Y+y, color.Black)
+				}
+			}
+		}
+		bb, err := encodeJPEG(m, r.quality)
+		if err != nil {
+			return nil, err
+		}
+		sd1.Raw, sd1.Content = bb, nil
+		streamLength := int64(len(sd1.Raw))
+		sd1.StreamLength = &streamLength
+		sd1.Update("Length", Integer(streamLength))
+		return &sd1, nil
+	}
+
+	n, bpc := 1, 1
+	if im := sd.BooleanEntry("ImageMask"); im == nil || !*im {
+		if n, _ = r.xRefTable.sampleComponents(sd.Dict["ColorSpace"]); n == 0 {
+			return nil, nil
+		}
+		i := sd.IntEntry("BitsPerComponent")
+		if i == nil {
+			return nil, nil
+		}
+		bpc = *i
+	}
+
+	sd1.Content = nil
+	if err := sd1.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	stride := (*w*n*bpc + 7) / 8
+	if len(sd1.Content) < stride**h {
+		return nil, nil
+	}
+
+	// Samples may share their buffer with sd.
+	bb := append([]byte(nil), sd1.Content[:stride**h]...)
+	bits := n * bpc
+
+	for y := 0; y < *h; y++ {
+		row := bb[y*stride : (y+1)*stride]
+		for x := 0; x < *w; x++ {
+			if !covered(x, y) {
+				continue
+			}
+			for k := x * bits; k < (x+1)*bits; k++ {
+				row[k/8] &^= 0x80 >> uint(k%8)
+			}
+		}
+	}
+
+	sd1.Content = bb
+	sd1.FilterPipeline = []PDFFilter{{Name: filter.Flate, DecodeParms: nil}}
+	sd1.Update("Filter", Name(filter.Flate))
+	sd1.Delete("DecodeParms")
+
+	return &sd1, sd1.Encode()
+}
+
+// redactXObject returns the replacement for drawing the XObject id or "" if it remains unchanged
+// along with true if its drawing needs to be removed.
+// Images get replaced by masked copies, forms by copies with redacted content.
+// The replacement takes over the resource name of the original which thus becomes unreachable from this page.
+func (r *redactor) redactXObject(tc *textContent, id string) (string, bool, error) {
+	d, err := r.xRefTable.DereferenceDict(tc.resDict["XObject"])
+	if err != nil || d == nil {
+		return "", false, err
+	}
+
+	o, found := d.Find(id)
+	if !found {
+		return "", false, nil
+	}
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if r.forms[objNr] {
+			return "", false, nil
+		}
+		r.forms[objNr] = true
+		defer delete(r.forms, objNr)
+	}
+
+	sd, err := r.xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return "", false, err
+	}
+
+	var sd1 *StreamDict
+
+	switch st := sd.Subtype(); {
+
+	case st != nil && *st == "Image":
+		ctm := tc.gs.ctm
+		if !r.overlaps(ctm.transform(0, 0), ctm.transform(1, 0), ctm.transform(0, 1), ctm.transform(1, 1)) {
+			return "", false, nil
+		}
+		if sd1, err = r.maskImage(sd, ctm); err != nil {
+			return "", false, err
+		}
+		if sd1 == nil {
+			log.Debug.Printf("Redact: removing image %s\n", id)
+			replaceXObject(tc.resDict, d, id, nil)
+			return "", true, nil
+		}
+
+	case st != nil && *st == "Form":
+		if tc.depth >= maxTextFormDepth {
+			return "", false, nil
+		}
+		if err := sd.Decode(); err != nil {
+			return "", false, err
+		}
+		gs := tc.gs
+		if a, err := r.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+			ff := make([]float64, 6)
+			for i, o := range a {
+				ff[i] = r.xRefTable.number(o)
+			}
+			gs.ctm = matrixForNumbers(ff).multiply(gs.ctm)
+		}
+		resDict := tc.resDict
+		d1, err := r.xRefTable.DereferenceDict(sd.Dict["Resources"])
+		if err != nil {
+			return "", false, err
+		}
+		if d1 != nil {
+			resDict = d1.Clone().(Dict)
+		}
+		s, changed, err := r.redactContent(string(sd.Content), resDict, gs, tc.depth+1)
+		if err != nil || !changed {
+			return "", false, err
+		}
+		form := sd.Clone().(StreamDict)
+		form.Content = []byte(s)
+		if d1 != nil {
+			form.Update("Resources", resDict)
+		}
+		if err := form.Encode(); err != nil {
+			return "", false, err
+		}
+		sd1 = &form
+
+	default:
+		return "", false, nil
+	}
+
+	ir, err := r.xRefTable.IndRefForNewObject(*sd1)
+	if err != nil {
+		return "", false, err
+	}
+	replaceXObject(tc.resDict, d, id, *ir)
+
+	return fmt.Sprintf(" /%s Do", id), false, nil
+}
+
+// redactContent returns the content stream s using resDict with everything beneath redaction areas removed
+// along with true if s got modified.
+// Paths lying completely within a redaction area get removed unless used for clipping.
+// Inline images overlapping a redaction area get removed as a whole.
+func (r *redactor) redactContent(s string, resDict Dict, gs textGState, depth int) (string, bool, error) {
+	tc := &textContent{resDict: resDict, gs: gs, tm: identMatrix, tlm: identMatrix, depth: depth}
+
+	var (
+		b, path strings.Builder // content, pending path construction
+		clip    bool            // pending path is used for clipping
+		changed bool
+	)
+
+	oo := []Object{}
+	start := s
+
+	for {
+		o, op, err := nextTextToken(&s)
+		if err != nil {
+			return "", false, err
+		}
+
+		if o != nil {
+			oo = append(oo, o)
+			continue
+		}
+
+		if op == "" {
+			b.WriteString(path.String())
+			b.WriteString(start)
+			break
+		}
+
+		var (
+			repl string
+			drop bool
+		)
+
+		switch op {
+
+		case "m", "l", "c", "v", "y", "re", "h", "W", "W*":
+			r.processOperator(tc, op, oo)
+			path.WriteString(start[:len(start)-len(s)])
+			clip = clip || op == "W" || op == "W*"
+			start = s
+			oo = oo[:0]
+			continue
+
+		case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+			if !clip && len(tc.path) > 0 && r.within(tc.path) {
+				path.Reset()
+				drop = true
+			}
+			r.processOperator(tc, op, oo)
+
+		case "BI":
+			if err := skipBI(&s, NewPageResourceNames()); err != nil {
+				return "", false, err
+			}
+			ctm := tc.gs.ctm
+			drop = r.overlaps(ctm.transform(0, 0), ctm.transform(1, 0), ctm.transform(0, 1), ctm.transform(1, 1))
+
+		case "Tj", "'", "\"", "TJ":
+			repl = r.redactTextOperator(tc, op, oo)
+
+		case "Do":
+			if len(oo) > 0 {
+				if n, ok := oo[len(oo)-1].(Name); ok {
+					if repl, drop, err = r.redactXObject(tc, n.Value()); err != nil {
+						return "", false, err
+					}
+				}
+			}
+
+		default:
+			if err := r.processOperator(tc, op, oo); err != nil {
+				return "", false, err
+			}
+		}
+
+		b.WriteString(path.String())
+		path.Reset()
+		clip = false
+
+		switch {
+		case drop:
+			b.WriteString(" ")
+			changed = true
+		case repl != "":
+			b.WriteString(repl)
+			changed = true
+		default:
+			b.WriteString(start[:len(start)-len(s)])
+		}
+
+		start = s
+		oo = oo[:0]
+	}
+
+	return b.String(), changed, nil
+}
+
+func (r *redactor) redactPage(ctx *Context, pageNr int) error {
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: Redact: unknown page number: %d", pageNr)
+	}
+
+	// Redacted XObjects get replaced in a private copy of the page resources.
+	resDict := Dict{}
+	if inhPAttrs.resources != nil {
+		resDict = inhPAttrs.resources.Clone().(Dict)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != errNoContent {
+		return err
+	}
+
+	s, _, err := r.redactContent(string(bb), resDict, textGState{ctm: identMatrix, hScale: 1}, 0)
+	if err != nil {
+		return errors.Wrapf(err, "page %d", pageNr)
+	}
+	d.Update("Resources", resDict)
+
+	var sb strings.Builder
+	sb.WriteString("q\n" + s + "\nQ\nq 0 g\n")
+	for _, a := range r.areas {
+		fmt.Fprintf(&sb, "%.2f %.2f %.2f %.2f re f\n", a.LL.X, a.LL.Y, a.Width(), a.Height())
+	}
+	sb.WriteString("Q\n")
+
+	sd, _ := ctx.NewStreamDictForBuf([]byte(sb.String()))
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	d.Update("Contents", *ir)
+
+	return nil
+}
+
+// Redact removes the text, images and paths beneath the redaction areas of pages given in user space
+// and covers each area by an opaque black box.
+// Text showing operators partially covered by an area keep their uncovered glyphs at their original position.
+// Covered image pixels get blackened in a copy of the image, images that can't be decoded get removed as a whole.
+func Redact(ctx *Context, areas map[int][]Rectangle) error {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	pageNrs := []int{}
+	for i, aa := range areas {
+		if i < 1 || i > ctx.PageCount {
+			return errors.Errorf("pdfcpu: Redact: invalid page number: %d", i)
+		}
+		for _, a := range aa {
+			if a.Rectangle == nil || a.Width() <= 0 || a.Height() <= 0 {
+				return errors.Errorf("pdfcpu: Redact: page %d: invalid redaction area", i)
+			}
+		}
+		if len(aa) > 0 {
+			pageNrs = append(pageNrs, i)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	for _, i := range pageNrs {
+		te, err := newTextExtractor(ctx.XRefTable, nil)
+		if err != nil {
+			return err
+		}
+		te.path = func(pp []types.Point) {}
+		r := &redactor{textExtractor: te, areas: areas[i], quality: ctx.JPEGQuality}
+		if err := r.redactPage(ctx, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
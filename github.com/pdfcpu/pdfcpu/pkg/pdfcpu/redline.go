@@ -0,0 +1,391 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	redlineAddedColor  = []float64{1, 1, 0} // highlight color of added words
+	redlineMarkerColor = []float64{1, 0, 0} // color of the margin markers for changed lines
+)
+
+// redlineMarkerWidth is the width of the margin marker for a changed line.
+const redlineMarkerWidth = 4.
+
+// textWord is a word of positioned text.
+type textWord struct {
+	s string
+	r *Rectangle
+}
+
+// textLine is a line of positioned text.
+type textLine struct {
+	words []textWord
+	r     *Rectangle
+}
+
+func (l textLine) wordStrings() []string {
+	ss := make([]string, len(l.words))
+	for i, w := range l.words {
+		ss[i] = w.s
+	}
+	return ss
+}
+
+func (l textLine) String() string {
+	return strings.Join(l.wordStrings(), " ")
+}
+
+func unionRect(r1, r2 *Rectangle) *Rectangle {
+	if r1 == nil {
+		return r2
+	}
+	return Rect(
+		math.Min(r1.LL.X, r2.LL.X), math.Min(r1.LL.Y, r2.LL.Y),
+		math.Max(r1.UR.X, r2.UR.X), math.Max(r1.UR.Y, r2.UR.Y))
+}
+
+// textLineBuilder groups the glyphs of a page into lines of words.
+type textLineBuilder struct {
+	lines []textLine
+	word  strings.Builder // text of the current word
+	r     *Rectangle      // bounding box of the current word
+	last  types.Point     // end of the last glyph
+	size  float64         // font size of the last glyph
+}
+
+func (b *textLineBuilder) endWord() {
+	if b.word.Len() == 0 {
+		return
+	}
+	l := &b.lines[len(b.lines)-1]
+	l.words = append(l.words, textWord{s: b.word.String(), r: b.r})
+	l.r = unionRect(l.r, b.r)
+	b.word.Reset()
+	b.r = nil
+}
+
+func (b *textLineBuilder) glyph(s string, start, end, center types.Point, size float64) {
+	if s == "" {
+		b.last = end
+		return
+	}
+
+	sz := math.Max(size, b.size)
+
+	switch {
+
+	case len(b.lines) == 0 || math.Abs(start.Y-b.last.Y) > sz/2:
+		if len(b.lines) > 0 {
+			b.endWord()
+		}
+		b.lines = append(b.lines, textLine{})
+
+	case math.Hypot(start.X-b.last.X, start.Y-b.last.Y) > sz/4:
+		b.endWord()
+
+	}
+
+	b.last, b.size = end, size
+
+	if strings.TrimSpace(s) == "" {
+		b.endWord()
+		return
+	}
+
+	// Approximate the glyph box by the ascent and descent of a typical Latin font.
+	r := Rect(
+		math.Min(start.X, end.X), math.Min(start.Y, end.Y)-.2*size,
+		math.Max(start.X, end.X), math.Max(start.Y, end.Y)+.8*size)
+
+	b.word.WriteString(s)
+	b.r = unionRect(b.r, r)
+}
+
+func (b *textLineBuilder) textLines() []textLine {
+	if len(b.lines) > 0 {
+		b.endWord()
+	}
+	ll := []textLine{}
+	for _, l := range b.lines {
+		if len(l.words) > 0 {
+			ll = append(ll, l)
+		}
+	}
+	return ll
+}
+
+func (ctx *Context) pageTextLines(pageNr int) ([]textLine, error) {
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	b := &textLineBuilder{}
+
+	if err := ctx.processPageText(d, inhPAttrs.resources, b.glyph); err != nil {
+		return nil, err
+	}
+
+	return b.textLines(), nil
+}
+
+// commonSubsequence returns the index pairs of a longest common subsequence of ss1 and ss2.
+func commonSubsequence(ss1, ss2 []string) [][2]int {
+	n1, n2 := len(ss1), len(ss2)
+
+	// l[i][j] is the length of a longest common subsequence of ss1[i:] and ss2[j:].
+	l := make([][]int, n1+1)
+	for i := range l {
+		l[i] = make([]int, n2+1)
+	}
+	for i := n1 - 1; i >= 0; i-- {
+		for j := n2 - 1; j >= 0; j-- {
+			if ss1[i] == ss2[j] {
+				l[i][j] = l[i+1][j+1] + 1
+			} else if l[i+1][j] >= l[i][j+1] {
+				l[i][j] = l[i+1][j]
+			} else {
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+
+	pp := [][2]int{}
+	for i, j := 0, 0; i < n1 && j < n2; {
+		switch {
+		case ss1[i] == ss2[j]:
+			pp = append(pp, [2]int{i, j})
+			i++
+			j++
+		case l[i+1][j] >= l[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return pp
+}
+
+// addedWords returns the words of l not part of the old line ol.
+func addedWords(ol, l textLine) []textWord {
+	pp := commonSubsequence(ol.wordStrings(), l.wordStrings())
+	ww := []textWord{}
+	j := 0
+	for _, p := range append(pp, [2]int{len(ol.words), len(l.words)}) {
+		ww = append(ww, l.words[j:p[1]]...)
+		j = p[1] + 1
+	}
+	return ww
+}
+
+// redlineMarkup holds the changes of a page.
+type redlineMarkup struct {
+	added   [][]textWord // added words by line
+	changed []*Rectangle // bounding boxes of changed lines
+}
+
+// pageRedline diffs the lines of a page against the lines of the corresponding old page.
+// Lines are aligned first, then the words of each pair of unmatched lines get compared.
+func pageRedline(oldLines, lines []textLine) redlineMarkup {
+	var m redlineMarkup
+
+	ss1, ss2 := make([]string, len(oldLines)), make([]string, len(lines))
+	for i, l := range oldLines {
+		ss1[i] = l.String()
+	}
+	for i, l := range lines {
+		ss2[i] = l.String()
+	}
+
+	i, j := 0, 0
+	for _, p := range append(commonSubsequence(ss1, ss2), [2]int{len(oldLines), len(lines)}) {
+
+		// The unmatched lines oldLines[i:p[0]] have been replaced by lines[j:p[1]].
+		ll1, ll2 := oldLines[i:p[0]], lines[j:p[1]]
+
+		for k, l := range ll2 {
+			ww := l.words
+			if k < len(ll1) {
+				ww = addedWords(ll1[k], l)
+			}
+			if len(ww) > 0 {
+				m.added = append(m.added, ww)
+			}
+			m.changed = append(m.changed, l.r)
+		}
+
+		// Removed lines get marked at their former position.
+		for k := len(ll2); k < len(ll1); k++ {
+			m.changed = append(m.changed, ll1[k].r)
+		}
+
+		i, j = p[0]+1, p[1]+1
+	}
+
+	return m
+}
+
+func highlightAnnotation(ww []textWord) Dict {
+	var r *Rectangle
+	qp := Array{}
+	for _, w := range ww {
+		r = unionRect(r, w.r)
+		// Acrobat orders the points: upper left, upper right, lower left, lower right.
+		qp = append(qp, NewNumberArray(w.r.LL.X, w.r.UR.Y, w.r.UR.X, w.r.UR.Y, w.r.LL.X, w.r.LL.Y, w.r.UR.X, w.r.LL.Y)...)
+	}
+
+	return Dict(
+		map[string]Object{
+			"Type":       Name("Annot"),
+			"Subtype":    Name("Highlight"),
+			"Contents":   StringLiteral("Added"),
+			"Rect":       r.Array(),
+			"C":          NewNumberArray(redlineAddedColor...),
+			"QuadPoints": qp,
+		},
+	)
+}
+
+func markerAnnotation(r *Rectangle) Dict {
+	return Dict(
+		map[string]Object{
+			"Type":     Name("Annot"),
+			"Subtype":  Name("Square"),
+			"Contents": StringLiteral("Changed"),
+			"Rect":     r.Array(),
+			"Border":   NewIntegerArray(0, 0, 0),
+			"C":        NewNumberArray(redlineMarkerColor...),
+			"IC":       NewNumberArray(redlineMarkerColor...),
+		},
+	)
+}
+
+// addRedlineAnnotations adds highlight annotations for the added words
+// and square annotations marking the changed lines in the left margin of the page d.
+func (ctx *Context) addRedlineAnnotations(d Dict, marginX float64, m redlineMarkup) error {
+	ag := &appearanceGenerator{xRefTable: ctx.XRefTable}
+	annots := Array{}
+
+	for _, ww := range m.added {
+		ad := highlightAnnotation(ww)
+		if err := ag.textMarkupAppearance(ad, "Highlight"); err != nil {
+			return err
+		}
+		ir, err := ctx.IndRefForNewObject(ad)
+		if err != nil {
+			return err
+		}
+		annots = append(annots, *ir)
+	}
+
+	for _, r := range m.changed {
+		ad := markerAnnotation(Rect(marginX, r.LL.Y, marginX+redlineMarkerWidth, r.UR.Y))
+		if err := ag.shapeAppearance(ad, "Square"); err != nil {
+			return err
+		}
+		ir, err := ctx.IndRefForNewObject(ad)
+		if err != nil {
+			return err
+		}
+		annots = append(annots, *ir)
+	}
+
+	if len(annots) == 0 {
+		return nil
+	}
+
+	if o, found := d.Find("Annots"); found {
+		a, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		annots = append(a, annots...)
+	}
+
+	d.Update("Annots", annots)
+
+	return nil
+}
+
+// Redline marks up the text of ctx that changed relative to the text of old
+// and returns the number of changed lines.
+// The positioned text of each page gets diffed word by word against the corresponding page of old,
+// therefore both documents are expected to share their page layouts.
+// Added words are highlighted and changed lines including removed ones are marked in the left page margin.
+// Pages beyond the page count of old are considered to be added entirely.
+func (ctx *Context) Redline(old *Context) (int, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return 0, err
+	}
+	if err := old.EnsurePageCount(); err != nil {
+		return 0, err
+	}
+
+	var changed int
+
+	for i := 1; i <= ctx.PageCount; i++ {
+
+		oldLines := []textLine{}
+		if i <= old.PageCount {
+			ll, err := old.pageTextLines(i)
+			if err != nil {
+				return 0, err
+			}
+			oldLines = ll
+		}
+
+		lines, err := ctx.pageTextLines(i)
+		if err != nil {
+			return 0, err
+		}
+
+		m := pageRedline(oldLines, lines)
+		if len(m.changed) == 0 {
+			continue
+		}
+		changed += len(m.changed)
+
+		d, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			return 0, err
+		}
+
+		var marginX float64
+		if box := inhPAttrs.cropBox; box != nil {
+			marginX = box.LL.X
+		} else if box := inhPAttrs.mediaBox; box != nil {
+			marginX = box.LL.X
+		}
+
+		if err := ctx.addRedlineAnnotations(d, marginX+redlineMarkerWidth, m); err != nil {
+			return 0, err
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/filter"
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// ImageResampling represents the outcome of resampling images to a target resolution.
+type ImageResampling struct {
+	Images      int   // Number of resampled images.
+	BytesBefore int64 // Encoded size of the resampled images before resampling.
+	BytesAfter  int64 // Encoded size of the resampled images after resampling.
+}
+
+// Saved returns the number of bytes saved by resampling.
+func (ir ImageResampling) Saved() int64 {
+	return ir.BytesBefore - ir.BytesAfter
+}
+
+func (ir ImageResampling) String() string {
+	return fmt.Sprintf("resampled %d images: %d bytes -> %d bytes, saved %d bytes", ir.Images, ir.BytesBefore, ir.BytesAfter, ir.Saved())
+}
+
+// sampleComponents returns the number of color components of the color space o
+// and true for indexed color spaces whose samples are indices into a lookup table.
+func (xRefTable *XRefTable) sampleComponents(o Object) (int, bool) {
+	o, err := xRefTable.Dereference(o)
+	if err != nil {
+		return 0, false
+	}
+
+	switch o := o.(type) {
+
+	case Name:
+		switch o.Value() {
+		case DeviceGrayCS:
+			return 1, false
+		case DeviceRGBCS:
+			return 3, false
+		case DeviceCMYKCS:
+			return 4, false
+		}
+
+	case Array:
+		if len(o) < 2 {
+			return 0, false
+		}
+		n, ok := o[0].(Name)
+		if !ok {
+			return 0, false
+		}
+		switch n.Value() {
+		case CalGrayCS:
+			return 1, false
+		case CalRGBCS, LabCS:
+			return 3, false
+		case IndexedCS:
+			return 1, true
+		case ICCBasedCS:
+			sd, err := xRefTable.DereferenceStreamDict(o[1])
+			if err != nil || sd == nil {
+				return 0, false
+			}
+			if i := sd.IntEntry("N"); i != nil {
+				return *i, false
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// resampleSamples scales the 8 bit samples bb of a w x h image with n components per pixel down to nw x nh.
+// Continuous samples get averaged over the covered source area, indexed samples use the center pixel.
+func resampleSamples(bb []byte, w, h, n, nw, nh int, indexed bool) []byte {
+	rb := make([]byte, nw*nh*n)
+	sum := make([]int, n)
+
+	for y := 0; y < nh; y++ {
+		y0, y1 := y*h/nh, (y+1)*h/nh
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < nw; x++ {
+			x0, x1 := x*w/nw, (x+1)*w/nw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			d := rb[(y*nw+x)*n : (y*nw+x+1)*n]
+			if indexed {
+				i := ((y0+y1)/2*w + (x0+x1)/2) * n
+				copy(d, bb[i:i+n])
+				continue
+			}
+			for i := range sum {
+				sum[i] = 0
+			}
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := (sy*w + sx) * n
+					for j := range sum {
+						sum[j] += int(bb[i+j])
+					}
+				}
+			}
+			c := (y1 - y0) * (x1 - x0)
+			for j := range sum {
+				d[j] = uint8((sum[j] + c/2) / c)
+			}
+		}
+	}
+
+	return rb
+}
+
+// resampleJPEG scales a gray or RGB JPEG down to nw x nh and reencodes it as JPEG.
+func resampleJPEG(buf []byte, nw, nh, quality int) ([]byte, bool, error) {
+	img, err := jpeg.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, false, err
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	var dst image.Image
+
+	switch img := img.(type) {
+
+	case *image.Gray:
+		g := image.NewGray(image.Rect(0, 0, nw, nh))
+		pix := make([]byte, 0, w*h)
+		for y := 0; y < h; y++ {
+			pix = append(pix, img.Pix[y*img.Stride:y*img.Stride+w]...)
+		}
+		g.Pix = resampleSamples(pix, w, h, 1, nw, nh, false)
+		dst = g
+
+	case *image.YCbCr:
+		pix := make([]byte, 0, w*h*3)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				pix = append(pix, c.R, c.G, c.B)
+			}
+		}
+		pix = resampleSamples(pix, w, h, 3, nw, nh, false)
+		rgba := image.NewRGBA(image.Rect(0, 0, nw, nh))
+		for i := 0; i < nw*nh; i++ {
+			copy(rgba.Pix[i*4:], pix[i*3:i*3+3])
+			rgba.Pix[i*4+3] = 255
+		}
+		dst = rgba
+
+	default:
+		// CMYK JPEGs can't be encoded.
+		return nil, false, nil
+	}
+
+	bb, err := encodeJPEG(dst, quality)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return bb, true, nil
+}
+
+// resampleImage scales the image sd down to nw x nh keeping its color space and returns true if sd got modified.
+// DCT encoded images get reencoded as JPEG, any other image gets flate encoded.
+// Image masks, images with bit depths other than 8, color key masking and any other filters remain unchanged.
+func (xRefTable *XRefTable) resampleImage(sd *StreamDict, nw, nh, quality int) (bool, error) {
+
+	if im := sd.BooleanEntry("ImageMask"); im != nil && *im {
+		return false, nil
+	}
+
+	if bpc := sd.IntEntry("BitsPerComponent"); bpc == nil || *bpc != 8 {
+		return false, nil
+	}
+
+	if _, ok := sd.Dict["Mask"].(Array); ok {
+		return false, nil
+	}
+
+	n, indexed := xRefTable.sampleComponents(sd.Dict["ColorSpace"])
+	if n == 0 {
+		return false, nil
+	}
+
+	w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+	if w == nil || h == nil {
+		return false, nil
+	}
+
+	if len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == filter.DCT {
+		if n == 4 {
+			return false, nil
+		}
+		bb, ok, err := resampleJPEG(sd.Raw, nw, nh, quality)
+		if err != nil || !ok {
+			return false, err
+		}
+		sd.Raw, sd.Content = bb, nil
+		streamLength := int64(len(sd.Raw))
+		sd.StreamLength = &streamLength
+		sd.Update("Length", Integer(streamLength))
+		sd.Update("Width", Integer(nw))
+		sd.Update("Height", Integer(nh))
+		return true, nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if len(sd.Content) < *w**h*n {
+		return false, errors.Errorf("pdfcpu: resampleImage: corrupt image data: %d bytes", len(sd.Content))
+	}
+
+	sd.Content = resampleSamples(sd.Content, *w, *h, n, nw, nh, indexed)
+	sd.FilterPipeline = []PDFFilter{{Name: filter.Flate, DecodeParms: nil}}
+	sd.Update("Filter", Name(filter.Flate))
+	sd.Delete("DecodeParms")
+	sd.Update("Width", Integer(nw))
+	sd.Update("Height", Integer(nh))
+
+	return true, sd.Encode()
+}
+
+// ResampleImages downsamples all image XObjects exceeding targetDPI in any of their placements on the pages of ctx
+// to the lowest scale satisfying targetDPI for all placements.
+// Images at or below targetDPI remain untouched.
+func ResampleImages(ctx *Context, targetDPI int) (ImageResampling, error) {
+
+	var res ImageResampling
+
+	if targetDPI <= 0 {
+		return res, errors.Errorf("pdfcpu: ResampleImages: invalid target resolution: %d", targetDPI)
+	}
+
+	ii, err := ctx.ImageResolutions(nil, 0)
+	if err != nil {
+		return res, err
+	}
+
+	// The lowest effective resolution of each image determines its scale.
+	dpi := map[int]float64{}
+	for _, ip := range ii {
+		if ip.ObjNr == 0 {
+			continue
+		}
+		if d, ok := dpi[ip.ObjNr]; !ok || ip.DPI() < d {
+			dpi[ip.ObjNr] = ip.DPI()
+		}
+	}
+
+	objNrs := make([]int, 0, len(dpi))
+	for objNr := range dpi {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+
+		f := float64(targetDPI) / dpi[objNr]
+		if f >= 1 {
+			continue
+		}
+
+		entry, found := ctx.FindTableEntryLight(objNr)
+		if !found || entry.Free {
+			continue
+		}
+
+		sd, ok := entry.Object.(StreamDict)
+		if !ok {
+			continue
+		}
+
+		w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+		if w == nil || h == nil {
+			continue
+		}
+
+		nw := int(math.Max(1, math.Ceil(float64(*w)*f)))
+		nh := int(math.Max(1, math.Ceil(float64(*h)*f)))
+		if nw >= *w && nh >= *h {
+			continue
+		}
+
+		before := int64(len(sd.Raw))
+
+		changed, err := ctx.resampleImage(&sd, nw, nh, ctx.JPEGQuality)
+		if err != nil {
+			return res, errors.Wrapf(err, "pdfcpu: ResampleImages: obj#%d", objNr)
+		}
+		if !changed {
+			continue
+		}
+
+		log.Debug.Printf("ResampleImages: obj#%d: %dx%d -> %dx%d\n", objNr, *w, *h, nw, nh)
+
+		entry.Object = sd
+
+		res.Images++
+		res.BytesBefore += before
+		res.BytesAfter += int64(len(sd.Raw))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// PageResourceBundle is a self-contained bundle of the resources of a page.
+type PageResourceBundle struct {
+	Dict    Dict           // resource dict
+	Objects map[int]Object // objects referenced by Dict directly or indirectly, keyed by their source object number
+}
+
+// collectResourceObjects adds clones of all objects referenced by o to objs.
+// References to page tree nodes are not followed.
+func (xRefTable *XRefTable) collectResourceObjects(o Object, objs map[int]Object) error {
+
+	switch o := o.(type) {
+
+	case IndirectRef:
+		objNr := o.ObjectNumber.Value()
+		if _, ok := objs[objNr]; ok {
+			return nil
+		}
+
+		o1, err := xRefTable.Dereference(o)
+		if err != nil {
+			return err
+		}
+
+		if d, ok := o1.(Dict); ok && d.Type() != nil && (*d.Type() == "Page" || *d.Type() == "Pages") {
+			o1 = nil
+		}
+
+		if o1 != nil {
+			o1 = o1.Clone()
+		}
+		objs[objNr] = o1
+
+		return xRefTable.collectResourceObjects(o1, objs)
+
+	case Dict:
+		for _, v := range o {
+			if err := xRefTable.collectResourceObjects(v, objs); err != nil {
+				return err
+			}
+		}
+
+	case StreamDict:
+		return xRefTable.collectResourceObjects(o.Dict, objs)
+
+	case Array:
+		for _, v := range o {
+			if err := xRefTable.collectResourceObjects(v, objs); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// ExtractPageResources returns the resources of page pageNr including inherited resources
+// along with all objects they refer to, eg. fonts, XObjects and color spaces.
+func (ctx *Context) ExtractPageResources(pageNr int) (*PageResourceBundle, error) {
+
+	consolidateRes := false
+	d, inhPAttrs, err := ctx.PageDict(pageNr, consolidateRes)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	pr := &PageResourceBundle{Dict: NewDict(), Objects: map[int]Object{}}
+
+	if inhPAttrs.resources != nil {
+		pr.Dict = inhPAttrs.resources.Clone().(Dict)
+	}
+
+	if err := ctx.collectResourceObjects(pr.Dict, pr.Objects); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// AddPageResources adds copies of the objects of pr using new object numbers
+// and returns a resource dict referring to them ready for use by a page of ctx.
+// Each call adds a new set of copies.
+func (ctx *Context) AddPageResources(pr *PageResourceBundle) (Dict, error) {
+
+	objNrs := make([]int, 0, len(pr.Objects))
+	for objNr := range pr.Objects {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	lookup := map[int]int{}
+	oo := make([]Object, len(objNrs))
+
+	for i, objNr := range objNrs {
+		// Dropped references to page tree nodes become null objects.
+		var o Object
+		if o1 := pr.Objects[objNr]; o1 != nil {
+			o = o1.Clone()
+		}
+		objNrNew, err := ctx.InsertObject(o)
+		if err != nil {
+			return nil, err
+		}
+		lookup[objNr] = objNrNew
+		oo[i] = o
+	}
+
+	for _, o := range oo {
+		patchObject(o, lookup)
+	}
+
+	d := pr.Dict.Clone().(Dict)
+	patchDict(d, lookup)
+
+	return d, nil
+}
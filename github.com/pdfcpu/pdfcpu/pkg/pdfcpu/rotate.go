@@ -16,7 +16,15 @@ limitations under the License.
 
 package pdfcpu
 
-import "github.com/pdfcpu/pdfcpu/pkg/log"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
 
 func rotatePage(xRefTable *XRefTable, i, j int) error {
 
@@ -47,3 +55,268 @@ func RotatePages(ctx *Context, selectedPages IntSet, rotation int) error {
 
 	return nil
 }
+
+// RotatedPages returns the rotation in effect for all pages with a non zero rotation keyed by page number.
+// Rotations inherited from the page tree are taken into account and normalized to one of 90, 180 or 270.
+func RotatedPages(ctx *Context) (map[int]int, error) {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	m := map[int]int{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+
+		_, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if r := (inhPAttrs.rotate%360 + 360) % 360; r != 0 {
+			m[i] = r
+		}
+	}
+
+	return m, nil
+}
+
+// RotatedPagesList returns a list of all pages with a non zero rotation along with their rotation.
+func RotatedPagesList(ctx *Context) ([]string, error) {
+
+	m, err := RotatedPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := []int{}
+	for i := range m {
+		pages = append(pages, i)
+	}
+	sort.Ints(pages)
+
+	ss := []string{}
+	for _, i := range pages {
+		ss = append(ss, fmt.Sprintf("page %d: %d", i, m[i]))
+	}
+
+	return ss, nil
+}
+
+// rotationMatrix returns the transform mapping the user space of a page with media box mb displayed using rotation rot
+// into the user space of an unrotated page displaying the same with media box origin 0,0.
+func rotationMatrix(mb *Rectangle, rot int) matrix {
+
+	x0, y0, x1, y1 := mb.LL.X, mb.LL.Y, mb.UR.X, mb.UR.Y
+
+	switch rot {
+	case 90:
+		return matrixForNumbers([]float64{0, -1, 1, 0, -y0, x1})
+	case 180:
+		return matrixForNumbers([]float64{-1, 0, 0, -1, x1, y1})
+	case 270:
+		return matrixForNumbers([]float64{0, 1, -1, 0, y1, -x0})
+	}
+
+	return matrixForNumbers([]float64{1, 0, 0, 1, -x0, -y0})
+}
+
+// wrapPageContent prepends prefix to and appends suffix to the content of the page dict d.
+func (xRefTable *XRefTable) wrapPageContent(d Dict, prefix, suffix string) error {
+
+	o, found := d.Find("Contents")
+	if !found {
+		return nil
+	}
+
+	var a Array
+
+	switch o1 := o.(type) {
+	case IndirectRef:
+		o2, err := xRefTable.Dereference(o1)
+		if err != nil {
+			return err
+		}
+		if a1, ok := o2.(Array); ok {
+			a = append(a, a1...)
+		} else {
+			a = Array{o1}
+		}
+	case Array:
+		a = append(a, o1...)
+	default:
+		return errors.New("pdfcpu: wrapPageContent: corrupt page content")
+	}
+
+	ir := func(s string) (*IndirectRef, error) {
+		sd, _ := xRefTable.NewStreamDictForBuf([]byte(s))
+		if err := sd.Encode(); err != nil {
+			return nil, err
+		}
+		return xRefTable.IndRefForNewObject(*sd)
+	}
+
+	irPrefix, err := ir(prefix)
+	if err != nil {
+		return err
+	}
+
+	irSuffix, err := ir(suffix)
+	if err != nil {
+		return err
+	}
+
+	d.Update("Contents", append(append(Array{*irPrefix}, a...), *irSuffix))
+
+	return nil
+}
+
+// normalizeAnnotationRotation applies m baking rotation rot into the page content to the annotation dict d.
+func (xRefTable *XRefTable) normalizeAnnotationRotation(d Dict, m matrix, rot int, visited IntSet) error {
+
+	if f := d.IntEntry("F"); f != nil && *f&16 > 0 {
+		// NoRotate annotations stay upright pinned to their upper left corner, see 12.5.3
+		a, err := xRefTable.DereferenceArray(d["Rect"])
+		if err != nil || len(a) != 4 {
+			return err
+		}
+		r, err := rect(xRefTable, a)
+		if err != nil {
+			return err
+		}
+		p := m.transform(r.LL.X, r.UR.Y)
+		d.Update("Rect", NewNumberArray(p.X, p.Y-r.Height(), p.X+r.Width(), p.Y))
+		return nil
+	}
+
+	if err := xRefTable.transformAnnotation(d, m, visited); err != nil {
+		return err
+	}
+
+	// Keep the orientation of widget appearances generated by viewers, see 12.5.6.19
+	mk, err := xRefTable.DereferenceDict(d["MK"])
+	if err != nil || mk == nil {
+		return err
+	}
+
+	r := 0
+	if i := mk.IntEntry("R"); i != nil {
+		r = *i
+	}
+	if r = normalizedRotation(r - rot); r == 0 {
+		mk.Delete("R")
+	} else {
+		mk.Update("R", Integer(r))
+	}
+
+	return nil
+}
+
+func (ctx *Context) normalizePageRotation(pageNr int, visited IntSet) error {
+
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+
+	rot := normalizedRotation(inhPAttrs.rotate)
+	if rot%90 != 0 {
+		return errors.Errorf("pdfcpu: NormalizeRotation: page %d: invalid rotation: %d", pageNr, inhPAttrs.rotate)
+	}
+	if rot == 0 {
+		if inhPAttrs.rotate != 0 {
+			d.Update("Rotate", Integer(0))
+		}
+		return nil
+	}
+
+	log.Debug.Printf("NormalizeRotation page:%d rotation:%d\n", pageNr, rot)
+
+	mb := inhPAttrs.mediaBox
+	if mb == nil {
+		return errors.Errorf("pdfcpu: NormalizeRotation: page %d: missing media box", pageNr)
+	}
+
+	m := rotationMatrix(mb, rot)
+
+	ff := []string{}
+	for _, f := range []float64{m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1]} {
+		ff = append(ff, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	if err := ctx.wrapPageContent(d, "q "+strings.Join(ff, " ")+" cm\n", "\nQ"); err != nil {
+		return err
+	}
+
+	d.Update("MediaBox", transformedRect(mb, m).Array())
+	if inhPAttrs.cropBox != nil {
+		d.Update("CropBox", transformedRect(inhPAttrs.cropBox, m).Array())
+	}
+
+	for _, k := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+		o, found := d.Find(k)
+		if !found {
+			continue
+		}
+		a, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		if len(a) != 4 {
+			continue
+		}
+		r, err := rect(ctx.XRefTable, a)
+		if err != nil {
+			return err
+		}
+		d.Update(k, transformedRect(r, m).Array())
+	}
+
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range annots {
+		if ir, ok := o.(IndirectRef); ok {
+			if visited[ir.ObjectNumber.Value()] {
+				continue
+			}
+			visited[ir.ObjectNumber.Value()] = true
+		}
+		ad, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if ad == nil {
+			continue
+		}
+		if err := ctx.normalizeAnnotationRotation(ad, m, rot, visited); err != nil {
+			return err
+		}
+	}
+
+	// Overrides any rotation inherited from the page tree.
+	d.Update("Rotate", Integer(0))
+
+	return nil
+}
+
+// NormalizeRotation bakes the rotation of all pages into their content and sets their rotation to 0.
+// The content of a rotated page gets wrapped into a transformation and its page boxes get rotated accordingly,
+// so that each page displays as before. Annotations including form widgets get transformed along with the content.
+func NormalizeRotation(ctx *Context) error {
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	visited := IntSet{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if err := ctx.normalizePageRotation(i, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf16"
 
 	"github.com/pdfcpu/pdfcpu/internal/corefont/metrics"
@@ -70,6 +71,16 @@ const (
 	RMFillAndStroke
 )
 
+// ClipShape represents the shape an image watermark gets clipped to.
+type ClipShape int
+
+// Clip shapes
+const (
+	ClipNone ClipShape = iota
+	ClipRound
+	ClipCircle
+)
+
 var (
 	errNoContent   = errors.New("pdfcpu: page without content")
 	errNoWatermark = errors.Errorf("pdfcpu: no watermarks found - nothing removed")
@@ -116,10 +127,14 @@ var wmParamMap = watermarkParamMap{
 	"opacity":         parseOpacity,
 	"points":          parseFontSize,
 	"position":        parsePositionAnchorWM,
+	"radius":          parseClipRadius,
 	"rendermode":      parseRenderMode,
 	"rotation":        parseRotation,
 	"scalefactor":     parseScaleFactorWM,
+	"shape":           parseClipShape,
 	"strokecolor":     parseStrokeColor,
+	"tiled":           parseTiled,
+	"tilespacing":     parseTileSpacing,
 }
 
 type matrix [3][3]float64
@@ -212,6 +227,11 @@ type Watermark struct {
 	Scale             float64       // relative scale factor: 0 <= x <= 1, absolute scale factor: 0 <= x
 	ScaleAbs          bool          // true for absolute scaling.
 	Update            bool          // true for updating instead of adding a page watermark.
+	Clip              ClipShape     // clip shape for image watermarks.
+	ClipRadius        float64       // corner radius for ClipRound.
+	Tiled             bool          // repeat the watermark in a grid covering the page.
+	TileSpacing       float64       // gap between adjacent tiles.
+	PageNrOffset      int           // added to the page number substituted for %p.
 
 	// resources
 	ocg, extGState, font, image *IndirectRef
@@ -221,6 +241,7 @@ type Watermark struct {
 
 	// PDF watermark
 	pdfRes map[int]pdfResources
+	pdfCtx *Context // parsed stamp source, see LoadPDF.
 
 	// page specific
 	bb      *Rectangle   // bounding box of the form representing this watermark.
@@ -228,6 +249,11 @@ type Watermark struct {
 	pageRot float64      // page rotation in effect.
 	form    *IndirectRef // Forms are dependent on given page dimensions.
 
+	// document specific values for text placeholders.
+	pageCount int
+	fileName  string
+	date      time.Time
+
 	// house keeping
 	objs   IntSet    // objects for which wm has been applied already.
 	fCache formCache // form cache.
@@ -255,6 +281,38 @@ func DefaultWatermarkConfig() *Watermark {
 	}
 }
 
+// LoadPDF parses the source of a PDF watermark once
+// so wm may be applied to any number of documents without reading the source again.
+func (wm *Watermark) LoadPDF() error {
+
+	if !wm.isPDF() {
+		return errors.New("pdfcpu: LoadPDF: not a PDF watermark")
+	}
+
+	// The stamp pdf is assumed to be valid.
+	ctx, err := ReadFile(wm.FileName, NewDefaultConfiguration())
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	wm.pdfCtx = ctx
+
+	return nil
+}
+
+// resetResources releases any resources created for the document wm has been applied to before.
+func (wm *Watermark) resetResources() {
+	wm.ocg, wm.extGState, wm.font, wm.image = nil, nil, nil, nil
+	wm.pdfRes = map[int]pdfResources{}
+	wm.bb, wm.vp, wm.form = nil, nil, nil
+	wm.objs = IntSet{}
+	wm.fCache = formCache{}
+}
+
 func (wm Watermark) typ() string {
 	if wm.isImage() {
 		return "image"
@@ -346,6 +404,61 @@ func (wm Watermark) calcMaxTextWidth() float64 {
 	return maxWidth
 }
 
+// hasPlaceholders returns true if the watermark text contains placeholders expanded per page.
+func (wm Watermark) hasPlaceholders() bool {
+	return wm.isText() && expandPlaceholders(wm.TextString, 0, 0, "", time.Time{}) != wm.TextString
+}
+
+// expandPlaceholders replaces %p by the page number, %P by the page count, %d by the date,
+// %f by the file name and %% by a literal percent sign.
+// %0np zero pads the page number to n digits.
+// Any other % sequence is left untouched.
+func expandPlaceholders(s string, pageNr, pageCount int, fileName string, date time.Time) string {
+
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if s[i+1] == '0' {
+			j := i + 2
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			if j < len(s) && s[j] == 'p' {
+				w, _ := strconv.Atoi(s[i+1 : j])
+				fmt.Fprintf(&sb, "%0*d", w, pageNr)
+				i = j
+				continue
+			}
+		}
+		switch s[i+1] {
+		case 'p':
+			sb.WriteString(strconv.Itoa(pageNr))
+		case 'P':
+			sb.WriteString(strconv.Itoa(pageCount))
+		case 'd':
+			sb.WriteString(date.Format("2006-01-02"))
+		case 'f':
+			sb.WriteString(fileName)
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+	}
+
+	return sb.String()
+}
+
 func (wm Watermark) textDescriptor() TextDescriptor {
 	td := TextDescriptor{
 		Text:           wm.TextString,
@@ -755,6 +868,73 @@ func parseBorder(s string, wm *Watermark) error {
 	return err
 }
 
+func parseClipShape(s string, wm *Watermark) error {
+
+	// circle
+	// round
+	// round r
+
+	c := strings.Split(s, " ")
+	if len(c) == 0 || len(c) > 2 || len(c[0]) == 0 {
+		return errors.Errorf("pdfcpu: shape: need circle or round with optional radius, %s\n", s)
+	}
+
+	switch {
+
+	case strings.HasPrefix("circle", c[0]) && len(c) == 1:
+		wm.Clip = ClipCircle
+
+	case strings.HasPrefix("round", c[0]):
+		wm.Clip = ClipRound
+		if len(c) == 2 {
+			return parseClipRadius(c[1], wm)
+		}
+
+	default:
+		return errors.Errorf("pdfcpu: shape: need circle or round with optional radius, %s\n", s)
+	}
+
+	return nil
+}
+
+func parseClipRadius(s string, wm *Watermark) error {
+
+	r, err := strconv.ParseFloat(s, 64)
+	if err != nil || r <= 0 {
+		return errors.Errorf("pdfcpu: radius: need positive numeric value, %s\n", s)
+	}
+
+	wm.ClipRadius = r
+
+	return nil
+}
+
+func parseTiled(s string, wm *Watermark) error {
+
+	switch strings.ToLower(s) {
+	case "on", "true":
+		wm.Tiled = true
+	case "off", "false":
+		wm.Tiled = false
+	default:
+		return errors.New("pdfcpu: tiled, please provide one of: on/off true/false")
+	}
+
+	return nil
+}
+
+func parseTileSpacing(s string, wm *Watermark) error {
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return errors.Errorf("pdfcpu: tilespacing: need non negative numeric value, %s\n", s)
+	}
+
+	wm.TileSpacing = f
+
+	return nil
+}
+
 func parseWatermarkDetails(mode int, modeParm, s string, onTop bool) (*Watermark, error) {
 
 	wm := DefaultWatermarkConfig()
@@ -781,6 +961,14 @@ func parseWatermarkDetails(mode int, modeParm, s string, onTop bool) (*Watermark
 		}
 	}
 
+	if wm.ClipRadius > 0 && wm.Clip == ClipNone {
+		wm.Clip = ClipRound
+	}
+
+	if wm.Clip != ClipNone && mode != WMImage {
+		return nil, errors.New("pdfcpu: shape and radius are supported for image watermarks only")
+	}
+
 	return wm, setWatermarkType(mode, modeParm, wm)
 }
 
@@ -1110,9 +1298,10 @@ func ttfFontFile(xRefTable *XRefTable, fontName string) (*IndirectRef, error) {
 	return flateEncodedStreamIndRef(xRefTable, bb)
 }
 
-func ttfFontDescriptor(xRefTable *XRefTable, ttf font.TTFLight, fontName string) (*IndirectRef, error) {
+// ttfFontDescriptor returns a font descriptor for fontName embedding the font program of the installed TrueType font programName.
+func ttfFontDescriptor(xRefTable *XRefTable, ttf font.TTFLight, fontName, programName string) (*IndirectRef, error) {
 
-	fontFile, err := ttfFontFile(xRefTable, fontName)
+	fontFile, err := ttfFontFile(xRefTable, programName)
 	if err != nil {
 		return nil, err
 	}
@@ -1157,7 +1346,53 @@ func userFontDict(xRefTable *XRefTable, fontName string) (Dict, error) {
 	}
 	d.Insert("Widths", *w)
 
-	fd, err := ttfFontDescriptor(xRefTable, ttf, fontName)
+	fd, err := ttfFontDescriptor(xRefTable, ttf, fontName, fontName)
+	if err != nil {
+		return nil, err
+	}
+	d.Insert("FontDescriptor", *fd)
+
+	d.InsertName("Encoding", "WinAnsiEncoding")
+
+	return d, nil
+}
+
+func coreFontWidths(xRefTable *XRefTable, fontName string) (*IndirectRef, error) {
+	a := make(Array, 256-32)
+	for i := 32; i < 256; i++ {
+		a[i-32] = Integer(font.CharWidth(fontName, i))
+	}
+	return xRefTable.IndRefForNewObject(a)
+}
+
+// embeddedCoreFontDict returns a font dict for the standard font fontName embedding the font program of an installed TrueType font.
+// Glyph widths follow the metrics of the standard font so the layout does not depend on the font program.
+func embeddedCoreFontDict(xRefTable *XRefTable, fontName string, programs map[string]string) (Dict, error) {
+
+	programName, ok := programs[fontName]
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: no font program configured for embedding standard font %s", fontName)
+	}
+
+	ttf, ok := font.UserFontMetrics[programName]
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: font program %s for standard font %s is not installed", programName, fontName)
+	}
+
+	d := NewDict()
+	d.InsertName("Type", "Font")
+	d.InsertName("Subtype", "TrueType")
+	d.InsertName("BaseFont", fontName)
+	d.InsertInt("FirstChar", 32)
+	d.InsertInt("LastChar", 255)
+
+	w, err := coreFontWidths(xRefTable, fontName)
+	if err != nil {
+		return nil, err
+	}
+	d.Insert("Widths", *w)
+
+	fd, err := ttfFontDescriptor(xRefTable, ttf, fontName, programName)
 	if err != nil {
 		return nil, err
 	}
@@ -1368,7 +1603,7 @@ func type0FontDict(xRefTable *XRefTable, fontName string) (Dict, error) {
 	return d, nil
 }
 
-func createFontResForWM(xRefTable *XRefTable, wm *Watermark) error {
+func createFontResForWM(xRefTable *XRefTable, conf *Configuration, wm *Watermark) error {
 
 	var (
 		d   Dict
@@ -1376,7 +1611,14 @@ func createFontResForWM(xRefTable *XRefTable, wm *Watermark) error {
 	)
 
 	if font.IsCoreFont(wm.FontName) {
-		d = coreFontDict(wm.FontName)
+		if conf != nil && conf.EmbedStandardFonts {
+			d, err = embeddedCoreFontDict(xRefTable, wm.FontName, conf.StandardFontPrograms)
+			if err != nil {
+				return err
+			}
+		} else {
+			d = coreFontDict(wm.FontName)
+		}
 	} else {
 		//d, err = type0FontDict(xRefTable, wm.FontName)
 		d, err = userFontDict(xRefTable, wm.FontName)
@@ -1519,15 +1761,15 @@ func createPDFRes(ctx, otherCtx *Context, pageNr int, migrated map[int]int, wm *
 
 func createPDFResForWM(ctx *Context, wm *Watermark) error {
 
-	// The stamp pdf is assumed to be valid.
-	otherCtx, err := ReadFile(wm.FileName, NewDefaultConfiguration())
-	if err != nil {
-		return err
+	if wm.pdfCtx == nil {
+		if err := wm.LoadPDF(); err != nil {
+			return err
+		}
+		// Release the stamp source after use unless cached by the caller.
+		defer func() { wm.pdfCtx = nil }()
 	}
 
-	if err := otherCtx.EnsurePageCount(); err != nil {
-		return nil
-	}
+	otherCtx := wm.pdfCtx
 
 	migrated := map[int]int{}
 
@@ -1623,7 +1865,7 @@ func createResourcesForWM(ctx *Context, wm *Watermark) error {
 		return createImageResForWM(xRefTable, wm)
 	}
 
-	return createFontResForWM(xRefTable, wm)
+	return createFontResForWM(xRefTable, ctx.Configuration, wm)
 }
 
 func ensureOCG(xRefTable *XRefTable, wm *Watermark) error {
@@ -1798,8 +2040,37 @@ func pdfFormContent(w io.Writer, pageNr int, wm *Watermark) error {
 	return err
 }
 
+// clipPath sets up the clipping path for an image watermark in form space.
+// Any rotation and scaling gets applied by the form matrix.
+func clipPath(w io.Writer, wm *Watermark) {
+
+	bb := RectForDim(wm.bb.Width(), wm.bb.Height())
+	d := math.Min(bb.Width(), bb.Height())
+
+	switch wm.Clip {
+
+	case ClipCircle:
+		x, y := (bb.Width()-d)/2, (bb.Height()-d)/2
+		ellipsePath(w, RectForWidthAndHeight(x, y, d, d))
+
+	case ClipRound:
+		r := wm.ClipRadius
+		if r == 0 {
+			r = d / 10
+		}
+		roundedRectPath(w, bb, r)
+
+	default:
+		return
+	}
+
+	fmt.Fprint(w, "W n ")
+}
+
 func imageFormContent(w io.Writer, wm *Watermark) {
-	fmt.Fprintf(w, "q %f 0 0 %f 0 0 cm /Im0 Do Q", wm.bb.Width(), wm.bb.Height()) // TODO dont need Q
+	fmt.Fprint(w, "q ")
+	clipPath(w, wm)
+	fmt.Fprintf(w, "%f 0 0 %f 0 0 cm /Im0 Do Q", wm.bb.Width(), wm.bb.Height()) // TODO dont need Q
 }
 
 func formContent(w io.Writer, pageNr int, wm *Watermark) error {
@@ -1812,7 +2083,7 @@ func formContent(w io.Writer, pageNr int, wm *Watermark) error {
 	return nil
 }
 
-func setupTextDescriptor(wm *Watermark) TextDescriptor {
+func setupTextDescriptor(wm *Watermark, pageNr int) TextDescriptor {
 
 	// Set horizontal alignment.
 	var hAlign HAlignment
@@ -1828,6 +2099,7 @@ func setupTextDescriptor(wm *Watermark) TextDescriptor {
 	x, y, _, vAlign := anchorPosAndAlign(BottomLeft, wm.vp)
 	td := wm.textDescriptor()
 	td.X, td.Y, td.HAlign, td.VAlign, td.FontKey = x, y, hAlign, vAlign, "F1"
+	td.Text = expandPlaceholders(td.Text, pageNr+wm.PageNrOffset, wm.pageCount, wm.fileName, wm.date)
 
 	// Set margins.
 	td.MLeft = float64(wm.MLeft)
@@ -1871,7 +2143,7 @@ func createForm(xRefTable *XRefTable, pageNr int, wm *Watermark, withBB bool) er
 	if wm.isImage() || wm.isPDF() {
 		wm.calcBoundingBox(pageNr)
 	} else {
-		td := setupTextDescriptor(wm)
+		td := setupTextDescriptor(wm, pageNr)
 		// Render td into b and return the bounding box.
 		wm.bb = WriteMultiLine(&b, wm.vp, nil, td)
 	}
@@ -1879,7 +2151,7 @@ func createForm(xRefTable *XRefTable, pageNr int, wm *Watermark, withBB bool) er
 	// The forms bounding box is dependent on the page dimensions.
 	bb := wm.bb
 
-	if cachedForm(wm) || pageNr > len(wm.pdfRes) {
+	if !wm.hasPlaceholders() && (cachedForm(wm) || pageNr > len(wm.pdfRes)) {
 		// Use cached form.
 		ir, ok := wm.fCache[*bb.Rectangle]
 		if ok {
@@ -1932,7 +2204,7 @@ func createForm(xRefTable *XRefTable, pageNr int, wm *Watermark, withBB bool) er
 
 	wm.form = ir
 
-	if cachedForm(wm) || pageNr >= len(wm.pdfRes) {
+	if !wm.hasPlaceholders() && (cachedForm(wm) || pageNr >= len(wm.pdfRes)) {
 		// Cache form.
 		wm.fCache[*wm.bb.Rectangle] = ir
 	}
@@ -2007,21 +2279,96 @@ func updatePageResourcesForWM(xRefTable *XRefTable, resDict Dict, wm *Watermark,
 	return nil
 }
 
-func wmContent(wm *Watermark, gsID, xoID string) []byte {
+// maxWatermarkTiles limits the number of tiles of a tiled watermark per page.
+const maxWatermarkTiles = 10000
+
+// tileRange returns the range of tile indices along one axis of the watermark's coordinate system
+// needed to cover the interval [min,max] using tiles of extent step.
+func tileRange(min, max, step float64) (int, int, error) {
+	if step <= 0 {
+		return 0, 0, errors.Errorf("pdfcpu: tiled watermark: invalid tile extent %.2f", step)
+	}
+	if (max-min)/step > maxWatermarkTiles {
+		return 0, 0, errors.Errorf("pdfcpu: tiled watermark: more than %d tiles, please increase scale or tilespacing", maxWatermarkTiles)
+	}
+	return int(math.Floor(min/step)) - 1, int(math.Ceil(max/step)) + 1, nil
+}
+
+// tiledWMContent repeats the watermark in a grid aligned with its rotation covering the viewport.
+// Tiles are clipped to the viewport.
+func tiledWMContent(wm *Watermark, m *matrix, gsID, xoID string) ([]byte, error) {
+
+	vp := wm.vp
+
+	// Map the viewport corners into the coordinate system of the watermark.
+	det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	minX, minY := math.MaxFloat64, math.MaxFloat64
+	maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+	for _, p := range []types.Point{vp.LL, {X: vp.UR.X, Y: vp.LL.Y}, vp.UR, {X: vp.LL.X, Y: vp.UR.Y}} {
+		dx, dy := p.X-m[2][0], p.Y-m[2][1]
+		x := (dx*m[1][1] - dy*m[1][0]) / det
+		y := (dy*m[0][0] - dx*m[0][1]) / det
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+
+	stepX := wm.bb.Width() + wm.TileSpacing
+	stepY := wm.bb.Height() + wm.TileSpacing
+	k0, k1, err := tileRange(minX, maxX, stepX)
+	if err != nil {
+		return nil, err
+	}
+	l0, l1, err := tileRange(minY, maxY, stepY)
+	if err != nil {
+		return nil, err
+	}
+	if (k1-k0+1)*(l1-l0+1) > maxWatermarkTiles {
+		return nil, errors.Errorf("pdfcpu: tiled watermark: more than %d tiles, please increase scale or tilespacing", maxWatermarkTiles)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, " /Artifact <</Subtype /Watermark /Type /Pagination >>BDC q %.2f %.2f %.2f %.2f re W n /%s gs",
+		vp.LL.X, vp.LL.Y, vp.Width(), vp.Height(), gsID)
+
+	for l := l0; l <= l1; l++ {
+		for k := k0; k <= k1; k++ {
+			t := identMatrix
+			t[2][0] = float64(k) * stepX
+			t[2][1] = float64(l) * stepY
+			mt := t.multiply(*m)
+			fmt.Fprintf(&b, " q %.2f %.2f %.2f %.2f %.2f %.2f cm /%s Do Q", mt[0][0], mt[0][1], mt[1][0], mt[1][1], mt[2][0], mt[2][1], xoID)
+		}
+	}
+
+	b.WriteString(" Q EMC ")
+
+	return b.Bytes(), nil
+}
+
+func wmContent(wm *Watermark, gsID, xoID string) ([]byte, error) {
 
 	m := wm.calcTransformMatrix()
 
+	if wm.Tiled {
+		return tiledWMContent(wm, m, gsID, xoID)
+	}
+
 	insertOCG := " /Artifact <</Subtype /Watermark /Type /Pagination >>BDC q %.2f %.2f %.2f %.2f %.2f %.2f cm /%s gs /%s Do Q EMC "
 
 	var b bytes.Buffer
 	fmt.Fprintf(&b, insertOCG, m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1], gsID, xoID)
 
-	return b.Bytes()
+	return b.Bytes(), nil
 }
 
 func insertPageContentsForWM(xRefTable *XRefTable, pageDict Dict, wm *Watermark, gsID, xoID string) error {
 
-	sd, _ := xRefTable.NewStreamDictForBuf(wmContent(wm, gsID, xoID))
+	bb, err := wmContent(wm, gsID, xoID)
+	if err != nil {
+		return err
+	}
+
+	sd, _ := xRefTable.NewStreamDictForBuf(bb)
 	if err := sd.Encode(); err != nil {
 		return err
 	}
@@ -2198,7 +2545,10 @@ func patchContentForWM(sd *StreamDict, gsID, xoID string, wm *Watermark, saveGSt
 		return err
 	}
 
-	bb := wmContent(wm, gsID, xoID)
+	bb, err := wmContent(wm, gsID, xoID)
+	if err != nil {
+		return err
+	}
 
 	if wm.OnTop {
 		if saveGState {
@@ -2236,6 +2586,16 @@ func AddWatermarks(ctx *Context, selectedPages IntSet, wm *Watermark) error {
 
 	xRefTable := ctx.XRefTable
 
+	// wm may have been applied to another document before.
+	wm.resetResources()
+
+	wm.pageCount = ctx.PageCount
+	wm.fileName = ""
+	if ctx.Read.FileName != "" {
+		wm.fileName = filepath.Base(ctx.Read.FileName)
+	}
+	wm.date = time.Now()
+
 	if err := prepareOCPropertiesInRoot(ctx, wm); err != nil {
 		return err
 	}
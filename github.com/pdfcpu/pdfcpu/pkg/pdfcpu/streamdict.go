@@ -18,6 +18,7 @@ package pdfcpu
 
 import (
 	"bytes"
+	"compress/zlib"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -44,6 +45,13 @@ type StreamDict struct {
 	Raw               []byte // Encoded
 	Content           []byte // Decoded
 	IsPageContent     bool
+	source            *streamSource // Unencoded data getting encoded while writing unless Raw is set
+}
+
+// streamSource provides the unencoded data of a stream starting at offset.
+type streamSource struct {
+	io.ReadSeeker
+	offset int64
 }
 
 // NewStreamDict creates a new PDFStreamDict for given PDFDict, stream offset and length.
@@ -57,6 +65,7 @@ func NewStreamDict(d Dict, streamOffset int64, streamLength *int64, streamLength
 		nil,
 		nil,
 		false,
+		nil,
 	}
 }
 
@@ -68,7 +77,7 @@ func (sd StreamDict) Clone() Object {
 	for k, v := range sd.FilterPipeline {
 		f := PDFFilter{}
 		f.Name = v.Name
-		if f.DecodeParms != nil {
+		if v.DecodeParms != nil {
 			f.DecodeParms = v.DecodeParms.Clone().(Dict)
 		}
 		pl[k] = f
@@ -105,6 +114,12 @@ func NewObjectStreamDict() *ObjectStreamDict {
 	return &ObjectStreamDict{StreamDict: sd}
 }
 
+// setFilter replaces sd's filter pipeline by the single filter named filterName.
+func (sd *StreamDict) setFilter(filterName string) {
+	sd.Update("Filter", Name(filterName))
+	sd.FilterPipeline = []PDFFilter{{Name: filterName, DecodeParms: nil}}
+}
+
 func parmsForFilter(d Dict) map[string]int {
 	m := map[string]int{}
 
@@ -185,6 +200,47 @@ func (sd *StreamDict) Encode() error {
 	return nil
 }
 
+// encodeData writes the data of r to w encoded by the filter pipeline of sd which is either Flate or empty
+// and returns the number of bytes written.
+func (sd StreamDict) encodeData(w io.Writer, r io.Reader) (int64, error) {
+	var n ByteCounter
+	w = io.MultiWriter(w, &n)
+
+	if !sd.HasSoleFilterNamed(filter.Flate) {
+		_, err := io.Copy(w, r)
+		return int64(n), err
+	}
+
+	zw := zlib.NewWriter(w)
+	if _, err := io.Copy(zw, r); err != nil {
+		return 0, err
+	}
+	err := zw.Close()
+
+	return int64(n), err
+}
+
+// writeSource writes the encoded data of sd's source to w and returns the number of bytes written.
+func (sd StreamDict) writeSource(w io.Writer) (int64, error) {
+	if _, err := sd.source.Seek(sd.source.offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return sd.encodeData(w, sd.source)
+}
+
+// loadRaw sets sd.Raw to the encoded data of sd's source if not already set.
+func (sd *StreamDict) loadRaw() error {
+	if sd.Raw != nil || sd.source == nil {
+		return nil
+	}
+	var b bytes.Buffer
+	if _, err := sd.writeSource(&b); err != nil {
+		return err
+	}
+	sd.Raw = b.Bytes()
+	return nil
+}
+
 // Decode applies sd's filter pipeline to sd.Raw in order to produce sd.Content.
 func (sd *StreamDict) Decode() error {
 	if sd.Content != nil {
@@ -192,6 +248,10 @@ func (sd *StreamDict) Decode() error {
 		return nil
 	}
 
+	if err := sd.loadRaw(); err != nil {
+		return err
+	}
+
 	// No filter specified, nothing to decode.
 	if sd.FilterPipeline == nil {
 		sd.Content = sd.Raw
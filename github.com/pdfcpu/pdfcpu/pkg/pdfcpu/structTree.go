@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errNoStructTree = errors.New("pdfcpu: missing structure tree - please tag this document first")
+
+// StructElement represents a structure element of the logical structure of a tagged PDF, see 14.7.2
+type StructElement struct {
+	Index      int    // Position in depth first order starting at 1, identifies the element.
+	Type       string // Structure type eg. P, H1, Table, Figure
+	Page       int    // Page the content of this element gets rendered on or 0.
+	MCIDs      []int  // Marked-content identifiers of directly referenced marked-content sequences.
+	Alt        string // Alternate description
+	ActualText string
+	Kids       []*StructElement
+	dict       Dict
+}
+
+// mcidRanges returns a compact representation of se.MCIDs eg. 0-3,5
+func (se StructElement) mcidRanges() string {
+	ss := []string{}
+	for i := 0; i < len(se.MCIDs); {
+		j := i
+		for j+1 < len(se.MCIDs) && se.MCIDs[j+1] == se.MCIDs[j]+1 {
+			j++
+		}
+		if j > i {
+			ss = append(ss, fmt.Sprintf("%d-%d", se.MCIDs[i], se.MCIDs[j]))
+		} else {
+			ss = append(ss, fmt.Sprintf("%d", se.MCIDs[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ss, ",")
+}
+
+func (se StructElement) String() string {
+	s := fmt.Sprintf("#%d %s", se.Index, se.Type)
+	if se.Page > 0 {
+		s += fmt.Sprintf(" page:%d", se.Page)
+	}
+	if len(se.MCIDs) > 0 {
+		s += " mcid:" + se.mcidRanges()
+	}
+	if se.Alt != "" {
+		s += fmt.Sprintf(" alt:%q", se.Alt)
+	}
+	if se.ActualText != "" {
+		s += fmt.Sprintf(" actualText:%q", se.ActualText)
+	}
+	return s
+}
+
+type structTreeWalker struct {
+	xRefTable *XRefTable
+	pageNrs   map[int]int // page dict obj# => page number
+	visited   IntSet
+	count     int
+}
+
+func (w *structTreeWalker) pageNr(o Object) (int, error) {
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		return 0, nil
+	}
+	objNr := ir.ObjectNumber.Value()
+	if pageNr, ok := w.pageNrs[objNr]; ok {
+		return pageNr, nil
+	}
+	pageNr, err := w.xRefTable.PageNumber(objNr)
+	if err != nil {
+		return 0, err
+	}
+	w.pageNrs[objNr] = pageNr
+	return pageNr, nil
+}
+
+func (w *structTreeWalker) text(d Dict, key string) (string, error) {
+	o, found := d.Find(key)
+	if !found {
+		return "", nil
+	}
+	return w.xRefTable.DereferenceText(o)
+}
+
+// processKid processes an entry of the K array of se, see 14.7.2 Table 323
+func (w *structTreeWalker) processKid(se *StructElement, o Object) error {
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if w.visited[objNr] {
+			return nil
+		}
+		w.visited[objNr] = true
+	}
+
+	o, err := w.xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return err
+	}
+
+	switch o := o.(type) {
+
+	case Integer:
+		// Marked-content sequence of se.Page
+		se.MCIDs = append(se.MCIDs, o.Value())
+
+	case Dict:
+		t := o.Type()
+		if t != nil && *t == "MCR" {
+			// Marked-content reference, see 14.7.4.2
+			if mcid := o.IntEntry("MCID"); mcid != nil {
+				se.MCIDs = append(se.MCIDs, *mcid)
+			}
+			return nil
+		}
+		if t != nil && *t == "OBJR" {
+			// Object references do not contribute to the logical structure.
+			return nil
+		}
+		kid, err := w.structElement(o, se.Page)
+		if err != nil {
+			return err
+		}
+		se.Kids = append(se.Kids, kid)
+
+	case Array:
+		for _, o := range o {
+			if err := w.processKid(se, o); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (w *structTreeWalker) structElement(d Dict, parentPageNr int) (*StructElement, error) {
+
+	w.count++
+
+	se := &StructElement{Index: w.count, Page: parentPageNr, dict: d}
+
+	if s := d.NameEntry("S"); s != nil {
+		se.Type = *s
+	}
+
+	if o, found := d.Find("Pg"); found {
+		pageNr, err := w.pageNr(o)
+		if err != nil {
+			return nil, err
+		}
+		se.Page = pageNr
+	}
+
+	var err error
+
+	if se.Alt, err = w.text(d, "Alt"); err != nil {
+		return nil, err
+	}
+
+	if se.ActualText, err = w.text(d, "ActualText"); err != nil {
+		return nil, err
+	}
+
+	if o, found := d.Find("K"); found {
+		if err = w.processKid(se, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return se, nil
+}
+
+// StructureTree returns the structure elements at the top level of the logical structure of a tagged PDF, see 14.7
+func (ctx *Context) StructureTree() ([]*StructElement, error) {
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := rootDict.Find("StructTreeRoot")
+	if !found {
+		return nil, errNoStructTree
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errNoStructTree
+	}
+
+	w := &structTreeWalker{xRefTable: ctx.XRefTable, pageNrs: map[int]int{}, visited: IntSet{}}
+
+	// The structure tree root acts as parent of the top level elements.
+	root := &StructElement{}
+	if o, found := d.Find("K"); found {
+		if err = w.processKid(root, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return root.Kids, nil
+}
+
+// textString returns s as PDF text string using UTF16BE for anything beyond ASCII, see 7.9.2.2
+func textString(s string) Object {
+	for _, r := range s {
+		if r > 0x7F {
+			return NewHexLiteral([]byte(EncodeUTF16String(s)))
+		}
+	}
+	s1, _ := Escape(s)
+	return StringLiteral(*s1)
+}
+
+func indexStructElements(ee []*StructElement, m map[int]*StructElement) {
+	for _, e := range ee {
+		m[e.Index] = e
+		indexStructElements(e.Kids, m)
+	}
+}
+
+// SetAltTexts sets the alternate descriptions of the structure elements identified by the keys of m.
+// Keys are structure element indices as returned by StructureTree and typically refer to Figure elements.
+func (ctx *Context) SetAltTexts(m map[int]string) error {
+
+	ee, err := ctx.StructureTree()
+	if err != nil {
+		return err
+	}
+
+	byIndex := map[int]*StructElement{}
+	indexStructElements(ee, byIndex)
+
+	for i, alt := range m {
+		se, ok := byIndex[i]
+		if !ok {
+			return errors.Errorf("pdfcpu: unknown structure element: #%d", i)
+		}
+		se.dict.Update("Alt", textString(alt))
+	}
+
+	return nil
+}
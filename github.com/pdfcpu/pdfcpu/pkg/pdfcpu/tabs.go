@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// The tab orders for annotations on a page, see 12.5 Table 30
+var tabOrders = map[string]string{
+	"R": "row",
+	"C": "column",
+	"S": "structure",
+	"A": "annotations",
+	"W": "widgets",
+}
+
+// widgetRanks returns the position of each widget of the fields named by fieldNames keyed by object number.
+func (xRefTable *XRefTable) widgetRanks(fieldNames []string) (map[int]int, error) {
+
+	ff, err := xRefTable.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]*formField{}
+	for _, f := range ff {
+		m[f.Name] = f
+	}
+
+	ranks := map[int]int{}
+	for _, fieldName := range fieldNames {
+		f, ok := m[fieldName]
+		if !ok {
+			return nil, errors.Errorf("pdfcpu: unknown form field: %s", fieldName)
+		}
+		for _, ir := range f.Refs {
+			if ir == nil {
+				continue
+			}
+			if _, ok := ranks[ir.ObjectNumber.Value()]; !ok {
+				ranks[ir.ObjectNumber.Value()] = len(ranks)
+			}
+		}
+	}
+
+	return ranks, nil
+}
+
+// orderAnnots moves the annotations of a ranked by ranks to the front in ranked order.
+// All other annotations follow in their original order.
+func orderAnnots(a Array, ranks map[int]int) {
+
+	rank := func(o Object) int {
+		if ir, ok := o.(IndirectRef); ok {
+			if r, ok := ranks[ir.ObjectNumber.Value()]; ok {
+				return r
+			}
+		}
+		return len(ranks)
+	}
+
+	sort.SliceStable(a, func(i, j int) bool { return rank(a[i]) < rank(a[j]) })
+}
+
+// SetTabOrder sets the tab order of all selected pages to one of R, C, S, A or W.
+// For annotation and widget order fieldNames optionally lists fully qualified field names in tab order.
+// The widgets of these fields move to the front of the page annotations in this order.
+// For row, column and structure order the page annotations remain untouched.
+func SetTabOrder(ctx *Context, selectedPages IntSet, order string, fieldNames []string) error {
+
+	if _, ok := tabOrders[order]; !ok {
+		return errors.Errorf("pdfcpu: unknown tab order: %s", order)
+	}
+
+	var ranks map[int]int
+
+	if len(fieldNames) > 0 {
+		if order != "A" && order != "W" {
+			return errors.Errorf("pdfcpu: field order requires tab order A or W, got: %s", order)
+		}
+		var err error
+		if ranks, err = ctx.widgetRanks(fieldNames); err != nil {
+			return err
+		}
+	}
+
+	for i, v := range selectedPages {
+		if !v {
+			continue
+		}
+
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return err
+		}
+
+		d.Update("Tabs", Name(order))
+
+		if ranks == nil {
+			continue
+		}
+
+		o, found := d.Find("Annots")
+		if !found {
+			continue
+		}
+
+		a, err := ctx.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+
+		orderAnnots(a, ranks)
+	}
+
+	return nil
+}
+
+// TabOrderList returns the tab order of all selected pages.
+func TabOrderList(ctx *Context, selectedPages IntSet) ([]string, error) {
+
+	pages := []int{}
+	for i, v := range selectedPages {
+		if v {
+			pages = append(pages, i)
+		}
+	}
+	sort.Ints(pages)
+
+	ss := []string{}
+
+	for _, i := range pages {
+
+		d, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+
+		s := "none"
+		if n := d.NameEntry("Tabs"); n != nil {
+			s = *n
+			if desc, ok := tabOrders[*n]; ok {
+				s = fmt.Sprintf("%s (%s)", *n, desc)
+			}
+		}
+
+		ss = append(ss, fmt.Sprintf("page %d: %s", i, s))
+	}
+
+	return ss, nil
+}
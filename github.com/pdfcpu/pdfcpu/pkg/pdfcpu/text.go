@@ -0,0 +1,883 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// maxTextFormDepth limits the nesting of form XObjects taken into account for text extraction.
+const maxTextFormDepth = 16
+
+// TextRun represents consecutive text of a page covered by the same link annotation or by none.
+type TextRun struct {
+	Page     int    // page number
+	Text     string // text in content stream order
+	URI      string // target of a covering URI link
+	Dest     string // named destination of a covering link
+	DestPage int    // target page of a covering link or 0
+}
+
+// Linked returns true if tr is covered by a link annotation with a known target.
+func (tr TextRun) Linked() bool {
+	return tr.URI != "" || tr.Dest != "" || tr.DestPage > 0
+}
+
+func (tr TextRun) String() string {
+	s := fmt.Sprintf("page %d: %q", tr.Page, tr.Text)
+	if tr.URI != "" {
+		s += " uri:" + tr.URI
+	}
+	if tr.Dest != "" {
+		s += " dest:" + tr.Dest
+	}
+	if tr.DestPage > 0 {
+		s += fmt.Sprintf(" page:%d", tr.DestPage)
+	}
+	return s
+}
+
+// transform applies m to the point x,y.
+func (m matrix) transform(x, y float64) types.Point {
+	return types.Point{
+		X: x*m[0][0] + y*m[1][0] + m[2][0],
+		Y: x*m[0][1] + y*m[1][1] + m[2][1],
+	}
+}
+
+func translationMatrix(tx, ty float64) matrix {
+	return matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}
+}
+
+// textGState is the part of the graphics state relevant for text positioning, see 8.4 and 9.3
+type textGState struct {
+	ctm                                    matrix
+	font                                   *textFont
+	fontSize, charSpace, wordSpace, hScale float64
+	leading, rise                          float64
+	whiteFill, whiteStroke                 bool // current colors are white, see pathFunc
+}
+
+// textContent is the state of processing a content stream.
+type textContent struct {
+	resDict Dict
+	gs      textGState
+	stack   []textGState
+	tm, tlm matrix        // text matrix and text line matrix
+	depth   int           // form XObject nesting level
+	path    []types.Point // points of the current path in user space
+}
+
+// glyphFunc receives the text of a shown glyph, its origin, its end and its center in user space
+// along with the font size in user space.
+type glyphFunc func(s string, start, end, center types.Point, size float64)
+
+// imageFunc receives an image XObject with resource name id drawn using the current transformation matrix ctm.
+type imageFunc func(id string, o Object, sd *StreamDict, ctm matrix)
+
+// pathFunc receives the points of a path painted using a color other than white in user space
+// including the control points of curves.
+type pathFunc func(pp []types.Point)
+
+// showFunc is called by each text showing operator before reporting its glyphs using font f.
+type showFunc func(f *textFont)
+
+// textExtractor processes the text showing operators of content streams, see 9.4
+type textExtractor struct {
+	xRefTable   *XRefTable
+	fonts       map[int]*textFont // font dict obj# => font
+	defaultFont *textFont
+	forms       IntSet // form XObjects being processed
+	glyph       glyphFunc
+	image       imageFunc // optional
+	path        pathFunc  // optional
+	show        showFunc  // optional
+}
+
+func newTextExtractor(xRefTable *XRefTable, glyph glyphFunc) (*textExtractor, error) {
+	f, err := newTextFont(xRefTable, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &textExtractor{xRefTable: xRefTable, fonts: map[int]*textFont{}, defaultFont: f, forms: IntSet{}, glyph: glyph}, nil
+}
+
+// nextTextToken returns the next operand or operator of the content stream s.
+func nextTextToken(s *string) (Object, string, error) {
+	l := strings.TrimLeftFunc(*s, whitespaceOrEOL)
+	for strings.HasPrefix(l, "%") {
+		i := strings.IndexAny(l, "\n\r")
+		if i < 0 {
+			i = len(l)
+		}
+		l = strings.TrimLeftFunc(l[i:], whitespaceOrEOL)
+	}
+
+	if len(l) == 0 {
+		*s = l
+		return nil, "", nil
+	}
+
+	if strings.IndexByte("[(</+-.0123456789", l[0]) >= 0 {
+		l1 := l
+		if o, err := parseObject(&l1); err == nil && o != nil {
+			if _, ok := o.(IndirectRef); ok {
+				// Content streams don't hold indirect references, "0 1 RG" is no "0 1 R" followed by "G".
+				i, _ := positionToNextWhitespaceOrChar(l, "[]()<>/%{}")
+				l1 = l[:i]
+				if o, err = parseObject(&l1); err != nil {
+					return nil, "", err
+				}
+				l1 = l[i:]
+			}
+			*s = l1
+			return o, "", nil
+		}
+		// Skip anything unparsable as if it were an unknown operator.
+	}
+
+	i, _ := positionToNextWhitespaceOrChar(l, "[]()<>/%{}")
+	if i < 0 {
+		i = len(l)
+	}
+	if i == 0 {
+		i = 1
+	}
+	*s = l[i:]
+	return nil, l[:i], nil
+}
+
+// numbers returns the values of the last n operands of oo.
+func numbers(oo []Object, n int) ([]float64, bool) {
+	if len(oo) < n {
+		return nil, false
+	}
+	ff := make([]float64, n)
+	for i, o := range oo[len(oo)-n:] {
+		switch o := o.(type) {
+		case Integer:
+			ff[i] = float64(o.Value())
+		case Float:
+			ff[i] = o.Value()
+		default:
+			return nil, false
+		}
+	}
+	return ff, true
+}
+
+func matrixForNumbers(ff []float64) matrix {
+	return matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+}
+
+func stringBytes(o Object) ([]byte, bool) {
+	switch o := o.(type) {
+	case StringLiteral:
+		bb, err := Unescape(o.Value())
+		return bb, err == nil
+	case HexLiteral:
+		bb, err := o.Bytes()
+		return bb, err == nil
+	}
+	return nil, false
+}
+
+func (te *textExtractor) font(resDict Dict, id string) (*textFont, error) {
+	d, err := te.xRefTable.DereferenceDict(resDict["Font"])
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := d.Find(id)
+	if !found {
+		return te.defaultFont, nil
+	}
+
+	ir, ok := o.(IndirectRef)
+	if !ok {
+		return newTextFont(te.xRefTable, o)
+	}
+
+	objNr := ir.ObjectNumber.Value()
+	if f, ok := te.fonts[objNr]; ok {
+		return f, nil
+	}
+
+	f, err := newTextFont(te.xRefTable, o)
+	if err != nil {
+		return nil, err
+	}
+	te.fonts[objNr] = f
+
+	return f, nil
+}
+
+// showText reports the glyphs of bb and advances the text matrix accordingly, see 9.4.4
+func (te *textExtractor) showText(tc *textContent, bb []byte) {
+	gs := &tc.gs
+	f := gs.font
+	if f == nil {
+		f = te.defaultFont
+	}
+
+	for len(bb) > 0 {
+		c, n := f.nextCode(bb)
+		bb = bb[n:]
+
+		w0 := f.width(c) / 1000
+		trm := matrix{{gs.fontSize * gs.hScale, 0, 0}, {0, gs.fontSize, 0}, {0, gs.rise, 1}}.multiply(tc.tm).multiply(gs.ctm)
+
+		te.glyph(f.text(c), trm.transform(0, 0), trm.transform(w0, 0), trm.transform(w0/2, .3), math.Hypot(trm[1][0], trm[1][1]))
+
+		tx := w0*gs.fontSize + gs.charSpace
+		if n == 1 && c == 32 {
+			tx += gs.wordSpace
+		}
+		tc.tm = translationMatrix(tx*gs.hScale, 0).multiply(tc.tm)
+	}
+}
+
+func (te *textExtractor) startShow(tc *textContent) {
+	if te.show == nil {
+		return
+	}
+	f := tc.gs.font
+	if f == nil {
+		f = te.defaultFont
+	}
+	te.show(f)
+}
+
+func (te *textExtractor) showTextArray(tc *textContent, a Array) {
+	for _, o := range a {
+		if bb, ok := stringBytes(o); ok {
+			te.showText(tc, bb)
+			continue
+		}
+		if ff, ok := numbers([]Object{o}, 1); ok {
+			tx := -ff[0] / 1000 * tc.gs.fontSize * tc.gs.hScale
+			tc.tm = translationMatrix(tx, 0).multiply(tc.tm)
+		}
+	}
+}
+
+func (tc *textContent) nextLine(tx, ty float64) {
+	tc.tlm = translationMatrix(tx, ty).multiply(tc.tlm)
+	tc.tm = tc.tlm
+}
+
+func (te *textExtractor) processForm(tc *textContent, id string) error {
+	if tc.depth >= maxTextFormDepth {
+		return nil
+	}
+
+	d, err := te.xRefTable.DereferenceDict(tc.resDict["XObject"])
+	if err != nil {
+		return err
+	}
+
+	o, found := d.Find(id)
+	if !found {
+		return nil
+	}
+
+	if ir, ok := o.(IndirectRef); ok {
+		objNr := ir.ObjectNumber.Value()
+		if te.forms[objNr] {
+			return nil
+		}
+		te.forms[objNr] = true
+		defer delete(te.forms, objNr)
+	}
+
+	sd, err := te.xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return err
+	}
+	if st := sd.Subtype(); st != nil && *st == "Image" && te.image != nil {
+		te.image(id, o, sd, tc.gs.ctm)
+		return nil
+	}
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	gs := tc.gs
+	if a, err := te.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		ff := make([]float64, 6)
+		for i, o := range a {
+			ff[i] = te.xRefTable.number(o)
+		}
+		gs.ctm = matrixForNumbers(ff).multiply(gs.ctm)
+	}
+
+	resDict := tc.resDict
+	if o, found := sd.Find("Resources"); found {
+		d, err := te.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			resDict = d
+		}
+	}
+
+	return te.processContent(string(sd.Content), resDict, gs, tc.depth+1)
+}
+
+// isWhite returns true if the color components ff of a gray, RGB or CMYK color make up white.
+func isWhite(ff []float64, cmyk bool) bool {
+	for _, f := range ff {
+		if cmyk && f != 0 || !cmyk && f != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// processPathOperator tracks the construction and painting of paths along with the use of white, see 8.5
+// and returns true if op has been processed.
+func (te *textExtractor) processPathOperator(tc *textContent, op string, oo []Object) bool {
+	gs := &tc.gs
+
+	add := func(ff ...float64) {
+		for i := 0; i+1 < len(ff); i += 2 {
+			tc.path = append(tc.path, gs.ctm.transform(ff[i], ff[i+1]))
+		}
+	}
+
+	switch op {
+
+	case "m", "l":
+		if ff, ok := numbers(oo, 2); ok {
+			add(ff...)
+		}
+
+	case "c":
+		if ff, ok := numbers(oo, 6); ok {
+			add(ff...)
+		}
+
+	case "v", "y":
+		if ff, ok := numbers(oo, 4); ok {
+			add(ff...)
+		}
+
+	case "re":
+		if ff, ok := numbers(oo, 4); ok {
+			x, y, w, h := ff[0], ff[1], ff[2], ff[3]
+			add(x, y, x+w, y, x+w, y+h, x, y+h)
+		}
+
+	case "h":
+
+	case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*":
+		fill := op != "S" && op != "s"
+		stroke := op != "f" && op != "F" && op != "f*"
+		if len(tc.path) > 0 && (fill && !gs.whiteFill || stroke && !gs.whiteStroke) {
+			te.path(tc.path)
+		}
+		tc.path = nil
+
+	case "n":
+		tc.path = nil
+
+	case "g", "G", "rg", "RG", "k", "K":
+		n := map[string]int{"g": 1, "G": 1, "rg": 3, "RG": 3, "k": 4, "K": 4}[op]
+		ff, ok := numbers(oo, n)
+		white := ok && isWhite(ff, n == 4)
+		if op == "g" || op == "rg" || op == "k" {
+			gs.whiteFill = white
+		} else {
+			gs.whiteStroke = white
+		}
+
+	case "cs", "sc", "scn":
+		gs.whiteFill = false
+
+	case "CS", "SC", "SCN":
+		gs.whiteStroke = false
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (te *textExtractor) processOperator(tc *textContent, op string, oo []Object) error {
+	gs := &tc.gs
+
+	if te.path != nil && te.processPathOperator(tc, op, oo) {
+		return nil
+	}
+
+	switch op {
+
+	case "q":
+		tc.stack = append(tc.stack, *gs)
+
+	case "Q":
+		if n := len(tc.stack); n > 0 {
+			*gs = tc.stack[n-1]
+			tc.stack = tc.stack[:n-1]
+		}
+
+	case "cm":
+		if ff, ok := numbers(oo, 6); ok {
+			gs.ctm = matrixForNumbers(ff).multiply(gs.ctm)
+		}
+
+	case "BT":
+		tc.tm, tc.tlm = identMatrix, identMatrix
+
+	case "Tf":
+		if len(oo) < 2 {
+			break
+		}
+		if n, ok := oo[len(oo)-2].(Name); ok {
+			f, err := te.font(tc.resDict, n.Value())
+			if err != nil {
+				return err
+			}
+			gs.font = f
+		}
+		if ff, ok := numbers(oo, 1); ok {
+			gs.fontSize = ff[0]
+		}
+
+	case "Tc", "Tw", "Tz", "TL", "Ts":
+		ff, ok := numbers(oo, 1)
+		if !ok {
+			break
+		}
+		switch op {
+		case "Tc":
+			gs.charSpace = ff[0]
+		case "Tw":
+			gs.wordSpace = ff[0]
+		case "Tz":
+			gs.hScale = ff[0] / 100
+		case "TL":
+			gs.leading = ff[0]
+		case "Ts":
+			gs.rise = ff[0]
+		}
+
+	case "Td", "TD":
+		if ff, ok := numbers(oo, 2); ok {
+			if op == "TD" {
+				gs.leading = -ff[1]
+			}
+			tc.nextLine(ff[0], ff[1])
+		}
+
+	case "Tm":
+		if ff, ok := numbers(oo, 6); ok {
+			tc.tlm = matrixForNumbers(ff)
+			tc.tm = tc.tlm
+		}
+
+	case "T*":
+		tc.nextLine(0, -gs.leading)
+
+	case "Tj", "'", "\"":
+		if len(oo) == 0 {
+			break
+		}
+		if op == "\"" {
+			if ff, ok := numbers(oo[:len(oo)-1], 2); ok {
+				gs.wordSpace, gs.charSpace = ff[0], ff[1]
+			}
+		}
+		if op != "Tj" {
+			tc.nextLine(0, -gs.leading)
+		}
+		if bb, ok := stringBytes(oo[len(oo)-1]); ok {
+			te.startShow(tc)
+			te.showText(tc, bb)
+		}
+
+	case "TJ":
+		if len(oo) > 0 {
+			if a, ok := oo[len(oo)-1].(Array); ok {
+				te.startShow(tc)
+				te.showTextArray(tc, a)
+			}
+		}
+
+	case "Do":
+		if len(oo) > 0 {
+			if n, ok := oo[len(oo)-1].(Name); ok {
+				return te.processForm(tc, n.Value())
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// processContent reports all glyphs shown by the content stream s.
+func (te *textExtractor) processContent(s string, resDict Dict, gs textGState, depth int) error {
+	tc := &textContent{resDict: resDict, gs: gs, tm: identMatrix, tlm: identMatrix, depth: depth}
+	oo := []Object{}
+
+	for {
+		o, op, err := nextTextToken(&s)
+		if err != nil {
+			return err
+		}
+
+		if o != nil {
+			oo = append(oo, o)
+			continue
+		}
+
+		if op == "" {
+			return nil
+		}
+
+		if op == "BI" {
+			if err := skipBI(&s, NewPageResourceNames()); err != nil {
+				return err
+			}
+		} else if err := te.processOperator(tc, op, oo); err != nil {
+			return err
+		}
+
+		oo = oo[:0]
+	}
+}
+
+// glyphCorners approximates the box of a glyph by ascent and descent relative to its baseline from start to end.
+func glyphCorners(start, end, center types.Point) []types.Point {
+	mid := types.Point{X: (start.X + end.X) / 2, Y: (start.Y + end.Y) / 2}
+	u := types.Point{X: (center.X - mid.X) / .3, Y: (center.Y - mid.Y) / .3}
+	pp := []types.Point{}
+	for _, f := range []float64{-.25, .75} {
+		pp = append(pp, types.Point{X: start.X + f*u.X, Y: start.Y + f*u.Y}, types.Point{X: end.X + f*u.X, Y: end.Y + f*u.Y})
+	}
+	return pp
+}
+
+// textRunBuilder groups the glyphs of a page into runs covered by the same link.
+type textRunBuilder struct {
+	pageNr int
+	links  []pageLink
+	runs   []TextRun
+	buf    strings.Builder // text of the current run
+	link   int             // link covering the current run or -1
+	last   types.Point     // end of the last glyph
+	size   float64         // font size of the last glyph
+}
+
+func (b *textRunBuilder) linkAt(p types.Point) int {
+	for i, l := range b.links {
+		if l.r.Contains(p) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *textRunBuilder) flush() {
+	if n := len(b.runs); n > 0 {
+		b.runs[n-1].Text = b.buf.String()
+		b.buf.Reset()
+	}
+}
+
+// separator returns the white space implied by the distance between the last glyph and a glyph starting at p.
+func (b *textRunBuilder) separator(p types.Point, size float64) string {
+	if len(b.runs) == 0 {
+		return ""
+	}
+	if b.size > size {
+		size = b.size
+	}
+	if math.Abs(p.Y-b.last.Y) > size/2 {
+		return "\n"
+	}
+	if math.Hypot(p.X-b.last.X, p.Y-b.last.Y) > size/4 {
+		return " "
+	}
+	return ""
+}
+
+func (b *textRunBuilder) glyph(s string, start, end, center types.Point, size float64) {
+	if s == "" {
+		b.last = end
+		return
+	}
+
+	sep := b.separator(start, size)
+	if sep == " " && (strings.TrimSpace(s) == "" || strings.HasSuffix(b.buf.String(), " ")) {
+		sep = ""
+	}
+
+	l := b.linkAt(center)
+
+	if len(b.runs) == 0 || l != b.link {
+		// Keep separators out of linked text if possible.
+		if l >= 0 && sep != "" {
+			b.buf.WriteString(sep)
+			sep = ""
+		}
+		b.flush()
+		tr := TextRun{Page: b.pageNr}
+		if l >= 0 {
+			t := b.links[l].target
+			tr.URI, tr.Dest, tr.DestPage = t.URI, t.Dest, t.Page
+		}
+		b.runs = append(b.runs, tr)
+		b.link = l
+	}
+
+	b.buf.WriteString(sep)
+	b.buf.WriteString(s)
+	b.last, b.size = end, size
+}
+
+func (b *textRunBuilder) textRuns() []TextRun {
+	b.flush()
+	rr := []TextRun{}
+	for _, tr := range b.runs {
+		if strings.TrimSpace(tr.Text) != "" {
+			rr = append(rr, tr)
+		}
+	}
+	return rr
+}
+
+// processPageText reports the glyphs shown by the content of the page d using resDict.
+func (ctx *Context) processPageText(d, resDict Dict, glyph glyphFunc) error {
+	bb, err := ctx.PageContent(d)
+	if err == errNoContent {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	te, err := newTextExtractor(ctx.XRefTable, glyph)
+	if err != nil {
+		return err
+	}
+
+	gs := textGState{ctm: identMatrix, hScale: 1}
+
+	return te.processContent(string(bb), resDict, gs, 0)
+}
+
+func (ctx *Context) pageTextRuns(pageNr int) ([]TextRun, error) {
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	ll, err := ctx.pageLinks(d)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &textRunBuilder{pageNr: pageNr, links: ll, link: -1}
+
+	if err := ctx.processPageText(d, inhPAttrs.resources, b.glyph); err != nil {
+		return nil, err
+	}
+
+	return b.textRuns(), nil
+}
+
+// ExtractTextWithLinks returns the text of all selected pages as runs of consecutive text
+// covered by the same link annotation or by none.
+// A glyph is covered by a link if its center lies within the link rectangle.
+func (ctx *Context) ExtractTextWithLinks(selectedPages IntSet) ([]TextRun, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	if len(selectedPages) == 0 {
+		selectedPages = IntSet{}
+		for i := 1; i <= ctx.PageCount; i++ {
+			selectedPages[i] = true
+		}
+	}
+
+	rr := []TextRun{}
+
+	for _, i := range sortedSelectedPages(selectedPages) {
+		runs, err := ctx.pageTextRuns(i)
+		if err != nil {
+			return nil, err
+		}
+		rr = append(rr, runs...)
+	}
+
+	return rr, nil
+}
+
+// ExtractPageText returns the text of page pageNr line by line in the order shown by the page content.
+// Words of a line are separated by a single space.
+func (ctx *Context) ExtractPageText(pageNr int) (string, error) {
+	ll, err := ctx.pageTextLines(pageNr)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, l := range ll {
+		sb.WriteString(l.String())
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+// TextSpan represents the text shown by a single text showing operator.
+type TextSpan struct {
+	Text string     // text in content stream order
+	Font string     // base font name without subset tag
+	Size float64    // font size in user space
+	Rect *Rectangle // bounding box in user space
+}
+
+func (ts TextSpan) String() string {
+	return fmt.Sprintf("%q font:%s size:%.2f rect:%s", ts.Text, ts.Font, ts.Size, ts.Rect)
+}
+
+// PageText represents the text of a page as spans in content stream order.
+type PageText struct {
+	Page  int // page number
+	Spans []TextSpan
+}
+
+// textSpanBuilder collects the glyphs of a page into one span per text showing operator.
+type textSpanBuilder struct {
+	spans []TextSpan
+	buf   strings.Builder // text of the current span
+}
+
+func (b *textSpanBuilder) flush() {
+	if n := len(b.spans); n > 0 {
+		b.spans[n-1].Text = b.buf.String()
+		b.buf.Reset()
+	}
+}
+
+func (b *textSpanBuilder) show(f *textFont) {
+	b.flush()
+	b.spans = append(b.spans, TextSpan{Font: f.name})
+}
+
+func (b *textSpanBuilder) glyph(s string, start, end, center types.Point, size float64) {
+	n := len(b.spans)
+	if n == 0 {
+		return
+	}
+	ts := &b.spans[n-1]
+	b.buf.WriteString(s)
+	ts.Size = size
+	for _, p := range glyphCorners(start, end, center) {
+		ts.Rect = unionRect(ts.Rect, Rect(p.X, p.Y, p.X, p.Y))
+	}
+}
+
+func (b *textSpanBuilder) textSpans() []TextSpan {
+	b.flush()
+	tt := []TextSpan{}
+	for _, ts := range b.spans {
+		if ts.Text != "" && ts.Rect != nil {
+			tt = append(tt, ts)
+		}
+	}
+	return tt
+}
+
+func (ctx *Context) pageTextSpans(pageNr int) ([]TextSpan, error) {
+	d, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == errNoContent {
+		return []TextSpan{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := &textSpanBuilder{}
+
+	te, err := newTextExtractor(ctx.XRefTable, b.glyph)
+	if err != nil {
+		return nil, err
+	}
+	te.show = b.show
+
+	gs := textGState{ctm: identMatrix, hScale: 1}
+
+	if err := te.processContent(string(bb), inhPAttrs.resources, gs, 0); err != nil {
+		return nil, err
+	}
+
+	return b.textSpans(), nil
+}
+
+// ExtractPositionedText returns the text of all selected pages as spans along with font, font size
+// and bounding box in user space. Each span holds the text shown by a single Tj, TJ, ' or " operator.
+func (ctx *Context) ExtractPositionedText(selectedPages IntSet) ([]PageText, error) {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	if len(selectedPages) == 0 {
+		selectedPages = IntSet{}
+		for i := 1; i <= ctx.PageCount; i++ {
+			selectedPages[i] = true
+		}
+	}
+
+	pp := []PageText{}
+
+	for _, i := range sortedSelectedPages(selectedPages) {
+		tt, err := ctx.pageTextSpans(i)
+		if err != nil {
+			return nil, err
+		}
+		pp = append(pp, PageText{Page: i, Spans: tt})
+	}
+
+	return pp, nil
+}
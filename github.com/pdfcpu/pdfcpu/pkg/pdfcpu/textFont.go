@@ -0,0 +1,491 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+)
+
+// maxCodeRange limits the number of codes covered by a single CMap or width range.
+const maxCodeRange = 0x10000
+
+// codeSpaceRange is a range of character codes of a given length in bytes, see 9.7.6.2
+type codeSpaceRange struct {
+	lo, hi []byte
+}
+
+func (r codeSpaceRange) contains(bb []byte) bool {
+	if len(bb) < len(r.lo) {
+		return false
+	}
+	for i := range r.lo {
+		if bb[i] < r.lo[i] || bb[i] > r.hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// widthRange assigns the same width to a range of CIDs, see 9.7.4.3
+type widthRange struct {
+	lo, hi uint32
+	w      float64
+}
+
+// textFont holds what it takes to decode and measure glyphs shown using a font, see 9.6 and 9.7
+type textFont struct {
+	name        string            // base font without subset tag
+	type0       bool              // composite font using multi byte codes
+	codeSpace   []codeSpaceRange  // code space of a composite font
+	toUnicode   map[uint32]string // code => text, see 9.10.3
+	glyphNames  map[uint32]string // code => glyph name for simple fonts using Differences
+	widths      map[uint32]float64
+	widthRanges []widthRange
+	dw          float64 // default width
+	scale       float64 // glyph space => text space in thousandths
+}
+
+// nextCode returns the first character code of bb along with its length in bytes.
+func (f *textFont) nextCode(bb []byte) (uint32, int) {
+	n := 1
+	if f.type0 {
+		n = 2
+		for _, r := range f.codeSpace {
+			if r.contains(bb) {
+				n = len(r.lo)
+				break
+			}
+		}
+	}
+	if n > len(bb) {
+		n = len(bb)
+	}
+	return codeForBytes(bb[:n]), n
+}
+
+// width returns the width of the glyph for code c in thousandths of text space units.
+func (f *textFont) width(c uint32) float64 {
+	if w, ok := f.widths[c]; ok {
+		return w * f.scale
+	}
+	for _, r := range f.widthRanges {
+		if c >= r.lo && c <= r.hi {
+			return r.w * f.scale
+		}
+	}
+	if !f.type0 && font.IsCoreFont(f.name) {
+		return float64(font.CharWidth(f.name, int(c)))
+	}
+	return f.dw * f.scale
+}
+
+// cp1252 holds the characters of WinAnsiEncoding different from Latin-1, see D.2
+var cp1252 = []rune{
+	0x20AC, 0xFFFD, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021, 0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0xFFFD, 0x017D, 0xFFFD,
+	0xFFFD, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014, 0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0xFFFD, 0x017E, 0x0178,
+}
+
+// glyphNameText maps frequently used glyph names beyond single characters to text, see Adobe Glyph List.
+var glyphNameText = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$", "percent": "%",
+	"ampersand": "&", "quotesingle": "'", "quoteright": "’", "quoteleft": "‘", "parenleft": "(", "parenright": ")",
+	"asterisk": "*", "plus": "+", "comma": ",", "hyphen": "-", "period": ".", "slash": "/",
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+	"colon": ":", "semicolon": ";", "less": "<", "equal": "=", "greater": ">", "question": "?", "at": "@",
+	"bracketleft": "[", "backslash": "\\", "bracketright": "]", "asciicircum": "^", "underscore": "_", "grave": "`",
+	"braceleft": "{", "bar": "|", "braceright": "}", "asciitilde": "~",
+	"endash": "–", "emdash": "—", "bullet": "•", "ellipsis": "…",
+	"quotedblleft": "“", "quotedblright": "”", "fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl",
+}
+
+func glyphText(name string) string {
+	if len(name) == 1 {
+		return name
+	}
+	if s, ok := glyphNameText[name]; ok {
+		return s
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if i, err := strconv.ParseUint(name[3:], 16, 16); err == nil {
+			return string(rune(i))
+		}
+	}
+	return ""
+}
+
+// text returns the text for code c or "" if unknown.
+func (f *textFont) text(c uint32) string {
+	if s, ok := f.toUnicode[c]; ok {
+		return s
+	}
+	if f.type0 {
+		return ""
+	}
+	if name, ok := f.glyphNames[c]; ok {
+		return glyphText(name)
+	}
+	if c >= 0x80 && c <= 0x9F {
+		return string(cp1252[c-0x80])
+	}
+	return string(rune(c))
+}
+
+// cmapTokens splits the content of a CMap into hex strings, names, brackets and keywords.
+func cmapTokens(s string) []string {
+	tt := []string{}
+	for {
+		s = strings.TrimLeftFunc(s, whitespaceOrEOL)
+		if len(s) == 0 {
+			return tt
+		}
+		var i int
+		switch s[0] {
+		case '<':
+			if i = strings.IndexByte(s, '>'); i < 0 {
+				return tt
+			}
+			i++
+		case '[', ']':
+			i = 1
+		case '%':
+			if i = strings.IndexAny(s, "\n\r"); i < 0 {
+				return tt
+			}
+			s = s[i:]
+			continue
+		default:
+			if i, _ = positionToNextWhitespaceOrChar(s[1:], "<[]/%"); i < 0 {
+				i = len(s) - 1
+			}
+			i++
+		}
+		tt = append(tt, s[:i])
+		s = s[i:]
+	}
+}
+
+// cmapHex returns the bytes of the CMap hex string token t.
+func cmapHex(t string) ([]byte, bool) {
+	if !strings.HasPrefix(t, "<") || !strings.HasSuffix(t, ">") {
+		return nil, false
+	}
+	s := strings.Map(func(r rune) rune {
+		if whitespaceOrEOL(r) {
+			return -1
+		}
+		return r
+	}, t[1:len(t)-1])
+	if len(s)%2 == 1 {
+		s += "0"
+	}
+	bb, err := hex.DecodeString(s)
+	return bb, err == nil
+}
+
+func codeForBytes(bb []byte) uint32 {
+	var c uint32
+	for _, b := range bb {
+		c = c<<8 | uint32(b)
+	}
+	return c
+}
+
+// utf16Text decodes UTF-16BE encoded bb.
+func utf16Text(bb []byte) string {
+	if len(bb)%2 == 1 {
+		return string(rune(bb[0]))
+	}
+	u := make([]uint16, len(bb)/2)
+	for i := range u {
+		u[i] = uint16(bb[2*i])<<8 | uint16(bb[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
+// incrementedText returns the text of bb with its last UTF-16 code unit incremented by i, see 9.10.3
+func incrementedText(bb []byte, i uint32) string {
+	if len(bb) < 2 {
+		return string(rune(codeForBytes(bb) + i))
+	}
+	bb1 := append([]byte{}, bb...)
+	n := len(bb1)
+	v := uint32(bb1[n-2])<<8 | uint32(bb1[n-1]) + i
+	bb1[n-2], bb1[n-1] = byte(v>>8), byte(v)
+	return utf16Text(bb1)
+}
+
+// parseCMap records the code space ranges and any bfchar and bfrange mappings of the CMap s in f, see 9.7.5 and 9.10.3
+func (f *textFont) parseCMap(s string, codeSpace bool) {
+	tt := cmapTokens(s)
+	for i := 0; i < len(tt); i++ {
+		switch tt[i] {
+
+		case "begincodespacerange":
+			for i++; i+1 < len(tt) && tt[i] != "endcodespacerange"; i += 2 {
+				lo, ok1 := cmapHex(tt[i])
+				hi, ok2 := cmapHex(tt[i+1])
+				if ok1 && ok2 && len(lo) == len(hi) && codeSpace {
+					f.codeSpace = append(f.codeSpace, codeSpaceRange{lo: lo, hi: hi})
+				}
+			}
+
+		case "beginbfchar":
+			for i++; i+1 < len(tt) && tt[i] != "endbfchar"; i += 2 {
+				src, ok := cmapHex(tt[i])
+				if !ok {
+					continue
+				}
+				if dst, ok := cmapHex(tt[i+1]); ok {
+					f.toUnicode[codeForBytes(src)] = utf16Text(dst)
+				} else if strings.HasPrefix(tt[i+1], "/") {
+					f.toUnicode[codeForBytes(src)] = glyphText(tt[i+1][1:])
+				}
+			}
+
+		case "beginbfrange":
+			for i++; i+2 < len(tt) && tt[i] != "endbfrange"; i += 3 {
+				lo, ok1 := cmapHex(tt[i])
+				hi, ok2 := cmapHex(tt[i+1])
+				if !ok1 || !ok2 {
+					continue
+				}
+				c1, c2 := codeForBytes(lo), codeForBytes(hi)
+				if c2 < c1 || c2-c1 >= maxCodeRange {
+					continue
+				}
+				if tt[i+2] == "[" {
+					i += 3
+					for c := c1; i < len(tt) && tt[i] != "]"; i, c = i+1, c+1 {
+						if dst, ok := cmapHex(tt[i]); ok && c <= c2 {
+							f.toUnicode[c] = utf16Text(dst)
+						}
+					}
+					// Continue with the token following ].
+					i -= 2
+					continue
+				}
+				if dst, ok := cmapHex(tt[i+2]); ok {
+					for c := c1; c <= c2; c++ {
+						f.toUnicode[c] = incrementedText(dst, c-c1)
+					}
+				}
+			}
+
+		}
+	}
+}
+
+func (xRefTable *XRefTable) streamContent(o Object) ([]byte, error) {
+	sd, err := xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+	return sd.Content, nil
+}
+
+func (xRefTable *XRefTable) number(o Object) float64 {
+	f, err := xRefTable.DereferenceNumber(o)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// parseCIDWidths records the glyph widths of the W array a of a CIDFont, see 9.7.4.3
+func (f *textFont) parseCIDWidths(xRefTable *XRefTable, a Array) error {
+	for i := 0; i+1 < len(a); {
+		c1 := uint32(xRefTable.number(a[i]))
+		o, err := xRefTable.Dereference(a[i+1])
+		if err != nil {
+			return err
+		}
+		if ww, ok := o.(Array); ok {
+			for j, w := range ww {
+				f.widths[c1+uint32(j)] = xRefTable.number(w)
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(a) {
+			break
+		}
+		c2 := uint32(xRefTable.number(o))
+		f.widthRanges = append(f.widthRanges, widthRange{lo: c1, hi: c2, w: xRefTable.number(a[i+2])})
+		i += 3
+	}
+	return nil
+}
+
+func (f *textFont) parseType0Font(xRefTable *XRefTable, d Dict) error {
+	f.type0 = true
+	f.dw = 1000
+
+	a, err := xRefTable.DereferenceArray(d["DescendantFonts"])
+	if err != nil || len(a) == 0 {
+		return err
+	}
+
+	df, err := xRefTable.DereferenceDict(a[0])
+	if err != nil || df == nil {
+		return err
+	}
+
+	if o, found := df.Find("DW"); found {
+		f.dw = xRefTable.number(o)
+	}
+
+	if o, found := df.Find("W"); found {
+		w, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		if err := f.parseCIDWidths(xRefTable, w); err != nil {
+			return err
+		}
+	}
+
+	// An embedded CMap defines the code space, Identity-H and Identity-V use 2 bytes.
+	if o, err := xRefTable.Dereference(d["Encoding"]); err == nil {
+		if _, ok := o.(StreamDict); ok {
+			bb, err := xRefTable.streamContent(d["Encoding"])
+			if err != nil {
+				return err
+			}
+			f.parseCMap(string(bb), true)
+		}
+	}
+
+	return nil
+}
+
+func (f *textFont) parseSimpleFont(xRefTable *XRefTable, d Dict) error {
+	if o, found := d.Find("Widths"); found {
+		a, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+		var firstChar uint32
+		if o, found := d.Find("FirstChar"); found {
+			firstChar = uint32(xRefTable.number(o))
+		}
+		for i, w := range a {
+			f.widths[firstChar+uint32(i)] = xRefTable.number(w)
+		}
+	}
+
+	fd, err := xRefTable.DereferenceDict(d["FontDescriptor"])
+	if err != nil {
+		return err
+	}
+	if o, found := fd.Find("MissingWidth"); found {
+		f.dw = xRefTable.number(o)
+	}
+
+	if d.Subtype() != nil && *d.Subtype() == "Type3" {
+		f.scale = 1000
+		if a, err := xRefTable.DereferenceArray(d["FontMatrix"]); err == nil && len(a) == 6 {
+			f.scale = xRefTable.number(a[0]) * 1000
+		}
+	}
+
+	o, err := xRefTable.Dereference(d["Encoding"])
+	if err != nil {
+		return err
+	}
+	enc, ok := o.(Dict)
+	if !ok {
+		return nil
+	}
+
+	// Glyph names of Differences override the base encoding, see 9.6.6.1
+	a, err := xRefTable.DereferenceArray(enc["Differences"])
+	if err != nil {
+		return err
+	}
+	var c uint32
+	for _, o := range a {
+		o, _ = xRefTable.Dereference(o)
+		switch o := o.(type) {
+		case Integer:
+			c = uint32(o.Value())
+		case Name:
+			f.glyphNames[c] = o.Value()
+			c++
+		}
+	}
+
+	return nil
+}
+
+// newTextFont returns the decoding and measuring details of the font dict o.
+func newTextFont(xRefTable *XRefTable, o Object) (*textFont, error) {
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &textFont{
+		toUnicode:  map[uint32]string{},
+		glyphNames: map[uint32]string{},
+		widths:     map[uint32]float64{},
+		scale:      1,
+	}
+
+	if d == nil {
+		f.dw = 500
+		return f, nil
+	}
+
+	if s := d.NameEntry("BaseFont"); s != nil {
+		f.name = *s
+		// Strip any subset tag, see 9.6.4
+		if i := strings.IndexByte(f.name, '+'); i == 6 {
+			f.name = f.name[7:]
+		}
+	}
+
+	if d.Subtype() != nil && *d.Subtype() == "Type0" {
+		err = f.parseType0Font(xRefTable, d)
+	} else {
+		err = f.parseSimpleFont(xRefTable, d)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if o, found := d.Find("ToUnicode"); found {
+		if o, err := xRefTable.Dereference(o); err == nil {
+			if _, ok := o.(StreamDict); ok {
+				bb, err := xRefTable.streamContent(o)
+				if err != nil {
+					return nil, err
+				}
+				f.parseCMap(string(bb), f.type0 && len(f.codeSpace) == 0)
+			}
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import "github.com/pdfcpu/pdfcpu/pkg/log"
+
+// removeSoftMasks removes the soft masks of the graphics state parameter dicts referenced by resDict.
+func (xRefTable *XRefTable) removeSoftMasks(resDict Dict) (int, error) {
+
+	o, found := resDict.Find("ExtGState")
+	if !found {
+		return 0, nil
+	}
+
+	d, err := xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		return 0, err
+	}
+
+	var count int
+
+	for _, o := range d {
+		gs, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return 0, err
+		}
+		if gs == nil {
+			continue
+		}
+		if _, found := gs.Find("SMask"); found {
+			gs.Delete("SMask")
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// removeTransparency removes the transparency group of d if d is a page or form XObject
+// and the soft masks of the graphics states in its resources.
+func (xRefTable *XRefTable) removeTransparency(d Dict, isForm bool) (int, error) {
+
+	var count int
+
+	if t := d.Type(); isForm || t != nil && *t == "Page" {
+		if _, found := d.Find("Group"); found {
+			d.Delete("Group")
+			count++
+		}
+	}
+
+	o, found := d.Find("Resources")
+	if !found {
+		return count, nil
+	}
+
+	resDict, err := xRefTable.DereferenceDict(o)
+	if err != nil || resDict == nil {
+		return count, err
+	}
+
+	c, err := xRefTable.removeSoftMasks(resDict)
+	if err != nil {
+		return 0, err
+	}
+
+	return count + c, nil
+}
+
+// RemoveTransparency removes the transparency groups of all pages and form XObjects
+// along with the soft masks of all graphics states in use and returns the number of entries removed.
+// Any blending effects get lost.
+func RemoveTransparency(ctx *Context) (int, error) {
+
+	var count int
+
+	for objNr, entry := range ctx.Table {
+
+		if entry.Free || entry.Object == nil {
+			continue
+		}
+
+		var (
+			c   int
+			err error
+		)
+
+		switch o := entry.Object.(type) {
+
+		case Dict:
+			c, err = ctx.removeTransparency(o, false)
+
+		case StreamDict:
+			st := o.Subtype()
+			c, err = ctx.removeTransparency(o.Dict, st != nil && *st == "Form")
+
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		if c > 0 {
+			log.Debug.Printf("RemoveTransparency: obj#%d: removed %d entries\n", objNr, c)
+		}
+
+		count += c
+	}
+
+	return count, nil
+}
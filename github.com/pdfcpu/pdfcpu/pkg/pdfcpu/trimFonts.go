@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+)
+
+// fontFile identifies an embedded font file by its font descriptor entry.
+type fontFile struct {
+	key string // FontFile, FontFile2 or FontFile3
+	ir  IndirectRef
+	sd  *StreamDict
+}
+
+// shareFontFiles lets font descriptor d refer to a font file already seen in ff if embedding identical data.
+// Returns the number of bytes saved.
+func shareFontFiles(xRefTable *XRefTable, d Dict, ff map[int][]fontFile) (int64, error) {
+
+	var saved int64
+
+	for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+
+		ir := d.IndirectRefEntry(k)
+		if ir == nil {
+			continue
+		}
+
+		sd, err := xRefTable.DereferenceStreamDict(*ir)
+		if err != nil {
+			return 0, err
+		}
+		if sd == nil || sd.Raw == nil {
+			continue
+		}
+
+		var dupl *fontFile
+
+		for i, f := range ff[len(sd.Raw)] {
+			if f.key != k {
+				continue
+			}
+			ok := f.ir.ObjectNumber == ir.ObjectNumber
+			if !ok {
+				if ok, err = equalStreamDicts(f.sd, sd, xRefTable); err != nil {
+					return 0, err
+				}
+			}
+			if ok {
+				dupl = &ff[len(sd.Raw)][i]
+				break
+			}
+		}
+
+		if dupl == nil {
+			ff[len(sd.Raw)] = append(ff[len(sd.Raw)], fontFile{key: k, ir: *ir, sd: sd})
+			continue
+		}
+
+		if dupl.ir.ObjectNumber == ir.ObjectNumber {
+			continue
+		}
+
+		log.Optimize.Printf("shareFontFiles: replacing font file obj#%d by obj#%d\n", ir.ObjectNumber, dupl.ir.ObjectNumber)
+		d.Update(k, dupl.ir)
+		saved += int64(len(sd.Raw))
+	}
+
+	return saved, nil
+}
+
+// trimWidths drops the leading and trailing zero widths of the simple font fontDict
+// adjusting FirstChar and LastChar accordingly.
+// Returns the number of bytes saved.
+func trimWidths(xRefTable *XRefTable, fontDict, fd Dict) (int64, error) {
+
+	// Codes outside FirstChar..LastChar fall back to MissingWidth.
+	if fd != nil {
+		if o, found := fd.Find("MissingWidth"); found {
+			o, err := xRefTable.Dereference(o)
+			if err != nil {
+				return 0, err
+			}
+			if xRefTable.number(o) != 0 {
+				return 0, nil
+			}
+		}
+	}
+
+	fc := fontDict.IntEntry("FirstChar")
+	if fc == nil {
+		return 0, nil
+	}
+
+	o, found := fontDict.Find("Widths")
+	if !found {
+		return 0, nil
+	}
+
+	a, err := xRefTable.DereferenceArray(o)
+	if err != nil || len(a) == 0 {
+		return 0, err
+	}
+
+	isZero := func(o Object) bool {
+		o, err := xRefTable.Dereference(o)
+		return err == nil && o != nil && xRefTable.number(o) == 0
+	}
+
+	i, j := 0, len(a)
+	for i < j && isZero(a[i]) {
+		i++
+	}
+	for j > i && isZero(a[j-1]) {
+		j--
+	}
+
+	if i == 0 && j == len(a) {
+		return 0, nil
+	}
+
+	if i == j {
+		// Keep a single width so FirstChar does not exceed LastChar.
+		i, j = 0, 1
+	}
+
+	var saved int64
+	for _, o := range append(a[:i:i], a[j:]...) {
+		saved += int64(len(o.PDFString()) + 1)
+	}
+
+	w := append(Array{}, a[i:j]...)
+
+	if ir, ok := o.(IndirectRef); ok {
+		entry, found := xRefTable.FindTableEntryForIndRef(&ir)
+		if found {
+			entry.Object = w
+		}
+	} else {
+		fontDict.Update("Widths", w)
+	}
+
+	fontDict.Update("FirstChar", Integer(*fc+i))
+	fontDict.Update("LastChar", Integer(*fc+j-1))
+
+	return saved, nil
+}
+
+// trimFonts removes redundant data from embedded subset fonts without resubsetting them.
+// Font files embedded more than once get shared and the Widths of simple fonts get trimmed to the range of used codes.
+// The number of bytes saved is recorded in ctx.Optimize.
+func trimFonts(ctx *Context) error {
+
+	log.Optimize.Println("trimFonts begin")
+
+	objNrs := []int{}
+	for objNr, fo := range ctx.Optimize.FontObjects {
+		if fo.Prefix != "" {
+			objNrs = append(objNrs, objNr)
+		}
+	}
+	sort.Ints(objNrs)
+
+	ff := map[int][]fontFile{}
+
+	var saved int64
+
+	for _, objNr := range objNrs {
+
+		fontDict := ctx.Optimize.FontObjects[objNr].FontDict
+
+		fd, err := fontDescriptor(ctx.XRefTable, fontDict, objNr)
+		if err != nil {
+			return err
+		}
+
+		if fd != nil {
+			n, err := shareFontFiles(ctx.XRefTable, fd, ff)
+			if err != nil {
+				return err
+			}
+			saved += n
+		}
+
+		n, err := trimWidths(ctx.XRefTable, fontDict, fd)
+		if err != nil {
+			return err
+		}
+		saved += n
+	}
+
+	ctx.Optimize.TrimmedFontBytes = saved
+
+	log.Info.Printf("trimming fonts saved %s (%d bytes)\n", ByteSize(saved), saved)
+
+	log.Optimize.Println("trimFonts end")
+
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/log"
+	"github.com/pkg/errors"
+)
+
+func parseAnchor(s string) (anchor, error) {
+
+	switch s {
+	case "tl":
+		return TopLeft, nil
+	case "tc":
+		return TopCenter, nil
+	case "tr":
+		return TopRight, nil
+	case "l":
+		return Left, nil
+	case "", "c":
+		return Center, nil
+	case "r":
+		return Right, nil
+	case "bl":
+		return BottomLeft, nil
+	case "bc":
+		return BottomCenter, nil
+	case "br":
+		return BottomRight, nil
+	}
+
+	return 0, errors.Errorf("pdfcpu: unknown position anchor: %s", s)
+}
+
+// anchorVector returns the horizontal and vertical direction of a as -1, 0 or 1.
+func anchorVector(a anchor) (float64, float64) {
+
+	switch a {
+	case TopLeft:
+		return -1, 1
+	case TopCenter:
+		return 0, 1
+	case TopRight:
+		return 1, 1
+	case Left:
+		return -1, 0
+	case Right:
+		return 1, 0
+	case BottomLeft:
+		return -1, -1
+	case BottomCenter:
+		return 0, -1
+	case BottomRight:
+		return 1, -1
+	}
+
+	return 0, 0
+}
+
+func normalizedRotation(rot int) int {
+	return (rot%360 + 360) % 360
+}
+
+// visibleDim returns the dimensions of r as displayed using rotation rot.
+func visibleDim(r *Rectangle, rot int) Dim {
+	if rot == 90 || rot == 270 {
+		return Dim{r.Height(), r.Width()}
+	}
+	return Dim{r.Width(), r.Height()}
+}
+
+func parseTargetSize(s string) (*Dim, error) {
+
+	if s == "" || s == "max" {
+		return nil, nil
+	}
+
+	if d, _, err := parsePageDim(s); err == nil {
+		return d, nil
+	}
+
+	d, _, err := parsePageFormat(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dim{d.Width, d.Height}, nil
+}
+
+// uniformMediaBox returns a media box of visible dimensions dim containing the page's view port vp
+// positioned according to a.
+func uniformMediaBox(vp *Rectangle, rot int, dim Dim, a anchor) (*Rectangle, error) {
+
+	w, h := dim.Width, dim.Height
+	if rot == 90 || rot == 270 {
+		w, h = h, w
+	}
+
+	ex, ey := w-vp.Width(), h-vp.Height()
+	if ex < 0 || ey < 0 {
+		return nil, errors.New("exceeds target size")
+	}
+
+	// Map the anchor from display orientation into user space.
+	x, y := anchorVector(a)
+	for i := 0; i < rot/90; i++ {
+		x, y = -y, x
+	}
+
+	llx := vp.LL.X - ex*(1+x)/2
+	lly := vp.LL.Y - ey*(1+y)/2
+
+	return RectForWidthAndHeight(llx, lly, w, h), nil
+}
+
+// UnifyPageSize sets a common media box for all pages of ctx without scaling any page content.
+// targetSize is either a paper size like A4 or A4L, dimensions like "612 792" or "max" for the largest page size.
+// align is the position anchor of each page within its new media box, one of tl,tc,tr,l,c,r,bl,bc,br.
+// Any crop box gets replaced by the new media box.
+func UnifyPageSize(ctx *Context, targetSize, align string) error {
+
+	dim, err := parseTargetSize(targetSize)
+	if err != nil {
+		return err
+	}
+
+	a, err := parseAnchor(align)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	consolidateRes := false
+
+	if dim == nil {
+		// Use the largest visible page dimensions.
+		dim = &Dim{}
+		for i := 1; i <= ctx.PageCount; i++ {
+			_, inhPAttrs, err := ctx.PageDict(i, consolidateRes)
+			if err != nil {
+				return err
+			}
+			d := visibleDim(viewPort(ctx.XRefTable, inhPAttrs), normalizedRotation(inhPAttrs.rotate))
+			if d.Width > dim.Width {
+				dim.Width = d.Width
+			}
+			if d.Height > dim.Height {
+				dim.Height = d.Height
+			}
+		}
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+
+		log.Debug.Printf("UnifyPageSize page:%d\n", i)
+
+		d, inhPAttrs, err := ctx.PageDict(i, consolidateRes)
+		if err != nil {
+			return err
+		}
+
+		rot := normalizedRotation(inhPAttrs.rotate)
+
+		mb, err := uniformMediaBox(viewPort(ctx.XRefTable, inhPAttrs), rot, *dim, a)
+		if err != nil {
+			return errors.Wrapf(err, "pdfcpu: UnifyPageSize: page %d", i)
+		}
+
+		// Page content and annotations keep their positions in user space.
+		d.Update("MediaBox", mb.Array())
+		d.Update("CropBox", mb.Array())
+	}
+
+	return nil
+}
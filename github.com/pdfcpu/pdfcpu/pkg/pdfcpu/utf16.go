@@ -109,6 +109,15 @@ func decodeUTF16String(b []byte) (string, error) {
 	return string(decb), nil
 }
 
+// EncodeUTF16String encodes s into UTF16BE including the byte order mark.
+func EncodeUTF16String(s string) string {
+	b := []byte{0xFE, 0xFF}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return string(b)
+}
+
 // DecodeUTF16String decodes a UTF16BE string from a hex string.
 func DecodeUTF16String(s string) (string, error) {
 	return decodeUTF16String([]byte(s))
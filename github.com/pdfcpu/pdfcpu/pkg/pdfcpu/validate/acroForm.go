@@ -81,6 +81,8 @@ func validateAppearanceDict(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
 	// see 12.5.5 Appearance Streams
 
+	objNr := objNumber(o)
+
 	d, err := xRefTable.DereferenceDict(o)
 	if err != nil || d == nil {
 		return err
@@ -92,6 +94,10 @@ func validateAppearanceDict(xRefTable *pdf.XRefTable, o pdf.Object) error {
 		if xRefTable.ValidationMode == pdf.ValidationStrict {
 			return errors.New("pdfcpu: validateAppearanceDict: missing required entry \"N\"")
 		}
+		err = relaxed(xRefTable, objNr, "validateAppearanceDict: missing required entry \"N\"")
+		if err != nil {
+			return err
+		}
 	} else {
 		err = validateAppearanceDictEntry(xRefTable, o)
 		if err != nil {
@@ -191,9 +197,9 @@ func validateAcroFieldDict(xRefTable *pdf.XRefTable, ir pdf.IndirectRef, inField
 		return err
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 		if len(d) == 0 {
-			return nil
+			return relaxed(xRefTable, ir.ObjectNumber.Value(), "validateAcroFieldDict: empty field dict")
 		}
 	}
 
@@ -216,6 +222,8 @@ func validateAcroFieldDict(xRefTable *pdf.XRefTable, ir pdf.IndirectRef, inField
 			return err
 		}
 
+		kids := make(pdf.Array, 0, len(a))
+
 		for _, value := range a {
 
 			ir, ok := value.(pdf.IndirectRef)
@@ -223,10 +231,18 @@ func validateAcroFieldDict(xRefTable *pdf.XRefTable, ir pdf.IndirectRef, inField
 				return errors.New("pdfcpu: validateAcroFieldDict: corrupt kids array: entries must be indirect reference")
 			}
 
-			if err = validateAcroFieldDict(xRefTable, ir, xInFieldType); err != nil {
+			if ok, err = validateAcroFieldKid(xRefTable, ir, xInFieldType); err != nil {
 				return err
 			}
+			if ok {
+				kids = append(kids, ir)
+			}
+
+		}
 
+		if len(kids) < len(a) {
+			// Drop the kids closing a cycle.
+			d.Update("Kids", kids)
 		}
 
 		return nil
@@ -248,6 +264,18 @@ func validateAcroFieldDict(xRefTable *pdf.XRefTable, ir pdf.IndirectRef, inField
 	return err
 }
 
+// validateAcroFieldKid validates the field dict referenced by ir unless ir closes a reference cycle.
+func validateAcroFieldKid(xRefTable *pdf.XRefTable, ir pdf.IndirectRef, inFieldType *pdf.Name) (bool, error) {
+
+	ok, err := xRefTable.EnterObject(ir, "validateAcroFieldDict")
+	if err != nil || !ok {
+		return false, err
+	}
+	defer xRefTable.LeaveObject(ir)
+
+	return true, validateAcroFieldDict(xRefTable, ir, inFieldType)
+}
+
 func validateAcroFormFields(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
 	a, err := xRefTable.DereferenceArray(o)
@@ -262,8 +290,7 @@ func validateAcroFormFields(xRefTable *pdf.XRefTable, o pdf.Object) error {
 			return errors.New("pdfcpu: validateAcroFormFields: corrupt form field array entry")
 		}
 
-		err = validateAcroFieldDict(xRefTable, ir, nil)
-		if err != nil {
+		if _, err = validateAcroFieldKid(xRefTable, ir, nil); err != nil {
 			return err
 		}
 
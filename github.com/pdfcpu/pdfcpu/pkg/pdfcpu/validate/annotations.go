@@ -334,9 +334,9 @@ func validateAnnotationDictLink(xRefTable *pdf.XRefTable, d pdf.Dict, dictName s
 	}
 
 	// BS, optional, border style dict, since V1.6
-	sinceVersion := pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "BS", pdf.V16, pdf.V13)
+	if err != nil {
+		return err
 	}
 
 	return validateBorderStyleDict(xRefTable, d, dictName, "BS", OPTIONAL, sinceVersion)
@@ -351,9 +351,9 @@ func validateAnnotationDictFreeTextPart1(xRefTable *pdf.XRefTable, d pdf.Dict, d
 	}
 
 	// Q, optional, integer, since V1.4, 0,1,2
-	sinceVersion = pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "Q", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateIntegerEntry(xRefTable, d, dictName, "Q", OPTIONAL, sinceVersion, func(i int) bool { return 0 <= i && i <= 2 })
 	if err != nil {
@@ -361,9 +361,9 @@ func validateAnnotationDictFreeTextPart1(xRefTable *pdf.XRefTable, d pdf.Dict, d
 	}
 
 	// RC, optional, text string or text stream, since V1.5
-	sinceVersion = pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "RC", pdf.V15, pdf.V14)
+	if err != nil {
+		return err
 	}
 	err = validateStringOrStreamEntry(xRefTable, d, dictName, "RC", OPTIONAL, sinceVersion)
 	if err != nil {
@@ -377,9 +377,9 @@ func validateAnnotationDictFreeTextPart1(xRefTable *pdf.XRefTable, d pdf.Dict, d
 	}
 
 	// CL, optional, number array, since V1.6, len: 4 or 6
-	sinceVersion = pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "CL", pdf.V16, pdf.V14)
+	if err != nil {
+		return err
 	}
 
 	_, err = validateNumberArrayEntry(xRefTable, d, dictName, "CL", OPTIONAL, sinceVersion, func(a pdf.Array) bool { return len(a) == 4 || len(a) == 6 })
@@ -390,14 +390,14 @@ func validateAnnotationDictFreeTextPart1(xRefTable *pdf.XRefTable, d pdf.Dict, d
 func validateAnnotationDictFreeTextPart2(xRefTable *pdf.XRefTable, d pdf.Dict, dictName string, sinceVersion pdf.Version) error {
 
 	// IT, optional, name, since V1.6
-	sinceVersion = pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "IT", pdf.V16, pdf.V14)
+	if err != nil {
+		return err
 	}
 	validate := func(s string) bool {
 		return pdf.MemberOf(s, []string{"FreeText", "FreeTextCallout", "FreeTextTypeWriter", "FreeTextTypewriter"})
 	}
-	_, err := validateNameEntry(xRefTable, d, dictName, "IT", OPTIONAL, sinceVersion, validate)
+	_, err = validateNameEntry(xRefTable, d, dictName, "IT", OPTIONAL, sinceVersion, validate)
 	if err != nil {
 		return err
 	}
@@ -409,9 +409,9 @@ func validateAnnotationDictFreeTextPart2(xRefTable *pdf.XRefTable, d pdf.Dict, d
 	}
 
 	// RD, optional, rectangle, since V1.6
-	sinceVersion = pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "RD", pdf.V16, pdf.V14)
+	if err != nil {
+		return err
 	}
 	_, err = validateRectangleEntry(xRefTable, d, dictName, "RD", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -419,9 +419,9 @@ func validateAnnotationDictFreeTextPart2(xRefTable *pdf.XRefTable, d pdf.Dict, d
 	}
 
 	// BS, optional, border style dict, since V1.6
-	sinceVersion = pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "BS", pdf.V16, pdf.V13)
+	if err != nil {
+		return err
 	}
 	err = validateBorderStyleDict(xRefTable, d, dictName, "BS", OPTIONAL, sinceVersion)
 	if err != nil {
@@ -429,9 +429,9 @@ func validateAnnotationDictFreeTextPart2(xRefTable *pdf.XRefTable, d pdf.Dict, d
 	}
 
 	// LE, optional, name, since V1.6
-	sinceVersion = pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "LE", pdf.V16, pdf.V14)
+	if err != nil {
+		return err
 	}
 	_, err = validateNameEntry(xRefTable, d, dictName, "LE", OPTIONAL, sinceVersion, nil)
 
@@ -483,9 +483,9 @@ func validateAnnotationDictLine(xRefTable *pdf.XRefTable, d pdf.Dict, dictName s
 	}
 
 	// LE, optional, name array, since V1.4, len:2
-	sinceVersion := pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "LE", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateNameArrayEntry(xRefTable, d, dictName, "LE", OPTIONAL, sinceVersion, func(a pdf.Array) bool { return len(a) == 2 })
 	if err != nil {
@@ -557,9 +557,9 @@ func validateAnnotationDictCircleOrSquare(xRefTable *pdf.XRefTable, d pdf.Dict,
 	}
 
 	// IC, optional, array, since V1.4
-	sinceVersion := pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "IC", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateNumberArrayEntry(xRefTable, d, dictName, "IC", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -1258,9 +1258,9 @@ func validateMarkupAnnotation(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// Subj, optional, text string, since V1.5
-	sinceVersion := pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "Subj", pdf.V15, pdf.V14)
+	if err != nil {
+		return err
 	}
 	_, err = validateStringEntry(xRefTable, d, dictName, "Subj", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -1423,9 +1423,9 @@ func validateAnnotationDictConcrete(xRefTable *pdf.XRefTable, d pdf.Dict, dictNa
 
 	// OC, optional, content group dict or content membership dict, since V1.5
 	// Specifying the optional content properties for the annotation.
-	sinceVersion := pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "OC", pdf.V15, pdf.V13)
+	if err != nil {
+		return err
 	}
 	if err := validateOptionalContent(xRefTable, d, dictName, "OC", OPTIONAL, sinceVersion); err != nil {
 		return err
@@ -1601,7 +1601,11 @@ func validatePagesAnnotations(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 			}
 
 		case "Page":
-			err = validatePageAnnotations(xRefTable, d)
+			objNr := 0
+			if ir, ok := v.(pdf.IndirectRef); ok {
+				objNr = ir.ObjectNumber.Value()
+			}
+			err = collect(xRefTable, objNr, validatePageAnnotations(xRefTable, d))
 			if err != nil {
 				return err
 			}
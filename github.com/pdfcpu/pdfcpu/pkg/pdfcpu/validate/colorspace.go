@@ -390,9 +390,9 @@ func validateDeviceNColorSpaceAttributesDict(xRefTable *pdf.XRefTable, o pdf.Obj
 		}
 	}
 
-	sinceVersion := pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "Process", pdf.V16, pdf.V13)
+	if err != nil {
+		return err
 	}
 
 	d1, err = validateDictEntry(xRefTable, d, dictName, "Process", OPTIONAL, sinceVersion, nil)
@@ -74,10 +74,12 @@ func validateDestinationArray(xRefTable *pdf.XRefTable, a pdf.Array) error {
 	switch len(a) {
 
 	case 2:
-		if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-			nameErr = !pdf.MemberOf(name.Value(), []string{"Fit", "FitB", "FitH"})
-		} else {
-			nameErr = !pdf.MemberOf(name.Value(), []string{"Fit", "FitB"})
+		nameErr = !pdf.MemberOf(name.Value(), []string{"Fit", "FitB"})
+		if nameErr && name.Value() == "FitH" && xRefTable.ValidationMode != pdf.ValidationStrict {
+			nameErr = false
+			if err = relaxed(xRefTable, 0, "validateDestinationArray: FitH without top"); err != nil {
+				return err
+			}
 		}
 
 	case 3:
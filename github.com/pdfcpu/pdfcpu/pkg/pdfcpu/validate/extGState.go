@@ -753,19 +753,19 @@ func validateExtGStateDictPart2(xRefTable *pdf.XRefTable, d pdf.Dict, dictName s
 func validateExtGStateDictPart3(xRefTable *pdf.XRefTable, d pdf.Dict, dictName string) error {
 
 	// BM, name or array, optional, since V1.4
-	sinceVersion := pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "BM", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
-	err := validateBlendModeEntry(xRefTable, d, dictName, "BM", OPTIONAL, sinceVersion)
+	err = validateBlendModeEntry(xRefTable, d, dictName, "BM", OPTIONAL, sinceVersion)
 	if err != nil {
 		return err
 	}
 
 	// SMask, dict or name, optional, since V1.4
-	sinceVersion = pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "SMask", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	err = validateSoftMaskEntry(xRefTable, d, dictName, "SMask", OPTIONAL, sinceVersion)
 	if err != nil {
@@ -773,9 +773,9 @@ func validateExtGStateDictPart3(xRefTable *pdf.XRefTable, d pdf.Dict, dictName s
 	}
 
 	// CA, number, optional, since V1.4, current stroking alpha constant, see 11.3.7.2 and 11.6.4.4
-	sinceVersion = pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "CA", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateNumberEntry(xRefTable, d, dictName, "CA", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -783,9 +783,9 @@ func validateExtGStateDictPart3(xRefTable *pdf.XRefTable, d pdf.Dict, dictName s
 	}
 
 	// ca, number, optional, since V1.4, same as CA but for nonstroking operations.
-	sinceVersion = pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "ca", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateNumberEntry(xRefTable, d, dictName, "ca", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -793,9 +793,9 @@ func validateExtGStateDictPart3(xRefTable *pdf.XRefTable, d pdf.Dict, dictName s
 	}
 
 	// AIS, alpha source flag "alpha is shape", boolean, optional, since V1.4
-	sinceVersion = pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "AIS", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateBooleanEntry(xRefTable, d, dictName, "AIS", OPTIONAL, sinceVersion, nil)
 	if err != nil {
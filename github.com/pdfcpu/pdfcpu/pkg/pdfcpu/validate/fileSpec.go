@@ -272,10 +272,14 @@ func validateFileSpecDictEntriesEFAndRF(xRefTable *pdf.XRefTable, efDict, rfDict
 
 func validateFileSpecDictType(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
-	if d.Type() == nil || (*d.Type() != "Filespec" && (xRefTable.ValidationMode == pdf.ValidationRelaxed && *d.Type() != "F")) {
+	if d.Type() == nil || (*d.Type() != "Filespec" && (xRefTable.ValidationMode != pdf.ValidationStrict && *d.Type() != "F")) {
 		return errors.New("pdfcpu: validateFileSpecDictType: missing type: FileSpec")
 	}
 
+	if *d.Type() == "F" && xRefTable.ValidationMode != pdf.ValidationStrict {
+		return relaxed(xRefTable, 0, "validateFileSpecDictType: type F instead of Filespec")
+	}
+
 	return nil
 }
 
@@ -299,12 +303,17 @@ func validateFileSpecDictEFAndRF(xRefTable *pdf.XRefTable, d pdf.Dict, dictName
 
 	// Type, required if EF present, name
 	validate := func(s string) bool {
-		return s == "Filespec" || (xRefTable.ValidationMode == pdf.ValidationRelaxed && s == "F")
+		return s == "Filespec" || (xRefTable.ValidationMode != pdf.ValidationStrict && s == "F")
 	}
-	_, err = validateNameEntry(xRefTable, d, dictName, "Type", efDict != nil, pdf.V10, validate)
+	t, err := validateNameEntry(xRefTable, d, dictName, "Type", efDict != nil, pdf.V10, validate)
 	if err != nil {
 		return err
 	}
+	if t != nil && t.Value() == "F" {
+		if err = relaxed(xRefTable, 0, "validateFileSpecDictEFAndRF: type F instead of Filespec"); err != nil {
+			return err
+		}
+	}
 
 	// if EF present, Type "FileSpec" is required
 	if efDict != nil {
@@ -352,9 +361,9 @@ func validateFileSpecDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// UF, optional, text string
-	sinceVersion := pdf.V17
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "UF", pdf.V17, pdf.V14)
+	if err != nil {
+		return err
 	}
 	_, err = validateStringEntry(xRefTable, d, dictName, "UF", OPTIONAL, sinceVersion, validateFileSpecString)
 	if err != nil {
@@ -379,9 +388,9 @@ func validateFileSpecDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// Desc, optional, text string, since V1.6
-	sinceVersion = pdf.V16
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V10
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "Desc", pdf.V16, pdf.V10)
+	if err != nil {
+		return err
 	}
 	_, err = validateStringEntry(xRefTable, d, dictName, "Desc", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -17,7 +17,6 @@ limitations under the License.
 package validate
 
 import (
-	"github.com/pdfcpu/pdfcpu/pkg/log"
 	pdf "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pkg/errors"
 )
@@ -110,11 +109,12 @@ func validateFontDescriptorType(xRefTable *pdf.XRefTable, d pdf.Dict) (err error
 
 	if dictType == nil {
 
-		if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-			log.Validate.Println("validateFontDescriptor: missing entry \"Type\"")
-		} else {
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
 			return errors.New("pdfcpu: validateFontDescriptor: missing entry \"Type\"")
 		}
+		if err = relaxed(xRefTable, 0, "validateFontDescriptor: missing entry \"Type\""); err != nil {
+			return err
+		}
 
 	}
 
@@ -137,27 +137,27 @@ func validateFontDescriptorPart1(xRefTable *pdf.XRefTable, d pdf.Dict, dictName,
 		return err
 	}
 
-	sinceVersion := pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "FontFamily", pdf.V15, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateStringEntry(xRefTable, d, dictName, "FontFamily", OPTIONAL, sinceVersion, nil)
 	if err != nil {
 		return err
 	}
 
-	sinceVersion = pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "FontStretch", pdf.V15, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateNameEntry(xRefTable, d, dictName, "FontStretch", OPTIONAL, sinceVersion, nil)
 	if err != nil {
 		return err
 	}
 
-	sinceVersion = pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "FontWeight", pdf.V15, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateNumberEntry(xRefTable, d, dictName, "FontWeight", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -206,9 +206,9 @@ func validateFontDescriptorPart2(xRefTable *pdf.XRefTable, d pdf.Dict, dictName,
 		return err
 	}
 
-	required := fontDictType != "Type3"
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = false
+	required, err := relaxedRequired(xRefTable, 0, d, dictName, "StemV", fontDictType != "Type3")
+	if err != nil {
+		return err
 	}
 	_, err = validateNumberEntry(xRefTable, d, dictName, "StemV", required, pdf.V10, nil)
 	if err != nil {
@@ -384,9 +384,9 @@ func validateTrueTypeFontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// FirstChar, required, integer
-	required := REQUIRED
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = OPTIONAL
+	required, err := relaxedRequired(xRefTable, 0, d, dictName, "FirstChar", REQUIRED)
+	if err != nil {
+		return err
 	}
 	_, err = validateIntegerEntry(xRefTable, d, dictName, "FirstChar", required, pdf.V10, nil)
 	if err != nil {
@@ -394,9 +394,9 @@ func validateTrueTypeFontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// LastChar, required, integer
-	required = REQUIRED
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = OPTIONAL
+	required, err = relaxedRequired(xRefTable, 0, d, dictName, "LastChar", REQUIRED)
+	if err != nil {
+		return err
 	}
 	_, err = validateIntegerEntry(xRefTable, d, dictName, "LastChar", required, pdf.V10, nil)
 	if err != nil {
@@ -404,9 +404,9 @@ func validateTrueTypeFontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// Widths, array of numbers.
-	required = REQUIRED
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = OPTIONAL
+	required, err = relaxedRequired(xRefTable, 0, d, dictName, "Widths", REQUIRED)
+	if err != nil {
+		return err
 	}
 	_, err = validateNumberArrayEntry(xRefTable, d, dictName, "Widths", required, pdf.V10, nil)
 	if err != nil {
@@ -414,9 +414,9 @@ func validateTrueTypeFontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// FontDescriptor, required, dictionary
-	required = REQUIRED
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = OPTIONAL
+	required, err = relaxedRequired(xRefTable, 0, d, dictName, "FontDescriptor", REQUIRED)
+	if err != nil {
+		return err
 	}
 	err = validateFontDescriptor(xRefTable, d, dictName, "TrueType", required, pdf.V10)
 	if err != nil {
@@ -669,9 +669,11 @@ func validateType1FontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 		return err
 	}
 
-	required := xRefTable.Version() >= pdf.V15 || !validateStandardType1Font((*fontName).Value())
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = !validateStandardType1Font((*fontName).Value())
+	required := !validateStandardType1Font((*fontName).Value())
+	if !required && xRefTable.Version() >= pdf.V15 {
+		if required, err = relaxedRequired(xRefTable, 0, d, dictName, "FirstChar", REQUIRED); err != nil {
+			return err
+		}
 	}
 	// FirstChar,  required except for standard 14 fonts. since 1.5 always required, integer
 	fc, err := validateIntegerEntry(xRefTable, d, dictName, "FirstChar", required, pdf.V10, nil)
@@ -681,10 +683,10 @@ func validateType1FontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 	if !required && fc != nil {
 		// For the standard 14 fonts, the entries FirstChar, LastChar, Widths and FontDescriptor shall either all be present or all be absent.
-		if xRefTable.ValidationMode == pdf.ValidationStrict {
-			required = true
-		} else {
-			// relaxed: do nothing
+		for _, entryName := range []string{"LastChar", "Widths", "FontDescriptor"} {
+			if required, err = relaxedRequired(xRefTable, 0, d, dictName, entryName, REQUIRED); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -906,9 +908,9 @@ func validateType3FontDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	}
 
 	// FontDescriptor, required since version 1.5 for tagged PDF documents, dict
-	sinceVersion := pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "FontDescriptor", pdf.V15, pdf.V13)
+	if err != nil {
+		return err
 	}
 	err = validateFontDescriptor(xRefTable, d, dictName, "Type3", xRefTable.Tagged, sinceVersion)
 	if err != nil {
@@ -940,9 +942,9 @@ func validateFontDict(xRefTable *pdf.XRefTable, o pdf.Object) (err error) {
 		return err
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 		if len(d) == 0 {
-			return nil
+			return relaxed(xRefTable, objNumber(o), "validateFontDict: empty font dict")
 		}
 	}
 
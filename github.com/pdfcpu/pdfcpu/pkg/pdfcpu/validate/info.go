@@ -38,15 +38,27 @@ func handleDefault(xRefTable *pdf.XRefTable, o pdf.Object) (string, error) {
 		return "", err
 	}
 
-	_, err = xRefTable.Dereference(o)
-	return "", err
+	if _, err = xRefTable.Dereference(o); err != nil {
+		return "", err
+	}
+
+	return "", relaxed(xRefTable, xRefTable.Info.ObjectNumber.Value(), "handleDefault: property value is no text string")
 }
 
 func validateInfoDictDate(xRefTable *pdf.XRefTable, o pdf.Object) (s string, err error) {
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		return validateString(xRefTable, o, nil)
+	if xRefTable.ValidationMode == pdf.ValidationStrict {
+		return validateDateObject(xRefTable, o, pdf.V10)
+	}
+
+	if s, err = validateString(xRefTable, o, nil); err != nil || s == "" {
+		return s, err
 	}
-	return validateDateObject(xRefTable, o, pdf.V10)
+
+	if _, ok := pdf.DateTime(s); !ok {
+		err = relaxed(xRefTable, xRefTable.Info.ObjectNumber.Value(), "validateInfoDictDate: <%s> invalid date", s)
+	}
+
+	return s, err
 }
 
 func validateInfoDictTrapped(xRefTable *pdf.XRefTable, o pdf.Object) error {
@@ -55,19 +67,26 @@ func validateInfoDictTrapped(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
 	validate := func(s string) bool { return pdf.MemberOf(s, []string{"True", "False", "Unknown"}) }
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 		validate = func(s string) bool {
 			return pdf.MemberOf(s, []string{"True", "False", "Unknown", "true", "false", "unknown"})
 		}
 	}
 
-	_, err := xRefTable.DereferenceName(o, sinceVersion, validate)
+	objNr := xRefTable.Info.ObjectNumber.Value()
+
+	n, err := xRefTable.DereferenceName(o, sinceVersion, validate)
 	if err == nil {
+		if s := n.Value(); s != "True" && s != "False" && s != "Unknown" {
+			return relaxed(xRefTable, objNr, "validateInfoDictTrapped: invalid name %s", s)
+		}
 		return nil
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		_, err = xRefTable.DereferenceBoolean(o, sinceVersion)
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
+		if _, err = xRefTable.DereferenceBoolean(o, sinceVersion); err == nil {
+			return relaxed(xRefTable, objNr, "validateInfoDictTrapped: boolean instead of name")
+		}
 	}
 
 	return err
@@ -705,6 +705,8 @@ func validateNameTree(xRefTable *pdf.XRefTable, name string, d pdf.Dict, root bo
 			return "", "", nil, errors.New("pdfcpu: validateNameTree: missing \"Kids\" array")
 		}
 
+		kids := make(pdf.Array, 0, len(a))
+
 		for _, o := range a {
 
 			kid, ok := o.(pdf.IndirectRef)
@@ -712,14 +714,26 @@ func validateNameTree(xRefTable *pdf.XRefTable, name string, d pdf.Dict, root bo
 				return "", "", nil, errors.New("pdfcpu: validateNameTree: corrupt kid, should be indirect reference")
 			}
 
+			if ok, err = xRefTable.EnterObject(kid, "validateNameTree"); err != nil {
+				return "", "", nil, err
+			}
+			if !ok {
+				// Drop the kid closing the cycle.
+				continue
+			}
+
+			kids = append(kids, kid)
+
 			d, err := xRefTable.DereferenceDict(kid)
 			if err != nil {
+				xRefTable.LeaveObject(kid)
 				return "", "", nil, err
 			}
 
 			var kminKid string
 			var kidNode *pdf.Node
 			kminKid, kmax, kidNode, err = validateNameTree(xRefTable, name, d, false)
+			xRefTable.LeaveObject(kid)
 			if err != nil {
 				return "", "", nil, err
 			}
@@ -730,6 +744,10 @@ func validateNameTree(xRefTable *pdf.XRefTable, name string, d pdf.Dict, root bo
 			node.Kids = append(node.Kids, kidNode)
 		}
 
+		if len(kids) < len(a) {
+			d.Update("Kids", kids)
+		}
+
 	} else {
 
 		// Leaf node
@@ -34,6 +34,55 @@ const (
 	OPTIONAL = false
 )
 
+// objNumber returns the object number of o if o is an indirect reference and 0 otherwise.
+func objNumber(o pdf.Object) int {
+	if ir, ok := o.(pdf.IndirectRef); ok {
+		return ir.ObjectNumber.Value()
+	}
+	return 0
+}
+
+// relaxed reports a violation of object objNr tolerated outside strict validation mode.
+func relaxed(xRefTable *pdf.XRefTable, objNr int, format string, args ...interface{}) error {
+	return xRefTable.Report(pdf.SeverityWarning, objNr, fmt.Sprintf(format, args...))
+}
+
+// relaxedVersion returns the version entryName of d is supported since obeying the validation mode.
+// Outside strict mode this is relaxedSince and a present entry only admitted by it gets reported.
+func relaxedVersion(xRefTable *pdf.XRefTable, objNr int, d pdf.Dict, dictName, entryName string, sinceVersion, relaxedSince pdf.Version) (pdf.Version, error) {
+
+	if xRefTable.ValidationMode == pdf.ValidationStrict {
+		return sinceVersion, nil
+	}
+
+	if o, found := d.Find(entryName); found && o != nil {
+		if v := xRefTable.Version(); v < sinceVersion && v >= relaxedSince {
+			if err := relaxed(xRefTable, objNr, "dict=%s entry=%s: unsupported in version %s", dictName, entryName, xRefTable.VersionString()); err != nil {
+				return relaxedSince, err
+			}
+		}
+	}
+
+	return relaxedSince, nil
+}
+
+// relaxedRequired returns whether entryName of d is required obeying the validation mode.
+// Outside strict mode a required entry is optional and a missing one gets reported.
+func relaxedRequired(xRefTable *pdf.XRefTable, objNr int, d pdf.Dict, dictName, entryName string, required bool) (bool, error) {
+
+	if !required || xRefTable.ValidationMode == pdf.ValidationStrict {
+		return required, nil
+	}
+
+	if o, found := d.Find(entryName); !found || o == nil {
+		if err := relaxed(xRefTable, objNr, "dict=%s missing required entry=%s", dictName, entryName); err != nil {
+			return OPTIONAL, err
+		}
+	}
+
+	return OPTIONAL, nil
+}
+
 func validateEntry(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName string, required bool, sinceVersion pdf.Version) (pdf.Object, error) {
 
 	o, found := d.Find(entryName)
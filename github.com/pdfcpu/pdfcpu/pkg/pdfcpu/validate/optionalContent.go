@@ -396,8 +396,9 @@ func validateOCProperties(xRefTable *pdf.XRefTable, rootDict pdf.Dict, required
 
 	// => 8.11.4 Configuring Optional Content
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err := relaxedVersion(xRefTable, 0, rootDict, "rootDict", "OCProperties", sinceVersion, pdf.V14)
+	if err != nil {
+		return err
 	}
 
 	d, err := validateDictEntry(xRefTable, rootDict, "rootDict", "OCProperties", required, sinceVersion, nil)
@@ -408,9 +409,9 @@ func validateOCProperties(xRefTable *pdf.XRefTable, rootDict pdf.Dict, required
 	dictName := "optContentPropertiesDict"
 
 	// "OCGs" required array of already written indRefs
-	r := true
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		r = false
+	r, err := relaxedRequired(xRefTable, 0, d, dictName, "OCGs", REQUIRED)
+	if err != nil {
+		return err
 	}
 	_, err = validateIndRefArrayEntry(xRefTable, d, dictName, "OCGs", r, sinceVersion, nil)
 	if err != nil {
@@ -77,17 +77,34 @@ func validateOutlineItemDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 	return validateActionOrDestination(xRefTable, d, dictName, pdf.V11)
 }
 
-func validateOutlineTree(xRefTable *pdf.XRefTable, first, last *pdf.IndirectRef) error {
+func validateOutlineTree(xRefTable *pdf.XRefTable, parent pdf.Dict, first, last *pdf.IndirectRef) error {
 
 	var (
 		d         pdf.Dict
 		objNumber int
+		ok        bool
 		err       error
 	)
 
 	// Process linked list of outline items.
 	for ir := first; ir != nil; ir = d.IndirectRefEntry("Next") {
 
+		ok, err = xRefTable.EnterObject(*ir, "validateOutlineTree")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Drop the link closing the cycle.
+			if d == nil {
+				parent.Delete("First")
+				parent.Delete("Last")
+			} else {
+				d.Delete("Next")
+			}
+			break
+		}
+		defer xRefTable.LeaveObject(*ir)
+
 		objNumber = ir.ObjectNumber.Value()
 
 		// outline item dict
@@ -112,10 +129,15 @@ func validateOutlineTree(xRefTable *pdf.XRefTable, first, last *pdf.IndirectRef)
 			continue
 		}
 
-		if firstChild != nil && (xRefTable.ValidationMode == pdf.ValidationRelaxed ||
+		if firstChild != nil && (xRefTable.ValidationMode != pdf.ValidationStrict ||
 			xRefTable.ValidationMode == pdf.ValidationStrict && lastChild != nil) {
+			if lastChild == nil {
+				if err = relaxed(xRefTable, objNumber, "validateOutlineTree: missing entry Last"); err != nil {
+					return err
+				}
+			}
 			// Recurse into subtree.
-			err = validateOutlineTree(xRefTable, firstChild, lastChild)
+			err = validateOutlineTree(xRefTable, d, firstChild, lastChild)
 			if err != nil {
 				return err
 			}
@@ -126,8 +148,11 @@ func validateOutlineTree(xRefTable *pdf.XRefTable, first, last *pdf.IndirectRef)
 
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationStrict && objNumber != last.ObjectNumber.Value() {
-		return errors.Errorf("pdfcpu: validateOutlineTree: corrupted child list %d <> %d\n", objNumber, last.ObjectNumber)
+	if last != nil && objNumber != last.ObjectNumber.Value() {
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
+			return errors.Errorf("pdfcpu: validateOutlineTree: corrupted child list %d <> %d\n", objNumber, last.ObjectNumber)
+		}
+		return relaxed(xRefTable, objNumber, "validateOutlineTree: corrupted child list %d <> %d", objNumber, last.ObjectNumber)
 	}
 
 	return nil
@@ -164,9 +189,14 @@ func validateOutlines(xRefTable *pdf.XRefTable, rootDict pdf.Dict, required bool
 		return nil
 	}
 
-	if xRefTable.ValidationMode == pdf.ValidationStrict && last == nil {
-		return errors.New("pdfcpu: validateOutlines: corrupted, root needs both first and last")
+	if last == nil {
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
+			return errors.New("pdfcpu: validateOutlines: corrupted, root needs both first and last")
+		}
+		if err = relaxed(xRefTable, ir.ObjectNumber.Value(), "validateOutlines: missing entry Last"); err != nil {
+			return err
+		}
 	}
 
-	return validateOutlineTree(xRefTable, first, last)
+	return validateOutlineTree(xRefTable, d, first, last)
 }
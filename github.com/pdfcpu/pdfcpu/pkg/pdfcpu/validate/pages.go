@@ -24,6 +24,8 @@ import (
 
 func validateResourceDict(xRefTable *pdf.XRefTable, o pdf.Object) (hasResources bool, err error) {
 
+	objNr := objNumber(o)
+
 	d, err := xRefTable.DereferenceDict(o)
 	if err != nil || d == nil {
 		return false, err
@@ -50,7 +52,7 @@ func validateResourceDict(xRefTable *pdf.XRefTable, o pdf.Object) (hasResources
 	}
 
 	allowedResDictKeys := []string{"ExtGState", "Font", "XObject", "Properties", "ColorSpace", "Pattern", "ProcSet", "Shading"}
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 		allowedResDictKeys = append(allowedResDictKeys, "Encoding")
 		allowedResDictKeys = append(allowedResDictKeys, "ProcSets")
 	}
@@ -60,6 +62,12 @@ func validateResourceDict(xRefTable *pdf.XRefTable, o pdf.Object) (hasResources
 	for k := range d {
 		if !pdf.MemberOf(k, allowedResDictKeys) {
 			d.Delete(k)
+			continue
+		}
+		if k == "Encoding" || k == "ProcSets" {
+			if err = relaxed(xRefTable, objNr, "validateResourceDict: unexpected entry %s", k); err != nil {
+				return false, err
+			}
 		}
 	}
 
@@ -241,8 +249,9 @@ func validatePageEntryRotate(xRefTable *pdf.XRefTable, d pdf.Dict, required bool
 
 func validatePageEntryGroup(xRefTable *pdf.XRefTable, d pdf.Dict, required bool, sinceVersion pdf.Version) error {
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, "pageDict", "Group", sinceVersion, pdf.V13)
+	if err != nil {
+		return err
 	}
 
 	d1, err := validateDictEntry(xRefTable, d, "pageDict", "Group", required, sinceVersion, nil)
@@ -462,10 +471,11 @@ func validatePageEntryTabs(xRefTable *pdf.XRefTable, d pdf.Dict, required bool,
 
 	validateTabs := func(s string) bool { return pdf.MemberOf(s, []string{"R", "C", "S", "A", "W"}) }
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V14
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, "pagesDict", "Tabs", sinceVersion, pdf.V14)
+	if err != nil {
+		return err
 	}
-	_, err := validateNameEntry(xRefTable, d, "pagesDict", "Tabs", required, sinceVersion, validateTabs)
+	_, err = validateNameEntry(xRefTable, d, "pagesDict", "Tabs", required, sinceVersion, validateTabs)
 
 	return err
 }
@@ -745,7 +755,7 @@ func validatePageEntryVP(xRefTable *pdf.XRefTable, d pdf.Dict, required bool, si
 	return nil
 }
 
-func validatePageDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNumber, genNumber int, hasResources, hasMediaBox bool) error {
+func validatePageDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNumber, genNumber, pageNr int, hasResources, hasMediaBox bool) error {
 
 	dictName := "pageDict"
 
@@ -753,28 +763,32 @@ func validatePageDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNumber, genNumber
 		return errors.New("pdfcpu: validatePageDict: missing parent")
 	}
 
-	// Contents
-	hasContents, err := validatePageContents(xRefTable, d)
-	if err != nil {
-		return err
-	}
+	// Content streams and resources of pages not selected are not validated.
+	if xRefTable.ValidatePages == nil || xRefTable.ValidatePages[pageNr] {
 
-	// Resources
-	err = validatePageResources(xRefTable, d, hasResources, hasContents)
-	if err != nil {
-		return err
+		// Contents
+		hasContents, err := validatePageContents(xRefTable, d)
+		if err != nil {
+			return errors.Wrapf(err, "page %d", pageNr)
+		}
+
+		// Resources
+		err = validatePageResources(xRefTable, d, hasResources, hasContents)
+		if err != nil {
+			return errors.Wrapf(err, "page %d", pageNr)
+		}
 	}
 
 	// MediaBox
-	_, err = validatePageEntryMediaBox(xRefTable, d, !hasMediaBox, pdf.V10)
+	_, err := validatePageEntryMediaBox(xRefTable, d, !hasMediaBox, pdf.V10)
 	if err != nil {
 		return err
 	}
 
 	// PieceInfo
-	sinceVersion := pdf.V13
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V10
+	sinceVersion, err := relaxedVersion(xRefTable, objNumber, d, dictName, "PieceInfo", pdf.V13, pdf.V10)
+	if err != nil {
+		return err
 	}
 	hasPieceInfo, err := validatePieceInfo(xRefTable, d, dictName, "PieceInfo", OPTIONAL, sinceVersion)
 	if err != nil {
@@ -787,8 +801,13 @@ func validatePageDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNumber, genNumber
 		return err
 	}
 
-	if hasPieceInfo && lm == nil && xRefTable.ValidationMode == pdf.ValidationStrict {
-		return errors.New("pdfcpu: validatePageDict: missing \"LastModified\" (required by \"PieceInfo\")")
+	if hasPieceInfo && lm == nil {
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
+			return errors.New("pdfcpu: validatePageDict: missing \"LastModified\" (required by \"PieceInfo\")")
+		}
+		if err = relaxed(xRefTable, objNumber, "validatePageDict: missing \"LastModified\" (required by \"PieceInfo\")"); err != nil {
+			return err
+		}
 	}
 
 	// AA
@@ -901,7 +920,7 @@ func validateResources(xRefTable *pdf.XRefTable, d pdf.Dict) (hasResources bool,
 	return validateResourceDict(xRefTable, o)
 }
 
-func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber int, hasResources, hasMediaBox bool) error {
+func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber int, pageNr *int, hasResources, hasMediaBox bool) error {
 
 	// Resources and Mediabox are inherited.
 	//var dHasResources, dHasMediaBox bool
@@ -924,9 +943,12 @@ func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber in
 		return errors.New("pdfcpu: validatePagesDict: corrupt \"Kids\" entry")
 	}
 
+	kids := make(pdf.Array, 0, len(kidsArray))
+
 	for _, o := range kidsArray {
 
 		if o == nil {
+			kids = append(kids, o)
 			continue
 		}
 
@@ -938,48 +960,66 @@ func validatePagesDict(xRefTable *pdf.XRefTable, d pdf.Dict, objNr, genNumber in
 
 		log.Validate.Printf("validatePagesDict: PageNode: %s\n", ir)
 
-		objNumber := ir.ObjectNumber.Value()
-		genNumber := ir.GenerationNumber.Value()
-
-		pageNodeDict, err := xRefTable.DereferenceDict(ir)
+		ok, err := xRefTable.EnterObject(ir, "validatePagesDict")
 		if err != nil {
 			return err
 		}
-
-		// Validate this kid's parent.
-		parentIndRef := pageNodeDict.IndirectRefEntry("Parent")
-		if parentIndRef.ObjectNumber.Value() != objNr {
-			return errors.New("pdfcpu: validatePagesDict: corrupt parent node")
+		if !ok {
+			// Drop the kid closing the cycle.
+			continue
 		}
 
-		dictType, err := dictTypeForPageNodeDict(pageNodeDict)
+		kids = append(kids, o)
+
+		err = validatePageNodeDict(xRefTable, ir, objNr, pageNr, hasResources, hasMediaBox)
+		xRefTable.LeaveObject(ir)
 		if err != nil {
 			return err
 		}
+	}
 
-		switch dictType {
+	if len(kids) < len(kidsArray) {
+		d.Update("Kids", kids)
+	}
 
-		case "Pages":
-			// Recurse over pagetree
-			err = validatePagesDict(xRefTable, pageNodeDict, objNumber, genNumber, hasResources, hasMediaBox)
-			if err != nil {
-				return err
-			}
+	return nil
+}
 
-		case "Page":
-			err = validatePageDict(xRefTable, pageNodeDict, objNumber, genNumber, hasResources, hasMediaBox)
-			if err != nil {
-				return err
-			}
+func validatePageNodeDict(xRefTable *pdf.XRefTable, ir pdf.IndirectRef, parentObjNr int, pageNr *int, hasResources, hasMediaBox bool) error {
 
-		default:
-			return errors.Errorf("pdfcpu: validatePagesDict: Unexpected dict type: %s", dictType)
+	objNumber := ir.ObjectNumber.Value()
+	genNumber := ir.GenerationNumber.Value()
 
-		}
+	pageNodeDict, err := xRefTable.DereferenceDict(ir)
+	if err != nil {
+		return err
+	}
 
+	// Validate this kid's parent.
+	parentIndRef := pageNodeDict.IndirectRefEntry("Parent")
+	if parentIndRef == nil || parentIndRef.ObjectNumber.Value() != parentObjNr {
+		return errors.New("pdfcpu: validatePagesDict: corrupt parent node")
 	}
 
-	return nil
+	dictType, err := dictTypeForPageNodeDict(pageNodeDict)
+	if err != nil {
+		return err
+	}
+
+	switch dictType {
+
+	case "Pages":
+		// Recurse over pagetree
+		return validatePagesDict(xRefTable, pageNodeDict, objNumber, genNumber, pageNr, hasResources, hasMediaBox)
+
+	case "Page":
+		*pageNr++
+		err := validatePageDict(xRefTable, pageNodeDict, objNumber, genNumber, *pageNr, hasResources, hasMediaBox)
+		return collect(xRefTable, objNumber, err)
+
+	}
+
+	return errors.Errorf("pdfcpu: validatePagesDict: Unexpected dict type: %s", dictType)
 }
 
 func validatePages(xRefTable *pdf.XRefTable, rootDict pdf.Dict) (pdf.Dict, error) {
@@ -1004,8 +1044,15 @@ func validatePages(xRefTable *pdf.XRefTable, rootDict pdf.Dict) (pdf.Dict, error
 		return nil, errors.New("pdfcpu: validatePagesDict: cannot dereference pageNodeDict")
 	}
 
+	// The root page node may be referenced by its descendants.
+	if _, err = xRefTable.EnterObject(*ir, "validatePages"); err != nil {
+		return nil, err
+	}
+	defer xRefTable.LeaveObject(*ir)
+
 	// Process page node tree.
-	err = validatePagesDict(xRefTable, rootPageNodeDict, objNumber, genNumber, false, false)
+	pageNr := 0
+	err = validatePagesDict(xRefTable, rootPageNodeDict, objNumber, genNumber, &pageNr, false, false)
 	if err != nil {
 		return nil, err
 	}
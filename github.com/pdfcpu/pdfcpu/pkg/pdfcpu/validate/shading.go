@@ -218,13 +218,18 @@ func validateCoonsPatchMeshesDict(xRefTable *pdf.XRefTable, dict pdf.Dict) error
 	}
 
 	validateBitsPerFlag := func(i int) bool { return i >= 0 && i <= 3 }
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 		validateBitsPerFlag = func(i int) bool { return i >= 0 && i <= 8 }
 	}
-	_, err = validateIntegerEntry(xRefTable, dict, dictName, "BitsPerFlag", REQUIRED, pdf.V10, validateBitsPerFlag)
+	bpf, err := validateIntegerEntry(xRefTable, dict, dictName, "BitsPerFlag", REQUIRED, pdf.V10, validateBitsPerFlag)
 	if err != nil {
 		return err
 	}
+	if bpf.Value() > 3 {
+		if err = relaxed(xRefTable, 0, "dict=%s entry=BitsPerFlag: invalid value %d", dictName, bpf.Value()); err != nil {
+			return err
+		}
+	}
 
 	_, err = validateNumberArrayEntry(xRefTable, dict, dictName, "Decode", REQUIRED, pdf.V10, nil)
 	if err != nil {
@@ -249,13 +254,18 @@ func validateTensorProductPatchMeshesDict(xRefTable *pdf.XRefTable, dict pdf.Dic
 	}
 
 	validateBitsPerFlag := func(i int) bool { return i >= 0 && i <= 3 }
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 		validateBitsPerFlag = func(i int) bool { return i >= 0 && i <= 8 }
 	}
-	_, err = validateIntegerEntry(xRefTable, dict, dictName, "BitsPerFlag", REQUIRED, pdf.V10, validateBitsPerFlag)
+	bpf, err := validateIntegerEntry(xRefTable, dict, dictName, "BitsPerFlag", REQUIRED, pdf.V10, validateBitsPerFlag)
 	if err != nil {
 		return err
 	}
+	if bpf.Value() > 3 {
+		if err = relaxed(xRefTable, 0, "dict=%s entry=BitsPerFlag: invalid value %d", dictName, bpf.Value()); err != nil {
+			return err
+		}
+	}
 
 	_, err = validateNumberArrayEntry(xRefTable, dict, dictName, "Decode", REQUIRED, pdf.V10, nil)
 	if err != nil {
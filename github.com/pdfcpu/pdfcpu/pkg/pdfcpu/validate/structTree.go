@@ -88,12 +88,11 @@ func validateObjectReferenceDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 	// Obj: required, indirect reference
 	ir := d.IndirectRefEntry("Obj")
-	if xRefTable.ValidationMode == pdf.ValidationStrict && ir == nil {
-		return errors.New("pdfcpu: validateObjectReferenceDict: missing required entry \"Obj\"")
-	}
-
 	if ir == nil {
-		return nil
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
+			return errors.New("pdfcpu: validateObjectReferenceDict: missing required entry \"Obj\"")
+		}
+		return relaxed(xRefTable, 0, "validateObjectReferenceDict: missing required entry \"Obj\"")
 	}
 
 	obj, err := xRefTable.Dereference(*ir)
@@ -110,60 +109,93 @@ func validateObjectReferenceDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 func validateStructElementDictEntryKArray(xRefTable *pdf.XRefTable, a pdf.Array) error {
 
-	for _, o := range a {
+	for i, o := range a {
 
-		o, err := xRefTable.Dereference(o)
+		ok, err := enterStructKid(xRefTable, o)
 		if err != nil {
 			return err
 		}
-
-		if o == nil {
+		if !ok {
+			// Drop the kid closing the cycle.
+			a[i] = nil
 			continue
 		}
 
-		switch o := o.(type) {
+		err = validateStructElementDictEntryKArrayEntry(xRefTable, o)
+		leaveStructKid(xRefTable, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-		case pdf.Integer:
+func validateStructElementDictEntryKArrayEntry(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
-		case pdf.Dict:
+	o, err := xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return err
+	}
+
+	switch o := o.(type) {
 
-			dictType := o.Type()
+	case pdf.Integer:
 
-			if dictType == nil || *dictType == "StructElem" {
-				err = validateStructElementDict(xRefTable, o)
-				if err != nil {
-					return err
-				}
-				break
+	case pdf.Dict:
+
+		dictType := o.Type()
+
+		if dictType == nil || *dictType == "StructElem" {
+			err = validateStructElementDict(xRefTable, o)
+			if err != nil {
+				return err
 			}
+			break
+		}
 
-			if *dictType == "MCR" {
-				err = validateMarkedContentReferenceDict(xRefTable, o)
-				if err != nil {
-					return err
-				}
-				break
+		if *dictType == "MCR" {
+			err = validateMarkedContentReferenceDict(xRefTable, o)
+			if err != nil {
+				return err
 			}
+			break
+		}
 
-			if *dictType == "OBJR" {
-				err = validateObjectReferenceDict(xRefTable, o)
-				if err != nil {
-					return err
-				}
-				break
+		if *dictType == "OBJR" {
+			err = validateObjectReferenceDict(xRefTable, o)
+			if err != nil {
+				return err
 			}
+			break
+		}
 
-			return errors.Errorf("validateStructElementDictEntryKArray: invalid dictType %s (should be \"StructElem\" or \"OBJR\" or \"MCR\")\n", *dictType)
+		return errors.Errorf("validateStructElementDictEntryKArray: invalid dictType %s (should be \"StructElem\" or \"OBJR\" or \"MCR\")\n", *dictType)
 
-		default:
-			return errors.New("validateStructElementDictEntryKArray: unsupported PDF object")
+	default:
+		return errors.New("validateStructElementDictEntryKArray: unsupported PDF object")
 
-		}
 	}
 
 	return nil
 }
 
+// enterStructKid marks the kid o of a K entry as being processed if o is an indirect reference.
+// It returns false if o closes a reference cycle.
+func enterStructKid(xRefTable *pdf.XRefTable, o pdf.Object) (bool, error) {
+	ir, ok := o.(pdf.IndirectRef)
+	if !ok {
+		return true, nil
+	}
+	return xRefTable.EnterObject(ir, "validateStructElementDict")
+}
+
+func leaveStructKid(xRefTable *pdf.XRefTable, o pdf.Object) {
+	if ir, ok := o.(pdf.IndirectRef); ok {
+		xRefTable.LeaveObject(ir)
+	}
+}
+
 func validateStructElementDictEntryK(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
 	// K: optional, the children of this structure element
@@ -239,8 +271,8 @@ func processStructElementDictPgEntry(xRefTable *pdf.XRefTable, ir pdf.IndirectRe
 
 	//logInfoWriter.Printf("known object for Pg: %v %s\n", obj, obj)
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed && o == nil {
-		return nil
+	if xRefTable.ValidationMode != pdf.ValidationStrict && o == nil {
+		return relaxed(xRefTable, ir.ObjectNumber.Value(), "processStructElementDictPgEntry: Pg object is null")
 	}
 
 	pageDict, ok := o.(pdf.Dict)
@@ -362,15 +394,21 @@ func validateStructElementDictPart1(xRefTable *pdf.XRefTable, d pdf.Dict, dictNa
 
 	// P: immediate parent, required, indirect reference
 	ir := d.IndirectRefEntry("P")
-	if xRefTable.ValidationMode != pdf.ValidationRelaxed {
-		if ir == nil {
+	if ir == nil {
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
 			return errors.Errorf("pdfcpu: validateStructElementDict: missing entry P: %s\n", d)
 		}
-
-		// Check if parent structure element exists.
-		if _, ok := xRefTable.FindTableEntryForIndRef(ir); !ok {
+		if err = relaxed(xRefTable, 0, "validateStructElementDict: missing entry P"); err != nil {
+			return err
+		}
+	} else if _, ok := xRefTable.FindTableEntryForIndRef(ir); !ok {
+		// Parent structure element does not exist.
+		if xRefTable.ValidationMode == pdf.ValidationStrict {
 			return errors.Errorf("pdfcpu: validateStructElementDict: unknown parent: %v\n", ir)
 		}
+		if err = relaxed(xRefTable, 0, "validateStructElementDict: unknown parent: %v", ir); err != nil {
+			return err
+		}
 	}
 
 	// ID: optional, byte string
@@ -390,10 +428,20 @@ func validateStructElementDictPart1(xRefTable *pdf.XRefTable, d pdf.Dict, dictNa
 
 	// K: optional, the children of this structure element.
 	if o, found := d.Find("K"); found {
-		err = validateStructElementDictEntryK(xRefTable, o)
+		ok, err := enterStructKid(xRefTable, o)
 		if err != nil {
 			return err
 		}
+		if !ok {
+			// Drop the kid closing the cycle.
+			d.Delete("K")
+		} else {
+			err = validateStructElementDictEntryK(xRefTable, o)
+			leaveStructKid(xRefTable, o)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// A: optional, attribute objects: dict or stream dict or array of these.
@@ -427,9 +475,9 @@ func validateStructElementDictPart2(xRefTable *pdf.XRefTable, d pdf.Dict, dictNa
 	}
 
 	// Lang: optional, text string, since 1.4
-	sinceVersion := pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "Lang", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	_, err = validateStringEntry(xRefTable, d, dictName, "Lang", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -470,37 +518,54 @@ func validateStructElementDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 func validateStructTreeRootDictEntryKArray(xRefTable *pdf.XRefTable, a pdf.Array) error {
 
-	for _, o := range a {
+	for i, o := range a {
 
-		o, err := xRefTable.Dereference(o)
+		ok, err := enterStructKid(xRefTable, o)
 		if err != nil {
 			return err
 		}
-
-		if o == nil {
+		if !ok {
+			// Drop the kid closing the cycle.
+			a[i] = nil
 			continue
 		}
 
-		switch o := o.(type) {
+		err = validateStructTreeRootDictEntryKArrayEntry(xRefTable, o)
+		leaveStructKid(xRefTable, o)
+		if err != nil {
+			return err
+		}
+	}
 
-		case pdf.Dict:
+	return nil
+}
 
-			dictType := o.Type()
+func validateStructTreeRootDictEntryKArrayEntry(xRefTable *pdf.XRefTable, o pdf.Object) error {
 
-			if dictType == nil || *dictType == "StructElem" {
-				err = validateStructElementDict(xRefTable, o)
-				if err != nil {
-					return err
-				}
-				break
-			}
+	o, err := xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return err
+	}
 
-			return errors.Errorf("pdfcpu: validateStructTreeRootDictEntryKArray: invalid dictType %s (should be \"StructElem\")\n", *dictType)
+	switch o := o.(type) {
 
-		default:
-			return errors.New("pdfcpu: validateStructTreeRootDictEntryKArray: unsupported PDF object")
+	case pdf.Dict:
 
+		dictType := o.Type()
+
+		if dictType == nil || *dictType == "StructElem" {
+			err = validateStructElementDict(xRefTable, o)
+			if err != nil {
+				return err
+			}
+			break
 		}
+
+		return errors.Errorf("pdfcpu: validateStructTreeRootDictEntryKArray: invalid dictType %s (should be \"StructElem\")\n", *dictType)
+
+	default:
+		return errors.New("pdfcpu: validateStructTreeRootDictEntryKArray: unsupported PDF object")
+
 	}
 
 	return nil
@@ -590,7 +655,7 @@ func processStructTreeClassMapDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 func validateStructTreeRootDictEntryParentTree(xRefTable *pdf.XRefTable, ir *pdf.IndirectRef) error {
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
+	if xRefTable.ValidationMode != pdf.ValidationStrict {
 
 		// Accept empty dict
 		d, err := xRefTable.DereferenceDict(*ir)
@@ -598,7 +663,7 @@ func validateStructTreeRootDictEntryParentTree(xRefTable *pdf.XRefTable, ir *pdf
 			return err
 		}
 		if d == nil || d.Len() == 0 {
-			return nil
+			return relaxed(xRefTable, ir.ObjectNumber.Value(), "validateStructTreeRootDictEntryParentTree: empty ParentTree")
 		}
 	}
 
@@ -617,7 +682,11 @@ func validateStructTreeRootDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 
 	// Optional entry K: struct element dict or array of struct element dicts
 	if o, found := d.Find("K"); found {
+		if _, err := enterStructKid(xRefTable, o); err != nil {
+			return err
+		}
 		err := validateStructTreeRootDictEntryK(xRefTable, o)
+		leaveStructKid(xRefTable, o)
 		if err != nil {
 			return err
 		}
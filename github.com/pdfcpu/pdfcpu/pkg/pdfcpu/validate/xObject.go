@@ -440,8 +440,10 @@ func validateImageStreamDictPart1(xRefTable *pdf.XRefTable, sd *pdf.StreamDict,
 			required = OPTIONAL
 		}
 
-		if sd.HasSoleFilterNamed(filter.CCITTFax) && xRefTable.ValidationMode == pdf.ValidationRelaxed {
-			required = OPTIONAL
+		if sd.HasSoleFilterNamed(filter.CCITTFax) {
+			if required, err = relaxedRequired(xRefTable, 0, sd.Dict, dictName, "ColorSpace", required); err != nil {
+				return false, err
+			}
 		}
 
 		err = validateColorSpaceEntry(xRefTable, sd.Dict, dictName, "ColorSpace", required, ExcludePatternCS)
@@ -527,9 +529,9 @@ func validateImageStreamDict(xRefTable *pdf.XRefTable, sd *pdf.StreamDict, isAlt
 	}
 
 	// SMask, stream, optional, since V1.4
-	sinceVersion := pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, sd.Dict, dictName, "SMask", pdf.V14, pdf.V13)
+	if err != nil {
+		return err
 	}
 	sd1, err := validateStreamDictEntry(xRefTable, sd.Dict, dictName, "SMask", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -701,9 +703,9 @@ func validateFormStreamDictPart2(xRefTable *pdf.XRefTable, d pdf.Dict, dictName
 
 	// OC, optional, content group dict or content membership dict, since V1.5
 	// Specifying the optional content properties for the annotation.
-	sinceVersion := pdf.V15
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, dictName, "OC", pdf.V15, pdf.V13)
+	if err != nil {
+		return err
 	}
 	err = validateOptionalContent(xRefTable, d, dictName, "OC", OPTIONAL, sinceVersion)
 	if err != nil {
@@ -751,9 +753,9 @@ func validateXObjectStreamDict(xRefTable *pdf.XRefTable, o pdf.Object) error {
 		return err
 	}
 
-	required := REQUIRED
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		required = OPTIONAL
+	required, err := relaxedRequired(xRefTable, 0, sd.Dict, dictName, "Subtype", REQUIRED)
+	if err != nil {
+		return err
 	}
 	subtype, err := validateNameEntry(xRefTable, sd.Dict, dictName, "Subtype", required, pdf.V10, nil)
 	if err != nil {
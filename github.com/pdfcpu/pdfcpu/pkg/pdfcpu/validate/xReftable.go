@@ -24,8 +24,113 @@ import (
 )
 
 // XRefTable validates a PDF cross reference table obeying the validation mode.
+// Any configured ReportFunc receives tolerated violations as warnings and a failing violation as error.
 func XRefTable(xRefTable *pdf.XRefTable) error {
 
+	if xRefTable.ValidationMode == pdf.ValidationCollect {
+		return collectXRefTable(xRefTable)
+	}
+
+	f := xRefTable.ReportFunc
+	if f == nil {
+		_, err := failingObject(validateXRefTable(xRefTable))
+		return err
+	}
+
+	// Remember whether the callback itself aborted validation.
+	var abort error
+	xRefTable.ReportFunc = func(severity pdf.ValidationSeverity, objNr int, msg string) error {
+		abort = f(severity, objNr, msg)
+		return abort
+	}
+	defer func() { xRefTable.ReportFunc = f }()
+
+	objNr, err := failingObject(validateXRefTable(xRefTable))
+	if err != nil && abort == nil {
+		if abort = f(pdf.SeverityError, objNr, err.Error()); abort != nil {
+			return abort
+		}
+	}
+
+	return err
+}
+
+// collectXRefTable validates xRefTable continuing after failing violations of pages, catalog entries and the info dict.
+// All findings are returned as pdf.ValidationErrors if any of them is an error.
+// Any configured ReportFunc still receives each finding as it occurs.
+func collectXRefTable(xRefTable *pdf.XRefTable) error {
+
+	f := xRefTable.ReportFunc
+
+	var (
+		abort error
+		ve    pdf.ValidationErrors
+	)
+
+	xRefTable.ReportFunc = func(severity pdf.ValidationSeverity, objNr int, msg string) error {
+		ve = append(ve, pdf.ValidationError{Severity: severity, ObjNr: objNr, Msg: msg})
+		if f != nil {
+			abort = f(severity, objNr, msg)
+		}
+		return abort
+	}
+	defer func() { xRefTable.ReportFunc = f }()
+
+	objNr, err := failingObject(validateXRefTable(xRefTable))
+	if abort != nil {
+		return abort
+	}
+	if err != nil {
+		if abort = xRefTable.Report(pdf.SeverityError, objNr, err.Error()); abort != nil {
+			return abort
+		}
+	}
+
+	for _, e := range ve {
+		if e.Severity == pdf.SeverityError {
+			xRefTable.Valid = false
+			return ve
+		}
+	}
+
+	return nil
+}
+
+// objectError is a failing violation of object objNr.
+type objectError struct {
+	objNr int
+	err   error
+}
+
+func (e objectError) Error() string {
+	return e.err.Error()
+}
+
+// collect reports err as failing violation of object objNr and lets validation continue in collect mode.
+// In any other mode err is returned attributed to objNr unless already attributed to a nested object.
+func collect(xRefTable *pdf.XRefTable, objNr int, err error) error {
+	if err == nil {
+		return nil
+	}
+	if xRefTable.ValidationMode != pdf.ValidationCollect {
+		if _, ok := err.(objectError); ok || objNr == 0 {
+			return err
+		}
+		return objectError{objNr: objNr, err: err}
+	}
+	return xRefTable.Report(pdf.SeverityError, objNr, err.Error())
+}
+
+// failingObject returns the number of the object err is attributed to, if known, and the underlying error.
+func failingObject(err error) (int, error) {
+	if e, ok := err.(objectError); ok {
+		return e.objNr, e.err
+	}
+	return 0, err
+}
+
+func validateXRefTable(xRefTable *pdf.XRefTable) error {
+
 	log.Info.Println("validating")
 	log.Validate.Println("*** validateXRefTable begin ***")
 
@@ -37,6 +142,9 @@ func XRefTable(xRefTable *pdf.XRefTable) error {
 
 	// Validate document information dictionary.
 	err = validateDocumentInfoObject(xRefTable)
+	if err != nil && xRefTable.Info != nil {
+		err = collect(xRefTable, xRefTable.Info.ObjectNumber.Value(), err)
+	}
 	if err != nil {
 		return err
 	}
@@ -204,9 +312,9 @@ func validateViewerPreferences(xRefTable *pdf.XRefTable, rootDict pdf.Dict, requ
 		return err
 	}
 
-	sinceVersion = pdf.V14
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V10
+	sinceVersion, err = relaxedVersion(xRefTable, 0, d, dictName, "DisplayDocTitle", pdf.V14, pdf.V10)
+	if err != nil {
+		return err
 	}
 	_, err = validateBooleanEntry(xRefTable, d, dictName, "DisplayDocTitle", OPTIONAL, sinceVersion, nil)
 	if err != nil {
@@ -306,8 +414,9 @@ func validateMetadata(xRefTable *pdf.XRefTable, d pdf.Dict, required bool, since
 	// as opposed to serving as an implementation artifact.
 	// Some PDF constructs are considered implementational, and hence may not have associated metadata.
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, d, "dict", "Metadata", sinceVersion, pdf.V13)
+	if err != nil {
+		return err
 	}
 
 	sd, err := validateStreamDictEntry(xRefTable, d, "dict", "Metadata", required, sinceVersion, nil)
@@ -482,8 +591,9 @@ func validateOutputIntents(xRefTable *pdf.XRefTable, rootDict pdf.Dict, required
 
 	// => 14.11.5 Output Intents
 
-	if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-		sinceVersion = pdf.V13
+	sinceVersion, err := relaxedVersion(xRefTable, 0, rootDict, "rootDict", "OutputIntents", sinceVersion, pdf.V13)
+	if err != nil {
+		return err
 	}
 
 	a, err := validateArrayEntry(xRefTable, rootDict, "rootDict", "OutputIntents", required, sinceVersion, nil)
@@ -526,9 +636,9 @@ func validatePieceDict(xRefTable *pdf.XRefTable, d pdf.Dict) error {
 			continue
 		}
 
-		required := REQUIRED
-		if xRefTable.ValidationMode == pdf.ValidationRelaxed {
-			required = OPTIONAL
+		required, err := relaxedRequired(xRefTable, 0, d1, dictName, "LastModified", REQUIRED)
+		if err != nil {
+			return err
 		}
 		_, err = validateDateEntry(xRefTable, d1, dictName, "LastModified", required, pdf.V10)
 		if err != nil {
@@ -566,17 +676,30 @@ func validatePieceInfo(xRefTable *pdf.XRefTable, d pdf.Dict, dictName, entryName
 	return hasPieceInfo, err
 }
 
-// TODO implement
 func validatePermissions(xRefTable *pdf.XRefTable, rootDict pdf.Dict, required bool, sinceVersion pdf.Version) error {
 
 	// => 12.8.4 Permissions
 
-	d, err := validateDictEntry(xRefTable, rootDict, "rootDict", "Permissions", required, sinceVersion, nil)
+	d, err := validateDictEntry(xRefTable, rootDict, "rootDict", "Perms", required, sinceVersion, nil)
 	if err != nil || d == nil {
 		return err
 	}
 
-	return errors.New("pdfcpu: validatePermissions: not supported")
+	// DocMDP, optional, signature dict
+	if o, found := d.Find("DocMDP"); found {
+		if err = validateSignatureDict(xRefTable, o); err != nil {
+			return err
+		}
+	}
+
+	// UR3, optional, signature dict
+	if o, found := d.Find("UR3"); found {
+		if err = validateSignatureDict(xRefTable, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // TODO implement
@@ -897,7 +1020,7 @@ func validateRootObject(xRefTable *pdf.XRefTable) error {
 			continue
 		}
 		err = f.validate(xRefTable, d, f.required, f.sinceVersion)
-		if err != nil {
+		if err = collect(xRefTable, xRefTable.Root.ObjectNumber.Value(), err); err != nil {
 			return err
 		}
 	}
@@ -30,8 +30,35 @@ import (
 	"github.com/pkg/errors"
 )
 
+// writePreservedHeader writes the header of the input file verbatim and
+// switches to its end of line marker for the rest of the file.
+// A header version below V1.7 gets overridden by the root version.
+func writePreservedHeader(ctx *Context) error {
+
+	w := ctx.Write
+	w.Eol = ctx.Read.Eol
+
+	n, err := w.Write(ctx.Read.Header)
+	if err != nil {
+		return err
+	}
+	w.Offset += int64(n)
+
+	if ctx.HeaderVersion != nil && *ctx.HeaderVersion < V17 {
+		ctx.RootDict.Update("Version", Name(V17.String()))
+	} else if ctx.RootVersion != nil {
+		ctx.RootDict.Delete("Version")
+	}
+
+	return nil
+}
+
 // Write generates a PDF file for the cross reference table contained in Context.
 func Write(ctx *Context) (err error) {
+	if err = ctx.validateStreamFilter(); err != nil {
+		return err
+	}
+
 	// Create a writer for dirname and filename if not already supplied.
 	if ctx.Write.Writer == nil {
 
@@ -66,15 +93,28 @@ func Write(ctx *Context) (err error) {
 		return err
 	}
 
-	// Since we support PDF Collections (since V1.7) for file attachments
-	// we need to always generate V1.7 PDF files.
-	if err = writeHeader(ctx.Write, V17); err != nil {
-		return err
+	if ctx.Linearize {
+		if err = writeLinearized(ctx); err != nil {
+			return err
+		}
+		return finishWrite(ctx)
 	}
 
-	// Ensure there is no root version.
-	if ctx.RootVersion != nil {
-		ctx.RootDict.Delete("Version")
+	if ctx.PreserveHeader && ctx.Read != nil && ctx.Read.Header != nil {
+		if err = writePreservedHeader(ctx); err != nil {
+			return err
+		}
+	} else {
+		// Since we support PDF Collections (since V1.7) for file attachments
+		// we need to always generate V1.7 PDF files.
+		if err = writeHeader(ctx.Write, V17); err != nil {
+			return err
+		}
+
+		// Ensure there is no root version.
+		if ctx.RootVersion != nil {
+			ctx.RootDict.Delete("Version")
+		}
 	}
 
 	log.Write.Printf("offset after writeHeader: %d\n", ctx.Write.Offset)
@@ -115,7 +155,12 @@ func Write(ctx *Context) (err error) {
 		return err
 	}
 
-	if err = setFileSizeOfWrittenFile(ctx.Write); err != nil {
+	return finishWrite(ctx)
+}
+
+func finishWrite(ctx *Context) error {
+
+	if err := setFileSizeOfWrittenFile(ctx.Write); err != nil {
 		return err
 	}
 
@@ -710,6 +755,7 @@ func writeXRefStream(ctx *Context) error {
 
 	xRefTable := ctx.XRefTable
 	xRefStreamDict := NewXRefStreamDict(ctx)
+	xRefStreamDict.setFilter(ctx.StreamFilterName())
 	xRefTableEntry := NewXRefTableEntryGen0(*xRefStreamDict)
 
 	// Reuse free objects (including recycled objects from this run).
@@ -821,12 +867,22 @@ func setupEncryption(ctx *Context) error {
 		return errors.New("pdfcpu: unsupported encryption algorithm")
 	}
 
+	if ctx.EncryptUsingAES256 {
+		ctx.EncryptUsingAES, ctx.EncryptKeyLength = true, 256
+	}
+
 	d := newEncryptDict(
 		ctx.EncryptUsingAES,
 		ctx.EncryptKeyLength,
+		ctx.EncryptUsingAES256,
 		ctx.Permissions,
 	)
 
+	if ctx.PermissionFlags != nil {
+		ctx.Permissions = ctx.PermissionFlags.Bits(*d.IntEntry("R"))
+		d.Update("P", Integer(ctx.Permissions))
+	}
+
 	if ctx.E, err = supportedEncryption(ctx, d); err != nil {
 		return err
 	}
@@ -870,14 +926,17 @@ func updateEncryption(ctx *Context) error {
 		return err
 	}
 
-	if ctx.Cmd == SETPERMISSIONS {
+	if ctx.Cmd == SETPERMISSIONS || ctx.Cmd == CHANGEPW && ctx.PermissionFlags != nil {
 		//fmt.Printf("updating permissions to: %v\n", ctx.UserAccessPermissions)
+		if ctx.PermissionFlags != nil {
+			ctx.Permissions = ctx.PermissionFlags.Bits(ctx.E.R)
+		}
 		ctx.E.P = int(ctx.Permissions)
 		d.Update("P", Integer(ctx.E.P))
 		// and moving on, U is dependent on P
 	}
 
-	// ctx.Cmd == CHANGEUPW or CHANGE OPW
+	// ctx.Cmd == CHANGEUPW, CHANGEOPW or CHANGEPW
 
 	if ctx.UserPWNew != nil {
 		//fmt.Printf("change upw from <%s> to <%s>\n", ctx.UserPW, *ctx.UserPWNew)
@@ -889,7 +948,7 @@ func updateEncryption(ctx *Context) error {
 		ctx.OwnerPW = *ctx.OwnerPWNew
 	}
 
-	if ctx.E.R == 5 {
+	if ctx.E.R >= 5 {
 
 		if err = calcOAndU(ctx, d); err != nil {
 			return err
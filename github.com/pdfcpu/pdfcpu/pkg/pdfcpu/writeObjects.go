@@ -70,6 +70,7 @@ func startObjectStream(ctx *Context) error {
 	log.Write.Println("startObjectStream begin")
 
 	objStreamDict := NewObjectStreamDict()
+	objStreamDict.setFilter(ctx.StreamFilterName())
 
 	objNr, err := ctx.InsertObject(*objStreamDict)
 	if err != nil {
@@ -282,7 +283,7 @@ func writeStringLiteralObject(ctx *Context, objNumber, genNumber int, stringLite
 
 	sl := stringLiteral
 
-	if ctx.EncKey != nil {
+	if ctx.encryptedStrings() {
 		s1, err := encryptString(stringLiteral.Value(), objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return err
@@ -307,7 +308,7 @@ func writeHexLiteralObject(ctx *Context, objNumber, genNumber int, hexLiteral He
 
 	hl := hexLiteral
 
-	if ctx.EncKey != nil {
+	if ctx.encryptedStrings() {
 		s1, err := encryptString(hexLiteral.Value(), objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return err
@@ -358,7 +359,7 @@ func writeDictObject(ctx *Context, objNumber, genNumber int, d Dict) error {
 		return nil
 	}
 
-	if ctx.EncKey != nil {
+	if ctx.encryptedStrings() {
 		_, err := encryptDeepObject(d, objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return err
@@ -379,7 +380,7 @@ func writeArrayObject(ctx *Context, objNumber, genNumber int, a Array) error {
 		return nil
 	}
 
-	if ctx.EncKey != nil {
+	if ctx.encryptedStrings() {
 		_, err := encryptDeepObject(a, objNumber, genNumber, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return err
@@ -396,11 +397,19 @@ func writeStream(w *WriteContext, sd StreamDict) (int64, error) {
 		return 0, errors.Wrapf(err, "writeStream: failed to write raw content")
 	}
 
-	c, err := w.Write(sd.Raw)
+	var c int64
+	if sd.Raw == nil && sd.source != nil {
+		// Encode the data while writing it.
+		c, err = sd.writeSource(w)
+	} else {
+		var i int
+		i, err = w.Write(sd.Raw)
+		c = int64(i)
+	}
 	if err != nil {
 		return 0, errors.Wrapf(err, "writeStream: failed to write raw content")
 	}
-	if int64(c) != *sd.StreamLength {
+	if c != *sd.StreamLength {
 		return 0, errors.Errorf("writeStream: failed to write raw content: %d bytes written - streamlength:%d", c, *sd.StreamLength)
 	}
 
@@ -435,6 +444,26 @@ func handleIndirectLength(ctx *Context, ir *IndirectRef) error {
 	return nil
 }
 
+// ensureStreamLength provides the length of streams built without knowing their length upfront.
+// Unencoded content gets encoded, otherwise the length is taken from the raw stream bytes.
+func ensureStreamLength(sd *StreamDict) error {
+
+	if sd.Raw == nil && sd.Content != nil {
+		return sd.Encode()
+	}
+
+	if sd.StreamLength == nil {
+		l := int64(len(sd.Raw))
+		sd.StreamLength = &l
+	}
+
+	if _, found := sd.Find("Length"); !found {
+		sd.Insert("Length", Integer(*sd.StreamLength))
+	}
+
+	return nil
+}
+
 func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict) error {
 
 	log.Write.Printf("writeStreamDictObject begin: object #%d\n%v", objNumber, sd)
@@ -446,6 +475,10 @@ func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict
 		ctx.Write.WriteToObjectStream = false
 	}
 
+	if err := ensureStreamLength(&sd); err != nil {
+		return err
+	}
+
 	// Sometimes a streamDicts length is a reference.
 	if ir := sd.IndirectRefEntry("Length"); ir != nil {
 		err := handleIndirectLength(ctx, ir)
@@ -458,10 +491,14 @@ func writeStreamDictObject(ctx *Context, objNumber, genNumber int, sd StreamDict
 
 	// Unless the "Identity" crypt filter is used we have to encrypt.
 	isXRefStreamDict := sd.Type() != nil && *sd.Type() == "XRef"
-	if ctx.EncKey != nil &&
+	if ctx.encryptedStreams() &&
 		!isXRefStreamDict &&
 		!(len(sd.FilterPipeline) == 1 && sd.FilterPipeline[0].Name == "Crypt") {
 
+		if err = sd.loadRaw(); err != nil {
+			return err
+		}
+
 		sd.Raw, err = encryptStream(sd.Raw, objNumber, genNumber, ctx.EncKey, ctx.AES4Streams, ctx.E.R)
 		if err != nil {
 			return err
@@ -583,7 +620,7 @@ func writeDeepDict(ctx *Context, d Dict, objNr, genNr int) error {
 
 func writeDeepStreamDict(ctx *Context, sd *StreamDict, objNr, genNr int) error {
 
-	if ctx.EncKey != nil {
+	if ctx.encryptedStrings() {
 		_, err := encryptDeepObject(*sd, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R)
 		if err != nil {
 			return err
@@ -36,6 +36,23 @@ func writePageEntry(ctx *Context, d Dict, dictName, entryName string, statsAttr
 	return nil
 }
 
+// writePageTreeDictObject writes a page tree node or page object honoring ExcludePagesFromObjectStreams.
+func writePageTreeDictObject(ctx *Context, objNr, genNr int, d Dict) error {
+
+	if !ctx.ExcludePagesFromObjectStreams {
+		return writeDictObject(ctx, objNr, genNr, d)
+	}
+
+	inObjStream := ctx.Write.WriteToObjectStream
+	ctx.Write.WriteToObjectStream = false
+
+	err := writeDictObject(ctx, objNr, genNr, d)
+
+	ctx.Write.WriteToObjectStream = inObjStream
+
+	return err
+}
+
 func writePageDict(ctx *Context, ir *IndirectRef, pageDict Dict, pageNr int) error {
 
 	objNr := ir.ObjectNumber.Value()
@@ -50,7 +67,7 @@ func writePageDict(ctx *Context, ir *IndirectRef, pageDict Dict, pageNr int) err
 
 	dictName := "pageDict"
 
-	if err := writeDictObject(ctx, objNr, genNr, pageDict); err != nil {
+	if err := writePageTreeDictObject(ctx, objNr, genNr, pageDict); err != nil {
 		return err
 	}
 
@@ -254,7 +271,7 @@ func writePagesDict(ctx *Context, ir *IndirectRef, pageNr *int) (skip bool, writ
 	d.Update("Count", Integer(countNew))
 	log.Write.Printf("writePagesDict: writing pageDict for obj=%d page=%d\n%s", objNr, *pageNr, d)
 
-	if err = writeDictObject(ctx, objNr, genNr, d); err != nil {
+	if err = writePageTreeDictObject(ctx, objNr, genNr, d); err != nil {
 		return false, 0, err
 	}
 
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// xmpPrefixes returns the prefixes bound to the namespace ns in the XMP packet s.
+func xmpPrefixes(s, ns string) []string {
+	re := regexp.MustCompile(`xmlns:([\w.-]+)\s*=\s*["']` + regexp.QuoteMeta(ns) + `["']`)
+	pp := []string{}
+	for _, m := range re.FindAllStringSubmatch(s, -1) {
+		if !MemberOf(m[1], pp) {
+			pp = append(pp, m[1])
+		}
+	}
+	return pp
+}
+
+// removeXMPProperty removes all occurrences of the property name of namespace ns from the XMP packet s
+// whether expressed as element or as attribute of rdf:Description.
+func removeXMPProperty(s, ns, name string) string {
+	for _, p := range xmpPrefixes(s, ns) {
+		qn := regexp.QuoteMeta(p + ":" + name)
+		for _, re := range []*regexp.Regexp{
+			regexp.MustCompile(`(?s)\s*<` + qn + `(\s[^>]*)?/>`),
+			regexp.MustCompile(`(?s)\s*<` + qn + `(\s[^>]*)?>.*?</` + qn + `\s*>`),
+			regexp.MustCompile(`\s` + qn + `\s*=\s*("[^"]*"|'[^']*')`),
+		} {
+			s = re.ReplaceAllString(s, "")
+		}
+	}
+	return s
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(s)
+}
+
+// xmpInfoDescription returns an rdf:Description holding the non empty fields of di.
+func xmpInfoDescription(di DocumentInfo, rdf string) string {
+
+	prefixes := map[string]string{nsDC: "dc", nsPDF: "pdf", nsXMP: "xmp"}
+
+	var sb strings.Builder
+	for _, e := range docInfoEntries {
+		v := *e.value(&di)
+		if v == nil || *v == "" {
+			continue
+		}
+		qn := prefixes[e.ns] + ":" + e.name
+		switch e.array {
+		case "Alt":
+			fmt.Fprintf(&sb, "\n   <%s><%s:Alt><%s:li xml:lang=\"x-default\">%s</%s:li></%s:Alt></%s>", qn, rdf, rdf, xmlEscape(*v), rdf, rdf, qn)
+		case "Seq":
+			fmt.Fprintf(&sb, "\n   <%s><%s:Seq><%s:li>%s</%s:li></%s:Seq></%s>", qn, rdf, rdf, xmlEscape(*v), rdf, rdf, qn)
+		default:
+			fmt.Fprintf(&sb, "\n   <%s>%s</%s>", qn, xmlEscape(*v), qn)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("  <%s:Description %s:about=\"\" xmlns:dc=\"%s\" xmlns:pdf=\"%s\" xmlns:xmp=\"%s\">%s\n  </%s:Description>\n ",
+		rdf, rdf, nsDC, nsPDF, nsXMP, sb.String(), rdf)
+}
+
+// updateXMPInfo replaces the XMP properties corresponding to the non nil fields of di in the XMP packet b.
+func updateXMPInfo(b []byte, di DocumentInfo) ([]byte, error) {
+
+	s := string(b)
+
+	rdf := "rdf"
+	if pp := xmpPrefixes(s, nsRDF); len(pp) > 0 {
+		rdf = pp[0]
+	}
+
+	for _, e := range docInfoEntries {
+		if *e.value(&di) != nil {
+			s = removeXMPProperty(s, e.ns, e.name)
+		}
+	}
+
+	i := strings.LastIndex(s, "</"+rdf+":RDF>")
+	if i < 0 {
+		return nil, errors.New("pdfcpu: corrupt XMP metadata: missing rdf:RDF")
+	}
+
+	return []byte(s[:i] + xmpInfoDescription(di, rdf) + s[i:]), nil
+}
+
+// validateXMP checks that b is well formed XML wrapped in an x:xmpmeta element, see XMP Specification Part 1, 7.3.3
+func validateXMP(b []byte) error {
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	root := true
+
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Errorf("pdfcpu: XMP metadata is not well formed XML: %v", err)
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok || !root {
+			continue
+		}
+		if se.Name.Space != "adobe:ns:meta/" || se.Name.Local != "xmpmeta" {
+			return errors.Errorf("pdfcpu: XMP metadata must be wrapped in x:xmpmeta, got <%s>", se.Name.Local)
+		}
+		root = false
+	}
+
+	if root {
+		return errors.New("pdfcpu: XMP metadata must be wrapped in x:xmpmeta")
+	}
+
+	return nil
+}
+
+// XMP returns the XMP metadata packet of the catalog of ctx or nil.
+func (ctx *Context) XMP() ([]byte, error) {
+
+	sd, err := ctx.catalogMetadata()
+	if err != nil || sd == nil {
+		return nil, err
+	}
+
+	return sd.Content, nil
+}
+
+// SetXMP replaces the XMP metadata stream of the catalog of ctx with the packet b.
+func (ctx *Context) SetXMP(b []byte) error {
+
+	if err := validateXMP(b); err != nil {
+		return err
+	}
+
+	// Metadata streams should not be compressed in order to remain readable by non PDF aware tools.
+	sd := NewStreamDict(Dict(map[string]Object{"Type": Name("Metadata"), "Subtype": Name("XML")}), 0, nil, nil, nil)
+	sd.Content = b
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	return ctx.updateCatalogMetadata(&sd)
+}
+
+// xmpSimpleProperty returns the value of the simple property name of namespace ns in the XMP packet s
+// whether expressed as element or as attribute of rdf:Description.
+func xmpSimpleProperty(s, ns, name string) (string, bool) {
+	for _, p := range xmpPrefixes(s, ns) {
+		qn := regexp.QuoteMeta(p + ":" + name)
+		for _, re := range []*regexp.Regexp{
+			regexp.MustCompile(`(?s)<` + qn + `(?:\s[^>]*)?>\s*([^<]*?)\s*</` + qn + `\s*>`),
+			regexp.MustCompile(`\s` + qn + `\s*=\s*(?:"([^"]*)"|'([^']*)')`),
+		} {
+			if m := re.FindStringSubmatch(s); m != nil {
+				return strings.Join(m[1:], ""), true
+			}
+		}
+	}
+	return "", false
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -94,6 +97,8 @@ type XRefTable struct {
 	AES4Strings         bool
 	AES4Streams         bool
 	AES4EmbeddedStreams bool
+	IdentityStrF        bool // Strings are not encrypted.
+	IdentityStmF        bool // Streams are not encrypted.
 
 	// PDF Version
 	HeaderVersion *Version // The PDF version the source is claiming to us as per its header.
@@ -126,8 +131,12 @@ type XRefTable struct {
 	Tagged bool // File is using tags. This is important for ???
 
 	// Validation
-	Valid          bool // true means successful validated against ISO 32000.
-	ValidationMode int  // see Configuration
+	Valid          bool       // true means successful validated against ISO 32000.
+	ValidationMode int        // see Configuration
+	ReportFunc     ReportFunc // see Configuration
+	CycleMode      int        // see Configuration
+	ValidatePages  IntSet     // pages getting their content and resources validated, nil for all pages
+	walking        IntSet     // objects currently being processed by recursive walks
 
 	Optimized   bool
 	Watermarked bool
@@ -186,6 +195,45 @@ func (xRefTable *XRefTable) ValidateVersion(element string, sinceVersion Version
 	return nil
 }
 
+// Report passes a validation finding to the configured ReportFunc if any.
+func (xRefTable *XRefTable) Report(severity ValidationSeverity, objNr int, msg string) error {
+	if xRefTable.ReportFunc == nil {
+		return nil
+	}
+	return xRefTable.ReportFunc(severity, objNr, msg)
+}
+
+// CycleDetected handles a recursive reference to object objNr encountered by where.
+// In CycleError mode this is an error. In CycleBreak mode a warning gets reported
+// and the caller is expected to drop the offending reference.
+func (xRefTable *XRefTable) CycleDetected(objNr int, where string) error {
+	msg := fmt.Sprintf("%s: recursive reference to object #%d", where, objNr)
+	if xRefTable.CycleMode != CycleBreak {
+		return errors.New("pdfcpu: " + msg)
+	}
+	return xRefTable.Report(SeverityWarning, objNr, msg)
+}
+
+// EnterObject marks the object referenced by ir as being processed by a recursive walk.
+// It returns false if ir is already being processed, ie. if following ir closes a reference cycle.
+// Each successful call must be paired with LeaveObject.
+func (xRefTable *XRefTable) EnterObject(ir IndirectRef, where string) (bool, error) {
+	objNr := ir.ObjectNumber.Value()
+	if xRefTable.walking == nil {
+		xRefTable.walking = IntSet{}
+	}
+	if xRefTable.walking[objNr] {
+		return false, xRefTable.CycleDetected(objNr, where)
+	}
+	xRefTable.walking[objNr] = true
+	return true, nil
+}
+
+// LeaveObject ends the processing of the object referenced by ir.
+func (xRefTable *XRefTable) LeaveObject(ir IndirectRef) {
+	delete(xRefTable.walking, ir.ObjectNumber.Value())
+}
+
 // EnsureVersionForWriting sets the version to the highest supported PDF Version 1.7.
 // This is necessary to allow validation after adding features not supported
 // by the original version of a document as during watermarking.
@@ -394,40 +442,104 @@ func (xRefTable *XRefTable) NewStreamDictForFile(filename string) (*StreamDict,
 
 // NewEmbeddedStreamDict creates and returns an embeddedStreamDict containing the bytes represented by r.
 func (xRefTable *XRefTable) NewEmbeddedStreamDict(r io.Reader, modDate time.Time) (*IndirectRef, error) {
-	buf, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
+	return xRefTable.newEmbeddedStreamDict(r, modDate, nil, true)
+}
+
+// ByteCounter is an io.Writer counting the bytes written.
+type ByteCounter int64
+
+func (c *ByteCounter) Write(p []byte) (int, error) {
+	*c += ByteCounter(len(p))
+	return len(p), nil
+}
+
+// embeddedFileStreamDict creates an embeddedStreamDict for r and stores the data either Flate encoded or raw.
+// r gets consumed in chunks while computing size and checksums on the fly.
+// If r is an io.ReadSeeker the data gets encoded again while writing and r needs to stay open until then,
+// otherwise the encoded data is the only copy of the data held in memory.
+// It also returns the SHA-256 checksum of the data.
+func embeddedFileStreamDict(r io.Reader, modDate time.Time, creationDate *time.Time, compress bool) (*StreamDict, [sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	var size ByteCounter
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	tr := io.TeeReader(r, io.MultiWriter(&size, md5Hash, sha256Hash))
+
+	sd := StreamDict{Dict: NewDict()}
+
+	if compress {
+		sd.FilterPipeline = []PDFFilter{{Name: filter.Flate, DecodeParms: nil}}
+		sd.InsertName("Filter", filter.Flate)
 	}
 
-	sd, err := xRefTable.NewStreamDictForBuf(buf)
-	if err != nil {
-		return nil, err
+	var streamLength int64
+
+	if rs, ok := r.(io.ReadSeeker); ok {
+		offset, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, sum, err
+		}
+		if streamLength, err = sd.encodeData(ioutil.Discard, tr); err != nil {
+			return nil, sum, err
+		}
+		sd.source = &streamSource{ReadSeeker: rs, offset: offset}
+	} else {
+		var (
+			b   bytes.Buffer
+			err error
+		)
+		if streamLength, err = sd.encodeData(&b, tr); err != nil {
+			return nil, sum, err
+		}
+		sd.Raw = b.Bytes()
 	}
 
+	sd.StreamLength = &streamLength
+	sd.InsertInt("Length", int(streamLength))
+
 	sd.InsertName("Type", "EmbeddedFile")
 	d := NewDict()
-	d.InsertInt("Size", len(buf))
+	d.InsertInt("Size", int(size))
+	d.Insert("CheckSum", NewHexLiteral(md5Hash.Sum(nil)))
 	d.Insert("ModDate", StringLiteral(DateString(modDate)))
+	if creationDate != nil {
+		d.Insert("CreationDate", StringLiteral(DateString(*creationDate)))
+	}
 	sd.Insert("Params", d)
-	if err = sd.Encode(); err != nil {
+
+	copy(sum[:], sha256Hash.Sum(nil))
+
+	return &sd, sum, nil
+}
+
+// newEmbeddedStreamDict creates an embeddedStreamDict for r and stores the data either Flate encoded or raw.
+// The data gets read right away since r may be closed before writing.
+func (xRefTable *XRefTable) newEmbeddedStreamDict(r io.Reader, modDate time.Time, creationDate *time.Time, compress bool) (*IndirectRef, error) {
+	sd, _, err := embeddedFileStreamDict(struct{ io.Reader }{r}, modDate, creationDate, compress)
+	if err != nil {
 		return nil, err
 	}
 
 	return xRefTable.IndRefForNewObject(*sd)
 }
 
-// NewFileSpectDictForAttachment returns a fileSpecDict for a.
-func (xRefTable *XRefTable) NewFileSpectDictForAttachment(a Attachment) (*IndirectRef, error) {
+// attachmentStreamDict returns the embedded file stream dict for a along with the SHA-256 checksum of its data.
+func attachmentStreamDict(a Attachment) (*StreamDict, [sha256.Size]byte, error) {
 	modTime := time.Now()
 	if a.ModTime != nil {
 		modTime = *a.ModTime
 	}
-	sd, err := xRefTable.NewEmbeddedStreamDict(a, modTime)
+	return embeddedFileStreamDict(a.Reader, modTime, a.CreationTime, !a.Uncompressed)
+}
+
+// fileSpecDictForEmbeddedStream returns a fileSpecDict for a embedding sd.
+func (xRefTable *XRefTable) fileSpecDictForEmbeddedStream(a Attachment, sd *StreamDict) (*IndirectRef, error) {
+	ir, err := xRefTable.IndRefForNewObject(*sd)
 	if err != nil {
 		return nil, err
 	}
 
-	d, err := xRefTable.NewFileSpecDict(a.ID, a.Desc, *sd)
+	d, err := xRefTable.NewFileSpecDict(a.ID, a.Desc, *ir)
 	if err != nil {
 		return nil, err
 	}
@@ -435,6 +547,16 @@ func (xRefTable *XRefTable) NewFileSpectDictForAttachment(a Attachment) (*Indire
 	return xRefTable.IndRefForNewObject(d)
 }
 
+// NewFileSpectDictForAttachment returns a fileSpecDict for a.
+func (xRefTable *XRefTable) NewFileSpectDictForAttachment(a Attachment) (*IndirectRef, error) {
+	sd, _, err := attachmentStreamDict(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return xRefTable.fileSpecDictForEmbeddedStream(a, sd)
+}
+
 // NewEmbeddedFileStreamDict returns an embeddedFileStreamDict containing the file "filename".
 func (xRefTable *XRefTable) NewEmbeddedFileStreamDict(filename string) (*IndirectRef, error) {
 	f, err := os.Open(filename)
@@ -1590,6 +1712,28 @@ func (xRefTable *XRefTable) EnsureCollection() error {
 	return nil
 }
 
+// collectionDict returns the Collection dict of the catalog or nil.
+func (xRefTable *XRefTable) collectionDict() (Dict, error) {
+	rootDict, err := xRefTable.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	return xRefTable.DereferenceDict(rootDict["Collection"])
+}
+
+// collectionInitial returns the name of the initial document of the collection, see 7.11.6 Table 153.
+func (xRefTable *XRefTable) collectionInitial() (Dict, string, error) {
+	d, err := xRefTable.collectionDict()
+	if err != nil || d == nil {
+		return nil, "", err
+	}
+	s := d.StringEntry("D")
+	if s == nil {
+		return d, "", nil
+	}
+	return d, *s, nil
+}
+
 // RemoveEmbeddedFilesNameTree removes both the embedded files name tree and the Collection dict.
 func (xRefTable *XRefTable) RemoveEmbeddedFilesNameTree() error {
 
@@ -1991,6 +2135,64 @@ func (xRefTable *XRefTable) PageNumber(pageObjNr int) (int, error) {
 	return xRefTable.processPageTreeForPageNumber(pageRootDict, &pageCount, pageObjNr)
 }
 
+func (xRefTable *XRefTable) processPageTreeForPageDictIndRef(root *IndirectRef, pageCount *int, pageNr int) (*IndirectRef, error) {
+
+	d, err := xRefTable.DereferenceDict(*root)
+	if err != nil {
+		return nil, err
+	}
+
+	// Iterate over page tree.
+	for _, o := range d.ArrayEntry("Kids") {
+
+		if o == nil {
+			continue
+		}
+
+		// Dereference next page node dict.
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return nil, errors.Errorf("pdfcpu: processPageTreeForPageDictIndRef: corrupt page node dict")
+		}
+
+		pageNodeDict, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return nil, err
+		}
+
+		switch *pageNodeDict.Type() {
+
+		case "Pages":
+			// Recurse over sub pagetree.
+			ir1, err := xRefTable.processPageTreeForPageDictIndRef(&ir, pageCount, pageNr)
+			if err != nil || ir1 != nil {
+				return ir1, err
+			}
+
+		case "Page":
+			*pageCount++
+			if *pageCount == pageNr {
+				return &ir, nil
+			}
+		}
+
+	}
+
+	return nil, nil
+}
+
+// PageDictIndRef returns the indirect reference of the page dict for page pageNr.
+func (xRefTable *XRefTable) PageDictIndRef(pageNr int) (*IndirectRef, error) {
+	// Get an indirect reference to the page tree root dict.
+	pageRootDict, _ := xRefTable.Pages()
+	pageCount := 0
+	ir, err := xRefTable.processPageTreeForPageDictIndRef(pageRootDict, &pageCount, pageNr)
+	if err == nil && ir == nil {
+		err = errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+	return ir, err
+}
+
 // EnsurePageCount evaluates the page count for xRefTable if necessary.
 // Important when validation is turned off.
 func (xRefTable *XRefTable) EnsurePageCount() error {
@@ -2019,31 +2221,31 @@ func (xRefTable *XRefTable) EnsurePageCount() error {
 	return nil
 }
 
-func (xRefTable *XRefTable) collectMediaBoxesForPageTree(root *IndirectRef, inhMediaBox *Rectangle, mediaBoxes []*Rectangle, p *int) (*Rectangle, error) {
+func (xRefTable *XRefTable) collectPageAttrsForPageTree(root *IndirectRef, inhPAttrs InheritedPageAttrs, pAttrs []InheritedPageAttrs, p *int) error {
 
 	d, err := xRefTable.DereferenceDict(*root)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	obj, found := d.Find("MediaBox")
-	if found {
-		a, err := xRefTable.DereferenceArray(obj)
-		if err != nil {
-			return nil, err
+	if err := xRefTable.checkInheritedPageAttrs(d, &inhPAttrs, false); err != nil {
+		return err
+	}
+
+	if t := d.Type(); t != nil && *t == "Page" {
+		if inhPAttrs.mediaBox == nil {
+			return errors.New("pdfcpu: collectPageAttrsForPageTree: mediaBox is nil")
 		}
-		if inhMediaBox, err = rect(xRefTable, a); err != nil {
-			return nil, err
+		if *p >= len(pAttrs) {
+			return errors.New("pdfcpu: collectPageAttrsForPageTree: corrupt page count")
 		}
+		pAttrs[*p] = inhPAttrs
+		*p++
+		return nil
 	}
 
 	// Iterate over page tree.
-	kids := d.ArrayEntry("Kids")
-	if kids == nil {
-		return inhMediaBox, nil
-	}
-
-	for _, o := range kids {
+	for _, o := range d.ArrayEntry("Kids") {
 
 		if o == nil {
 			continue
@@ -2052,48 +2254,28 @@ func (xRefTable *XRefTable) collectMediaBoxesForPageTree(root *IndirectRef, inhM
 		// Dereference next page node dict.
 		ir, ok := o.(IndirectRef)
 		if !ok {
-			return nil, errors.Errorf("pdfcpu: collectMediaBoxesForPageTree: corrupt page node dict")
+			return errors.Errorf("pdfcpu: collectPageAttrsForPageTree: corrupt page node dict")
 		}
 
-		pageNodeDict, err := xRefTable.DereferenceDict(ir)
-		if err != nil {
-			return nil, err
-		}
-
-		switch *pageNodeDict.Type() {
-
-		case "Pages":
-			// Recurse over sub pagetree.
-			if _, err = xRefTable.collectMediaBoxesForPageTree(&ir, inhMediaBox, mediaBoxes, p); err != nil {
-				return nil, err
-			}
-
-		case "Page":
-			mediaBox, err := xRefTable.collectMediaBoxesForPageTree(&ir, inhMediaBox, mediaBoxes, p)
-			if err != nil {
-				return nil, err
-			}
-			if mediaBox == nil {
-				return nil, errors.New("pdfcpu: collectMediaBoxesForPageTree: mediaBox is nil")
-			}
-			mediaBoxes[*p] = mediaBox
-			*p++
+		if err = xRefTable.collectPageAttrsForPageTree(&ir, inhPAttrs, pAttrs, p); err != nil {
+			return err
 		}
-
 	}
 
-	return nil, nil
+	return nil
 }
 
-// PageDims returns a sorted slice with media box dimensions
-// for all pages sorted ascending by page number.
+// PageDims returns a sorted slice with the dimensions of the visible region of all pages
+// sorted ascending by page number.
+// The visible region is the crop box or the media box if there is no crop box
+// with width and height swapped for pages rotated by 90 or 270 degrees.
 func (xRefTable *XRefTable) PageDims() ([]Dim, error) {
 
 	if err := xRefTable.EnsurePageCount(); err != nil {
 		return nil, err
 	}
 
-	mediaBoxes := make([]*Rectangle, xRefTable.PageCount)
+	pAttrs := make([]InheritedPageAttrs, xRefTable.PageCount)
 
 	// Get an indirect reference to the page tree root dict.
 	root, err := xRefTable.Pages()
@@ -2102,14 +2284,17 @@ func (xRefTable *XRefTable) PageDims() ([]Dim, error) {
 	}
 
 	i := 0
-	var mb Rectangle
-	if _, err := xRefTable.collectMediaBoxesForPageTree(root, &mb, mediaBoxes, &i); err != nil {
+	if err := xRefTable.collectPageAttrsForPageTree(root, InheritedPageAttrs{}, pAttrs, &i); err != nil {
 		return nil, err
 	}
 
-	ps := make([]Dim, len(mediaBoxes))
-	for i, mb := range mediaBoxes {
-		ps[i] = Dim{mb.Width(), mb.Height()}
+	ps := make([]Dim, i)
+	for j := range ps {
+		vr := viewPort(xRefTable, &pAttrs[j])
+		ps[j] = Dim{vr.Width(), vr.Height()}
+		if rot := (pAttrs[j].rotate%360 + 360) % 360; rot == 90 || rot == 270 {
+			ps[j] = Dim{vr.Height(), vr.Width()}
+		}
 	}
 
 	return ps, nil
@@ -2262,6 +2447,261 @@ func (xRefTable *XRefTable) InsertBlankPages(pages IntSet, before bool) error {
 	return err
 }
 
+// blankPage creates a blank page of dimensions dim for parent.
+// If dim is nil the blank page displays like page pageNr.
+func (xRefTable *XRefTable) blankPage(parent *IndirectRef, pageNr int, dim *Dim) (*IndirectRef, error) {
+
+	if dim != nil {
+		return xRefTable.emptyPage(parent, RectForDim(dim.Width, dim.Height))
+	}
+
+	_, inhPAttrs, err := xRefTable.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	vp := viewPort(xRefTable, inhPAttrs)
+	if vp == nil {
+		return nil, errors.Errorf("pdfcpu: blankPage: page %d: missing mediaBox", pageNr)
+	}
+
+	ir, err := xRefTable.emptyPage(parent, RectForDim(vp.Width(), vp.Height()))
+	if err != nil {
+		return nil, err
+	}
+
+	if rot := normalizedRotation(inhPAttrs.rotate); rot != 0 {
+		d, err := xRefTable.DereferenceDict(*ir)
+		if err != nil {
+			return nil, err
+		}
+		d.Insert("Rotate", Integer(rot))
+	}
+
+	return ir, nil
+}
+
+func (xRefTable *XRefTable) insertBlankPagesAtIntoPageTree(root *IndirectRef, p *int, blanks map[int][]IndirectRef) (int, error) {
+
+	d, err := xRefTable.DereferenceDict(*root)
+	if err != nil {
+		return 0, err
+	}
+
+	i := 0
+	a := Array{}
+
+	for _, o := range d.ArrayEntry("Kids") {
+
+		if o == nil {
+			continue
+		}
+
+		ir, ok := o.(IndirectRef)
+		if !ok {
+			return 0, errors.Errorf("pdfcpu: insertBlankPagesAtIntoPageTree: corrupt page node dict")
+		}
+
+		pageNodeDict, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return 0, err
+		}
+
+		if t := pageNodeDict.Type(); t != nil && *t == "Pages" {
+			j, err := xRefTable.insertBlankPagesAtIntoPageTree(&ir, p, blanks)
+			if err != nil {
+				return 0, err
+			}
+			a = append(a, ir)
+			i += j
+			continue
+		}
+
+		*p++
+		for _, indRef := range blanks[*p] {
+			blankDict, err := xRefTable.DereferenceDict(indRef)
+			if err != nil {
+				return 0, err
+			}
+			blankDict.Update("Parent", *root)
+			a = append(a, indRef)
+			i++
+		}
+		a = append(a, ir)
+	}
+
+	d.Update("Kids", a)
+
+	return i, d.IncrementBy("Count", i)
+}
+
+// InsertBlankPagesAt inserts a blank page before each page at positions referring to the original page numbering.
+// Position PageCount+1 appends a blank page, repeated positions insert several blank pages.
+// If dim is nil each blank page displays like the page following it or like the last page if appended.
+func (xRefTable *XRefTable) InsertBlankPagesAt(positions []int, dim *Dim) error {
+
+	if err := xRefTable.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	if dim != nil && (dim.Width <= 0 || dim.Height <= 0) {
+		return errors.Errorf("pdfcpu: InsertBlankPagesAt: invalid dimensions: %v", *dim)
+	}
+
+	n := xRefTable.PageCount
+	for _, p := range positions {
+		if p < 1 || p > n+1 {
+			return errors.Errorf("pdfcpu: InsertBlankPagesAt: invalid position: %d", p)
+		}
+	}
+
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return err
+	}
+
+	// Create all blank pages upfront while page numbers still refer to the original numbering.
+	blanks := map[int][]IndirectRef{}
+	for _, p := range positions {
+		pageNr := p
+		if pageNr > n {
+			pageNr = n
+		}
+		ir, err := xRefTable.blankPage(root, pageNr, dim)
+		if err != nil {
+			return err
+		}
+		blanks[p] = append(blanks[p], *ir)
+	}
+
+	p := 0
+	if _, err := xRefTable.insertBlankPagesAtIntoPageTree(root, &p, blanks); err != nil {
+		return err
+	}
+
+	if appended := blanks[n+1]; len(appended) > 0 {
+		d, err := xRefTable.DereferenceDict(*root)
+		if err != nil {
+			return err
+		}
+		kids := d.ArrayEntry("Kids")
+		for _, ir := range appended {
+			kids = append(kids, ir)
+		}
+		d.Update("Kids", kids)
+		if err := d.IncrementBy("Count", len(appended)); err != nil {
+			return err
+		}
+	}
+
+	xRefTable.PageCount += len(positions)
+
+	return nil
+}
+
+// inheritedPageAttr returns the value for the inheritable page attribute key of the page dict d, see 7.7.3.4
+func (xRefTable *XRefTable) inheritedPageAttr(d Dict, key string) (Object, error) {
+
+	visited := IntSet{}
+
+	for d != nil {
+		if o, found := d.Find(key); found {
+			return o, nil
+		}
+		ir := d.IndirectRefEntry("Parent")
+		if ir == nil || visited[ir.ObjectNumber.Value()] {
+			return nil, nil
+		}
+		visited[ir.ObjectNumber.Value()] = true
+		var err error
+		if d, err = xRefTable.DereferenceDict(*ir); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// ReorderPages rearranges the pages of xRefTable so that page order[i-1] becomes page i.
+// order needs to be a permutation of all page numbers.
+// The page tree gets flattened with inherited page attributes copied into the pages.
+// Destinations refer to page objects and therefore keep pointing at the moved pages.
+func (xRefTable *XRefTable) ReorderPages(order []int) error {
+
+	if err := xRefTable.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	n := xRefTable.PageCount
+	if len(order) != n {
+		return errors.Errorf("pdfcpu: ReorderPages: order must contain all %d pages, got %d", n, len(order))
+	}
+
+	seen := IntSet{}
+	for _, p := range order {
+		if p < 1 || p > n {
+			return errors.Errorf("pdfcpu: ReorderPages: invalid page number: %d", p)
+		}
+		if seen[p] {
+			return errors.Errorf("pdfcpu: ReorderPages: duplicate page number: %d", p)
+		}
+		seen[p] = true
+	}
+
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return err
+	}
+
+	rootDict, err := xRefTable.DereferenceDict(*root)
+	if err != nil {
+		return err
+	}
+
+	pages := make([]IndirectRef, n)
+
+	for i := 1; i <= n; i++ {
+		ir, err := xRefTable.PageDictIndRef(i)
+		if err != nil {
+			return err
+		}
+		pages[i-1] = *ir
+	}
+
+	kids := Array{}
+
+	for _, p := range order {
+
+		ir := pages[p-1]
+
+		d, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			return err
+		}
+
+		for _, k := range []string{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if _, found := d.Find(k); found {
+				continue
+			}
+			o, err := xRefTable.inheritedPageAttr(d, k)
+			if err != nil {
+				return err
+			}
+			if o != nil {
+				d.Insert(k, o)
+			}
+		}
+
+		d.Update("Parent", *root)
+		kids = append(kids, ir)
+	}
+
+	rootDict.Update("Kids", kids)
+	rootDict.Update("Count", Integer(n))
+
+	return nil
+}
+
 func (xRefTable *XRefTable) detectPageTreeWatermarks(root *IndirectRef) error {
 
 	d, err := xRefTable.DereferenceDict(*root)
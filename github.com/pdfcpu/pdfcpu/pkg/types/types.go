@@ -71,7 +71,7 @@ func (r Rectangle) Center() Point {
 
 // Contains returns true if rectangle r contains point p.
 func (r Rectangle) Contains(p Point) bool {
-	return p.X >= r.LL.X && p.X <= r.UR.X && p.Y >= r.LL.Y && p.Y <= r.LL.Y
+	return p.X >= r.LL.X && p.X <= r.UR.X && p.Y >= r.LL.Y && p.Y <= r.UR.Y
 }
 
 func (r Rectangle) String() string {